@@ -26,6 +26,11 @@ func GetAccount(ctx context.Context, cfg *config.Configuration, fetcher stored_r
 	if accountJSON, accErrs := fetcher.FetchAccount(ctx, cfg.AccountDefaultsJSON(), accountID); len(accErrs) > 0 || accountJSON == nil {
 		// accountID does not reference a valid account
 		for _, e := range accErrs {
+			if inheritanceErr, ok := e.(stored_requests.AccountInheritanceError); ok {
+				return nil, []error{&errortypes.MalformedAcct{
+					Message: fmt.Sprintf("The prebid-server account config for account id \"%s\" is malformed: %s. Please reach out to the prebid server host.", accountID, inheritanceErr.Message),
+				}}
+			}
 			if _, ok := e.(stored_requests.NotFoundError); !ok {
 				errs = append(errs, e)
 			}
@@ -54,6 +59,11 @@ func GetAccount(ctx context.Context, cfg *config.Configuration, fetcher stored_r
 				Message: fmt.Sprintf("The prebid-server account config DSA for account id \"%s\" is malformed. Please reach out to the prebid server host.", accountID),
 			}}
 		}
+		if privacyErrs := account.Privacy.ValidatePrivacyReg(nil); len(privacyErrs) > 0 {
+			return nil, []error{&errortypes.MalformedAcct{
+				Message: fmt.Sprintf("The prebid-server account config for account id \"%s\" is malformed: %v. Please reach out to the prebid server host.", accountID, privacyErrs[0]),
+			}}
+		}
 
 		// Fill in ID if needed, so it can be left out of account definition
 		if len(account.ID) == 0 {
@@ -27,6 +27,8 @@ var mockAccountData = map[string]json.RawMessage{
 	"valid_acct_dsa":            json.RawMessage(`{"disabled":false, "privacy": {"dsa": {"default": "` + validDSA + `"}}}`),
 	"invalid_acct_dsa":          json.RawMessage(`{"disabled":false, "privacy": {"dsa": {"default": "` + invalidDSA + `"}}}`),
 	"invalid_acct_ipv6_ipv4":    json.RawMessage(`{"disabled":false, "privacy": {"ipv6": {"anon_keep_bits": -32}, "ipv4": {"anon_keep_bits": -16}}}`),
+	"usnat_disabled_acct":       json.RawMessage(`{"disabled":false, "privacy": {"allowactivities": {"transmitUfpd": {"rules": [{"privacyreg": ["usnat"]}]}}}}`),
+	"usnat_enabled_acct":        json.RawMessage(`{"disabled":false, "privacy": {"allowactivities": {"transmitUfpd": {"rules": [{"privacyreg": ["usnat"]}]}}, "modules": {"usnat": {"enabled": true}}}}`),
 	"disabled_acct":             json.RawMessage(`{"disabled":true}`),
 	"malformed_acct":            json.RawMessage(`{"disabled":"invalid type"}`),
 	"gdpr_channel_enabled_acct": json.RawMessage(`{"disabled":false,"gdpr":{"channel_enabled":{"amp":true}}}`),
@@ -40,6 +42,9 @@ func (af mockAccountFetcher) FetchAccount(ctx context.Context, accountDefaultsJS
 	if account, ok := mockAccountData[accountID]; ok {
 		return account, nil
 	}
+	if accountID == "broken_parent_acct" {
+		return nil, []error{stored_requests.AccountInheritanceError{AccountID: accountID, Message: "the parent account network can't be fetched"}}
+	}
 	return nil, []error{stored_requests.NotFoundError{ID: accountID, DataType: "Account"}}
 }
 
@@ -93,6 +98,10 @@ func TestGetAccount(t *testing.T) {
 		{accountID: "invalid_acct_ipv6_ipv4", required: true, disabled: false, err: nil, wantDefaultIP: true},
 		{accountID: "invalid_acct_dsa", required: false, disabled: false, err: &errortypes.MalformedAcct{}},
 
+		// pubID given and matches a host account whose privacyreg rules select no enabled privacy module
+		{accountID: "usnat_disabled_acct", required: false, disabled: false, err: &errortypes.MalformedAcct{}},
+		{accountID: "usnat_enabled_acct", required: false, disabled: false, err: nil},
+
 		// pubID given and matches a host account explicitly disabled (Disabled: true on account json)
 		{accountID: "disabled_acct", required: false, disabled: false, err: &errortypes.AccountDisabled{}},
 		{accountID: "disabled_acct", required: true, disabled: false, err: &errortypes.AccountDisabled{}},
@@ -105,6 +114,10 @@ func TestGetAccount(t *testing.T) {
 		{accountID: "malformed_acct", required: false, disabled: true, err: &errortypes.MalformedAcct{}},
 		{accountID: "malformed_acct", required: true, disabled: true, err: &errortypes.MalformedAcct{}},
 
+		// pubID given and matches a host account whose parent chain is broken
+		{accountID: "broken_parent_acct", required: false, disabled: false, err: &errortypes.MalformedAcct{}},
+		{accountID: "broken_parent_acct", required: true, disabled: true, err: &errortypes.MalformedAcct{}},
+
 		// account not provided (does not exist)
 		{accountID: "", required: false, disabled: false, err: nil},
 		{accountID: "", required: true, disabled: false, err: nil},
@@ -8,16 +8,19 @@ import (
 	"github.com/prebid/prebid-server/v3/analytics"
 	"github.com/prebid/prebid-server/v3/analytics/agma"
 	"github.com/prebid/prebid-server/v3/analytics/clients"
+	"github.com/prebid/prebid-server/v3/analytics/filesink"
 	"github.com/prebid/prebid-server/v3/analytics/filesystem"
+	"github.com/prebid/prebid-server/v3/analytics/httpanalytics"
 	"github.com/prebid/prebid-server/v3/analytics/pubstack"
 	"github.com/prebid/prebid-server/v3/config"
+	"github.com/prebid/prebid-server/v3/metrics"
 	"github.com/prebid/prebid-server/v3/openrtb_ext"
 	"github.com/prebid/prebid-server/v3/ortb"
 	"github.com/prebid/prebid-server/v3/privacy"
 )
 
 // Modules that need to be logged to need to be initialized here
-func New(analytics *config.Analytics) analytics.Runner {
+func New(analytics *config.Analytics, metricsEngine metrics.MetricsEngine) analytics.Runner {
 	modules := make(enabledAnalytics, 0)
 	if len(analytics.File.Filename) > 0 {
 		if mod, err := filesystem.NewFileLogger(analytics.File.Filename); err == nil {
@@ -56,6 +59,28 @@ func New(analytics *config.Analytics) analytics.Runner {
 		}
 	}
 
+	if analytics.HTTP.Enabled {
+		httpModule, err := httpanalytics.NewModule(
+			clients.GetDefaultHttpInstance(),
+			analytics.HTTP,
+			metricsEngine,
+			clock.New())
+		if err == nil {
+			modules["http"] = httpModule
+		} else {
+			glog.Errorf("Could not initialize HTTP Analytics: %v", err)
+		}
+	}
+
+	if analytics.FileSink.Enabled {
+		fileSink, err := filesink.NewModule(analytics.FileSink, metricsEngine, clock.New())
+		if err == nil {
+			modules["filesink"] = fileSink
+		} else {
+			glog.Errorf("Could not initialize FileSink: %v", err)
+		}
+	}
+
 	return modules
 }
 
@@ -32,6 +32,10 @@ type AuctionObject struct {
 	HookExecutionOutcome []hookexecution.StageOutcome
 	SeatNonBid           []openrtb_ext.SeatNonBid
 	RequestWrapper       *openrtb_ext.RequestWrapper
+	// PrivacyTrace holds the privacy enforcement applied to each bidder, only present when debug is enabled
+	PrivacyTrace map[openrtb_ext.BidderName]*openrtb_ext.ExtBidderPrivacyTrace
+	// StoredVersions holds the versions of the versioned stored data used by the request
+	StoredVersions *openrtb_ext.ExtStoredVersions
 }
 
 // Loggable object of a transaction at /openrtb2/amp endpoint
@@ -45,6 +49,8 @@ type AmpObject struct {
 	HookExecutionOutcome []hookexecution.StageOutcome
 	SeatNonBid           []openrtb_ext.SeatNonBid
 	RequestWrapper       *openrtb_ext.RequestWrapper
+	// StoredVersions holds the versions of the versioned stored data used by the request
+	StoredVersions *openrtb_ext.ExtStoredVersions
 }
 
 // Loggable object of a transaction at /openrtb2/video endpoint
@@ -0,0 +1,53 @@
+package filesink
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"time"
+
+	"github.com/prebid/prebid-server/v3/config"
+)
+
+const defaultHookTimeout = time.Minute
+
+// SegmentHook is called on each closed segment, such as to ship it. The hooks are called in order, one
+// segment at a time, and the retention only removes the segments once their hooks returned.
+type SegmentHook interface {
+	SegmentClosed(segment Segment) error
+}
+
+// SegmentHookFunc is a function used as a SegmentHook
+type SegmentHookFunc func(segment Segment) error
+
+func (f SegmentHookFunc) SegmentClosed(segment Segment) error {
+	return f(segment)
+}
+
+// commandHook runs a command on the segments, with the paths of the segment files appended to its arguments
+type commandHook struct {
+	command []string
+	timeout time.Duration
+}
+
+func newCommandHook(cfg config.FileSinkHook) *commandHook {
+	timeout := defaultHookTimeout
+	if cfg.TimeoutMS > 0 {
+		timeout = time.Duration(cfg.TimeoutMS) * time.Millisecond
+	}
+	return &commandHook{command: cfg.Command, timeout: timeout}
+}
+
+func (h *commandHook) SegmentClosed(segment Segment) error {
+	ctx, cancel := context.WithTimeout(context.Background(), h.timeout)
+	defer cancel()
+
+	args := append(append([]string{}, h.command[1:]...), segment.Path)
+	if segment.CSVPath != "" {
+		args = append(args, segment.CSVPath)
+	}
+	if output, err := exec.CommandContext(ctx, h.command[0], args...).CombinedOutput(); err != nil {
+		return fmt.Errorf("%s failed: %v: %s", h.command[0], err, output)
+	}
+	return nil
+}
@@ -0,0 +1,217 @@
+package filesink
+
+import (
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/prebid/openrtb/v20/openrtb2"
+	"github.com/tidwall/gjson"
+
+	"github.com/prebid/prebid-server/v3/config"
+	"github.com/prebid/prebid-server/v3/openrtb_ext"
+	"github.com/prebid/prebid-server/v3/util/jsonutil"
+)
+
+// SchemaVersion is the version of the Record schema. It's bumped on any change which isn't a new optional
+// field, and is part of the names of the segments so that readers never mix versions.
+const SchemaVersion = 1
+
+const (
+	// RecordStatusBid is the status of a bidder which bid on the imp. The record holds its highest bid.
+	RecordStatusBid = "bid"
+	// RecordStatusNonBid is the status of a bidder whose bid was rejected or which reported why it didn't bid
+	RecordStatusNonBid = "nonbid"
+	// RecordStatusNoBid is the status of a bidder which was called for the imp, but didn't bid
+	RecordStatusNoBid = "no_bid"
+)
+
+const (
+	RecordTypeAuction = "auction"
+	RecordTypeAMP     = "amp"
+	RecordTypeVideo   = "video"
+)
+
+// Record is the outcome of an imp for a bidder. The segments hold one record per bidder per imp of the auctions.
+type Record struct {
+	Version   int       `json:"v"`
+	Timestamp time.Time `json:"ts"`
+	Type      string    `json:"type"`
+	RequestID string    `json:"request_id"`
+	Account   string    `json:"account,omitempty"`
+	ImpID     string    `json:"imp_id"`
+	Bidder    string    `json:"bidder"`
+	Status    string    `json:"status"`
+	// Price is the CPM of the bid in Currency, or of the rejected bid for a nonbid
+	Price    float64 `json:"price,omitempty"`
+	Currency string  `json:"currency,omitempty"`
+	DealID   string  `json:"deal_id,omitempty"`
+	// LatencyMS is the response time of the bidder for the whole request
+	LatencyMS *int `json:"latency_ms,omitempty"`
+	// NonBidReason is the status code of the nonbid
+	NonBidReason int `json:"nbr,omitempty"`
+}
+
+// columns are the header of the CSV files, in the order of the values of Record.row
+var columns = []string{"v", "ts", "type", "request_id", "account", "imp_id", "bidder", "status", "price", "currency", "deal_id", "latency_ms", "nbr"}
+
+func (r Record) row() []string {
+	latency := ""
+	if r.LatencyMS != nil {
+		latency = strconv.Itoa(*r.LatencyMS)
+	}
+	return []string{
+		strconv.Itoa(r.Version),
+		r.Timestamp.UTC().Format(time.RFC3339Nano),
+		r.Type,
+		r.RequestID,
+		r.Account,
+		r.ImpID,
+		r.Bidder,
+		r.Status,
+		strconv.FormatFloat(r.Price, 'f', -1, 64),
+		r.Currency,
+		r.DealID,
+		latency,
+		strconv.Itoa(r.NonBidReason),
+	}
+}
+
+// auction holds the parts of an auction the records are built from
+type auction struct {
+	recordType string
+	timestamp  time.Time
+	account    *config.Account
+	request    *openrtb2.BidRequest
+	response   *openrtb2.BidResponse
+	seatNonBid []openrtb_ext.SeatNonBid
+}
+
+// records returns the record of each bidder of each imp, with the imps in the order of the request and the
+// bidders sorted by name
+func (a auction) records() []Record {
+	if a.request == nil {
+		return nil
+	}
+
+	bids := make(map[string]map[string]openrtb2.Bid)
+	var currency string
+	var latencies map[string]int
+	if a.response != nil {
+		currency = a.response.Cur
+		for _, seatBid := range a.response.SeatBid {
+			for _, bid := range seatBid.Bid {
+				seatBids := bids[bid.ImpID]
+				if seatBids == nil {
+					seatBids = make(map[string]openrtb2.Bid)
+					bids[bid.ImpID] = seatBids
+				}
+				if best, ok := seatBids[seatBid.Seat]; !ok || bid.Price > best.Price {
+					seatBids[seatBid.Seat] = bid
+				}
+			}
+		}
+		latencies = responseTimes(a.response.Ext)
+	}
+
+	nonBids := make(map[string]map[string]openrtb_ext.NonBid)
+	for _, seatNonBid := range a.seatNonBid {
+		for _, nonBid := range seatNonBid.NonBid {
+			if nonBids[nonBid.ImpId] == nil {
+				nonBids[nonBid.ImpId] = make(map[string]openrtb_ext.NonBid)
+			}
+			nonBids[nonBid.ImpId][seatNonBid.Seat] = nonBid
+		}
+	}
+
+	account := accountID(a.account, a.request)
+	var records []Record
+	for _, imp := range a.request.Imp {
+		for _, bidder := range impBidders(imp, bids[imp.ID], nonBids[imp.ID]) {
+			record := Record{
+				Version:   SchemaVersion,
+				Timestamp: a.timestamp,
+				Type:      a.recordType,
+				RequestID: a.request.ID,
+				Account:   account,
+				ImpID:     imp.ID,
+				Bidder:    bidder,
+				Status:    RecordStatusNoBid,
+			}
+			if latency, ok := latencies[bidder]; ok {
+				record.LatencyMS = &latency
+			}
+			if bid, ok := bids[imp.ID][bidder]; ok {
+				record.Status = RecordStatusBid
+				record.Price = bid.Price
+				record.Currency = currency
+				record.DealID = bid.DealID
+			} else if nonBid, ok := nonBids[imp.ID][bidder]; ok {
+				record.Status = RecordStatusNonBid
+				record.NonBidReason = nonBid.StatusCode
+				if nonBid.Ext != nil {
+					record.Price = nonBid.Ext.Prebid.Bid.Price
+					record.DealID = nonBid.Ext.Prebid.Bid.DealID
+					if record.Price != 0 {
+						record.Currency = currency
+					}
+				}
+			}
+			records = append(records, record)
+		}
+	}
+	return records
+}
+
+// impBidders returns the bidders of the imp, and the seats which bid or reported a nonbid on it
+func impBidders(imp openrtb2.Imp, bids map[string]openrtb2.Bid, nonBids map[string]openrtb_ext.NonBid) []string {
+	seen := make(map[string]bool)
+	var bidders []string
+	add := func(bidder string) {
+		if !seen[bidder] {
+			seen[bidder] = true
+			bidders = append(bidders, bidder)
+		}
+	}
+	gjson.GetBytes(imp.Ext, "prebid.bidder").ForEach(func(key, _ gjson.Result) bool {
+		add(key.String())
+		return true
+	})
+	for seat := range bids {
+		add(seat)
+	}
+	for seat := range nonBids {
+		add(seat)
+	}
+	sort.Strings(bidders)
+	return bidders
+}
+
+func responseTimes(ext []byte) map[string]int {
+	if len(ext) == 0 {
+		return nil
+	}
+	var responseExt struct {
+		ResponseTimeMillis map[string]int `json:"responsetimemillis"`
+	}
+	if err := jsonutil.UnmarshalValid(ext, &responseExt); err != nil {
+		return nil
+	}
+	return responseExt.ResponseTimeMillis
+}
+
+// accountID returns the ID of the account, or else the publisher ID of the request
+func accountID(account *config.Account, request *openrtb2.BidRequest) string {
+	if account != nil && account.ID != "" {
+		return account.ID
+	}
+	switch {
+	case request.Site != nil && request.Site.Publisher != nil:
+		return request.Site.Publisher.ID
+	case request.App != nil && request.App.Publisher != nil:
+		return request.App.Publisher.ID
+	case request.DOOH != nil && request.DOOH.Publisher != nil:
+		return request.DOOH.Publisher.ID
+	}
+	return ""
+}
@@ -0,0 +1,106 @@
+package filesink
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/prebid/openrtb/v20/openrtb2"
+	"github.com/prebid/prebid-server/v3/config"
+	"github.com/prebid/prebid-server/v3/openrtb_ext"
+	"github.com/prebid/prebid-server/v3/util/ptrutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRecords(t *testing.T) {
+	timestamp := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	request := &openrtb2.BidRequest{
+		ID:   "request",
+		Site: &openrtb2.Site{Publisher: &openrtb2.Publisher{ID: "publisher"}},
+		Imp: []openrtb2.Imp{
+			{ID: "imp1", Ext: json.RawMessage(`{"prebid":{"bidder":{"appnexus":{},"rubicon":{},"pubmatic":{}}}}`)},
+			{ID: "imp2", Ext: json.RawMessage(`{"prebid":{"bidder":{"appnexus":{}}}}`)},
+		},
+	}
+	response := &openrtb2.BidResponse{
+		ID:  "request",
+		Cur: "USD",
+		SeatBid: []openrtb2.SeatBid{
+			{Seat: "appnexus", Bid: []openrtb2.Bid{
+				{ID: "bid1", ImpID: "imp1", Price: 1.5},
+				{ID: "bid2", ImpID: "imp1", Price: 2.5, DealID: "deal"},
+			}},
+		},
+		Ext: json.RawMessage(`{"responsetimemillis":{"appnexus":35,"rubicon":80}}`),
+	}
+	seatNonBid := []openrtb_ext.SeatNonBid{
+		{Seat: "rubicon", NonBid: []openrtb_ext.NonBid{
+			{ImpId: "imp1", StatusCode: 301, Ext: &openrtb_ext.NonBidExt{Prebid: openrtb_ext.ExtResponseNonBidPrebid{Bid: openrtb_ext.NonBidObject{Price: 0.5}}}},
+		}},
+	}
+
+	testCases := []struct {
+		description string
+		auction     auction
+		expected    []Record
+	}{
+		{
+			description: "no_request",
+			auction:     auction{recordType: RecordTypeAuction, timestamp: timestamp},
+		},
+		{
+			description: "bids_nonbids_and_no_bids",
+			auction: auction{
+				recordType: RecordTypeAuction,
+				timestamp:  timestamp,
+				account:    &config.Account{ID: "account"},
+				request:    request,
+				response:   response,
+				seatNonBid: seatNonBid,
+			},
+			expected: []Record{
+				{Version: 1, Timestamp: timestamp, Type: "auction", RequestID: "request", Account: "account", ImpID: "imp1", Bidder: "appnexus", Status: RecordStatusBid, Price: 2.5, Currency: "USD", DealID: "deal", LatencyMS: ptrutil.ToPtr(35)},
+				{Version: 1, Timestamp: timestamp, Type: "auction", RequestID: "request", Account: "account", ImpID: "imp1", Bidder: "pubmatic", Status: RecordStatusNoBid},
+				{Version: 1, Timestamp: timestamp, Type: "auction", RequestID: "request", Account: "account", ImpID: "imp1", Bidder: "rubicon", Status: RecordStatusNonBid, Price: 0.5, Currency: "USD", LatencyMS: ptrutil.ToPtr(80), NonBidReason: 301},
+				{Version: 1, Timestamp: timestamp, Type: "auction", RequestID: "request", Account: "account", ImpID: "imp2", Bidder: "appnexus", Status: RecordStatusNoBid, LatencyMS: ptrutil.ToPtr(35)},
+			},
+		},
+		{
+			description: "publisher_account_without_response",
+			auction: auction{
+				recordType: RecordTypeAMP,
+				timestamp:  timestamp,
+				request:    &openrtb2.BidRequest{ID: "request", App: &openrtb2.App{Publisher: &openrtb2.Publisher{ID: "publisher"}}, Imp: request.Imp[1:]},
+			},
+			expected: []Record{
+				{Version: 1, Timestamp: timestamp, Type: "amp", RequestID: "request", Account: "publisher", ImpID: "imp2", Bidder: "appnexus", Status: RecordStatusNoBid},
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.description, func(t *testing.T) {
+			assert.Equal(t, test.expected, test.auction.records())
+		})
+	}
+}
+
+func TestRecordRow(t *testing.T) {
+	record := Record{
+		Version:   1,
+		Timestamp: time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC),
+		Type:      "auction",
+		RequestID: "request",
+		Account:   "account",
+		ImpID:     "imp1",
+		Bidder:    "appnexus",
+		Status:    RecordStatusBid,
+		Price:     2.5,
+		Currency:  "USD",
+		LatencyMS: ptrutil.ToPtr(35),
+	}
+
+	row := record.row()
+	assert.Len(t, row, len(columns))
+	assert.Equal(t, []string{"1", "2024-05-01T12:00:00Z", "auction", "request", "account", "imp1", "appnexus", "bid", "2.5", "USD", "", "35", "0"}, row)
+}
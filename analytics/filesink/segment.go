@@ -0,0 +1,184 @@
+package filesink
+
+import (
+	"compress/gzip"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/klauspost/compress/zstd"
+
+	"github.com/prebid/prebid-server/v3/config"
+	"github.com/prebid/prebid-server/v3/util/jsonutil"
+)
+
+// tmpSuffix ends the names of the files being written, so that the readers of the directory only see the
+// closed segments
+const tmpSuffix = ".tmp"
+
+// Segment is a closed segment, handed over to the hooks
+type Segment struct {
+	// Path is the path of the JSON lines file of the records
+	Path string
+	// CSVPath is the path of the CSV file of the records, if the CSV files are enabled
+	CSVPath string
+	Records int
+	Opened  time.Time
+	Closed  time.Time
+}
+
+// compressedFile is a file written through the compressor of the config, under its temporary name until closed
+type compressedFile struct {
+	path       string
+	file       *os.File
+	compressor io.WriteCloser
+	w          io.Writer
+}
+
+func createCompressedFile(path string, compression string) (*compressedFile, error) {
+	file, err := os.OpenFile(path+tmpSuffix, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+	if err != nil {
+		return nil, err
+	}
+
+	f := &compressedFile{path: path, file: file, w: file}
+	switch compression {
+	case config.FileSinkCompressionGzip:
+		f.compressor = gzip.NewWriter(file)
+	case config.FileSinkCompressionZstd:
+		encoder, err := zstd.NewWriter(file)
+		if err != nil {
+			file.Close()
+			os.Remove(file.Name())
+			return nil, err
+		}
+		f.compressor = encoder
+	}
+	if f.compressor != nil {
+		f.w = f.compressor
+	}
+	return f, nil
+}
+
+func (f *compressedFile) Write(p []byte) (int, error) {
+	return f.w.Write(p)
+}
+
+// Close flushes the file, and renames it to its final name
+func (f *compressedFile) Close() error {
+	if f.compressor != nil {
+		if err := f.compressor.Close(); err != nil {
+			f.file.Close()
+			return err
+		}
+	}
+	if err := f.file.Close(); err != nil {
+		return err
+	}
+	return os.Rename(f.file.Name(), f.path)
+}
+
+// segment is the segment being written
+type segment struct {
+	records *compressedFile
+	csvFile *compressedFile
+	csv     *csv.Writer
+	size    int64
+	count   int
+	opened  time.Time
+}
+
+// segmentName returns the name of the segment opened at the time, without its extension. The names sort
+// in the order the segments are opened.
+func segmentName(prefix string, opened time.Time, sequence int) string {
+	return fmt.Sprintf("%s-%s-%06d.v%d", prefix, opened.UTC().Format("20060102T150405.000Z"), sequence, SchemaVersion)
+}
+
+func compressionExtension(compression string) string {
+	switch compression {
+	case config.FileSinkCompressionGzip:
+		return ".gz"
+	case config.FileSinkCompressionZstd:
+		return ".zst"
+	}
+	return ""
+}
+
+func openSegment(cfg config.FileSink, opened time.Time, sequence int) (*segment, error) {
+	base := filepath.Join(cfg.Directory, segmentName(cfg.Prefix, opened, sequence))
+	extension := compressionExtension(cfg.Compression)
+
+	records, err := createCompressedFile(base+".jsonl"+extension, cfg.Compression)
+	if err != nil {
+		return nil, err
+	}
+	s := &segment{records: records, opened: opened}
+
+	if cfg.CSV {
+		if s.csvFile, err = createCompressedFile(base+".csv"+extension, cfg.Compression); err != nil {
+			s.discard()
+			return nil, err
+		}
+		s.csv = csv.NewWriter(s.csvFile)
+		if err := s.csv.Write(columns); err != nil {
+			s.discard()
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+// write appends the records to the segment
+func (s *segment) write(records []Record) error {
+	for _, record := range records {
+		data, err := jsonutil.Marshal(record)
+		if err != nil {
+			return err
+		}
+		data = append(data, '\n')
+		if _, err := s.records.Write(data); err != nil {
+			return err
+		}
+		s.size += int64(len(data))
+
+		if s.csv != nil {
+			if err := s.csv.Write(record.row()); err != nil {
+				return err
+			}
+		}
+	}
+	s.count += len(records)
+	return nil
+}
+
+// close closes the files of the segment, and returns the segment to hand over to the hooks
+func (s *segment) close(closed time.Time) (Segment, error) {
+	closedSegment := Segment{Path: s.records.path, Records: s.count, Opened: s.opened, Closed: closed}
+	if s.csv != nil {
+		s.csv.Flush()
+		if err := s.csv.Error(); err != nil {
+			s.discard()
+			return Segment{}, err
+		}
+		if err := s.csvFile.Close(); err != nil {
+			s.discard()
+			return Segment{}, err
+		}
+		closedSegment.CSVPath = s.csvFile.path
+	}
+	if err := s.records.Close(); err != nil {
+		return Segment{}, err
+	}
+	return closedSegment, nil
+}
+
+// discard closes the files of the segment, and leaves them under their temporary names
+func (s *segment) discard() {
+	s.records.file.Close()
+	if s.csvFile != nil {
+		s.csvFile.file.Close()
+	}
+}
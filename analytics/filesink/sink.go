@@ -0,0 +1,275 @@
+// Package filesink implements an analytics module writing the auction outcomes to local files. The records are
+// written to segments, which are rotated by size and age, compressed, and handed over to hooks once closed.
+package filesink
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/benbjohnson/clock"
+	"github.com/golang/glog"
+
+	"github.com/prebid/prebid-server/v3/analytics"
+	"github.com/prebid/prebid-server/v3/config"
+	"github.com/prebid/prebid-server/v3/metrics"
+)
+
+// metricsName identifies the file sink in the analytics events metrics
+const metricsName = "file_sink"
+
+type FileSink struct {
+	cfg           config.FileSink
+	maxAge        time.Duration
+	hooks         []SegmentHook
+	clock         clock.Clock
+	metricsEngine metrics.MetricsEngine
+
+	queue  chan []Record
+	closed chan Segment
+	stop   chan struct{}
+	done   chan struct{}
+
+	// current is the segment being written, only used by the writer goroutine
+	current      *segment
+	rotateTimer  *clock.Timer
+	sequence     int
+	shutdownOnce sync.Once
+}
+
+// NewModule returns the file sink of the config. The hooks are called on the closed segments after the hooks
+// of the config.
+func NewModule(cfg config.FileSink, metricsEngine metrics.MetricsEngine, clock clock.Clock, hooks ...SegmentHook) (*FileSink, error) {
+	if err := os.MkdirAll(cfg.Directory, 0755); err != nil {
+		return nil, err
+	}
+
+	s := &FileSink{
+		cfg:           cfg,
+		maxAge:        time.Duration(cfg.Rotation.MaxAgeSeconds) * time.Second,
+		clock:         clock,
+		metricsEngine: metricsEngine,
+		queue:         make(chan []Record, cfg.QueueSize),
+		closed:        make(chan Segment, 16),
+		stop:          make(chan struct{}),
+		done:          make(chan struct{}),
+	}
+	for _, hookCfg := range cfg.Hooks {
+		s.hooks = append(s.hooks, newCommandHook(hookCfg))
+	}
+	s.hooks = append(s.hooks, hooks...)
+
+	hooksDone := make(chan struct{})
+	go s.runHooks(hooksDone)
+	go s.run(hooksDone)
+	return s, nil
+}
+
+func (s *FileSink) log(a auction) {
+	records := a.records()
+	if len(records) == 0 {
+		return
+	}
+	select {
+	case s.queue <- records:
+	default:
+		s.metricsEngine.RecordAnalyticsEvents(metricsName, metrics.AnalyticsEventDropped, len(records))
+	}
+}
+
+func (s *FileSink) LogAuctionObject(ao *analytics.AuctionObject) {
+	if ao == nil || ao.RequestWrapper == nil {
+		return
+	}
+	s.log(auction{
+		recordType: RecordTypeAuction,
+		timestamp:  s.clock.Now(),
+		account:    ao.Account,
+		request:    ao.RequestWrapper.BidRequest,
+		response:   ao.Response,
+		seatNonBid: ao.SeatNonBid,
+	})
+}
+
+func (s *FileSink) LogAmpObject(ao *analytics.AmpObject) {
+	if ao == nil || ao.RequestWrapper == nil {
+		return
+	}
+	s.log(auction{
+		recordType: RecordTypeAMP,
+		timestamp:  s.clock.Now(),
+		request:    ao.RequestWrapper.BidRequest,
+		response:   ao.AuctionResponse,
+		seatNonBid: ao.SeatNonBid,
+	})
+}
+
+func (s *FileSink) LogVideoObject(vo *analytics.VideoObject) {
+	if vo == nil || vo.RequestWrapper == nil {
+		return
+	}
+	s.log(auction{
+		recordType: RecordTypeVideo,
+		timestamp:  s.clock.Now(),
+		request:    vo.RequestWrapper.BidRequest,
+		response:   vo.Response,
+		seatNonBid: vo.SeatNonBid,
+	})
+}
+
+// The records are only about the auctions, so the other events aren't written
+
+func (s *FileSink) LogCookieSyncObject(cso *analytics.CookieSyncObject) {}
+
+func (s *FileSink) LogSetUIDObject(so *analytics.SetUIDObject) {}
+
+func (s *FileSink) LogNotificationEventObject(ne *analytics.NotificationEvent) {}
+
+// Shutdown writes the queued records, closes the segment being written and waits for the hooks
+func (s *FileSink) Shutdown() {
+	s.shutdownOnce.Do(func() {
+		glog.Info("[FileSink] Shutdown, closing the segment")
+		close(s.stop)
+		<-s.done
+	})
+}
+
+// run writes the queued records until the sink is stopped
+func (s *FileSink) run(hooksDone <-chan struct{}) {
+	defer close(s.done)
+
+	for {
+		var rotate <-chan time.Time
+		if s.rotateTimer != nil {
+			rotate = s.rotateTimer.C
+		}
+
+		select {
+		case records := <-s.queue:
+			s.write(records)
+		case <-rotate:
+			s.closeSegment()
+		case <-s.stop:
+			for drained := false; !drained; {
+				select {
+				case records := <-s.queue:
+					s.write(records)
+				default:
+					drained = true
+				}
+			}
+			s.closeSegment()
+			close(s.closed)
+			<-hooksDone
+			return
+		}
+	}
+}
+
+func (s *FileSink) write(records []Record) {
+	if s.current == nil {
+		s.sequence++
+		current, err := openSegment(s.cfg, s.clock.Now(), s.sequence)
+		if err != nil {
+			glog.Errorf("[FileSink] Opening a segment failed: %v", err)
+			s.metricsEngine.RecordAnalyticsEvents(metricsName, metrics.AnalyticsEventFailed, len(records))
+			return
+		}
+		s.current = current
+		s.rotateTimer = s.clock.Timer(s.maxAge)
+	}
+
+	if err := s.current.write(records); err != nil {
+		glog.Errorf("[FileSink] Writing to the segment %s failed: %v", s.current.records.path, err)
+		s.metricsEngine.RecordAnalyticsEvents(metricsName, metrics.AnalyticsEventFailed, len(records))
+		s.closeSegment()
+		return
+	}
+	s.metricsEngine.RecordAnalyticsEvents(metricsName, metrics.AnalyticsEventSent, len(records))
+
+	if s.current.size >= s.cfg.Rotation.MaxSizeBytes {
+		s.closeSegment()
+	}
+}
+
+// closeSegment closes the segment being written, if any, and hands it over to the hooks
+func (s *FileSink) closeSegment() {
+	if s.current == nil {
+		return
+	}
+	s.rotateTimer.Stop()
+	s.rotateTimer = nil
+
+	closedSegment, err := s.current.close(s.clock.Now())
+	s.current = nil
+	if err != nil {
+		glog.Errorf("[FileSink] Closing a segment failed: %v", err)
+		return
+	}
+	s.closed <- closedSegment
+}
+
+// runHooks calls the hooks on the closed segments, then removes the segments beyond the retention
+func (s *FileSink) runHooks(done chan<- struct{}) {
+	defer close(done)
+	for closedSegment := range s.closed {
+		for _, hook := range s.hooks {
+			if err := hook.SegmentClosed(closedSegment); err != nil {
+				glog.Errorf("[FileSink] The hook of the segment %s failed: %v", closedSegment.Path, err)
+			}
+		}
+		s.applyRetention()
+	}
+}
+
+// applyRetention removes the oldest closed segments beyond the max number of segments, and the segments
+// older than the max age
+func (s *FileSink) applyRetention() {
+	retention := s.cfg.Retention
+	if retention.MaxSegments == 0 && retention.MaxAgeHours == 0 {
+		return
+	}
+
+	entries, err := os.ReadDir(s.cfg.Directory)
+	if err != nil {
+		glog.Errorf("[FileSink] Listing the segments failed: %v", err)
+		return
+	}
+
+	// the files of a segment share its name, and the names sort in the order the segments are opened
+	files := make(map[string][]os.DirEntry)
+	var names []string
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasPrefix(name, s.cfg.Prefix+"-") || strings.HasSuffix(name, tmpSuffix) {
+			continue
+		}
+		base := s.cfg.Prefix + "-" + strings.SplitN(strings.TrimPrefix(name, s.cfg.Prefix+"-"), ".v", 2)[0]
+		if _, ok := files[base]; !ok {
+			names = append(names, base)
+		}
+		files[base] = append(files[base], entry)
+	}
+	sort.Strings(names)
+
+	expiry := s.clock.Now().Add(-time.Duration(retention.MaxAgeHours) * time.Hour)
+	for i, name := range names {
+		expired := retention.MaxSegments > 0 && len(names)-i > retention.MaxSegments
+		if !expired && retention.MaxAgeHours > 0 {
+			if info, err := files[name][0].Info(); err == nil && info.ModTime().Before(expiry) {
+				expired = true
+			}
+		}
+		if !expired {
+			continue
+		}
+		for _, entry := range files[name] {
+			if err := os.Remove(filepath.Join(s.cfg.Directory, entry.Name())); err != nil {
+				glog.Errorf("[FileSink] Removing the segment file %s failed: %v", entry.Name(), err)
+			}
+		}
+	}
+}
@@ -0,0 +1,296 @@
+package filesink
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/benbjohnson/clock"
+	"github.com/klauspost/compress/zstd"
+	"github.com/prebid/openrtb/v20/openrtb2"
+	"github.com/prebid/prebid-server/v3/analytics"
+	"github.com/prebid/prebid-server/v3/config"
+	"github.com/prebid/prebid-server/v3/metrics"
+	"github.com/prebid/prebid-server/v3/openrtb_ext"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func testConfig(directory string) config.FileSink {
+	return config.FileSink{
+		Enabled:     true,
+		Directory:   directory,
+		Prefix:      "pbs",
+		Compression: config.FileSinkCompressionGzip,
+		Rotation:    config.FileSinkRotation{MaxSizeBytes: 1024 * 1024, MaxAgeSeconds: 60},
+		QueueSize:   10,
+	}
+}
+
+func testAuctionObject(requestID string) *analytics.AuctionObject {
+	return &analytics.AuctionObject{
+		RequestWrapper: &openrtb_ext.RequestWrapper{BidRequest: &openrtb2.BidRequest{
+			ID:   requestID,
+			Site: &openrtb2.Site{Publisher: &openrtb2.Publisher{ID: "publisher"}},
+			Imp:  []openrtb2.Imp{{ID: "imp", Ext: json.RawMessage(`{"prebid":{"bidder":{"appnexus":{},"rubicon":{}}}}`)}},
+		}},
+		Response: &openrtb2.BidResponse{
+			ID:      requestID,
+			Cur:     "USD",
+			SeatBid: []openrtb2.SeatBid{{Seat: "appnexus", Bid: []openrtb2.Bid{{ID: "bid", ImpID: "imp", Price: 1.5}}}},
+		},
+	}
+}
+
+func newMetricsEngine() *metrics.MetricsEngineMock {
+	metricsEngine := &metrics.MetricsEngineMock{}
+	metricsEngine.On("RecordAnalyticsEvents", metricsName, mock.Anything, mock.Anything).Return()
+	return metricsEngine
+}
+
+// segmentsHook collects the closed segments
+type segmentsHook chan Segment
+
+func (h segmentsHook) SegmentClosed(segment Segment) error {
+	h <- segment
+	return nil
+}
+
+func readFile(t *testing.T, path string, compression string) []byte {
+	file, err := os.Open(path)
+	require.NoError(t, err)
+	defer file.Close()
+
+	var reader io.Reader = file
+	switch compression {
+	case config.FileSinkCompressionGzip:
+		gzipReader, err := gzip.NewReader(file)
+		require.NoError(t, err)
+		reader = gzipReader
+	case config.FileSinkCompressionZstd:
+		zstdReader, err := zstd.NewReader(file)
+		require.NoError(t, err)
+		defer zstdReader.Close()
+		reader = zstdReader
+	}
+	data, err := io.ReadAll(reader)
+	require.NoError(t, err)
+	return data
+}
+
+func readRecords(t *testing.T, path string, compression string) []Record {
+	var records []Record
+	scanner := bufio.NewScanner(strings.NewReader(string(readFile(t, path, compression))))
+	for scanner.Scan() {
+		var record Record
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &record))
+		records = append(records, record)
+	}
+	return records
+}
+
+func TestWriteSegment(t *testing.T) {
+	testCases := []struct {
+		compression       string
+		expectedExtension string
+	}{
+		{compression: config.FileSinkCompressionNone, expectedExtension: ""},
+		{compression: config.FileSinkCompressionGzip, expectedExtension: ".gz"},
+		{compression: config.FileSinkCompressionZstd, expectedExtension: ".zst"},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.compression, func(t *testing.T) {
+			cfg := testConfig(filepath.Join(t.TempDir(), "segments"))
+			cfg.Compression = test.compression
+			cfg.CSV = true
+
+			hook := make(segmentsHook, 1)
+			clockMock := clock.NewMock()
+			clockMock.Set(time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC))
+			sink, err := NewModule(cfg, newMetricsEngine(), clockMock, hook)
+			require.NoError(t, err)
+
+			sink.LogAuctionObject(testAuctionObject("request1"))
+			sink.LogCookieSyncObject(&analytics.CookieSyncObject{})
+			sink.Shutdown()
+
+			segment := <-hook
+			assert.Equal(t, filepath.Join(cfg.Directory, "pbs-20240501T120000.000Z-000001.v1.jsonl"+test.expectedExtension), segment.Path)
+			assert.Equal(t, filepath.Join(cfg.Directory, "pbs-20240501T120000.000Z-000001.v1.csv"+test.expectedExtension), segment.CSVPath)
+			assert.Equal(t, 2, segment.Records)
+
+			records := readRecords(t, segment.Path, test.compression)
+			require.Len(t, records, 2)
+			assert.Equal(t, "appnexus", records[0].Bidder)
+			assert.Equal(t, RecordStatusBid, records[0].Status)
+			assert.Equal(t, "rubicon", records[1].Bidder)
+			assert.Equal(t, RecordStatusNoBid, records[1].Status)
+
+			rows, err := csv.NewReader(strings.NewReader(string(readFile(t, segment.CSVPath, test.compression)))).ReadAll()
+			require.NoError(t, err)
+			require.Len(t, rows, 3)
+			assert.Equal(t, columns, rows[0])
+			assert.Equal(t, "appnexus", rows[1][6])
+
+			entries, err := os.ReadDir(cfg.Directory)
+			require.NoError(t, err)
+			assert.Len(t, entries, 2, "the temporary files should be renamed")
+		})
+	}
+}
+
+func TestRotateBySize(t *testing.T) {
+	cfg := testConfig(t.TempDir())
+	cfg.Rotation.MaxSizeBytes = 1
+
+	hook := make(segmentsHook, 2)
+	sink, err := NewModule(cfg, newMetricsEngine(), clock.NewMock(), hook)
+	require.NoError(t, err)
+
+	sink.LogAuctionObject(testAuctionObject("request1"))
+	sink.LogAuctionObject(testAuctionObject("request2"))
+
+	first, second := <-hook, <-hook
+	assert.Equal(t, "request1", readRecords(t, first.Path, cfg.Compression)[0].RequestID)
+	assert.Equal(t, "request2", readRecords(t, second.Path, cfg.Compression)[0].RequestID)
+	assert.NotEqual(t, first.Path, second.Path)
+
+	sink.Shutdown()
+	assert.Empty(t, hook, "no segment should be open on shutdown")
+}
+
+func TestRotateByAge(t *testing.T) {
+	cfg := testConfig(t.TempDir())
+
+	hook := make(segmentsHook, 1)
+	clockMock := clock.NewMock()
+	sink, err := NewModule(cfg, newMetricsEngine(), clockMock, hook)
+	require.NoError(t, err)
+	defer sink.Shutdown()
+
+	sink.LogAuctionObject(testAuctionObject("request1"))
+
+	var segment Segment
+	assert.Eventually(t, func() bool {
+		clockMock.Add(time.Minute)
+		select {
+		case segment = <-hook:
+			return true
+		default:
+			return false
+		}
+	}, time.Second, 5*time.Millisecond)
+	assert.Equal(t, 2, segment.Records)
+	assert.Equal(t, time.Minute, segment.Closed.Sub(segment.Opened))
+}
+
+func TestRetention(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	testCases := []struct {
+		description   string
+		retention     config.FileSinkRetention
+		expectedFiles []string
+	}{
+		{
+			description: "keep_all",
+			expectedFiles: []string{
+				"other.jsonl",
+				"pbs-20240101T000000.000Z-000001.v1.csv.gz",
+				"pbs-20240101T000000.000Z-000001.v1.jsonl.gz",
+				"pbs-20240501T100000.000Z-000001.v1.jsonl.gz",
+				"pbs-20240501T120000.000Z-000001.v1.jsonl.gz",
+				"pbs-20240501T120000.000Z-000002.v1.jsonl.gz.tmp",
+			},
+		},
+		{
+			description: "max_segments",
+			retention:   config.FileSinkRetention{MaxSegments: 2},
+			expectedFiles: []string{
+				"other.jsonl",
+				"pbs-20240501T100000.000Z-000001.v1.jsonl.gz",
+				"pbs-20240501T120000.000Z-000001.v1.jsonl.gz",
+				"pbs-20240501T120000.000Z-000002.v1.jsonl.gz.tmp",
+			},
+		},
+		{
+			description: "max_age",
+			retention:   config.FileSinkRetention{MaxAgeHours: 24},
+			expectedFiles: []string{
+				"other.jsonl",
+				"pbs-20240501T100000.000Z-000001.v1.jsonl.gz",
+				"pbs-20240501T120000.000Z-000001.v1.jsonl.gz",
+				"pbs-20240501T120000.000Z-000002.v1.jsonl.gz.tmp",
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.description, func(t *testing.T) {
+			cfg := testConfig(t.TempDir())
+			cfg.Retention = test.retention
+
+			oldFiles := map[string]time.Time{
+				"other.jsonl": now.Add(-1000 * time.Hour),
+				"pbs-20240101T000000.000Z-000001.v1.csv.gz":       now.Add(-1000 * time.Hour),
+				"pbs-20240101T000000.000Z-000001.v1.jsonl.gz":     now.Add(-1000 * time.Hour),
+				"pbs-20240501T100000.000Z-000001.v1.jsonl.gz":     now.Add(-2 * time.Hour),
+				"pbs-20240501T120000.000Z-000002.v1.jsonl.gz.tmp": now.Add(-1000 * time.Hour),
+			}
+			for name, modTime := range oldFiles {
+				path := filepath.Join(cfg.Directory, name)
+				require.NoError(t, os.WriteFile(path, nil, 0644))
+				require.NoError(t, os.Chtimes(path, modTime, modTime))
+			}
+
+			clockMock := clock.NewMock()
+			clockMock.Set(now)
+			sink, err := NewModule(cfg, newMetricsEngine(), clockMock)
+			require.NoError(t, err)
+			sink.LogAuctionObject(testAuctionObject("request1"))
+			sink.Shutdown()
+
+			entries, err := os.ReadDir(cfg.Directory)
+			require.NoError(t, err)
+			var files []string
+			for _, entry := range entries {
+				files = append(files, entry.Name())
+			}
+			assert.Equal(t, test.expectedFiles, files)
+		})
+	}
+}
+
+func TestDropWhenQueueIsFull(t *testing.T) {
+	metricsEngine := &metrics.MetricsEngineMock{}
+	metricsEngine.On("RecordAnalyticsEvents", metricsName, metrics.AnalyticsEventDropped, 2).Return()
+
+	sink := &FileSink{clock: clock.NewMock(), metricsEngine: metricsEngine, queue: make(chan []Record, 1)}
+	sink.LogAuctionObject(testAuctionObject("request1"))
+	sink.LogAuctionObject(testAuctionObject("request2"))
+
+	assert.Len(t, sink.queue, 1)
+	metricsEngine.AssertNumberOfCalls(t, "RecordAnalyticsEvents", 1)
+}
+
+func TestCommandHook(t *testing.T) {
+	directory := t.TempDir()
+	segment := Segment{Path: filepath.Join(directory, "segment.jsonl"), CSVPath: filepath.Join(directory, "segment.csv")}
+
+	hook := newCommandHook(config.FileSinkHook{Command: []string{"sh", "-c", `echo "$0 $1" > "$0.shipped"`}})
+	assert.NoError(t, hook.SegmentClosed(segment))
+	shipped, err := os.ReadFile(segment.Path + ".shipped")
+	require.NoError(t, err)
+	assert.Equal(t, segment.Path+" "+segment.CSVPath+"\n", string(shipped))
+
+	failingHook := newCommandHook(config.FileSinkHook{Command: []string{"sh", "-c", "echo oops; exit 1"}})
+	assert.EqualError(t, failingHook.SegmentClosed(segment), "sh failed: exit status 1: oops\n")
+}
@@ -0,0 +1,293 @@
+package httpanalytics
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/benbjohnson/clock"
+	"github.com/golang/glog"
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+
+	"github.com/prebid/prebid-server/v3/config"
+	"github.com/prebid/prebid-server/v3/metrics"
+	"github.com/prebid/prebid-server/v3/version"
+)
+
+const (
+	defaultTimeout       = 2 * time.Second
+	defaultQueueSize     = 1000
+	defaultBufferCount   = 100
+	defaultBufferSize    = 1024 * 1024
+	defaultBufferTimeout = 5 * time.Second
+	defaultBackoff       = 100 * time.Millisecond
+	defaultMaxBackoff    = 5 * time.Second
+)
+
+// field is a field of the posted events, whose value is at path in the analytics events
+type field struct {
+	name string
+	path string
+}
+
+// destination posts the events it accepts in batches. The events are queued, and a goroutine batches them
+// and posts the batches, so that a slow destination drops the events rather than slowing down the requests.
+type destination struct {
+	name     string
+	url      string
+	headers  map[string]string
+	timeout  time.Duration
+	gzip     bool
+	events   map[string]bool
+	rate     float64
+	accounts map[string]float64
+	fields   []field
+
+	maxCount      int
+	maxSize       int
+	flushInterval time.Duration
+	maxRetries    int
+	backoff       time.Duration
+	maxBackoff    time.Duration
+
+	httpClient    *http.Client
+	clock         clock.Clock
+	random        func() float64
+	metricsEngine metrics.MetricsEngine
+
+	queue chan []byte
+	stop  chan struct{}
+	done  chan struct{}
+}
+
+func newDestination(cfg config.HTTPAnalyticsDestination, httpClient *http.Client, metricsEngine metrics.MetricsEngine, clock clock.Clock, random func() float64) *destination {
+	d := &destination{
+		name:          cfg.Name,
+		url:           cfg.URL,
+		headers:       cfg.Headers,
+		timeout:       durationOrDefault(cfg.TimeoutMS, defaultTimeout),
+		gzip:          cfg.Gzip,
+		rate:          1,
+		accounts:      cfg.Sampling.Accounts,
+		maxCount:      intOrDefault(cfg.Buffers.Count, defaultBufferCount),
+		maxSize:       intOrDefault(cfg.Buffers.SizeBytes, defaultBufferSize),
+		flushInterval: durationOrDefault(cfg.Buffers.TimeoutMS, defaultBufferTimeout),
+		maxRetries:    cfg.Retry.MaxRetries,
+		backoff:       durationOrDefault(cfg.Retry.BackoffMS, defaultBackoff),
+		maxBackoff:    durationOrDefault(cfg.Retry.MaxBackoffMS, defaultMaxBackoff),
+		httpClient:    httpClient,
+		clock:         clock,
+		random:        random,
+		metricsEngine: metricsEngine,
+		queue:         make(chan []byte, intOrDefault(cfg.QueueSize, defaultQueueSize)),
+		stop:          make(chan struct{}),
+		done:          make(chan struct{}),
+	}
+	if cfg.Sampling.Rate != nil {
+		d.rate = *cfg.Sampling.Rate
+	}
+	if len(cfg.Events) > 0 {
+		d.events = make(map[string]bool, len(cfg.Events))
+		for _, eventType := range cfg.Events {
+			d.events[eventType] = true
+		}
+	}
+	for name, path := range cfg.Fields {
+		d.fields = append(d.fields, field{name: name, path: path})
+	}
+	// the fields are set in order, so that the posted events are stable
+	sort.Slice(d.fields, func(i, j int) bool { return d.fields[i].name < d.fields[j].name })
+	return d
+}
+
+// wants returns true if the destination posts the events of the type
+func (d *destination) wants(eventType string) bool {
+	return d.events == nil || d.events[eventType]
+}
+
+// accepts returns true if the event of the account is sampled in
+func (d *destination) accepts(eventType string, account string) bool {
+	if !d.wants(eventType) {
+		return false
+	}
+	rate := d.rate
+	if accountRate, ok := d.accounts[account]; ok {
+		rate = accountRate
+	}
+	return rate >= 1 || d.random() < rate
+}
+
+// project returns the fields of the event the destination selects, or the whole event if it doesn't select any
+func (d *destination) project(data []byte) ([]byte, error) {
+	if len(d.fields) == 0 {
+		return data, nil
+	}
+	projected := []byte(`{}`)
+	for _, f := range d.fields {
+		value := gjson.GetBytes(data, f.path)
+		if !value.Exists() {
+			continue
+		}
+		var err error
+		if projected, err = sjson.SetRawBytes(projected, f.name, []byte(value.Raw)); err != nil {
+			return nil, fmt.Errorf("the field %s can't be set: %v", f.name, err)
+		}
+	}
+	return projected, nil
+}
+
+// enqueue queues the event to be posted, or drops it if the queue is full
+func (d *destination) enqueue(data []byte) {
+	select {
+	case d.queue <- data:
+	default:
+		d.metricsEngine.RecordAnalyticsEvents(d.name, metrics.AnalyticsEventDropped, 1)
+	}
+}
+
+// run batches the queued events and posts the batches until the destination is stopped. The queued events
+// are then posted before it returns.
+func (d *destination) run() {
+	defer close(d.done)
+	ticker := d.clock.Ticker(d.flushInterval)
+	defer ticker.Stop()
+
+	var batch [][]byte
+	size := 0
+	add := func(data []byte) {
+		batch = append(batch, data)
+		size += len(data)
+		if len(batch) >= d.maxCount || size >= d.maxSize {
+			d.post(batch)
+			batch, size = nil, 0
+		}
+	}
+	flush := func() {
+		if len(batch) > 0 {
+			d.post(batch)
+			batch, size = nil, 0
+		}
+	}
+
+	for {
+		select {
+		case data := <-d.queue:
+			add(data)
+		case <-ticker.C:
+			flush()
+		case <-d.stop:
+			for {
+				select {
+				case data := <-d.queue:
+					add(data)
+				default:
+					flush()
+					return
+				}
+			}
+		}
+	}
+}
+
+// post sends the batch as a JSON array, and retries the failures which may be transient with an exponential
+// backoff. The retries are abandoned once the destination is stopped, so that the shutdown isn't held up.
+func (d *destination) post(batch [][]byte) {
+	body := append([]byte{'['}, bytes.Join(batch, []byte{','})...)
+	body = append(body, ']')
+	if d.gzip {
+		var err error
+		if body, err = compress(body); err != nil {
+			glog.Errorf("[HTTPAnalytics] Compressing the events of %s failed: %v", d.name, err)
+			d.metricsEngine.RecordAnalyticsEvents(d.name, metrics.AnalyticsEventFailed, len(batch))
+			return
+		}
+	}
+
+	backoff := d.backoff
+	for attempt := 0; ; attempt++ {
+		retryable, err := d.send(body)
+		if err == nil {
+			d.metricsEngine.RecordAnalyticsEvents(d.name, metrics.AnalyticsEventSent, len(batch))
+			return
+		}
+		if !retryable || attempt >= d.maxRetries {
+			glog.Warningf("[HTTPAnalytics] Posting %d events to %s failed: %v", len(batch), d.name, err)
+			d.metricsEngine.RecordAnalyticsEvents(d.name, metrics.AnalyticsEventFailed, len(batch))
+			return
+		}
+
+		select {
+		case <-d.clock.After(backoff):
+		case <-d.stop:
+			glog.Warningf("[HTTPAnalytics] Posting %d events to %s failed on shutdown: %v", len(batch), d.name, err)
+			d.metricsEngine.RecordAnalyticsEvents(d.name, metrics.AnalyticsEventFailed, len(batch))
+			return
+		}
+		backoff = min(2*backoff, d.maxBackoff)
+	}
+}
+
+// send posts the body once. The failures of the connection, the 429 and the 5xx responses are retryable.
+func (d *destination) send(body []byte) (retryable bool, err error) {
+	ctx, cancel := context.WithTimeout(context.Background(), d.timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, d.url, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Prebid", version.BuildXPrebidHeader(version.Ver))
+	if d.gzip {
+		req.Header.Set("Content-Encoding", "gzip")
+	}
+	for name, value := range d.headers {
+		req.Header.Set(name, value)
+	}
+
+	resp, err := d.httpClient.Do(req)
+	if err != nil {
+		return true, err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		retryable := resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+		return retryable, fmt.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+	return false, nil
+}
+
+func compress(body []byte) ([]byte, error) {
+	var b bytes.Buffer
+	w := gzip.NewWriter(&b)
+	if _, err := w.Write(body); err != nil {
+		w.Close()
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
+
+func durationOrDefault(ms int, defaultDuration time.Duration) time.Duration {
+	if ms <= 0 {
+		return defaultDuration
+	}
+	return time.Duration(ms) * time.Millisecond
+}
+
+func intOrDefault(value int, defaultValue int) int {
+	if value <= 0 {
+		return defaultValue
+	}
+	return value
+}
@@ -0,0 +1,175 @@
+package httpanalytics
+
+import (
+	"time"
+
+	"github.com/prebid/openrtb/v20/openrtb2"
+	"github.com/prebid/prebid-server/v3/analytics"
+	"github.com/prebid/prebid-server/v3/config"
+	"github.com/prebid/prebid-server/v3/openrtb_ext"
+)
+
+// event is the model of the analytics events, which the fields of the destinations select from. Only the fields
+// of its type are set.
+type event struct {
+	Type      string    `json:"type"`
+	Timestamp time.Time `json:"timestamp"`
+	Account   string    `json:"account,omitempty"`
+	Status    int       `json:"status,omitempty"`
+	Errors    []string  `json:"errors,omitempty"`
+
+	// auction, amp and video
+	StartTime  *time.Time                     `json:"start_time,omitempty"`
+	Request    *openrtb2.BidRequest           `json:"request,omitempty"`
+	Response   *openrtb2.BidResponse          `json:"response,omitempty"`
+	SeatNonBid []openrtb_ext.SeatNonBid       `json:"seatnonbid,omitempty"`
+	Versions   *openrtb_ext.ExtStoredVersions `json:"stored_versions,omitempty"`
+
+	// amp
+	Origin    string            `json:"origin,omitempty"`
+	Targeting map[string]string `json:"targeting,omitempty"`
+
+	// video
+	VideoRequest  *openrtb_ext.BidRequestVideo  `json:"video_request,omitempty"`
+	VideoResponse *openrtb_ext.BidResponseVideo `json:"video_response,omitempty"`
+
+	// cookie_sync
+	Bidders []*analytics.CookieSyncBidder `json:"bidders,omitempty"`
+
+	// setuid
+	Bidder  string `json:"bidder,omitempty"`
+	UID     string `json:"uid,omitempty"`
+	Success *bool  `json:"success,omitempty"`
+
+	// event
+	Notification *analytics.EventRequest `json:"notification,omitempty"`
+}
+
+func newAuctionEvent(ao *analytics.AuctionObject, now time.Time) *event {
+	return &event{
+		Type:       config.HTTPAnalyticsEventAuction,
+		Timestamp:  now,
+		Account:    accountID(ao.Account, ao.RequestWrapper),
+		Status:     ao.Status,
+		Errors:     errorMessages(ao.Errors),
+		StartTime:  startTime(ao.StartTime),
+		Request:    bidRequest(ao.RequestWrapper),
+		Response:   ao.Response,
+		SeatNonBid: ao.SeatNonBid,
+		Versions:   ao.StoredVersions,
+	}
+}
+
+func newAmpEvent(ao *analytics.AmpObject, now time.Time) *event {
+	return &event{
+		Type:       config.HTTPAnalyticsEventAMP,
+		Timestamp:  now,
+		Account:    accountID(nil, ao.RequestWrapper),
+		Status:     ao.Status,
+		Errors:     errorMessages(ao.Errors),
+		StartTime:  startTime(ao.StartTime),
+		Request:    bidRequest(ao.RequestWrapper),
+		Response:   ao.AuctionResponse,
+		SeatNonBid: ao.SeatNonBid,
+		Versions:   ao.StoredVersions,
+		Origin:     ao.Origin,
+		Targeting:  ao.AmpTargetingValues,
+	}
+}
+
+func newVideoEvent(vo *analytics.VideoObject, now time.Time) *event {
+	return &event{
+		Type:          config.HTTPAnalyticsEventVideo,
+		Timestamp:     now,
+		Account:       accountID(nil, vo.RequestWrapper),
+		Status:        vo.Status,
+		Errors:        errorMessages(vo.Errors),
+		StartTime:     startTime(vo.StartTime),
+		Request:       bidRequest(vo.RequestWrapper),
+		Response:      vo.Response,
+		SeatNonBid:    vo.SeatNonBid,
+		VideoRequest:  vo.VideoRequest,
+		VideoResponse: vo.VideoResponse,
+	}
+}
+
+func newCookieSyncEvent(cso *analytics.CookieSyncObject, now time.Time) *event {
+	return &event{
+		Type:      config.HTTPAnalyticsEventCookieSync,
+		Timestamp: now,
+		Status:    cso.Status,
+		Errors:    errorMessages(cso.Errors),
+		Bidders:   cso.BidderStatus,
+	}
+}
+
+func newSetUIDEvent(so *analytics.SetUIDObject, now time.Time) *event {
+	success := so.Success
+	return &event{
+		Type:      config.HTTPAnalyticsEventSetUID,
+		Timestamp: now,
+		Status:    so.Status,
+		Errors:    errorMessages(so.Errors),
+		Bidder:    so.Bidder,
+		UID:       so.UID,
+		Success:   &success,
+	}
+}
+
+func newNotificationEvent(ne *analytics.NotificationEvent, now time.Time) *event {
+	e := &event{
+		Type:         config.HTTPAnalyticsEventNotification,
+		Timestamp:    now,
+		Notification: ne.Request,
+	}
+	if ne.Account != nil {
+		e.Account = ne.Account.ID
+	} else if ne.Request != nil {
+		e.Account = ne.Request.AccountID
+	}
+	return e
+}
+
+// accountID returns the ID of the account, or else the publisher ID of the request
+func accountID(account *config.Account, requestWrapper *openrtb_ext.RequestWrapper) string {
+	if account != nil && account.ID != "" {
+		return account.ID
+	}
+	request := bidRequest(requestWrapper)
+	switch {
+	case request == nil:
+		return ""
+	case request.Site != nil && request.Site.Publisher != nil:
+		return request.Site.Publisher.ID
+	case request.App != nil && request.App.Publisher != nil:
+		return request.App.Publisher.ID
+	case request.DOOH != nil && request.DOOH.Publisher != nil:
+		return request.DOOH.Publisher.ID
+	}
+	return ""
+}
+
+func bidRequest(requestWrapper *openrtb_ext.RequestWrapper) *openrtb2.BidRequest {
+	if requestWrapper == nil {
+		return nil
+	}
+	return requestWrapper.BidRequest
+}
+
+func startTime(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	return &t
+}
+
+func errorMessages(errs []error) []string {
+	if len(errs) == 0 {
+		return nil
+	}
+	messages := make([]string, len(errs))
+	for i, err := range errs {
+		messages[i] = err.Error()
+	}
+	return messages
+}
@@ -0,0 +1,129 @@
+// Package httpanalytics implements a generic analytics module, which posts the analytics events to the HTTP
+// destinations of its config. Each destination selects the event types and fields it receives, and samples them.
+package httpanalytics
+
+import (
+	"errors"
+	"math/rand"
+	"net/http"
+	"sync"
+
+	"github.com/benbjohnson/clock"
+	"github.com/golang/glog"
+
+	"github.com/prebid/prebid-server/v3/analytics"
+	"github.com/prebid/prebid-server/v3/config"
+	"github.com/prebid/prebid-server/v3/metrics"
+	"github.com/prebid/prebid-server/v3/util/jsonutil"
+)
+
+type HTTPLogger struct {
+	destinations []*destination
+	clock        clock.Clock
+	shutdownOnce sync.Once
+}
+
+// NewModule returns the module posting the events to the destinations of the config
+func NewModule(httpClient *http.Client, cfg config.HTTPAnalytics, metricsEngine metrics.MetricsEngine, clock clock.Clock) (analytics.Module, error) {
+	return newHTTPLogger(httpClient, cfg, metricsEngine, clock, rand.Float64)
+}
+
+func newHTTPLogger(httpClient *http.Client, cfg config.HTTPAnalytics, metricsEngine metrics.MetricsEngine, clock clock.Clock, random func() float64) (*HTTPLogger, error) {
+	if len(cfg.Destinations) == 0 {
+		return nil, errors.New("Please configure at least one destination for the HTTP Analytics")
+	}
+
+	l := &HTTPLogger{clock: clock}
+	for _, destinationCfg := range cfg.Destinations {
+		d := newDestination(destinationCfg, httpClient, metricsEngine, clock, random)
+		l.destinations = append(l.destinations, d)
+		go d.run()
+	}
+	return l, nil
+}
+
+// log queues the event for the destinations which accept it. The event is only built and marshaled if a
+// destination wants its type.
+func (l *HTTPLogger) log(eventType string, newEvent func() *event) {
+	var e *event
+	var data []byte
+	for _, d := range l.destinations {
+		if !d.wants(eventType) {
+			continue
+		}
+		if e == nil {
+			e = newEvent()
+		}
+		if !d.accepts(eventType, e.Account) {
+			continue
+		}
+		if data == nil {
+			var err error
+			if data, err = jsonutil.Marshal(e); err != nil {
+				glog.Errorf("[HTTPAnalytics] Error serializing %s event: %v", eventType, err)
+				return
+			}
+		}
+		projected, err := d.project(data)
+		if err != nil {
+			glog.Errorf("[HTTPAnalytics] Error selecting the fields of %s event for %s: %v", eventType, d.name, err)
+			continue
+		}
+		d.enqueue(projected)
+	}
+}
+
+func (l *HTTPLogger) LogAuctionObject(ao *analytics.AuctionObject) {
+	if ao == nil {
+		return
+	}
+	l.log(config.HTTPAnalyticsEventAuction, func() *event { return newAuctionEvent(ao, l.clock.Now()) })
+}
+
+func (l *HTTPLogger) LogAmpObject(ao *analytics.AmpObject) {
+	if ao == nil {
+		return
+	}
+	l.log(config.HTTPAnalyticsEventAMP, func() *event { return newAmpEvent(ao, l.clock.Now()) })
+}
+
+func (l *HTTPLogger) LogVideoObject(vo *analytics.VideoObject) {
+	if vo == nil {
+		return
+	}
+	l.log(config.HTTPAnalyticsEventVideo, func() *event { return newVideoEvent(vo, l.clock.Now()) })
+}
+
+func (l *HTTPLogger) LogCookieSyncObject(cso *analytics.CookieSyncObject) {
+	if cso == nil {
+		return
+	}
+	l.log(config.HTTPAnalyticsEventCookieSync, func() *event { return newCookieSyncEvent(cso, l.clock.Now()) })
+}
+
+func (l *HTTPLogger) LogSetUIDObject(so *analytics.SetUIDObject) {
+	if so == nil {
+		return
+	}
+	l.log(config.HTTPAnalyticsEventSetUID, func() *event { return newSetUIDEvent(so, l.clock.Now()) })
+}
+
+func (l *HTTPLogger) LogNotificationEventObject(ne *analytics.NotificationEvent) {
+	if ne == nil {
+		return
+	}
+	l.log(config.HTTPAnalyticsEventNotification, func() *event { return newNotificationEvent(ne, l.clock.Now()) })
+}
+
+// Shutdown posts the queued events of every destination
+func (l *HTTPLogger) Shutdown() {
+	l.shutdownOnce.Do(func() {
+		glog.Info("[HTTPAnalytics] Shutdown, trying to post the queued events")
+		for _, d := range l.destinations {
+			close(d.stop)
+		}
+		for _, d := range l.destinations {
+			<-d.done
+		}
+	})
+}
@@ -0,0 +1,359 @@
+package httpanalytics
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/benbjohnson/clock"
+	"github.com/prebid/openrtb/v20/openrtb2"
+	"github.com/prebid/prebid-server/v3/analytics"
+	"github.com/prebid/prebid-server/v3/config"
+	"github.com/prebid/prebid-server/v3/metrics"
+	"github.com/prebid/prebid-server/v3/openrtb_ext"
+	"github.com/prebid/prebid-server/v3/util/ptrutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingServer records the posts it receives, and responds with the status codes in turn
+type recordingServer struct {
+	*httptest.Server
+	mu       sync.Mutex
+	bodies   []string
+	headers  []http.Header
+	statuses []int
+}
+
+func newRecordingServer(statuses ...int) *recordingServer {
+	s := &recordingServer{statuses: statuses}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if r.Header.Get("Content-Encoding") == "gzip" {
+			reader, _ := gzip.NewReader(bytes.NewReader(body))
+			body, _ = io.ReadAll(reader)
+		}
+
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		s.bodies = append(s.bodies, string(body))
+		s.headers = append(s.headers, r.Header.Clone())
+		status := http.StatusOK
+		if len(s.statuses) > 0 {
+			status, s.statuses = s.statuses[0], s.statuses[1:]
+		}
+		w.WriteHeader(status)
+	}))
+	return s
+}
+
+func (s *recordingServer) posts() ([]string, []http.Header) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.bodies...), append([]http.Header(nil), s.headers...)
+}
+
+func auctionObject(account string) *analytics.AuctionObject {
+	return &analytics.AuctionObject{
+		Status: http.StatusOK,
+		RequestWrapper: &openrtb_ext.RequestWrapper{BidRequest: &openrtb2.BidRequest{
+			ID:   "request",
+			Site: &openrtb2.Site{Publisher: &openrtb2.Publisher{ID: account}},
+		}},
+		Response: &openrtb2.BidResponse{
+			ID:      "request",
+			SeatBid: []openrtb2.SeatBid{{Seat: "appnexus", Bid: []openrtb2.Bid{{ID: "bid", Price: 1.5}}}},
+		},
+	}
+}
+
+func TestNewModuleWithoutDestinations(t *testing.T) {
+	module, err := NewModule(http.DefaultClient, config.HTTPAnalytics{Enabled: true}, &metrics.MetricsEngineMock{}, clock.NewMock())
+	assert.Nil(t, module)
+	assert.EqualError(t, err, "Please configure at least one destination for the HTTP Analytics")
+}
+
+func TestPostOnBufferCount(t *testing.T) {
+	server := newRecordingServer()
+	defer server.Close()
+
+	metricsEngine := &metrics.MetricsEngineMock{}
+	metricsEngine.On("RecordAnalyticsEvents", "warehouse", metrics.AnalyticsEventSent, 2).Return()
+
+	cfg := config.HTTPAnalytics{Enabled: true, Destinations: []config.HTTPAnalyticsDestination{
+		{
+			Name:    "warehouse",
+			URL:     server.URL,
+			Headers: map[string]string{"Authorization": "Bearer token"},
+			Buffers: config.HTTPAnalyticsBuffers{Count: 2},
+			Fields:  map[string]string{"type": "type", "account": "account"},
+		},
+	}}
+	logger, err := newHTTPLogger(server.Client(), cfg, metricsEngine, clock.NewMock(), nil)
+	require.NoError(t, err)
+
+	logger.LogAuctionObject(auctionObject("1001"))
+	logger.LogAuctionObject(auctionObject("1002"))
+
+	assert.Eventually(t, func() bool {
+		bodies, _ := server.posts()
+		return len(bodies) == 1
+	}, time.Second, 5*time.Millisecond)
+
+	bodies, headers := server.posts()
+	assert.JSONEq(t, `[{"type":"auction","account":"1001"},{"type":"auction","account":"1002"}]`, bodies[0])
+	assert.Equal(t, "application/json", headers[0].Get("Content-Type"))
+	assert.Equal(t, "Bearer token", headers[0].Get("Authorization"))
+	assert.NotEmpty(t, headers[0].Get("X-Prebid"))
+
+	logger.Shutdown()
+	metricsEngine.AssertExpectations(t)
+}
+
+func TestPostOnTimeout(t *testing.T) {
+	server := newRecordingServer()
+	defer server.Close()
+
+	metricsEngine := &metrics.MetricsEngineMock{}
+	metricsEngine.On("RecordAnalyticsEvents", "warehouse", metrics.AnalyticsEventSent, 1).Return()
+
+	clockMock := clock.NewMock()
+	cfg := config.HTTPAnalytics{Enabled: true, Destinations: []config.HTTPAnalyticsDestination{
+		{
+			Name:    "warehouse",
+			URL:     server.URL,
+			Buffers: config.HTTPAnalyticsBuffers{TimeoutMS: 1000},
+			Fields:  map[string]string{"account": "account"},
+		},
+	}}
+	logger, err := newHTTPLogger(server.Client(), cfg, metricsEngine, clockMock, nil)
+	require.NoError(t, err)
+
+	logger.LogAuctionObject(auctionObject("1001"))
+
+	assert.Eventually(t, func() bool {
+		clockMock.Add(time.Second)
+		bodies, _ := server.posts()
+		return len(bodies) == 1
+	}, time.Second, 5*time.Millisecond)
+
+	bodies, _ := server.posts()
+	assert.JSONEq(t, `[{"account":"1001"}]`, bodies[0])
+
+	logger.Shutdown()
+	metricsEngine.AssertExpectations(t)
+}
+
+func TestShutdownPostsQueuedEvents(t *testing.T) {
+	server := newRecordingServer()
+	defer server.Close()
+
+	metricsEngine := &metrics.MetricsEngineMock{}
+	metricsEngine.On("RecordAnalyticsEvents", "auctions", metrics.AnalyticsEventSent, 1).Return()
+	metricsEngine.On("RecordAnalyticsEvents", "syncs", metrics.AnalyticsEventSent, 2).Return()
+
+	cfg := config.HTTPAnalytics{Enabled: true, Destinations: []config.HTTPAnalyticsDestination{
+		{
+			Name:   "auctions",
+			URL:    server.URL + "/auctions",
+			Events: []string{config.HTTPAnalyticsEventAuction},
+			Fields: map[string]string{"account": "account", "seats": "response.seatbid.#.seat"},
+		},
+		{
+			Name:   "syncs",
+			URL:    server.URL + "/syncs",
+			Events: []string{config.HTTPAnalyticsEventCookieSync, config.HTTPAnalyticsEventSetUID},
+			Gzip:   true,
+			Fields: map[string]string{"type": "type", "bidder": "bidder", "success": "success"},
+		},
+	}}
+	logger, err := newHTTPLogger(server.Client(), cfg, metricsEngine, clock.NewMock(), nil)
+	require.NoError(t, err)
+
+	logger.LogAuctionObject(auctionObject("1001"))
+	logger.LogCookieSyncObject(&analytics.CookieSyncObject{Status: http.StatusOK})
+	logger.LogSetUIDObject(&analytics.SetUIDObject{Status: http.StatusOK, Bidder: "appnexus", Success: true})
+	logger.LogNotificationEventObject(&analytics.NotificationEvent{Request: &analytics.EventRequest{AccountID: "1001"}})
+	logger.Shutdown()
+
+	bodies, headers := server.posts()
+	require.Len(t, bodies, 2)
+	for i, body := range bodies {
+		if headers[i].Get("Content-Encoding") == "gzip" {
+			assert.JSONEq(t, `[{"type":"cookie_sync"},{"type":"setuid","bidder":"appnexus","success":true}]`, body)
+		} else {
+			assert.JSONEq(t, `[{"account":"1001","seats":["appnexus"]}]`, body)
+		}
+	}
+	metricsEngine.AssertExpectations(t)
+}
+
+func TestSampling(t *testing.T) {
+	testCases := []struct {
+		description string
+		sampling    config.HTTPAnalyticsSampling
+		account     string
+		random      float64
+		expected    bool
+	}{
+		{
+			description: "default_rate",
+			account:     "1001",
+			random:      0.99,
+			expected:    true,
+		},
+		{
+			description: "sampled_in",
+			sampling:    config.HTTPAnalyticsSampling{Rate: ptrutil.ToPtr(0.5)},
+			account:     "1001",
+			random:      0.25,
+			expected:    true,
+		},
+		{
+			description: "sampled_out",
+			sampling:    config.HTTPAnalyticsSampling{Rate: ptrutil.ToPtr(0.5)},
+			account:     "1001",
+			random:      0.75,
+			expected:    false,
+		},
+		{
+			description: "zero_rate",
+			sampling:    config.HTTPAnalyticsSampling{Rate: ptrutil.ToPtr(0.0)},
+			account:     "1001",
+			random:      0,
+			expected:    false,
+		},
+		{
+			description: "account_rate",
+			sampling:    config.HTTPAnalyticsSampling{Rate: ptrutil.ToPtr(0.0), Accounts: map[string]float64{"1001": 1}},
+			account:     "1001",
+			random:      0.99,
+			expected:    true,
+		},
+		{
+			description: "other_account",
+			sampling:    config.HTTPAnalyticsSampling{Rate: ptrutil.ToPtr(0.0), Accounts: map[string]float64{"1001": 1}},
+			account:     "1002",
+			random:      0,
+			expected:    false,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.description, func(t *testing.T) {
+			cfg := config.HTTPAnalyticsDestination{Name: "warehouse", Sampling: test.sampling}
+			d := newDestination(cfg, http.DefaultClient, &metrics.MetricsEngineMock{}, clock.NewMock(), func() float64 { return test.random })
+			assert.Equal(t, test.expected, d.accepts(config.HTTPAnalyticsEventAuction, test.account))
+		})
+	}
+}
+
+func TestProject(t *testing.T) {
+	testCases := []struct {
+		description string
+		fields      map[string]string
+		expected    string
+	}{
+		{
+			description: "whole_event",
+			expected:    `{"type":"auction","account":"1001","response":{"seatbid":[{"seat":"appnexus"},{"seat":"rubicon"}]}}`,
+		},
+		{
+			description: "selected_fields",
+			fields:      map[string]string{"account": "account", "seats": "response.seatbid.#.seat", "publisher.id": "account"},
+			expected:    `{"account":"1001","publisher":{"id":"1001"},"seats":["appnexus","rubicon"]}`,
+		},
+		{
+			description: "missing_fields",
+			fields:      map[string]string{"account": "account", "origin": "origin"},
+			expected:    `{"account":"1001"}`,
+		},
+	}
+
+	event := []byte(`{"type":"auction","account":"1001","response":{"seatbid":[{"seat":"appnexus"},{"seat":"rubicon"}]}}`)
+	for _, test := range testCases {
+		t.Run(test.description, func(t *testing.T) {
+			d := newDestination(config.HTTPAnalyticsDestination{Fields: test.fields}, http.DefaultClient, &metrics.MetricsEngineMock{}, clock.NewMock(), nil)
+			projected, err := d.project(event)
+			assert.NoError(t, err)
+			assert.JSONEq(t, test.expected, string(projected))
+		})
+	}
+}
+
+func TestEnqueueDropsWhenQueueIsFull(t *testing.T) {
+	metricsEngine := &metrics.MetricsEngineMock{}
+	metricsEngine.On("RecordAnalyticsEvents", "warehouse", metrics.AnalyticsEventDropped, 1).Return()
+
+	d := newDestination(config.HTTPAnalyticsDestination{Name: "warehouse", QueueSize: 1}, http.DefaultClient, metricsEngine, clock.NewMock(), nil)
+	d.enqueue([]byte(`{"type":"auction"}`))
+	d.enqueue([]byte(`{"type":"auction"}`))
+
+	assert.Len(t, d.queue, 1)
+	metricsEngine.AssertNumberOfCalls(t, "RecordAnalyticsEvents", 1)
+}
+
+func TestPostRetries(t *testing.T) {
+	testCases := []struct {
+		description      string
+		statuses         []int
+		maxRetries       int
+		expectedAttempts int
+		expectedStatus   metrics.AnalyticsEventStatus
+	}{
+		{
+			description:      "success",
+			expectedAttempts: 1,
+			expectedStatus:   metrics.AnalyticsEventSent,
+		},
+		{
+			description:      "success_after_retries",
+			statuses:         []int{http.StatusServiceUnavailable, http.StatusTooManyRequests},
+			maxRetries:       2,
+			expectedAttempts: 3,
+			expectedStatus:   metrics.AnalyticsEventSent,
+		},
+		{
+			description:      "retries_exhausted",
+			statuses:         []int{http.StatusInternalServerError, http.StatusInternalServerError, http.StatusInternalServerError},
+			maxRetries:       2,
+			expectedAttempts: 3,
+			expectedStatus:   metrics.AnalyticsEventFailed,
+		},
+		{
+			description:      "not_retryable",
+			statuses:         []int{http.StatusBadRequest},
+			maxRetries:       2,
+			expectedAttempts: 1,
+			expectedStatus:   metrics.AnalyticsEventFailed,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.description, func(t *testing.T) {
+			server := newRecordingServer(test.statuses...)
+			defer server.Close()
+
+			metricsEngine := &metrics.MetricsEngineMock{}
+			metricsEngine.On("RecordAnalyticsEvents", "warehouse", test.expectedStatus, 2).Return()
+
+			cfg := config.HTTPAnalyticsDestination{
+				Name:  "warehouse",
+				URL:   server.URL,
+				Retry: config.HTTPAnalyticsRetry{MaxRetries: test.maxRetries, BackoffMS: 1, MaxBackoffMS: 2},
+			}
+			d := newDestination(cfg, server.Client(), metricsEngine, clock.New(), nil)
+			d.post([][]byte{[]byte(`{"type":"auction"}`), []byte(`{"type":"amp"}`)})
+
+			bodies, _ := server.posts()
+			assert.Len(t, bodies, test.expectedAttempts)
+			assert.JSONEq(t, `[{"type":"auction"},{"type":"amp"}]`, bodies[0])
+			metricsEngine.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,100 @@
+package bidnotifications
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/prebid/prebid-server/v3/metrics"
+	"github.com/prebid/prebid-server/v3/openrtb_ext"
+)
+
+// notification is a notification URL to fire
+type notification struct {
+	bidder           openrtb_ext.BidderName
+	notificationType metrics.BidNotificationType
+	url              string
+}
+
+// dispatcher fires the queued notifications with a fixed number of workers, so that the auctions never wait
+// on them. The notifications are dropped once the queue is full.
+type dispatcher struct {
+	httpClient    *http.Client
+	timeout       time.Duration
+	metricsEngine metrics.MetricsEngine
+
+	queue        chan notification
+	stop         chan struct{}
+	workers      sync.WaitGroup
+	shutdownOnce sync.Once
+}
+
+func newDispatcher(httpClient *http.Client, timeout time.Duration, queueSize int, workers int, metricsEngine metrics.MetricsEngine) *dispatcher {
+	d := &dispatcher{
+		httpClient:    httpClient,
+		timeout:       timeout,
+		metricsEngine: metricsEngine,
+		queue:         make(chan notification, queueSize),
+		stop:          make(chan struct{}),
+	}
+	d.workers.Add(workers)
+	for i := 0; i < workers; i++ {
+		go d.run()
+	}
+	return d
+}
+
+func (d *dispatcher) dispatch(n notification) {
+	select {
+	case d.queue <- n:
+	default:
+		d.metricsEngine.RecordBidNotification(n.bidder, n.notificationType, metrics.BidNotificationDropped)
+	}
+}
+
+func (d *dispatcher) run() {
+	defer d.workers.Done()
+	for {
+		select {
+		case n := <-d.queue:
+			d.fire(n)
+		case <-d.stop:
+			return
+		}
+	}
+}
+
+func (d *dispatcher) fire(n notification) {
+	ctx, cancel := context.WithTimeout(context.Background(), d.timeout)
+	defer cancel()
+
+	status := metrics.BidNotificationFailed
+	defer func() {
+		d.metricsEngine.RecordBidNotification(n.bidder, n.notificationType, status)
+	}()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, n.url, nil)
+	if err != nil {
+		return
+	}
+	resp, err := d.httpClient.Do(req)
+	if err != nil {
+		return
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		status = metrics.BidNotificationSent
+	}
+}
+
+// shutdown stops the workers once their current notifications are fired. The queued notifications are dropped.
+func (d *dispatcher) shutdown() {
+	d.shutdownOnce.Do(func() {
+		close(d.stop)
+		d.workers.Wait()
+	})
+}
@@ -0,0 +1,60 @@
+package bidnotifications
+
+import (
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// The OpenRTB substitution macros of the notification URLs
+const (
+	macroAuctionID       = "${AUCTION_ID}"
+	macroAuctionBidID    = "${AUCTION_BID_ID}"
+	macroAuctionImpID    = "${AUCTION_IMP_ID}"
+	macroAuctionSeatID   = "${AUCTION_SEAT_ID}"
+	macroAuctionAdID     = "${AUCTION_AD_ID}"
+	macroAuctionPrice    = "${AUCTION_PRICE}"
+	macroAuctionCurrency = "${AUCTION_CURRENCY}"
+	macroAuctionLoss     = "${AUCTION_LOSS}"
+	macroAuctionMinToWin = "${AUCTION_MIN_TO_WIN}"
+)
+
+// substituteMacros replaces the macros of the notification URL of the bid. ${AUCTION_PRICE} is the price the
+// bidder bid, and ${AUCTION_MIN_TO_WIN} the price it had to bid to tie with the bid which won the imp if the
+// bid was outbid, both in ${AUCTION_CURRENCY}, the currency the bidder bid in. ${AUCTION_LOSS} is 0 for the
+// bids which weren't lost.
+func substituteMacros(notificationURL string, auction Auction, bid Bid) string {
+	if !strings.Contains(notificationURL, "${") {
+		return notificationURL
+	}
+
+	currency := bid.Currency
+	if currency == "" {
+		currency = auction.Currency
+	}
+
+	minToWin := ""
+	if bid.MinToWin > 0 {
+		minToWin = formatPrice(bid.MinToWin)
+	}
+	lossReason := LossReasonWon
+	if bid.Lost {
+		lossReason = bid.LossReason
+	}
+
+	return strings.NewReplacer(
+		macroAuctionID, url.QueryEscape(auction.ID),
+		macroAuctionBidID, url.QueryEscape(bid.ID),
+		macroAuctionImpID, url.QueryEscape(bid.ImpID),
+		macroAuctionSeatID, url.QueryEscape(bid.Seat),
+		macroAuctionAdID, url.QueryEscape(bid.AdID),
+		macroAuctionPrice, formatPrice(bid.Price),
+		macroAuctionCurrency, url.QueryEscape(currency),
+		macroAuctionLoss, strconv.Itoa(int(lossReason)),
+		macroAuctionMinToWin, minToWin,
+	).Replace(notificationURL)
+}
+
+func formatPrice(price float64) string {
+	return strconv.FormatFloat(price, 'f', -1, 64)
+}
@@ -0,0 +1,55 @@
+package bidnotifications
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSubstituteMacros(t *testing.T) {
+	auction := Auction{ID: "auction 1", Currency: "USD"}
+
+	testCases := []struct {
+		name        string
+		url         string
+		bid         Bid
+		expectedURL string
+	}{
+		{
+			name:        "no-macros",
+			url:         "https://bidder.com/win?id=1",
+			bid:         Bid{ID: "bid1", Price: 1},
+			expectedURL: "https://bidder.com/win?id=1",
+		},
+		{
+			name:        "won",
+			url:         "https://bidder.com/win?a=${AUCTION_ID}&b=${AUCTION_BID_ID}&i=${AUCTION_IMP_ID}&s=${AUCTION_SEAT_ID}&ad=${AUCTION_AD_ID}&p=${AUCTION_PRICE}&c=${AUCTION_CURRENCY}&l=${AUCTION_LOSS}&m=${AUCTION_MIN_TO_WIN}",
+			bid:         Bid{ID: "bid&1", ImpID: "imp1", Seat: "seat1", AdID: "ad1", Price: 1.25},
+			expectedURL: "https://bidder.com/win?a=auction+1&b=bid%261&i=imp1&s=seat1&ad=ad1&p=1.25&c=USD&l=0&m=",
+		},
+		{
+			name:        "outbid",
+			url:         "https://bidder.com/loss?l=${AUCTION_LOSS}&p=${AUCTION_PRICE}&m=${AUCTION_MIN_TO_WIN}",
+			bid:         Bid{ID: "bid1", Price: 0.5, Lost: true, LossReason: LossReasonLostToHigherBid, MinToWin: 0.75},
+			expectedURL: "https://bidder.com/loss?l=102&p=0.5&m=0.75",
+		},
+		{
+			name:        "bidder-currency",
+			url:         "https://bidder.com/win?p=${AUCTION_PRICE}&c=${AUCTION_CURRENCY}",
+			bid:         Bid{ID: "bid1", Price: 2.5, Currency: "EUR"},
+			expectedURL: "https://bidder.com/win?p=2.5&c=EUR",
+		},
+		{
+			name:        "rejected",
+			url:         "https://bidder.com/loss?l=${AUCTION_LOSS}&m=${AUCTION_MIN_TO_WIN}",
+			bid:         Bid{ID: "bid1", Price: 0.5, Lost: true, LossReason: LossReasonBelowAuctionFloor},
+			expectedURL: "https://bidder.com/loss?l=100&m=",
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.expectedURL, substituteMacros(test.url, auction, test.bid))
+		})
+	}
+}
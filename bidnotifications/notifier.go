@@ -0,0 +1,190 @@
+// Package bidnotifications fires the notification URLs of the bids on behalf of the clients, many of which
+// drop them. The loss URLs are fired once the auction is over, with the loss reason of the bid. The win and
+// billing URLs of the returned bids are kept until their win and impression events arrive.
+package bidnotifications
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/benbjohnson/clock"
+
+	"github.com/prebid/prebid-server/v3/analytics"
+	"github.com/prebid/prebid-server/v3/config"
+	"github.com/prebid/prebid-server/v3/metrics"
+	"github.com/prebid/prebid-server/v3/openrtb_ext"
+)
+
+// LossReason is the OpenRTB loss reason code of a bid, substituted for the ${AUCTION_LOSS} macro
+type LossReason int
+
+const (
+	LossReasonWon                    LossReason = 0
+	LossReasonInternalError          LossReason = 1
+	LossReasonInvalidBidResponse     LossReason = 3
+	LossReasonBelowAuctionFloor      LossReason = 100
+	LossReasonBelowDealFloor         LossReason = 101
+	LossReasonLostToHigherBid        LossReason = 102
+	LossReasonLostToDealBid          LossReason = 103
+	LossReasonCreativeFiltered       LossReason = 200
+	LossReasonCreativeSizeNotAllowed LossReason = 203
+	LossReasonCreativeNotSecure      LossReason = 207
+)
+
+// Auction is the outcome of the bids of an auction
+type Auction struct {
+	Account *config.Account
+	// ID is the ID of the bid request
+	ID string
+	// Currency is the currency of the auction, of the prices of the bids which don't have their own
+	Currency string
+	Bids     []Bid
+}
+
+// Bid is the outcome of a bid of an auction
+type Bid struct {
+	// Bidder is the adapter of the bid, which opts in the notifications
+	Bidder openrtb_ext.BidderName
+	// Seat is the seat of the bid, which its events refer to as their bidder
+	Seat  string
+	ID    string
+	ImpID string
+	AdID  string
+	// EventBidID is the bid ID its events refer to, which is the ID generated by Prebid Server if any
+	EventBidID string
+	// Price is the price the bidder bid, before the bid adjustments and the currency conversion
+	Price float64
+	// Currency is the currency the bidder bid in, of Price and MinToWin
+	Currency string
+	NURL     string
+	BURL     string
+	LURL     string
+	// Lost is set on the bids which were rejected or outbid
+	Lost       bool
+	LossReason LossReason
+	// MinToWin is the price the bidder had to bid to tie with the bid which won the imp, if the bid was outbid
+	MinToWin float64
+}
+
+// Notifier fires the notification URLs of the bids of the accounts which opted in
+type Notifier interface {
+	// Enabled returns true if the notifications are enabled for the account
+	Enabled(account *config.Account) bool
+	// BidderEnabled returns true if the host and the account opted the bidder in the notifications, whose
+	// notification URLs are then fired by Prebid Server instead of the client
+	BidderEnabled(account *config.Account, bidder openrtb_ext.BidderName) bool
+	// NotifyAuction fires the loss URLs of the losing bids, and keeps the win and billing URLs of the others
+	NotifyAuction(auction Auction)
+	// NotifyEvent fires the win or billing URL of the bid of the event
+	NotifyEvent(event *analytics.EventRequest)
+	// Shutdown stops firing the notifications
+	Shutdown()
+}
+
+type notifier struct {
+	bidders    map[string]bool
+	dispatcher *dispatcher
+	store      *winStore
+}
+
+// NewNotifier returns the notifier of the config, or nil if the notifications are disabled
+func NewNotifier(cfg config.BidNotifications, httpClient *http.Client, metricsEngine metrics.MetricsEngine, clock clock.Clock) Notifier {
+	if !cfg.Enabled {
+		return nil
+	}
+
+	n := &notifier{
+		bidders:    normalizedBidders(cfg.Bidders),
+		dispatcher: newDispatcher(httpClient, time.Duration(cfg.TimeoutMS)*time.Millisecond, cfg.QueueSize, cfg.Workers, metricsEngine),
+		store:      newWinStore(time.Duration(cfg.WinTTLSeconds)*time.Second, cfg.MaxStoredBids, clock),
+	}
+	return n
+}
+
+func normalizedBidders(bidders []string) map[string]bool {
+	normalized := make(map[string]bool, len(bidders))
+	for _, bidder := range bidders {
+		normalized[strings.ToLower(bidder)] = true
+	}
+	return normalized
+}
+
+func (n *notifier) Enabled(account *config.Account) bool {
+	return account != nil && account.BidNotifications.Enabled
+}
+
+func (n *notifier) BidderEnabled(account *config.Account, bidder openrtb_ext.BidderName) bool {
+	if !n.Enabled(account) {
+		return false
+	}
+	name := strings.ToLower(bidder.String())
+	if !n.bidders[name] {
+		return false
+	}
+	if len(account.BidNotifications.Bidders) == 0 {
+		return true
+	}
+	for _, accountBidder := range account.BidNotifications.Bidders {
+		if strings.ToLower(accountBidder) == name {
+			return true
+		}
+	}
+	return false
+}
+
+func (n *notifier) NotifyAuction(auction Auction) {
+	if !n.Enabled(auction.Account) {
+		return
+	}
+	for _, bid := range auction.Bids {
+		if !n.BidderEnabled(auction.Account, bid.Bidder) {
+			continue
+		}
+		if bid.Lost {
+			if bid.LURL != "" {
+				n.dispatcher.dispatch(notification{
+					bidder:           bid.Bidder,
+					notificationType: metrics.BidNotificationLoss,
+					url:              substituteMacros(bid.LURL, auction, bid),
+				})
+			}
+			continue
+		}
+		if bid.NURL == "" && bid.BURL == "" {
+			continue
+		}
+		n.store.put(winKey(auction.Account.ID, bid.EventBidID), storedBid{
+			bidder: bid.Bidder,
+			seat:   bid.Seat,
+			nurl:   substituteMacros(bid.NURL, auction, bid),
+			burl:   substituteMacros(bid.BURL, auction, bid),
+		})
+	}
+}
+
+func (n *notifier) NotifyEvent(event *analytics.EventRequest) {
+	var notificationType metrics.BidNotificationType
+	switch event.Type {
+	case analytics.Win:
+		notificationType = metrics.BidNotificationWin
+	case analytics.Imp:
+		notificationType = metrics.BidNotificationBilling
+	default:
+		return
+	}
+
+	bidder, url, ok := n.store.take(winKey(event.AccountID, event.BidID), event.Bidder, notificationType)
+	if !ok || url == "" {
+		return
+	}
+	n.dispatcher.dispatch(notification{bidder: bidder, notificationType: notificationType, url: url})
+}
+
+func (n *notifier) Shutdown() {
+	n.dispatcher.shutdown()
+}
+
+func winKey(accountID string, bidID string) string {
+	return accountID + "|" + bidID
+}
@@ -0,0 +1,250 @@
+package bidnotifications
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/benbjohnson/clock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/prebid/prebid-server/v3/analytics"
+	"github.com/prebid/prebid-server/v3/config"
+	"github.com/prebid/prebid-server/v3/metrics"
+	"github.com/prebid/prebid-server/v3/openrtb_ext"
+)
+
+// notificationServer records the paths and queries of the notifications it receives
+func notificationServer(t *testing.T, status int) (*httptest.Server, chan string) {
+	received := make(chan string, 10)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received <- r.URL.RequestURI()
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(server.Close)
+	return server, received
+}
+
+func newMetricsEngine(recorded chan metrics.BidNotificationStatus) *metrics.MetricsEngineMock {
+	me := &metrics.MetricsEngineMock{}
+	me.On("RecordBidNotification", mock.Anything, mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		recorded <- args.Get(2).(metrics.BidNotificationStatus)
+	})
+	return me
+}
+
+func newTestNotifier(t *testing.T, me metrics.MetricsEngine, clock clock.Clock) Notifier {
+	n := NewNotifier(config.BidNotifications{
+		Enabled:       true,
+		Bidders:       []string{"appnexus", "RubiCon"},
+		TimeoutMS:     1000,
+		QueueSize:     10,
+		Workers:       1,
+		WinTTLSeconds: 60,
+		MaxStoredBids: 10,
+	}, http.DefaultClient, me, clock)
+	t.Cleanup(n.Shutdown)
+	return n
+}
+
+func receive(t *testing.T, received chan string) string {
+	select {
+	case uri := <-received:
+		return uri
+	case <-time.After(time.Second):
+		t.Fatal("notification not received")
+		return ""
+	}
+}
+
+func assertNothingReceived(t *testing.T, received chan string) {
+	select {
+	case uri := <-received:
+		t.Fatalf("unexpected notification %s", uri)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestNewNotifierDisabled(t *testing.T) {
+	assert.Nil(t, NewNotifier(config.BidNotifications{}, http.DefaultClient, &metrics.MetricsEngineMock{}, clock.NewMock()))
+}
+
+func TestNotifyAuctionLoss(t *testing.T) {
+	server, received := notificationServer(t, http.StatusOK)
+	recorded := make(chan metrics.BidNotificationStatus, 10)
+	n := newTestNotifier(t, newMetricsEngine(recorded), clock.NewMock())
+
+	n.NotifyAuction(Auction{
+		Account:  &config.Account{ID: "account", BidNotifications: config.AccountBidNotifications{Enabled: true}},
+		ID:       "auction",
+		Currency: "USD",
+		Bids: []Bid{
+			{
+				Bidder:     "appnexus",
+				Seat:       "appnexus",
+				ID:         "bid1",
+				ImpID:      "imp1",
+				Price:      1.5,
+				LURL:       server.URL + "/loss?reason=${AUCTION_LOSS}&min=${AUCTION_MIN_TO_WIN}&price=${AUCTION_PRICE}",
+				Lost:       true,
+				LossReason: LossReasonLostToHigherBid,
+				MinToWin:   2,
+			},
+			{
+				Bidder:     "pubmatic",
+				Seat:       "pubmatic",
+				ID:         "bid2",
+				ImpID:      "imp1",
+				LURL:       server.URL + "/pubmatic",
+				Lost:       true,
+				LossReason: LossReasonLostToHigherBid,
+			},
+		},
+	})
+
+	assert.Equal(t, "/loss?reason=102&min=2&price=1.5", receive(t, received))
+	assert.Equal(t, metrics.BidNotificationSent, <-recorded)
+	assertNothingReceived(t, received)
+}
+
+func TestNotifyEvent(t *testing.T) {
+	server, received := notificationServer(t, http.StatusOK)
+	recorded := make(chan metrics.BidNotificationStatus, 10)
+	n := newTestNotifier(t, newMetricsEngine(recorded), clock.NewMock())
+
+	n.NotifyAuction(Auction{
+		Account:  &config.Account{ID: "account", BidNotifications: config.AccountBidNotifications{Enabled: true}},
+		ID:       "auction",
+		Currency: "EUR",
+		Bids: []Bid{
+			{
+				Bidder:     "rubicon",
+				Seat:       "rubicon",
+				ID:         "bid1",
+				ImpID:      "imp1",
+				EventBidID: "generated",
+				Price:      3,
+				NURL:       server.URL + "/win?price=${AUCTION_PRICE}&cur=${AUCTION_CURRENCY}",
+				BURL:       server.URL + "/bill?loss=${AUCTION_LOSS}",
+			},
+		},
+	})
+
+	// the event of another account or seat fires nothing
+	n.NotifyEvent(&analytics.EventRequest{Type: analytics.Win, AccountID: "other", BidID: "generated"})
+	n.NotifyEvent(&analytics.EventRequest{Type: analytics.Win, AccountID: "account", BidID: "generated", Bidder: "appnexus"})
+	assertNothingReceived(t, received)
+
+	win := &analytics.EventRequest{Type: analytics.Win, AccountID: "account", BidID: "generated", Bidder: "rubicon"}
+	n.NotifyEvent(win)
+	assert.Equal(t, "/win?price=3&cur=EUR", receive(t, received))
+	assert.Equal(t, metrics.BidNotificationSent, <-recorded)
+
+	// the win URL is fired once
+	n.NotifyEvent(win)
+	assertNothingReceived(t, received)
+
+	n.NotifyEvent(&analytics.EventRequest{Type: analytics.Imp, AccountID: "account", BidID: "generated"})
+	assert.Equal(t, "/bill?loss=0", receive(t, received))
+	assert.Equal(t, metrics.BidNotificationSent, <-recorded)
+}
+
+func TestNotifyAuctionAccountBidders(t *testing.T) {
+	server, received := notificationServer(t, http.StatusOK)
+	recorded := make(chan metrics.BidNotificationStatus, 10)
+	n := newTestNotifier(t, newMetricsEngine(recorded), clock.NewMock())
+
+	bids := []Bid{
+		{Bidder: "appnexus", Seat: "appnexus", ID: "bid1", LURL: server.URL + "/appnexus", Lost: true},
+		{Bidder: "rubicon", Seat: "rubicon", ID: "bid2", LURL: server.URL + "/rubicon", Lost: true},
+	}
+
+	n.NotifyAuction(Auction{
+		Account: &config.Account{ID: "account", BidNotifications: config.AccountBidNotifications{Enabled: false}},
+		Bids:    bids,
+	})
+	assertNothingReceived(t, received)
+
+	n.NotifyAuction(Auction{
+		Account: &config.Account{ID: "account", BidNotifications: config.AccountBidNotifications{Enabled: true, Bidders: []string{"Rubicon"}}},
+		Bids:    bids,
+	})
+	assert.Equal(t, "/rubicon", receive(t, received))
+	assertNothingReceived(t, received)
+}
+
+func TestDispatcherStatus(t *testing.T) {
+	testCases := []struct {
+		name           string
+		responseStatus int
+		expectedStatus metrics.BidNotificationStatus
+	}{
+		{
+			name:           "ok",
+			responseStatus: http.StatusNoContent,
+			expectedStatus: metrics.BidNotificationSent,
+		},
+		{
+			name:           "error",
+			responseStatus: http.StatusInternalServerError,
+			expectedStatus: metrics.BidNotificationFailed,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.name, func(t *testing.T) {
+			server, received := notificationServer(t, test.responseStatus)
+			recorded := make(chan metrics.BidNotificationStatus, 1)
+			d := newDispatcher(http.DefaultClient, time.Second, 1, 1, newMetricsEngine(recorded))
+			defer d.shutdown()
+
+			d.dispatch(notification{bidder: "appnexus", notificationType: metrics.BidNotificationLoss, url: server.URL})
+
+			receive(t, received)
+			assert.Equal(t, test.expectedStatus, <-recorded)
+		})
+	}
+}
+
+func TestDispatcherDropsWhenFull(t *testing.T) {
+	me := &metrics.MetricsEngineMock{}
+	me.On("RecordBidNotification", openrtb_ext.BidderName("appnexus"), metrics.BidNotificationLoss, metrics.BidNotificationDropped).Once()
+
+	// without workers, the queue is never drained
+	d := newDispatcher(http.DefaultClient, time.Second, 1, 0, me)
+	d.dispatch(notification{bidder: "appnexus", notificationType: metrics.BidNotificationLoss, url: "http://localhost/1"})
+	d.dispatch(notification{bidder: "appnexus", notificationType: metrics.BidNotificationLoss, url: "http://localhost/2"})
+	d.shutdown()
+
+	me.AssertExpectations(t)
+}
+
+func TestWinStore(t *testing.T) {
+	clockMock := clock.NewMock()
+	store := newWinStore(time.Minute, 2, clockMock)
+
+	store.put("a", storedBid{bidder: "appnexus", seat: "appnexus", nurl: "nurl-a"})
+	store.put("b", storedBid{bidder: "appnexus", seat: "appnexus", nurl: "nurl-b"})
+	clockMock.Add(30 * time.Second)
+	store.put("c", storedBid{bidder: "appnexus", seat: "appnexus", nurl: "nurl-c", burl: "burl-c"})
+
+	_, _, ok := store.take("a", "", metrics.BidNotificationWin)
+	assert.False(t, ok, "the oldest bid is evicted beyond the max bids")
+
+	bidder, url, ok := store.take("c", "APPNEXUS", metrics.BidNotificationWin)
+	assert.True(t, ok)
+	assert.Equal(t, openrtb_ext.BidderName("appnexus"), bidder)
+	assert.Equal(t, "nurl-c", url)
+
+	clockMock.Add(45 * time.Second)
+	_, _, ok = store.take("b", "", metrics.BidNotificationWin)
+	assert.False(t, ok, "the bid expired")
+
+	_, url, ok = store.take("c", "", metrics.BidNotificationBilling)
+	assert.True(t, ok)
+	assert.Equal(t, "burl-c", url)
+	assert.Empty(t, store.bids, "the bids are removed once their URLs are fired")
+	assert.Zero(t, store.order.Len())
+}
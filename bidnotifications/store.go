@@ -0,0 +1,106 @@
+package bidnotifications
+
+import (
+	"container/list"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/benbjohnson/clock"
+
+	"github.com/prebid/prebid-server/v3/metrics"
+	"github.com/prebid/prebid-server/v3/openrtb_ext"
+)
+
+// storedBid holds the win and billing URLs of a returned bid, with its macros substituted. Each URL is fired
+// once, and cleared.
+type storedBid struct {
+	bidder openrtb_ext.BidderName
+	seat   string
+	nurl   string
+	burl   string
+}
+
+type storedEntry struct {
+	key     string
+	bid     storedBid
+	expires time.Time
+}
+
+// winStore keeps the returned bids until their events arrive. The bids expire after the TTL, and the oldest
+// bids are evicted beyond the max number of bids. The bids are only known to this instance, so the events
+// must be routed to the instance which ran the auction.
+type winStore struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	maxBids int
+	clock   clock.Clock
+	bids    map[string]*list.Element
+	// order holds the entries in the order they're put, which is the order they expire in
+	order *list.List
+}
+
+func newWinStore(ttl time.Duration, maxBids int, clock clock.Clock) *winStore {
+	return &winStore{
+		ttl:     ttl,
+		maxBids: maxBids,
+		clock:   clock,
+		bids:    make(map[string]*list.Element),
+		order:   list.New(),
+	}
+}
+
+func (s *winStore) put(key string, bid storedBid) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.clock.Now()
+	if element, ok := s.bids[key]; ok {
+		s.remove(element)
+	}
+	s.bids[key] = s.order.PushBack(&storedEntry{key: key, bid: bid, expires: now.Add(s.ttl)})
+
+	for front := s.order.Front(); front != nil; front = s.order.Front() {
+		if s.order.Len() <= s.maxBids && now.Before(front.Value.(*storedEntry).expires) {
+			break
+		}
+		s.remove(front)
+	}
+}
+
+// take returns the URL of the notification type of the bid, and clears it. The seat of the event must be the
+// seat of the bid, unless the event has none.
+func (s *winStore) take(key string, seat string, notificationType metrics.BidNotificationType) (openrtb_ext.BidderName, string, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	element, ok := s.bids[key]
+	if !ok {
+		return "", "", false
+	}
+	entry := element.Value.(*storedEntry)
+	if !s.clock.Now().Before(entry.expires) {
+		s.remove(element)
+		return "", "", false
+	}
+	if seat != "" && !strings.EqualFold(seat, entry.bid.seat) {
+		return "", "", false
+	}
+
+	var url string
+	switch notificationType {
+	case metrics.BidNotificationWin:
+		url, entry.bid.nurl = entry.bid.nurl, ""
+	case metrics.BidNotificationBilling:
+		url, entry.bid.burl = entry.bid.burl, ""
+	}
+	if entry.bid.nurl == "" && entry.bid.burl == "" {
+		s.remove(element)
+	}
+	return entry.bid.bidder, url, true
+}
+
+func (s *winStore) remove(element *list.Element) {
+	delete(s.bids, element.Value.(*storedEntry).key)
+	s.order.Remove(element)
+}
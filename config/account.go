@@ -43,6 +43,9 @@ type Account struct {
 	BidAdjustments          *openrtb_ext.ExtRequestPrebidBidAdjustments `mapstructure:"bidadjustments" json:"bidadjustments"`
 	Privacy                 AccountPrivacy                              `mapstructure:"privacy" json:"privacy"`
 	PreferredMediaType      openrtb_ext.PreferredMediaType              `mapstructure:"preferredmediatype" json:"preferredmediatype"`
+	BidNotifications        AccountBidNotifications                     `mapstructure:"bid_notifications" json:"bid_notifications"`
+	// StoredRequestVersions overrides the rollouts of the versioned stored requests and imps, by stored ID
+	StoredRequestVersions map[string]StoredVersionRollout `mapstructure:"stored_request_versions" json:"stored_request_versions"`
 }
 
 // CookieSync represents the account-level defaults for the cookie sync endpoint.
@@ -50,6 +53,10 @@ type CookieSync struct {
 	DefaultLimit    *int  `mapstructure:"default_limit" json:"default_limit"`
 	MaxLimit        *int  `mapstructure:"max_limit" json:"max_limit"`
 	DefaultCoopSync *bool `mapstructure:"default_coop_sync" json:"default_coop_sync"`
+	// Strategy overrides the host strategy ordering the bidders to sync
+	Strategy *string `mapstructure:"strategy" json:"strategy"`
+	// BidderWeights are the weights of the weighted strategy, used instead of the live bidder stats
+	BidderWeights map[string]float64 `mapstructure:"bidder_weights" json:"bidder_weights"`
 }
 
 // AccountCCPA represents account-specific CCPA configuration
@@ -336,11 +343,75 @@ func (m AccountModules) ModuleConfig(id string) (json.RawMessage, error) {
 }
 
 type AccountPrivacy struct {
-	AllowActivities *AllowActivities `mapstructure:"allowactivities" json:"allowactivities"`
-	DSA             *AccountDSA      `mapstructure:"dsa" json:"dsa"`
-	IPv6Config      IPv6             `mapstructure:"ipv6" json:"ipv6"`
-	IPv4Config      IPv4             `mapstructure:"ipv4" json:"ipv4"`
-	PrivacySandbox  PrivacySandbox   `mapstructure:"privacysandbox" json:"privacysandbox"`
+	AllowActivities *AllowActivities      `mapstructure:"allowactivities" json:"allowactivities"`
+	DSA             *AccountDSA           `mapstructure:"dsa" json:"dsa"`
+	IPv6Config      IPv6                  `mapstructure:"ipv6" json:"ipv6"`
+	IPv4Config      IPv4                  `mapstructure:"ipv4" json:"ipv4"`
+	PrivacySandbox  PrivacySandbox        `mapstructure:"privacysandbox" json:"privacysandbox"`
+	Modules         AccountPrivacyModules `mapstructure:"modules" json:"modules"`
+}
+
+const (
+	// PrivacyRegUSNat selects the GPP US national and state sections enforcement in the privacyreg rule condition
+	PrivacyRegUSNat = "usnat"
+	// PrivacyRegAll selects all enabled privacy modules in the privacyreg rule condition
+	PrivacyRegAll = "*"
+)
+
+// AccountPrivacyModules holds the configuration of the privacy modules which activity rules
+// may delegate to using the privacyreg condition
+type AccountPrivacyModules struct {
+	USNat AccountUSNat `mapstructure:"usnat" json:"usnat"`
+}
+
+// selects returns true if the privacyreg condition of a rule selects an enabled privacy module
+func (m AccountPrivacyModules) selects(privacyReg []string) bool {
+	for _, reg := range privacyReg {
+		if (reg == PrivacyRegUSNat || reg == PrivacyRegAll) && m.USNat.Enabled {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidatePrivacyReg returns an error for each activity rule whose privacyreg condition selects no enabled
+// privacy module, as the rule has no effect
+func (p *AccountPrivacy) ValidatePrivacyReg(errs []error) []error {
+	if p.AllowActivities == nil {
+		return errs
+	}
+	activities := []struct {
+		name     string
+		activity Activity
+	}{
+		{"syncUser", p.AllowActivities.SyncUser},
+		{"fetchBids", p.AllowActivities.FetchBids},
+		{"enrichUfpd", p.AllowActivities.EnrichUserFPD},
+		{"reportAnalytics", p.AllowActivities.ReportAnalytics},
+		{"transmitUfpd", p.AllowActivities.TransmitUserFPD},
+		{"transmitPreciseGeo", p.AllowActivities.TransmitPreciseGeo},
+		{"transmitUniqueRequestIds", p.AllowActivities.TransmitUniqueRequestIds},
+		{"transmitTid", p.AllowActivities.TransmitTids},
+		{"transmitEids", p.AllowActivities.TransmitEids},
+	}
+	for _, a := range activities {
+		for i, rule := range a.activity.Rules {
+			if len(rule.PrivacyReg) > 0 && !p.Modules.selects(rule.PrivacyReg) {
+				errs = append(errs, fmt.Errorf("privacy.allowactivities.%s.rules[%d].privacyreg %v selects no enabled privacy module", a.name, i, rule.PrivacyReg))
+			}
+		}
+	}
+	return errs
+}
+
+// AccountUSNat configures the MSPA enforcement of the GPP US national and state sections
+type AccountUSNat struct {
+	Enabled bool `mapstructure:"enabled" json:"enabled"`
+	// SkipSIDs lists the GPP section ids which are ignored by the enforcement
+	SkipSIDs []int8 `mapstructure:"skip_sids" json:"skip_sids"`
+	// Normalize enables the enforcement of the state sections by normalizing them to the national section fields,
+	// only the national section is enforced if disabled. Defaults to true.
+	Normalize *bool `mapstructure:"normalize" json:"normalize"`
 }
 
 type PrivacySandbox struct {
@@ -1014,3 +1014,60 @@ func TestIPMaskingValidate(t *testing.T) {
 		})
 	}
 }
+
+func TestPrivacyRegValidate(t *testing.T) {
+	usnatRule := []ActivityRule{{PrivacyReg: []string{"usnat"}}}
+	tests := []struct {
+		name    string
+		privacy AccountPrivacy
+		want    []error
+	}{
+		{
+			name:    "no_activities",
+			privacy: AccountPrivacy{},
+		},
+		{
+			name: "module_enabled",
+			privacy: AccountPrivacy{
+				AllowActivities: &AllowActivities{TransmitUserFPD: Activity{Rules: usnatRule}},
+				Modules:         AccountPrivacyModules{USNat: AccountUSNat{Enabled: true}},
+			},
+		},
+		{
+			name: "all_modules_enabled",
+			privacy: AccountPrivacy{
+				AllowActivities: &AllowActivities{SyncUser: Activity{Rules: []ActivityRule{{PrivacyReg: []string{"*"}}}}},
+				Modules:         AccountPrivacyModules{USNat: AccountUSNat{Enabled: true}},
+			},
+		},
+		{
+			name: "module_disabled",
+			privacy: AccountPrivacy{
+				AllowActivities: &AllowActivities{
+					FetchBids:       Activity{Rules: []ActivityRule{{Allow: true}}},
+					TransmitUserFPD: Activity{Rules: append([]ActivityRule{{Allow: true}}, usnatRule...)},
+				},
+			},
+			want: []error{
+				errors.New("privacy.allowactivities.transmitUfpd.rules[1].privacyreg [usnat] selects no enabled privacy module"),
+			},
+		},
+		{
+			name: "unknown_module",
+			privacy: AccountPrivacy{
+				AllowActivities: &AllowActivities{TransmitEids: Activity{Rules: []ActivityRule{{PrivacyReg: []string{"gdpr"}}}}},
+				Modules:         AccountPrivacyModules{USNat: AccountUSNat{Enabled: true}},
+			},
+			want: []error{
+				errors.New("privacy.allowactivities.transmitEids.rules[0].privacyreg [gdpr] selects no enabled privacy module"),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			errs := tt.privacy.ValidatePrivacyReg(nil)
+			assert.ElementsMatch(t, errs, tt.want)
+		})
+	}
+}
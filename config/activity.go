@@ -9,6 +9,7 @@ type AllowActivities struct {
 	TransmitPreciseGeo       Activity `mapstructure:"transmitPreciseGeo" json:"transmitPreciseGeo"`
 	TransmitUniqueRequestIds Activity `mapstructure:"transmitUniqueRequestIds" json:"transmitUniqueRequestIds"`
 	TransmitTids             Activity `mapstructure:"transmitTid" json:"transmitTid"`
+	TransmitEids             Activity `mapstructure:"transmitEids" json:"transmitEids"`
 }
 
 type Activity struct {
@@ -19,6 +20,8 @@ type Activity struct {
 type ActivityRule struct {
 	Condition ActivityCondition `mapstructure:"condition" json:"condition"`
 	Allow     bool              `mapstructure:"allow" json:"allow"`
+	// PrivacyReg delegates the rule result to the listed privacy modules, "*" selects all of them
+	PrivacyReg []string `mapstructure:"privacyreg" json:"privacyreg"`
 }
 
 type ActivityCondition struct {
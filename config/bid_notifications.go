@@ -0,0 +1,53 @@
+package config
+
+import (
+	"fmt"
+)
+
+// BidNotifications configures the notification URLs of the bids fired by Prebid Server, rather than left to
+// the client: the bid.lurl of the losing bids once the auction is over, and the bid.nurl and bid.burl of the
+// returned bids once their win and impression events arrive. The accounts opt in with their bid_notifications.
+type BidNotifications struct {
+	Enabled bool `mapstructure:"enabled"`
+	// Bidders are the bidders whose notification URLs may be fired
+	Bidders []string `mapstructure:"bidders"`
+	// TimeoutMS is the timeout of each notification call
+	TimeoutMS int `mapstructure:"timeout_ms"`
+	// QueueSize is the number of notifications waiting to be fired, beyond which they're dropped
+	QueueSize int `mapstructure:"queue_size"`
+	// Workers is the number of notifications fired concurrently
+	Workers int `mapstructure:"workers"`
+	// WinTTLSeconds is how long the win and billing URLs of the returned bids are kept for their events
+	WinTTLSeconds int `mapstructure:"win_ttl_seconds"`
+	// MaxStoredBids caps the returned bids whose win and billing URLs are kept, the oldest being evicted first
+	MaxStoredBids int `mapstructure:"max_stored_bids"`
+}
+
+// AccountBidNotifications opts an account in the bid notifications of the host
+type AccountBidNotifications struct {
+	Enabled bool `mapstructure:"enabled" json:"enabled"`
+	// Bidders restricts the notifications to these bidders, among the bidders of the host. All of them if empty.
+	Bidders []string `mapstructure:"bidders" json:"bidders"`
+}
+
+func (cfg *BidNotifications) validate(errs []error) []error {
+	if !cfg.Enabled {
+		return errs
+	}
+	positive := []struct {
+		field string
+		value int
+	}{
+		{"timeout_ms", cfg.TimeoutMS},
+		{"queue_size", cfg.QueueSize},
+		{"workers", cfg.Workers},
+		{"win_ttl_seconds", cfg.WinTTLSeconds},
+		{"max_stored_bids", cfg.MaxStoredBids},
+	}
+	for _, p := range positive {
+		if p.value <= 0 {
+			errs = append(errs, fmt.Errorf("bid_notifications.%s must be > 0. Got %d", p.field, p.value))
+		}
+	}
+	return errs
+}
@@ -0,0 +1,40 @@
+package config
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBidNotificationsValidate(t *testing.T) {
+	testCases := []struct {
+		description  string
+		cfg          BidNotifications
+		expectedErrs []error
+	}{
+		{
+			description: "disabled",
+			cfg:         BidNotifications{},
+		},
+		{
+			description: "valid",
+			cfg:         BidNotifications{Enabled: true, TimeoutMS: 1000, QueueSize: 100, Workers: 2, WinTTLSeconds: 60, MaxStoredBids: 100},
+		},
+		{
+			description: "invalid",
+			cfg:         BidNotifications{Enabled: true, TimeoutMS: 0, QueueSize: 100, Workers: -1, WinTTLSeconds: 60, MaxStoredBids: 0},
+			expectedErrs: []error{
+				errors.New("bid_notifications.timeout_ms must be > 0. Got 0"),
+				errors.New("bid_notifications.workers must be > 0. Got -1"),
+				errors.New("bid_notifications.max_stored_bids must be > 0. Got 0"),
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.description, func(t *testing.T) {
+			assert.Equal(t, test.expectedErrs, test.cfg.validate(nil))
+		})
+	}
+}
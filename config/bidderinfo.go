@@ -134,6 +134,19 @@ type Syncer struct {
 
 	// SkipWhen allows bidders to specify when they don't want to sync
 	SkipWhen *SkipWhen `yaml:"skipwhen" mapstructure:"skipwhen"`
+
+	// EID allows the UIDs synced under this syncer's key to be sent to the other bidders as a user.eids
+	// entry of the given source, in addition to being sent to the bidder as its own buyeruid.
+	EID *SyncerEID `yaml:"eid" mapstructure:"eid"`
+}
+
+// SyncerEID specifies the user.eids entry built from a synced UID.
+type SyncerEID struct {
+	// Source is the eid source, usually the domain of the ID provider.
+	Source string `yaml:"source" mapstructure:"source"`
+	// AType is the agent type of the uid: 1 for a browser or device ID, 2 for an in-app ID or 3 for a
+	// person-based ID. Vendor-specific agent types are 500 and above.
+	AType int64 `yaml:"atype" mapstructure:"atype"`
 }
 
 type SkipWhen struct {
@@ -214,7 +227,8 @@ func (s *Syncer) Defined() bool {
 		s.ExternalURL != "" ||
 		s.SupportCORS != nil ||
 		s.FormatOverride != "" ||
-		s.SkipWhen != nil
+		s.SkipWhen != nil ||
+		s.EID != nil
 }
 
 type InfoReader interface {
@@ -596,6 +610,15 @@ func validateSyncer(bidderInfo BidderInfo) error {
 		}
 	}
 
+	if eid := bidderInfo.Syncer.EID; eid != nil {
+		if eid.Source == "" {
+			return errors.New("syncer could not be created, eid source is required")
+		}
+		if (eid.AType < 1 || eid.AType > 3) && eid.AType < 500 {
+			return fmt.Errorf("syncer could not be created, invalid eid atype: %d", eid.AType)
+		}
+	}
+
 	return nil
 }
 
@@ -711,6 +734,10 @@ func (s *Syncer) Override(original *Syncer) *Syncer {
 		copy.SupportCORS = s.SupportCORS
 	}
 
+	if s.EID != nil {
+		copy.EID = s.EID
+	}
+
 	return &copy
 }
 
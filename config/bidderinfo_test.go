@@ -1394,6 +1394,68 @@ func TestBidderInfoValidationNegative(t *testing.T) {
 				errors.New("syncer could not be created, invalid format override value: x"),
 			},
 		},
+		{
+			"Missing eid source",
+			BidderInfos{
+				"bidderB": BidderInfo{
+					Endpoint: "http://bidderA.com/openrtb2",
+					Maintainer: &MaintainerInfo{
+						Email: "maintainer@bidderA.com",
+					},
+					Capabilities: &CapabilitiesInfo{
+						App: &PlatformInfo{
+							MediaTypes: []openrtb_ext.BidType{
+								openrtb_ext.BidTypeBanner,
+								openrtb_ext.BidTypeNative,
+							},
+						},
+						Site: &PlatformInfo{
+							MediaTypes: []openrtb_ext.BidType{
+								openrtb_ext.BidTypeBanner,
+								openrtb_ext.BidTypeNative,
+							},
+						},
+					},
+					Syncer: &Syncer{
+						EID: &SyncerEID{AType: 1},
+					},
+				},
+			},
+			[]error{
+				errors.New("syncer could not be created, eid source is required"),
+			},
+		},
+		{
+			"Invalid eid atype",
+			BidderInfos{
+				"bidderB": BidderInfo{
+					Endpoint: "http://bidderA.com/openrtb2",
+					Maintainer: &MaintainerInfo{
+						Email: "maintainer@bidderA.com",
+					},
+					Capabilities: &CapabilitiesInfo{
+						App: &PlatformInfo{
+							MediaTypes: []openrtb_ext.BidType{
+								openrtb_ext.BidTypeBanner,
+								openrtb_ext.BidTypeNative,
+							},
+						},
+						Site: &PlatformInfo{
+							MediaTypes: []openrtb_ext.BidType{
+								openrtb_ext.BidTypeBanner,
+								openrtb_ext.BidTypeNative,
+							},
+						},
+					},
+					Syncer: &Syncer{
+						EID: &SyncerEID{Source: "id.com", AType: 4},
+					},
+				},
+			},
+			[]error{
+				errors.New("syncer could not be created, invalid eid atype: 4"),
+			},
+		},
 	}
 
 	for _, test := range testCases {
@@ -1474,6 +1536,12 @@ func TestSyncerOverride(t *testing.T) {
 			givenOverride: &Syncer{SupportCORS: &falseValue},
 			expected:      &Syncer{SupportCORS: &falseValue},
 		},
+		{
+			description:   "Override EID",
+			givenOriginal: &Syncer{EID: &SyncerEID{Source: "original.com", AType: 1}},
+			givenOverride: &Syncer{EID: &SyncerEID{Source: "override.com", AType: 3}},
+			expected:      &Syncer{EID: &SyncerEID{Source: "override.com", AType: 3}},
+		},
 		{
 			description:   "Override Partial - Other Fields Untouched",
 			givenOriginal: &Syncer{Key: "originalKey", ExternalURL: "originalExternalURL"},
@@ -1614,6 +1682,11 @@ func TestSyncerDefined(t *testing.T) {
 			givenSyncer: &Syncer{SkipWhen: &SkipWhen{}},
 			expected:    true,
 		},
+		{
+			name:        "eid-only",
+			givenSyncer: &Syncer{EID: &SyncerEID{}},
+			expected:    true,
+		},
 		{
 			name:        "supports-only",
 			givenSyncer: &Syncer{Supports: []string{"anySupports"}},
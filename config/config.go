@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"net/url"
 	"reflect"
+	"regexp"
 	"strings"
 	"time"
 
@@ -54,6 +55,12 @@ type Configuration struct {
 	// Note that StoredVideo refers to stored video requests, and has nothing to do with caching video creatives.
 	StoredVideo     StoredRequests `mapstructure:"stored_video_req"`
 	StoredResponses StoredRequests `mapstructure:"stored_responses"`
+	// StoredDataManagement is the admin API which persists the stored data
+	StoredDataManagement StoredDataManagement `mapstructure:"stored_data_management"`
+	// BidNotifications fires the notification URLs of the bids on behalf of the clients
+	BidNotifications BidNotifications `mapstructure:"bid_notifications"`
+	// Tracing exports the OpenTelemetry traces of the requests
+	Tracing Tracing `mapstructure:"tracing"`
 	// StoredRequestsTimeout defines the number of milliseconds before a timeout occurs with stored requests fetch
 	StoredRequestsTimeout int `mapstructure:"stored_requests_timeout_ms"`
 
@@ -141,11 +148,20 @@ func (cfg *Configuration) validate(v *viper.Viper) []error {
 	errs = cfg.Accounts.validate(errs)
 	errs = cfg.CategoryMapping.validate(errs)
 	errs = cfg.StoredVideo.validate(errs)
+	errs = cfg.StoredDataManagement.validate(errs)
+	errs = cfg.Analytics.HTTP.validate(errs)
+	errs = cfg.Analytics.FileSink.validate(errs)
+	errs = cfg.BidNotifications.validate(errs)
+	errs = cfg.Tracing.validate(errs)
 	errs = cfg.Metrics.validate(errs)
 	if cfg.MaxRequestSize < 0 {
 		errs = append(errs, fmt.Errorf("cfg.max_request_size must be >= 0. Got %d", cfg.MaxRequestSize))
 	}
 	errs = cfg.GDPR.validate(v, errs)
+	errs = cfg.HostCookie.UIDStore.validate(errs)
+	errs = cfg.HostCookie.Security.validate(errs)
+	errs = cfg.UserSync.Chooser.validate(errs)
+	errs = cfg.UserSync.UIDMapping.validate(errs)
 	errs = cfg.CurrencyConverter.validate(errs)
 	errs = cfg.Debug.validate(errs)
 	errs = cfg.ExtCacheURL.validate(errs)
@@ -162,6 +178,7 @@ func (cfg *Configuration) validate(v *viper.Viper) []error {
 	errs = cfg.BidderInfos.validate(errs)
 	errs = cfg.AccountDefaults.Privacy.IPv6Config.Validate(errs)
 	errs = cfg.AccountDefaults.Privacy.IPv4Config.Validate(errs)
+	errs = cfg.AccountDefaults.Privacy.ValidatePrivacyReg(errs)
 
 	return errs
 }
@@ -255,6 +272,7 @@ type GDPR struct {
 	// to DefaultValue
 	EEACountries    []string `mapstructure:"eea_countries"`
 	EEACountriesMap map[string]struct{}
+	VendorList      GDPRVendorList `mapstructure:"vendorlist"`
 }
 
 func (cfg *GDPR) validate(v *viper.Viper, errs []error) []error {
@@ -272,6 +290,7 @@ func (cfg *GDPR) validate(v *viper.Viper, errs []error) []error {
 	if cfg.AMPException {
 		errs = append(errs, fmt.Errorf("gdpr.amp_exception has been discontinued and must be removed from your config. If you need to disable GDPR for AMP, you may do so per-account (gdpr.integration_enabled.amp) or at the host level for the default account (account_defaults.gdpr.integration_enabled.amp)"))
 	}
+	errs = cfg.VendorList.validate(errs)
 	return cfg.validatePurposes(errs)
 }
 
@@ -313,6 +332,68 @@ func (t *GDPRTimeouts) ActiveTimeout() time.Duration {
 	return time.Duration(t.ActiveVendorlistFetch) * time.Millisecond
 }
 
+const (
+	GDPRVendorListSourceLocal = "local"
+	GDPRVendorListSourceHTTP  = "http"
+)
+
+// GDPRVendorList configures where the global vendor lists are loaded from
+type GDPRVendorList struct {
+	// Sources lists the vendor list backends in fallback order
+	Sources []string           `mapstructure:"sources"`
+	Local   GDPRVendorListDir  `mapstructure:"local"`
+	HTTP    GDPRVendorListHTTP `mapstructure:"http"`
+	// Pinned forces the use of a single list version per spec version, regardless of the
+	// version referenced by the consent string
+	Pinned []GDPRVendorListPin `mapstructure:"pinned"`
+}
+
+// GDPRVendorListDir holds vendor lists stored as vendor-list-v{spec}-{version}.json files
+type GDPRVendorListDir struct {
+	Dir string `mapstructure:"dir"`
+}
+
+// GDPRVendorListHTTP downloads vendor lists. The {spec} and {version} macros of the URLs are
+// replaced by the spec and list versions.
+type GDPRVendorListHTTP struct {
+	URL       string `mapstructure:"url"`
+	LatestURL string `mapstructure:"latest_url"`
+	Proxy     string `mapstructure:"proxy"`
+}
+
+type GDPRVendorListPin struct {
+	SpecVersion uint16 `mapstructure:"spec_version"`
+	ListVersion uint16 `mapstructure:"list_version"`
+}
+
+func (cfg *GDPRVendorList) validate(errs []error) []error {
+	for _, source := range cfg.Sources {
+		switch source {
+		case GDPRVendorListSourceLocal:
+			if cfg.Local.Dir == "" {
+				errs = append(errs, errors.New("gdpr.vendorlist.local.dir is required when the local vendor list source is enabled"))
+			}
+		case GDPRVendorListSourceHTTP:
+			if cfg.HTTP.URL == "" || cfg.HTTP.LatestURL == "" {
+				errs = append(errs, errors.New("gdpr.vendorlist.http.url and gdpr.vendorlist.http.latest_url are required when the http vendor list source is enabled"))
+			}
+			if cfg.HTTP.Proxy != "" {
+				if _, err := url.Parse(cfg.HTTP.Proxy); err != nil {
+					errs = append(errs, fmt.Errorf("gdpr.vendorlist.http.proxy is invalid: %v", err))
+				}
+			}
+		default:
+			errs = append(errs, fmt.Errorf("gdpr.vendorlist.sources contains unknown source %q, must be one of %q or %q", source, GDPRVendorListSourceLocal, GDPRVendorListSourceHTTP))
+		}
+	}
+	for _, pin := range cfg.Pinned {
+		if pin.SpecVersion == 0 || pin.ListVersion == 0 {
+			errs = append(errs, errors.New("gdpr.vendorlist.pinned entries must set both spec_version and list_version"))
+		}
+	}
+	return errs
+}
+
 const (
 	TCF2EnforceAlgoBasic = "basic"
 	TCF2EnforceAlgoFull  = "full"
@@ -454,6 +535,8 @@ type Analytics struct {
 	File     FileLogs      `mapstructure:"file"`
 	Agma     AgmaAnalytics `mapstructure:"agma"`
 	Pubstack Pubstack      `mapstructure:"pubstack"`
+	HTTP     HTTPAnalytics `mapstructure:"http"`
+	FileSink FileSink      `mapstructure:"file_sink"`
 }
 
 type CurrencyConverter struct {
@@ -533,12 +616,124 @@ type HostCookie struct {
 	OptOutCookie       Cookie `mapstructure:"optout_cookie"`
 	// Cookie timeout in days
 	TTL int64 `mapstructure:"ttl_days"`
+	// UIDStore keeps the bidder UIDs server side instead of in the uids cookie
+	UIDStore UIDStore `mapstructure:"uid_store"`
+	// Security encrypts or signs the uids cookie
+	Security UIDCookieSecurity `mapstructure:"security"`
 }
 
 func (cfg *HostCookie) TTLDuration() time.Duration {
 	return time.Duration(cfg.TTL) * time.Hour * 24
 }
 
+// UIDStore configures the server side storage of the bidder UIDs. When enabled, the uids cookie only
+// holds a signed host ID and the UIDs are read from and written to the store. Cookies holding the
+// UIDs are still read, and are moved to the store the next time they are written.
+type UIDStore struct {
+	Enabled    bool           `mapstructure:"enabled"`
+	SigningKey string         `mapstructure:"signing_key"`
+	Type       string         `mapstructure:"type"`
+	TimeoutMS  int            `mapstructure:"timeout_ms"`
+	Memory     UIDStoreMemory `mapstructure:"memory"`
+}
+
+// UIDStoreMemory configures the in-memory UID store, which evicts the least recently used users once full
+type UIDStoreMemory struct {
+	SizeBytes int `mapstructure:"size_bytes"`
+}
+
+const UIDStoreTypeMemory = "memory"
+
+func (cfg *UIDStore) validate(errs []error) []error {
+	if !cfg.Enabled {
+		return errs
+	}
+	if cfg.SigningKey == "" {
+		errs = append(errs, errors.New("host_cookie.uid_store.signing_key is required when the uid store is enabled"))
+	}
+	if cfg.TimeoutMS <= 0 {
+		errs = append(errs, fmt.Errorf("host_cookie.uid_store.timeout_ms must be > 0. Got %d", cfg.TimeoutMS))
+	}
+	switch cfg.Type {
+	case UIDStoreTypeMemory:
+		if cfg.Memory.SizeBytes <= 0 {
+			errs = append(errs, fmt.Errorf("host_cookie.uid_store.memory.size_bytes must be > 0. Got %d", cfg.Memory.SizeBytes))
+		}
+	default:
+		errs = append(errs, fmt.Errorf("host_cookie.uid_store.type %q is not supported, must be %q", cfg.Type, UIDStoreTypeMemory))
+	}
+	return errs
+}
+
+// UIDCookieSecurity configures the protection of the uids cookie. Cookies are written with the active
+// key and read with any key of the keyring, so keys can be rotated by adding the new key, making it
+// active once every instance knows it, and removing the old key once its cookies have expired.
+type UIDCookieSecurity struct {
+	Mode      string         `mapstructure:"mode"`
+	ActiveKey string         `mapstructure:"active_key"`
+	Keys      []UIDCookieKey `mapstructure:"keys"`
+	// KeysFile is a JSON file holding an array of keys, read in addition to Keys
+	KeysFile string `mapstructure:"keys_file"`
+	// LegacyCutoff is the RFC 3339 time after which the legacy cookies, neither signed nor encrypted, are
+	// rejected. They're read until then, so they're secured the next time they're written. It's usually the
+	// time the mode was enabled plus the cookie TTL. A time in the past rejects them right away.
+	LegacyCutoff string `mapstructure:"legacy_cutoff"`
+}
+
+// UIDCookieKey is a key of the uids cookie keyring
+type UIDCookieKey struct {
+	ID     string `mapstructure:"id" json:"id"`
+	Secret string `mapstructure:"secret" json:"secret"`
+}
+
+const (
+	UIDCookieSecurityModeNone    = "none"
+	UIDCookieSecurityModeSign    = "sign"
+	UIDCookieSecurityModeEncrypt = "encrypt"
+)
+
+var uidCookieKeyIDRegex = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+func (cfg *UIDCookieSecurity) validate(errs []error) []error {
+	switch cfg.Mode {
+	case "", UIDCookieSecurityModeNone:
+		return errs
+	case UIDCookieSecurityModeSign, UIDCookieSecurityModeEncrypt:
+	default:
+		return append(errs, fmt.Errorf("host_cookie.security.mode %q is not supported, must be one of %q, %q or %q", cfg.Mode, UIDCookieSecurityModeNone, UIDCookieSecurityModeSign, UIDCookieSecurityModeEncrypt))
+	}
+
+	if cfg.ActiveKey == "" {
+		errs = append(errs, fmt.Errorf("host_cookie.security.active_key is required when the mode is %q", cfg.Mode))
+	}
+	if len(cfg.Keys) == 0 && cfg.KeysFile == "" {
+		errs = append(errs, fmt.Errorf("host_cookie.security.keys or host_cookie.security.keys_file is required when the mode is %q", cfg.Mode))
+	}
+	if cfg.LegacyCutoff != "" {
+		if _, err := time.Parse(time.RFC3339, cfg.LegacyCutoff); err != nil {
+			errs = append(errs, fmt.Errorf("host_cookie.security.legacy_cutoff %q must be an RFC 3339 time", cfg.LegacyCutoff))
+		}
+	}
+
+	activeKeyFound := false
+	for i, key := range cfg.Keys {
+		if !uidCookieKeyIDRegex.MatchString(key.ID) {
+			errs = append(errs, fmt.Errorf("host_cookie.security.keys[%d].id %q must only hold letters, digits, '-' and '_'", i, key.ID))
+		}
+		if key.Secret == "" {
+			errs = append(errs, fmt.Errorf("host_cookie.security.keys[%d].secret is required", i))
+		}
+		if key.ID == cfg.ActiveKey {
+			activeKeyFound = true
+		}
+	}
+	// The keys of the file are checked when the file is read
+	if cfg.ActiveKey != "" && len(cfg.Keys) > 0 && cfg.KeysFile == "" && !activeKeyFound {
+		errs = append(errs, fmt.Errorf("host_cookie.security.active_key %q is not in host_cookie.security.keys", cfg.ActiveKey))
+	}
+	return errs
+}
+
 type RequestTimeoutHeaders struct {
 	RequestTimeInQueue    string `mapstructure:"request_time_in_queue"`
 	RequestTimeoutInQueue string `mapstructure:"request_timeout_in_queue"`
@@ -547,6 +742,7 @@ type RequestTimeoutHeaders struct {
 type Metrics struct {
 	Influxdb   InfluxMetrics     `mapstructure:"influxdb"`
 	Prometheus PrometheusMetrics `mapstructure:"prometheus"`
+	StatsD     StatsDMetrics     `mapstructure:"statsd"`
 	Disabled   DisabledMetrics   `mapstructure:"disabled_metrics"`
 }
 
@@ -573,10 +769,15 @@ type DisabledMetrics struct {
 
 	// True if we want to stop collecting account modules metrics
 	AccountModulesMetrics bool `mapstructure:"account_modules_metrics"`
+
+	// True if we want to stop collecting account auction outcome metrics, such as the wins of each
+	// adapter by account
+	AccountAuctionOutcome bool `mapstructure:"account_auction_outcome"`
 }
 
 func (cfg *Metrics) validate(errs []error) []error {
-	return cfg.Prometheus.validate(errs)
+	errs = cfg.Prometheus.validate(errs)
+	return cfg.StatsD.validate(errs)
 }
 
 type InfluxMetrics struct {
@@ -607,6 +808,54 @@ func (m *PrometheusMetrics) Timeout() time.Duration {
 	return time.Duration(m.TimeoutMillisRaw) * time.Millisecond
 }
 
+// StatsDMetrics configures the metrics sent to a StatsD agent in the DogStatsD format, with the labels as tags
+type StatsDMetrics struct {
+	// Address is the host:port of the agent over UDP, or unix:///path/to/socket for a Unix domain socket.
+	// The StatsD metrics are disabled if it's empty.
+	Address   string   `mapstructure:"address"`
+	Namespace string   `mapstructure:"namespace"`
+	Tags      []string `mapstructure:"tags"`
+	// SampleRate is the share of the values which are sent, the agent scaling the counts back up
+	SampleRate float64 `mapstructure:"sample_rate"`
+	// ClientSideAggregation sums the counts before sending them. ExtendedAggregation also aggregates the
+	// timings and histograms.
+	ClientSideAggregation      bool `mapstructure:"client_side_aggregation"`
+	ExtendedAggregation        bool `mapstructure:"extended_aggregation"`
+	AggregationFlushIntervalMS int  `mapstructure:"aggregation_flush_interval_ms"`
+	// BufferFlushIntervalMS is how long the metrics are buffered before they're sent, unless the buffer fills up
+	BufferFlushIntervalMS int `mapstructure:"buffer_flush_interval_ms"`
+	MaxMessagesPerPayload int `mapstructure:"max_messages_per_payload"`
+	// WriteTimeoutMS is the timeout of the writes to a Unix domain socket
+	WriteTimeoutMS int `mapstructure:"write_timeout_ms"`
+	// Telemetry sends the metrics of the client itself, such as the dropped metrics
+	Telemetry bool `mapstructure:"telemetry"`
+}
+
+func (cfg *StatsDMetrics) validate(errs []error) []error {
+	if cfg.Address == "" {
+		return errs
+	}
+	if cfg.SampleRate <= 0 || cfg.SampleRate > 1 {
+		errs = append(errs, fmt.Errorf("metrics.statsd.sample_rate must be > 0 and <= 1. Got %g", cfg.SampleRate))
+	}
+	if cfg.ClientSideAggregation && cfg.AggregationFlushIntervalMS <= 0 {
+		errs = append(errs, fmt.Errorf("metrics.statsd.aggregation_flush_interval_ms must be positive if metrics.statsd.client_side_aggregation is enabled. Got %d", cfg.AggregationFlushIntervalMS))
+	}
+	if cfg.ExtendedAggregation && !cfg.ClientSideAggregation {
+		errs = append(errs, errors.New("metrics.statsd.extended_aggregation requires metrics.statsd.client_side_aggregation"))
+	}
+	if cfg.BufferFlushIntervalMS <= 0 {
+		errs = append(errs, fmt.Errorf("metrics.statsd.buffer_flush_interval_ms must be positive. Got %d", cfg.BufferFlushIntervalMS))
+	}
+	if cfg.MaxMessagesPerPayload <= 0 {
+		errs = append(errs, fmt.Errorf("metrics.statsd.max_messages_per_payload must be positive. Got %d", cfg.MaxMessagesPerPayload))
+	}
+	if cfg.WriteTimeoutMS <= 0 {
+		errs = append(errs, fmt.Errorf("metrics.statsd.write_timeout_ms must be positive. Got %d", cfg.WriteTimeoutMS))
+	}
+	return errs
+}
+
 // ExternalCache configures the externally accessible cache url.
 type ExternalCache struct {
 	Scheme string `mapstructure:"scheme"`
@@ -935,6 +1184,15 @@ func SetupViper(v *viper.Viper, filename string, bidderInfos BidderInfos) {
 	v.SetDefault("host_cookie.value", "")
 	v.SetDefault("host_cookie.ttl_days", 90)
 	v.SetDefault("host_cookie.max_cookie_size_bytes", 0)
+	v.SetDefault("host_cookie.uid_store.enabled", false)
+	v.SetDefault("host_cookie.uid_store.signing_key", "")
+	v.SetDefault("host_cookie.uid_store.type", UIDStoreTypeMemory)
+	v.SetDefault("host_cookie.uid_store.timeout_ms", 50)
+	v.SetDefault("host_cookie.uid_store.memory.size_bytes", 100*1024*1024)
+	v.SetDefault("host_cookie.security.mode", UIDCookieSecurityModeNone)
+	v.SetDefault("host_cookie.security.active_key", "")
+	v.SetDefault("host_cookie.security.keys_file", "")
+	v.SetDefault("host_cookie.security.legacy_cutoff", "")
 	v.SetDefault("host_schain_node", nil)
 	v.SetDefault("validations.banner_creative_max_size", ValidationSkip)
 	v.SetDefault("validations.secure_markup", ValidationSkip)
@@ -955,6 +1213,7 @@ func SetupViper(v *viper.Viper, filename string, bidderInfos BidderInfos) {
 	v.SetDefault("metrics.disabled_metrics.adapter_connections_metrics", true)
 	v.SetDefault("metrics.disabled_metrics.adapter_buyeruid_scrubbed", true)
 	v.SetDefault("metrics.disabled_metrics.adapter_gdpr_request_blocked", false)
+	v.SetDefault("metrics.disabled_metrics.account_auction_outcome", true)
 	v.SetDefault("metrics.influxdb.host", "")
 	v.SetDefault("metrics.influxdb.database", "")
 	v.SetDefault("metrics.influxdb.measurement", "")
@@ -966,8 +1225,23 @@ func SetupViper(v *viper.Viper, filename string, bidderInfos BidderInfos) {
 	v.SetDefault("metrics.prometheus.namespace", "")
 	v.SetDefault("metrics.prometheus.subsystem", "")
 	v.SetDefault("metrics.prometheus.timeout_ms", 10000)
+	v.SetDefault("metrics.statsd.address", "")
+	v.SetDefault("metrics.statsd.namespace", "prebidserver.")
+	v.SetDefault("metrics.statsd.tags", []string{})
+	v.SetDefault("metrics.statsd.sample_rate", 1.0)
+	v.SetDefault("metrics.statsd.client_side_aggregation", true)
+	v.SetDefault("metrics.statsd.extended_aggregation", false)
+	v.SetDefault("metrics.statsd.aggregation_flush_interval_ms", 2000)
+	v.SetDefault("metrics.statsd.buffer_flush_interval_ms", 100)
+	v.SetDefault("metrics.statsd.max_messages_per_payload", 1000)
+	v.SetDefault("metrics.statsd.write_timeout_ms", 100)
+	v.SetDefault("metrics.statsd.telemetry", false)
 	v.SetDefault("category_mapping.filesystem.enabled", true)
 	v.SetDefault("category_mapping.filesystem.directorypath", "./static/category-mapping")
+	v.SetDefault("category_mapping.filesystem.watch.enabled", false)
+	v.SetDefault("category_mapping.filesystem.watch.polling", false)
+	v.SetDefault("category_mapping.filesystem.watch.poll_interval_ms", 1000)
+	v.SetDefault("category_mapping.filesystem.watch.debounce_ms", 250)
 	v.SetDefault("category_mapping.http.endpoint", "")
 	v.SetDefault("stored_requests_timeout_ms", 50)
 	v.SetDefault("stored_requests.database.connection.driver", "")
@@ -991,14 +1265,46 @@ func SetupViper(v *viper.Viper, filename string, bidderInfos BidderInfos) {
 	v.SetDefault("stored_requests.database.poll_for_updates.amp_query", "")
 	v.SetDefault("stored_requests.filesystem.enabled", false)
 	v.SetDefault("stored_requests.filesystem.directorypath", "./stored_requests/data/by_id")
+	v.SetDefault("stored_requests.filesystem.watch.enabled", false)
+	v.SetDefault("stored_requests.filesystem.watch.polling", false)
+	v.SetDefault("stored_requests.filesystem.watch.poll_interval_ms", 1000)
+	v.SetDefault("stored_requests.filesystem.watch.debounce_ms", 250)
+	v.SetDefault("stored_requests.s3.enabled", false)
+	v.SetDefault("stored_requests.s3.endpoint", "https://s3.amazonaws.com")
+	v.SetDefault("stored_requests.s3.region", "us-east-1")
+	v.SetDefault("stored_requests.s3.bucket", "")
+	v.SetDefault("stored_requests.s3.force_path_style", false)
+	v.SetDefault("stored_requests.s3.access_key_id", "")
+	v.SetDefault("stored_requests.s3.secret_access_key", "")
+	v.SetDefault("stored_requests.s3.prefixes.requests", "stored_requests/")
+	v.SetDefault("stored_requests.s3.prefixes.imps", "stored_imps/")
+	v.SetDefault("stored_requests.s3.prefixes.responses", "stored_responses/")
+	v.SetDefault("stored_requests.s3.prefixes.accounts", "accounts/")
+	v.SetDefault("stored_requests.s3.gzip", false)
+	v.SetDefault("stored_requests.s3.timeout_ms", 1000)
+	v.SetDefault("stored_requests.s3.refresh_rate_seconds", 0)
 	v.SetDefault("stored_requests.directorypath", "./stored_requests/data/by_id")
 	v.SetDefault("stored_requests.http.endpoint", "")
 	v.SetDefault("stored_requests.http.amp_endpoint", "")
 	v.SetDefault("stored_requests.in_memory_cache.type", "none")
+	v.SetDefault("stored_requests.in_memory_cache.negative_ttl_seconds", 0)
 	v.SetDefault("stored_requests.in_memory_cache.ttl_seconds", 0)
 	v.SetDefault("stored_requests.in_memory_cache.request_cache_size_bytes", 0)
 	v.SetDefault("stored_requests.in_memory_cache.imp_cache_size_bytes", 0)
 	v.SetDefault("stored_requests.in_memory_cache.resp_cache_size_bytes", 0)
+	v.SetDefault("stored_requests.redis_cache.enabled", false)
+	v.SetDefault("stored_requests.redis_cache.address", "")
+	v.SetDefault("stored_requests.redis_cache.password", "")
+	v.SetDefault("stored_requests.redis_cache.db", 0)
+	v.SetDefault("stored_requests.redis_cache.tls", false)
+	v.SetDefault("stored_requests.redis_cache.prefixes.requests", "pbs:stored_requests:")
+	v.SetDefault("stored_requests.redis_cache.prefixes.imps", "pbs:stored_imps:")
+	v.SetDefault("stored_requests.redis_cache.prefixes.responses", "pbs:stored_responses:")
+	v.SetDefault("stored_requests.redis_cache.prefixes.accounts", "pbs:accounts:")
+	v.SetDefault("stored_requests.redis_cache.ttl_seconds", 3600)
+	v.SetDefault("stored_requests.redis_cache.timeout_ms", 50)
+	v.SetDefault("stored_requests.redis_cache.pool_size", 10)
+	v.SetDefault("stored_requests.redis_cache.retry_interval_ms", 5000)
 	v.SetDefault("stored_requests.cache_events_api", false)
 	v.SetDefault("stored_requests.http_events.endpoint", "")
 	v.SetDefault("stored_requests.http_events.amp_endpoint", "")
@@ -1027,12 +1333,44 @@ func SetupViper(v *viper.Viper, filename string, bidderInfos BidderInfos) {
 	v.SetDefault("stored_video_req.database.poll_for_updates.amp_query", "")
 	v.SetDefault("stored_video_req.filesystem.enabled", false)
 	v.SetDefault("stored_video_req.filesystem.directorypath", "")
+	v.SetDefault("stored_video_req.filesystem.watch.enabled", false)
+	v.SetDefault("stored_video_req.filesystem.watch.polling", false)
+	v.SetDefault("stored_video_req.filesystem.watch.poll_interval_ms", 1000)
+	v.SetDefault("stored_video_req.filesystem.watch.debounce_ms", 250)
+	v.SetDefault("stored_video_req.s3.enabled", false)
+	v.SetDefault("stored_video_req.s3.endpoint", "https://s3.amazonaws.com")
+	v.SetDefault("stored_video_req.s3.region", "us-east-1")
+	v.SetDefault("stored_video_req.s3.bucket", "")
+	v.SetDefault("stored_video_req.s3.force_path_style", false)
+	v.SetDefault("stored_video_req.s3.access_key_id", "")
+	v.SetDefault("stored_video_req.s3.secret_access_key", "")
+	v.SetDefault("stored_video_req.s3.prefixes.requests", "stored_requests/")
+	v.SetDefault("stored_video_req.s3.prefixes.imps", "stored_imps/")
+	v.SetDefault("stored_video_req.s3.prefixes.responses", "stored_responses/")
+	v.SetDefault("stored_video_req.s3.prefixes.accounts", "accounts/")
+	v.SetDefault("stored_video_req.s3.gzip", false)
+	v.SetDefault("stored_video_req.s3.timeout_ms", 1000)
+	v.SetDefault("stored_video_req.s3.refresh_rate_seconds", 0)
 	v.SetDefault("stored_video_req.http.endpoint", "")
 	v.SetDefault("stored_video_req.in_memory_cache.type", "none")
+	v.SetDefault("stored_video_req.in_memory_cache.negative_ttl_seconds", 0)
 	v.SetDefault("stored_video_req.in_memory_cache.ttl_seconds", 0)
 	v.SetDefault("stored_video_req.in_memory_cache.request_cache_size_bytes", 0)
 	v.SetDefault("stored_video_req.in_memory_cache.imp_cache_size_bytes", 0)
 	v.SetDefault("stored_video_req.in_memory_cache.resp_cache_size_bytes", 0)
+	v.SetDefault("stored_video_req.redis_cache.enabled", false)
+	v.SetDefault("stored_video_req.redis_cache.address", "")
+	v.SetDefault("stored_video_req.redis_cache.password", "")
+	v.SetDefault("stored_video_req.redis_cache.db", 0)
+	v.SetDefault("stored_video_req.redis_cache.tls", false)
+	v.SetDefault("stored_video_req.redis_cache.prefixes.requests", "pbs:stored_video_req:")
+	v.SetDefault("stored_video_req.redis_cache.prefixes.imps", "pbs:stored_video_imps:")
+	v.SetDefault("stored_video_req.redis_cache.prefixes.responses", "pbs:stored_responses:")
+	v.SetDefault("stored_video_req.redis_cache.prefixes.accounts", "pbs:accounts:")
+	v.SetDefault("stored_video_req.redis_cache.ttl_seconds", 3600)
+	v.SetDefault("stored_video_req.redis_cache.timeout_ms", 50)
+	v.SetDefault("stored_video_req.redis_cache.pool_size", 10)
+	v.SetDefault("stored_video_req.redis_cache.retry_interval_ms", 5000)
 	v.SetDefault("stored_video_req.cache_events.enabled", false)
 	v.SetDefault("stored_video_req.cache_events.endpoint", "")
 	v.SetDefault("stored_video_req.http_events.endpoint", "")
@@ -1059,12 +1397,44 @@ func SetupViper(v *viper.Viper, filename string, bidderInfos BidderInfos) {
 	v.SetDefault("stored_responses.database.poll_for_updates.amp_query", "")
 	v.SetDefault("stored_responses.filesystem.enabled", false)
 	v.SetDefault("stored_responses.filesystem.directorypath", "")
+	v.SetDefault("stored_responses.filesystem.watch.enabled", false)
+	v.SetDefault("stored_responses.filesystem.watch.polling", false)
+	v.SetDefault("stored_responses.filesystem.watch.poll_interval_ms", 1000)
+	v.SetDefault("stored_responses.filesystem.watch.debounce_ms", 250)
+	v.SetDefault("stored_responses.s3.enabled", false)
+	v.SetDefault("stored_responses.s3.endpoint", "https://s3.amazonaws.com")
+	v.SetDefault("stored_responses.s3.region", "us-east-1")
+	v.SetDefault("stored_responses.s3.bucket", "")
+	v.SetDefault("stored_responses.s3.force_path_style", false)
+	v.SetDefault("stored_responses.s3.access_key_id", "")
+	v.SetDefault("stored_responses.s3.secret_access_key", "")
+	v.SetDefault("stored_responses.s3.prefixes.requests", "stored_requests/")
+	v.SetDefault("stored_responses.s3.prefixes.imps", "stored_imps/")
+	v.SetDefault("stored_responses.s3.prefixes.responses", "stored_responses/")
+	v.SetDefault("stored_responses.s3.prefixes.accounts", "accounts/")
+	v.SetDefault("stored_responses.s3.gzip", false)
+	v.SetDefault("stored_responses.s3.timeout_ms", 1000)
+	v.SetDefault("stored_responses.s3.refresh_rate_seconds", 0)
 	v.SetDefault("stored_responses.http.endpoint", "")
 	v.SetDefault("stored_responses.in_memory_cache.type", "none")
+	v.SetDefault("stored_responses.in_memory_cache.negative_ttl_seconds", 0)
 	v.SetDefault("stored_responses.in_memory_cache.ttl_seconds", 0)
 	v.SetDefault("stored_responses.in_memory_cache.request_cache_size_bytes", 0)
 	v.SetDefault("stored_responses.in_memory_cache.imp_cache_size_bytes", 0)
 	v.SetDefault("stored_responses.in_memory_cache.resp_cache_size_bytes", 0)
+	v.SetDefault("stored_responses.redis_cache.enabled", false)
+	v.SetDefault("stored_responses.redis_cache.address", "")
+	v.SetDefault("stored_responses.redis_cache.password", "")
+	v.SetDefault("stored_responses.redis_cache.db", 0)
+	v.SetDefault("stored_responses.redis_cache.tls", false)
+	v.SetDefault("stored_responses.redis_cache.prefixes.requests", "pbs:stored_requests:")
+	v.SetDefault("stored_responses.redis_cache.prefixes.imps", "pbs:stored_imps:")
+	v.SetDefault("stored_responses.redis_cache.prefixes.responses", "pbs:stored_responses:")
+	v.SetDefault("stored_responses.redis_cache.prefixes.accounts", "pbs:accounts:")
+	v.SetDefault("stored_responses.redis_cache.ttl_seconds", 3600)
+	v.SetDefault("stored_responses.redis_cache.timeout_ms", 50)
+	v.SetDefault("stored_responses.redis_cache.pool_size", 10)
+	v.SetDefault("stored_responses.redis_cache.retry_interval_ms", 5000)
 	v.SetDefault("stored_responses.cache_events.enabled", false)
 	v.SetDefault("stored_responses.cache_events.endpoint", "")
 	v.SetDefault("stored_responses.http_events.endpoint", "")
@@ -1077,11 +1447,89 @@ func SetupViper(v *viper.Viper, filename string, bidderInfos BidderInfos) {
 
 	v.SetDefault("event.timeout_ms", 1000)
 
+	v.SetDefault("bid_notifications.enabled", false)
+	v.SetDefault("bid_notifications.bidders", []string{})
+	v.SetDefault("bid_notifications.timeout_ms", 1000)
+	v.SetDefault("bid_notifications.queue_size", 10000)
+	v.SetDefault("bid_notifications.workers", 4)
+	v.SetDefault("bid_notifications.win_ttl_seconds", 3600)
+	v.SetDefault("bid_notifications.max_stored_bids", 100000)
+	v.SetDefault("tracing.enabled", false)
+	v.SetDefault("tracing.service_name", "prebid-server")
+	v.SetDefault("tracing.sample_rate", 0.01)
+	v.SetDefault("tracing.propagate_to_bidders", false)
+	v.SetDefault("tracing.otlp.endpoint", "localhost:4318")
+	v.SetDefault("tracing.otlp.insecure", false)
+	v.SetDefault("tracing.otlp.timeout_ms", 10000)
+
 	v.SetDefault("user_sync.priority_groups", [][]string{})
+	v.SetDefault("user_sync.chooser.strategy", UserSyncChooserStrategyRandom)
+	v.SetDefault("user_sync.chooser.live_stats.enabled", false)
+	v.SetDefault("user_sync.chooser.live_stats.half_life_seconds", 3600)
+	v.SetDefault("user_sync.uid_mapping.enabled", false)
+	v.SetDefault("user_sync.uid_mapping.api_keys", []string{})
+	v.SetDefault("user_sync.uid_mapping.max_records", 1000)
+	v.SetDefault("user_sync.uid_mapping.max_ttl_seconds", 90*24*60*60)
+	v.SetDefault("user_sync.uid_mapping.timeout_ms", 50)
+	v.SetDefault("user_sync.uid_mapping.hashed_email_eid_source", "")
+	v.SetDefault("user_sync.uid_mapping.type", UIDMappingTypeMemory)
+	v.SetDefault("user_sync.uid_mapping.memory.size_bytes", 100*1024*1024)
 
 	v.SetDefault("accounts.filesystem.enabled", false)
 	v.SetDefault("accounts.filesystem.directorypath", "./stored_requests/data/by_id")
+	v.SetDefault("accounts.filesystem.watch.enabled", false)
+	v.SetDefault("accounts.filesystem.watch.polling", false)
+	v.SetDefault("accounts.filesystem.watch.poll_interval_ms", 1000)
+	v.SetDefault("accounts.filesystem.watch.debounce_ms", 250)
+	v.SetDefault("accounts.s3.enabled", false)
+	v.SetDefault("accounts.s3.endpoint", "https://s3.amazonaws.com")
+	v.SetDefault("accounts.s3.region", "us-east-1")
+	v.SetDefault("accounts.s3.bucket", "")
+	v.SetDefault("accounts.s3.force_path_style", false)
+	v.SetDefault("accounts.s3.access_key_id", "")
+	v.SetDefault("accounts.s3.secret_access_key", "")
+	v.SetDefault("accounts.s3.prefixes.requests", "stored_requests/")
+	v.SetDefault("accounts.s3.prefixes.imps", "stored_imps/")
+	v.SetDefault("accounts.s3.prefixes.responses", "stored_responses/")
+	v.SetDefault("accounts.s3.prefixes.accounts", "accounts/")
+	v.SetDefault("accounts.s3.gzip", false)
+	v.SetDefault("accounts.s3.timeout_ms", 1000)
+	v.SetDefault("accounts.s3.refresh_rate_seconds", 0)
 	v.SetDefault("accounts.in_memory_cache.type", "none")
+	v.SetDefault("accounts.in_memory_cache.negative_ttl_seconds", 0)
+	v.SetDefault("accounts.redis_cache.enabled", false)
+	v.SetDefault("accounts.redis_cache.address", "")
+	v.SetDefault("accounts.redis_cache.password", "")
+	v.SetDefault("accounts.redis_cache.db", 0)
+	v.SetDefault("accounts.redis_cache.tls", false)
+	v.SetDefault("accounts.redis_cache.prefixes.requests", "pbs:stored_requests:")
+	v.SetDefault("accounts.redis_cache.prefixes.imps", "pbs:stored_imps:")
+	v.SetDefault("accounts.redis_cache.prefixes.responses", "pbs:stored_responses:")
+	v.SetDefault("accounts.redis_cache.prefixes.accounts", "pbs:accounts:")
+	v.SetDefault("accounts.redis_cache.ttl_seconds", 3600)
+	v.SetDefault("accounts.redis_cache.timeout_ms", 50)
+	v.SetDefault("accounts.redis_cache.pool_size", 10)
+	v.SetDefault("accounts.redis_cache.retry_interval_ms", 5000)
+	v.SetDefault("stored_data_management.enabled", false)
+	v.SetDefault("stored_data_management.backend", "database")
+	v.SetDefault("stored_data_management.database.connection.driver", "")
+	v.SetDefault("stored_data_management.database.connection.dbname", "")
+	v.SetDefault("stored_data_management.database.connection.host", "")
+	v.SetDefault("stored_data_management.database.connection.port", 0)
+	v.SetDefault("stored_data_management.database.connection.user", "")
+	v.SetDefault("stored_data_management.database.connection.password", "")
+	v.SetDefault("stored_data_management.database.connection.query_string", "")
+	v.SetDefault("stored_data_management.database.connection.tls.root_cert", "")
+	v.SetDefault("stored_data_management.database.connection.tls.client_cert", "")
+	v.SetDefault("stored_data_management.database.connection.tls.client_key", "")
+	v.SetDefault("stored_data_management.database.tables.requests", "stored_requests")
+	v.SetDefault("stored_data_management.database.tables.imps", "stored_imps")
+	v.SetDefault("stored_data_management.database.tables.responses", "stored_responses")
+	v.SetDefault("stored_data_management.database.tables.accounts", "accounts")
+	v.SetDefault("stored_data_management.database.tables.audit", "stored_data_audit")
+	v.SetDefault("stored_data_management.filesystem.directorypath", "")
+	v.SetDefault("stored_data_management.filesystem.audit_path", "")
+	v.SetDefault("stored_data_management.timeout_ms", 1000)
 
 	v.BindEnv("user_sync.external_url")
 	v.BindEnv("user_sync.coop_sync.default")
@@ -1107,6 +1555,19 @@ func SetupViper(v *viper.Viper, filename string, bidderInfos BidderInfos) {
 	v.SetDefault("analytics.agma.buffers.count", 100)
 	v.SetDefault("analytics.agma.buffers.timeout", "15m")
 	v.SetDefault("analytics.agma.accounts", []AgmaAnalyticsAccount{})
+	v.SetDefault("analytics.http.enabled", false)
+	v.SetDefault("analytics.http.destinations", []HTTPAnalyticsDestination{})
+	v.SetDefault("analytics.file_sink.enabled", false)
+	v.SetDefault("analytics.file_sink.directory", "")
+	v.SetDefault("analytics.file_sink.prefix", "pbs-analytics")
+	v.SetDefault("analytics.file_sink.compression", FileSinkCompressionGzip)
+	v.SetDefault("analytics.file_sink.rotation.max_size_bytes", 100*1024*1024)
+	v.SetDefault("analytics.file_sink.rotation.max_age_seconds", 3600)
+	v.SetDefault("analytics.file_sink.retention.max_segments", 0)
+	v.SetDefault("analytics.file_sink.retention.max_age_hours", 0)
+	v.SetDefault("analytics.file_sink.csv", false)
+	v.SetDefault("analytics.file_sink.hooks", []FileSinkHook{})
+	v.SetDefault("analytics.file_sink.queue_size", 10000)
 	v.SetDefault("amp_timeout_adjustment_ms", 0)
 	v.BindEnv("gdpr.default_value")
 	v.SetDefault("gdpr.enabled", true)
@@ -1114,6 +1575,11 @@ func SetupViper(v *viper.Viper, filename string, bidderInfos BidderInfos) {
 	v.SetDefault("gdpr.timeouts_ms.init_vendorlist_fetches", 0)
 	v.SetDefault("gdpr.timeouts_ms.active_vendorlist_fetch", 0)
 	v.SetDefault("gdpr.non_standard_publishers", []string{""})
+	v.SetDefault("gdpr.vendorlist.sources", []string{GDPRVendorListSourceHTTP})
+	v.SetDefault("gdpr.vendorlist.local.dir", "")
+	v.SetDefault("gdpr.vendorlist.http.url", "https://vendor-list.consensu.org/v{spec}/archives/vendor-list-v{version}.json")
+	v.SetDefault("gdpr.vendorlist.http.latest_url", "https://vendor-list.consensu.org/v{spec}/vendor-list.json")
+	v.SetDefault("gdpr.vendorlist.http.proxy", "")
 	v.SetDefault("gdpr.tcf2.enabled", true)
 	v.SetDefault("gdpr.tcf2.purpose1.enforce_vendors", true)
 	v.SetDefault("gdpr.tcf2.purpose2.enforce_vendors", true)
@@ -1263,6 +1729,8 @@ func SetupViper(v *viper.Viper, filename string, bidderInfos BidderInfos) {
 	// Defaults for account_defaults.events.default_url
 	v.SetDefault("account_defaults.events.default_url", "https://PBS_HOST/event?t=##PBS-EVENTTYPE##&vtype=##PBS-VASTEVENT##&b=##PBS-BIDID##&f=i&a=##PBS-ACCOUNTID##&ts=##PBS-TIMESTAMP##&bidder=##PBS-BIDDER##&int=##PBS-INTEGRATION##&mt=##PBS-MEDIATYPE##&ch=##PBS-CHANNEL##&aid=##PBS-AUCTIONID##&l=##PBS-LINEID##")
 	v.SetDefault("account_defaults.events.enabled", false)
+	v.SetDefault("account_defaults.bid_notifications.enabled", false)
+	v.SetDefault("account_defaults.bid_notifications.bidders", []string{})
 
 	v.SetDefault("experiment.adscert.mode", "off")
 	v.SetDefault("experiment.adscert.inprocess.origin", "")
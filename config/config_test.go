@@ -150,10 +150,33 @@ func TestDefaults(t *testing.T) {
 	cmpInts(t, "max_request_size", 1024*256, int(cfg.MaxRequestSize))
 	cmpInts(t, "host_cookie.ttl_days", 90, int(cfg.HostCookie.TTL))
 	cmpInts(t, "host_cookie.max_cookie_size_bytes", 0, cfg.HostCookie.MaxCookieSizeBytes)
+	cmpBools(t, "host_cookie.uid_store.enabled", false, cfg.HostCookie.UIDStore.Enabled)
+	cmpStrings(t, "host_cookie.uid_store.type", "memory", cfg.HostCookie.UIDStore.Type)
+	cmpInts(t, "host_cookie.uid_store.timeout_ms", 50, cfg.HostCookie.UIDStore.TimeoutMS)
+	cmpInts(t, "host_cookie.uid_store.memory.size_bytes", 100*1024*1024, cfg.HostCookie.UIDStore.Memory.SizeBytes)
+	cmpStrings(t, "host_cookie.security.mode", "none", cfg.HostCookie.Security.Mode)
+	cmpStrings(t, "user_sync.chooser.strategy", "random", cfg.UserSync.Chooser.Strategy)
+	cmpBools(t, "user_sync.chooser.live_stats.enabled", false, cfg.UserSync.Chooser.LiveStats.Enabled)
+	cmpInts(t, "user_sync.chooser.live_stats.half_life_seconds", 3600, cfg.UserSync.Chooser.LiveStats.HalfLifeSeconds)
+	cmpBools(t, "user_sync.uid_mapping.enabled", false, cfg.UserSync.UIDMapping.Enabled)
+	cmpInts(t, "user_sync.uid_mapping.max_records", 1000, cfg.UserSync.UIDMapping.MaxRecords)
+	cmpInts(t, "user_sync.uid_mapping.max_ttl_seconds", 7776000, cfg.UserSync.UIDMapping.MaxTTLSeconds)
+	cmpInts(t, "user_sync.uid_mapping.timeout_ms", 50, cfg.UserSync.UIDMapping.TimeoutMS)
+	cmpStrings(t, "user_sync.uid_mapping.type", "memory", cfg.UserSync.UIDMapping.Type)
 	cmpInts(t, "currency_converter.fetch_interval_seconds", 1800, cfg.CurrencyConverter.FetchIntervalSeconds)
 	cmpStrings(t, "currency_converter.fetch_url", "https://cdn.jsdelivr.net/gh/prebid/currency-file@1/latest.json", cfg.CurrencyConverter.FetchURL)
 	cmpBools(t, "account_required", false, cfg.AccountRequired)
 	cmpInts(t, "metrics.influxdb.collection_rate_seconds", 20, cfg.Metrics.Influxdb.MetricSendInterval)
+	cmpStrings(t, "metrics.statsd.address", "", cfg.Metrics.StatsD.Address)
+	cmpStrings(t, "metrics.statsd.namespace", "prebidserver.", cfg.Metrics.StatsD.Namespace)
+	assert.Equal(t, 1.0, cfg.Metrics.StatsD.SampleRate, "metrics.statsd.sample_rate")
+	cmpBools(t, "metrics.statsd.client_side_aggregation", true, cfg.Metrics.StatsD.ClientSideAggregation)
+	cmpBools(t, "metrics.statsd.extended_aggregation", false, cfg.Metrics.StatsD.ExtendedAggregation)
+	cmpInts(t, "metrics.statsd.aggregation_flush_interval_ms", 2000, cfg.Metrics.StatsD.AggregationFlushIntervalMS)
+	cmpInts(t, "metrics.statsd.buffer_flush_interval_ms", 100, cfg.Metrics.StatsD.BufferFlushIntervalMS)
+	cmpInts(t, "metrics.statsd.max_messages_per_payload", 1000, cfg.Metrics.StatsD.MaxMessagesPerPayload)
+	cmpInts(t, "metrics.statsd.write_timeout_ms", 100, cfg.Metrics.StatsD.WriteTimeoutMS)
+	cmpBools(t, "metrics.statsd.telemetry", false, cfg.Metrics.StatsD.Telemetry)
 	cmpBools(t, "account_adapter_details", false, cfg.Metrics.Disabled.AccountAdapterDetails)
 	cmpBools(t, "account_debug", true, cfg.Metrics.Disabled.AccountDebug)
 	cmpBools(t, "account_stored_responses", true, cfg.Metrics.Disabled.AccountStoredResponses)
@@ -164,6 +187,42 @@ func TestDefaults(t *testing.T) {
 	cmpInts(t, "stored_requests_timeout_ms", 50, cfg.StoredRequestsTimeout)
 	cmpBools(t, "stored_requests.filesystem.enabled", false, cfg.StoredRequests.Files.Enabled)
 	cmpStrings(t, "stored_requests.filesystem.directorypath", "./stored_requests/data/by_id", cfg.StoredRequests.Files.Path)
+	cmpBools(t, "stored_requests.filesystem.watch.enabled", false, cfg.StoredRequests.Files.Watch.Enabled)
+	cmpBools(t, "stored_requests.filesystem.watch.polling", false, cfg.StoredRequests.Files.Watch.Polling)
+	cmpInts(t, "stored_requests.filesystem.watch.poll_interval_ms", 1000, cfg.StoredRequests.Files.Watch.PollIntervalMS)
+	cmpInts(t, "stored_requests.filesystem.watch.debounce_ms", 250, cfg.StoredRequests.Files.Watch.DebounceMS)
+	cmpBools(t, "stored_requests.s3.enabled", false, cfg.StoredRequests.S3.Enabled)
+	cmpStrings(t, "stored_requests.s3.endpoint", "https://s3.amazonaws.com", cfg.StoredRequests.S3.Endpoint)
+	cmpStrings(t, "stored_requests.s3.region", "us-east-1", cfg.StoredRequests.S3.Region)
+	cmpStrings(t, "stored_requests.s3.prefixes.requests", "stored_requests/", cfg.StoredRequests.S3.Prefixes.Requests)
+	cmpStrings(t, "stored_requests.s3.prefixes.imps", "stored_imps/", cfg.StoredRequests.S3.Prefixes.Imps)
+	cmpStrings(t, "stored_requests.s3.prefixes.responses", "stored_responses/", cfg.StoredRequests.S3.Prefixes.Responses)
+	cmpStrings(t, "stored_requests.s3.prefixes.accounts", "accounts/", cfg.StoredRequests.S3.Prefixes.Accounts)
+	cmpBools(t, "stored_requests.s3.gzip", false, cfg.StoredRequests.S3.Gzip)
+	cmpInts(t, "stored_requests.s3.timeout_ms", 1000, cfg.StoredRequests.S3.TimeoutMS)
+	cmpInts(t, "stored_requests.s3.refresh_rate_seconds", 0, cfg.StoredRequests.S3.RefreshRateSeconds)
+	cmpStrings(t, "accounts.s3.prefixes.accounts", "accounts/", cfg.Accounts.S3.Prefixes.Accounts)
+	cmpInts(t, "stored_requests.in_memory_cache.negative_ttl_seconds", 0, cfg.StoredRequests.InMemoryCache.NegativeTTL)
+	cmpInts(t, "accounts.in_memory_cache.negative_ttl_seconds", 0, cfg.Accounts.InMemoryCache.NegativeTTL)
+	cmpBools(t, "stored_requests.redis_cache.enabled", false, cfg.StoredRequests.RedisCache.Enabled)
+	cmpStrings(t, "stored_requests.redis_cache.prefixes.requests", "pbs:stored_requests:", cfg.StoredRequests.RedisCache.Prefixes.Requests)
+	cmpStrings(t, "stored_requests.redis_cache.prefixes.imps", "pbs:stored_imps:", cfg.StoredRequests.RedisCache.Prefixes.Imps)
+	cmpStrings(t, "stored_requests.redis_cache.prefixes.responses", "pbs:stored_responses:", cfg.StoredRequests.RedisCache.Prefixes.Responses)
+	cmpInts(t, "stored_requests.redis_cache.ttl_seconds", 3600, cfg.StoredRequests.RedisCache.TTL)
+	cmpInts(t, "stored_requests.redis_cache.timeout_ms", 50, cfg.StoredRequests.RedisCache.TimeoutMS)
+	cmpInts(t, "stored_requests.redis_cache.pool_size", 10, cfg.StoredRequests.RedisCache.PoolSize)
+	cmpInts(t, "stored_requests.redis_cache.retry_interval_ms", 5000, cfg.StoredRequests.RedisCache.RetryIntervalMS)
+	cmpStrings(t, "stored_amp_req.redis_cache.prefixes.requests", "pbs:stored_requests:amp:", cfg.StoredRequestsAMP.RedisCache.Prefixes.Requests)
+	cmpStrings(t, "stored_video_req.redis_cache.prefixes.requests", "pbs:stored_video_req:", cfg.StoredVideo.RedisCache.Prefixes.Requests)
+	cmpStrings(t, "accounts.redis_cache.prefixes.accounts", "pbs:accounts:", cfg.Accounts.RedisCache.Prefixes.Accounts)
+	cmpBools(t, "stored_data_management.enabled", false, cfg.StoredDataManagement.Enabled)
+	cmpStrings(t, "stored_data_management.backend", "database", cfg.StoredDataManagement.Backend)
+	cmpStrings(t, "stored_data_management.database.tables.requests", "stored_requests", cfg.StoredDataManagement.Database.Tables.Requests)
+	cmpStrings(t, "stored_data_management.database.tables.imps", "stored_imps", cfg.StoredDataManagement.Database.Tables.Imps)
+	cmpStrings(t, "stored_data_management.database.tables.responses", "stored_responses", cfg.StoredDataManagement.Database.Tables.Responses)
+	cmpStrings(t, "stored_data_management.database.tables.accounts", "accounts", cfg.StoredDataManagement.Database.Tables.Accounts)
+	cmpStrings(t, "stored_data_management.database.tables.audit", "stored_data_audit", cfg.StoredDataManagement.Database.Tables.Audit)
+	cmpInts(t, "stored_data_management.timeout_ms", 1000, cfg.StoredDataManagement.TimeoutMS)
 	cmpBools(t, "auto_gen_source_tid", true, cfg.AutoGenSourceTID)
 	cmpBools(t, "generate_bid_id", false, cfg.GenerateBidID)
 	cmpStrings(t, "experiment.adscert.mode", "off", cfg.Experiment.AdCerts.Mode)
@@ -218,6 +277,7 @@ func TestDefaults(t *testing.T) {
 	cmpInts(t, "validations.max_creative_width", 0, int(cfg.Validations.MaxCreativeWidth))
 	cmpInts(t, "validations.max_creative_height", 0, int(cfg.Validations.MaxCreativeHeight))
 	cmpBools(t, "account_modules_metrics", false, cfg.Metrics.Disabled.AccountModulesMetrics)
+	cmpBools(t, "account_auction_outcome", true, cfg.Metrics.Disabled.AccountAuctionOutcome)
 
 	cmpBools(t, "tmax_adjustments.enabled", false, cfg.TmaxAdjustments.Enabled)
 	cmpUnsignedInts(t, "tmax_adjustments.bidder_response_duration_min_ms", 0, cfg.TmaxAdjustments.BidderResponseDurationMin)
@@ -236,6 +296,25 @@ func TestDefaults(t *testing.T) {
 	cmpInts(t, "analytics.agma.buffers.count", 100, cfg.Analytics.Agma.Buffers.EventCount)
 	cmpStrings(t, "analytics.agma.buffers.timeout", "15m", cfg.Analytics.Agma.Buffers.Timeout)
 	cmpInts(t, "analytics.agma.accounts", 0, len(cfg.Analytics.Agma.Accounts))
+	cmpBools(t, "analytics.http.enabled", false, cfg.Analytics.HTTP.Enabled)
+	cmpInts(t, "analytics.http.destinations", 0, len(cfg.Analytics.HTTP.Destinations))
+	cmpBools(t, "analytics.file_sink.enabled", false, cfg.Analytics.FileSink.Enabled)
+	cmpStrings(t, "analytics.file_sink.prefix", "pbs-analytics", cfg.Analytics.FileSink.Prefix)
+	cmpStrings(t, "analytics.file_sink.compression", "gzip", cfg.Analytics.FileSink.Compression)
+	cmpInts(t, "analytics.file_sink.rotation.max_age_seconds", 3600, cfg.Analytics.FileSink.Rotation.MaxAgeSeconds)
+	cmpInts(t, "analytics.file_sink.queue_size", 10000, cfg.Analytics.FileSink.QueueSize)
+	cmpBools(t, "bid_notifications.enabled", false, cfg.BidNotifications.Enabled)
+	cmpInts(t, "bid_notifications.timeout_ms", 1000, cfg.BidNotifications.TimeoutMS)
+	cmpInts(t, "bid_notifications.queue_size", 10000, cfg.BidNotifications.QueueSize)
+	cmpInts(t, "bid_notifications.workers", 4, cfg.BidNotifications.Workers)
+	cmpInts(t, "bid_notifications.win_ttl_seconds", 3600, cfg.BidNotifications.WinTTLSeconds)
+	cmpInts(t, "bid_notifications.max_stored_bids", 100000, cfg.BidNotifications.MaxStoredBids)
+	cmpBools(t, "account_defaults.bid_notifications.enabled", false, cfg.AccountDefaults.BidNotifications.Enabled)
+	cmpBools(t, "tracing.enabled", false, cfg.Tracing.Enabled)
+	cmpStrings(t, "tracing.service_name", "prebid-server", cfg.Tracing.ServiceName)
+	cmpBools(t, "tracing.propagate_to_bidders", false, cfg.Tracing.PropagateToBidders)
+	cmpStrings(t, "tracing.otlp.endpoint", "localhost:4318", cfg.Tracing.OTLP.Endpoint)
+	cmpInts(t, "tracing.otlp.timeout_ms", 10000, cfg.Tracing.OTLP.TimeoutMS)
 	expectedTCF2 := TCF2{
 		Enabled: true,
 		Purpose1: TCF2Purpose{
@@ -350,6 +429,15 @@ gdpr:
   default_value: "1"
   non_standard_publishers: ["pub1", "pub2"]
   eea_countries: ["eea1", "eea2"]
+  vendorlist:
+    sources: ["local", "http"]
+    local:
+      dir: "/etc/gvl"
+    http:
+      proxy: "http://proxy.example.com:3128"
+    pinned:
+      - spec_version: 3
+        list_version: 55
   tcf2:
     purpose1:
       enforce_vendors: false
@@ -451,6 +539,7 @@ metrics:
     adapter_buyeruid_scrubbed: false
     adapter_gdpr_request_blocked: true
     account_modules_metrics: true
+    account_auction_outcome: false
 blocked_apps: ["spamAppID","sketchy-app-id"]
 account_required: true
 auto_gen_source_tid: false
@@ -710,6 +799,18 @@ func TestFullConfig(t *testing.T) {
 	assert.Equal(t, []string{"eea1", "eea2"}, cfg.GDPR.EEACountries, "gdpr.eea_countries")
 	assert.Equal(t, map[string]struct{}{"eea1": {}, "eea2": {}}, cfg.GDPR.EEACountriesMap, "gdpr.eea_countries Hash Map")
 
+	expectedVendorList := GDPRVendorList{
+		Sources: []string{"local", "http"},
+		Local:   GDPRVendorListDir{Dir: "/etc/gvl"},
+		HTTP: GDPRVendorListHTTP{
+			URL:       "https://vendor-list.consensu.org/v{spec}/archives/vendor-list-v{version}.json",
+			LatestURL: "https://vendor-list.consensu.org/v{spec}/vendor-list.json",
+			Proxy:     "http://proxy.example.com:3128",
+		},
+		Pinned: []GDPRVendorListPin{{SpecVersion: 3, ListVersion: 55}},
+	}
+	assert.Equal(t, expectedVendorList, cfg.GDPR.VendorList, "gdpr.vendorlist")
+
 	cmpBools(t, "ccpa.enforce", true, cfg.CCPA.Enforce)
 	cmpBools(t, "lmt.enforce", true, cfg.LMT.Enforce)
 
@@ -880,6 +981,7 @@ func TestFullConfig(t *testing.T) {
 	cmpInts(t, "experiment.adscert.remote.signing_timeout_ms", 10, cfg.Experiment.AdCerts.Remote.SigningTimeoutMs)
 	cmpBools(t, "hooks.enabled", true, cfg.Hooks.Enabled)
 	cmpBools(t, "account_modules_metrics", true, cfg.Metrics.Disabled.AccountModulesMetrics)
+	cmpBools(t, "account_auction_outcome", false, cfg.Metrics.Disabled.AccountAuctionOutcome)
 	cmpBools(t, "analytics.agma.enabled", true, cfg.Analytics.Agma.Enabled)
 	cmpStrings(t, "analytics.agma.endpoint.timeout", "5s", cfg.Analytics.Agma.Endpoint.Timeout)
 	cmpBools(t, "analytics.agma.endpoint.gzip", false, cfg.Analytics.Agma.Endpoint.Gzip)
@@ -1200,6 +1302,85 @@ func TestNegativePrometheusTimeout(t *testing.T) {
 	assertOneError(t, cfg.validate(v), "metrics.prometheus.timeout_ms must be positive if metrics.prometheus.port is defined. Got timeout=0 and port=8001")
 }
 
+func TestStatsDMetricsValidate(t *testing.T) {
+	valid := StatsDMetrics{
+		Address:                    "localhost:8125",
+		SampleRate:                 1,
+		ClientSideAggregation:      true,
+		AggregationFlushIntervalMS: 2000,
+		BufferFlushIntervalMS:      100,
+		MaxMessagesPerPayload:      1000,
+		WriteTimeoutMS:             100,
+	}
+
+	testCases := []struct {
+		description  string
+		modify       func(cfg *StatsDMetrics)
+		expectedErrs []error
+	}{
+		{
+			description: "valid",
+			modify:      func(cfg *StatsDMetrics) {},
+		},
+		{
+			description: "disabled",
+			modify: func(cfg *StatsDMetrics) {
+				*cfg = StatsDMetrics{}
+			},
+		},
+		{
+			description: "sample-rate-zero",
+			modify: func(cfg *StatsDMetrics) {
+				cfg.SampleRate = 0
+			},
+			expectedErrs: []error{errors.New("metrics.statsd.sample_rate must be > 0 and <= 1. Got 0")},
+		},
+		{
+			description: "sample-rate-above-one",
+			modify: func(cfg *StatsDMetrics) {
+				cfg.SampleRate = 1.5
+			},
+			expectedErrs: []error{errors.New("metrics.statsd.sample_rate must be > 0 and <= 1. Got 1.5")},
+		},
+		{
+			description: "aggregation-without-interval",
+			modify: func(cfg *StatsDMetrics) {
+				cfg.AggregationFlushIntervalMS = 0
+			},
+			expectedErrs: []error{errors.New("metrics.statsd.aggregation_flush_interval_ms must be positive if metrics.statsd.client_side_aggregation is enabled. Got 0")},
+		},
+		{
+			description: "extended-aggregation-without-aggregation",
+			modify: func(cfg *StatsDMetrics) {
+				cfg.ClientSideAggregation = false
+				cfg.ExtendedAggregation = true
+			},
+			expectedErrs: []error{errors.New("metrics.statsd.extended_aggregation requires metrics.statsd.client_side_aggregation")},
+		},
+		{
+			description: "buffer-settings",
+			modify: func(cfg *StatsDMetrics) {
+				cfg.BufferFlushIntervalMS = 0
+				cfg.MaxMessagesPerPayload = -1
+				cfg.WriteTimeoutMS = 0
+			},
+			expectedErrs: []error{
+				errors.New("metrics.statsd.buffer_flush_interval_ms must be positive. Got 0"),
+				errors.New("metrics.statsd.max_messages_per_payload must be positive. Got -1"),
+				errors.New("metrics.statsd.write_timeout_ms must be positive. Got 0"),
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.description, func(t *testing.T) {
+			cfg := valid
+			test.modify(&cfg)
+			assert.Equal(t, test.expectedErrs, cfg.validate(nil))
+		})
+	}
+}
+
 func TestInvalidHostVendorID(t *testing.T) {
 	tests := []struct {
 		description  string
@@ -1228,6 +1409,205 @@ func TestInvalidHostVendorID(t *testing.T) {
 	}
 }
 
+func TestInvalidGDPRVendorList(t *testing.T) {
+	tests := []struct {
+		description  string
+		vendorList   GDPRVendorList
+		wantErrorMsg string
+	}{
+		{
+			description:  "Unknown source",
+			vendorList:   GDPRVendorList{Sources: []string{"ftp"}},
+			wantErrorMsg: `gdpr.vendorlist.sources contains unknown source "ftp", must be one of "local" or "http"`,
+		},
+		{
+			description:  "Local source without directory",
+			vendorList:   GDPRVendorList{Sources: []string{"local"}},
+			wantErrorMsg: "gdpr.vendorlist.local.dir is required when the local vendor list source is enabled",
+		},
+		{
+			description:  "HTTP source without URL",
+			vendorList:   GDPRVendorList{Sources: []string{"http"}, HTTP: GDPRVendorListHTTP{LatestURL: "https://gvl"}},
+			wantErrorMsg: "gdpr.vendorlist.http.url and gdpr.vendorlist.http.latest_url are required when the http vendor list source is enabled",
+		},
+		{
+			description:  "HTTP source with invalid proxy",
+			vendorList:   GDPRVendorList{Sources: []string{"http"}, HTTP: GDPRVendorListHTTP{URL: "https://gvl", LatestURL: "https://gvl", Proxy: "://proxy"}},
+			wantErrorMsg: `gdpr.vendorlist.http.proxy is invalid: parse "://proxy": missing protocol scheme`,
+		},
+		{
+			description:  "Incomplete pin",
+			vendorList:   GDPRVendorList{Pinned: []GDPRVendorListPin{{SpecVersion: 3}}},
+			wantErrorMsg: "gdpr.vendorlist.pinned entries must set both spec_version and list_version",
+		},
+	}
+
+	for _, tt := range tests {
+		cfg, v := newDefaultConfig(t)
+		cfg.GDPR.VendorList = tt.vendorList
+		assertOneError(t, cfg.validate(v), tt.wantErrorMsg)
+	}
+}
+
+func TestInvalidUIDStore(t *testing.T) {
+	tests := []struct {
+		description  string
+		uidStore     UIDStore
+		wantErrorMsg string
+	}{
+		{
+			description:  "Missing signing key",
+			uidStore:     UIDStore{Enabled: true, Type: "memory", TimeoutMS: 50, Memory: UIDStoreMemory{SizeBytes: 1024}},
+			wantErrorMsg: "host_cookie.uid_store.signing_key is required when the uid store is enabled",
+		},
+		{
+			description:  "Invalid timeout",
+			uidStore:     UIDStore{Enabled: true, SigningKey: "key", Type: "memory", Memory: UIDStoreMemory{SizeBytes: 1024}},
+			wantErrorMsg: "host_cookie.uid_store.timeout_ms must be > 0. Got 0",
+		},
+		{
+			description:  "Unknown type",
+			uidStore:     UIDStore{Enabled: true, SigningKey: "key", Type: "redis", TimeoutMS: 50},
+			wantErrorMsg: `host_cookie.uid_store.type "redis" is not supported, must be "memory"`,
+		},
+		{
+			description:  "Memory store without size",
+			uidStore:     UIDStore{Enabled: true, SigningKey: "key", Type: "memory", TimeoutMS: 50},
+			wantErrorMsg: "host_cookie.uid_store.memory.size_bytes must be > 0. Got 0",
+		},
+	}
+
+	for _, tt := range tests {
+		cfg, v := newDefaultConfig(t)
+		cfg.HostCookie.UIDStore = tt.uidStore
+		assertOneError(t, cfg.validate(v), tt.wantErrorMsg)
+	}
+}
+
+func TestInvalidUIDCookieSecurity(t *testing.T) {
+	tests := []struct {
+		description  string
+		security     UIDCookieSecurity
+		wantErrorMsg string
+	}{
+		{
+			description:  "Unknown mode",
+			security:     UIDCookieSecurity{Mode: "obfuscate"},
+			wantErrorMsg: `host_cookie.security.mode "obfuscate" is not supported, must be one of "none", "sign" or "encrypt"`,
+		},
+		{
+			description:  "Missing active key",
+			security:     UIDCookieSecurity{Mode: "encrypt", Keys: []UIDCookieKey{{ID: "k1", Secret: "secret"}}},
+			wantErrorMsg: `host_cookie.security.active_key is required when the mode is "encrypt"`,
+		},
+		{
+			description:  "Missing keys",
+			security:     UIDCookieSecurity{Mode: "sign", ActiveKey: "k1"},
+			wantErrorMsg: `host_cookie.security.keys or host_cookie.security.keys_file is required when the mode is "sign"`,
+		},
+		{
+			description:  "Invalid key ID",
+			security:     UIDCookieSecurity{Mode: "encrypt", ActiveKey: "k~1", Keys: []UIDCookieKey{{ID: "k~1", Secret: "secret"}}},
+			wantErrorMsg: `host_cookie.security.keys[0].id "k~1" must only hold letters, digits, '-' and '_'`,
+		},
+		{
+			description:  "Missing secret",
+			security:     UIDCookieSecurity{Mode: "encrypt", ActiveKey: "k1", Keys: []UIDCookieKey{{ID: "k1"}}},
+			wantErrorMsg: "host_cookie.security.keys[0].secret is required",
+		},
+		{
+			description:  "Unknown active key",
+			security:     UIDCookieSecurity{Mode: "encrypt", ActiveKey: "k2", Keys: []UIDCookieKey{{ID: "k1", Secret: "secret"}}},
+			wantErrorMsg: `host_cookie.security.active_key "k2" is not in host_cookie.security.keys`,
+		},
+		{
+			description:  "Malformed legacy cutoff",
+			security:     UIDCookieSecurity{Mode: "sign", ActiveKey: "k1", Keys: []UIDCookieKey{{ID: "k1", Secret: "secret"}}, LegacyCutoff: "2026-10-19"},
+			wantErrorMsg: `host_cookie.security.legacy_cutoff "2026-10-19" must be an RFC 3339 time`,
+		},
+	}
+
+	for _, tt := range tests {
+		cfg, v := newDefaultConfig(t)
+		cfg.HostCookie.Security = tt.security
+		assertOneError(t, cfg.validate(v), tt.wantErrorMsg)
+	}
+}
+
+func TestInvalidUserSyncChooser(t *testing.T) {
+	tests := []struct {
+		description  string
+		chooser      UserSyncChooser
+		wantErrorMsg string
+	}{
+		{
+			description:  "Unknown strategy",
+			chooser:      UserSyncChooser{Strategy: "round_robin"},
+			wantErrorMsg: `user_sync.chooser.strategy "round_robin" is not supported, must be "random" or "weighted"`,
+		},
+		{
+			description:  "Live stats without half life",
+			chooser:      UserSyncChooser{Strategy: "weighted", LiveStats: UserSyncLiveStats{Enabled: true}},
+			wantErrorMsg: "user_sync.chooser.live_stats.half_life_seconds must be > 0. Got 0",
+		},
+	}
+
+	for _, tt := range tests {
+		cfg, v := newDefaultConfig(t)
+		cfg.UserSync.Chooser = tt.chooser
+		assertOneError(t, cfg.validate(v), tt.wantErrorMsg)
+	}
+}
+
+func TestInvalidUIDMapping(t *testing.T) {
+	valid := UIDMapping{Enabled: true, APIKeys: []string{"key"}, MaxRecords: 10, MaxTTLSeconds: 60, TimeoutMS: 50, Type: "memory", Memory: UIDMappingMemory{SizeBytes: 1024}}
+
+	tests := []struct {
+		description  string
+		modify       func(cfg *UIDMapping)
+		wantErrorMsg string
+	}{
+		{
+			description:  "Missing api keys",
+			modify:       func(cfg *UIDMapping) { cfg.APIKeys = nil },
+			wantErrorMsg: "user_sync.uid_mapping.api_keys is required when the uid mapping is enabled",
+		},
+		{
+			description:  "Invalid max records",
+			modify:       func(cfg *UIDMapping) { cfg.MaxRecords = 0 },
+			wantErrorMsg: "user_sync.uid_mapping.max_records must be > 0. Got 0",
+		},
+		{
+			description:  "Invalid max ttl",
+			modify:       func(cfg *UIDMapping) { cfg.MaxTTLSeconds = -1 },
+			wantErrorMsg: "user_sync.uid_mapping.max_ttl_seconds must be > 0. Got -1",
+		},
+		{
+			description:  "Invalid timeout",
+			modify:       func(cfg *UIDMapping) { cfg.TimeoutMS = 0 },
+			wantErrorMsg: "user_sync.uid_mapping.timeout_ms must be > 0. Got 0",
+		},
+		{
+			description:  "Unknown type",
+			modify:       func(cfg *UIDMapping) { cfg.Type = "redis" },
+			wantErrorMsg: `user_sync.uid_mapping.type "redis" is not supported, must be "memory"`,
+		},
+		{
+			description:  "Memory store without size",
+			modify:       func(cfg *UIDMapping) { cfg.Memory.SizeBytes = 0 },
+			wantErrorMsg: "user_sync.uid_mapping.memory.size_bytes must be > 0. Got 0",
+		},
+	}
+
+	for _, tt := range tests {
+		cfg, v := newDefaultConfig(t)
+		cfg.UserSync.UIDMapping = valid
+		cfg.UserSync.UIDMapping.APIKeys = []string{"key"}
+		tt.modify(&cfg.UserSync.UIDMapping)
+		assertOneError(t, cfg.validate(v), tt.wantErrorMsg)
+	}
+}
+
 func TestInvalidAMPException(t *testing.T) {
 	cfg, v := newDefaultConfig(t)
 	cfg.GDPR.AMPException = true
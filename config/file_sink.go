@@ -0,0 +1,93 @@
+package config
+
+import (
+	"fmt"
+)
+
+// FileSink specifies the analytics module writing the auction outcomes to local segment files, which are
+// rotated, compressed and handed over to the hooks once closed.
+type FileSink struct {
+	Enabled bool `mapstructure:"enabled"`
+	// Directory is where the segments are written. It's created if it doesn't exist.
+	Directory string `mapstructure:"directory"`
+	// Prefix starts the names of the segments
+	Prefix string `mapstructure:"prefix"`
+	// Compression of the segments, among "none", "gzip" and "zstd"
+	Compression string            `mapstructure:"compression"`
+	Rotation    FileSinkRotation  `mapstructure:"rotation"`
+	Retention   FileSinkRetention `mapstructure:"retention"`
+	// CSV enables writing the records of each segment to a CSV file as well, a row per record. The CSV
+	// files are row oriented, not columnar.
+	CSV bool `mapstructure:"csv"`
+	// Hooks run on each closed segment, in order
+	Hooks []FileSinkHook `mapstructure:"hooks"`
+	// QueueSize is the number of auctions waiting to be written, beyond which they're dropped
+	QueueSize int `mapstructure:"queue_size"`
+}
+
+// FileSinkRotation specifies when the segments are closed: once MaxSizeBytes of records are written to it,
+// before compression, or MaxAgeSeconds after it's opened.
+type FileSinkRotation struct {
+	MaxSizeBytes  int64 `mapstructure:"max_size_bytes"`
+	MaxAgeSeconds int   `mapstructure:"max_age_seconds"`
+}
+
+// FileSinkRetention specifies the closed segments which are kept. A zero value keeps all of them.
+type FileSinkRetention struct {
+	MaxSegments int `mapstructure:"max_segments"`
+	MaxAgeHours int `mapstructure:"max_age_hours"`
+}
+
+// FileSinkHook runs a command on each closed segment, such as a script shipping it. The paths of the segment
+// and of its CSV file, if any, are appended to the arguments of the command.
+type FileSinkHook struct {
+	Command   []string `mapstructure:"command"`
+	TimeoutMS int      `mapstructure:"timeout_ms"`
+}
+
+const (
+	FileSinkCompressionNone = "none"
+	FileSinkCompressionGzip = "gzip"
+	FileSinkCompressionZstd = "zstd"
+)
+
+func (cfg *FileSink) validate(errs []error) []error {
+	if !cfg.Enabled {
+		return errs
+	}
+	if cfg.Directory == "" {
+		errs = append(errs, fmt.Errorf("analytics.file_sink.directory is required"))
+	}
+	if cfg.Prefix == "" {
+		errs = append(errs, fmt.Errorf("analytics.file_sink.prefix is required"))
+	}
+	switch cfg.Compression {
+	case FileSinkCompressionNone, FileSinkCompressionGzip, FileSinkCompressionZstd:
+	default:
+		errs = append(errs, fmt.Errorf("analytics.file_sink.compression %q must be one of none, gzip or zstd", cfg.Compression))
+	}
+	if cfg.Rotation.MaxSizeBytes <= 0 {
+		errs = append(errs, fmt.Errorf("analytics.file_sink.rotation.max_size_bytes must be > 0. Got %d", cfg.Rotation.MaxSizeBytes))
+	}
+	if cfg.Rotation.MaxAgeSeconds <= 0 {
+		errs = append(errs, fmt.Errorf("analytics.file_sink.rotation.max_age_seconds must be > 0. Got %d", cfg.Rotation.MaxAgeSeconds))
+	}
+	if cfg.Retention.MaxSegments < 0 {
+		errs = append(errs, fmt.Errorf("analytics.file_sink.retention.max_segments must be >= 0. Got %d", cfg.Retention.MaxSegments))
+	}
+	if cfg.Retention.MaxAgeHours < 0 {
+		errs = append(errs, fmt.Errorf("analytics.file_sink.retention.max_age_hours must be >= 0. Got %d", cfg.Retention.MaxAgeHours))
+	}
+	for i, hook := range cfg.Hooks {
+		if len(hook.Command) == 0 || hook.Command[0] == "" {
+			errs = append(errs, fmt.Errorf("analytics.file_sink.hooks[%d].command is required", i))
+		}
+		if hook.TimeoutMS < 0 {
+			errs = append(errs, fmt.Errorf("analytics.file_sink.hooks[%d].timeout_ms must be >= 0. Got %d", i, hook.TimeoutMS))
+		}
+	}
+	if cfg.QueueSize <= 0 {
+		errs = append(errs, fmt.Errorf("analytics.file_sink.queue_size must be > 0. Got %d", cfg.QueueSize))
+	}
+	return errs
+}
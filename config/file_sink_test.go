@@ -0,0 +1,95 @@
+package config
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFileSinkValidate(t *testing.T) {
+	validCfg := FileSink{
+		Enabled:     true,
+		Directory:   "/var/log/pbs",
+		Prefix:      "pbs-analytics",
+		Compression: FileSinkCompressionZstd,
+		Rotation:    FileSinkRotation{MaxSizeBytes: 1024, MaxAgeSeconds: 60},
+		Hooks:       []FileSinkHook{{Command: []string{"/usr/local/bin/ship"}}},
+		QueueSize:   100,
+	}
+
+	testCases := []struct {
+		description  string
+		cfg          func(cfg FileSink) FileSink
+		expectedErrs []error
+	}{
+		{
+			description: "valid",
+			cfg:         func(cfg FileSink) FileSink { return cfg },
+		},
+		{
+			description: "disabled",
+			cfg:         func(cfg FileSink) FileSink { return FileSink{} },
+		},
+		{
+			description: "missing_directory_and_prefix",
+			cfg: func(cfg FileSink) FileSink {
+				cfg.Directory = ""
+				cfg.Prefix = ""
+				return cfg
+			},
+			expectedErrs: []error{
+				errors.New("analytics.file_sink.directory is required"),
+				errors.New("analytics.file_sink.prefix is required"),
+			},
+		},
+		{
+			description: "unknown_compression",
+			cfg: func(cfg FileSink) FileSink {
+				cfg.Compression = "brotli"
+				return cfg
+			},
+			expectedErrs: []error{errors.New(`analytics.file_sink.compression "brotli" must be one of none, gzip or zstd`)},
+		},
+		{
+			description: "invalid_rotation_and_retention",
+			cfg: func(cfg FileSink) FileSink {
+				cfg.Rotation = FileSinkRotation{}
+				cfg.Retention = FileSinkRetention{MaxSegments: -1, MaxAgeHours: -1}
+				return cfg
+			},
+			expectedErrs: []error{
+				errors.New("analytics.file_sink.rotation.max_size_bytes must be > 0. Got 0"),
+				errors.New("analytics.file_sink.rotation.max_age_seconds must be > 0. Got 0"),
+				errors.New("analytics.file_sink.retention.max_segments must be >= 0. Got -1"),
+				errors.New("analytics.file_sink.retention.max_age_hours must be >= 0. Got -1"),
+			},
+		},
+		{
+			description: "invalid_hook",
+			cfg: func(cfg FileSink) FileSink {
+				cfg.Hooks = []FileSinkHook{{TimeoutMS: -1}}
+				return cfg
+			},
+			expectedErrs: []error{
+				errors.New("analytics.file_sink.hooks[0].command is required"),
+				errors.New("analytics.file_sink.hooks[0].timeout_ms must be >= 0. Got -1"),
+			},
+		},
+		{
+			description: "invalid_queue_size",
+			cfg: func(cfg FileSink) FileSink {
+				cfg.QueueSize = 0
+				return cfg
+			},
+			expectedErrs: []error{errors.New("analytics.file_sink.queue_size must be > 0. Got 0")},
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.description, func(t *testing.T) {
+			cfg := test.cfg(validCfg)
+			assert.Equal(t, test.expectedErrs, cfg.validate(nil))
+		})
+	}
+}
@@ -0,0 +1,145 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+)
+
+// HTTPAnalytics specifies the generic analytics module, which posts the analytics events to HTTP destinations
+// in batches.
+type HTTPAnalytics struct {
+	Enabled      bool                       `mapstructure:"enabled"`
+	Destinations []HTTPAnalyticsDestination `mapstructure:"destinations"`
+}
+
+// HTTPAnalyticsDestination is an endpoint the analytics events are posted to, as JSON arrays
+type HTTPAnalyticsDestination struct {
+	// Name identifies the destination in the logs and metrics
+	Name    string            `mapstructure:"name"`
+	URL     string            `mapstructure:"url"`
+	Headers map[string]string `mapstructure:"headers"`
+	// TimeoutMS is the timeout of each post. Defaults to 2000.
+	TimeoutMS int  `mapstructure:"timeout_ms"`
+	Gzip      bool `mapstructure:"gzip"`
+	// Events are the types of the events posted, among the HTTPAnalyticsEventTypes. All of them are posted if it's empty.
+	Events   []string              `mapstructure:"events"`
+	Sampling HTTPAnalyticsSampling `mapstructure:"sampling"`
+	// Fields maps the fields of the posted events to the paths of their values in the analytics events, such as
+	// "response.seatbid.#.seat". The whole analytics events are posted if it's empty.
+	Fields  map[string]string    `mapstructure:"fields"`
+	Buffers HTTPAnalyticsBuffers `mapstructure:"buffers"`
+	Retry   HTTPAnalyticsRetry   `mapstructure:"retry"`
+	// QueueSize is the number of events waiting to be posted, beyond which the events are dropped. Defaults to 1000.
+	QueueSize int `mapstructure:"queue_size"`
+}
+
+// HTTPAnalyticsSampling specifies the share of the events which are posted
+type HTTPAnalyticsSampling struct {
+	// Rate is the share of the events posted, in [0, 1]. Defaults to 1.
+	Rate *float64 `mapstructure:"rate"`
+	// Accounts overrides the rate of the events of some accounts
+	Accounts map[string]float64 `mapstructure:"accounts"`
+}
+
+// HTTPAnalyticsBuffers specifies when the buffered events are posted: once the buffer holds Count events or
+// SizeBytes bytes, and every TimeoutMS. They default to 100 events, 1MB and 5000ms.
+type HTTPAnalyticsBuffers struct {
+	Count     int `mapstructure:"count"`
+	SizeBytes int `mapstructure:"size_bytes"`
+	TimeoutMS int `mapstructure:"timeout_ms"`
+}
+
+// HTTPAnalyticsRetry specifies the retries of the failed posts, with an exponential backoff from BackoffMS
+// up to MaxBackoffMS. The backoffs default to 100ms and 5000ms.
+type HTTPAnalyticsRetry struct {
+	MaxRetries   int `mapstructure:"max_retries"`
+	BackoffMS    int `mapstructure:"backoff_ms"`
+	MaxBackoffMS int `mapstructure:"max_backoff_ms"`
+}
+
+const (
+	HTTPAnalyticsEventAuction      = "auction"
+	HTTPAnalyticsEventAMP          = "amp"
+	HTTPAnalyticsEventVideo        = "video"
+	HTTPAnalyticsEventCookieSync   = "cookie_sync"
+	HTTPAnalyticsEventSetUID       = "setuid"
+	HTTPAnalyticsEventNotification = "event"
+)
+
+// HTTPAnalyticsEventTypes returns the types of the analytics events
+func HTTPAnalyticsEventTypes() []string {
+	return []string{
+		HTTPAnalyticsEventAuction,
+		HTTPAnalyticsEventAMP,
+		HTTPAnalyticsEventVideo,
+		HTTPAnalyticsEventCookieSync,
+		HTTPAnalyticsEventSetUID,
+		HTTPAnalyticsEventNotification,
+	}
+}
+
+func (cfg *HTTPAnalytics) validate(errs []error) []error {
+	if !cfg.Enabled {
+		return errs
+	}
+	if len(cfg.Destinations) == 0 {
+		errs = append(errs, fmt.Errorf("analytics.http.destinations must have at least one destination"))
+	}
+
+	names := make(map[string]bool, len(cfg.Destinations))
+	for i, destination := range cfg.Destinations {
+		section := fmt.Sprintf("analytics.http.destinations[%d]", i)
+		if destination.Name == "" {
+			errs = append(errs, fmt.Errorf("%s.name is required", section))
+		} else if names[destination.Name] {
+			errs = append(errs, fmt.Errorf("%s.name %q is used by another destination", section, destination.Name))
+		}
+		names[destination.Name] = true
+
+		if parsed, err := url.Parse(destination.URL); err != nil || parsed.Scheme == "" || parsed.Host == "" {
+			errs = append(errs, fmt.Errorf("%s.url %q must be an absolute URL", section, destination.URL))
+		}
+		for _, event := range destination.Events {
+			if !isHTTPAnalyticsEventType(event) {
+				errs = append(errs, fmt.Errorf("%s.events %q isn't an event type, must be one of %v", section, event, HTTPAnalyticsEventTypes()))
+			}
+		}
+		if rate := destination.Sampling.Rate; rate != nil && (*rate < 0 || *rate > 1) {
+			errs = append(errs, fmt.Errorf("%s.sampling.rate must be in the range [0, 1]. Got %g", section, *rate))
+		}
+		for account, rate := range destination.Sampling.Accounts {
+			if rate < 0 || rate > 1 {
+				errs = append(errs, fmt.Errorf("%s.sampling.accounts.%s must be in the range [0, 1]. Got %g", section, account, rate))
+			}
+		}
+
+		nonNegative := []struct {
+			field string
+			value int
+		}{
+			{"timeout_ms", destination.TimeoutMS},
+			{"queue_size", destination.QueueSize},
+			{"buffers.count", destination.Buffers.Count},
+			{"buffers.size_bytes", destination.Buffers.SizeBytes},
+			{"buffers.timeout_ms", destination.Buffers.TimeoutMS},
+			{"retry.max_retries", destination.Retry.MaxRetries},
+			{"retry.backoff_ms", destination.Retry.BackoffMS},
+			{"retry.max_backoff_ms", destination.Retry.MaxBackoffMS},
+		}
+		for _, n := range nonNegative {
+			if n.value < 0 {
+				errs = append(errs, fmt.Errorf("%s.%s must be >= 0. Got %d", section, n.field, n.value))
+			}
+		}
+	}
+	return errs
+}
+
+func isHTTPAnalyticsEventType(event string) bool {
+	for _, eventType := range HTTPAnalyticsEventTypes() {
+		if event == eventType {
+			return true
+		}
+	}
+	return false
+}
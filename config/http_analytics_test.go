@@ -0,0 +1,106 @@
+package config
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/prebid/prebid-server/v3/util/ptrutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHTTPAnalyticsValidate(t *testing.T) {
+	validDestination := HTTPAnalyticsDestination{
+		Name:   "warehouse",
+		URL:    "https://analytics.example.com/events",
+		Events: []string{HTTPAnalyticsEventAuction, HTTPAnalyticsEventNotification},
+		Sampling: HTTPAnalyticsSampling{
+			Rate:     ptrutil.ToPtr(0.1),
+			Accounts: map[string]float64{"1001": 1},
+		},
+	}
+
+	testCases := []struct {
+		description  string
+		cfg          HTTPAnalytics
+		expectedErrs []error
+	}{
+		{
+			description: "disabled",
+			cfg:         HTTPAnalytics{Enabled: false, Destinations: []HTTPAnalyticsDestination{{}}},
+		},
+		{
+			description: "valid",
+			cfg:         HTTPAnalytics{Enabled: true, Destinations: []HTTPAnalyticsDestination{validDestination}},
+		},
+		{
+			description:  "no_destinations",
+			cfg:          HTTPAnalytics{Enabled: true},
+			expectedErrs: []error{errors.New("analytics.http.destinations must have at least one destination")},
+		},
+		{
+			description: "missing_name_and_relative_url",
+			cfg:         HTTPAnalytics{Enabled: true, Destinations: []HTTPAnalyticsDestination{{URL: "/events"}}},
+			expectedErrs: []error{
+				errors.New("analytics.http.destinations[0].name is required"),
+				errors.New(`analytics.http.destinations[0].url "/events" must be an absolute URL`),
+			},
+		},
+		{
+			description: "duplicate_name",
+			cfg:         HTTPAnalytics{Enabled: true, Destinations: []HTTPAnalyticsDestination{validDestination, validDestination}},
+			expectedErrs: []error{
+				errors.New(`analytics.http.destinations[1].name "warehouse" is used by another destination`),
+			},
+		},
+		{
+			description: "unknown_event",
+			cfg: HTTPAnalytics{Enabled: true, Destinations: []HTTPAnalyticsDestination{
+				{Name: "warehouse", URL: "https://analytics.example.com", Events: []string{"win"}},
+			}},
+			expectedErrs: []error{
+				errors.New(`analytics.http.destinations[0].events "win" isn't an event type, must be one of [auction amp video cookie_sync setuid event]`),
+			},
+		},
+		{
+			description: "invalid_rates",
+			cfg: HTTPAnalytics{Enabled: true, Destinations: []HTTPAnalyticsDestination{
+				{
+					Name: "warehouse",
+					URL:  "https://analytics.example.com",
+					Sampling: HTTPAnalyticsSampling{
+						Rate:     ptrutil.ToPtr(1.5),
+						Accounts: map[string]float64{"1001": -0.5},
+					},
+				},
+			}},
+			expectedErrs: []error{
+				errors.New("analytics.http.destinations[0].sampling.rate must be in the range [0, 1]. Got 1.5"),
+				errors.New("analytics.http.destinations[0].sampling.accounts.1001 must be in the range [0, 1]. Got -0.5"),
+			},
+		},
+		{
+			description: "negative_values",
+			cfg: HTTPAnalytics{Enabled: true, Destinations: []HTTPAnalyticsDestination{
+				{
+					Name:      "warehouse",
+					URL:       "https://analytics.example.com",
+					QueueSize: -1,
+					Buffers:   HTTPAnalyticsBuffers{Count: -1},
+					Retry:     HTTPAnalyticsRetry{MaxRetries: -1},
+				},
+			}},
+			expectedErrs: []error{
+				errors.New("analytics.http.destinations[0].queue_size must be >= 0. Got -1"),
+				errors.New("analytics.http.destinations[0].buffers.count must be >= 0. Got -1"),
+				errors.New("analytics.http.destinations[0].retry.max_retries must be >= 0. Got -1"),
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.description, func(t *testing.T) {
+			errs := test.cfg.validate(nil)
+			assert.Equal(t, test.expectedErrs, errs)
+		})
+	}
+}
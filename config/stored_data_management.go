@@ -0,0 +1,87 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+)
+
+// StoredDataManagement specifies the admin API which creates, updates and deletes the stored requests, imps,
+// responses and accounts. The writes are validated, and persisted to a database or to the filesystem.
+type StoredDataManagement struct {
+	Enabled bool `mapstructure:"enabled"`
+	// Backend is where the stored data is persisted, "database" or "filesystem"
+	Backend    string                         `mapstructure:"backend"`
+	Database   StoredDataManagementDatabase   `mapstructure:"database"`
+	Filesystem StoredDataManagementFilesystem `mapstructure:"filesystem"`
+	TimeoutMS  int                            `mapstructure:"timeout_ms"`
+}
+
+// StoredDataManagementDatabase specifies the database tables of the stored data. Each data table has the id, data,
+// version and last_updated columns. The deleted entries are kept with a null data, so that the database event
+// producers invalidate them in the caches of every instance.
+type StoredDataManagementDatabase struct {
+	Connection DatabaseConnection `mapstructure:"connection"`
+	Tables     StoredDataTables   `mapstructure:"tables"`
+}
+
+type StoredDataTables struct {
+	Requests  string `mapstructure:"requests"`
+	Imps      string `mapstructure:"imps"`
+	Responses string `mapstructure:"responses"`
+	Accounts  string `mapstructure:"accounts"`
+	// Audit is the table of the audit log, with the data_type, id, version, action, data, author and changed_at columns
+	Audit string `mapstructure:"audit"`
+}
+
+// StoredDataManagementFilesystem specifies the directory of the stored data, laid out as the filesystem backend
+// reads it, and the file of the audit log.
+type StoredDataManagementFilesystem struct {
+	DirectoryPath string `mapstructure:"directorypath"`
+	AuditPath     string `mapstructure:"audit_path"`
+}
+
+const (
+	StoredDataManagementBackendDatabase   = "database"
+	StoredDataManagementBackendFilesystem = "filesystem"
+)
+
+var tableNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*(\.[A-Za-z_][A-Za-z0-9_]*)?$`)
+
+func (cfg *StoredDataManagement) validate(errs []error) []error {
+	if !cfg.Enabled {
+		return errs
+	}
+	switch cfg.Backend {
+	case StoredDataManagementBackendDatabase:
+		if cfg.Database.Connection.Driver == "" || cfg.Database.Connection.Database == "" {
+			errs = append(errs, errors.New("stored_data_management.database.connection.driver and dbname are required with the database backend"))
+		}
+		tables := []struct{ name, table string }{
+			{"requests", cfg.Database.Tables.Requests},
+			{"imps", cfg.Database.Tables.Imps},
+			{"responses", cfg.Database.Tables.Responses},
+			{"accounts", cfg.Database.Tables.Accounts},
+			{"audit", cfg.Database.Tables.Audit},
+		}
+		// the table names are written into the queries, so they're restricted to identifiers
+		for _, t := range tables {
+			if !tableNamePattern.MatchString(t.table) {
+				errs = append(errs, fmt.Errorf("stored_data_management.database.tables.%s %q isn't a valid table name", t.name, t.table))
+			}
+		}
+	case StoredDataManagementBackendFilesystem:
+		if cfg.Filesystem.DirectoryPath == "" {
+			errs = append(errs, errors.New("stored_data_management.filesystem.directorypath is required with the filesystem backend"))
+		}
+		if cfg.Filesystem.AuditPath == "" {
+			errs = append(errs, errors.New("stored_data_management.filesystem.audit_path is required with the filesystem backend"))
+		}
+	default:
+		errs = append(errs, fmt.Errorf("stored_data_management.backend %q is not supported, must be %q or %q", cfg.Backend, StoredDataManagementBackendDatabase, StoredDataManagementBackendFilesystem))
+	}
+	if cfg.TimeoutMS <= 0 {
+		errs = append(errs, fmt.Errorf("stored_data_management.timeout_ms must be > 0. Got %d", cfg.TimeoutMS))
+	}
+	return errs
+}
@@ -2,6 +2,7 @@ package config
 
 import (
 	"fmt"
+	"net/url"
 	"strings"
 	"time"
 
@@ -62,9 +63,15 @@ type StoredRequests struct {
 	// HTTP configures an instance of stored_requests/backends/http/http_fetcher.go.
 	// If non-nil, Stored Requests will be fetched from the endpoint described there.
 	HTTP HTTPFetcherConfig `mapstructure:"http"`
+	// S3 configures an instance of stored_requests/backends/s3_fetcher/fetcher.go.
+	// If enabled, Stored Requests will be fetched from an S3 compatible bucket.
+	S3 S3FetcherConfig `mapstructure:"s3"`
 	// InMemoryCache configures an instance of stored_requests/caches/memory/cache.go.
 	// If non-nil, Stored Requests will be saved in an in-memory cache.
 	InMemoryCache InMemoryCache `mapstructure:"in_memory_cache"`
+	// RedisCache configures an instance of stored_requests/caches/redis/cache.go.
+	// If enabled, Stored Requests will be saved in a cache shared by the instances, behind the in-memory cache.
+	RedisCache RedisCache `mapstructure:"redis_cache"`
 	// CacheEvents configures an instance of stored_requests/events/api/api.go.
 	// This is a sub-object containing the endpoint name to use for this API endpoint.
 	CacheEvents CacheEventsConfig `mapstructure:"cache_events"`
@@ -103,6 +110,141 @@ type FileFetcherConfig struct {
 	Enabled bool `mapstructure:"enabled"`
 	// Path to the directory this file fetcher gets data from.
 	Path string `mapstructure:"directorypath"`
+	// Watch reloads the files when they are added, changed or deleted.
+	Watch FileWatchConfig `mapstructure:"watch"`
+}
+
+// FileWatchConfig configures the reload of the files of a stored_requests/backends/file_fetcher/fetcher.go
+type FileWatchConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+	// Polling checks the files for changes every PollIntervalMS instead of using filesystem notifications,
+	// which are not available on every filesystem. Polling is also used when the notifications fail to start.
+	Polling        bool `mapstructure:"polling"`
+	PollIntervalMS int  `mapstructure:"poll_interval_ms"`
+	// DebounceMS is how long the files must go unchanged before they are reloaded, so that a burst
+	// of changes causes a single reload.
+	DebounceMS int `mapstructure:"debounce_ms"`
+}
+
+func (cfg *FileWatchConfig) validate(section string, errs []error) []error {
+	if !cfg.Enabled {
+		return errs
+	}
+	if cfg.PollIntervalMS <= 0 {
+		errs = append(errs, fmt.Errorf("%s.filesystem.watch.poll_interval_ms must be > 0. Got %d", section, cfg.PollIntervalMS))
+	}
+	if cfg.DebounceMS < 0 {
+		errs = append(errs, fmt.Errorf("%s.filesystem.watch.debounce_ms must be >= 0. Got %d", section, cfg.DebounceMS))
+	}
+	return errs
+}
+
+// S3FetcherConfig configures a stored_requests/backends/s3_fetcher/fetcher.go
+type S3FetcherConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+	// Endpoint is the URL of the S3 compatible service, like https://s3.us-east-1.amazonaws.com
+	Endpoint string `mapstructure:"endpoint"`
+	Region   string `mapstructure:"region"`
+	Bucket   string `mapstructure:"bucket"`
+	// ForcePathStyle puts the bucket in the path of the object URLs rather than in the host name,
+	// which most self-hosted S3 compatible services require.
+	ForcePathStyle bool `mapstructure:"force_path_style"`
+	// AccessKeyID and SecretAccessKey sign the requests. The requests are anonymous when they're empty.
+	AccessKeyID     string `mapstructure:"access_key_id"`
+	SecretAccessKey string `mapstructure:"secret_access_key"`
+	// Prefixes are the key prefixes of the objects of each data type. An object is named "{prefix}{id}.json".
+	Prefixes S3Prefixes `mapstructure:"prefixes"`
+	// Gzip should be true if the objects are gzip compressed and named "{prefix}{id}.json.gz".
+	Gzip      bool `mapstructure:"gzip"`
+	TimeoutMS int  `mapstructure:"timeout_ms"`
+	// RefreshRateSeconds is how often the bucket is listed to find the changed and deleted objects,
+	// which are then updated in the caches. The bucket is never listed if it is 0.
+	RefreshRateSeconds int `mapstructure:"refresh_rate_seconds"`
+}
+
+// S3Prefixes are the key prefixes of the stored data in the bucket
+type S3Prefixes struct {
+	Requests  string `mapstructure:"requests"`
+	Imps      string `mapstructure:"imps"`
+	Responses string `mapstructure:"responses"`
+	Accounts  string `mapstructure:"accounts"`
+}
+
+func (cfg *S3FetcherConfig) validate(section string, errs []error) []error {
+	if !cfg.Enabled {
+		return errs
+	}
+	if cfg.Bucket == "" {
+		errs = append(errs, fmt.Errorf("%s.s3.bucket must be set", section))
+	}
+	if cfg.Region == "" {
+		errs = append(errs, fmt.Errorf("%s.s3.region must be set", section))
+	}
+	if endpoint, err := url.Parse(cfg.Endpoint); err != nil || endpoint.Scheme == "" || endpoint.Host == "" {
+		errs = append(errs, fmt.Errorf("%s.s3.endpoint must be a valid URL. Got %s", section, cfg.Endpoint))
+	}
+	if (cfg.AccessKeyID == "") != (cfg.SecretAccessKey == "") {
+		errs = append(errs, fmt.Errorf("%s.s3: access_key_id and secret_access_key must be set together", section))
+	}
+	if cfg.TimeoutMS <= 0 {
+		errs = append(errs, fmt.Errorf("%s.s3.timeout_ms must be > 0. Got %d", section, cfg.TimeoutMS))
+	}
+	if cfg.RefreshRateSeconds < 0 {
+		errs = append(errs, fmt.Errorf("%s.s3.refresh_rate_seconds must be >= 0. Got %d", section, cfg.RefreshRateSeconds))
+	}
+	return errs
+}
+
+// RedisCache configures a stored_requests/caches/redis/cache.go, a cache in a server speaking the Redis protocol
+type RedisCache struct {
+	Enabled bool `mapstructure:"enabled"`
+	// Address is the host:port of the server
+	Address  string `mapstructure:"address"`
+	Password string `mapstructure:"password"`
+	DB       int    `mapstructure:"db"`
+	TLS      bool   `mapstructure:"tls"`
+	// Prefixes are the key prefixes of the entries of each data type. An entry is stored as "{prefix}{id}".
+	Prefixes RedisCachePrefixes `mapstructure:"prefixes"`
+	// TTL is the number of seconds the entries are kept. They're kept until they're invalidated if it's 0.
+	TTL       int `mapstructure:"ttl_seconds"`
+	TimeoutMS int `mapstructure:"timeout_ms"`
+	// PoolSize is the maximum number of idle connections
+	PoolSize int `mapstructure:"pool_size"`
+	// RetryIntervalMS is how long the cache is bypassed after the server failed, before it's tried again
+	RetryIntervalMS int `mapstructure:"retry_interval_ms"`
+}
+
+// RedisCachePrefixes are the key prefixes of the stored data in the Redis cache
+type RedisCachePrefixes struct {
+	Requests  string `mapstructure:"requests"`
+	Imps      string `mapstructure:"imps"`
+	Responses string `mapstructure:"responses"`
+	Accounts  string `mapstructure:"accounts"`
+}
+
+func (cfg *RedisCache) validate(section string, errs []error) []error {
+	if !cfg.Enabled {
+		return errs
+	}
+	if cfg.Address == "" {
+		errs = append(errs, fmt.Errorf("%s.redis_cache.address must be set", section))
+	}
+	if cfg.DB < 0 {
+		errs = append(errs, fmt.Errorf("%s.redis_cache.db must be >= 0. Got %d", section, cfg.DB))
+	}
+	if cfg.TTL < 0 {
+		errs = append(errs, fmt.Errorf("%s.redis_cache.ttl_seconds must be >= 0. Got %d", section, cfg.TTL))
+	}
+	if cfg.TimeoutMS <= 0 {
+		errs = append(errs, fmt.Errorf("%s.redis_cache.timeout_ms must be > 0. Got %d", section, cfg.TimeoutMS))
+	}
+	if cfg.PoolSize <= 0 {
+		errs = append(errs, fmt.Errorf("%s.redis_cache.pool_size must be > 0. Got %d", section, cfg.PoolSize))
+	}
+	if cfg.RetryIntervalMS < 0 {
+		errs = append(errs, fmt.Errorf("%s.redis_cache.retry_interval_ms must be >= 0. Got %d", section, cfg.RetryIntervalMS))
+	}
+	return errs
 }
 
 // HTTPFetcherConfig configures a stored_requests/backends/http_fetcher/fetcher.go
@@ -126,6 +268,7 @@ func resolvedStoredRequestsConfig(cfg *Configuration) {
 	amp.HTTP.Endpoint = sr.HTTP.AmpEndpoint
 	amp.CacheEvents.Endpoint = "/storedrequests/amp"
 	amp.HTTPEvents.Endpoint = sr.HTTPEvents.AmpEndpoint
+	amp.RedisCache.Prefixes.Requests = sr.RedisCache.Prefixes.Requests + "amp:"
 
 	// Set data types for each section
 	cfg.StoredRequests.dataType = RequestDataType
@@ -142,11 +285,19 @@ func (cfg *StoredRequests) validate(errs []error) []error {
 	} else {
 		errs = cfg.Database.validate(cfg.DataType(), errs)
 	}
+	errs = cfg.Files.Watch.validate(cfg.Section(), errs)
 
 	// Categories do not use cache so none of the following checks apply
 	if cfg.DataType() == CategoryDataType {
+		if cfg.S3.Enabled {
+			errs = append(errs, fmt.Errorf("%s.s3: retrieving categories via s3 not available, use %s.filesystem or %s.http", cfg.Section(), cfg.Section(), cfg.Section()))
+		}
+		if cfg.RedisCache.Enabled {
+			errs = append(errs, fmt.Errorf("%s.redis_cache: categories are not cached", cfg.Section()))
+		}
 		return errs
 	}
+	errs = cfg.S3.validate(cfg.Section(), errs)
 
 	if cfg.InMemoryCache.Type == "none" {
 		if cfg.CacheEvents.Enabled {
@@ -163,8 +314,12 @@ func (cfg *StoredRequests) validate(errs []error) []error {
 		if cfg.Database.CacheInitialization.Query != "" {
 			errs = append(errs, fmt.Errorf("%s: database.initialize_caches.query must be empty if in_memory_cache=none", cfg.Section()))
 		}
+		if cfg.S3.RefreshRateSeconds != 0 {
+			errs = append(errs, fmt.Errorf("%s: s3.refresh_rate_seconds must be 0 if in_memory_cache=none", cfg.Section()))
+		}
 	}
 	errs = cfg.InMemoryCache.validate(cfg.DataType(), errs)
+	errs = cfg.RedisCache.validate(cfg.Section(), errs)
 	return errs
 }
 
@@ -322,10 +477,16 @@ type InMemoryCache struct {
 	ImpCacheSize int `mapstructure:"imp_cache_size_bytes"`
 	// ResponsesCacheSize is the max number of bytes allowed in the cache for Stored Responses. Values <= 0 will have no limit
 	RespCacheSize int `mapstructure:"resp_cache_size_bytes"`
+	// NegativeTTL is the number of seconds the IDs which weren't found are remembered, so that the backend isn't
+	// queried again for them. They're saved or invalidated by the events like the data. 0 disables it.
+	NegativeTTL int `mapstructure:"negative_ttl_seconds"`
 }
 
 func (cfg *InMemoryCache) validate(dataType DataType, errs []error) []error {
 	section := dataType.Section()
+	if cfg.NegativeTTL < 0 {
+		errs = append(errs, fmt.Errorf("%s: in_memory_cache.negative_ttl_seconds must be >= 0. Got %d", section, cfg.NegativeTTL))
+	}
 	switch cfg.Type {
 	case "none":
 		// No errors for no config options
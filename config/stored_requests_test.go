@@ -61,6 +61,14 @@ func TestInMemoryCacheValidationStoredRequests(t *testing.T) {
 		Type: "lru",
 		Size: 1000,
 	}).validate(RequestDataType, nil))
+	assertNoErrs(t, (&InMemoryCache{
+		Type:        "none",
+		NegativeTTL: 10,
+	}).validate(RequestDataType, nil))
+	assertErrsExist(t, (&InMemoryCache{
+		Type:        "none",
+		NegativeTTL: -1,
+	}).validate(RequestDataType, nil))
 }
 
 func TestInMemoryCacheValidationSingleCache(t *testing.T) {
@@ -103,6 +111,179 @@ func TestInMemoryCacheValidationSingleCache(t *testing.T) {
 	}).validate(AccountDataType, nil))
 }
 
+func TestFileWatchConfigValidation(t *testing.T) {
+	tests := []struct {
+		description string
+		cfg         FileWatchConfig
+		expectedErr []error
+	}{
+		{
+			description: "Disabled",
+			cfg:         FileWatchConfig{PollIntervalMS: -1, DebounceMS: -1},
+		},
+		{
+			description: "Valid",
+			cfg:         FileWatchConfig{Enabled: true, PollIntervalMS: 1000, DebounceMS: 0},
+		},
+		{
+			description: "Invalid Poll Interval",
+			cfg:         FileWatchConfig{Enabled: true, PollIntervalMS: 0},
+			expectedErr: []error{errors.New("stored_requests.filesystem.watch.poll_interval_ms must be > 0. Got 0")},
+		},
+		{
+			description: "Invalid Debounce",
+			cfg:         FileWatchConfig{Enabled: true, PollIntervalMS: 1000, DebounceMS: -1},
+			expectedErr: []error{errors.New("stored_requests.filesystem.watch.debounce_ms must be >= 0. Got -1")},
+		},
+	}
+
+	for _, test := range tests {
+		errs := test.cfg.validate("stored_requests", nil)
+		assert.Equal(t, test.expectedErr, errs, test.description)
+	}
+}
+
+func TestS3FetcherConfigValidation(t *testing.T) {
+	validConfig := func() S3FetcherConfig {
+		return S3FetcherConfig{
+			Enabled:   true,
+			Endpoint:  "https://s3.amazonaws.com",
+			Region:    "us-east-1",
+			Bucket:    "stored-data",
+			TimeoutMS: 1000,
+		}
+	}
+
+	tests := []struct {
+		description string
+		cfg         func(cfg *S3FetcherConfig)
+		expectedErr []error
+	}{
+		{
+			description: "Disabled",
+			cfg: func(cfg *S3FetcherConfig) {
+				*cfg = S3FetcherConfig{}
+			},
+		},
+		{
+			description: "Valid",
+			cfg:         func(cfg *S3FetcherConfig) {},
+		},
+		{
+			description: "Valid With Credentials",
+			cfg: func(cfg *S3FetcherConfig) {
+				cfg.AccessKeyID, cfg.SecretAccessKey = "key", "secret"
+			},
+		},
+		{
+			description: "Missing Bucket And Region",
+			cfg: func(cfg *S3FetcherConfig) {
+				cfg.Bucket, cfg.Region = "", ""
+			},
+			expectedErr: []error{
+				errors.New("stored_requests.s3.bucket must be set"),
+				errors.New("stored_requests.s3.region must be set"),
+			},
+		},
+		{
+			description: "Invalid Endpoint",
+			cfg: func(cfg *S3FetcherConfig) {
+				cfg.Endpoint = "s3.amazonaws.com"
+			},
+			expectedErr: []error{errors.New("stored_requests.s3.endpoint must be a valid URL. Got s3.amazonaws.com")},
+		},
+		{
+			description: "Partial Credentials",
+			cfg: func(cfg *S3FetcherConfig) {
+				cfg.AccessKeyID = "key"
+			},
+			expectedErr: []error{errors.New("stored_requests.s3: access_key_id and secret_access_key must be set together")},
+		},
+		{
+			description: "Invalid Timeout And Refresh Rate",
+			cfg: func(cfg *S3FetcherConfig) {
+				cfg.TimeoutMS, cfg.RefreshRateSeconds = 0, -1
+			},
+			expectedErr: []error{
+				errors.New("stored_requests.s3.timeout_ms must be > 0. Got 0"),
+				errors.New("stored_requests.s3.refresh_rate_seconds must be >= 0. Got -1"),
+			},
+		},
+	}
+
+	for _, test := range tests {
+		cfg := validConfig()
+		test.cfg(&cfg)
+		errs := cfg.validate("stored_requests", nil)
+		assert.Equal(t, test.expectedErr, errs, test.description)
+	}
+}
+
+func TestRedisCacheConfigValidation(t *testing.T) {
+	validConfig := func() RedisCache {
+		return RedisCache{
+			Enabled:         true,
+			Address:         "localhost:6379",
+			TTL:             3600,
+			TimeoutMS:       50,
+			PoolSize:        10,
+			RetryIntervalMS: 5000,
+		}
+	}
+
+	tests := []struct {
+		description string
+		cfg         func(cfg *RedisCache)
+		expectedErr []error
+	}{
+		{
+			description: "Disabled",
+			cfg: func(cfg *RedisCache) {
+				*cfg = RedisCache{}
+			},
+		},
+		{
+			description: "Valid",
+			cfg:         func(cfg *RedisCache) {},
+		},
+		{
+			description: "Valid Without TTL",
+			cfg: func(cfg *RedisCache) {
+				cfg.TTL = 0
+			},
+		},
+		{
+			description: "Missing Address And Invalid DB",
+			cfg: func(cfg *RedisCache) {
+				cfg.Address, cfg.DB = "", -1
+			},
+			expectedErr: []error{
+				errors.New("stored_requests.redis_cache.address must be set"),
+				errors.New("stored_requests.redis_cache.db must be >= 0. Got -1"),
+			},
+		},
+		{
+			description: "Invalid Durations And Pool Size",
+			cfg: func(cfg *RedisCache) {
+				cfg.TTL, cfg.TimeoutMS, cfg.PoolSize, cfg.RetryIntervalMS = -1, 0, 0, -1
+			},
+			expectedErr: []error{
+				errors.New("stored_requests.redis_cache.ttl_seconds must be >= 0. Got -1"),
+				errors.New("stored_requests.redis_cache.timeout_ms must be > 0. Got 0"),
+				errors.New("stored_requests.redis_cache.pool_size must be > 0. Got 0"),
+				errors.New("stored_requests.redis_cache.retry_interval_ms must be >= 0. Got -1"),
+			},
+		},
+	}
+
+	for _, test := range tests {
+		cfg := validConfig()
+		test.cfg(&cfg)
+		errs := cfg.validate("stored_requests", nil)
+		assert.Equal(t, test.expectedErr, errs, test.description)
+	}
+}
+
 func TestDatabaseConfigValidation(t *testing.T) {
 	tests := []struct {
 		description            string
@@ -290,3 +471,85 @@ func TestResolveConfig(t *testing.T) {
 	assertStringsEqual(t, amp.HTTPEvents.Endpoint, cfg.StoredRequests.HTTPEvents.AmpEndpoint)
 	assertStringsEqual(t, amp.CacheEvents.Endpoint, "/storedrequests/amp")
 }
+
+func TestStoredDataManagementValidation(t *testing.T) {
+	validConfig := func() StoredDataManagement {
+		return StoredDataManagement{
+			Enabled: true,
+			Backend: StoredDataManagementBackendDatabase,
+			Database: StoredDataManagementDatabase{
+				Connection: DatabaseConnection{Driver: "postgres", Database: "prebid"},
+				Tables: StoredDataTables{
+					Requests:  "stored_requests",
+					Imps:      "stored_imps",
+					Responses: "stored_responses",
+					Accounts:  "public.accounts",
+					Audit:     "stored_data_audit",
+				},
+			},
+			TimeoutMS: 1000,
+		}
+	}
+
+	tests := []struct {
+		description string
+		cfg         func(cfg *StoredDataManagement)
+		expectedErr []error
+	}{
+		{
+			description: "Disabled",
+			cfg: func(cfg *StoredDataManagement) {
+				*cfg = StoredDataManagement{}
+			},
+		},
+		{
+			description: "Valid Database",
+			cfg:         func(cfg *StoredDataManagement) {},
+		},
+		{
+			description: "Valid Filesystem",
+			cfg: func(cfg *StoredDataManagement) {
+				cfg.Backend = StoredDataManagementBackendFilesystem
+				cfg.Filesystem = StoredDataManagementFilesystem{DirectoryPath: "stored_data", AuditPath: "audit.jsonl"}
+			},
+		},
+		{
+			description: "Missing Connection And Invalid Table",
+			cfg: func(cfg *StoredDataManagement) {
+				cfg.Database.Connection.Database = ""
+				cfg.Database.Tables.Audit = "audit; DROP TABLE accounts"
+			},
+			expectedErr: []error{
+				errors.New("stored_data_management.database.connection.driver and dbname are required with the database backend"),
+				errors.New(`stored_data_management.database.tables.audit "audit; DROP TABLE accounts" isn't a valid table name`),
+			},
+		},
+		{
+			description: "Missing Filesystem Paths",
+			cfg: func(cfg *StoredDataManagement) {
+				cfg.Backend = StoredDataManagementBackendFilesystem
+			},
+			expectedErr: []error{
+				errors.New("stored_data_management.filesystem.directorypath is required with the filesystem backend"),
+				errors.New("stored_data_management.filesystem.audit_path is required with the filesystem backend"),
+			},
+		},
+		{
+			description: "Unknown Backend And Invalid Timeout",
+			cfg: func(cfg *StoredDataManagement) {
+				cfg.Backend, cfg.TimeoutMS = "http", 0
+			},
+			expectedErr: []error{
+				errors.New(`stored_data_management.backend "http" is not supported, must be "database" or "filesystem"`),
+				errors.New("stored_data_management.timeout_ms must be > 0. Got 0"),
+			},
+		},
+	}
+
+	for _, test := range tests {
+		cfg := validConfig()
+		test.cfg(&cfg)
+		errs := cfg.validate(nil)
+		assert.Equal(t, test.expectedErr, errs, test.description)
+	}
+}
@@ -0,0 +1,49 @@
+package config
+
+import (
+	"fmt"
+	"time"
+)
+
+// StoredVersionRollout selects the version of a versioned stored request or stored imp. It's held
+// by the stored entry itself, or by the account for the stored IDs it overrides.
+type StoredVersionRollout struct {
+	// Default is the version used when no other rule applies
+	Default string `mapstructure:"default" json:"default"`
+	// Schedule replaces the default version with the latest version whose activation time has passed
+	Schedule []StoredVersionActivation `mapstructure:"schedule" json:"schedule"`
+	// Split sends a percentage of the requests to other versions
+	Split []StoredVersionSplit `mapstructure:"split" json:"split"`
+}
+
+// StoredVersionActivation activates a version at an RFC 3339 time
+type StoredVersionActivation struct {
+	Version    string `mapstructure:"version" json:"version"`
+	ActivateAt string `mapstructure:"activate_at" json:"activate_at"`
+}
+
+// StoredVersionSplit sends a percentage of the requests to a version
+type StoredVersionSplit struct {
+	Version string `mapstructure:"version" json:"version"`
+	Percent int    `mapstructure:"percent" json:"percent"`
+}
+
+// Validate checks the split percentages and the activation times of the rollout
+func (r *StoredVersionRollout) Validate() error {
+	total := 0
+	for _, split := range r.Split {
+		if split.Percent < 0 || split.Percent > 100 {
+			return fmt.Errorf("the percent of version %s must be between 0 and 100", split.Version)
+		}
+		total += split.Percent
+	}
+	if total > 100 {
+		return fmt.Errorf("the split percents add up to %d, more than 100", total)
+	}
+	for _, activation := range r.Schedule {
+		if _, err := time.Parse(time.RFC3339, activation.ActivateAt); err != nil {
+			return fmt.Errorf("the activation time of version %s isn't a valid RFC 3339 time: %v", activation.Version, err)
+		}
+	}
+	return nil
+}
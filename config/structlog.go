@@ -14,6 +14,8 @@ type logMsg func(string, ...interface{})
 var mapregex = regexp.MustCompile(`mapstructure:"([^"]+)"`)
 var blocklistregexp = []*regexp.Regexp{
 	regexp.MustCompile("password"),
+	regexp.MustCompile("secret"),
+	regexp.MustCompile("signing_key"),
 }
 
 // LogGeneral will log nearly any sort of value, but requires the name of the root object to be in the
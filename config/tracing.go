@@ -0,0 +1,47 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Tracing configures the OpenTelemetry traces of the requests, exported over OTLP
+type Tracing struct {
+	Enabled     bool   `mapstructure:"enabled"`
+	ServiceName string `mapstructure:"service_name"`
+	// SampleRate is the share of the traces started by Prebid Server which are sampled. The requests with a
+	// traceparent header are sampled as their caller decided.
+	SampleRate float64 `mapstructure:"sample_rate"`
+	// PropagateToBidders sends the traceparent header to the bidders
+	PropagateToBidders bool        `mapstructure:"propagate_to_bidders"`
+	OTLP               TracingOTLP `mapstructure:"otlp"`
+}
+
+// TracingOTLP configures the OTLP over HTTP exporter of the spans
+type TracingOTLP struct {
+	// Endpoint is the host and port of the collector
+	Endpoint string `mapstructure:"endpoint"`
+	// Insecure exports over HTTP rather than HTTPS
+	Insecure  bool              `mapstructure:"insecure"`
+	Headers   map[string]string `mapstructure:"headers"`
+	TimeoutMS int               `mapstructure:"timeout_ms"`
+}
+
+func (cfg *Tracing) validate(errs []error) []error {
+	if !cfg.Enabled {
+		return errs
+	}
+	if cfg.ServiceName == "" {
+		errs = append(errs, errors.New("tracing.service_name is required"))
+	}
+	if cfg.SampleRate < 0 || cfg.SampleRate > 1 {
+		errs = append(errs, fmt.Errorf("tracing.sample_rate must be between 0 and 1. Got %g", cfg.SampleRate))
+	}
+	if cfg.OTLP.Endpoint == "" {
+		errs = append(errs, errors.New("tracing.otlp.endpoint is required"))
+	}
+	if cfg.OTLP.TimeoutMS <= 0 {
+		errs = append(errs, fmt.Errorf("tracing.otlp.timeout_ms must be > 0. Got %d", cfg.OTLP.TimeoutMS))
+	}
+	return errs
+}
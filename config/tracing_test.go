@@ -0,0 +1,41 @@
+package config
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTracingValidate(t *testing.T) {
+	testCases := []struct {
+		description  string
+		cfg          Tracing
+		expectedErrs []error
+	}{
+		{
+			description: "disabled",
+			cfg:         Tracing{},
+		},
+		{
+			description: "valid",
+			cfg:         Tracing{Enabled: true, ServiceName: "prebid-server", SampleRate: 1, OTLP: TracingOTLP{Endpoint: "collector:4318", TimeoutMS: 1000}},
+		},
+		{
+			description: "invalid",
+			cfg:         Tracing{Enabled: true, SampleRate: 1.5},
+			expectedErrs: []error{
+				errors.New("tracing.service_name is required"),
+				errors.New("tracing.sample_rate must be between 0 and 1. Got 1.5"),
+				errors.New("tracing.otlp.endpoint is required"),
+				errors.New("tracing.otlp.timeout_ms must be > 0. Got 0"),
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.description, func(t *testing.T) {
+			assert.Equal(t, test.expectedErrs, test.cfg.validate(nil))
+		})
+	}
+}
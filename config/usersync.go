@@ -1,14 +1,108 @@
 package config
 
+import (
+	"errors"
+	"fmt"
+)
+
 // UserSync specifies the static global user sync configuration.
 type UserSync struct {
 	Cooperative    UserSyncCooperative `mapstructure:"coop_sync"`
 	ExternalURL    string              `mapstructure:"external_url"`
 	RedirectURL    string              `mapstructure:"redirect_url"`
 	PriorityGroups [][]string          `mapstructure:"priority_groups"`
+	Chooser        UserSyncChooser     `mapstructure:"chooser"`
+	UIDMapping     UIDMapping          `mapstructure:"uid_mapping"`
 }
 
 // UserSyncCooperative specifies the static global default cooperative cookie sync
 type UserSyncCooperative struct {
 	EnabledByDefault bool `mapstructure:"default"`
 }
+
+// UserSyncChooser specifies how the cookie sync endpoint orders the bidders within each group
+// before applying the limit.
+type UserSyncChooser struct {
+	// Strategy is the default ordering strategy, which accounts may override.
+	Strategy string `mapstructure:"strategy"`
+	// LiveStats derives the bidder weights from the bid rate and average CPM seen in the auctions,
+	// used by the weighted strategy when the account doesn't configure bidder weights.
+	LiveStats UserSyncLiveStats `mapstructure:"live_stats"`
+}
+
+// UserSyncLiveStats specifies the collection of bidder stats for the weighted strategy
+type UserSyncLiveStats struct {
+	Enabled bool `mapstructure:"enabled"`
+	// HalfLifeSeconds is the age at which auction outcomes count half as much as recent ones.
+	HalfLifeSeconds int `mapstructure:"half_life_seconds"`
+}
+
+const (
+	// UserSyncChooserStrategyRandom shuffles the bidders
+	UserSyncChooserStrategyRandom = "random"
+	// UserSyncChooserStrategyWeighted shuffles the bidders so the bidders with higher weights are more
+	// likely to sync first
+	UserSyncChooserStrategyWeighted = "weighted"
+)
+
+func (cfg *UserSyncChooser) validate(errs []error) []error {
+	switch cfg.Strategy {
+	case "", UserSyncChooserStrategyRandom, UserSyncChooserStrategyWeighted:
+	default:
+		errs = append(errs, fmt.Errorf("user_sync.chooser.strategy %q is not supported, must be %q or %q", cfg.Strategy, UserSyncChooserStrategyRandom, UserSyncChooserStrategyWeighted))
+	}
+	if cfg.LiveStats.Enabled && cfg.LiveStats.HalfLifeSeconds <= 0 {
+		errs = append(errs, fmt.Errorf("user_sync.chooser.live_stats.half_life_seconds must be > 0. Got %d", cfg.LiveStats.HalfLifeSeconds))
+	}
+	return errs
+}
+
+// UIDMapping specifies the server to server ingestion of partner UIDs, for the partners which can't
+// sync through the browser. The UIDs are mapped to the host cookie ID or to a hashed email, and are
+// used by the auction when the uids cookie has no UID for a bidder.
+type UIDMapping struct {
+	Enabled bool `mapstructure:"enabled"`
+	// APIKeys are the bearer tokens the partners authenticate with
+	APIKeys       []string `mapstructure:"api_keys"`
+	MaxRecords    int      `mapstructure:"max_records"`
+	MaxTTLSeconds int      `mapstructure:"max_ttl_seconds"`
+	TimeoutMS     int      `mapstructure:"timeout_ms"`
+	// HashedEmailEIDSource is the source of the request EID holding the SHA-256 hashed email of the user
+	HashedEmailEIDSource string           `mapstructure:"hashed_email_eid_source"`
+	Type                 string           `mapstructure:"type"`
+	Memory               UIDMappingMemory `mapstructure:"memory"`
+}
+
+// UIDMappingMemory specifies the in-memory UID mapping store, which evicts the least recently used keys once full
+type UIDMappingMemory struct {
+	SizeBytes int `mapstructure:"size_bytes"`
+}
+
+const UIDMappingTypeMemory = "memory"
+
+func (cfg *UIDMapping) validate(errs []error) []error {
+	if !cfg.Enabled {
+		return errs
+	}
+	if len(cfg.APIKeys) == 0 {
+		errs = append(errs, errors.New("user_sync.uid_mapping.api_keys is required when the uid mapping is enabled"))
+	}
+	if cfg.MaxRecords <= 0 {
+		errs = append(errs, fmt.Errorf("user_sync.uid_mapping.max_records must be > 0. Got %d", cfg.MaxRecords))
+	}
+	if cfg.MaxTTLSeconds <= 0 {
+		errs = append(errs, fmt.Errorf("user_sync.uid_mapping.max_ttl_seconds must be > 0. Got %d", cfg.MaxTTLSeconds))
+	}
+	if cfg.TimeoutMS <= 0 {
+		errs = append(errs, fmt.Errorf("user_sync.uid_mapping.timeout_ms must be > 0. Got %d", cfg.TimeoutMS))
+	}
+	switch cfg.Type {
+	case UIDMappingTypeMemory:
+		if cfg.Memory.SizeBytes <= 0 {
+			errs = append(errs, fmt.Errorf("user_sync.uid_mapping.memory.size_bytes must be > 0. Got %d", cfg.Memory.SizeBytes))
+		}
+	default:
+		errs = append(errs, fmt.Errorf("user_sync.uid_mapping.type %q is not supported, must be %q", cfg.Type, UIDMappingTypeMemory))
+	}
+	return errs
+}
@@ -1,6 +1,7 @@
 package currency
 
 import (
+	"context"
 	"fmt"
 	"io"
 	"net/http"
@@ -9,8 +10,10 @@ import (
 
 	"github.com/golang/glog"
 	"github.com/prebid/prebid-server/v3/errortypes"
+	"github.com/prebid/prebid-server/v3/tracing"
 	"github.com/prebid/prebid-server/v3/util/jsonutil"
 	"github.com/prebid/prebid-server/v3/util/timeutil"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // RateConverter holds the currencies conversion rates dictionary
@@ -76,7 +79,10 @@ func (rc *RateConverter) fetch() (*Rates, error) {
 
 // Update updates the internal currencies rates from remote sources
 func (rc *RateConverter) update() error {
+	_, span := tracing.Start(context.Background(), "currency.update_rates", trace.WithSpanKind(trace.SpanKindClient))
 	rates, err := rc.fetch()
+	tracing.End(span, err)
+
 	if err == nil {
 		rc.rates.Store(rates)
 		rc.lastUpdated.Store(rc.time.Now())
@@ -0,0 +1,78 @@
+package endpoints
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/golang/glog"
+
+	"github.com/prebid/prebid-server/v3/stored_requests"
+	"github.com/prebid/prebid-server/v3/util/jsonutil"
+)
+
+// resolvedAccountResponse is the account merged over its parent chain and the account defaults. Sources holds
+// the layer each field comes from: the account defaults or the ID of an account of the chain.
+type resolvedAccountResponse struct {
+	ID      string            `json:"id"`
+	Chain   []string          `json:"chain"`
+	Account json.RawMessage   `json:"account"`
+	Sources map[string]string `json:"sources"`
+}
+
+// NewResolvedAccountEndpoint returns the config of the account of the id query param as the auctions see it,
+// along with the layer each field comes from.
+func NewResolvedAccountEndpoint(resolver stored_requests.AccountResolver, accountDefaultsJSON json.RawMessage) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			w.Header().Set("Allow", "GET")
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+
+		accountID := r.URL.Query().Get("id")
+		if accountID == "" {
+			writeResolvedAccountError(w, http.StatusBadRequest, errors.New("the id query param is required"))
+			return
+		}
+
+		resolved, errs := resolver.ResolveAccount(r.Context(), accountDefaultsJSON, accountID)
+		if len(errs) > 0 {
+			status := http.StatusInternalServerError
+			switch errs[0].(type) {
+			case stored_requests.NotFoundError:
+				status = http.StatusNotFound
+			case stored_requests.AccountInheritanceError:
+				status = http.StatusUnprocessableEntity
+			}
+			writeResolvedAccountError(w, status, errs[0])
+			return
+		}
+
+		sources, err := stored_requests.AccountSources(resolved.Layers)
+		if err != nil {
+			writeResolvedAccountError(w, http.StatusUnprocessableEntity, err)
+			return
+		}
+
+		jsonOutput, err := jsonutil.Marshal(resolvedAccountResponse{
+			ID:      accountID,
+			Chain:   resolved.Chain,
+			Account: resolved.Account,
+			Sources: sources,
+		})
+		if err != nil {
+			glog.Errorf("/accounts/resolved Critical error when trying to marshal the account: %v", err)
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(jsonOutput)
+	}
+}
+
+func writeResolvedAccountError(w http.ResponseWriter, status int, err error) {
+	w.WriteHeader(status)
+	w.Write([]byte(err.Error()))
+}
@@ -0,0 +1,107 @@
+package endpoints
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/prebid/prebid-server/v3/stored_requests"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeAccountResolver struct {
+	resolved stored_requests.ResolvedAccount
+	err      error
+}
+
+func (r fakeAccountResolver) ResolveAccount(ctx context.Context, accountDefaultsJSON json.RawMessage, accountID string) (stored_requests.ResolvedAccount, []error) {
+	if r.err != nil {
+		return stored_requests.ResolvedAccount{}, []error{r.err}
+	}
+	return r.resolved, nil
+}
+
+func TestResolvedAccountEndpoint(t *testing.T) {
+	resolved := stored_requests.ResolvedAccount{
+		Account: json.RawMessage(`{"disabled":false,"gdpr":{"enabled":true},"parent":"network"}`),
+		Chain:   []string{"network", "publisher"},
+		Layers: []stored_requests.AccountLayer{
+			{Name: stored_requests.AccountDefaultsLayer, Data: json.RawMessage(`{"disabled":false,"gdpr":{"enabled":false}}`)},
+			{Name: "network", Data: json.RawMessage(`{"gdpr":{"enabled":true}}`)},
+			{Name: "publisher", Data: json.RawMessage(`{"parent":"network"}`)},
+		},
+	}
+
+	testCases := []struct {
+		name           string
+		method         string
+		url            string
+		resolver       fakeAccountResolver
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:           "resolved",
+			method:         http.MethodGet,
+			url:            "/accounts/resolved?id=publisher",
+			resolver:       fakeAccountResolver{resolved: resolved},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"id":"publisher","chain":["network","publisher"],"account":{"disabled":false,"gdpr":{"enabled":true},"parent":"network"},"sources":{"disabled":"defaults","gdpr.enabled":"network","parent":"publisher"}}`,
+		},
+		{
+			name:           "missing_id",
+			method:         http.MethodGet,
+			url:            "/accounts/resolved",
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "the id query param is required",
+		},
+		{
+			name:           "not_found",
+			method:         http.MethodGet,
+			url:            "/accounts/resolved?id=unknown",
+			resolver:       fakeAccountResolver{err: stored_requests.NotFoundError{ID: "unknown", DataType: "Account"}},
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   `Stored Account with ID="unknown" not found.`,
+		},
+		{
+			name:           "broken_chain",
+			method:         http.MethodGet,
+			url:            "/accounts/resolved?id=publisher",
+			resolver:       fakeAccountResolver{err: stored_requests.AccountInheritanceError{AccountID: "publisher", Message: "it has a cycle: publisher -> publisher"}},
+			expectedStatus: http.StatusUnprocessableEntity,
+			expectedBody:   "The parent chain of account publisher is invalid: it has a cycle: publisher -> publisher",
+		},
+		{
+			name:           "fetch_error",
+			method:         http.MethodGet,
+			url:            "/accounts/resolved?id=publisher",
+			resolver:       fakeAccountResolver{err: errors.New("timeout")},
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   "timeout",
+		},
+		{
+			name:           "wrong_method",
+			method:         http.MethodPost,
+			url:            "/accounts/resolved?id=publisher",
+			expectedStatus: http.StatusMethodNotAllowed,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.name, func(t *testing.T) {
+			handler := NewResolvedAccountEndpoint(test.resolver, json.RawMessage(`{"disabled":false}`))
+			w := httptest.NewRecorder()
+			handler(w, httptest.NewRequest(test.method, test.url, nil))
+
+			assert.Equal(t, test.expectedStatus, w.Code)
+			if test.expectedStatus == http.StatusOK {
+				assert.JSONEq(t, test.expectedBody, w.Body.String())
+			} else {
+				assert.Equal(t, test.expectedBody, w.Body.String())
+			}
+		})
+	}
+}
@@ -30,6 +30,7 @@ import (
 	"github.com/prebid/prebid-server/v3/stored_requests"
 	"github.com/prebid/prebid-server/v3/usersync"
 	"github.com/prebid/prebid-server/v3/util/jsonutil"
+	"github.com/prebid/prebid-server/v3/util/ptrutil"
 	stringutil "github.com/prebid/prebid-server/v3/util/stringutil"
 	"github.com/prebid/prebid-server/v3/util/timeutil"
 )
@@ -58,7 +59,9 @@ func NewCookieSyncEndpoint(
 	metrics metrics.MetricsEngine,
 	analyticsRunner analytics.Runner,
 	accountsFetcher stored_requests.AccountFetcher,
-	bidders map[string]openrtb_ext.BidderName) HTTPRouterHandler {
+	bidders map[string]openrtb_ext.BidderName,
+	cookieDecoder usersync.Decoder,
+	liveBidderWeights usersync.BidderWeights) HTTPRouterHandler {
 
 	bidderHashSet := make(map[string]struct{}, len(bidders))
 	for _, bidder := range bidders {
@@ -75,10 +78,12 @@ func NewCookieSyncEndpoint(
 			ccpaEnforce:            config.CCPA.Enforce,
 			bidderHashSet:          bidderHashSet,
 		},
-		metrics:         metrics,
-		pbsAnalytics:    analyticsRunner,
-		accountsFetcher: accountsFetcher,
-		time:            &timeutil.RealTime{},
+		metrics:           metrics,
+		pbsAnalytics:      analyticsRunner,
+		accountsFetcher:   accountsFetcher,
+		time:              &timeutil.RealTime{},
+		cookieDecoder:     cookieDecoder,
+		liveBidderWeights: liveBidderWeights,
 	}
 }
 
@@ -90,6 +95,9 @@ type cookieSyncEndpoint struct {
 	pbsAnalytics    analytics.Runner
 	accountsFetcher stored_requests.AccountFetcher
 	time            timeutil.Time
+	cookieDecoder   usersync.Decoder
+	// liveBidderWeights are the bidder weights derived from the auctions, nil when not collected
+	liveBidderWeights usersync.BidderWeights
 }
 
 func (c *cookieSyncEndpoint) Handle(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
@@ -100,9 +108,7 @@ func (c *cookieSyncEndpoint) Handle(w http.ResponseWriter, r *http.Request, _ ht
 		c.handleError(w, err, http.StatusBadRequest)
 		return
 	}
-	decoder := usersync.Base64Decoder{}
-
-	cookie := usersync.ReadCookie(r, decoder, &c.config.HostCookie)
+	cookie := usersync.ReadCookie(r, c.cookieDecoder, &c.config.HostCookie)
 	usersync.SyncHostCookie(r, cookie, &c.config.HostCookie)
 
 	result := c.chooser.Choose(request, cookie)
@@ -113,11 +119,11 @@ func (c *cookieSyncEndpoint) Handle(w http.ResponseWriter, r *http.Request, _ ht
 		c.handleError(w, errCookieSyncOptOut, http.StatusUnauthorized)
 	case usersync.StatusBlockedByPrivacy:
 		c.metrics.RecordCookieSync(metrics.CookieSyncGDPRHostCookieBlocked)
-		c.handleResponse(w, request.SyncTypeFilter, cookie, privacyMacros, nil, result.BiddersEvaluated, request.Debug)
+		c.handleResponse(w, request.SyncTypeFilter, cookie, privacyMacros, nil, result.BiddersEvaluated, result.BidderOrder, request.Debug)
 	case usersync.StatusOK:
 		c.metrics.RecordCookieSync(metrics.CookieSyncOK)
 		c.writeSyncerMetrics(result.BiddersEvaluated)
-		c.handleResponse(w, request.SyncTypeFilter, cookie, privacyMacros, result.SyncersChosen, result.BiddersEvaluated, request.Debug)
+		c.handleResponse(w, request.SyncTypeFilter, cookie, privacyMacros, result.SyncersChosen, result.BiddersEvaluated, result.BidderOrder, request.Debug)
 	}
 }
 
@@ -197,10 +203,27 @@ func (c *cookieSyncEndpoint) parseRequest(r *http.Request) (usersync.Request, ma
 		},
 		SyncTypeFilter: syncTypeFilter,
 		GPPSID:         request.GPPSID,
+		BidderWeights:  c.bidderWeights(account.CookieSync),
 	}
 	return rx, privacyMacros, account, nil
 }
 
+// bidderWeights returns the weights ordering the bidders to sync, or nil when the bidders are ordered at random
+func (c *cookieSyncEndpoint) bidderWeights(cookieSyncConfig config.CookieSync) usersync.BidderWeights {
+	strategy := c.config.UserSync.Chooser.Strategy
+	if cookieSyncConfig.Strategy != nil {
+		strategy = *cookieSyncConfig.Strategy
+	}
+	if strategy != config.UserSyncChooserStrategyWeighted {
+		return nil
+	}
+
+	if len(cookieSyncConfig.BidderWeights) > 0 {
+		return usersync.NewStaticBidderWeights(cookieSyncConfig.BidderWeights)
+	}
+	return c.liveBidderWeights
+}
+
 func extractPrivacyPolicies(request cookieSyncRequest, usersyncDefaultGDPRValue string) (macros.UserSyncPrivacy, gdpr.Signal, privacy.Policies, error) {
 	// GDPR
 	gppSID, err := stringutil.StrToInt8Slice(request.GPPSID)
@@ -253,6 +276,7 @@ func extractPrivacyPolicies(request cookieSyncRequest, usersyncDefaultGDPRValue
 
 	privacyPolicies := privacy.Policies{
 		GPPSID: gppSID,
+		GPP:    request.GPP,
 	}
 
 	return privacyMacros, gdprSignal, privacyPolicies, nil
@@ -435,7 +459,7 @@ func (c *cookieSyncEndpoint) writeSyncerMetrics(biddersEvaluated []usersync.Bidd
 	}
 }
 
-func (c *cookieSyncEndpoint) handleResponse(w http.ResponseWriter, tf usersync.SyncTypeFilter, co *usersync.Cookie, m macros.UserSyncPrivacy, s []usersync.SyncerChoice, biddersEvaluated []usersync.BidderEvaluation, debug bool) {
+func (c *cookieSyncEndpoint) handleResponse(w http.ResponseWriter, tf usersync.SyncTypeFilter, co *usersync.Cookie, m macros.UserSyncPrivacy, s []usersync.SyncerChoice, biddersEvaluated []usersync.BidderEvaluation, bidderOrder []usersync.BidderWeight, debug bool) {
 	status := "no_cookie"
 	if co.HasAnyLiveSyncs() {
 		status = "ok"
@@ -481,6 +505,14 @@ func (c *cookieSyncEndpoint) handleResponse(w http.ResponseWriter, tf usersync.S
 			biddersSeen[bidderEval.Bidder] = struct{}{}
 		}
 		response.Debug = debugInfo
+
+		for _, bidderWeight := range bidderOrder {
+			orderResponse := cookieSyncResponseBidderOrder{Bidder: bidderWeight.Bidder}
+			if bidderWeight.Known {
+				orderResponse.Weight = ptrutil.ToPtr(bidderWeight.Weight)
+			}
+			response.BidderOrder = append(response.BidderOrder, orderResponse)
+		}
 	}
 
 	c.pbsAnalytics.LogCookieSyncObject(&analytics.CookieSyncObject{
@@ -587,6 +619,8 @@ type cookieSyncResponse struct {
 	Status       string                     `json:"status"`
 	BidderStatus []cookieSyncResponseBidder `json:"bidder_status"`
 	Debug        []cookieSyncResponseDebug  `json:"debug,omitempty"`
+	// BidderOrder lists the evaluated bidders in order, when the bidders are ordered by weight
+	BidderOrder []cookieSyncResponseBidderOrder `json:"bidder_order,omitempty"`
 }
 
 type cookieSyncResponseBidder struct {
@@ -606,6 +640,11 @@ type cookieSyncResponseDebug struct {
 	Error  string `json:"error,omitempty"`
 }
 
+type cookieSyncResponseBidderOrder struct {
+	Bidder string   `json:"bidder"`
+	Weight *float64 `json:"weight,omitempty"`
+}
+
 type usersyncPrivacyConfig struct {
 	gdprConfig             config.GDPR
 	gdprPermissionsBuilder gdpr.PermissionsBuilder
@@ -75,6 +75,8 @@ func TestNewCookieSyncEndpoint(t *testing.T) {
 		&analytics,
 		&fetcher,
 		bidders,
+		usersync.Base64Decoder{},
+		nil,
 	)
 	result := endpoint.(*cookieSyncEndpoint)
 
@@ -381,6 +383,7 @@ func TestCookieSyncHandle(t *testing.T) {
 			pbsAnalytics:    &mockAnalytics,
 			accountsFetcher: &fakeAccountFetcher,
 			time:            &fakeTime{time: time.Date(2024, 2, 22, 9, 42, 4, 13, time.UTC)},
+			cookieDecoder:   usersync.Base64Decoder{},
 		}
 		assert.NoError(t, endpoint.config.MarshalAccountDefaults())
 
@@ -566,7 +569,7 @@ func TestExtractPrivacyPolicies(t *testing.T) {
 					GPPSID:      "6",
 				},
 				gdprSignal: gdpr.SignalNo,
-				policies:   privacy.Policies{GPPSID: []int8{6}},
+				policies:   privacy.Policies{GPPSID: []int8{6}, GPP: "DBACNYA~CPXxRfAPXxRfAAfKABENB-CgAAAAAAAAAAYgAAAAAAAA~1YNN"},
 				err:        nil,
 			},
 		},
@@ -635,7 +638,7 @@ func TestCookieSyncParseRequest(t *testing.T) {
 				Privacy: usersyncPrivacy{
 					gdprPermissions:  &fakePermissions{},
 					ccpaParsedPolicy: expectedCCPAParsedPolicy,
-					activityRequest:  privacy.NewRequestFromPolicies(privacy.Policies{GPPSID: []int8{2}}),
+					activityRequest:  privacy.NewRequestFromPolicies(privacy.Policies{GPPSID: []int8{2}, GPP: "DBABMA~CPXxRfAPXxRfAAfKABENB-CgAAAAAAAAAAYgAAAAAAAA"}),
 					gdprSignal:       1,
 				},
 				SyncTypeFilter: usersync.SyncTypeFilter{
@@ -1812,6 +1815,7 @@ func TestCookieSyncHandleResponse(t *testing.T) {
 		givenCookieHasSyncs bool
 		givenSyncersChosen  []usersync.SyncerChoice
 		givenDebug          bool
+		givenBidderOrder    []usersync.BidderWeight
 		expectedJSON        string
 		expectedAnalytics   analytics.CookieSyncObject
 	}{
@@ -1897,6 +1901,23 @@ func TestCookieSyncHandleResponse(t *testing.T) {
 			expectedJSON:        `{"status":"ok","bidder_status":[],"debug":[{"bidder":"Bidder1","error":"Already in sync"},{"bidder":"Bidder2","error":"Unsupported bidder"},{"bidder":"Bidder3","error":"No sync config"},{"bidder":"Bidder4","error":"Rejected by privacy"},{"bidder":"Bidder5","error":"Rejected by request filter"},{"bidder":"Bidder6","error":"Status blocked by user opt out"},{"bidder":"Bidder7","error":"Sync disabled by config"},{"bidder":"BidderA","error":"Duplicate bidder synced as syncerB"}]}` + "\n",
 			expectedAnalytics:   analytics.CookieSyncObject{Status: 200, BidderStatus: []*analytics.CookieSyncBidder{}},
 		},
+		{
+			description:         "Debug is true with bidders ordered by weight, should see the bidder order in response",
+			givenCookieHasSyncs: true,
+			givenDebug:          true,
+			givenSyncersChosen:  []usersync.SyncerChoice{},
+			givenBidderOrder:    []usersync.BidderWeight{{Bidder: "Bidder1", Weight: 2.5, Known: true}, {Bidder: "Bidder2"}},
+			expectedJSON:        `{"status":"ok","bidder_status":[],"debug":[{"bidder":"Bidder1","error":"Already in sync"},{"bidder":"Bidder2","error":"Unsupported bidder"},{"bidder":"Bidder3","error":"No sync config"},{"bidder":"Bidder4","error":"Rejected by privacy"},{"bidder":"Bidder5","error":"Rejected by request filter"},{"bidder":"Bidder6","error":"Status blocked by user opt out"},{"bidder":"Bidder7","error":"Sync disabled by config"},{"bidder":"BidderA","error":"Duplicate bidder synced as syncerB"}],"bidder_order":[{"bidder":"Bidder1","weight":2.5},{"bidder":"Bidder2"}]}` + "\n",
+			expectedAnalytics:   analytics.CookieSyncObject{Status: 200, BidderStatus: []*analytics.CookieSyncBidder{}},
+		},
+		{
+			description:         "Debug is false with bidders ordered by weight, should not see the bidder order in response",
+			givenCookieHasSyncs: true,
+			givenSyncersChosen:  []usersync.SyncerChoice{},
+			givenBidderOrder:    []usersync.BidderWeight{{Bidder: "Bidder1", Weight: 2.5, Known: true}},
+			expectedJSON:        `{"status":"ok","bidder_status":[]}` + "\n",
+			expectedAnalytics:   analytics.CookieSyncObject{Status: 200, BidderStatus: []*analytics.CookieSyncBidder{}},
+		},
 	}
 
 	for _, test := range testCases {
@@ -1919,7 +1940,7 @@ func TestCookieSyncHandleResponse(t *testing.T) {
 		} else {
 			bidderEval = []usersync.BidderEvaluation{}
 		}
-		endpoint.handleResponse(writer, syncTypeFilter, cookie, privacyMacros, test.givenSyncersChosen, bidderEval, test.givenDebug)
+		endpoint.handleResponse(writer, syncTypeFilter, cookie, privacyMacros, test.givenSyncersChosen, bidderEval, test.givenBidderOrder, test.givenDebug)
 
 		if assert.Equal(t, writer.Code, http.StatusOK, test.description+":http_status") {
 			assert.Equal(t, writer.Header().Get("Content-Type"), "application/json; charset=utf-8", test.description+":http_header")
@@ -1929,6 +1950,65 @@ func TestCookieSyncHandleResponse(t *testing.T) {
 	}
 }
 
+func TestCookieSyncBidderWeights(t *testing.T) {
+	liveWeights := usersync.NewStaticBidderWeights(map[string]float64{"live": 1})
+
+	testCases := []struct {
+		description       string
+		givenHostStrategy string
+		givenCookieSync   config.CookieSync
+		givenLiveWeights  usersync.BidderWeights
+		expected          usersync.BidderWeights
+	}{
+		{
+			description:       "Random",
+			givenHostStrategy: config.UserSyncChooserStrategyRandom,
+			givenLiveWeights:  liveWeights,
+			expected:          nil,
+		},
+		{
+			description:       "Weighted - Live Weights",
+			givenHostStrategy: config.UserSyncChooserStrategyWeighted,
+			givenLiveWeights:  liveWeights,
+			expected:          liveWeights,
+		},
+		{
+			description:       "Weighted - Live Weights Not Collected",
+			givenHostStrategy: config.UserSyncChooserStrategyWeighted,
+			expected:          nil,
+		},
+		{
+			description:       "Weighted - Account Weights",
+			givenHostStrategy: config.UserSyncChooserStrategyWeighted,
+			givenCookieSync:   config.CookieSync{BidderWeights: map[string]float64{"Account": 2}},
+			givenLiveWeights:  liveWeights,
+			expected:          usersync.StaticBidderWeights{"account": 2},
+		},
+		{
+			description:       "Account Overrides Host Strategy - Weighted",
+			givenHostStrategy: config.UserSyncChooserStrategyRandom,
+			givenCookieSync:   config.CookieSync{Strategy: ptrutil.ToPtr(config.UserSyncChooserStrategyWeighted)},
+			givenLiveWeights:  liveWeights,
+			expected:          liveWeights,
+		},
+		{
+			description:       "Account Overrides Host Strategy - Random",
+			givenHostStrategy: config.UserSyncChooserStrategyWeighted,
+			givenCookieSync:   config.CookieSync{Strategy: ptrutil.ToPtr(config.UserSyncChooserStrategyRandom), BidderWeights: map[string]float64{"account": 2}},
+			givenLiveWeights:  liveWeights,
+			expected:          nil,
+		},
+	}
+
+	for _, test := range testCases {
+		endpoint := cookieSyncEndpoint{
+			config:            &config.Configuration{UserSync: config.UserSync{Chooser: config.UserSyncChooser{Strategy: test.givenHostStrategy}}},
+			liveBidderWeights: test.givenLiveWeights,
+		}
+		assert.Equal(t, test.expected, endpoint.bidderWeights(test.givenCookieSync), test.description)
+	}
+}
+
 func TestMapBidderStatusToAnalytics(t *testing.T) {
 	testCases := []struct {
 		description string
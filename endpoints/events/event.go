@@ -15,6 +15,7 @@ import (
 	"github.com/julienschmidt/httprouter"
 	accountService "github.com/prebid/prebid-server/v3/account"
 	"github.com/prebid/prebid-server/v3/analytics"
+	"github.com/prebid/prebid-server/v3/bidnotifications"
 	"github.com/prebid/prebid-server/v3/config"
 	"github.com/prebid/prebid-server/v3/errortypes"
 	"github.com/prebid/prebid-server/v3/metrics"
@@ -47,15 +48,17 @@ type eventEndpoint struct {
 	Cfg           *config.Configuration
 	TrackingPixel *httputil.Pixel
 	MetricsEngine metrics.MetricsEngine
+	BidNotifier   bidnotifications.Notifier
 }
 
-func NewEventEndpoint(cfg *config.Configuration, accounts stored_requests.AccountFetcher, analytics analytics.Runner, me metrics.MetricsEngine) httprouter.Handle {
+func NewEventEndpoint(cfg *config.Configuration, accounts stored_requests.AccountFetcher, analytics analytics.Runner, me metrics.MetricsEngine, bidNotifier bidnotifications.Notifier) httprouter.Handle {
 	ee := &eventEndpoint{
 		Accounts:      accounts,
 		Analytics:     analytics,
 		Cfg:           cfg,
 		TrackingPixel: &httputil.Pixel1x1PNG,
 		MetricsEngine: me,
+		BidNotifier:   bidNotifier,
 	}
 
 	return ee.Handle
@@ -86,6 +89,11 @@ func (e *eventEndpoint) Handle(w http.ResponseWriter, r *http.Request, _ httprou
 	}
 	eventRequest.AccountID = accountId
 
+	// fire the win or billing notification of the bid, whether or not the event is logged
+	if e.BidNotifier != nil {
+		e.BidNotifier.NotifyEvent(eventRequest)
+	}
+
 	if eventRequest.Analytics != analytics.Enabled {
 		w.WriteHeader(http.StatusNoContent)
 		return
@@ -13,9 +13,11 @@ import (
 	"time"
 
 	"github.com/prebid/prebid-server/v3/analytics"
+	"github.com/prebid/prebid-server/v3/bidnotifications"
 	"github.com/prebid/prebid-server/v3/config"
 	"github.com/prebid/prebid-server/v3/errortypes"
 	"github.com/prebid/prebid-server/v3/metrics"
+	"github.com/prebid/prebid-server/v3/openrtb_ext"
 	"github.com/prebid/prebid-server/v3/privacy"
 	"github.com/prebid/prebid-server/v3/stored_requests"
 	"github.com/stretchr/testify/assert"
@@ -123,7 +125,7 @@ func TestShouldReturnBadRequestWhenTypeIsMissing(t *testing.T) {
 	req := httptest.NewRequest("GET", "/event?b=test", strings.NewReader(reqData))
 	recorder := httptest.NewRecorder()
 
-	e := NewEventEndpoint(cfg, mockAccountsFetcher, mockAnalyticsModule, &metrics.MetricsEngineMock{})
+	e := NewEventEndpoint(cfg, mockAccountsFetcher, mockAnalyticsModule, &metrics.MetricsEngineMock{}, nil)
 
 	// execute
 	e(recorder, req, nil)
@@ -159,7 +161,7 @@ func TestShouldReturnBadRequestWhenTypeIsInvalid(t *testing.T) {
 	req := httptest.NewRequest("GET", "/event?t=test&b=t", strings.NewReader(reqData))
 	recorder := httptest.NewRecorder()
 
-	e := NewEventEndpoint(cfg, mockAccounts, mockAnalyticsModule, &metrics.MetricsEngineMock{})
+	e := NewEventEndpoint(cfg, mockAccounts, mockAnalyticsModule, &metrics.MetricsEngineMock{}, nil)
 
 	// execute
 	e(recorder, req, nil)
@@ -195,7 +197,7 @@ func TestShouldReturnBadRequestWhenBidIdIsMissing(t *testing.T) {
 	req := httptest.NewRequest("GET", "/event?t=win", strings.NewReader(reqData))
 	recorder := httptest.NewRecorder()
 
-	e := NewEventEndpoint(cfg, mockAccountsFetcher, mockAnalyticsModule, &metrics.MetricsEngineMock{})
+	e := NewEventEndpoint(cfg, mockAccountsFetcher, mockAnalyticsModule, &metrics.MetricsEngineMock{}, nil)
 
 	// execute
 	e(recorder, req, nil)
@@ -231,7 +233,7 @@ func TestShouldReturnBadRequestWhenTimestampIsInvalid(t *testing.T) {
 	req := httptest.NewRequest("GET", "/event?t=win&b=test&ts=q", strings.NewReader(reqData))
 	recorder := httptest.NewRecorder()
 
-	e := NewEventEndpoint(cfg, mockAccountsFetcher, mockAnalyticsModule, &metrics.MetricsEngineMock{})
+	e := NewEventEndpoint(cfg, mockAccountsFetcher, mockAnalyticsModule, &metrics.MetricsEngineMock{}, nil)
 
 	// execute
 	e(recorder, req, nil)
@@ -267,7 +269,7 @@ func TestShouldReturnUnauthorizedWhenAccountIsMissing(t *testing.T) {
 	req := httptest.NewRequest("GET", "/event?t=win&b=test&ts=1234", strings.NewReader(reqData))
 	recorder := httptest.NewRecorder()
 
-	e := NewEventEndpoint(cfg, mockAccountsFetcher, mockAnalyticsModule, &metrics.MetricsEngineMock{})
+	e := NewEventEndpoint(cfg, mockAccountsFetcher, mockAnalyticsModule, &metrics.MetricsEngineMock{}, nil)
 
 	// execute
 	e(recorder, req, nil)
@@ -303,7 +305,7 @@ func TestShouldReturnBadRequestWhenFormatValueIsInvalid(t *testing.T) {
 	req := httptest.NewRequest("GET", "/event?t=win&b=test&ts=1234&f=q", strings.NewReader(reqData))
 	recorder := httptest.NewRecorder()
 
-	e := NewEventEndpoint(cfg, mockAccountsFetcher, mockAnalyticsModule, &metrics.MetricsEngineMock{})
+	e := NewEventEndpoint(cfg, mockAccountsFetcher, mockAnalyticsModule, &metrics.MetricsEngineMock{}, nil)
 
 	// execute
 	e(recorder, req, nil)
@@ -339,7 +341,7 @@ func TestShouldReturnBadRequestWhenAnalyticsValueIsInvalid(t *testing.T) {
 	req := httptest.NewRequest("GET", "/event?t=win&b=test&ts=1234&f=b&x=4", strings.NewReader(reqData))
 	recorder := httptest.NewRecorder()
 
-	e := NewEventEndpoint(cfg, mockAccountsFetcher, mockAnalyticsModule, &metrics.MetricsEngineMock{})
+	e := NewEventEndpoint(cfg, mockAccountsFetcher, mockAnalyticsModule, &metrics.MetricsEngineMock{}, nil)
 
 	// execute
 	e(recorder, req, nil)
@@ -378,7 +380,7 @@ func TestShouldNotPassEventToAnalyticsReporterWhenAccountNotFoundAndDefaultIsFal
 	req := httptest.NewRequest("GET", "/event?t=win&b=test&ts=1234&f=b&x=1&a=testacc", strings.NewReader(reqData))
 	recorder := httptest.NewRecorder()
 
-	e := NewEventEndpoint(cfg, mockAccountsFetcher, mockAnalyticsModule, &metrics.MetricsEngineMock{})
+	e := NewEventEndpoint(cfg, mockAccountsFetcher, mockAnalyticsModule, &metrics.MetricsEngineMock{}, nil)
 
 	// execute
 	e(recorder, req, nil)
@@ -412,7 +414,7 @@ func TestShouldReturnBadRequestWhenIntegrationValueIsInvalid(t *testing.T) {
 	req := httptest.NewRequest("GET", "/event?t=win&b=bidId&f=b&ts=1000&x=1&a=accountId&bidder=bidder&int=Te$tIntegrationType", strings.NewReader(reqData))
 	recorder := httptest.NewRecorder()
 
-	e := NewEventEndpoint(cfg, mockAccountsFetcher, mockAnalyticsModule, &metrics.MetricsEngineMock{})
+	e := NewEventEndpoint(cfg, mockAccountsFetcher, mockAnalyticsModule, &metrics.MetricsEngineMock{}, nil)
 
 	// execute
 	e(recorder, req, nil)
@@ -451,7 +453,7 @@ func TestShouldNotPassEventToAnalyticsReporterWhenAccountEventNotEnabled(t *test
 	req := httptest.NewRequest("GET", "/event?t=win&b=test&ts=1234&f=b&x=1&a=events_disabled", strings.NewReader(reqData))
 	recorder := httptest.NewRecorder()
 
-	e := NewEventEndpoint(cfg, mockAccountsFetcher, mockAnalyticsModule, &metrics.MetricsEngineMock{})
+	e := NewEventEndpoint(cfg, mockAccountsFetcher, mockAnalyticsModule, &metrics.MetricsEngineMock{}, nil)
 
 	// execute
 	e(recorder, req, nil)
@@ -489,7 +491,7 @@ func TestShouldPassEventToAnalyticsReporterWhenAccountEventEnabled(t *testing.T)
 	req := httptest.NewRequest("GET", "/event?t=win&b=test&ts=1234&f=b&x=1&a=events_enabled", strings.NewReader(reqData))
 	recorder := httptest.NewRecorder()
 
-	e := NewEventEndpoint(cfg, mockAccountsFetcher, mockAnalyticsModule, &metrics.MetricsEngineMock{})
+	e := NewEventEndpoint(cfg, mockAccountsFetcher, mockAnalyticsModule, &metrics.MetricsEngineMock{}, nil)
 
 	// execute
 	e(recorder, req, nil)
@@ -523,7 +525,7 @@ func TestShouldNotPassEventToAnalyticsReporterWhenAnalyticsValueIsZero(t *testin
 	req := httptest.NewRequest("GET", "/event?t=win&b=test&ts=1234&f=b&x=0&a=events_enabled", strings.NewReader(reqData))
 	recorder := httptest.NewRecorder()
 
-	e := NewEventEndpoint(cfg, mockAccountsFetcher, mockAnalyticsModule, &metrics.MetricsEngineMock{})
+	e := NewEventEndpoint(cfg, mockAccountsFetcher, mockAnalyticsModule, &metrics.MetricsEngineMock{}, nil)
 
 	// execute
 	e(recorder, req, nil)
@@ -557,7 +559,7 @@ func TestShouldRespondWithPixelAndContentTypeWhenRequestFormatIsImage(t *testing
 	req := httptest.NewRequest("GET", "/event?t=win&b=test&ts=1234&f=i&x=1&a=events_enabled", strings.NewReader(reqData))
 	recorder := httptest.NewRecorder()
 
-	e := NewEventEndpoint(cfg, mockAccountsFetcher, mockAnalyticsModule, &metrics.MetricsEngineMock{})
+	e := NewEventEndpoint(cfg, mockAccountsFetcher, mockAnalyticsModule, &metrics.MetricsEngineMock{}, nil)
 
 	// execute
 	e(recorder, req, nil)
@@ -598,7 +600,7 @@ func TestShouldRespondWithNoContentWhenRequestFormatIsNotDefined(t *testing.T) {
 	req := httptest.NewRequest("GET", "/event?t=imp&b=test&ts=1234&x=1&a=events_enabled", strings.NewReader(reqData))
 	recorder := httptest.NewRecorder()
 
-	e := NewEventEndpoint(cfg, mockAccountsFetcher, mockAnalyticsModule, &metrics.MetricsEngineMock{})
+	e := NewEventEndpoint(cfg, mockAccountsFetcher, mockAnalyticsModule, &metrics.MetricsEngineMock{}, nil)
 
 	// execute
 	e(recorder, req, nil)
@@ -615,6 +617,49 @@ func TestShouldRespondWithNoContentWhenRequestFormatIsNotDefined(t *testing.T) {
 	assert.Equal(t, 0, len(d))
 }
 
+type mockBidNotifier struct {
+	events []*analytics.EventRequest
+}
+
+func (n *mockBidNotifier) Enabled(account *config.Account) bool { return true }
+
+func (n *mockBidNotifier) BidderEnabled(account *config.Account, bidder openrtb_ext.BidderName) bool {
+	return true
+}
+
+func (n *mockBidNotifier) NotifyAuction(auction bidnotifications.Auction) {}
+
+func (n *mockBidNotifier) NotifyEvent(event *analytics.EventRequest) {
+	n.events = append(n.events, event)
+}
+
+func (n *mockBidNotifier) Shutdown() {}
+
+func TestShouldNotifyBidsWhenAnalyticsIsDisabled(t *testing.T) {
+	mockAnalyticsModule := &eventsMockAnalyticsModule{}
+	bidNotifier := &mockBidNotifier{}
+
+	cfg := &config.Configuration{
+		AccountDefaults: config.Account{},
+	}
+
+	req := httptest.NewRequest("GET", "/event?t=win&b=bid1&bidder=appnexus&x=0&a=events_enabled", strings.NewReader(""))
+	recorder := httptest.NewRecorder()
+
+	e := NewEventEndpoint(cfg, &mockAccountsFetcher{}, mockAnalyticsModule, &metrics.MetricsEngineMock{}, bidNotifier)
+	e(recorder, req, nil)
+
+	assert.Equal(t, 204, recorder.Result().StatusCode)
+	assert.False(t, mockAnalyticsModule.Invoked)
+	assert.Equal(t, []*analytics.EventRequest{{
+		Type:      analytics.Win,
+		BidID:     "bid1",
+		AccountID: "events_enabled",
+		Bidder:    "appnexus",
+		Analytics: analytics.Disabled,
+	}}, bidNotifier.events)
+}
+
 func TestShouldParseEventCorrectly(t *testing.T) {
 
 	tests := map[string]struct {
@@ -815,7 +860,7 @@ func TestShouldReturnBadRequestWhenVTypeIsInvalid(t *testing.T) {
 
 		recorder := httptest.NewRecorder()
 
-		e := NewEventEndpoint(cfg, mockAccountsFetcher, mockAnalyticsModule, &metrics.MetricsEngineMock{})
+		e := NewEventEndpoint(cfg, mockAccountsFetcher, mockAnalyticsModule, &metrics.MetricsEngineMock{}, nil)
 		e(recorder, test.req, nil)
 
 		d, err := io.ReadAll(recorder.Result().Body)
@@ -0,0 +1,74 @@
+package endpoints
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+
+	"github.com/golang/glog"
+	"github.com/prebid/prebid-server/v3/util/jsonutil"
+)
+
+type vendorLists interface {
+	Source() string
+	Pinned() map[uint16]uint16
+	LoadedVersions() map[uint16][]uint16
+	Preload(ctx context.Context)
+}
+
+// vendorListsInfo holds the global vendor lists loaded for GDPR enforcement, keyed by spec version.
+type vendorListsInfo struct {
+	Source   string              `json:"source"`
+	Pinned   map[string]uint16   `json:"pinned,omitempty"`
+	Versions map[string][]uint16 `json:"versions"`
+}
+
+// NewVendorListsEndpoint returns the loaded global vendor list versions. A POST request preloads
+// the vendor lists from the configured source before responding.
+func NewVendorListsEndpoint(lists vendorLists) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if lists == nil {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+
+		switch r.Method {
+		case http.MethodGet:
+		case http.MethodPost:
+			lists.Preload(r.Context())
+		default:
+			w.Header().Set("Allow", "GET, POST")
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+
+		jsonOutput, err := jsonutil.Marshal(newVendorListsInfo(lists))
+		if err != nil {
+			glog.Errorf("/gdpr/vendorlists Critical error when trying to marshal vendorListsInfo: %v", err)
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(jsonOutput)
+	}
+}
+
+func newVendorListsInfo(lists vendorLists) vendorListsInfo {
+	info := vendorListsInfo{
+		Source:   lists.Source(),
+		Versions: make(map[string][]uint16),
+	}
+
+	for specVersion, listVersions := range lists.LoadedVersions() {
+		info.Versions[strconv.Itoa(int(specVersion))] = listVersions
+	}
+
+	if pinned := lists.Pinned(); len(pinned) > 0 {
+		info.Pinned = make(map[string]uint16, len(pinned))
+		for specVersion, listVersion := range pinned {
+			info.Pinned[strconv.Itoa(int(specVersion))] = listVersion
+		}
+	}
+	return info
+}
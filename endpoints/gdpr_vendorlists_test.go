@@ -0,0 +1,98 @@
+package endpoints
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type mockVendorLists struct {
+	pinned    map[uint16]uint16
+	versions  map[uint16][]uint16
+	preloaded bool
+}
+
+func (m *mockVendorLists) Source() string {
+	return "local,http"
+}
+
+func (m *mockVendorLists) Pinned() map[uint16]uint16 {
+	return m.pinned
+}
+
+func (m *mockVendorLists) LoadedVersions() map[uint16][]uint16 {
+	return m.versions
+}
+
+func (m *mockVendorLists) Preload(ctx context.Context) {
+	m.preloaded = true
+	m.versions[3] = append(m.versions[3], 3)
+}
+
+func TestVendorListsEndpoint(t *testing.T) {
+	testCases := []struct {
+		name              string
+		method            string
+		pinned            map[uint16]uint16
+		expectedStatus    int
+		expectedBody      string
+		expectedPreloaded bool
+	}{
+		{
+			name:           "get",
+			method:         http.MethodGet,
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"source":"local,http","versions":{"2":[2],"3":[1,2]}}`,
+		},
+		{
+			name:           "get_pinned",
+			method:         http.MethodGet,
+			pinned:         map[uint16]uint16{3: 2},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"source":"local,http","pinned":{"3":2},"versions":{"2":[2],"3":[1,2]}}`,
+		},
+		{
+			name:              "post_preloads",
+			method:            http.MethodPost,
+			expectedStatus:    http.StatusOK,
+			expectedBody:      `{"source":"local,http","versions":{"2":[2],"3":[1,2,3]}}`,
+			expectedPreloaded: true,
+		},
+		{
+			name:           "method_not_allowed",
+			method:         http.MethodDelete,
+			expectedStatus: http.StatusMethodNotAllowed,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.name, func(t *testing.T) {
+			lists := &mockVendorLists{
+				pinned:   test.pinned,
+				versions: map[uint16][]uint16{2: {2}, 3: {1, 2}},
+			}
+			handler := NewVendorListsEndpoint(lists)
+
+			w := httptest.NewRecorder()
+			handler(w, httptest.NewRequest(test.method, "/gdpr/vendorlists", nil))
+
+			assert.Equal(t, test.expectedStatus, w.Code)
+			if test.expectedBody != "" {
+				assert.JSONEq(t, test.expectedBody, w.Body.String())
+			}
+			assert.Equal(t, test.expectedPreloaded, lists.preloaded)
+		})
+	}
+}
+
+func TestVendorListsEndpointNotConfigured(t *testing.T) {
+	handler := NewVendorListsEndpoint(nil)
+
+	w := httptest.NewRecorder()
+	handler(w, httptest.NewRequest(http.MethodGet, "/gdpr/vendorlists", nil))
+
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+}
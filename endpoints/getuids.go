@@ -16,9 +16,9 @@ type userSyncs struct {
 
 // NewGetUIDsEndpoint implements the /getuid endpoint which
 // returns all the existing syncs for the user
-func NewGetUIDsEndpoint(cfg config.HostCookie) httprouter.Handle {
+func NewGetUIDsEndpoint(cfg config.HostCookie, cookieDecoder usersync.Decoder) httprouter.Handle {
 	return httprouter.Handle(func(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
-		cookie := usersync.ReadCookie(r, usersync.Base64Decoder{}, &cfg)
+		cookie := usersync.ReadCookie(r, cookieDecoder, &cfg)
 		usersync.SyncHostCookie(r, cookie, &cfg)
 
 		userSyncs := new(userSyncs)
@@ -4,14 +4,16 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/prebid/prebid-server/v3/config"
+	"github.com/prebid/prebid-server/v3/usersync"
 	"github.com/stretchr/testify/assert"
 )
 
 func TestGetUIDs(t *testing.T) {
 	req := makeRequest("/getuids", map[string]string{"adnxs": "123", "audienceNetwork": "456"})
-	endpoint := NewGetUIDsEndpoint(config.HostCookie{})
+	endpoint := NewGetUIDsEndpoint(config.HostCookie{}, usersync.Base64Decoder{})
 	res := httptest.NewRecorder()
 	endpoint(res, req, nil)
 
@@ -22,7 +24,7 @@ func TestGetUIDs(t *testing.T) {
 
 func TestGetUIDsWithNoSyncs(t *testing.T) {
 	req := makeRequest("/getuids", map[string]string{})
-	endpoint := NewGetUIDsEndpoint(config.HostCookie{})
+	endpoint := NewGetUIDsEndpoint(config.HostCookie{}, usersync.Base64Decoder{})
 	res := httptest.NewRecorder()
 	endpoint(res, req, nil)
 
@@ -32,10 +34,27 @@ func TestGetUIDsWithNoSyncs(t *testing.T) {
 
 func TestGetUIDWIthNoCookie(t *testing.T) {
 	req := httptest.NewRequest("GET", "/getuids", nil)
-	endpoint := NewGetUIDsEndpoint(config.HostCookie{})
+	endpoint := NewGetUIDsEndpoint(config.HostCookie{}, usersync.Base64Decoder{})
 	res := httptest.NewRecorder()
 	endpoint(res, req, nil)
 
 	assert.Equal(t, http.StatusOK, res.Code)
 	assert.JSONEq(t, `{}`, res.Body.String(), "GetUIDs endpoint shouldn't return anything if there doesn't exist a PBS cookie")
 }
+
+func TestGetUIDsFromStore(t *testing.T) {
+	codec := usersync.NewStoreCodec(usersync.NewMemoryUIDStore(1024*1024, time.Hour), "key", time.Second, usersync.Base64Codec{})
+	cookie := usersync.NewCookie()
+	cookie.Sync("adnxs", "123")
+	encodedCookie, err := codec.Encode(cookie)
+	assert.NoError(t, err)
+
+	req := httptest.NewRequest("GET", "/getuids", nil)
+	req.AddCookie(&http.Cookie{Name: "uids", Value: encodedCookie})
+	endpoint := NewGetUIDsEndpoint(config.HostCookie{}, codec)
+	res := httptest.NewRecorder()
+	endpoint(res, req, nil)
+
+	assert.Equal(t, http.StatusOK, res.Code)
+	assert.JSONEq(t, `{"buyeruids": {"adnxs": "123"}}`, res.Body.String(), "GetUIDs endpoint should return the user IDs held by the store")
+}
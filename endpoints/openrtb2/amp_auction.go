@@ -34,7 +34,9 @@ import (
 	"github.com/prebid/prebid-server/v3/privacy"
 	"github.com/prebid/prebid-server/v3/stored_requests"
 	"github.com/prebid/prebid-server/v3/stored_requests/backends/empty_fetcher"
+	"github.com/prebid/prebid-server/v3/stored_requests/templates"
 	"github.com/prebid/prebid-server/v3/stored_responses"
+	"github.com/prebid/prebid-server/v3/tracing"
 	"github.com/prebid/prebid-server/v3/usersync"
 	"github.com/prebid/prebid-server/v3/util/iputil"
 	"github.com/prebid/prebid-server/v3/util/jsonutil"
@@ -71,6 +73,8 @@ func NewAmpEndpoint(
 	storedRespFetcher stored_requests.Fetcher,
 	hookExecutionPlanBuilder hooks.ExecutionPlanBuilder,
 	tmaxAdjustments *exchange.TmaxAdjustmentsPreprocessed,
+	cookieDecoder usersync.Decoder,
+	uidMappings *usersync.UIDMappings,
 ) (httprouter.Handle, error) {
 
 	if ex == nil || requestValidator == nil || requestsById == nil || accounts == nil || cfg == nil || metricsEngine == nil {
@@ -105,6 +109,8 @@ func NewAmpEndpoint(
 		hookExecutionPlanBuilder,
 		tmaxAdjustments,
 		openrtb_ext.NormalizeBidderName,
+		cookieDecoder,
+		uidMappings,
 	}).AmpAuction), nil
 
 }
@@ -119,6 +125,7 @@ func (deps *endpointDeps) AmpAuction(w http.ResponseWriter, r *http.Request, _ h
 	start := time.Now()
 
 	hookExecutor := hookexecution.NewHookExecutor(deps.hookExecutionPlanBuilder, hookexecution.EndpointAmp, deps.metricsEngine)
+	hookExecutor.SetTraceContext(tracing.Detach(r.Context()))
 
 	ao := analytics.AmpObject{
 		Status:    http.StatusOK,
@@ -140,7 +147,9 @@ func (deps *endpointDeps) AmpAuction(w http.ResponseWriter, r *http.Request, _ h
 	defer func() {
 		deps.metricsEngine.RecordRequest(labels)
 		deps.metricsEngine.RecordRequestTime(labels, time.Since(start))
+		_, span := tracing.Start(r.Context(), "analytics.log_amp_object")
 		deps.analytics.LogAmpObject(&ao, activityControl)
+		span.End()
 	}()
 
 	// Add AMP headers
@@ -160,8 +169,10 @@ func (deps *endpointDeps) AmpAuction(w http.ResponseWriter, r *http.Request, _ h
 
 	// There is no body for AMP requests, so we pass a nil body and ignore the return value.
 	_, rejectErr := hookExecutor.ExecuteEntrypointStage(r, nilBody)
+	r, versionSelection := withStoredVersionSelection(r)
 	reqWrapper, storedAuctionResponses, storedBidResponses, bidderImpReplaceImp, errL := deps.parseAmpRequest(r)
 	ao.Errors = append(ao.Errors, errL...)
+	ao.StoredVersions = versionSelection.Resolved()
 	// Process reject after parsing amp request, so we can use reqWrapper.
 	// There is no body for AMP requests, so we pass a nil body and ignore the return value.
 	if rejectErr != nil {
@@ -180,7 +191,7 @@ func (deps *endpointDeps) AmpAuction(w http.ResponseWriter, r *http.Request, _ h
 
 	ao.RequestWrapper = reqWrapper
 
-	ctx := context.Background()
+	ctx := tracing.Detach(r.Context())
 	var cancel context.CancelFunc
 	if reqWrapper.TMax > 0 {
 		ctx, cancel = context.WithDeadline(ctx, start.Add(time.Duration(reqWrapper.TMax)*time.Millisecond))
@@ -190,7 +201,7 @@ func (deps *endpointDeps) AmpAuction(w http.ResponseWriter, r *http.Request, _ h
 	defer cancel()
 
 	// Read UserSyncs/Cookie from Request
-	usersyncs := usersync.ReadCookie(r, usersync.Base64Decoder{}, &deps.cfg.HostCookie)
+	usersyncs := usersync.ReadCookie(r, deps.cookieDecoder, &deps.cfg.HostCookie)
 	usersync.SyncHostCookie(r, usersyncs, &deps.cfg.HostCookie)
 	if usersyncs.HasAnyLiveSyncs() {
 		labels.CookieFlag = metrics.CookieFlagYes
@@ -231,6 +242,22 @@ func (deps *endpointDeps) AmpAuction(w http.ResponseWriter, r *http.Request, _ h
 		return
 	}
 
+	// Load the stored request again if the account overrides the rollout of the version which was resolved
+	if versionSelection.UseAccountRollouts(account.StoredRequestVersions) {
+		reqWrapper, storedAuctionResponses, storedBidResponses, bidderImpReplaceImp, errL = deps.parseAmpRequest(r)
+		ao.StoredVersions = versionSelection.Resolved()
+		if errortypes.ContainsFatalError(errL) {
+			ao.Errors = append(ao.Errors, errL...)
+			w.WriteHeader(http.StatusBadRequest)
+			for _, err := range errortypes.FatalOnly(errL) {
+				fmt.Fprintf(w, "Invalid request: %s\n", err.Error())
+			}
+			labels.RequestStatus = metrics.RequestStatusBadInput
+			return
+		}
+		ao.RequestWrapper = reqWrapper
+	}
+
 	// Populate any "missing" OpenRTB fields with info from other sources, (e.g. HTTP request headers).
 	if errs := deps.setFieldsImplicitly(r, reqWrapper, account); len(errs) > 0 {
 		errL = append(errL, errs...)
@@ -261,7 +288,7 @@ func (deps *endpointDeps) AmpAuction(w http.ResponseWriter, r *http.Request, _ h
 	auctionRequest := &exchange.AuctionRequest{
 		BidRequestWrapper:          reqWrapper,
 		Account:                    *account,
-		UserSyncs:                  usersyncs,
+		UserSyncs:                  deps.uidMappings.IDFetcher(ctx, usersyncs, reqWrapper.User),
 		RequestType:                labels.RType,
 		StartTime:                  start,
 		LegacyLabels:               labels,
@@ -275,6 +302,7 @@ func (deps *endpointDeps) AmpAuction(w http.ResponseWriter, r *http.Request, _ h
 		TCF2Config:                 tcf2Config,
 		Activities:                 activityControl,
 		TmaxAdjustments:            deps.tmaxAdjustments,
+		StoredVersions:             ao.StoredVersions,
 	}
 
 	auctionResponse, err := deps.ex.HoldAuction(ctx, auctionRequest, nil)
@@ -484,6 +512,13 @@ func getExtBidResponse(
 			extBidResponse.Prebid = &openrtb_ext.ExtResponsePrebid{Modules: modules}
 		}
 
+		if extBidResponse.Debug != nil && extResponse.Prebid != nil && extResponse.Prebid.StoredVersions != nil {
+			if extBidResponse.Prebid == nil {
+				extBidResponse.Prebid = &openrtb_ext.ExtResponsePrebid{}
+			}
+			extBidResponse.Prebid.StoredVersions = extResponse.Prebid.StoredVersions
+		}
+
 		if len(warns) > 0 {
 			ao.Errors = append(ao.Errors, warns...)
 		}
@@ -542,7 +577,8 @@ func (deps *endpointDeps) loadRequestJSONForAmp(httpRequest *http.Request) (req
 		return nil, nil, nil, nil, []error{err}
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(deps.cfg.StoredRequestsTimeout)*time.Millisecond)
+	versionSelection := stored_requests.VersionSelectionFromContext(httpRequest.Context())
+	ctx, cancel := context.WithTimeout(stored_requests.WithVersionSelection(tracing.Detach(httpRequest.Context()), versionSelection), time.Duration(deps.cfg.StoredRequestsTimeout)*time.Millisecond)
 	defer cancel()
 
 	storedRequests, _, errs := deps.storedReqFetcher.FetchRequests(ctx, []string{ampParams.StoredRequestID}, nil)
@@ -554,8 +590,13 @@ func (deps *endpointDeps) loadRequestJSONForAmp(httpRequest *http.Request) (req
 		return
 	}
 
-	// The fetched config becomes the entire OpenRTB request
-	requestJSON := storedRequests[ampParams.StoredRequestID]
+	// The fetched config becomes the entire OpenRTB request. There's no incoming request to resolve the
+	// placeholders of a parameterized config against, so only account.id and the defaults are resolved.
+	requestJSON, err := storedTemplates.Render(storedRequests[ampParams.StoredRequestID], templates.Params{AccountID: ampParams.Account})
+	if err != nil {
+		errs = []error{fmt.Errorf("tag_id %s: %v", ampParams.StoredRequestID, err)}
+		return
+	}
 	if err := jsonutil.UnmarshalValid(requestJSON, req); err != nil {
 		errs = []error{err}
 		return
@@ -35,6 +35,7 @@ import (
 	"github.com/prebid/prebid-server/v3/ortb"
 	"github.com/prebid/prebid-server/v3/privacy"
 	"github.com/prebid/prebid-server/v3/stored_requests/backends/empty_fetcher"
+	"github.com/prebid/prebid-server/v3/usersync"
 	"github.com/prebid/prebid-server/v3/util/jsonutil"
 )
 
@@ -221,13 +222,15 @@ func TestAMPPageInfo(t *testing.T) {
 		empty_fetcher.EmptyFetcher{},
 		&config.Configuration{MaxRequestSize: maxSize},
 		&metricsConfig.NilMetricsEngine{},
-		analyticsBuild.New(&config.Analytics{}),
+		analyticsBuild.New(&config.Analytics{}, &metricsConfig.NilMetricsEngine{}),
 		map[string]string{},
 		[]byte{},
 		openrtb_ext.BuildBidderMap(),
 		empty_fetcher.EmptyFetcher{},
 		hooks.EmptyPlanBuilder{},
 		nil,
+		usersync.Base64Decoder{},
+		nil,
 	)
 	request := httptest.NewRequest("GET", fmt.Sprintf("/openrtb2/auction/amp?tag_id=1&curl=%s", url.QueryEscape(page)), nil)
 	recorder := httptest.NewRecorder()
@@ -318,13 +321,15 @@ func TestGDPRConsent(t *testing.T) {
 				GDPR:           config.GDPR{Enabled: true},
 			},
 			&metricsConfig.NilMetricsEngine{},
-			analyticsBuild.New(&config.Analytics{}),
+			analyticsBuild.New(&config.Analytics{}, &metricsConfig.NilMetricsEngine{}),
 			map[string]string{},
 			[]byte{},
 			openrtb_ext.BuildBidderMap(),
 			empty_fetcher.EmptyFetcher{},
 			hooks.EmptyPlanBuilder{},
 			nil,
+			usersync.Base64Decoder{},
+			nil,
 		)
 
 		// Invoke Endpoint
@@ -706,13 +711,15 @@ func TestCCPAConsent(t *testing.T) {
 			empty_fetcher.EmptyFetcher{},
 			&config.Configuration{MaxRequestSize: maxSize},
 			&metricsConfig.NilMetricsEngine{},
-			analyticsBuild.New(&config.Analytics{}),
+			analyticsBuild.New(&config.Analytics{}, &metricsConfig.NilMetricsEngine{}),
 			map[string]string{},
 			[]byte{},
 			openrtb_ext.BuildBidderMap(),
 			empty_fetcher.EmptyFetcher{},
 			hooks.EmptyPlanBuilder{},
 			nil,
+			usersync.Base64Decoder{},
+			nil,
 		)
 
 		// Invoke Endpoint
@@ -813,13 +820,15 @@ func TestConsentWarnings(t *testing.T) {
 			empty_fetcher.EmptyFetcher{},
 			&config.Configuration{MaxRequestSize: maxSize},
 			&metricsConfig.NilMetricsEngine{},
-			analyticsBuild.New(&config.Analytics{}),
+			analyticsBuild.New(&config.Analytics{}, &metricsConfig.NilMetricsEngine{}),
 			map[string]string{},
 			[]byte{},
 			openrtb_ext.BuildBidderMap(),
 			empty_fetcher.EmptyFetcher{},
 			hooks.EmptyPlanBuilder{},
 			nil,
+			usersync.Base64Decoder{},
+			nil,
 		)
 
 		// Invoke Endpoint
@@ -914,13 +923,15 @@ func TestNewAndLegacyConsentBothProvided(t *testing.T) {
 				GDPR:           config.GDPR{Enabled: true},
 			},
 			&metricsConfig.NilMetricsEngine{},
-			analyticsBuild.New(&config.Analytics{}),
+			analyticsBuild.New(&config.Analytics{}, &metricsConfig.NilMetricsEngine{}),
 			map[string]string{},
 			[]byte{},
 			openrtb_ext.BuildBidderMap(),
 			empty_fetcher.EmptyFetcher{},
 			hooks.EmptyPlanBuilder{},
 			nil,
+			usersync.Base64Decoder{},
+			nil,
 		)
 
 		// Invoke Endpoint
@@ -962,13 +973,15 @@ func TestAMPSiteExt(t *testing.T) {
 		empty_fetcher.EmptyFetcher{},
 		&config.Configuration{MaxRequestSize: maxSize},
 		&metricsConfig.NilMetricsEngine{},
-		analyticsBuild.New(&config.Analytics{}),
+		analyticsBuild.New(&config.Analytics{}, &metricsConfig.NilMetricsEngine{}),
 		nil,
 		nil,
 		openrtb_ext.BuildBidderMap(),
 		empty_fetcher.EmptyFetcher{},
 		hooks.EmptyPlanBuilder{},
 		nil,
+		usersync.Base64Decoder{},
+		nil,
 	)
 	request, err := http.NewRequest("GET", "/openrtb2/auction/amp?tag_id=1", nil)
 	if !assert.NoError(t, err) {
@@ -1027,13 +1040,15 @@ func TestAmpBadRequests(t *testing.T) {
 		empty_fetcher.EmptyFetcher{},
 		&config.Configuration{MaxRequestSize: maxSize},
 		&metricsConfig.NilMetricsEngine{},
-		analyticsBuild.New(&config.Analytics{}),
+		analyticsBuild.New(&config.Analytics{}, &metricsConfig.NilMetricsEngine{}),
 		map[string]string{},
 		[]byte{},
 		openrtb_ext.BuildBidderMap(),
 		empty_fetcher.EmptyFetcher{},
 		hooks.EmptyPlanBuilder{},
 		nil,
+		usersync.Base64Decoder{},
+		nil,
 	)
 
 	for id, test := range badRequests {
@@ -1118,13 +1133,15 @@ func TestAmpDebug(t *testing.T) {
 		empty_fetcher.EmptyFetcher{},
 		&config.Configuration{MaxRequestSize: maxSize},
 		&metricsConfig.NilMetricsEngine{},
-		analyticsBuild.New(&config.Analytics{}),
+		analyticsBuild.New(&config.Analytics{}, &metricsConfig.NilMetricsEngine{}),
 		map[string]string{},
 		[]byte{},
 		openrtb_ext.BuildBidderMap(),
 		empty_fetcher.EmptyFetcher{},
 		hooks.EmptyPlanBuilder{},
 		nil,
+		usersync.Base64Decoder{},
+		nil,
 	)
 
 	for requestID := range requests {
@@ -1254,13 +1271,15 @@ func TestQueryParamOverrides(t *testing.T) {
 		empty_fetcher.EmptyFetcher{},
 		&config.Configuration{MaxRequestSize: maxSize},
 		&metricsConfig.NilMetricsEngine{},
-		analyticsBuild.New(&config.Analytics{}),
+		analyticsBuild.New(&config.Analytics{}, &metricsConfig.NilMetricsEngine{}),
 		map[string]string{},
 		[]byte{},
 		openrtb_ext.BuildBidderMap(),
 		empty_fetcher.EmptyFetcher{},
 		hooks.EmptyPlanBuilder{},
 		nil,
+		usersync.Base64Decoder{},
+		nil,
 	)
 
 	requestID := "1"
@@ -1412,13 +1431,15 @@ func (s formatOverrideSpec) execute(t *testing.T) {
 		empty_fetcher.EmptyFetcher{},
 		&config.Configuration{MaxRequestSize: maxSize},
 		&metricsConfig.NilMetricsEngine{},
-		analyticsBuild.New(&config.Analytics{}),
+		analyticsBuild.New(&config.Analytics{}, &metricsConfig.NilMetricsEngine{}),
 		map[string]string{},
 		[]byte{},
 		openrtb_ext.BuildBidderMap(),
 		empty_fetcher.EmptyFetcher{},
 		hooks.EmptyPlanBuilder{},
 		nil,
+		usersync.Base64Decoder{},
+		nil,
 	)
 
 	url := fmt.Sprintf("/openrtb2/auction/amp?tag_id=1&debug=1&w=%d&h=%d&ow=%d&oh=%d&ms=%s&account=%s", s.width, s.height, s.overrideWidth, s.overrideHeight, s.multisize, s.account)
@@ -1911,6 +1932,43 @@ func TestIdGeneration(t *testing.T) {
 	}
 }
 
+func TestAmpStoredRequestTemplates(t *testing.T) {
+	testCases := []struct {
+		description          string
+		givenInStoredRequest json.RawMessage
+		expectedTagID        string
+		expectedError        string
+	}{
+		{
+			description:          "Account ID and defaults are resolved",
+			givenInStoredRequest: json.RawMessage(`{"id":"ThisID","site":{"page":"prebid.org"},"imp":[{"id":"some-imp-id","tagid":"{{account.id}}-top","banner":{"format":[{"w":300,"h":250}]},"ext":{"appnexus":{"placementId":"{{ext.placementId|1}}"}}}],"tmax":1}`),
+			expectedTagID:        "acct-top",
+		},
+		{
+			description:          "A parameter without a default can't be resolved",
+			givenInStoredRequest: json.RawMessage(`{"id":"ThisID","site":{"page":"{{site.page}}"},"imp":[{"id":"some-imp-id","banner":{"format":[{"w":300,"h":250}]},"ext":{"appnexus":{"placementId":1}}}],"tmax":1}`),
+			expectedError:        "tag_id test: the required parameter site.page is missing",
+		},
+	}
+
+	for _, test := range testCases {
+		request := httptest.NewRequest("GET", "/openrtb2/auction/amp?tag_id=test&account=acct", nil)
+		recorder := httptest.NewRecorder()
+
+		actualAmpObject, endpoint := ampObjectTestSetup(t, "test", test.givenInStoredRequest, false, &mockAmpExchange{})
+		endpoint(recorder, request, nil)
+
+		if test.expectedError != "" {
+			assert.Equal(t, http.StatusBadRequest, recorder.Code, test.description)
+			assert.Contains(t, recorder.Body.String(), test.expectedError, test.description)
+			continue
+		}
+		if assert.Equal(t, http.StatusOK, recorder.Code, test.description) {
+			assert.Equal(t, test.expectedTagID, actualAmpObject.RequestWrapper.Imp[0].TagID, test.description)
+		}
+	}
+}
+
 func ampObjectTestSetup(t *testing.T, inTagId string, inStoredRequest json.RawMessage, generateRequestID bool, exchange *mockAmpExchange) (*analytics.AmpObject, httprouter.Handle) {
 	actualAmpObject := analytics.AmpObject{}
 	logger := newMockLogger(&actualAmpObject, nil)
@@ -1936,6 +1994,8 @@ func ampObjectTestSetup(t *testing.T, inTagId string, inStoredRequest json.RawMe
 		empty_fetcher.EmptyFetcher{},
 		hooks.EmptyPlanBuilder{},
 		nil,
+		usersync.Base64Decoder{},
+		nil,
 	)
 	return &actualAmpObject, endpoint
 }
@@ -1982,13 +2042,15 @@ func TestAmpAuctionResponseHeaders(t *testing.T) {
 		empty_fetcher.EmptyFetcher{},
 		&config.Configuration{MaxRequestSize: maxSize},
 		&metricsConfig.NilMetricsEngine{},
-		analyticsBuild.New(&config.Analytics{}),
+		analyticsBuild.New(&config.Analytics{}, &metricsConfig.NilMetricsEngine{}),
 		map[string]string{},
 		[]byte{},
 		openrtb_ext.BuildBidderMap(),
 		empty_fetcher.EmptyFetcher{},
 		hooks.EmptyPlanBuilder{},
 		nil,
+		usersync.Base64Decoder{},
+		nil,
 	)
 
 	for _, test := range testCases {
@@ -2018,13 +2080,15 @@ func TestRequestWithTargeting(t *testing.T) {
 		empty_fetcher.EmptyFetcher{},
 		&config.Configuration{MaxRequestSize: maxSize},
 		&metricsConfig.NilMetricsEngine{},
-		analyticsBuild.New(&config.Analytics{}),
+		analyticsBuild.New(&config.Analytics{}, &metricsConfig.NilMetricsEngine{}),
 		nil,
 		nil,
 		openrtb_ext.BuildBidderMap(),
 		empty_fetcher.EmptyFetcher{},
 		hooks.EmptyPlanBuilder{},
 		nil,
+		usersync.Base64Decoder{},
+		nil,
 	)
 	url, err := url.Parse("/openrtb2/auction/amp")
 	assert.NoError(t, err, "unexpected error received while parsing url")
@@ -2476,13 +2540,15 @@ func TestAmpAuctionDebugWarningsOnly(t *testing.T) {
 			},
 		},
 		&metricsConfig.NilMetricsEngine{},
-		analyticsBuild.New(&config.Analytics{}),
+		analyticsBuild.New(&config.Analytics{}, &metricsConfig.NilMetricsEngine{}),
 		map[string]string{},
 		[]byte{},
 		openrtb_ext.BuildBidderMap(),
 		empty_fetcher.EmptyFetcher{},
 		hooks.EmptyPlanBuilder{},
 		nil,
+		usersync.Base64Decoder{},
+		nil,
 	)
 
 	for _, test := range testCases {
@@ -46,7 +46,9 @@ import (
 	"github.com/prebid/prebid-server/v3/privacy/lmt"
 	"github.com/prebid/prebid-server/v3/stored_requests"
 	"github.com/prebid/prebid-server/v3/stored_requests/backends/empty_fetcher"
+	"github.com/prebid/prebid-server/v3/stored_requests/templates"
 	"github.com/prebid/prebid-server/v3/stored_responses"
+	"github.com/prebid/prebid-server/v3/tracing"
 	"github.com/prebid/prebid-server/v3/usersync"
 	"github.com/prebid/prebid-server/v3/util/httputil"
 	"github.com/prebid/prebid-server/v3/util/iputil"
@@ -61,6 +63,9 @@ const secCookieDeprecation = "Sec-Cookie-Deprecation"
 const secBrowsingTopics = "Sec-Browsing-Topics"
 const observeBrowsingTopics = "Observe-Browsing-Topics"
 const observeBrowsingTopicsValue = "?1"
+const storedVersionHeader = "X-Prebid-Stored-Version"
+const storedVersionParam = "stored_version"
+const storedTemplateCacheSize = 10000
 
 var (
 	dntKey      string = http.CanonicalHeaderKey("DNT")
@@ -70,6 +75,9 @@ var (
 	notAmp      int8   = 0
 )
 
+// storedTemplates keeps the parsed templates of the parameterized stored requests and imps
+var storedTemplates = templates.NewCache(storedTemplateCacheSize)
+
 var accountIdSearchPath = [...]struct {
 	isApp  bool
 	isDOOH bool
@@ -98,6 +106,8 @@ func NewEndpoint(
 	storedRespFetcher stored_requests.Fetcher,
 	hookExecutionPlanBuilder hooks.ExecutionPlanBuilder,
 	tmaxAdjustments *exchange.TmaxAdjustmentsPreprocessed,
+	cookieDecoder usersync.Decoder,
+	uidMappings *usersync.UIDMappings,
 ) (httprouter.Handle, error) {
 	if ex == nil || requestValidator == nil || requestsById == nil || accounts == nil || cfg == nil || metricsEngine == nil {
 		return nil, errors.New("NewEndpoint requires non-nil arguments.")
@@ -130,7 +140,9 @@ func NewEndpoint(
 		storedRespFetcher,
 		hookExecutionPlanBuilder,
 		tmaxAdjustments,
-		openrtb_ext.NormalizeBidderName}).Auction), nil
+		openrtb_ext.NormalizeBidderName,
+		cookieDecoder,
+		uidMappings}).Auction), nil
 }
 
 type endpointDeps struct {
@@ -154,6 +166,8 @@ type endpointDeps struct {
 	hookExecutionPlanBuilder  hooks.ExecutionPlanBuilder
 	tmaxAdjustments           *exchange.TmaxAdjustmentsPreprocessed
 	normalizeBidderName       openrtb_ext.BidderNameNormalizer
+	cookieDecoder             usersync.Decoder
+	uidMappings               *usersync.UIDMappings
 }
 
 func (deps *endpointDeps) Auction(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
@@ -166,6 +180,7 @@ func (deps *endpointDeps) Auction(w http.ResponseWriter, r *http.Request, _ http
 	start := time.Now()
 
 	hookExecutor := hookexecution.NewHookExecutor(deps.hookExecutionPlanBuilder, hookexecution.EndpointAuction, deps.metricsEngine)
+	hookExecutor.SetTraceContext(tracing.Detach(r.Context()))
 
 	ao := analytics.AuctionObject{
 		Status:    http.StatusOK,
@@ -185,13 +200,17 @@ func (deps *endpointDeps) Auction(w http.ResponseWriter, r *http.Request, _ http
 	defer func() {
 		deps.metricsEngine.RecordRequest(labels)
 		deps.metricsEngine.RecordRequestTime(labels, time.Since(start))
+		_, span := tracing.Start(r.Context(), "analytics.log_auction_object")
 		deps.analytics.LogAuctionObject(&ao, activityControl)
+		span.End()
 	}()
 
 	w.Header().Set("X-Prebid", version.BuildXPrebidHeader(version.Ver))
 	setBrowsingTopicsHeader(w, r)
 
+	r, versionSelection := withStoredVersionSelection(r)
 	req, impExtInfoMap, storedAuctionResponses, storedBidResponses, bidderImpReplaceImp, account, errL := deps.parseRequest(r, &labels, hookExecutor)
+	ao.StoredVersions = versionSelection.Resolved()
 	if errortypes.ContainsFatalError(errL) && writeError(errL, w, &labels) {
 		return
 	}
@@ -209,7 +228,7 @@ func (deps *endpointDeps) Auction(w http.ResponseWriter, r *http.Request, _ http
 	hookExecutor.SetActivityControl(activityControl)
 	hookExecutor.SetAccount(account)
 
-	ctx := context.Background()
+	ctx := tracing.Detach(r.Context())
 
 	timeout := deps.cfg.AuctionTimeouts.LimitAuctionTimeout(time.Duration(req.TMax) * time.Millisecond)
 	if timeout > 0 {
@@ -219,8 +238,7 @@ func (deps *endpointDeps) Auction(w http.ResponseWriter, r *http.Request, _ http
 	}
 
 	// Read Usersyncs/Cookie
-	decoder := usersync.Base64Decoder{}
-	usersyncs := usersync.ReadCookie(r, decoder, &deps.cfg.HostCookie)
+	usersyncs := usersync.ReadCookie(r, deps.cookieDecoder, &deps.cfg.HostCookie)
 	usersync.SyncHostCookie(r, usersyncs, &deps.cfg.HostCookie)
 
 	if req.Site != nil {
@@ -245,7 +263,7 @@ func (deps *endpointDeps) Auction(w http.ResponseWriter, r *http.Request, _ http
 	auctionRequest := &exchange.AuctionRequest{
 		BidRequestWrapper:          req,
 		Account:                    *account,
-		UserSyncs:                  usersyncs,
+		UserSyncs:                  deps.uidMappings.IDFetcher(ctx, usersyncs, req.User),
 		RequestType:                labels.RType,
 		StartTime:                  start,
 		LegacyLabels:               labels,
@@ -260,6 +278,7 @@ func (deps *endpointDeps) Auction(w http.ResponseWriter, r *http.Request, _ http
 		TCF2Config:                 tcf2Config,
 		Activities:                 activityControl,
 		TmaxAdjustments:            deps.tmaxAdjustments,
+		StoredVersions:             ao.StoredVersions,
 	}
 	auctionResponse, err := deps.ex.HoldAuction(ctx, auctionRequest, nil)
 	defer func() {
@@ -275,6 +294,7 @@ func (deps *endpointDeps) Auction(w http.ResponseWriter, r *http.Request, _ http
 	}
 	ao.Response = response
 	ao.SeatNonBid = auctionResponse.GetSeatNonBid()
+	ao.PrivacyTrace = auctionResponse.GetPrivacyTrace()
 	rejectErr, isRejectErr := hookexecution.CastRejectErr(err)
 	if err != nil && !isRejectErr {
 		if errortypes.ReadCode(err) == errortypes.BadInputErrorCode {
@@ -465,7 +485,8 @@ func (deps *endpointDeps) parseRequest(httpRequest *http.Request, labels *metric
 	}
 
 	timeout := parseTimeout(requestJson, time.Duration(deps.cfg.StoredRequestsTimeout)*time.Millisecond)
-	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	versionSelection := stored_requests.VersionSelectionFromContext(httpRequest.Context())
+	ctx, cancel := context.WithTimeout(stored_requests.WithVersionSelection(tracing.Detach(httpRequest.Context()), versionSelection), timeout)
 	defer cancel()
 
 	impInfo, errs := parseImpInfo(requestJson)
@@ -512,8 +533,9 @@ func (deps *endpointDeps) parseRequest(httpRequest *http.Request, labels *metric
 		return
 	}
 
-	// retrieve storedRequests and storedImps once more in case stored data was changed by the raw auction hook
-	if hasPayloadUpdatesAt(hooks.StageRawAuctionRequest.String(), hookExecutor.GetOutcomes()) {
+	// retrieve storedRequests and storedImps once more in case stored data was changed by the raw auction hook,
+	// or the account overrides the rollouts of the versions which were resolved
+	if versionSelection.UseAccountRollouts(account.StoredRequestVersions) || hasPayloadUpdatesAt(hooks.StageRawAuctionRequest.String(), hookExecutor.GetOutcomes()) {
 		impInfo, errs = parseImpInfo(requestJson)
 		if len(errs) > 0 {
 			return nil, nil, nil, nil, nil, nil, errs
@@ -525,7 +547,7 @@ func (deps *endpointDeps) parseRequest(httpRequest *http.Request, labels *metric
 	}
 
 	// Fetch the Stored Request data and merge it into the HTTP request.
-	if requestJson, impExtInfoMap, errs = deps.processStoredRequests(requestJson, impInfo, storedRequests, storedImps, storedBidRequestId, hasStoredBidRequest); len(errs) > 0 {
+	if requestJson, impExtInfoMap, errs = deps.processStoredRequests(requestJson, impInfo, storedRequests, storedImps, storedBidRequestId, hasStoredBidRequest, accountId); len(errs) > 0 {
 		return
 	}
 
@@ -607,6 +629,17 @@ func hasPayloadUpdatesAt(stageName string, outcomes []hookexecution.StageOutcome
 	return false
 }
 
+// withStoredVersionSelection returns a copy of the request which carries the selection of the versions of the
+// versioned stored requests and imps. A version can be pinned for QA with a header or a query param.
+func withStoredVersionSelection(httpRequest *http.Request) (*http.Request, *stored_requests.VersionSelection) {
+	pinned := httpRequest.Header.Get(storedVersionHeader)
+	if pinned == "" {
+		pinned = httpRequest.URL.Query().Get(storedVersionParam)
+	}
+	selection := stored_requests.NewVersionSelection(pinned)
+	return httpRequest.WithContext(stored_requests.WithVersionSelection(httpRequest.Context(), selection)), selection
+}
+
 // parseTimeout returns parses tmax from the requestJson, or returns the default if it doesn't exist.
 //
 // requestJson should be the content of the POST body.
@@ -1679,8 +1712,18 @@ func (deps *endpointDeps) getStoredRequests(ctx context.Context, requestJson []b
 	return storedBidRequestId, hasStoredBidRequest, storedRequests, storedImps, errs
 }
 
-func (deps *endpointDeps) processStoredRequests(requestJson []byte, impInfo []ImpExtPrebidData, storedRequests map[string]json.RawMessage, storedImps map[string]json.RawMessage, storedBidRequestId string, hasStoredBidRequest bool) ([]byte, map[string]exchange.ImpExtInfo, []error) {
-	bidRequestID, err := getBidRequestID(storedRequests[storedBidRequestId])
+func (deps *endpointDeps) processStoredRequests(requestJson []byte, impInfo []ImpExtPrebidData, storedRequests map[string]json.RawMessage, storedImps map[string]json.RawMessage, storedBidRequestId string, hasStoredBidRequest bool, accountID string) ([]byte, map[string]exchange.ImpExtInfo, []error) {
+	// Resolve the placeholders of a parameterized Stored BidRequest against the incoming request
+	storedRequest := storedRequests[storedBidRequestId]
+	if hasStoredBidRequest {
+		rendered, err := storedTemplates.Render(storedRequest, templates.Params{Request: requestJson, Reference: requestJson, AccountID: accountID})
+		if err != nil {
+			return nil, nil, []error{fmt.Errorf("ext.prebid.storedrequest.id %s: %v", storedBidRequestId, err)}
+		}
+		storedRequest = rendered
+	}
+
+	bidRequestID, err := getBidRequestID(storedRequest)
 	if err != nil {
 		return nil, nil, []error{err}
 	}
@@ -1698,7 +1741,7 @@ func (deps *endpointDeps) processStoredRequests(requestJson []byte, impInfo []Im
 			if err != nil {
 				return nil, nil, []error{err}
 			}
-			uuidPatch, err = jsonpatch.MergePatch(storedRequests[storedBidRequestId], uuidPatch)
+			uuidPatch, err = jsonpatch.MergePatch(storedRequest, uuidPatch)
 			if err != nil {
 				errL := storedRequestErrorChecker(requestJson, storedRequests, storedBidRequestId)
 				return nil, nil, errL
@@ -1709,7 +1752,7 @@ func (deps *endpointDeps) processStoredRequests(requestJson []byte, impInfo []Im
 				return nil, nil, errL
 			}
 		} else {
-			resolvedRequest, err = jsonpatch.MergePatch(storedRequests[storedBidRequestId], requestJson)
+			resolvedRequest, err = jsonpatch.MergePatch(storedRequest, requestJson)
 			if err != nil {
 				errL := storedRequestErrorChecker(requestJson, storedRequests, storedBidRequestId)
 				return nil, nil, errL
@@ -1742,7 +1785,13 @@ func (deps *endpointDeps) processStoredRequests(requestJson []byte, impInfo []Im
 	resolvedImps := make([]json.RawMessage, 0, len(impInfo))
 	for i, impData := range impInfo {
 		if impData.ImpExtPrebid.StoredRequest != nil && len(impData.ImpExtPrebid.StoredRequest.ID) > 0 {
-			resolvedImp, err := jsonpatch.MergePatch(storedImps[impData.ImpExtPrebid.StoredRequest.ID], impData.Imp)
+			// Resolve the placeholders of a parameterized Stored Imp against the incoming imp and the resolved request
+			storedImp, err := storedTemplates.Render(storedImps[impData.ImpExtPrebid.StoredRequest.ID], templates.Params{Request: resolvedRequest, Imp: impData.Imp, Reference: impData.Imp, AccountID: accountID})
+			if err != nil {
+				return nil, nil, []error{fmt.Errorf("imp.ext.prebid.storedrequest.id %s: %v", impData.ImpExtPrebid.StoredRequest.ID, err)}
+			}
+
+			resolvedImp, err := jsonpatch.MergePatch(storedImp, impData.Imp)
 
 			if err != nil {
 				hasErr, errMessage := getJsonSyntaxError(impData.Imp)
@@ -1772,7 +1821,7 @@ func (deps *endpointDeps) processStoredRequests(requestJson []byte, impInfo []Im
 			if err != nil && err != jsonparser.KeyPathNotFoundError {
 				return nil, nil, []error{err}
 			}
-			impExtInfoMap[impId] = exchange.ImpExtInfo{EchoVideoAttrs: echoVideoAttributes, StoredImp: storedImps[impData.ImpExtPrebid.StoredRequest.ID], Passthrough: passthrough}
+			impExtInfoMap[impId] = exchange.ImpExtInfo{EchoVideoAttrs: echoVideoAttributes, StoredImp: storedImp, Passthrough: passthrough}
 
 		} else {
 			resolvedImps = append(resolvedImps, impData.Imp)
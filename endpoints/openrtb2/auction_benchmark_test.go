@@ -100,6 +100,7 @@ func BenchmarkOpenrtbEndpoint(b *testing.B) {
 		macros.NewStringIndexBasedReplacer(),
 		nil,
 		singleFormatBidders,
+		nil,
 	)
 
 	endpoint, _ := NewEndpoint(
@@ -110,13 +111,15 @@ func BenchmarkOpenrtbEndpoint(b *testing.B) {
 		empty_fetcher.EmptyFetcher{},
 		&config.Configuration{MaxRequestSize: maxSize},
 		nilMetrics,
-		analyticsBuild.New(&config.Analytics{}),
+		analyticsBuild.New(&config.Analytics{}, &metricsConfig.NilMetricsEngine{}),
 		map[string]string{},
 		[]byte{},
 		nil,
 		empty_fetcher.EmptyFetcher{},
 		hooks.EmptyPlanBuilder{},
 		nil,
+		usersync.Base64Decoder{},
+		nil,
 	)
 
 	b.ResetTimer()
@@ -36,8 +36,10 @@ import (
 	metricsConfig "github.com/prebid/prebid-server/v3/metrics/config"
 	"github.com/prebid/prebid-server/v3/openrtb_ext"
 	"github.com/prebid/prebid-server/v3/ortb"
+	"github.com/prebid/prebid-server/v3/stored_requests"
 	"github.com/prebid/prebid-server/v3/stored_requests/backends/empty_fetcher"
 	"github.com/prebid/prebid-server/v3/stored_responses"
+	"github.com/prebid/prebid-server/v3/usersync"
 	"github.com/prebid/prebid-server/v3/util/iputil"
 	"github.com/prebid/prebid-server/v3/util/jsonutil"
 	"github.com/prebid/prebid-server/v3/util/ptrutil"
@@ -480,13 +482,15 @@ func TestExplicitUserId(t *testing.T) {
 		empty_fetcher.EmptyFetcher{},
 		cfg,
 		&metricsConfig.NilMetricsEngine{},
-		analyticsBuild.New(&config.Analytics{}),
+		analyticsBuild.New(&config.Analytics{}, &metricsConfig.NilMetricsEngine{}),
 		map[string]string{},
 		[]byte{},
 		openrtb_ext.BuildBidderMap(),
 		empty_fetcher.EmptyFetcher{},
 		hooks.EmptyPlanBuilder{},
 		nil,
+		usersync.Base64Decoder{},
+		nil,
 	)
 
 	endpoint(httptest.NewRecorder(), request, nil)
@@ -538,13 +542,15 @@ func doBadAliasRequest(t *testing.T, filename string, expectMsg string) {
 		empty_fetcher.EmptyFetcher{},
 		&config.Configuration{MaxRequestSize: maxSize},
 		&metricsConfig.NilMetricsEngine{},
-		analyticsBuild.New(&config.Analytics{}),
+		analyticsBuild.New(&config.Analytics{}, &metricsConfig.NilMetricsEngine{}),
 		disabledBidders,
 		aliasJSON,
 		bidderMap,
 		empty_fetcher.EmptyFetcher{},
 		hooks.EmptyPlanBuilder{},
 		nil,
+		usersync.Base64Decoder{},
+		nil,
 	)
 
 	request := httptest.NewRequest("POST", "/openrtb2/auction", bytes.NewReader(testBidRequest))
@@ -593,13 +599,15 @@ func TestNilExchange(t *testing.T) {
 		empty_fetcher.EmptyFetcher{},
 		&config.Configuration{MaxRequestSize: maxSize},
 		&metricsConfig.NilMetricsEngine{},
-		analyticsBuild.New(&config.Analytics{}),
+		analyticsBuild.New(&config.Analytics{}, &metricsConfig.NilMetricsEngine{}),
 		map[string]string{},
 		[]byte{},
 		openrtb_ext.BuildBidderMap(),
 		empty_fetcher.EmptyFetcher{},
 		hooks.EmptyPlanBuilder{},
 		nil,
+		usersync.Base64Decoder{},
+		nil,
 	)
 
 	if err == nil {
@@ -619,13 +627,15 @@ func TestExchangeError(t *testing.T) {
 		empty_fetcher.EmptyFetcher{},
 		&config.Configuration{MaxRequestSize: maxSize},
 		&metricsConfig.NilMetricsEngine{},
-		analyticsBuild.New(&config.Analytics{}),
+		analyticsBuild.New(&config.Analytics{}, &metricsConfig.NilMetricsEngine{}),
 		map[string]string{},
 		[]byte{},
 		openrtb_ext.BuildBidderMap(),
 		empty_fetcher.EmptyFetcher{},
 		hooks.EmptyPlanBuilder{},
 		nil,
+		usersync.Base64Decoder{},
+		nil,
 	)
 
 	request := httptest.NewRequest("POST", "/openrtb2/auction", strings.NewReader(validRequest(t, "site.json")))
@@ -746,13 +756,15 @@ func TestImplicitIPsEndToEnd(t *testing.T) {
 			empty_fetcher.EmptyFetcher{},
 			cfg,
 			&metricsConfig.NilMetricsEngine{},
-			analyticsBuild.New(&config.Analytics{}),
+			analyticsBuild.New(&config.Analytics{}, &metricsConfig.NilMetricsEngine{}),
 			map[string]string{},
 			[]byte{},
 			openrtb_ext.BuildBidderMap(),
 			empty_fetcher.EmptyFetcher{},
 			hooks.EmptyPlanBuilder{},
 			nil,
+			usersync.Base64Decoder{},
+			nil,
 		)
 
 		httpReq := httptest.NewRequest("POST", "/openrtb2/auction", strings.NewReader(validRequest(t, test.reqJSONFile)))
@@ -946,13 +958,15 @@ func TestImplicitDNTEndToEnd(t *testing.T) {
 			empty_fetcher.EmptyFetcher{},
 			&config.Configuration{MaxRequestSize: maxSize},
 			&metricsConfig.NilMetricsEngine{},
-			analyticsBuild.New(&config.Analytics{}),
+			analyticsBuild.New(&config.Analytics{}, &metricsConfig.NilMetricsEngine{}),
 			map[string]string{},
 			[]byte{},
 			openrtb_ext.BuildBidderMap(),
 			empty_fetcher.EmptyFetcher{},
 			hooks.EmptyPlanBuilder{},
 			nil,
+			usersync.Base64Decoder{},
+			nil,
 		)
 
 		httpReq := httptest.NewRequest("POST", "/openrtb2/auction", strings.NewReader(validRequest(t, test.reqJSONFile)))
@@ -1183,7 +1197,7 @@ func TestStoredRequests(t *testing.T) {
 		empty_fetcher.EmptyFetcher{},
 		&config.Configuration{MaxRequestSize: maxSize},
 		&metricsConfig.NilMetricsEngine{},
-		analyticsBuild.New(&config.Analytics{}),
+		analyticsBuild.New(&config.Analytics{}, &metricsConfig.NilMetricsEngine{}),
 		map[string]string{},
 		false,
 		[]byte{},
@@ -1195,6 +1209,8 @@ func TestStoredRequests(t *testing.T) {
 		hooks.EmptyPlanBuilder{},
 		nil,
 		openrtb_ext.NormalizeBidderName,
+		usersync.Base64Decoder{},
+		nil,
 	}
 
 	testStoreVideoAttr := []bool{true, true, false, false, false}
@@ -1204,7 +1220,7 @@ func TestStoredRequests(t *testing.T) {
 		assert.Len(t, errs, 0, "No errors should be returned")
 		storedBidRequestId, hasStoredBidRequest, storedRequests, storedImps, errs := deps.getStoredRequests(context.Background(), json.RawMessage(requestData), impInfo)
 		assert.Len(t, errs, 0, "No errors should be returned")
-		newRequest, impExtInfoMap, errList := deps.processStoredRequests(json.RawMessage(requestData), impInfo, storedRequests, storedImps, storedBidRequestId, hasStoredBidRequest)
+		newRequest, impExtInfoMap, errList := deps.processStoredRequests(json.RawMessage(requestData), impInfo, storedRequests, storedImps, storedBidRequestId, hasStoredBidRequest, "")
 		if len(errList) != 0 {
 			for _, err := range errList {
 				if err != nil {
@@ -1226,6 +1242,51 @@ func TestStoredRequests(t *testing.T) {
 	}
 }
 
+func TestStoredRequestTemplates(t *testing.T) {
+	deps := &endpointDeps{
+		uuidGenerator: fakeUUIDGenerator{},
+		cfg:           &config.Configuration{MaxRequestSize: maxSize},
+	}
+	storedRequests := map[string]json.RawMessage{
+		"req": json.RawMessage(`{"tmax":"{{ext.prebid.storedrequest.params.tmax|500}}","site":{"page":"https://{{site.domain}}/"},"ext":{"prebid":{"debug":true}}}`),
+	}
+	storedImps := map[string]json.RawMessage{
+		"imp": json.RawMessage(`{"tagid":"{{account.id}}-{{site.domain}}","ext":{"appnexus":{"placementId":"{{ext.prebid.storedrequest.params.placementId}}"}}}`),
+	}
+
+	testCases := []struct {
+		description   string
+		request       string
+		expectedJSON  string
+		expectedError string
+	}{
+		{
+			description:  "Resolved",
+			request:      `{"id":"1","site":{"domain":"example.com"},"imp":[{"id":"imp-1","ext":{"prebid":{"storedrequest":{"id":"imp","params":{"placementId":12345}}}}}],"ext":{"prebid":{"storedrequest":{"id":"req"}}}}`,
+			expectedJSON: `{"id":"1","tmax":500,"site":{"domain":"example.com","page":"https://example.com/"},"imp":[{"id":"imp-1","tagid":"acct-example.com","ext":{"appnexus":{"placementId":12345},"prebid":{"storedrequest":{"id":"imp","params":{"placementId":12345}}}}}],"ext":{"prebid":{"debug":true,"storedrequest":{"id":"req"}}}}`,
+		},
+		{
+			description:   "Missing Parameter",
+			request:       `{"id":"1","site":{"domain":"example.com"},"imp":[{"id":"imp-1","ext":{"prebid":{"storedrequest":{"id":"imp"}}}}],"ext":{"prebid":{"storedrequest":{"id":"req"}}}}`,
+			expectedError: "imp.ext.prebid.storedrequest.id imp: the required parameter ext.prebid.storedrequest.params.placementId is missing",
+		},
+	}
+
+	for _, test := range testCases {
+		impInfo, errs := parseImpInfo([]byte(test.request))
+		assert.Empty(t, errs, test.description)
+
+		resolved, _, errs := deps.processStoredRequests(json.RawMessage(test.request), impInfo, storedRequests, storedImps, "req", true, "acct")
+		if test.expectedError != "" {
+			assert.Len(t, errs, 1, test.description)
+			assert.EqualError(t, errs[0], test.expectedError, test.description)
+			continue
+		}
+		assert.Empty(t, errs, test.description)
+		assert.JSONEq(t, test.expectedJSON, string(resolved), test.description)
+	}
+}
+
 func TestMergeBidderParams(t *testing.T) {
 	testCases := []struct {
 		description         string
@@ -1633,7 +1694,7 @@ func TestValidateRequest(t *testing.T) {
 		empty_fetcher.EmptyFetcher{},
 		&config.Configuration{MaxRequestSize: maxSize},
 		&metricsConfig.NilMetricsEngine{},
-		analyticsBuild.New(&config.Analytics{}),
+		analyticsBuild.New(&config.Analytics{}, &metricsConfig.NilMetricsEngine{}),
 		map[string]string{},
 		false,
 		[]byte{},
@@ -1645,6 +1706,8 @@ func TestValidateRequest(t *testing.T) {
 		hooks.EmptyPlanBuilder{},
 		nil,
 		openrtb_ext.NormalizeBidderName,
+		usersync.Base64Decoder{},
+		nil,
 	}
 
 	testCases := []struct {
@@ -2351,7 +2414,7 @@ func TestSetIntegrationType(t *testing.T) {
 		empty_fetcher.EmptyFetcher{},
 		&config.Configuration{},
 		&metricsConfig.NilMetricsEngine{},
-		analyticsBuild.New(&config.Analytics{}),
+		analyticsBuild.New(&config.Analytics{}, &metricsConfig.NilMetricsEngine{}),
 		map[string]string{},
 		false,
 		[]byte{},
@@ -2363,6 +2426,8 @@ func TestSetIntegrationType(t *testing.T) {
 		hooks.EmptyPlanBuilder{},
 		nil,
 		openrtb_ext.NormalizeBidderName,
+		usersync.Base64Decoder{},
+		nil,
 	}
 
 	testCases := []struct {
@@ -2418,7 +2483,7 @@ func TestStoredRequestGenerateUuid(t *testing.T) {
 		empty_fetcher.EmptyFetcher{},
 		&config.Configuration{MaxRequestSize: maxSize},
 		&metricsConfig.NilMetricsEngine{},
-		analyticsBuild.New(&config.Analytics{}),
+		analyticsBuild.New(&config.Analytics{}, &metricsConfig.NilMetricsEngine{}),
 		map[string]string{},
 		false,
 		[]byte{},
@@ -2430,6 +2495,8 @@ func TestStoredRequestGenerateUuid(t *testing.T) {
 		hooks.EmptyPlanBuilder{},
 		nil,
 		openrtb_ext.NormalizeBidderName,
+		usersync.Base64Decoder{},
+		nil,
 	}
 
 	req := &openrtb2.BidRequest{}
@@ -2498,7 +2565,7 @@ func TestStoredRequestGenerateUuid(t *testing.T) {
 		assert.Empty(t, errs, test.description)
 		storedBidRequestId, hasStoredBidRequest, storedRequests, storedImps, errs := deps.getStoredRequests(context.Background(), json.RawMessage(test.givenRawData), impInfo)
 		assert.Empty(t, errs, test.description)
-		newRequest, _, errList := deps.processStoredRequests(json.RawMessage(test.givenRawData), impInfo, storedRequests, storedImps, storedBidRequestId, hasStoredBidRequest)
+		newRequest, _, errList := deps.processStoredRequests(json.RawMessage(test.givenRawData), impInfo, storedRequests, storedImps, storedBidRequestId, hasStoredBidRequest, "")
 		assert.Empty(t, errList, test.description)
 
 		if err := jsonutil.UnmarshalValid(newRequest, req); err != nil {
@@ -2523,7 +2590,7 @@ func TestOversizedRequest(t *testing.T) {
 		empty_fetcher.EmptyFetcher{},
 		&config.Configuration{MaxRequestSize: int64(len(reqBody) - 1)},
 		&metricsConfig.NilMetricsEngine{},
-		analyticsBuild.New(&config.Analytics{}),
+		analyticsBuild.New(&config.Analytics{}, &metricsConfig.NilMetricsEngine{}),
 		map[string]string{},
 		false,
 		[]byte{},
@@ -2535,6 +2602,8 @@ func TestOversizedRequest(t *testing.T) {
 		hooks.EmptyPlanBuilder{},
 		nil,
 		openrtb_ext.NormalizeBidderName,
+		usersync.Base64Decoder{},
+		nil,
 	}
 
 	req := httptest.NewRequest("POST", "/openrtb2/auction", strings.NewReader(reqBody))
@@ -2563,7 +2632,7 @@ func TestRequestSizeEdgeCase(t *testing.T) {
 		empty_fetcher.EmptyFetcher{},
 		&config.Configuration{MaxRequestSize: int64(len(reqBody))},
 		&metricsConfig.NilMetricsEngine{},
-		analyticsBuild.New(&config.Analytics{}),
+		analyticsBuild.New(&config.Analytics{}, &metricsConfig.NilMetricsEngine{}),
 		map[string]string{},
 		false,
 		[]byte{},
@@ -2575,6 +2644,8 @@ func TestRequestSizeEdgeCase(t *testing.T) {
 		hooks.EmptyPlanBuilder{},
 		nil,
 		openrtb_ext.NormalizeBidderName,
+		usersync.Base64Decoder{},
+		nil,
 	}
 
 	req := httptest.NewRequest("POST", "/openrtb2/auction", strings.NewReader(reqBody))
@@ -2601,13 +2672,15 @@ func TestNoEncoding(t *testing.T) {
 		empty_fetcher.EmptyFetcher{},
 		&config.Configuration{MaxRequestSize: maxSize},
 		&metricsConfig.NilMetricsEngine{},
-		analyticsBuild.New(&config.Analytics{}),
+		analyticsBuild.New(&config.Analytics{}, &metricsConfig.NilMetricsEngine{}),
 		map[string]string{},
 		[]byte{},
 		openrtb_ext.BuildBidderMap(),
 		empty_fetcher.EmptyFetcher{},
 		hooks.EmptyPlanBuilder{},
 		nil,
+		usersync.Base64Decoder{},
+		nil,
 	)
 	request := httptest.NewRequest("POST", "/openrtb2/auction", strings.NewReader(validRequest(t, "site.json")))
 	recorder := httptest.NewRecorder()
@@ -2686,13 +2759,15 @@ func TestContentType(t *testing.T) {
 		empty_fetcher.EmptyFetcher{},
 		&config.Configuration{MaxRequestSize: maxSize},
 		&metricsConfig.NilMetricsEngine{},
-		analyticsBuild.New(&config.Analytics{}),
+		analyticsBuild.New(&config.Analytics{}, &metricsConfig.NilMetricsEngine{}),
 		map[string]string{},
 		[]byte{},
 		openrtb_ext.BuildBidderMap(),
 		empty_fetcher.EmptyFetcher{},
 		hooks.EmptyPlanBuilder{},
 		nil,
+		usersync.Base64Decoder{},
+		nil,
 	)
 	request := httptest.NewRequest("POST", "/openrtb2/auction", strings.NewReader(validRequest(t, "site.json")))
 	recorder := httptest.NewRecorder()
@@ -2724,7 +2799,7 @@ func TestCurrencyTrunc(t *testing.T) {
 		empty_fetcher.EmptyFetcher{},
 		&config.Configuration{},
 		&metricsConfig.NilMetricsEngine{},
-		analyticsBuild.New(&config.Analytics{}),
+		analyticsBuild.New(&config.Analytics{}, &metricsConfig.NilMetricsEngine{}),
 		map[string]string{},
 		false,
 		[]byte{},
@@ -2736,6 +2811,8 @@ func TestCurrencyTrunc(t *testing.T) {
 		hooks.EmptyPlanBuilder{},
 		nil,
 		openrtb_ext.NormalizeBidderName,
+		usersync.Base64Decoder{},
+		nil,
 	}
 
 	ui := int64(1)
@@ -2773,7 +2850,7 @@ func TestCCPAInvalid(t *testing.T) {
 		empty_fetcher.EmptyFetcher{},
 		&config.Configuration{},
 		&metricsConfig.NilMetricsEngine{},
-		analyticsBuild.New(&config.Analytics{}),
+		analyticsBuild.New(&config.Analytics{}, &metricsConfig.NilMetricsEngine{}),
 		map[string]string{},
 		false,
 		[]byte{},
@@ -2785,6 +2862,8 @@ func TestCCPAInvalid(t *testing.T) {
 		hooks.EmptyPlanBuilder{},
 		nil,
 		openrtb_ext.NormalizeBidderName,
+		usersync.Base64Decoder{},
+		nil,
 	}
 
 	ui := int64(1)
@@ -2826,7 +2905,7 @@ func TestNoSaleInvalid(t *testing.T) {
 		empty_fetcher.EmptyFetcher{},
 		&config.Configuration{},
 		&metricsConfig.NilMetricsEngine{},
-		analyticsBuild.New(&config.Analytics{}),
+		analyticsBuild.New(&config.Analytics{}, &metricsConfig.NilMetricsEngine{}),
 		map[string]string{},
 		false,
 		[]byte{},
@@ -2838,6 +2917,8 @@ func TestNoSaleInvalid(t *testing.T) {
 		hooks.EmptyPlanBuilder{},
 		nil,
 		openrtb_ext.NormalizeBidderName,
+		usersync.Base64Decoder{},
+		nil,
 	}
 
 	ui := int64(1)
@@ -2882,7 +2963,7 @@ func TestValidateSourceTID(t *testing.T) {
 		empty_fetcher.EmptyFetcher{},
 		cfg,
 		&metricsConfig.NilMetricsEngine{},
-		analyticsBuild.New(&config.Analytics{}),
+		analyticsBuild.New(&config.Analytics{}, &metricsConfig.NilMetricsEngine{}),
 		map[string]string{},
 		false,
 		[]byte{},
@@ -2894,6 +2975,8 @@ func TestValidateSourceTID(t *testing.T) {
 		hooks.EmptyPlanBuilder{},
 		nil,
 		openrtb_ext.NormalizeBidderName,
+		usersync.Base64Decoder{},
+		nil,
 	}
 
 	ui := int64(1)
@@ -2928,7 +3011,7 @@ func TestSChainInvalid(t *testing.T) {
 		empty_fetcher.EmptyFetcher{},
 		&config.Configuration{},
 		&metricsConfig.NilMetricsEngine{},
-		analyticsBuild.New(&config.Analytics{}),
+		analyticsBuild.New(&config.Analytics{}, &metricsConfig.NilMetricsEngine{}),
 		map[string]string{},
 		false,
 		[]byte{},
@@ -2940,6 +3023,8 @@ func TestSChainInvalid(t *testing.T) {
 		hooks.EmptyPlanBuilder{},
 		nil,
 		openrtb_ext.NormalizeBidderName,
+		usersync.Base64Decoder{},
+		nil,
 	}
 
 	ui := int64(1)
@@ -3369,7 +3454,7 @@ func TestEidPermissionsInvalid(t *testing.T) {
 		empty_fetcher.EmptyFetcher{},
 		&config.Configuration{},
 		&metricsConfig.NilMetricsEngine{},
-		analyticsBuild.New(&config.Analytics{}),
+		analyticsBuild.New(&config.Analytics{}, &metricsConfig.NilMetricsEngine{}),
 		map[string]string{},
 		false,
 		[]byte{},
@@ -3381,6 +3466,8 @@ func TestEidPermissionsInvalid(t *testing.T) {
 		hooks.EmptyPlanBuilder{},
 		nil,
 		openrtb_ext.NormalizeBidderName,
+		usersync.Base64Decoder{},
+		nil,
 	}
 
 	ui := int64(1)
@@ -3648,13 +3735,15 @@ func TestIOS14EndToEnd(t *testing.T) {
 		empty_fetcher.EmptyFetcher{},
 		&config.Configuration{MaxRequestSize: maxSize},
 		&metricsConfig.NilMetricsEngine{},
-		analyticsBuild.New(&config.Analytics{}),
+		analyticsBuild.New(&config.Analytics{}, &metricsConfig.NilMetricsEngine{}),
 		map[string]string{},
 		[]byte{},
 		openrtb_ext.BuildBidderMap(),
 		empty_fetcher.EmptyFetcher{},
 		hooks.EmptyPlanBuilder{},
 		nil,
+		usersync.Base64Decoder{},
+		nil,
 	)
 
 	httpReq := httptest.NewRequest("POST", "/openrtb2/auction", strings.NewReader(validRequest(t, "app-ios140-no-ifa.json")))
@@ -3711,7 +3800,7 @@ func TestAuctionWarnings(t *testing.T) {
 		empty_fetcher.EmptyFetcher{},
 		&config.Configuration{MaxRequestSize: maxSize},
 		&metricsConfig.NilMetricsEngine{},
-		analyticsBuild.New(&config.Analytics{}),
+		analyticsBuild.New(&config.Analytics{}, &metricsConfig.NilMetricsEngine{}),
 		map[string]string{},
 		false,
 		[]byte{},
@@ -3723,6 +3812,8 @@ func TestAuctionWarnings(t *testing.T) {
 		hooks.EmptyPlanBuilder{},
 		nil,
 		openrtb_ext.NormalizeBidderName,
+		usersync.Base64Decoder{},
+		nil,
 	}
 	for _, test := range testCases {
 		t.Run(test.name, func(t *testing.T) {
@@ -3758,7 +3849,7 @@ func TestParseRequestParseImpInfoError(t *testing.T) {
 		empty_fetcher.EmptyFetcher{},
 		&config.Configuration{MaxRequestSize: int64(len(reqBody))},
 		&metricsConfig.NilMetricsEngine{},
-		analyticsBuild.New(&config.Analytics{}),
+		analyticsBuild.New(&config.Analytics{}, &metricsConfig.NilMetricsEngine{}),
 		map[string]string{},
 		false,
 		[]byte{},
@@ -3770,6 +3861,8 @@ func TestParseRequestParseImpInfoError(t *testing.T) {
 		hooks.EmptyPlanBuilder{},
 		nil,
 		openrtb_ext.NormalizeBidderName,
+		usersync.Base64Decoder{},
+		nil,
 	}
 
 	hookExecutor := hookexecution.NewHookExecutor(deps.hookExecutionPlanBuilder, hookexecution.EndpointAuction, deps.metricsEngine)
@@ -3839,7 +3932,7 @@ func TestParseGzipedRequest(t *testing.T) {
 		empty_fetcher.EmptyFetcher{},
 		&config.Configuration{MaxRequestSize: int64(50), Compression: config.Compression{Request: config.CompressionInfo{GZIP: false}}},
 		&metricsConfig.NilMetricsEngine{},
-		analyticsBuild.New(&config.Analytics{}),
+		analyticsBuild.New(&config.Analytics{}, &metricsConfig.NilMetricsEngine{}),
 		map[string]string{},
 		false,
 		[]byte{},
@@ -3851,6 +3944,8 @@ func TestParseGzipedRequest(t *testing.T) {
 		hooks.EmptyPlanBuilder{},
 		nil,
 		openrtb_ext.NormalizeBidderName,
+		usersync.Base64Decoder{},
+		nil,
 	}
 
 	hookExecutor := hookexecution.NewHookExecutor(deps.hookExecutionPlanBuilder, hookexecution.EndpointAuction, deps.metricsEngine)
@@ -3947,13 +4042,15 @@ func TestAuctionResponseHeaders(t *testing.T) {
 		empty_fetcher.EmptyFetcher{},
 		&config.Configuration{MaxRequestSize: maxSize},
 		&metricsConfig.NilMetricsEngine{},
-		analyticsBuild.New(&config.Analytics{}),
+		analyticsBuild.New(&config.Analytics{}, &metricsConfig.NilMetricsEngine{}),
 		map[string]string{},
 		[]byte{},
 		openrtb_ext.BuildBidderMap(),
 		empty_fetcher.EmptyFetcher{},
 		hooks.EmptyPlanBuilder{},
 		nil,
+		usersync.Base64Decoder{},
+		nil,
 	)
 
 	for _, test := range testCases {
@@ -4022,7 +4119,7 @@ func TestParseRequestMergeBidderParams(t *testing.T) {
 				empty_fetcher.EmptyFetcher{},
 				&config.Configuration{MaxRequestSize: int64(len(test.givenRequestBody))},
 				&metricsConfig.NilMetricsEngine{},
-				analyticsBuild.New(&config.Analytics{}),
+				analyticsBuild.New(&config.Analytics{}, &metricsConfig.NilMetricsEngine{}),
 				map[string]string{},
 				false,
 				[]byte{},
@@ -4034,6 +4131,8 @@ func TestParseRequestMergeBidderParams(t *testing.T) {
 				hooks.EmptyPlanBuilder{},
 				nil,
 				openrtb_ext.NormalizeBidderName,
+				usersync.Base64Decoder{},
+				nil,
 			}
 
 			hookExecutor := hookexecution.NewHookExecutor(deps.hookExecutionPlanBuilder, hookexecution.EndpointAuction, deps.metricsEngine)
@@ -4128,7 +4227,7 @@ func TestParseRequestStoredResponses(t *testing.T) {
 				empty_fetcher.EmptyFetcher{},
 				&config.Configuration{MaxRequestSize: int64(len(test.givenRequestBody))},
 				&metricsConfig.NilMetricsEngine{},
-				analyticsBuild.New(&config.Analytics{}),
+				analyticsBuild.New(&config.Analytics{}, &metricsConfig.NilMetricsEngine{}),
 				map[string]string{},
 				false,
 				[]byte{},
@@ -4140,6 +4239,8 @@ func TestParseRequestStoredResponses(t *testing.T) {
 				hooks.EmptyPlanBuilder{},
 				nil,
 				openrtb_ext.NormalizeBidderName,
+				usersync.Base64Decoder{},
+				nil,
 			}
 
 			hookExecutor := hookexecution.NewHookExecutor(deps.hookExecutionPlanBuilder, hookexecution.EndpointAuction, deps.metricsEngine)
@@ -4241,7 +4342,7 @@ func TestParseRequestStoredBidResponses(t *testing.T) {
 				empty_fetcher.EmptyFetcher{},
 				&config.Configuration{MaxRequestSize: int64(len(test.givenRequestBody))},
 				&metricsConfig.NilMetricsEngine{},
-				analyticsBuild.New(&config.Analytics{}),
+				analyticsBuild.New(&config.Analytics{}, &metricsConfig.NilMetricsEngine{}),
 				map[string]string{},
 				false,
 				[]byte{},
@@ -4253,6 +4354,8 @@ func TestParseRequestStoredBidResponses(t *testing.T) {
 				hooks.EmptyPlanBuilder{},
 				nil,
 				openrtb_ext.NormalizeBidderName,
+				usersync.Base64Decoder{},
+				nil,
 			}
 
 			hookExecutor := hookexecution.NewHookExecutor(deps.hookExecutionPlanBuilder, hookexecution.EndpointAuction, deps.metricsEngine)
@@ -4279,7 +4382,7 @@ func TestValidateStoredResp(t *testing.T) {
 		empty_fetcher.EmptyFetcher{},
 		&config.Configuration{MaxRequestSize: maxSize},
 		&metricsConfig.NilMetricsEngine{},
-		analyticsBuild.New(&config.Analytics{}),
+		analyticsBuild.New(&config.Analytics{}, &metricsConfig.NilMetricsEngine{}),
 		map[string]string{},
 		false,
 		[]byte{},
@@ -4291,6 +4394,8 @@ func TestValidateStoredResp(t *testing.T) {
 		hooks.EmptyPlanBuilder{},
 		nil,
 		openrtb_ext.NormalizeBidderName,
+		usersync.Base64Decoder{},
+		nil,
 	}
 
 	testCases := []struct {
@@ -5097,7 +5202,7 @@ func TestParseRequestMultiBid(t *testing.T) {
 				empty_fetcher.EmptyFetcher{},
 				&config.Configuration{MaxRequestSize: int64(len(test.givenRequestBody))},
 				&metricsConfig.NilMetricsEngine{},
-				analyticsBuild.New(&config.Analytics{}),
+				analyticsBuild.New(&config.Analytics{}, &metricsConfig.NilMetricsEngine{}),
 				map[string]string{},
 				false,
 				[]byte{},
@@ -5109,6 +5214,8 @@ func TestParseRequestMultiBid(t *testing.T) {
 				hooks.EmptyPlanBuilder{},
 				nil,
 				openrtb_ext.NormalizeBidderName,
+				usersync.Base64Decoder{},
+				nil,
 			}
 
 			hookExecutor := hookexecution.NewHookExecutor(deps.hookExecutionPlanBuilder, hookexecution.EndpointAuction, deps.metricsEngine)
@@ -5813,7 +5920,7 @@ func TestValidateRequestCookieDeprecation(t *testing.T) {
 		&mockAccountFetcher{},
 		&config.Configuration{},
 		&metricsConfig.NilMetricsEngine{},
-		analyticsBuild.New(&config.Analytics{}),
+		analyticsBuild.New(&config.Analytics{}, &metricsConfig.NilMetricsEngine{}),
 		map[string]string{},
 		false,
 		[]byte{},
@@ -5825,6 +5932,8 @@ func TestValidateRequestCookieDeprecation(t *testing.T) {
 		hooks.EmptyPlanBuilder{},
 		nil,
 		openrtb_ext.NormalizeBidderName,
+		usersync.Base64Decoder{},
+		nil,
 	}
 
 	for _, test := range testCases {
@@ -6379,3 +6488,39 @@ func TestValidateUser(t *testing.T) {
 		})
 	}
 }
+
+func TestWithStoredVersionSelection(t *testing.T) {
+	testCases := []struct {
+		description    string
+		url            string
+		header         string
+		expectedPinned string
+	}{
+		{
+			description: "Not Pinned",
+			url:         "/openrtb2/auction",
+		},
+		{
+			description:    "Pinned By Header",
+			url:            "/openrtb2/auction?stored_version=v1",
+			header:         "v2",
+			expectedPinned: "v2",
+		},
+		{
+			description:    "Pinned By Query Param",
+			url:            "/openrtb2/auction?stored_version=v1",
+			expectedPinned: "v1",
+		},
+	}
+
+	for _, test := range testCases {
+		httpReq := httptest.NewRequest("POST", test.url, nil)
+		if test.header != "" {
+			httpReq.Header.Set("X-Prebid-Stored-Version", test.header)
+		}
+
+		httpReq, selection := withStoredVersionSelection(httpReq)
+		assert.Equal(t, test.expectedPinned, selection.Pinned, test.description)
+		assert.Same(t, selection, stored_requests.VersionSelectionFromContext(httpReq.Context()), test.description)
+	}
+}
@@ -33,6 +33,8 @@ import (
 	"github.com/prebid/prebid-server/v3/prebid_cache_client"
 	"github.com/prebid/prebid-server/v3/stored_requests"
 	"github.com/prebid/prebid-server/v3/stored_requests/backends/empty_fetcher"
+	"github.com/prebid/prebid-server/v3/stored_requests/templates"
+	"github.com/prebid/prebid-server/v3/tracing"
 	"github.com/prebid/prebid-server/v3/usersync"
 	"github.com/prebid/prebid-server/v3/util/iputil"
 	"github.com/prebid/prebid-server/v3/util/jsonutil"
@@ -58,6 +60,8 @@ func NewVideoEndpoint(
 	bidderMap map[string]openrtb_ext.BidderName,
 	cache prebid_cache_client.Client,
 	tmaxAdjustments *exchange.TmaxAdjustmentsPreprocessed,
+	cookieDecoder usersync.Decoder,
+	uidMappings *usersync.UIDMappings,
 ) (httprouter.Handle, error) {
 
 	if ex == nil || requestValidator == nil || requestsById == nil || accounts == nil || cfg == nil || met == nil {
@@ -93,7 +97,9 @@ func NewVideoEndpoint(
 		empty_fetcher.EmptyFetcher{},
 		hooks.EmptyPlanBuilder{},
 		tmaxAdjustments,
-		openrtb_ext.NormalizeBidderName}).VideoAuctionEndpoint), nil
+		openrtb_ext.NormalizeBidderName,
+		cookieDecoder,
+		uidMappings}).VideoAuctionEndpoint), nil
 }
 
 /*
@@ -161,7 +167,9 @@ func (deps *endpointDeps) VideoAuctionEndpoint(w http.ResponseWriter, r *http.Re
 		}
 		deps.metricsEngine.RecordRequest(labels)
 		deps.metricsEngine.RecordRequestTime(labels, time.Since(start))
+		_, span := tracing.Start(r.Context(), "analytics.log_video_object")
 		deps.analytics.LogVideoObject(&vo, activityControl)
+		span.End()
 	}()
 
 	w.Header().Set("X-Prebid", version.BuildXPrebidHeader(version.Ver))
@@ -196,12 +204,19 @@ func (deps *endpointDeps) VideoAuctionEndpoint(w http.ResponseWriter, r *http.Re
 			return
 		}
 	} else {
-		storedRequest, errs := deps.loadStoredVideoRequest(context.Background(), storedRequestId)
+		storedRequest, errs := deps.loadStoredVideoRequest(tracing.Detach(r.Context()), storedRequestId)
 		if len(errs) > 0 {
 			handleError(&labels, w, errs, &vo, &debugLog)
 			return
 		}
 
+		// resolve the placeholders of a parameterized stored video req against the incoming req
+		storedRequest, err = storedTemplates.Render(storedRequest, templates.Params{Request: requestJson, Reference: requestJson})
+		if err != nil {
+			handleError(&labels, w, []error{fmt.Errorf("storedrequestid %s: %v", storedRequestId, err)}, &vo, &debugLog)
+			return
+		}
+
 		//merge incoming req with stored video req
 		resolvedRequest, err = jsonpatch.MergePatch(storedRequest, requestJson)
 		if err != nil {
@@ -241,7 +256,7 @@ func (deps *endpointDeps) VideoAuctionEndpoint(w http.ResponseWriter, r *http.Re
 	}
 
 	//create impressions array
-	imps, podErrors := deps.createImpressions(videoBidReq, podErrors)
+	imps, podErrors := deps.createImpressions(videoBidReq, resolvedRequest, podErrors)
 
 	if len(podErrors) == initialPodNumber {
 		resPodErr := make([]string, 0)
@@ -270,7 +285,7 @@ func (deps *endpointDeps) VideoAuctionEndpoint(w http.ResponseWriter, r *http.Re
 		return
 	}
 
-	ctx := context.Background()
+	ctx := tracing.Detach(r.Context())
 	timeout := deps.cfg.AuctionTimeouts.LimitAuctionTimeout(time.Duration(bidReqWrapper.TMax) * time.Millisecond)
 	if timeout > 0 {
 		var cancel context.CancelFunc
@@ -279,8 +294,7 @@ func (deps *endpointDeps) VideoAuctionEndpoint(w http.ResponseWriter, r *http.Re
 	}
 
 	// Read Usersyncs/Cookie
-	decoder := usersync.Base64Decoder{}
-	usersyncs := usersync.ReadCookie(r, decoder, &deps.cfg.HostCookie)
+	usersyncs := usersync.ReadCookie(r, deps.cookieDecoder, &deps.cfg.HostCookie)
 	usersync.SyncHostCookie(r, usersyncs, &deps.cfg.HostCookie)
 
 	if bidReqWrapper.App != nil {
@@ -323,7 +337,7 @@ func (deps *endpointDeps) VideoAuctionEndpoint(w http.ResponseWriter, r *http.Re
 	auctionRequest := &exchange.AuctionRequest{
 		BidRequestWrapper:          bidReqWrapper,
 		Account:                    *account,
-		UserSyncs:                  usersyncs,
+		UserSyncs:                  deps.uidMappings.IDFetcher(ctx, usersyncs, bidReqWrapper.User),
 		RequestType:                labels.RType,
 		StartTime:                  start,
 		LegacyLabels:               labels,
@@ -438,7 +452,7 @@ func handleError(labels *metrics.Labels, w http.ResponseWriter, errL []error, vo
 	vo.Errors = append(vo.Errors, errL...)
 }
 
-func (deps *endpointDeps) createImpressions(videoReq *openrtb_ext.BidRequestVideo, podErrors []PodError) ([]openrtb2.Imp, []PodError) {
+func (deps *endpointDeps) createImpressions(videoReq *openrtb_ext.BidRequestVideo, requestJson []byte, podErrors []PodError) ([]openrtb2.Imp, []PodError) {
 	videoDur := videoReq.PodConfig.DurationRangeSec
 	minDuration, maxDuration := minMax(videoDur)
 	reqExactDur := videoReq.PodConfig.RequireExactDuration
@@ -449,7 +463,7 @@ func (deps *endpointDeps) createImpressions(videoReq *openrtb_ext.BidRequestVide
 
 		//load stored impression
 		storedImpressionId := string(pod.ConfigId)
-		storedImp, errs := deps.loadStoredImp(storedImpressionId)
+		storedImp, errs := deps.loadStoredImp(storedImpressionId, requestJson)
 		if errs != nil {
 			err := fmt.Sprintf("unable to load configid %s, Pod id: %d", storedImpressionId, pod.PodId)
 			podErr := PodError{}
@@ -507,7 +521,8 @@ func createImpressionTemplate(imp openrtb2.Imp, video *openrtb2.Video) openrtb2.
 	return imp
 }
 
-func (deps *endpointDeps) loadStoredImp(storedImpId string) (openrtb2.Imp, []error) {
+// loadStoredImp fetches the stored imp of a pod, resolving its placeholders against the video request
+func (deps *endpointDeps) loadStoredImp(storedImpId string, requestJson []byte) (openrtb2.Imp, []error) {
 	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(deps.cfg.StoredRequestsTimeout)*time.Millisecond)
 	defer cancel()
 
@@ -517,7 +532,12 @@ func (deps *endpointDeps) loadStoredImp(storedImpId string) (openrtb2.Imp, []err
 		return impr, err
 	}
 
-	if err := jsonutil.UnmarshalValid(imp[storedImpId], &impr); err != nil {
+	storedImp, renderErr := storedTemplates.Render(imp[storedImpId], templates.Params{Request: requestJson, Reference: requestJson})
+	if renderErr != nil {
+		return impr, []error{renderErr}
+	}
+
+	if err := jsonutil.UnmarshalValid(storedImp, &impr); err != nil {
 		return impr, []error{err}
 	}
 	return impr, nil
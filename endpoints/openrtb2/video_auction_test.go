@@ -24,6 +24,7 @@ import (
 	"github.com/prebid/prebid-server/v3/prebid_cache_client"
 	"github.com/prebid/prebid-server/v3/privacy"
 	"github.com/prebid/prebid-server/v3/stored_requests/backends/empty_fetcher"
+	"github.com/prebid/prebid-server/v3/usersync"
 	"github.com/prebid/prebid-server/v3/util/jsonutil"
 	"github.com/prebid/prebid-server/v3/util/ptrutil"
 
@@ -801,6 +802,44 @@ func TestMergeOpenRTBToVideoRequest(t *testing.T) {
 	assert.Equal(t, videoReq.User, bidReq.User, "User is incorrect")
 }
 
+func TestLoadStoredImpTemplates(t *testing.T) {
+	deps := mockDeps(t, &mockExchangeVideo{})
+	deps.storedReqFetcher = mockVideoStoredImpFetcher{imps: map[string]json.RawMessage{
+		"imp": json.RawMessage(`{"tagid":"{{site.page}}-{{ext.pod|1}}","ext":{"appnexus":{"placementId":"{{app.bundle}}"}}}`),
+	}}
+
+	testCases := []struct {
+		description   string
+		request       string
+		expectedTagID string
+		expectedError string
+	}{
+		{
+			description:   "Resolved",
+			request:       `{"site":{"page":"prebid.com"},"app":{"bundle":12345}}`,
+			expectedTagID: "prebid.com-1",
+		},
+		{
+			description:   "Missing Parameter",
+			request:       `{"site":{"page":"prebid.com"}}`,
+			expectedError: "the required parameter app.bundle is missing",
+		},
+	}
+
+	for _, test := range testCases {
+		imp, errs := deps.loadStoredImp("imp", []byte(test.request))
+		if test.expectedError != "" {
+			if assert.Len(t, errs, 1, test.description) {
+				assert.EqualError(t, errs[0], test.expectedError, test.description)
+			}
+			continue
+		}
+		assert.Empty(t, errs, test.description)
+		assert.Equal(t, test.expectedTagID, imp.TagID, test.description)
+		assert.JSONEq(t, `{"appnexus":{"placementId":12345}}`, string(imp.Ext), test.description)
+	}
+}
+
 func TestHandleError(t *testing.T) {
 	tests := []struct {
 		description       string
@@ -1252,6 +1291,8 @@ func mockDepsWithMetrics(t *testing.T, ex *mockExchangeVideo) (*endpointDeps, *m
 		hooks.EmptyPlanBuilder{},
 		nil,
 		openrtb_ext.NormalizeBidderName,
+		usersync.Base64Decoder{},
+		nil,
 	}
 	return deps, metrics, mockModule
 }
@@ -1291,7 +1332,7 @@ func mockDeps(t *testing.T, ex *mockExchangeVideo) *endpointDeps {
 		&mockAccountFetcher{data: mockVideoAccountData},
 		&config.Configuration{MaxRequestSize: maxSize},
 		&metricsConfig.NilMetricsEngine{},
-		analyticsBuild.New(&config.Analytics{}),
+		analyticsBuild.New(&config.Analytics{}, &metricsConfig.NilMetricsEngine{}),
 		map[string]string{},
 		false,
 		[]byte{},
@@ -1303,6 +1344,8 @@ func mockDeps(t *testing.T, ex *mockExchangeVideo) *endpointDeps {
 		hooks.EmptyPlanBuilder{},
 		nil,
 		openrtb_ext.NormalizeBidderName,
+		usersync.Base64Decoder{},
+		nil,
 	}
 }
 
@@ -1316,7 +1359,7 @@ func mockDepsAppendBidderNames(t *testing.T, ex *mockExchangeAppendBidderNames)
 		empty_fetcher.EmptyFetcher{},
 		&config.Configuration{MaxRequestSize: maxSize},
 		&metricsConfig.NilMetricsEngine{},
-		analyticsBuild.New(&config.Analytics{}),
+		analyticsBuild.New(&config.Analytics{}, &metricsConfig.NilMetricsEngine{}),
 		map[string]string{},
 		false,
 		[]byte{},
@@ -1328,6 +1371,8 @@ func mockDepsAppendBidderNames(t *testing.T, ex *mockExchangeAppendBidderNames)
 		hooks.EmptyPlanBuilder{},
 		nil,
 		openrtb_ext.NormalizeBidderName,
+		usersync.Base64Decoder{},
+		nil,
 	}
 
 	return deps
@@ -1343,7 +1388,7 @@ func mockDepsNoBids(t *testing.T, ex *mockExchangeVideoNoBids) *endpointDeps {
 		empty_fetcher.EmptyFetcher{},
 		&config.Configuration{MaxRequestSize: maxSize},
 		&metricsConfig.NilMetricsEngine{},
-		analyticsBuild.New(&config.Analytics{}),
+		analyticsBuild.New(&config.Analytics{}, &metricsConfig.NilMetricsEngine{}),
 		map[string]string{},
 		false,
 		[]byte{},
@@ -1355,6 +1400,8 @@ func mockDepsNoBids(t *testing.T, ex *mockExchangeVideoNoBids) *endpointDeps {
 		hooks.EmptyPlanBuilder{},
 		nil,
 		openrtb_ext.NormalizeBidderName,
+		usersync.Base64Decoder{},
+		nil,
 	}
 
 	return edep
@@ -1386,6 +1433,15 @@ func (cf mockVideoStoredReqFetcher) FetchResponses(ctx context.Context, ids []st
 	return nil, nil
 }
 
+type mockVideoStoredImpFetcher struct {
+	mockVideoStoredReqFetcher
+	imps map[string]json.RawMessage
+}
+
+func (cf mockVideoStoredImpFetcher) FetchRequests(ctx context.Context, requestIDs []string, impIDs []string) (requestData map[string]json.RawMessage, impData map[string]json.RawMessage, errs []error) {
+	return nil, cf.imps, nil
+}
+
 type mockExchangeVideo struct {
 	lastRequest *openrtb2.BidRequest
 	cache       *mockCacheClient
@@ -0,0 +1,160 @@
+package endpoints
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/golang/glog"
+	"github.com/prebid/openrtb/v20/openrtb2"
+
+	accountService "github.com/prebid/prebid-server/v3/account"
+	"github.com/prebid/prebid-server/v3/config"
+	"github.com/prebid/prebid-server/v3/errortypes"
+	"github.com/prebid/prebid-server/v3/exchange"
+	"github.com/prebid/prebid-server/v3/gdpr"
+	"github.com/prebid/prebid-server/v3/metrics"
+	"github.com/prebid/prebid-server/v3/openrtb_ext"
+	"github.com/prebid/prebid-server/v3/privacy"
+	"github.com/prebid/prebid-server/v3/stored_requests"
+	"github.com/prebid/prebid-server/v3/util/jsonutil"
+	"github.com/prebid/prebid-server/v3/util/ptrutil"
+)
+
+// privacyExplainRequest holds either a full OpenRTB request or the consent fields to explain.
+type privacyExplainRequest struct {
+	Account string          `json:"account"`
+	Bidders []string        `json:"bidders"`
+	Request json.RawMessage `json:"request"`
+
+	GDPR      *int8  `json:"gdpr"`
+	Consent   string `json:"consent"`
+	GPP       string `json:"gpp"`
+	GPPSID    []int8 `json:"gppSid"`
+	USPrivacy string `json:"usPrivacy"`
+	COPPA     int8   `json:"coppa"`
+	LMT       int8   `json:"lmt"`
+}
+
+// NewPrivacyExplainEndpoint reports, for each bidder, the privacy decisions the auction would apply
+// to a request and the reasons behind them.
+func NewPrivacyExplainEndpoint(cfg *config.Configuration, accounts stored_requests.AccountFetcher, explainer exchange.PrivacyExplainer, me metrics.MetricsEngine) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", "POST")
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			writePrivacyExplainError(w, http.StatusBadRequest, fmt.Errorf("failed to read the request body: %v", err))
+			return
+		}
+
+		var explainReq privacyExplainRequest
+		if err := jsonutil.UnmarshalValid(body, &explainReq); err != nil {
+			writePrivacyExplainError(w, http.StatusBadRequest, fmt.Errorf("invalid request: %v", err))
+			return
+		}
+
+		bidRequest, err := explainReq.bidRequest()
+		if err != nil {
+			writePrivacyExplainError(w, http.StatusBadRequest, err)
+			return
+		}
+		if len(bidRequest.Imp) == 0 && len(explainReq.Bidders) == 0 {
+			writePrivacyExplainError(w, http.StatusBadRequest, fmt.Errorf("bidders are required when the request has no imps"))
+			return
+		}
+
+		accountID := explainReq.accountID(bidRequest)
+		account, errs := accountService.GetAccount(r.Context(), cfg, accounts, accountID, me)
+		if len(errs) > 0 {
+			status := http.StatusBadRequest
+			if errortypes.ReadCode(errs[0]) == errortypes.BlockedAppErrorCode {
+				status = http.StatusServiceUnavailable
+			}
+			writePrivacyExplainError(w, status, errs[0])
+			return
+		}
+
+		requestType := metrics.ReqTypeORTB2Web
+		if bidRequest.App != nil {
+			requestType = metrics.ReqTypeORTB2App
+		}
+
+		auctionReq := &exchange.AuctionRequest{
+			BidRequestWrapper: &openrtb_ext.RequestWrapper{BidRequest: bidRequest},
+			Account:           *account,
+			TCF2Config:        gdpr.NewTCF2Config(cfg.GDPR.TCF2, account.GDPR),
+			Activities:        privacy.NewActivityControl(&account.Privacy),
+			LegacyLabels:      metrics.Labels{PubID: accountID, RType: requestType},
+		}
+
+		explanation, err := explainer.ExplainPrivacy(r.Context(), auctionReq, explainReq.Bidders)
+		if err != nil {
+			writePrivacyExplainError(w, http.StatusBadRequest, err)
+			return
+		}
+
+		jsonOutput, err := jsonutil.Marshal(explanation)
+		if err != nil {
+			glog.Errorf("/privacy/explain Critical error when trying to marshal the explanation: %v", err)
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(jsonOutput)
+	}
+}
+
+// bidRequest returns the OpenRTB request to explain, built from the consent fields when none is given
+func (req privacyExplainRequest) bidRequest() (*openrtb2.BidRequest, error) {
+	if len(req.Request) > 0 {
+		bidRequest := &openrtb2.BidRequest{}
+		if err := jsonutil.UnmarshalValid(req.Request, bidRequest); err != nil {
+			return nil, fmt.Errorf("invalid request.request: %v", err)
+		}
+		return bidRequest, nil
+	}
+
+	bidRequest := &openrtb2.BidRequest{
+		ID: "privacy-explain",
+		Regs: &openrtb2.Regs{
+			GDPR:      req.GDPR,
+			GPP:       req.GPP,
+			GPPSID:    req.GPPSID,
+			USPrivacy: req.USPrivacy,
+			COPPA:     req.COPPA,
+		},
+		Site: &openrtb2.Site{Publisher: &openrtb2.Publisher{ID: req.Account}},
+	}
+	if req.Consent != "" {
+		bidRequest.User = &openrtb2.User{Consent: req.Consent}
+	}
+	if req.LMT != 0 {
+		bidRequest.Device = &openrtb2.Device{Lmt: ptrutil.ToPtr(req.LMT)}
+	}
+	return bidRequest, nil
+}
+
+func (req privacyExplainRequest) accountID(bidRequest *openrtb2.BidRequest) string {
+	if req.Account != "" {
+		return req.Account
+	}
+	if bidRequest.Site != nil && bidRequest.Site.Publisher != nil && bidRequest.Site.Publisher.ID != "" {
+		return bidRequest.Site.Publisher.ID
+	}
+	if bidRequest.App != nil && bidRequest.App.Publisher != nil && bidRequest.App.Publisher.ID != "" {
+		return bidRequest.App.Publisher.ID
+	}
+	return metrics.PublisherUnknown
+}
+
+func writePrivacyExplainError(w http.ResponseWriter, status int, err error) {
+	w.WriteHeader(status)
+	w.Write([]byte(err.Error()))
+}
@@ -0,0 +1,148 @@
+package endpoints
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/prebid/openrtb/v20/openrtb2"
+	"github.com/prebid/prebid-server/v3/config"
+	"github.com/prebid/prebid-server/v3/exchange"
+	"github.com/prebid/prebid-server/v3/metrics"
+	"github.com/prebid/prebid-server/v3/privacy"
+	"github.com/prebid/prebid-server/v3/util/ptrutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakePrivacyExplainer struct {
+	auctionReq *exchange.AuctionRequest
+	bidders    []string
+	err        error
+}
+
+func (e *fakePrivacyExplainer) ExplainPrivacy(ctx context.Context, r *exchange.AuctionRequest, bidders []string) (*exchange.PrivacyExplanation, error) {
+	e.auctionReq = r
+	e.bidders = bidders
+	if e.err != nil {
+		return nil, e.err
+	}
+	return &exchange.PrivacyExplanation{
+		GDPRApplies: true,
+		Bidders: map[string]exchange.BidderPrivacyExplanation{
+			"appnexus": {
+				CoreBidder: "appnexus",
+				Activities: map[string]privacy.ActivityDecision{"fetchBids": {Allowed: true}},
+				Scrubbed:   []string{"user.buyeruid"},
+			},
+		},
+	}, nil
+}
+
+func TestPrivacyExplainEndpoint(t *testing.T) {
+	testCases := []struct {
+		name               string
+		method             string
+		body               string
+		accountRequired    bool
+		explainerErr       error
+		expectedStatus     int
+		expectedBody       string
+		expectedAccount    string
+		expectedBidders    []string
+		expectedBidRequest *openrtb2.BidRequest
+	}{
+		{
+			name:            "consent_fields",
+			method:          http.MethodPost,
+			body:            `{"account":"acct","bidders":["appnexus"],"gdpr":1,"consent":"tcf","gpp":"gpp","gppSid":[2],"usPrivacy":"1YNN","coppa":1,"lmt":1}`,
+			expectedStatus:  http.StatusOK,
+			expectedBody:    `{"gdprApplies":true,"ccpaProvided":false,"ccpaEnforced":false,"coppa":false,"lmt":false,"bidders":{"appnexus":{"coreBidder":"appnexus","blocked":false,"activities":{"fetchBids":{"allowed":true}},"ccpaEnforced":false,"scrubbed":["user.buyeruid"]}}}`,
+			expectedAccount: "acct",
+			expectedBidders: []string{"appnexus"},
+			expectedBidRequest: &openrtb2.BidRequest{
+				ID:     "privacy-explain",
+				Regs:   &openrtb2.Regs{GDPR: ptrutil.ToPtr[int8](1), GPP: "gpp", GPPSID: []int8{2}, USPrivacy: "1YNN", COPPA: 1},
+				Site:   &openrtb2.Site{Publisher: &openrtb2.Publisher{ID: "acct"}},
+				User:   &openrtb2.User{Consent: "tcf"},
+				Device: &openrtb2.Device{Lmt: ptrutil.ToPtr[int8](1)},
+			},
+		},
+		{
+			name:            "openrtb_request_account_from_app",
+			method:          http.MethodPost,
+			body:            `{"request":{"id":"req","imp":[{"id":"imp"}],"app":{"publisher":{"id":"acct"}}}}`,
+			expectedStatus:  http.StatusOK,
+			expectedAccount: "acct",
+			expectedBidRequest: &openrtb2.BidRequest{
+				ID:  "req",
+				Imp: []openrtb2.Imp{{ID: "imp"}},
+				App: &openrtb2.App{Publisher: &openrtb2.Publisher{ID: "acct"}},
+			},
+		},
+		{
+			name:           "bidders_required_without_imps",
+			method:         http.MethodPost,
+			body:           `{"account":"acct","gdpr":1}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "bidders are required when the request has no imps",
+		},
+		{
+			name:           "malformed_body",
+			method:         http.MethodPost,
+			body:           `{`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:            "account_required",
+			method:          http.MethodPost,
+			body:            `{"bidders":["appnexus"]}`,
+			accountRequired: true,
+			expectedStatus:  http.StatusBadRequest,
+		},
+		{
+			name:           "explainer_error",
+			method:         http.MethodPost,
+			body:           `{"account":"acct","bidders":["appnexus"]}`,
+			explainerErr:   errors.New("request.ext is invalid"),
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "request.ext is invalid",
+		},
+		{
+			name:           "method_not_allowed",
+			method:         http.MethodGet,
+			expectedStatus: http.StatusMethodNotAllowed,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.name, func(t *testing.T) {
+			explainer := &fakePrivacyExplainer{err: test.explainerErr}
+			accounts := FakeAccountsFetcher{AccountData: map[string]json.RawMessage{
+				"acct": json.RawMessage(`{"id":"acct","disabled":false}`),
+			}}
+			handler := NewPrivacyExplainEndpoint(&config.Configuration{AccountRequired: test.accountRequired}, accounts, explainer, &metrics.MetricsEngineMock{})
+
+			w := httptest.NewRecorder()
+			handler(w, httptest.NewRequest(test.method, "/privacy/explain", strings.NewReader(test.body)))
+
+			assert.Equal(t, test.expectedStatus, w.Code)
+			if test.expectedStatus == http.StatusOK {
+				if test.expectedBody != "" {
+					assert.JSONEq(t, test.expectedBody, w.Body.String())
+				}
+				require.NotNil(t, explainer.auctionReq)
+				assert.Equal(t, test.expectedAccount, explainer.auctionReq.Account.ID)
+				assert.Equal(t, test.expectedAccount, explainer.auctionReq.LegacyLabels.PubID)
+				assert.Equal(t, test.expectedBidders, explainer.bidders)
+				assert.Equal(t, test.expectedBidRequest, explainer.auctionReq.BidRequestWrapper.BidRequest)
+			} else if test.expectedBody != "" {
+				assert.Equal(t, test.expectedBody, w.Body.String())
+			}
+		})
+	}
+}
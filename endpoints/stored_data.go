@@ -0,0 +1,178 @@
+package endpoints
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/golang/glog"
+
+	"github.com/prebid/prebid-server/v3/stored_requests/management"
+	"github.com/prebid/prebid-server/v3/util/jsonutil"
+)
+
+const storedDataPath = "/stored_data/"
+
+// StoredDataValidator validates the stored data before it's persisted
+type StoredDataValidator interface {
+	Validate(dataType management.DataType, id string, data json.RawMessage) []error
+}
+
+// storedDataWrite is the body of a PUT. Version is the version the entry is expected to have, 0 to create it.
+type storedDataWrite struct {
+	Version int             `json:"version"`
+	Author  string          `json:"author"`
+	Data    json.RawMessage `json:"data"`
+}
+
+// NewStoredDataEndpoint manages the stored requests, imps, responses and accounts of the store:
+//
+//	GET    /stored_data/{type}/{id}        returns the entry and its version
+//	PUT    /stored_data/{type}/{id}        creates or updates the entry, if the body's version is current
+//	DELETE /stored_data/{type}/{id}        deletes the entry, if the version query param is current
+//	GET    /stored_data/{type}/{id}/audit  returns the changes of the entry
+func NewStoredDataEndpoint(store management.Store, validator StoredDataValidator, maxSize int64, timeout time.Duration) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		dataType, id, audit, ok := parseStoredDataPath(r.URL.Path)
+		if !ok {
+			writeStoredDataError(w, http.StatusNotFound, fmt.Errorf("the path must be %s{%s}/{id}", storedDataPath, joinDataTypes()))
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), timeout)
+		defer cancel()
+
+		switch {
+		case audit && r.Method == http.MethodGet:
+			records, err := store.Audit(ctx, dataType, id)
+			if err != nil {
+				writeStoredDataError(w, storedDataErrorStatus(err), err)
+				return
+			}
+			writeStoredDataJSON(w, http.StatusOK, records)
+		case audit:
+			w.Header().Set("Allow", "GET")
+			w.WriteHeader(http.StatusMethodNotAllowed)
+		case r.Method == http.MethodGet:
+			entry, err := store.Get(ctx, dataType, id)
+			if err != nil {
+				writeStoredDataError(w, storedDataErrorStatus(err), err)
+				return
+			}
+			writeStoredDataJSON(w, http.StatusOK, entry)
+		case r.Method == http.MethodPut:
+			putStoredData(ctx, w, r, store, validator, maxSize, dataType, id)
+		case r.Method == http.MethodDelete:
+			version, err := strconv.Atoi(r.URL.Query().Get("version"))
+			if err != nil {
+				writeStoredDataError(w, http.StatusBadRequest, errors.New("the version query param must be the current version of the entry"))
+				return
+			}
+			if err := store.Delete(ctx, dataType, id, version, r.URL.Query().Get("author")); err != nil {
+				writeStoredDataError(w, storedDataErrorStatus(err), err)
+				return
+			}
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			w.Header().Set("Allow", "GET, PUT, DELETE")
+			w.WriteHeader(http.StatusMethodNotAllowed)
+		}
+	}
+}
+
+func putStoredData(ctx context.Context, w http.ResponseWriter, r *http.Request, store management.Store, validator StoredDataValidator, maxSize int64, dataType management.DataType, id string) {
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxSize))
+	if err != nil {
+		writeStoredDataError(w, http.StatusBadRequest, fmt.Errorf("failed to read the request body: %v", err))
+		return
+	}
+
+	var write storedDataWrite
+	if err := jsonutil.UnmarshalValid(body, &write); err != nil {
+		writeStoredDataError(w, http.StatusBadRequest, fmt.Errorf("invalid request: %v", err))
+		return
+	}
+	if len(write.Data) == 0 || string(write.Data) == "null" {
+		writeStoredDataError(w, http.StatusBadRequest, errors.New("data is required"))
+		return
+	}
+
+	if errs := validator.Validate(dataType, id, write.Data); len(errs) > 0 {
+		messages := make([]string, 0, len(errs))
+		for _, err := range errs {
+			messages = append(messages, err.Error())
+		}
+		writeStoredDataError(w, http.StatusBadRequest, fmt.Errorf("invalid stored data:\n%s", strings.Join(messages, "\n")))
+		return
+	}
+
+	entry, err := store.Put(ctx, dataType, id, write.Data, write.Version, write.Author)
+	if err != nil {
+		writeStoredDataError(w, storedDataErrorStatus(err), err)
+		return
+	}
+	status := http.StatusOK
+	if write.Version == 0 {
+		status = http.StatusCreated
+	}
+	writeStoredDataJSON(w, status, entry)
+}
+
+// parseStoredDataPath returns the type and id of /stored_data/{type}/{id}, and whether the audit is requested
+func parseStoredDataPath(path string) (dataType management.DataType, id string, audit bool, ok bool) {
+	parts := strings.Split(strings.TrimPrefix(path, storedDataPath), "/")
+	if len(parts) == 3 && parts[2] == "audit" {
+		audit = true
+		parts = parts[:2]
+	}
+	if len(parts) != 2 || parts[1] == "" || !management.IsDataType(management.DataType(parts[0])) {
+		return "", "", false, false
+	}
+	return management.DataType(parts[0]), parts[1], audit, true
+}
+
+func joinDataTypes() string {
+	types := make([]string, 0, len(management.DataTypes))
+	for _, dataType := range management.DataTypes {
+		types = append(types, string(dataType))
+	}
+	return strings.Join(types, "|")
+}
+
+func storedDataErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, management.ErrNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, management.ErrVersionConflict):
+		return http.StatusConflict
+	case errors.Is(err, management.ErrInvalidID):
+		return http.StatusBadRequest
+	case errors.Is(err, context.DeadlineExceeded):
+		return http.StatusGatewayTimeout
+	}
+	glog.Errorf("%s Failed to access the stored data: %v", storedDataPath, err)
+	return http.StatusInternalServerError
+}
+
+func writeStoredDataJSON(w http.ResponseWriter, status int, value interface{}) {
+	jsonOutput, err := jsonutil.Marshal(value)
+	if err != nil {
+		glog.Errorf("%s Critical error when trying to marshal the response: %v", storedDataPath, err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	w.Write(jsonOutput)
+}
+
+func writeStoredDataError(w http.ResponseWriter, status int, err error) {
+	w.WriteHeader(status)
+	w.Write([]byte(err.Error()))
+}
@@ -0,0 +1,158 @@
+package endpoints
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prebid/prebid-server/v3/stored_requests/management"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeStoredDataValidator struct{}
+
+func (fakeStoredDataValidator) Validate(dataType management.DataType, id string, data json.RawMessage) []error {
+	if strings.Contains(string(data), "invalid") {
+		return []error{errors.New("request.imp[0] is invalid")}
+	}
+	return nil
+}
+
+func TestStoredDataEndpoint(t *testing.T) {
+	directory := t.TempDir()
+	store := management.NewFileStore(directory, filepath.Join(directory, "audit.jsonl"))
+	endpoint := NewStoredDataEndpoint(store, fakeStoredDataValidator{}, 1024, time.Second)
+
+	testCases := []struct {
+		description    string
+		method         string
+		path           string
+		body           string
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			description:    "Get Missing",
+			method:         http.MethodGet,
+			path:           "/stored_data/imps/imp",
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   "the stored data doesn't exist",
+		},
+		{
+			description:    "Create",
+			method:         http.MethodPut,
+			path:           "/stored_data/imps/imp",
+			body:           `{"version":0,"author":"alice","data":{"banner":{}}}`,
+			expectedStatus: http.StatusCreated,
+			expectedBody:   `{"id":"imp","version":1,"data":{"banner":{}}}`,
+		},
+		{
+			description:    "Create Existing",
+			method:         http.MethodPut,
+			path:           "/stored_data/imps/imp",
+			body:           `{"version":0,"data":{"video":{}}}`,
+			expectedStatus: http.StatusConflict,
+			expectedBody:   "the stored data was changed since the expected version",
+		},
+		{
+			description:    "Invalid Data",
+			method:         http.MethodPut,
+			path:           "/stored_data/imps/imp",
+			body:           `{"version":1,"data":{"invalid":true}}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "invalid stored data:\nrequest.imp[0] is invalid",
+		},
+		{
+			description:    "Missing Data",
+			method:         http.MethodPut,
+			path:           "/stored_data/imps/imp",
+			body:           `{"version":1}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "data is required",
+		},
+		{
+			description:    "Update",
+			method:         http.MethodPut,
+			path:           "/stored_data/imps/imp",
+			body:           `{"version":1,"author":"bob","data":{"video":{}}}`,
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"id":"imp","version":2,"data":{"video":{}}}`,
+		},
+		{
+			description:    "Get",
+			method:         http.MethodGet,
+			path:           "/stored_data/imps/imp",
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"id":"imp","version":2,"data":{"video":{}}}`,
+		},
+		{
+			description:    "Delete Without Version",
+			method:         http.MethodDelete,
+			path:           "/stored_data/imps/imp",
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "the version query param must be the current version of the entry",
+		},
+		{
+			description:    "Delete",
+			method:         http.MethodDelete,
+			path:           "/stored_data/imps/imp?version=2&author=alice",
+			expectedStatus: http.StatusNoContent,
+		},
+		{
+			description:    "Audit",
+			method:         http.MethodGet,
+			path:           "/stored_data/imps/imp/audit",
+			expectedStatus: http.StatusOK,
+		},
+		{
+			description:    "Unknown Type",
+			method:         http.MethodGet,
+			path:           "/stored_data/videos/imp",
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   "the path must be /stored_data/{requests|imps|responses|accounts}/{id}",
+		},
+		{
+			description:    "Invalid ID",
+			method:         http.MethodGet,
+			path:           "/stored_data/imps/..",
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			description:    "Method Not Allowed",
+			method:         http.MethodPost,
+			path:           "/stored_data/imps/imp",
+			expectedStatus: http.StatusMethodNotAllowed,
+		},
+	}
+
+	for _, test := range testCases {
+		request := httptest.NewRequest(test.method, test.path, strings.NewReader(test.body))
+		recorder := httptest.NewRecorder()
+		endpoint(recorder, request)
+
+		assert.Equal(t, test.expectedStatus, recorder.Code, test.description)
+		if test.expectedBody == "" {
+			continue
+		}
+		if strings.HasPrefix(test.expectedBody, "{") {
+			assert.JSONEq(t, test.expectedBody, recorder.Body.String(), test.description)
+		} else {
+			assert.Equal(t, test.expectedBody, recorder.Body.String(), test.description)
+		}
+	}
+
+	request := httptest.NewRequest(http.MethodGet, "/stored_data/imps/imp/audit", nil)
+	recorder := httptest.NewRecorder()
+	endpoint(recorder, request)
+	var records []management.AuditRecord
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &records))
+	require.Len(t, records, 3)
+	assert.Equal(t, []management.Action{management.ActionCreate, management.ActionUpdate, management.ActionDelete}, []management.Action{records[0].Action, records[1].Action, records[2].Action})
+	assert.Equal(t, "alice", records[2].Author)
+}
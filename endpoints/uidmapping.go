@@ -0,0 +1,204 @@
+package endpoints
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/julienschmidt/httprouter"
+	accountService "github.com/prebid/prebid-server/v3/account"
+	"github.com/prebid/prebid-server/v3/config"
+	"github.com/prebid/prebid-server/v3/errortypes"
+	"github.com/prebid/prebid-server/v3/gdpr"
+	"github.com/prebid/prebid-server/v3/metrics"
+	"github.com/prebid/prebid-server/v3/privacy"
+	"github.com/prebid/prebid-server/v3/stored_requests"
+	"github.com/prebid/prebid-server/v3/usersync"
+	"github.com/prebid/prebid-server/v3/util/jsonutil"
+	stringutil "github.com/prebid/prebid-server/v3/util/stringutil"
+)
+
+// uidMappingRequest is a batch of partner UIDs sent server to server
+type uidMappingRequest struct {
+	Account string             `json:"account"`
+	Records []uidMappingRecord `json:"records"`
+}
+
+// uidMappingRecord maps the UID given by a bidder to either the host cookie UID or the hashed email of a user
+type uidMappingRecord struct {
+	HostUID     string `json:"hostuid"`
+	EmailSHA256 string `json:"email_sha256"`
+	Bidder      string `json:"bidder"`
+	UID         string `json:"uid"`
+	TTL         int    `json:"ttl"`
+	GDPR        string `json:"gdpr"`
+	GDPRConsent string `json:"gdpr_consent"`
+	GPP         string `json:"gpp"`
+	GPPSID      string `json:"gpp_sid"`
+}
+
+type uidMappingResponse struct {
+	Accepted int                      `json:"accepted"`
+	Rejected []uidMappingRejectedItem `json:"rejected"`
+}
+
+type uidMappingRejectedItem struct {
+	Index int    `json:"index"`
+	Error string `json:"error"`
+}
+
+// NewUIDMappingEndpoint ingests the UIDs of the partners which can't sync through the browser. Each
+// record goes through the same bidder, activity and GDPR checks as a /setuid call.
+func NewUIDMappingEndpoint(cfg *config.Configuration, syncersByBidder map[string]usersync.Syncer, gdprPermsBuilder gdpr.PermissionsBuilder, tcf2CfgBuilder gdpr.TCF2ConfigBuilder, accountsFetcher stored_requests.AccountFetcher, metricsEngine metrics.MetricsEngine, store usersync.UIDMappingStore) httprouter.Handle {
+	mappingCfg := cfg.UserSync.UIDMapping
+
+	return httprouter.Handle(func(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+		if !uidMappingAuthorized(r, mappingCfg.APIKeys) {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			writeUIDMappingError(w, http.StatusBadRequest, fmt.Errorf("failed to read the request body: %v", err))
+			return
+		}
+		var mappingReq uidMappingRequest
+		if err := jsonutil.UnmarshalValid(body, &mappingReq); err != nil {
+			writeUIDMappingError(w, http.StatusBadRequest, fmt.Errorf("invalid request: %v", err))
+			return
+		}
+		if len(mappingReq.Records) > mappingCfg.MaxRecords {
+			writeUIDMappingError(w, http.StatusRequestEntityTooLarge, fmt.Errorf("the request has %d records, the limit is %d", len(mappingReq.Records), mappingCfg.MaxRecords))
+			return
+		}
+
+		accountID := mappingReq.Account
+		if accountID == "" {
+			accountID = metrics.PublisherUnknown
+		}
+		account, fetchErrs := accountService.GetAccount(r.Context(), cfg, accountsFetcher, accountID, metricsEngine)
+		if len(fetchErrs) > 0 {
+			writeUIDMappingError(w, http.StatusBadRequest, combineErrors(fetchErrs))
+			return
+		}
+		activityControl := privacy.NewActivityControl(&account.Privacy)
+		tcf2Cfg := tcf2CfgBuilder(cfg.GDPR.TCF2, account.GDPR)
+
+		now := time.Now()
+		maxTTL := time.Duration(mappingCfg.MaxTTLSeconds) * time.Second
+		response := uidMappingResponse{Rejected: []uidMappingRejectedItem{}}
+		mappings := make([]usersync.UIDMapping, 0, len(mappingReq.Records))
+		for i, record := range mappingReq.Records {
+			mapping, err := record.validate(syncersByBidder, activityControl, gdprPermsBuilder, tcf2Cfg)
+			if err != nil {
+				response.Rejected = append(response.Rejected, uidMappingRejectedItem{Index: i, Error: err.Error()})
+				continue
+			}
+			mapping.Expires = now.Add(min(time.Duration(record.TTL)*time.Second, maxTTL))
+			mappings = append(mappings, mapping)
+		}
+
+		if len(mappings) > 0 {
+			ctx, cancel := context.WithTimeout(r.Context(), time.Duration(mappingCfg.TimeoutMS)*time.Millisecond)
+			defer cancel()
+			if err := store.Save(ctx, mappings); err != nil {
+				writeUIDMappingError(w, http.StatusServiceUnavailable, fmt.Errorf("failed to save the uid mappings: %v", err))
+				return
+			}
+		}
+		response.Accepted = len(mappings)
+
+		data, err := jsonutil.Marshal(response)
+		if err != nil {
+			writeUIDMappingError(w, http.StatusInternalServerError, err)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(data)
+	})
+}
+
+// uidMappingAuthorized checks the bearer token of the request against the API keys in constant time
+func uidMappingAuthorized(r *http.Request, apiKeys []string) bool {
+	token, found := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !found || token == "" {
+		return false
+	}
+	authorized := false
+	for _, apiKey := range apiKeys {
+		if subtle.ConstantTimeCompare([]byte(token), []byte(apiKey)) == 1 {
+			authorized = true
+		}
+	}
+	return authorized
+}
+
+// validate returns the mapping of the record, or an error when the record is invalid or the user
+// doesn't allow the bidder to sync.
+func (record uidMappingRecord) validate(syncersByBidder map[string]usersync.Syncer, activityControl privacy.ActivityControl, gdprPermsBuilder gdpr.PermissionsBuilder, tcf2Cfg gdpr.TCF2ConfigReader) (usersync.UIDMapping, error) {
+	var mapping usersync.UIDMapping
+	switch {
+	case record.HostUID != "" && record.EmailSHA256 != "":
+		return mapping, errors.New("only one of hostuid and email_sha256 may be set")
+	case record.HostUID != "":
+		mapping.KeyType, mapping.Key = usersync.UIDMappingKeyHostUID, record.HostUID
+	case record.EmailSHA256 != "":
+		if _, err := hex.DecodeString(record.EmailSHA256); err != nil || len(record.EmailSHA256) != 64 {
+			return mapping, errors.New("email_sha256 must be a hex encoded SHA-256 hash")
+		}
+		mapping.KeyType, mapping.Key = usersync.UIDMappingKeyEmailSHA256, strings.ToLower(record.EmailSHA256)
+	default:
+		return mapping, errors.New("one of hostuid and email_sha256 is required")
+	}
+
+	if record.UID == "" {
+		return mapping, errors.New("uid is required")
+	}
+	if record.TTL <= 0 {
+		return mapping, errors.New("ttl must be greater than 0")
+	}
+
+	query := url.Values{"bidder": []string{record.Bidder}}
+	syncer, _, err := getSyncer(query, syncersByBidder)
+	if err != nil {
+		return mapping, err
+	}
+	mapping.SyncerKey = syncer.Key()
+	mapping.UID = record.UID
+
+	gppSID, err := stringutil.StrToInt8Slice(record.GPPSID)
+	if err != nil {
+		return mapping, errors.New("invalid gpp_sid encoding, must be a csv list of integers")
+	}
+	policies := privacy.Policies{GPPSID: gppSID, GPP: record.GPP}
+	if !activityControl.Allow(privacy.ActivitySyncUser, privacy.Component{Type: privacy.ComponentTypeBidder, Name: record.Bidder}, privacy.NewRequestFromPolicies(policies)) {
+		return mapping, errors.New("the user doesn't allow the bidder to sync")
+	}
+
+	query.Set("gdpr", record.GDPR)
+	query.Set("gdpr_consent", record.GDPRConsent)
+	query.Set("gpp", record.GPP)
+	query.Set("gpp_sid", record.GPPSID)
+	gdprRequestInfo, err := extractGDPRInfo(query)
+	if err != nil && !errortypes.IsWarning(err) {
+		return mapping, err
+	}
+	if shouldReturn, _, body := preventSyncsGDPR(gdprRequestInfo, gdprPermsBuilder, tcf2Cfg); shouldReturn {
+		return mapping, errors.New(body)
+	}
+	return mapping, nil
+}
+
+func writeUIDMappingError(w http.ResponseWriter, status int, err error) {
+	w.WriteHeader(status)
+	w.Write([]byte(err.Error()))
+}
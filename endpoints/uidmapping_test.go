@@ -0,0 +1,203 @@
+package endpoints
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prebid/prebid-server/v3/config"
+	"github.com/prebid/prebid-server/v3/gdpr"
+	"github.com/prebid/prebid-server/v3/metrics"
+	"github.com/prebid/prebid-server/v3/usersync"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUIDMappingEndpoint(t *testing.T) {
+	emailHash := strings.Repeat("AB", 32)
+
+	testCases := []struct {
+		description       string
+		authorization     string
+		body              string
+		allowHostCookies  bool
+		expectedStatus    int
+		expectedBody      string
+		expectedHostUIDs  map[string]string
+		expectedEmailUIDs map[string]string
+	}{
+		{
+			description:    "Missing API Key",
+			body:           `{"records":[]}`,
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			description:    "Wrong API Key",
+			authorization:  "Bearer wrong",
+			body:           `{"records":[]}`,
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			description:    "Malformed Body",
+			authorization:  "Bearer key",
+			body:           `{`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			description:    "Too Many Records",
+			authorization:  "Bearer key",
+			body:           `{"records":[{},{},{}]}`,
+			expectedStatus: http.StatusRequestEntityTooLarge,
+			expectedBody:   "the request has 3 records, the limit is 2",
+		},
+		{
+			description:    "Blocked Account",
+			authorization:  "Bearer key",
+			body:           `{"account":"disabled_acct","records":[]}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			description:       "Accepted Records",
+			authorization:     "Bearer key",
+			body:              `{"records":[{"hostuid":"host-1","bidder":"appnexus","uid":"an-1","ttl":3600},{"email_sha256":"` + emailHash + `","bidder":"rubicon","uid":"rp-1","ttl":999999}]}`,
+			allowHostCookies:  true,
+			expectedStatus:    http.StatusOK,
+			expectedBody:      `{"accepted":2,"rejected":[]}`,
+			expectedHostUIDs:  map[string]string{"adnxs": "an-1"},
+			expectedEmailUIDs: map[string]string{"rubicon": "rp-1"},
+		},
+		{
+			description:    "Rejected Records",
+			authorization:  "Bearer key",
+			body:           `{"records":[{"bidder":"appnexus","uid":"an-1","ttl":60},{"hostuid":"host-1","email_sha256":"` + emailHash + `","bidder":"appnexus","uid":"an-1","ttl":60}]}`,
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"accepted":0,"rejected":[{"index":0,"error":"one of hostuid and email_sha256 is required"},{"index":1,"error":"only one of hostuid and email_sha256 may be set"}]}`,
+		},
+		{
+			description:    "Invalid Record Fields",
+			authorization:  "Bearer key",
+			body:           `{"records":[{"email_sha256":"abc","bidder":"appnexus","uid":"an-1","ttl":60},{"hostuid":"host-1","bidder":"unknown","uid":"an-1","ttl":60}]}`,
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"accepted":0,"rejected":[{"index":0,"error":"email_sha256 must be a hex encoded SHA-256 hash"},{"index":1,"error":"The bidder name provided is not supported by Prebid Server"}]}`,
+		},
+		{
+			description:    "Missing UID And TTL",
+			authorization:  "Bearer key",
+			body:           `{"records":[{"hostuid":"host-1","bidder":"appnexus","ttl":60},{"hostuid":"host-1","bidder":"appnexus","uid":"an-1"}]}`,
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"accepted":0,"rejected":[{"index":0,"error":"uid is required"},{"index":1,"error":"ttl must be greater than 0"}]}`,
+		},
+		{
+			description:    "Activity Blocks Sync",
+			authorization:  "Bearer key",
+			body:           `{"account":"valid_acct_with_valid_activities_usersync_disabled","records":[{"hostuid":"host-1","bidder":"appnexus","uid":"an-1","ttl":60}]}`,
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"accepted":0,"rejected":[{"index":0,"error":"the user doesn't allow the bidder to sync"}]}`,
+		},
+		{
+			description:      "GDPR Blocks Sync",
+			authorization:    "Bearer key",
+			body:             `{"records":[{"hostuid":"host-1","bidder":"appnexus","uid":"an-1","ttl":60,"gdpr":"1","gdpr_consent":"consent"}]}`,
+			allowHostCookies: false,
+			expectedStatus:   http.StatusOK,
+			expectedBody:     `{"accepted":0,"rejected":[{"index":0,"error":"The gdpr_consent string prevents cookies from being saved"}]}`,
+		},
+		{
+			description:      "GDPR Consent Missing",
+			authorization:    "Bearer key",
+			body:             `{"records":[{"hostuid":"host-1","bidder":"appnexus","uid":"an-1","ttl":60,"gdpr":"1"}]}`,
+			allowHostCookies: true,
+			expectedStatus:   http.StatusOK,
+			expectedBody:     `{"accepted":0,"rejected":[{"index":0,"error":"GDPR consent is required when gdpr signal equals 1"}]}`,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.description, func(t *testing.T) {
+			store := usersync.NewMemoryUIDMappingStore(1024 * 1024)
+			req := httptest.NewRequest(http.MethodPost, "/uid_mapping", strings.NewReader(test.body))
+			if test.authorization != "" {
+				req.Header.Set("Authorization", test.authorization)
+			}
+
+			response := doUIDMappingRequest(req, store, test.allowHostCookies)
+
+			assert.Equal(t, test.expectedStatus, response.Code)
+			if test.expectedBody != "" {
+				assert.Equal(t, test.expectedBody, response.Body.String())
+			}
+			assertMappedUIDs(t, store, usersync.UIDMappingKeyHostUID, "host-1", test.expectedHostUIDs)
+			assertMappedUIDs(t, store, usersync.UIDMappingKeyEmailSHA256, strings.ToLower(emailHash), test.expectedEmailUIDs)
+		})
+	}
+}
+
+func TestUIDMappingEndpointCapsTTL(t *testing.T) {
+	store := usersync.NewMemoryUIDMappingStore(1024 * 1024)
+	req := httptest.NewRequest(http.MethodPost, "/uid_mapping", strings.NewReader(`{"records":[{"hostuid":"host-1","bidder":"appnexus","uid":"an-1","ttl":999999}]}`))
+	req.Header.Set("Authorization", "Bearer key")
+
+	response := doUIDMappingRequest(req, store, true)
+	require.Equal(t, http.StatusOK, response.Code)
+
+	uids, err := store.Get(context.Background(), usersync.UIDMappingKeyHostUID, "host-1")
+	require.NoError(t, err)
+	assert.WithinDuration(t, time.Now().Add(time.Hour), uids["adnxs"].Expires, time.Minute)
+}
+
+func doUIDMappingRequest(req *http.Request, store usersync.UIDMappingStore, allowHostCookies bool) *httptest.ResponseRecorder {
+	cfg := config.Configuration{
+		UserSync: config.UserSync{
+			UIDMapping: config.UIDMapping{
+				Enabled:       true,
+				APIKeys:       []string{"other", "key"},
+				MaxRecords:    2,
+				MaxTTLSeconds: 3600,
+				TimeoutMS:     50,
+				Type:          config.UIDMappingTypeMemory,
+			},
+		},
+	}
+	cfg.MarshalAccountDefaults()
+
+	gdprPermsBuilder := fakePermissionsBuilder{
+		permissions: &fakePermsSetUID{allowHost: allowHostCookies},
+	}.Builder
+	tcf2ConfigBuilder := fakeTCF2ConfigBuilder{
+		cfg: gdpr.NewTCF2Config(config.TCF2{}, config.AccountGDPR{}),
+	}.Builder
+
+	syncersByBidder := map[string]usersync.Syncer{
+		"appnexus": fakeSyncer{key: "adnxs"},
+		"rubicon":  fakeSyncer{key: "rubicon"},
+	}
+
+	fakeAccountsFetcher := FakeAccountsFetcher{AccountData: map[string]json.RawMessage{
+		"disabled_acct": json.RawMessage(`{"disabled":true}`),
+		"valid_acct_with_valid_activities_usersync_disabled": json.RawMessage(`{"privacy":{"allowactivities":{"syncUser":{"default": false}}}}`),
+	}}
+
+	endpoint := NewUIDMappingEndpoint(&cfg, syncersByBidder, gdprPermsBuilder, tcf2ConfigBuilder, fakeAccountsFetcher, &metrics.MetricsEngineMock{}, store)
+	response := httptest.NewRecorder()
+	endpoint(response, req, nil)
+	return response
+}
+
+func assertMappedUIDs(t *testing.T, store usersync.UIDMappingStore, keyType, key string, expected map[string]string) {
+	t.Helper()
+
+	uids, err := store.Get(context.Background(), keyType, key)
+	require.NoError(t, err)
+	actual := make(map[string]string, len(uids))
+	for syncerKey, uid := range uids {
+		actual[syncerKey] = uid.UID
+	}
+	if expected == nil {
+		expected = map[string]string{}
+	}
+	assert.Equal(t, expected, actual)
+}
@@ -0,0 +1,97 @@
+package exchange
+
+import (
+	"github.com/prebid/openrtb/v20/openrtb2"
+
+	"github.com/prebid/prebid-server/v3/exchange/entities"
+	"github.com/prebid/prebid-server/v3/metrics"
+	"github.com/prebid/prebid-server/v3/openrtb_ext"
+)
+
+// auctionBid is a bid which entered the auction of an imp, with the adapter which made it
+type auctionBid struct {
+	adapter openrtb_ext.BidderName
+	bid     *entities.PbsOrtbBid
+}
+
+// recordAuctionOutcome records the outcome of the auction of each imp: the bids which entered it, the
+// winning bid and how much it beat the best bid of the other adapters by. The winners are those of the
+// auction which set the targeting, if any, and are otherwise picked as it would. The prices are recorded
+// in thousandths, as the adapter prices are.
+func recordAuctionOutcome(me metrics.MetricsEngine, imps []openrtb2.Imp, adapterBids map[openrtb_ext.BidderName]*entities.PbsOrtbSeatBid, winningBids map[string]*entities.PbsOrtbBid, pubID string, preferDeals bool) {
+	bidsByImp := make(map[string][]auctionBid, len(imps))
+	bidsByAdapter := make(map[openrtb_ext.BidderName]int)
+	for seat, seatBid := range adapterBids {
+		if seatBid == nil {
+			continue
+		}
+		for _, bid := range seatBid.Bids {
+			if bid == nil || bid.Bid == nil {
+				continue
+			}
+			adapter := bid.AdapterCode
+			if adapter == "" {
+				adapter = seat
+			}
+			bidsByImp[bid.Bid.ImpID] = append(bidsByImp[bid.Bid.ImpID], auctionBid{adapter: adapter, bid: bid})
+			bidsByAdapter[adapter]++
+		}
+	}
+
+	for adapter, bids := range bidsByAdapter {
+		me.RecordAdapterAuctionBids(metrics.AdapterLabels{Adapter: adapter, PubID: pubID}, bids)
+	}
+
+	for _, imp := range imps {
+		bids := bidsByImp[imp.ID]
+		me.RecordImpBids(pubID, len(bids))
+
+		var winner *auctionBid
+		if winningBids != nil {
+			winner = findAuctionBid(bids, winningBids[imp.ID])
+		} else {
+			winner = bestAuctionBid(bids, "", preferDeals)
+		}
+		if winner == nil {
+			continue
+		}
+		labels := metrics.AdapterLabels{Adapter: winner.adapter, PubID: pubID}
+		me.RecordAdapterWin(labels, winner.bid.BidType, winner.bid.Bid.Price*1000)
+
+		runnerUp := bestAuctionBid(bids, winner.adapter, preferDeals)
+		if runnerUp == nil {
+			continue
+		}
+		// A deal which won over a higher price beat nothing
+		if gap := winner.bid.Bid.Price - runnerUp.bid.Bid.Price; gap >= 0 {
+			me.RecordAdapterWinPriceGap(labels, gap*1000)
+		}
+	}
+}
+
+// findAuctionBid returns the auction bid of the bid, or nil if it isn't one of the bids
+func findAuctionBid(bids []auctionBid, bid *entities.PbsOrtbBid) *auctionBid {
+	if bid == nil {
+		return nil
+	}
+	for i := range bids {
+		if bids[i].bid == bid {
+			return &bids[i]
+		}
+	}
+	return nil
+}
+
+// bestAuctionBid finds the bid which wins among the bids of the adapters other than excluded
+func bestAuctionBid(bids []auctionBid, excluded openrtb_ext.BidderName, preferDeals bool) *auctionBid {
+	var best *auctionBid
+	for i := range bids {
+		if bids[i].adapter == excluded {
+			continue
+		}
+		if best == nil || isNewWinningBid(bids[i].bid.Bid, best.bid.Bid, preferDeals) {
+			best = &bids[i]
+		}
+	}
+	return best
+}
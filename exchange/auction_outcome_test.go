@@ -0,0 +1,88 @@
+package exchange
+
+import (
+	"testing"
+
+	"github.com/prebid/openrtb/v20/openrtb2"
+
+	"github.com/prebid/prebid-server/v3/exchange/entities"
+	"github.com/prebid/prebid-server/v3/metrics"
+	"github.com/prebid/prebid-server/v3/openrtb_ext"
+)
+
+func TestRecordAuctionOutcome(t *testing.T) {
+	imps := []openrtb2.Imp{{ID: "imp1"}, {ID: "imp2"}, {ID: "imp3"}}
+	appnexusBid := &entities.PbsOrtbBid{Bid: &openrtb2.Bid{ID: "1", ImpID: "imp1", Price: 3}, BidType: openrtb_ext.BidTypeBanner, AdapterCode: "appnexus"}
+	appnexusDeal := &entities.PbsOrtbBid{Bid: &openrtb2.Bid{ID: "3", ImpID: "imp2", Price: 1, DealID: "deal"}, BidType: openrtb_ext.BidTypeVideo, AdapterCode: "appnexus"}
+	adapterBids := map[openrtb_ext.BidderName]*entities.PbsOrtbSeatBid{
+		"groupm": {Bids: []*entities.PbsOrtbBid{
+			appnexusBid,
+			{Bid: &openrtb2.Bid{ID: "2", ImpID: "imp1", Price: 2.5}, BidType: openrtb_ext.BidTypeBanner, AdapterCode: "appnexus"},
+			appnexusDeal,
+		}},
+		"rubicon": {Bids: []*entities.PbsOrtbBid{
+			{Bid: &openrtb2.Bid{ID: "4", ImpID: "imp1", Price: 2}, BidType: openrtb_ext.BidTypeBanner},
+			{Bid: &openrtb2.Bid{ID: "5", ImpID: "imp2", Price: 1.5}, BidType: openrtb_ext.BidTypeVideo},
+			nil,
+		}},
+		"pubmatic": nil,
+	}
+
+	appnexus := metrics.AdapterLabels{Adapter: "appnexus", PubID: "acct"}
+	rubicon := metrics.AdapterLabels{Adapter: "rubicon", PubID: "acct"}
+
+	testCases := []struct {
+		name        string
+		winningBids map[string]*entities.PbsOrtbBid
+		preferDeals bool
+		expectWins  func(me *metrics.MetricsEngineMock)
+	}{
+		{
+			name:        "prefer-deals",
+			preferDeals: true,
+			expectWins: func(me *metrics.MetricsEngineMock) {
+				me.On("RecordAdapterWin", appnexus, openrtb_ext.BidTypeBanner, 3000.0).Once()
+				me.On("RecordAdapterWinPriceGap", appnexus, 1000.0).Once()
+				// the deal won over a higher price, so it has no gap
+				me.On("RecordAdapterWin", appnexus, openrtb_ext.BidTypeVideo, 1000.0).Once()
+			},
+		},
+		{
+			name:        "highest-price",
+			preferDeals: false,
+			expectWins: func(me *metrics.MetricsEngineMock) {
+				me.On("RecordAdapterWin", appnexus, openrtb_ext.BidTypeBanner, 3000.0).Once()
+				me.On("RecordAdapterWinPriceGap", appnexus, 1000.0).Once()
+				me.On("RecordAdapterWin", rubicon, openrtb_ext.BidTypeVideo, 1500.0).Once()
+				me.On("RecordAdapterWinPriceGap", rubicon, 500.0).Once()
+			},
+		},
+		{
+			name:        "auction-winners",
+			winningBids: map[string]*entities.PbsOrtbBid{"imp1": appnexusBid, "imp2": appnexusDeal},
+			preferDeals: false,
+			expectWins: func(me *metrics.MetricsEngineMock) {
+				me.On("RecordAdapterWin", appnexus, openrtb_ext.BidTypeBanner, 3000.0).Once()
+				me.On("RecordAdapterWinPriceGap", appnexus, 1000.0).Once()
+				// the winners of the auction are recorded, rather than picked again
+				me.On("RecordAdapterWin", appnexus, openrtb_ext.BidTypeVideo, 1000.0).Once()
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.name, func(t *testing.T) {
+			me := &metrics.MetricsEngineMock{}
+			me.On("RecordAdapterAuctionBids", appnexus, 3).Once()
+			me.On("RecordAdapterAuctionBids", rubicon, 2).Once()
+			me.On("RecordImpBids", "acct", 3).Once()
+			me.On("RecordImpBids", "acct", 2).Once()
+			me.On("RecordImpBids", "acct", 0).Once()
+			test.expectWins(me)
+
+			recordAuctionOutcome(me, imps, adapterBids, test.winningBids, "acct", test.preferDeals)
+
+			me.AssertExpectations(t)
+		})
+	}
+}
@@ -18,3 +18,11 @@ func (ar *AuctionResponse) GetSeatNonBid() []openrtb_ext.SeatNonBid {
 	}
 	return nil
 }
+
+// GetPrivacyTrace returns the privacy trace per bidder if present. nil otherwise
+func (ar *AuctionResponse) GetPrivacyTrace() map[openrtb_ext.BidderName]*openrtb_ext.ExtBidderPrivacyTrace {
+	if ar != nil && ar.ExtBidResponse != nil && ar.ExtBidResponse.Debug != nil {
+		return ar.ExtBidResponse.Debug.Privacy
+	}
+	return nil
+}
@@ -0,0 +1,212 @@
+package exchange
+
+import (
+	"github.com/prebid/openrtb/v20/openrtb2"
+
+	"github.com/prebid/prebid-server/v3/bidnotifications"
+	"github.com/prebid/prebid-server/v3/config"
+	"github.com/prebid/prebid-server/v3/exchange/entities"
+	"github.com/prebid/prebid-server/v3/openrtb_ext"
+)
+
+// notifiedBid is a bid returned by a bidder with notification URLs, kept to find its outcome once the
+// response is built
+type notifiedBid struct {
+	seat   string
+	bidder openrtb_ext.BidderName
+	bid    *entities.PbsOrtbBid
+}
+
+type returnedBidKey struct {
+	seat  string
+	impID string
+	bidID string
+}
+
+func (e *exchange) bidNotificationsEnabled(account *config.Account) bool {
+	return e.bidNotifier != nil && e.bidNotifier.Enabled(account)
+}
+
+// collectNotifiedBids lists the bids of the enabled bidders with notification URLs, before any of them is rejected
+func collectNotifiedBids(adapterBids map[openrtb_ext.BidderName]*entities.PbsOrtbSeatBid, bidderEnabled func(openrtb_ext.BidderName) bool) []notifiedBid {
+	var notifiedBids []notifiedBid
+	for seat, seatBid := range adapterBids {
+		if seatBid == nil {
+			continue
+		}
+		for _, bid := range seatBid.Bids {
+			if bid == nil || bid.Bid == nil {
+				continue
+			}
+			if bid.Bid.NURL == "" && bid.Bid.BURL == "" && bid.Bid.LURL == "" {
+				continue
+			}
+			bidder := bid.AdapterCode
+			if bidder == "" {
+				bidder = seat
+			}
+			if !bidderEnabled(bidder) {
+				continue
+			}
+			notifiedBids = append(notifiedBids, notifiedBid{seat: seat.String(), bidder: bidder, bid: bid})
+		}
+	}
+	return notifiedBids
+}
+
+// buildNotifiedAuction finds the outcome of the notified bids. The bid with the highest price of each imp wins,
+// and the other bids of the response are outbid. The bids missing from the response were rejected, for the
+// reason of their seat non bid.
+func buildNotifiedAuction(account *config.Account, notifiedBids []notifiedBid, bidResponse *openrtb2.BidResponse, seatNonBids SeatNonBidBuilder, preferDeals bool) bidnotifications.Auction {
+	returned := make(map[returnedBidKey]bool)
+	winners := make(map[string]*openrtb2.Bid)
+	winnerSeats := make(map[string]string)
+	for _, seatBid := range bidResponse.SeatBid {
+		for i := range seatBid.Bid {
+			bid := &seatBid.Bid[i]
+			returned[returnedBidKey{seat: seatBid.Seat, impID: bid.ImpID, bidID: bid.ID}] = true
+			if winner, ok := winners[bid.ImpID]; !ok || isNewWinningBid(bid, winner, preferDeals) {
+				winners[bid.ImpID] = bid
+				winnerSeats[bid.ImpID] = seatBid.Seat
+			}
+		}
+	}
+
+	matchedNonBids := make(map[string][]bool, len(seatNonBids))
+	for seat, nonBids := range seatNonBids {
+		matchedNonBids[seat] = make([]bool, len(nonBids))
+	}
+
+	auction := bidnotifications.Auction{
+		Account:  account,
+		ID:       bidResponse.ID,
+		Currency: bidResponse.Cur,
+		Bids:     make([]bidnotifications.Bid, 0, len(notifiedBids)),
+	}
+	for _, notified := range notifiedBids {
+		bid := notified.bid.Bid
+		outcome := bidnotifications.Bid{
+			Bidder:     notified.bidder,
+			Seat:       notified.seat,
+			ID:         bid.ID,
+			ImpID:      bid.ImpID,
+			AdID:       bid.AdID,
+			EventBidID: bid.ID,
+			Price:      bid.Price,
+			NURL:       bid.NURL,
+			BURL:       bid.BURL,
+			LURL:       bid.LURL,
+		}
+		if notified.bid.GeneratedBidID != "" {
+			outcome.EventBidID = notified.bid.GeneratedBidID
+		}
+		if !firesNURL(bid) {
+			outcome.NURL = ""
+		}
+		// The prices are notified as the bidder bid them. The price to win is scaled by the bid adjustments
+		// and the currency conversion of the bid, so it's what the bidder had to bid to tie.
+		priceScale := 1.0
+		if notified.bid.OriginalBidCur != "" && bid.Price > 0 {
+			outcome.Price = notified.bid.OriginalBidCPM
+			outcome.Currency = notified.bid.OriginalBidCur
+			priceScale = notified.bid.OriginalBidCPM / bid.Price
+		}
+
+		winner := winners[bid.ImpID]
+		isWinner := winner != nil && winner.ID == bid.ID && winnerSeats[bid.ImpID] == notified.seat
+		switch {
+		case isWinner:
+		case returned[returnedBidKey{seat: notified.seat, impID: bid.ImpID, bidID: bid.ID}]:
+			outcome.Lost = true
+			outcome.LossReason = outbidLossReason(bid, winner)
+			outcome.MinToWin = winner.Price * priceScale
+		default:
+			outcome.Lost = true
+			if reason, ok := matchNonBid(seatNonBids, matchedNonBids, notified); ok {
+				outcome.LossReason = reason.lossReason()
+			} else if winner != nil {
+				// Bids dropped without a seat non bid, such as the bids beyond the multibid limit, were outbid
+				outcome.LossReason = outbidLossReason(bid, winner)
+				outcome.MinToWin = winner.Price * priceScale
+			} else {
+				outcome.LossReason = bidnotifications.LossReasonInternalError
+			}
+		}
+		auction.Bids = append(auction.Bids, outcome)
+	}
+	return auction
+}
+
+// removeNotifiedURLs removes the notification URLs fired by Prebid Server from the bids of the response,
+// so the client doesn't fire them again
+func removeNotifiedURLs(bidResponse *openrtb2.BidResponse, notifiedBids []notifiedBid) {
+	notified := make(map[returnedBidKey]*openrtb2.Bid, len(notifiedBids))
+	for _, n := range notifiedBids {
+		notified[returnedBidKey{seat: n.seat, impID: n.bid.Bid.ImpID, bidID: n.bid.Bid.ID}] = n.bid.Bid
+	}
+	for _, seatBid := range bidResponse.SeatBid {
+		for i := range seatBid.Bid {
+			bid := &seatBid.Bid[i]
+			notifiedBid, ok := notified[returnedBidKey{seat: seatBid.Seat, impID: bid.ImpID, bidID: bid.ID}]
+			if !ok {
+				continue
+			}
+			if firesNURL(notifiedBid) {
+				bid.NURL = ""
+			}
+			bid.BURL = ""
+			bid.LURL = ""
+		}
+	}
+}
+
+// firesNURL returns true if Prebid Server fires the win URL of the bid. The bids without markup are left
+// out, as the client fetches their creative from the win URL.
+func firesNURL(bid *openrtb2.Bid) bool {
+	return bid.AdM != ""
+}
+
+func outbidLossReason(bid *openrtb2.Bid, winner *openrtb2.Bid) bidnotifications.LossReason {
+	if winner.DealID != "" && bid.DealID == "" {
+		return bidnotifications.LossReasonLostToDealBid
+	}
+	return bidnotifications.LossReasonLostToHigherBid
+}
+
+// matchNonBid finds the seat non bid of a rejected bid, which doesn't keep the bid ID. Each seat non bid is
+// matched once, so that identical bids of the same seat get one each.
+func matchNonBid(seatNonBids SeatNonBidBuilder, matched map[string][]bool, notified notifiedBid) (NonBidReason, bool) {
+	bid := notified.bid.Bid
+	for i, nonBid := range seatNonBids[notified.seat] {
+		if matched[notified.seat][i] || nonBid.ImpId != bid.ImpID || nonBid.Ext == nil {
+			continue
+		}
+		nonBidObject := nonBid.Ext.Prebid.Bid
+		if nonBidObject.Price != bid.Price || nonBidObject.DealID != bid.DealID {
+			continue
+		}
+		matched[notified.seat][i] = true
+		return NonBidReason(nonBid.StatusCode), true
+	}
+	return 0, false
+}
+
+// lossReason maps the reason a bid was rejected to its OpenRTB loss reason
+func (reason NonBidReason) lossReason() bidnotifications.LossReason {
+	switch reason {
+	case ResponseRejectedBelowFloor:
+		return bidnotifications.LossReasonBelowAuctionFloor
+	case ResponseRejectedBelowDealFloor:
+		return bidnotifications.LossReasonBelowDealFloor
+	case ResponseRejectedCreativeSizeNotAllowed:
+		return bidnotifications.LossReasonCreativeSizeNotAllowed
+	case ResponseRejectedCreativeNotSecure:
+		return bidnotifications.LossReasonCreativeNotSecure
+	case ResponseRejectedCategoryMappingInvalid:
+		return bidnotifications.LossReasonCreativeFiltered
+	case ResponseRejectedGeneral:
+		return bidnotifications.LossReasonInvalidBidResponse
+	default:
+		return bidnotifications.LossReasonInternalError
+	}
+}
@@ -0,0 +1,241 @@
+package exchange
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/prebid/openrtb/v20/openrtb2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/prebid/prebid-server/v3/adapters"
+	"github.com/prebid/prebid-server/v3/analytics"
+	"github.com/prebid/prebid-server/v3/bidnotifications"
+	"github.com/prebid/prebid-server/v3/config"
+	"github.com/prebid/prebid-server/v3/currency"
+	"github.com/prebid/prebid-server/v3/exchange/entities"
+	"github.com/prebid/prebid-server/v3/gdpr"
+	"github.com/prebid/prebid-server/v3/hooks/hookexecution"
+	metricsConf "github.com/prebid/prebid-server/v3/metrics/config"
+	"github.com/prebid/prebid-server/v3/openrtb_ext"
+)
+
+func TestCollectNotifiedBids(t *testing.T) {
+	withURLs := &entities.PbsOrtbBid{Bid: &openrtb2.Bid{ID: "bid1", LURL: "lurl"}, AdapterCode: "appnexus"}
+	withoutURLs := &entities.PbsOrtbBid{Bid: &openrtb2.Bid{ID: "bid2"}}
+	withoutAdapterCode := &entities.PbsOrtbBid{Bid: &openrtb2.Bid{ID: "bid3", NURL: "nurl"}}
+	notEnabled := &entities.PbsOrtbBid{Bid: &openrtb2.Bid{ID: "bid4", BURL: "burl"}}
+
+	adapterBids := map[openrtb_ext.BidderName]*entities.PbsOrtbSeatBid{
+		"groupm":   {Bids: []*entities.PbsOrtbBid{withURLs, withoutURLs}},
+		"rubicon":  {Bids: []*entities.PbsOrtbBid{withoutAdapterCode, nil}},
+		"openx":    {Bids: []*entities.PbsOrtbBid{notEnabled}},
+		"pubmatic": nil,
+	}
+	bidderEnabled := func(bidder openrtb_ext.BidderName) bool {
+		return bidder != "openx"
+	}
+
+	assert.ElementsMatch(t, []notifiedBid{
+		{seat: "groupm", bidder: "appnexus", bid: withURLs},
+		{seat: "rubicon", bidder: "rubicon", bid: withoutAdapterCode},
+	}, collectNotifiedBids(adapterBids, bidderEnabled))
+}
+
+func TestBuildNotifiedAuction(t *testing.T) {
+	account := &config.Account{ID: "account"}
+
+	winner := &entities.PbsOrtbBid{Bid: &openrtb2.Bid{ID: "winner", ImpID: "imp1", Price: 3, AdM: "adm", NURL: "nurl"}, GeneratedBidID: "generated", OriginalBidCPM: 2.5, OriginalBidCur: "USD"}
+	// the bidder bid 4 EUR, converted to 2 USD
+	outbid := &entities.PbsOrtbBid{Bid: &openrtb2.Bid{ID: "outbid", ImpID: "imp1", Price: 2, LURL: "lurl"}, OriginalBidCPM: 4, OriginalBidCur: "EUR"}
+	belowFloor := &entities.PbsOrtbBid{Bid: &openrtb2.Bid{ID: "floor", ImpID: "imp1", Price: 0.5, LURL: "lurl"}}
+	snipped := &entities.PbsOrtbBid{Bid: &openrtb2.Bid{ID: "snipped", ImpID: "imp1", Price: 1, LURL: "lurl"}}
+	notSecure := &entities.PbsOrtbBid{Bid: &openrtb2.Bid{ID: "insecure", ImpID: "imp2", Price: 1, DealID: "deal", LURL: "lurl"}}
+	dealWinner := &entities.PbsOrtbBid{Bid: &openrtb2.Bid{ID: "deal", ImpID: "imp3", Price: 1, DealID: "deal", NURL: "nurl", BURL: "burl"}}
+	outbidByDeal := &entities.PbsOrtbBid{Bid: &openrtb2.Bid{ID: "nodeal", ImpID: "imp3", Price: 2, LURL: "lurl"}}
+
+	notifiedBids := []notifiedBid{
+		{seat: "appnexus", bidder: "appnexus", bid: winner},
+		{seat: "rubicon", bidder: "rubicon", bid: outbid},
+		{seat: "rubicon", bidder: "rubicon", bid: belowFloor},
+		{seat: "rubicon", bidder: "rubicon", bid: snipped},
+		{seat: "rubicon", bidder: "rubicon", bid: notSecure},
+		{seat: "rubicon", bidder: "rubicon", bid: outbidByDeal},
+		{seat: "appnexus", bidder: "appnexus", bid: dealWinner},
+	}
+	bidResponse := &openrtb2.BidResponse{
+		ID:  "auction",
+		Cur: "USD",
+		SeatBid: []openrtb2.SeatBid{
+			{Seat: "appnexus", Bid: []openrtb2.Bid{*winner.Bid, *dealWinner.Bid}},
+			{Seat: "rubicon", Bid: []openrtb2.Bid{*outbid.Bid, *outbidByDeal.Bid}},
+		},
+	}
+	seatNonBids := SeatNonBidBuilder{}
+	seatNonBids.rejectBid(belowFloor, int(ResponseRejectedBelowFloor), "rubicon")
+	seatNonBids.rejectBid(notSecure, int(ResponseRejectedCreativeNotSecure), "rubicon")
+
+	auction := buildNotifiedAuction(account, notifiedBids, bidResponse, seatNonBids, true)
+
+	assert.Equal(t, account, auction.Account)
+	assert.Equal(t, "auction", auction.ID)
+	assert.Equal(t, "USD", auction.Currency)
+	assert.Equal(t, []bidnotifications.Bid{
+		{Bidder: "appnexus", Seat: "appnexus", ID: "winner", ImpID: "imp1", EventBidID: "generated", Price: 2.5, Currency: "USD", NURL: "nurl"},
+		{Bidder: "rubicon", Seat: "rubicon", ID: "outbid", ImpID: "imp1", EventBidID: "outbid", Price: 4, Currency: "EUR", LURL: "lurl", Lost: true, LossReason: bidnotifications.LossReasonLostToHigherBid, MinToWin: 6},
+		{Bidder: "rubicon", Seat: "rubicon", ID: "floor", ImpID: "imp1", EventBidID: "floor", Price: 0.5, LURL: "lurl", Lost: true, LossReason: bidnotifications.LossReasonBelowAuctionFloor},
+		{Bidder: "rubicon", Seat: "rubicon", ID: "snipped", ImpID: "imp1", EventBidID: "snipped", Price: 1, LURL: "lurl", Lost: true, LossReason: bidnotifications.LossReasonLostToHigherBid, MinToWin: 3},
+		{Bidder: "rubicon", Seat: "rubicon", ID: "insecure", ImpID: "imp2", EventBidID: "insecure", Price: 1, LURL: "lurl", Lost: true, LossReason: bidnotifications.LossReasonCreativeNotSecure},
+		{Bidder: "rubicon", Seat: "rubicon", ID: "nodeal", ImpID: "imp3", EventBidID: "nodeal", Price: 2, LURL: "lurl", Lost: true, LossReason: bidnotifications.LossReasonLostToDealBid, MinToWin: 1},
+		{Bidder: "appnexus", Seat: "appnexus", ID: "deal", ImpID: "imp3", EventBidID: "deal", Price: 1, BURL: "burl"},
+	}, auction.Bids, "the nurl of the bids without markup should be left to the client")
+}
+
+func TestNonBidReasonLossReason(t *testing.T) {
+	testCases := []struct {
+		reason   NonBidReason
+		expected bidnotifications.LossReason
+	}{
+		{reason: ResponseRejectedBelowFloor, expected: bidnotifications.LossReasonBelowAuctionFloor},
+		{reason: ResponseRejectedBelowDealFloor, expected: bidnotifications.LossReasonBelowDealFloor},
+		{reason: ResponseRejectedCreativeSizeNotAllowed, expected: bidnotifications.LossReasonCreativeSizeNotAllowed},
+		{reason: ResponseRejectedCreativeNotSecure, expected: bidnotifications.LossReasonCreativeNotSecure},
+		{reason: ResponseRejectedCategoryMappingInvalid, expected: bidnotifications.LossReasonCreativeFiltered},
+		{reason: ResponseRejectedGeneral, expected: bidnotifications.LossReasonInvalidBidResponse},
+		{reason: ErrorTimeout, expected: bidnotifications.LossReasonInternalError},
+	}
+
+	for _, test := range testCases {
+		assert.Equal(t, test.expected, test.reason.lossReason(), "reason %d", test.reason)
+	}
+}
+
+func TestHoldAuctionRemovesNotifiedURLs(t *testing.T) {
+	withMarkup := openrtb2.Bid{ID: "with-markup", ImpID: "imp", Price: 2, AdM: "adm", NURL: "nurl", BURL: "burl", LURL: "lurl"}
+	withoutMarkup := openrtb2.Bid{ID: "without-markup", ImpID: "imp", Price: 1, NURL: "nurl", BURL: "burl", LURL: "lurl"}
+
+	testCases := []struct {
+		name             string
+		account          config.Account
+		notifierBidders  []openrtb_ext.BidderName
+		expectedURLs     map[string][3]string
+		expectedNotified bool
+	}{
+		{
+			name:            "opted_in",
+			account:         config.Account{ID: "account", BidNotifications: config.AccountBidNotifications{Enabled: true}},
+			notifierBidders: []openrtb_ext.BidderName{openrtb_ext.BidderAppnexus},
+			expectedURLs: map[string][3]string{
+				"with-markup":    {"", "", ""},
+				"without-markup": {"nurl", "", ""},
+			},
+			expectedNotified: true,
+		},
+		{
+			name:            "account_not_opted_in",
+			account:         config.Account{ID: "account"},
+			notifierBidders: []openrtb_ext.BidderName{openrtb_ext.BidderAppnexus},
+			expectedURLs: map[string][3]string{
+				"with-markup":    {"nurl", "burl", "lurl"},
+				"without-markup": {"nurl", "burl", "lurl"},
+			},
+		},
+		{
+			name:    "bidder_not_opted_in",
+			account: config.Account{ID: "account", BidNotifications: config.AccountBidNotifications{Enabled: true}},
+			expectedURLs: map[string][3]string{
+				"with-markup":    {"nurl", "burl", "lurl"},
+				"without-markup": {"nurl", "burl", "lurl"},
+			},
+		},
+	}
+
+	noBidServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(204) }))
+	defer noBidServer.Close()
+
+	for _, test := range testCases {
+		t.Run(test.name, func(t *testing.T) {
+			bidderImpl := &goodSingleBidder{
+				httpRequest: &adapters.RequestData{Method: "POST", Uri: noBidServer.URL, Body: []byte(`{}`), Headers: http.Header{}},
+				bidResponse: &adapters.BidderResponse{
+					Bids: []*adapters.TypedBid{
+						{Bid: ptrToBid(withMarkup), BidType: openrtb_ext.BidTypeBanner},
+						{Bid: ptrToBid(withoutMarkup), BidType: openrtb_ext.BidTypeBanner},
+					},
+				},
+			}
+			notifier := &mockBidNotifier{bidders: test.notifierBidders}
+
+			e := new(exchange)
+			e.adapterMap = map[openrtb_ext.BidderName]AdaptedBidder{
+				openrtb_ext.BidderAppnexus: AdaptBidder(bidderImpl, noBidServer.Client(), &config.Configuration{}, &metricsConf.NilMetricsEngine{}, openrtb_ext.BidderAppnexus, nil, ""),
+			}
+			e.cache = &wellBehavedCache{}
+			e.me = &metricsConf.NilMetricsEngine{}
+			e.gdprPermsBuilder = fakePermissionsBuilder{permissions: &permissionsMock{allowAllBidders: true}}.Builder
+			e.currencyConverter = currency.NewRateConverter(&http.Client{}, "", time.Duration(0))
+			e.categoriesFetcher = nilCategoryFetcher{}
+			e.bidIDGenerator = &fakeBidIDGenerator{}
+			e.bidNotifier = notifier
+			e.requestSplitter = requestSplitter{me: e.me, gdprPermsBuilder: e.gdprPermsBuilder}
+
+			auctionRequest := &AuctionRequest{
+				BidRequestWrapper: &openrtb_ext.RequestWrapper{BidRequest: &openrtb2.BidRequest{
+					ID: "request",
+					Imp: []openrtb2.Imp{{
+						ID:     "imp",
+						Banner: &openrtb2.Banner{Format: []openrtb2.Format{{W: 300, H: 250}}},
+						Ext:    json.RawMessage(`{"prebid":{"bidder":{"appnexus":{"placementId":1}}}}`),
+					}},
+					Site: &openrtb2.Site{Page: "prebid.org"},
+				}},
+				Account:      test.account,
+				UserSyncs:    &emptyUsersync{},
+				HookExecutor: &hookexecution.EmptyHookExecutor{},
+				TCF2Config:   gdpr.NewTCF2Config(config.TCF2{}, config.AccountGDPR{}),
+			}
+
+			response, err := e.HoldAuction(context.Background(), auctionRequest, &DebugLog{})
+			require.NoError(t, err)
+			require.Len(t, response.SeatBid, 1)
+
+			urls := make(map[string][3]string)
+			for _, bid := range response.SeatBid[0].Bid {
+				urls[bid.ID] = [3]string{bid.NURL, bid.BURL, bid.LURL}
+			}
+			assert.Equal(t, test.expectedURLs, urls)
+			assert.Equal(t, test.expectedNotified, len(notifier.auctions) > 0)
+		})
+	}
+}
+
+func ptrToBid(bid openrtb2.Bid) *openrtb2.Bid {
+	return &bid
+}
+
+// mockBidNotifier records the notified auctions, the notifications being enabled for its bidders
+type mockBidNotifier struct {
+	bidders  []openrtb_ext.BidderName
+	auctions []bidnotifications.Auction
+}
+
+func (n *mockBidNotifier) Enabled(account *config.Account) bool {
+	return account.BidNotifications.Enabled
+}
+
+func (n *mockBidNotifier) BidderEnabled(account *config.Account, bidder openrtb_ext.BidderName) bool {
+	return n.Enabled(account) && slices.Contains(n.bidders, bidder)
+}
+
+func (n *mockBidNotifier) NotifyAuction(auction bidnotifications.Auction) {
+	n.auctions = append(n.auctions, auction)
+}
+
+func (n *mockBidNotifier) NotifyEvent(event *analytics.EventRequest) {}
+
+func (n *mockBidNotifier) Shutdown() {}
@@ -23,7 +23,10 @@ import (
 	"github.com/prebid/prebid-server/v3/exchange/entities"
 	"github.com/prebid/prebid-server/v3/experiment/adscert"
 	"github.com/prebid/prebid-server/v3/hooks/hookexecution"
+	"github.com/prebid/prebid-server/v3/tracing"
 	"github.com/prebid/prebid-server/v3/version"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 
 	"github.com/prebid/openrtb/v20/adcom1"
 	nativeRequests "github.com/prebid/openrtb/v20/native1/request"
@@ -108,6 +111,7 @@ func AdaptBidder(bidder adapters.Bidder, client *http.Client, cfg *config.Config
 			DisableConnMetrics:  cfg.Metrics.Disabled.AdapterConnectionMetrics,
 			DebugInfo:           config.DebugInfo{Allow: parseDebugInfo(debugInfo)},
 			EndpointCompression: endpointCompression,
+			PropagateTrace:      cfg.Tracing.Enabled && cfg.Tracing.PropagateToBidders,
 		},
 	}
 }
@@ -132,9 +136,14 @@ type bidderAdapterConfig struct {
 	DisableConnMetrics  bool
 	DebugInfo           config.DebugInfo
 	EndpointCompression string
+	// PropagateTrace sends the traceparent header of the bidder calls
+	PropagateTrace bool
 }
 
 func (bidder *BidderAdapter) requestBid(ctx context.Context, bidderRequest BidderRequest, conversions currency.Conversions, reqInfo *adapters.ExtraRequestInfo, adsCertSigner adscert.Signer, bidRequestOptions bidRequestOptions, alternateBidderCodes openrtb_ext.ExtAlternateBidderCodes, hookExecutor hookexecution.StageExecutor, ruleToAdjustments openrtb_ext.AdjustmentsByDealID) ([]*entities.PbsOrtbSeatBid, extraBidderRespInfo, []error) {
+	ctx, span := tracing.Start(ctx, "exchange.request_bid", trace.WithAttributes(attribute.String("pbs.bidder", bidderRequest.BidderName.String())))
+	defer span.End()
+
 	request := openrtb_ext.RequestWrapper{BidRequest: bidderRequest.BidRequest}
 	reject := hookExecutor.ExecuteBidderRequestStage(&request, string(bidderRequest.BidderName))
 	seatNonBidBuilder := SeatNonBidBuilder{}
@@ -530,7 +539,21 @@ func (bidder *BidderAdapter) doRequest(ctx context.Context, req *adapters.Reques
 	return bidder.doRequestImpl(ctx, req, glog.Warningf, bidderRequestStartTime, tmaxAdjustments)
 }
 
-func (bidder *BidderAdapter) doRequestImpl(ctx context.Context, req *adapters.RequestData, logger util.LogMsg, bidderRequestStartTime time.Time, tmaxAdjustments *TmaxAdjustmentsPreprocessed) *httpCallInfo {
+func (bidder *BidderAdapter) doRequestImpl(ctx context.Context, req *adapters.RequestData, logger util.LogMsg, bidderRequestStartTime time.Time, tmaxAdjustments *TmaxAdjustmentsPreprocessed) (callInfo *httpCallInfo) {
+	ctx, span := tracing.Start(ctx, "exchange.bidder_call",
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("pbs.bidder", bidder.BidderName.String()),
+			attribute.String("http.request.method", req.Method),
+		),
+	)
+	defer func() {
+		if callInfo.response != nil {
+			span.SetAttributes(attribute.Int("http.response.status_code", callInfo.response.StatusCode))
+		}
+		tracing.End(span, callInfo.err)
+	}()
+
 	requestBody, err := getRequestBody(req, bidder.config.EndpointCompression)
 	if err != nil {
 		return &httpCallInfo{
@@ -546,10 +569,19 @@ func (bidder *BidderAdapter) doRequestImpl(ctx context.Context, req *adapters.Re
 		}
 	}
 	httpReq.Header = req.Headers
+	span.SetAttributes(attribute.String("server.address", httpReq.URL.Host))
+	if bidder.config.PropagateTrace {
+		// the adapter's headers are left as they are, for the debug info of the call
+		httpReq.Header = req.Headers.Clone()
+		if httpReq.Header == nil {
+			httpReq.Header = http.Header{}
+		}
+		tracing.Inject(ctx, httpReq.Header)
+	}
 
-	// If adapter connection metrics are not disabled, add the client trace
-	// to get complete connection info into our metrics
-	if !bidder.config.DisableConnMetrics {
+	// If adapter connection metrics are not disabled, or the call is traced, add the client trace
+	// to get complete connection info into our metrics and span
+	if !bidder.config.DisableConnMetrics || span.IsRecording() {
 		ctx = bidder.addClientTrace(ctx)
 	}
 	bidder.me.RecordOverheadTime(metrics.PreBidder, time.Since(bidderRequestStartTime))
@@ -666,11 +698,14 @@ type httpCallInfo struct {
 
 // This function adds an httptrace.ClientTrace object to the context so, if connection with the bidder
 // endpoint is established, we can keep track of whether the connection was newly created, reused, and
-// the time from the connection request, to the connection creation.
+// the time from the connection request, to the connection creation. The timings are recorded in the
+// metrics, unless the adapter connection metrics are disabled, and as events of the span of ctx.
 func (bidder *BidderAdapter) addClientTrace(ctx context.Context) context.Context {
 	var connStart, dnsStart, tlsStart time.Time
+	span := trace.SpanFromContext(ctx)
+	recordMetrics := !bidder.config.DisableConnMetrics
 
-	trace := &httptrace.ClientTrace{
+	clientTrace := &httptrace.ClientTrace{
 		// GetConn is called before a connection is created or retrieved from an idle pool
 		GetConn: func(hostPort string) {
 			connStart = time.Now()
@@ -679,7 +714,13 @@ func (bidder *BidderAdapter) addClientTrace(ctx context.Context) context.Context
 		GotConn: func(info httptrace.GotConnInfo) {
 			connWaitTime := time.Since(connStart)
 
-			bidder.me.RecordAdapterConnections(bidder.BidderName, info.Reused, connWaitTime)
+			if recordMetrics {
+				bidder.me.RecordAdapterConnections(bidder.BidderName, info.Reused, connWaitTime)
+			}
+			span.AddEvent("connection", trace.WithAttributes(
+				attribute.Bool("reused", info.Reused),
+				attribute.Float64("wait_ms", durationMillis(connWaitTime)),
+			))
 		},
 		// DNSStart is called when a DNS lookup begins.
 		DNSStart: func(info httptrace.DNSStartInfo) {
@@ -689,20 +730,34 @@ func (bidder *BidderAdapter) addClientTrace(ctx context.Context) context.Context
 		DNSDone: func(info httptrace.DNSDoneInfo) {
 			dnsLookupTime := time.Since(dnsStart)
 
-			bidder.me.RecordDNSTime(dnsLookupTime)
+			if recordMetrics {
+				bidder.me.RecordDNSTime(dnsLookupTime)
+			}
+			span.AddEvent("dns", trace.WithAttributes(attribute.Float64("duration_ms", durationMillis(dnsLookupTime))))
 		},
 
 		TLSHandshakeStart: func() {
 			tlsStart = time.Now()
 		},
 
-		TLSHandshakeDone: func(tls.ConnectionState, error) {
+		TLSHandshakeDone: func(_ tls.ConnectionState, err error) {
 			tlsHandshakeTime := time.Since(tlsStart)
 
-			bidder.me.RecordTLSHandshakeTime(tlsHandshakeTime)
+			if recordMetrics {
+				bidder.me.RecordTLSHandshakeTime(tlsHandshakeTime)
+			}
+			attributes := []attribute.KeyValue{attribute.Float64("duration_ms", durationMillis(tlsHandshakeTime))}
+			if err != nil {
+				attributes = append(attributes, attribute.String("error", err.Error()))
+			}
+			span.AddEvent("tls_handshake", trace.WithAttributes(attributes...))
 		},
 	}
-	return httptrace.WithClientTrace(ctx, trace)
+	return httptrace.WithClientTrace(ctx, clientTrace)
+}
+
+func durationMillis(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
 }
 
 func prepareStoredResponse(impId string, bidResp json.RawMessage) *httpCallInfo {
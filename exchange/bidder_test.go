@@ -36,11 +36,14 @@ import (
 	"github.com/prebid/prebid-server/v3/metrics"
 	metricsConfig "github.com/prebid/prebid-server/v3/metrics/config"
 	"github.com/prebid/prebid-server/v3/openrtb_ext"
+	"github.com/prebid/prebid-server/v3/tracing"
+	"github.com/prebid/prebid-server/v3/tracing/tracingtest"
 	"github.com/prebid/prebid-server/v3/util/jsonutil"
 	"github.com/prebid/prebid-server/v3/util/ptrutil"
 	"github.com/prebid/prebid-server/v3/version"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 // TestSingleBidder makes sure that the following things work if the Bidder needs only one request.
@@ -3566,3 +3569,77 @@ func BenchmarkCompressToGZIPOptimized(b *testing.B) {
 		getRequestBody(req, "GZIP")
 	}
 }
+
+func TestDoRequestImplTracing(t *testing.T) {
+	testCases := []struct {
+		name           string
+		propagateTrace bool
+	}{
+		{
+			name:           "propagated",
+			propagateTrace: true,
+		},
+		{
+			name:           "not-propagated",
+			propagateTrace: false,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.name, func(t *testing.T) {
+			exporter := tracingtest.Setup(t)
+
+			var receivedTraceparent string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				receivedTraceparent = r.Header.Get("traceparent")
+				w.WriteHeader(http.StatusNoContent)
+			}))
+			defer server.Close()
+
+			bidderAdapter := BidderAdapter{
+				BidderName: openrtb_ext.BidderAppnexus,
+				Client:     server.Client(),
+				me:         &metricsConfig.NilMetricsEngine{},
+				config: bidderAdapterConfig{
+					DisableConnMetrics: true,
+					PropagateTrace:     test.propagateTrace,
+				},
+			}
+			bidRequest := adapters.RequestData{
+				Method:  http.MethodPost,
+				Uri:     server.URL,
+				Body:    []byte(`{"id":"this-id"}`),
+				Headers: http.Header{"Content-Type": []string{"application/json"}},
+			}
+			logger := func(msg string, args ...interface{}) {}
+
+			ctx, parent := tracing.Start(context.Background(), "exchange.request_bid")
+			httpCallInfo := bidderAdapter.doRequestImpl(ctx, &bidRequest, logger, time.Now(), nil)
+			parent.End()
+
+			assert.NoError(t, httpCallInfo.err)
+			assert.Equal(t, http.Header{"Content-Type": []string{"application/json"}}, bidRequest.Headers, "the adapter's headers must be left as they are")
+
+			spans := exporter.GetSpans()
+			if !assert.Equal(t, []string{"exchange.bidder_call", "exchange.request_bid"}, tracingtest.SpanNames(spans)) {
+				return
+			}
+			callSpan := spans[0]
+			assert.Equal(t, parent.SpanContext().SpanID(), callSpan.Parent.SpanID())
+			assert.Contains(t, callSpan.Attributes, attribute.String("pbs.bidder", "appnexus"))
+			assert.Contains(t, callSpan.Attributes, attribute.Int("http.response.status_code", http.StatusNoContent))
+
+			eventNames := make([]string, 0, len(callSpan.Events))
+			for _, event := range callSpan.Events {
+				eventNames = append(eventNames, event.Name)
+			}
+			assert.Contains(t, eventNames, "connection", "the client trace must be added to the traced calls even without the connection metrics")
+
+			if test.propagateTrace {
+				assert.Equal(t, fmt.Sprintf("00-%s-%s-01", callSpan.SpanContext.TraceID(), callSpan.SpanContext.SpanID()), receivedTraceparent)
+			} else {
+				assert.Empty(t, receivedTraceparent)
+			}
+		})
+	}
+}
@@ -20,6 +20,7 @@ import (
 	"github.com/prebid/prebid-server/v3/adapters"
 	"github.com/prebid/prebid-server/v3/adservertargeting"
 	"github.com/prebid/prebid-server/v3/bidadjustment"
+	"github.com/prebid/prebid-server/v3/bidnotifications"
 	"github.com/prebid/prebid-server/v3/config"
 	"github.com/prebid/prebid-server/v3/currency"
 	"github.com/prebid/prebid-server/v3/dsa"
@@ -36,6 +37,7 @@ import (
 	"github.com/prebid/prebid-server/v3/prebid_cache_client"
 	"github.com/prebid/prebid-server/v3/stored_requests"
 	"github.com/prebid/prebid-server/v3/stored_responses"
+	"github.com/prebid/prebid-server/v3/tracing"
 	"github.com/prebid/prebid-server/v3/usersync"
 	"github.com/prebid/prebid-server/v3/util/jsonutil"
 	"github.com/prebid/prebid-server/v3/util/maputil"
@@ -86,6 +88,7 @@ type exchange struct {
 	priceFloorEnabled        bool
 	priceFloorFetcher        floors.FloorFetcher
 	singleFormatBidders      map[openrtb_ext.BidderName]struct{}
+	bidNotifier              bidnotifications.Notifier
 }
 
 // Container to pass out response ext data from the GetAllBids goroutines back into the main thread
@@ -137,7 +140,7 @@ func (randomDeduplicateBidBooleanGenerator) Generate() bool {
 	return rand.Intn(100) < 50
 }
 
-func NewExchange(adapters map[openrtb_ext.BidderName]AdaptedBidder, cache prebid_cache_client.Client, cfg *config.Configuration, requestValidator ortb.RequestValidator, syncersByBidder map[string]usersync.Syncer, metricsEngine metrics.MetricsEngine, infos config.BidderInfos, gdprPermsBuilder gdpr.PermissionsBuilder, currencyConverter *currency.RateConverter, categoriesFetcher stored_requests.CategoryFetcher, adsCertSigner adscert.Signer, macroReplacer macros.Replacer, priceFloorFetcher floors.FloorFetcher, singleFormatBidders map[openrtb_ext.BidderName]struct{}) Exchange {
+func NewExchange(adapters map[openrtb_ext.BidderName]AdaptedBidder, cache prebid_cache_client.Client, cfg *config.Configuration, requestValidator ortb.RequestValidator, syncersByBidder map[string]usersync.Syncer, metricsEngine metrics.MetricsEngine, infos config.BidderInfos, gdprPermsBuilder gdpr.PermissionsBuilder, currencyConverter *currency.RateConverter, categoriesFetcher stored_requests.CategoryFetcher, adsCertSigner adscert.Signer, macroReplacer macros.Replacer, priceFloorFetcher floors.FloorFetcher, singleFormatBidders map[openrtb_ext.BidderName]struct{}, bidNotifier bidnotifications.Notifier) Exchange {
 	bidderToSyncerKey := map[string]string{}
 	for bidder, syncer := range syncersByBidder {
 		bidderToSyncerKey[bidder] = syncer.Key()
@@ -155,6 +158,7 @@ func NewExchange(adapters map[openrtb_ext.BidderName]AdaptedBidder, cache prebid
 	}
 	requestSplitter := requestSplitter{
 		bidderToSyncerKey: bidderToSyncerKey,
+		syncerEIDs:        buildSyncerEIDs(syncersByBidder, infos),
 		me:                metricsEngine,
 		privacyConfig:     privacyConfig,
 		gdprPermsBuilder:  gdprPermsBuilder,
@@ -186,6 +190,7 @@ func NewExchange(adapters map[openrtb_ext.BidderName]AdaptedBidder, cache prebid
 		priceFloorEnabled:        cfg.PriceFloors.Enabled,
 		priceFloorFetcher:        priceFloorFetcher,
 		singleFormatBidders:      singleFormatBidders,
+		bidNotifier:              bidNotifier,
 	}
 }
 
@@ -224,6 +229,10 @@ type AuctionRequest struct {
 	QueryParams             url.Values
 	BidderResponseStartTime time.Time
 	TmaxAdjustments         *TmaxAdjustmentsPreprocessed
+	// PrivacyTrace collects the privacy enforcement applied to each bidder request when not nil
+	PrivacyTrace map[openrtb_ext.BidderName]*openrtb_ext.ExtBidderPrivacyTrace
+	// StoredVersions are the versions of the versioned stored data resolved for the request
+	StoredVersions *openrtb_ext.ExtStoredVersions
 }
 
 // BidderRequest holds the bidder specific request and all other
@@ -275,7 +284,9 @@ func (e *exchange) HoldAuction(ctx context.Context, r *AuctionRequest, debugLog
 	}
 
 	// Get currency rates conversions for the auction
+	_, currencySpan := tracing.Start(ctx, "currency.auction_rates")
 	conversions := currency.GetAuctionCurrencyRates(e.currencyConverter, requestExtPrebid.CurrencyConversions)
+	currencySpan.End()
 
 	var floorErrs []error
 	if e.priceFloorEnabled {
@@ -295,6 +306,9 @@ func (e *exchange) HoldAuction(ctx context.Context, r *AuctionRequest, debugLog
 		}
 		r.ResolvedBidRequest = resolvedBidReq
 	}
+	if responseDebugAllow {
+		r.PrivacyTrace = make(map[openrtb_ext.BidderName]*openrtb_ext.ExtBidderPrivacyTrace)
+	}
 	e.me.RecordDebugRequest(responseDebugAllow || accountDebugAllow, r.PubID)
 
 	if r.RequestType == metrics.ReqTypeORTB2Web ||
@@ -383,6 +397,8 @@ func (e *exchange) HoldAuction(ctx context.Context, r *AuctionRequest, debugLog
 		// List of bidders we have requests for.
 		liveAdapters      []openrtb_ext.BidderName
 		seatNonBidBuilder SeatNonBidBuilder = SeatNonBidBuilder{}
+		// Bids whose notification URLs are fired once the response is built
+		notifiedBids []notifiedBid
 	)
 
 	if len(r.StoredAuctionResponses) > 0 {
@@ -414,7 +430,13 @@ func (e *exchange) HoldAuction(ctx context.Context, r *AuctionRequest, debugLog
 		if extraRespInfo.seatNonBidBuilder != nil {
 			seatNonBidBuilder = extraRespInfo.seatNonBidBuilder
 		}
+		if e.bidNotificationsEnabled(&r.Account) {
+			notifiedBids = collectNotifiedBids(adapterBids, func(bidder openrtb_ext.BidderName) bool {
+				return e.bidNotifier.BidderEnabled(&r.Account, bidder)
+			})
+		}
 	}
+	seatNonBidBuilder.appendSeatNonBids(r.HookExecutor.GetSeatNonBid())
 
 	var (
 		auc            *auction
@@ -539,8 +561,19 @@ func (e *exchange) HoldAuction(ctx context.Context, r *AuctionRequest, debugLog
 
 	e.bidValidationEnforcement.SetBannerCreativeMaxSize(r.Account.Validations)
 
+	preferDeals := targData != nil && targData.preferDeals
+	var winningBids map[string]*entities.PbsOrtbBid
+	if auc != nil {
+		winningBids = auc.winningBids
+	}
+	recordAuctionOutcome(e.me, r.BidRequestWrapper.Imp, adapterBids, winningBids, r.PubID, preferDeals)
+
 	// Build the response
 	bidResponse := e.buildBidResponse(ctx, liveAdapters, adapterBids, r.BidRequestWrapper, adapterExtra, auc, bidResponseExt, cacheInstructions.returnCreative, r.ImpExtInfoMap, r.PubID, errs, &seatNonBidBuilder)
+	if len(notifiedBids) > 0 {
+		e.bidNotifier.NotifyAuction(buildNotifiedAuction(&r.Account, notifiedBids, bidResponse, seatNonBidBuilder, preferDeals))
+		removeNotifiedURLs(bidResponse, notifiedBids)
+	}
 	bidResponse = adservertargeting.Apply(r.BidRequestWrapper, r.ResolvedBidRequest, bidResponse, r.QueryParams, bidResponseExt, r.Account.TruncateTargetAttribute)
 
 	bidResponse.Ext, err = encodeBidResponseExt(bidResponseExt)
@@ -1270,6 +1303,7 @@ func (e *exchange) makeExtBidResponse(adapterBids map[openrtb_ext.BidderName]*en
 		bidResponseExt.Debug = &openrtb_ext.ExtResponseDebug{
 			HttpCalls:       make(map[openrtb_ext.BidderName][]*openrtb_ext.ExtHttpCall),
 			ResolvedRequest: r.ResolvedBidRequest,
+			Privacy:         r.PrivacyTrace,
 		}
 	}
 
@@ -1278,13 +1312,20 @@ func (e *exchange) makeExtBidResponse(adapterBids map[openrtb_ext.BidderName]*en
 		auctionTimestamp = r.StartTime.UnixMilli()
 	}
 
+	var storedVersions *openrtb_ext.ExtStoredVersions
+	if debugInfo {
+		storedVersions = r.StoredVersions
+	}
+
 	if auctionTimestamp > 0 ||
 		passthrough != nil ||
-		fledge != nil {
+		fledge != nil ||
+		storedVersions != nil {
 		bidResponseExt.Prebid = &openrtb_ext.ExtResponsePrebid{
 			AuctionTimestamp: auctionTimestamp,
 			Passthrough:      passthrough,
 			Fledge:           fledge,
+			StoredVersions:   storedVersions,
 		}
 	}
 
@@ -0,0 +1,241 @@
+package exchange
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strconv"
+
+	"github.com/prebid/openrtb/v20/openrtb2"
+
+	"github.com/prebid/prebid-server/v3/firstpartydata"
+	"github.com/prebid/prebid-server/v3/gdpr"
+	metricsConfig "github.com/prebid/prebid-server/v3/metrics/config"
+	"github.com/prebid/prebid-server/v3/openrtb_ext"
+	"github.com/prebid/prebid-server/v3/privacy"
+	"github.com/prebid/prebid-server/v3/usersync"
+	"github.com/prebid/prebid-server/v3/util/jsonutil"
+)
+
+// PrivacyExplainer reports the privacy decisions an auction applies to the bidders of a request,
+// running the enforcement code of the auction without sending any bid request.
+type PrivacyExplainer interface {
+	ExplainPrivacy(ctx context.Context, r *AuctionRequest, bidders []string) (*PrivacyExplanation, error)
+}
+
+// PrivacyExplanation holds the request level privacy policies and the decisions per bidder
+type PrivacyExplanation struct {
+	GDPRApplies  bool                                `json:"gdprApplies"`
+	CCPAProvided bool                                `json:"ccpaProvided"`
+	CCPAEnforced bool                                `json:"ccpaEnforced"`
+	COPPA        bool                                `json:"coppa"`
+	LMT          bool                                `json:"lmt"`
+	Bidders      map[string]BidderPrivacyExplanation `json:"bidders"`
+	Errors       []string                            `json:"errors,omitempty"`
+}
+
+// BidderPrivacyExplanation holds the privacy decisions for a bidder
+type BidderPrivacyExplanation struct {
+	CoreBidder string `json:"coreBidder"`
+	Blocked    bool   `json:"blocked"`
+	// BlockedBy identifies the check which blocked the bid request, either the fetchBids activity or gdpr
+	BlockedBy    string                              `json:"blockedBy,omitempty"`
+	GDPR         *gdpr.AuctionPermissionsExplanation `json:"gdpr,omitempty"`
+	Activities   map[string]privacy.ActivityDecision `json:"activities"`
+	CCPAEnforced bool                                `json:"ccpaEnforced"`
+	Scrubbed     []string                            `json:"scrubbed,omitempty"`
+	Error        string                              `json:"error,omitempty"`
+}
+
+const (
+	blockedByActivity = "activity:fetchBids"
+	blockedByGDPR     = "gdpr"
+)
+
+// explainedActivities are the activities the auction evaluates for each bidder
+var explainedActivities = []privacy.Activity{
+	privacy.ActivityFetchBids,
+	privacy.ActivityTransmitUserFPD,
+	privacy.ActivityTransmitPreciseGeo,
+	privacy.ActivityTransmitEIDs,
+	privacy.ActivityTransmitTIDs,
+}
+
+// ExplainPrivacy explains the privacy decisions for the bidders, or for the bidders of the imps when none are given
+func (e *exchange) ExplainPrivacy(ctx context.Context, r *AuctionRequest, bidders []string) (*PrivacyExplanation, error) {
+	if r.BidRequestWrapper == nil || r.BidRequestWrapper.BidRequest == nil {
+		return nil, errors.New("request is missing")
+	}
+
+	// the request is prepared and split as in the auction, which modifies it, so the explanation works on a copy
+	if err := r.BidRequestWrapper.RebuildRequest(); err != nil {
+		return nil, err
+	}
+	requestJSON, err := jsonutil.Marshal(r.BidRequestWrapper.BidRequest)
+	if err != nil {
+		return nil, err
+	}
+	bidRequest := &openrtb2.BidRequest{}
+	if err := jsonutil.UnmarshalValid(requestJSON, bidRequest); err != nil {
+		return nil, err
+	}
+	auctionReq := *r
+	auctionReq.BidRequestWrapper = &openrtb_ext.RequestWrapper{BidRequest: bidRequest}
+	auctionReq.PrivacyTrace = nil
+	if auctionReq.UserSyncs == nil {
+		auctionReq.UserSyncs = usersync.NewCookie()
+	}
+	req := auctionReq.BidRequestWrapper
+	if err := PreloadExts(req); err != nil {
+		return nil, err
+	}
+
+	requestExt, err := req.GetRequestExt()
+	if err != nil {
+		return nil, err
+	}
+
+	resolvedFPD, fpdErrors := firstpartydata.ExtractFPDForBidders(req)
+	if len(fpdErrors) > 0 {
+		return nil, fpdErrors[0]
+	}
+	auctionReq.FirstPartyData = resolvedFPD
+
+	requestExtPrebid := requestExt.GetPrebid()
+	if requestExtPrebid == nil {
+		requestExtPrebid = &openrtb_ext.ExtRequestPrebid{}
+	}
+	requestExtLegacy := &openrtb_ext.ExtRequest{
+		Prebid: *requestExtPrebid,
+		SChain: requestExt.GetSChain(),
+	}
+	bidAdjustmentFactors := getExtBidAdjustmentFactors(requestExtPrebid)
+
+	eeaCountries := selectEEACountries(e.privacyConfig.GDPR.EEACountries, r.Account.GDPR.EEACountries)
+	gdprDefaultValue := e.parseGDPRDefaultValue(req, eeaCountries)
+	gdprSignal, err := getGDPR(req)
+	if err != nil {
+		return nil, err
+	}
+	channelEnabled := r.TCF2Config.ChannelEnabled(channelTypeMap[r.LegacyLabels.RType])
+	gdprEnforced := enforceGDPR(gdprSignal, gdprDefaultValue, channelEnabled)
+
+	// explanations must not be recorded as auction metrics
+	rs := e.requestSplitter
+	rs.me = &metricsConfig.NilMetricsEngine{}
+
+	split, privacyErrs := rs.splitRequest(auctionReq, requestExtLegacy, gdprSignal, gdprEnforced, bidAdjustmentFactors)
+	if split == nil {
+		if len(privacyErrs) > 0 {
+			return nil, privacyErrs[0]
+		}
+		return nil, errors.New("request can't be split into bidder requests")
+	}
+	if len(bidders) == 0 {
+		for bidder := range split.impsByBidder {
+			bidders = append(bidders, bidder)
+		}
+	}
+
+	explanation := &PrivacyExplanation{
+		GDPRApplies:  gdprEnforced,
+		CCPAProvided: split.reqPrivacy.labels.CCPAProvided,
+		CCPAEnforced: split.reqPrivacy.labels.CCPAEnforced,
+		COPPA:        split.reqPrivacy.coppa,
+		LMT:          split.reqPrivacy.lmt,
+		Bidders:      make(map[string]BidderPrivacyExplanation, len(bidders)),
+	}
+	for _, err := range privacyErrs {
+		explanation.Errors = append(explanation.Errors, err.Error())
+	}
+
+	for _, bidder := range bidders {
+		explainer := &bidderPrivacyExplainer{}
+		newPrivacyExplainer := func(reqWrapper *openrtb_ext.RequestWrapper, bidder string, coreBidder openrtb_ext.BidderName) bidderPrivacyRecorder {
+			explainer.explanation = BidderPrivacyExplanation{
+				CoreBidder:   coreBidder.String(),
+				Activities:   explainActivities(auctionReq.Activities, bidder, reqWrapper),
+				GDPR:         explainGDPR(ctx, split.reqPrivacy.gdprPerms, gdprEnforced, coreBidder, openrtb_ext.BidderName(bidder)),
+				CCPAEnforced: split.reqPrivacy.ccpaEnforcer.ShouldEnforce(bidder),
+			}
+			return explainer
+		}
+
+		if _, _, err := rs.buildBidderRequest(ctx, split, bidder, split.impsByBidder[bidder], newPrivacyExplainer); err != nil {
+			explainer.explanation.Error = err.Error()
+		}
+		explanation.Bidders[bidder] = explainer.explanation
+	}
+
+	return explanation, nil
+}
+
+// bidderPrivacyExplainer records the privacy enforcement applied to a bidder request in its explanation
+type bidderPrivacyExplainer struct {
+	explanation BidderPrivacyExplanation
+	snapshot    []byte
+}
+
+// beforeScrub snapshots the request ahead of a privacy enforcement step
+func (x *bidderPrivacyExplainer) beforeScrub(reqWrapper *openrtb_ext.RequestWrapper) {
+	x.snapshot = snapshotRequest(reqWrapper)
+}
+
+// afterScrub records the paths of the fields changed since the last snapshot
+func (x *bidderPrivacyExplainer) afterScrub(reqWrapper *openrtb_ext.RequestWrapper) {
+	x.explanation.Scrubbed = appendScrubbedPaths(x.explanation.Scrubbed, x.snapshot, reqWrapper)
+}
+
+// blocked records the bidder request was blocked by privacy enforcement
+func (x *bidderPrivacyExplainer) blocked() {
+	x.explanation.Blocked = true
+	x.explanation.BlockedBy = blockedBy(x.explanation.Activities[privacy.ActivityFetchBids.String()].Allowed)
+}
+
+// changedJSONPaths returns the sorted paths of the fields of the original document which are
+// removed or modified in the updated document
+func changedJSONPaths(original, updated []byte) ([]string, error) {
+	var before, after any
+	if err := jsonutil.UnmarshalValid(original, &before); err != nil {
+		return nil, err
+	}
+	if err := jsonutil.UnmarshalValid(updated, &after); err != nil {
+		return nil, err
+	}
+
+	var paths []string
+	collectChangedPaths("", before, after, &paths)
+	slices.Sort(paths)
+	return paths, nil
+}
+
+func collectChangedPaths(path string, before, after any, paths *[]string) {
+	switch b := before.(type) {
+	case map[string]any:
+		a, ok := after.(map[string]any)
+		if !ok {
+			*paths = append(*paths, path)
+			return
+		}
+		for key, value := range b {
+			childPath := key
+			if path != "" {
+				childPath = path + "." + key
+			}
+			collectChangedPaths(childPath, value, a[key], paths)
+		}
+	case []any:
+		a, ok := after.([]any)
+		if !ok || len(a) != len(b) {
+			*paths = append(*paths, path)
+			return
+		}
+		for i := range b {
+			collectChangedPaths(path+"["+strconv.Itoa(i)+"]", b[i], a[i], paths)
+		}
+	default:
+		if before != after {
+			*paths = append(*paths, path)
+		}
+	}
+}
@@ -0,0 +1,222 @@
+package exchange
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/prebid/openrtb/v20/openrtb2"
+	"github.com/prebid/prebid-server/v3/config"
+	"github.com/prebid/prebid-server/v3/gdpr"
+	"github.com/prebid/prebid-server/v3/metrics"
+	"github.com/prebid/prebid-server/v3/openrtb_ext"
+	"github.com/prebid/prebid-server/v3/privacy"
+	"github.com/prebid/prebid-server/v3/util/jsonutil"
+	"github.com/prebid/prebid-server/v3/util/ptrutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// explainingPermissionsMock mocks a Permissions able to explain its decisions
+type explainingPermissionsMock struct {
+	permissionsMock
+}
+
+func (p *explainingPermissionsMock) ExplainAuctionActivities(ctx context.Context, bidderCoreName openrtb_ext.BidderName, bidder openrtb_ext.BidderName) gdpr.AuctionPermissionsExplanation {
+	permissions := p.AuctionActivitiesAllowed(ctx, bidderCoreName, bidder)
+	return gdpr.AuctionPermissionsExplanation{
+		Reason:     gdpr.ReasonConsent,
+		BidRequest: gdpr.PermissionDecision{Allowed: permissions.AllowBidRequest, Purpose: 2, Reason: gdpr.ReasonLegalBasis},
+		Geo:        gdpr.PermissionDecision{Allowed: permissions.PassGeo, SpecialFeature: 1, Reason: gdpr.ReasonLegalBasis},
+		ID:         gdpr.PermissionDecision{Allowed: permissions.PassID, Reason: gdpr.ReasonNoLegalBasis},
+	}
+}
+
+func newPrivacyExplainRequest(regs *openrtb2.Regs) *openrtb2.BidRequest {
+	return &openrtb2.BidRequest{
+		ID: "req",
+		Imp: []openrtb2.Imp{{
+			ID:  "imp",
+			Ext: json.RawMessage(`{"prebid":{"bidder":{"appnexus":{"placementId":1},"rubicon":{"accountId":1}}}}`),
+		}},
+		Site: &openrtb2.Site{Publisher: &openrtb2.Publisher{ID: "acct"}},
+		User: &openrtb2.User{ID: "user", BuyerUID: "buyer"},
+		Device: &openrtb2.Device{
+			IFA: "ifa",
+			IP:  "132.173.230.74",
+		},
+		Regs: regs,
+	}
+}
+
+func TestExplainPrivacy(t *testing.T) {
+	fetchBidsDeniedForRubicon := privacy.NewActivityControl(&config.AccountPrivacy{
+		AllowActivities: &config.AllowActivities{
+			FetchBids: config.Activity{
+				Rules: []config.ActivityRule{{Condition: config.ActivityCondition{ComponentName: []string{"rubicon"}}}},
+			},
+		},
+	})
+
+	testCases := []struct {
+		name                string
+		bidRequest          *openrtb2.BidRequest
+		activities          privacy.ActivityControl
+		permissions         gdpr.Permissions
+		bidders             []string
+		expectedGDPRApplies bool
+		expectedCCPA        bool
+		assertBidders       func(t *testing.T, bidders map[string]BidderPrivacyExplanation)
+	}{
+		{
+			name:       "no_privacy_policies",
+			bidRequest: newPrivacyExplainRequest(nil),
+			assertBidders: func(t *testing.T, bidders map[string]BidderPrivacyExplanation) {
+				require.Len(t, bidders, 2)
+				for _, bidder := range bidders {
+					assert.False(t, bidder.Blocked)
+					assert.Nil(t, bidder.GDPR)
+					assert.Empty(t, bidder.Scrubbed)
+					assert.Equal(t, privacy.ActivityDecision{Allowed: true}, bidder.Activities["fetchBids"])
+				}
+			},
+		},
+		{
+			name:       "blocked_by_activity",
+			bidRequest: newPrivacyExplainRequest(nil),
+			activities: fetchBidsDeniedForRubicon,
+			bidders:    []string{"rubicon"},
+			assertBidders: func(t *testing.T, bidders map[string]BidderPrivacyExplanation) {
+				require.Len(t, bidders, 1)
+				assert.True(t, bidders["rubicon"].Blocked)
+				assert.Equal(t, "activity:fetchBids", bidders["rubicon"].BlockedBy)
+				assert.Equal(t, privacy.ActivityDecision{Allowed: false, Rule: "rules[0] componentName=[rubicon] deny"}, bidders["rubicon"].Activities["fetchBids"])
+			},
+		},
+		{
+			name:                "gdpr",
+			bidRequest:          newPrivacyExplainRequest(&openrtb2.Regs{GDPR: ptrutil.ToPtr[int8](1)}),
+			permissions:         &explainingPermissionsMock{permissionsMock{allowedBidders: []openrtb_ext.BidderName{"appnexus"}, passGeo: true}},
+			expectedGDPRApplies: true,
+			assertBidders: func(t *testing.T, bidders map[string]BidderPrivacyExplanation) {
+				require.Len(t, bidders, 2)
+
+				assert.True(t, bidders["rubicon"].Blocked)
+				assert.Equal(t, "gdpr", bidders["rubicon"].BlockedBy)
+				require.NotNil(t, bidders["rubicon"].GDPR)
+				assert.False(t, bidders["rubicon"].GDPR.BidRequest.Allowed)
+
+				assert.False(t, bidders["appnexus"].Blocked)
+				require.NotNil(t, bidders["appnexus"].GDPR)
+				assert.Equal(t, gdpr.ReasonNoLegalBasis, bidders["appnexus"].GDPR.ID.Reason)
+				assert.Contains(t, bidders["appnexus"].Scrubbed, "device.ifa")
+				assert.Contains(t, bidders["appnexus"].Scrubbed, "user.buyeruid")
+				assert.NotContains(t, bidders["appnexus"].Scrubbed, "device.ip")
+			},
+		},
+		{
+			name:         "ccpa",
+			bidRequest:   newPrivacyExplainRequest(&openrtb2.Regs{USPrivacy: "1-Y-"}),
+			bidders:      []string{"appnexus"},
+			expectedCCPA: true,
+			assertBidders: func(t *testing.T, bidders map[string]BidderPrivacyExplanation) {
+				require.Len(t, bidders, 1)
+				assert.True(t, bidders["appnexus"].CCPAEnforced)
+				assert.Contains(t, bidders["appnexus"].Scrubbed, "device.ifa")
+				assert.Contains(t, bidders["appnexus"].Scrubbed, "device.ip")
+			},
+		},
+		{
+			name: "explicit_buyeruid",
+			bidRequest: func() *openrtb2.BidRequest {
+				bidRequest := newPrivacyExplainRequest(&openrtb2.Regs{USPrivacy: "1-Y-"})
+				bidRequest.User = &openrtb2.User{ID: "user", Ext: json.RawMessage(`{"prebid":{"buyeruids":{"appnexus":"explicit"}}}`)}
+				return bidRequest
+			}(),
+			bidders:      []string{"appnexus"},
+			expectedCCPA: true,
+			assertBidders: func(t *testing.T, bidders map[string]BidderPrivacyExplanation) {
+				require.Len(t, bidders, 1)
+				assert.Contains(t, bidders["appnexus"].Scrubbed, "user.buyeruid", "the buyeruid set by the auction should be scrubbed")
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.name, func(t *testing.T) {
+			permissions := test.permissions
+			if permissions == nil {
+				permissions = &permissionsMock{allowAllBidders: true, passGeo: true, passID: true}
+			}
+			privacyConfig := config.Privacy{
+				CCPA: config.CCPA{Enforce: true},
+				LMT:  config.LMT{Enforce: true},
+			}
+			e := &exchange{
+				gdprDefaultValue: gdpr.SignalNo,
+				privacyConfig:    privacyConfig,
+				requestSplitter: requestSplitter{
+					me:               &metrics.MetricsEngineMock{},
+					privacyConfig:    privacyConfig,
+					gdprPermsBuilder: fakePermissionsBuilder{permissions: permissions}.Builder,
+					bidderInfo:       config.BidderInfos{},
+				},
+			}
+			original, err := jsonutil.Marshal(test.bidRequest)
+			require.NoError(t, err)
+
+			auctionReq := &AuctionRequest{
+				BidRequestWrapper: &openrtb_ext.RequestWrapper{BidRequest: test.bidRequest},
+				Account:           config.Account{ID: "acct"},
+				Activities:        test.activities,
+				TCF2Config:        gdpr.NewTCF2Config(config.TCF2{Enabled: true}, config.AccountGDPR{}),
+				LegacyLabels:      metrics.Labels{PubID: "acct", RType: metrics.ReqTypeORTB2Web},
+			}
+
+			explanation, err := e.ExplainPrivacy(context.Background(), auctionReq, test.bidders)
+			require.NoError(t, err)
+			assert.Equal(t, test.expectedGDPRApplies, explanation.GDPRApplies)
+			assert.Equal(t, test.expectedCCPA, explanation.CCPAEnforced)
+			test.assertBidders(t, explanation.Bidders)
+
+			after, err := jsonutil.Marshal(test.bidRequest)
+			require.NoError(t, err)
+			assert.JSONEq(t, string(original), string(after), "the request should not be modified")
+		})
+	}
+}
+
+func TestChangedJSONPaths(t *testing.T) {
+	testCases := []struct {
+		name     string
+		original string
+		updated  string
+		expected []string
+	}{
+		{
+			name:     "unchanged",
+			original: `{"user":{"id":"1"},"imp":[{"id":"a"}]}`,
+			updated:  `{"user":{"id":"1"},"imp":[{"id":"a"}]}`,
+		},
+		{
+			name:     "removed_and_modified",
+			original: `{"user":{"id":"1","yob":1980,"eids":[{"source":"a"}]},"device":{"ip":"1.2.3.4","geo":{"lat":1.234}}}`,
+			updated:  `{"user":{"yob":1980},"device":{"ip":"1.2.3.0","geo":{"lat":1.23}}}`,
+			expected: []string{"device.geo.lat", "device.ip", "user.eids", "user.id"},
+		},
+		{
+			name:     "array_items",
+			original: `{"imp":[{"ext":{"tid":"1"}},{"ext":{"tid":"2"}}]}`,
+			updated:  `{"imp":[{"ext":{}},{"ext":{}}]}`,
+			expected: []string{"imp[0].ext.tid", "imp[1].ext.tid"},
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.name, func(t *testing.T) {
+			paths, err := changedJSONPaths([]byte(test.original), []byte(test.updated))
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, paths)
+		})
+	}
+}
@@ -0,0 +1,138 @@
+package exchange
+
+import (
+	"context"
+	"slices"
+
+	"github.com/prebid/prebid-server/v3/gdpr"
+	"github.com/prebid/prebid-server/v3/openrtb_ext"
+	"github.com/prebid/prebid-server/v3/privacy"
+	"github.com/prebid/prebid-server/v3/util/jsonutil"
+)
+
+// bidderPrivacyTracer records the privacy enforcement applied to a bidder request in the debug output.
+// A nil tracer records nothing.
+type bidderPrivacyTracer struct {
+	trace    *openrtb_ext.ExtBidderPrivacyTrace
+	snapshot []byte
+}
+
+// newBidderPrivacyTracer starts the trace of the bidder when the auction collects privacy traces
+func newBidderPrivacyTracer(ctx context.Context, reqWrapper *openrtb_ext.RequestWrapper, bidder string, coreBidder openrtb_ext.BidderName, auctionReq AuctionRequest, reqPrivacy requestPrivacy, gdprEnforced bool) *bidderPrivacyTracer {
+	if auctionReq.PrivacyTrace == nil {
+		return nil
+	}
+
+	trace := &openrtb_ext.ExtBidderPrivacyTrace{
+		Activities:   make(map[string]openrtb_ext.ExtActivityTrace, len(explainedActivities)),
+		CCPAEnforced: reqPrivacy.ccpaEnforcer.ShouldEnforce(bidder),
+	}
+	for activity, decision := range explainActivities(auctionReq.Activities, bidder, reqWrapper) {
+		trace.Activities[activity] = openrtb_ext.ExtActivityTrace{Allowed: decision.Allowed, Rule: decision.Rule}
+	}
+	if gdprExplanation := explainGDPR(ctx, reqPrivacy.gdprPerms, gdprEnforced, coreBidder, openrtb_ext.BidderName(bidder)); gdprExplanation != nil {
+		trace.TCF = &openrtb_ext.ExtTCFTrace{
+			Reason:     gdprExplanation.Reason,
+			VendorID:   gdprExplanation.VendorID,
+			BidRequest: tcfPurposeTrace(gdprExplanation.BidRequest),
+			Geo:        tcfPurposeTrace(gdprExplanation.Geo),
+			ID:         tcfPurposeTrace(gdprExplanation.ID),
+		}
+	}
+
+	auctionReq.PrivacyTrace[openrtb_ext.BidderName(bidder)] = trace
+	return &bidderPrivacyTracer{trace: trace}
+}
+
+func tcfPurposeTrace(decision gdpr.PermissionDecision) openrtb_ext.ExtTCFPurposeTrace {
+	return openrtb_ext.ExtTCFPurposeTrace{
+		Allowed:         decision.Allowed,
+		Purpose:         decision.Purpose,
+		SpecialFeature:  decision.SpecialFeature,
+		EnforceAlgo:     decision.EnforceAlgo,
+		VendorException: decision.VendorException,
+		Reason:          decision.Reason,
+	}
+}
+
+// beforeScrub snapshots the request ahead of a privacy enforcement step
+func (t *bidderPrivacyTracer) beforeScrub(reqWrapper *openrtb_ext.RequestWrapper) {
+	if t == nil {
+		return
+	}
+	t.snapshot = snapshotRequest(reqWrapper)
+}
+
+// afterScrub records the paths of the fields changed since the last snapshot
+func (t *bidderPrivacyTracer) afterScrub(reqWrapper *openrtb_ext.RequestWrapper) {
+	if t == nil {
+		return
+	}
+	t.trace.Scrubbed = appendScrubbedPaths(t.trace.Scrubbed, t.snapshot, reqWrapper)
+}
+
+// blocked records the bidder request was blocked by privacy enforcement
+func (t *bidderPrivacyTracer) blocked() {
+	if t == nil {
+		return
+	}
+	t.trace.Blocked = true
+	t.trace.BlockedBy = blockedBy(t.trace.Activities[privacy.ActivityFetchBids.String()].Allowed)
+}
+
+// explainActivities evaluates the activities the auction enforces for the bidder
+func explainActivities(activities privacy.ActivityControl, bidder string, reqWrapper *openrtb_ext.RequestWrapper) map[string]privacy.ActivityDecision {
+	scope := privacy.Component{Type: privacy.ComponentTypeBidder, Name: bidder}
+	decisions := make(map[string]privacy.ActivityDecision, len(explainedActivities))
+	for _, activity := range explainedActivities {
+		decisions[activity.String()] = activities.Explain(activity, scope, privacy.NewRequestFromBidRequest(*reqWrapper))
+	}
+	return decisions
+}
+
+// explainGDPR explains the GDPR permissions of the bidder, nil when GDPR is not enforced or the
+// permissions can't be explained
+func explainGDPR(ctx context.Context, perms gdpr.Permissions, gdprEnforced bool, coreBidder, bidder openrtb_ext.BidderName) *gdpr.AuctionPermissionsExplanation {
+	explainer, ok := perms.(gdpr.PermissionsExplainer)
+	if !ok || !gdprEnforced {
+		return nil
+	}
+	explanation := explainer.ExplainAuctionActivities(ctx, coreBidder, bidder)
+	return &explanation
+}
+
+// blockedBy identifies the check which blocked a bidder request
+func blockedBy(fetchBidsAllowed bool) string {
+	if !fetchBidsAllowed {
+		return blockedByActivity
+	}
+	return blockedByGDPR
+}
+
+// snapshotRequest serializes the request ahead of a privacy enforcement step, nil when it can't be serialized
+func snapshotRequest(reqWrapper *openrtb_ext.RequestWrapper) []byte {
+	if err := reqWrapper.RebuildRequest(); err != nil {
+		return nil
+	}
+	snapshot, _ := jsonutil.Marshal(reqWrapper.BidRequest)
+	return snapshot
+}
+
+// appendScrubbedPaths adds the paths of the fields of the request changed since the snapshot to the
+// sorted scrubbed paths
+func appendScrubbedPaths(scrubbed []string, snapshot []byte, reqWrapper *openrtb_ext.RequestWrapper) []string {
+	if snapshot == nil {
+		return scrubbed
+	}
+	paths, err := changedJSONPaths(snapshot, snapshotRequest(reqWrapper))
+	if err != nil {
+		return scrubbed
+	}
+	for _, path := range paths {
+		if !slices.Contains(scrubbed, path) {
+			scrubbed = append(scrubbed, path)
+		}
+	}
+	slices.Sort(scrubbed)
+	return scrubbed
+}
@@ -0,0 +1,155 @@
+package exchange
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/prebid/openrtb/v20/openrtb2"
+	"github.com/prebid/prebid-server/v3/config"
+	"github.com/prebid/prebid-server/v3/gdpr"
+	"github.com/prebid/prebid-server/v3/metrics"
+	"github.com/prebid/prebid-server/v3/openrtb_ext"
+	"github.com/prebid/prebid-server/v3/privacy"
+	"github.com/prebid/prebid-server/v3/util/jsonutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCleanOpenRTBRequestsPrivacyTrace(t *testing.T) {
+	newRequest := func() *openrtb2.BidRequest {
+		bidRequest := newPrivacyExplainRequest(nil)
+		bidRequest.User = &openrtb2.User{ID: "secret-user", BuyerUID: "secret-buyer", EIDs: []openrtb2.EID{{Source: "src.com", UIDs: []openrtb2.UID{{ID: "secret-eid"}}}}}
+		bidRequest.Device.IFA = "secret-ifa"
+		bidRequest.Ext = json.RawMessage(`{"prebid":{"data":{"eidpermissions":[{"source":"src.com","bidders":["rubicon"]}]}}}`)
+		return bidRequest
+	}
+
+	transmitUfpdDeniedForAppnexus := privacy.NewActivityControl(&config.AccountPrivacy{
+		AllowActivities: &config.AllowActivities{
+			TransmitUserFPD: config.Activity{
+				Rules: []config.ActivityRule{{Condition: config.ActivityCondition{ComponentName: []string{"appnexus"}}}},
+			},
+		},
+	})
+
+	testCases := []struct {
+		name          string
+		trace         bool
+		activities    privacy.ActivityControl
+		permissions   gdpr.Permissions
+		gdprEnforced  bool
+		expectedTrace map[openrtb_ext.BidderName]*openrtb_ext.ExtBidderPrivacyTrace
+	}{
+		{
+			name:        "trace_disabled",
+			permissions: &permissionsMock{allowAllBidders: true, passGeo: true, passID: true},
+		},
+		{
+			name:        "eids_removed",
+			trace:       true,
+			permissions: &permissionsMock{allowAllBidders: true, passGeo: true, passID: true},
+			expectedTrace: map[openrtb_ext.BidderName]*openrtb_ext.ExtBidderPrivacyTrace{
+				"appnexus": {
+					Activities: allowedActivitiesTrace(),
+					Scrubbed:   []string{"user.eids"},
+				},
+				"rubicon": {
+					Activities: allowedActivitiesTrace(),
+				},
+			},
+		},
+		{
+			name:        "activity_scrubbing",
+			trace:       true,
+			activities:  transmitUfpdDeniedForAppnexus,
+			permissions: &permissionsMock{allowAllBidders: true, passGeo: true, passID: true},
+			expectedTrace: map[openrtb_ext.BidderName]*openrtb_ext.ExtBidderPrivacyTrace{
+				"appnexus": {
+					Activities: func() map[string]openrtb_ext.ExtActivityTrace {
+						activities := allowedActivitiesTrace()
+						activities["transmitUfpd"] = openrtb_ext.ExtActivityTrace{Allowed: false, Rule: "rules[0] componentName=[appnexus] deny"}
+						return activities
+					}(),
+					Scrubbed: []string{"device.ifa", "user.buyeruid", "user.eids", "user.id"},
+				},
+				"rubicon": {
+					Activities: allowedActivitiesTrace(),
+				},
+			},
+		},
+		{
+			name:         "gdpr",
+			trace:        true,
+			permissions:  &explainingPermissionsMock{permissionsMock{allowedBidders: []openrtb_ext.BidderName{"appnexus"}, passGeo: true}},
+			gdprEnforced: true,
+			expectedTrace: map[openrtb_ext.BidderName]*openrtb_ext.ExtBidderPrivacyTrace{
+				"appnexus": {
+					Activities: allowedActivitiesTrace(),
+					TCF: &openrtb_ext.ExtTCFTrace{
+						Reason:     gdpr.ReasonConsent,
+						BidRequest: openrtb_ext.ExtTCFPurposeTrace{Allowed: true, Purpose: 2, Reason: gdpr.ReasonLegalBasis},
+						Geo:        openrtb_ext.ExtTCFPurposeTrace{Allowed: true, SpecialFeature: 1, Reason: gdpr.ReasonLegalBasis},
+						ID:         openrtb_ext.ExtTCFPurposeTrace{Allowed: false, Reason: gdpr.ReasonNoLegalBasis},
+					},
+					Scrubbed: []string{"device.ifa", "user.buyeruid", "user.eids", "user.id"},
+				},
+				"rubicon": {
+					Blocked:    true,
+					BlockedBy:  "gdpr",
+					Activities: allowedActivitiesTrace(),
+					TCF: &openrtb_ext.ExtTCFTrace{
+						Reason:     gdpr.ReasonConsent,
+						BidRequest: openrtb_ext.ExtTCFPurposeTrace{Allowed: false, Purpose: 2, Reason: gdpr.ReasonLegalBasis},
+						Geo:        openrtb_ext.ExtTCFPurposeTrace{Allowed: true, SpecialFeature: 1, Reason: gdpr.ReasonLegalBasis},
+						ID:         openrtb_ext.ExtTCFPurposeTrace{Allowed: false, Reason: gdpr.ReasonNoLegalBasis},
+					},
+				},
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.name, func(t *testing.T) {
+			metricsMock := metrics.MetricsEngineMock{}
+			metricsMock.Mock.On("RecordAdapterBuyerUIDScrubbed", mock.Anything).Return()
+			metricsMock.Mock.On("RecordAdapterGDPRRequestBlocked", mock.Anything).Return()
+
+			reqSplitter := &requestSplitter{
+				bidderToSyncerKey: map[string]string{},
+				me:                &metricsMock,
+				gdprPermsBuilder:  fakePermissionsBuilder{permissions: test.permissions}.Builder,
+				bidderInfo:        config.BidderInfos{},
+			}
+
+			auctionReq := AuctionRequest{
+				BidRequestWrapper: &openrtb_ext.RequestWrapper{BidRequest: newRequest()},
+				UserSyncs:         &emptyUsersync{},
+				Activities:        test.activities,
+				TCF2Config:        gdpr.NewTCF2Config(config.TCF2{Enabled: true}, config.AccountGDPR{}),
+			}
+			if test.trace {
+				auctionReq.PrivacyTrace = make(map[openrtb_ext.BidderName]*openrtb_ext.ExtBidderPrivacyTrace)
+			}
+
+			_, _, errs := reqSplitter.cleanOpenRTBRequests(context.Background(), auctionReq, nil, gdpr.SignalYes, test.gdprEnforced, map[string]float64{})
+			assert.Empty(t, errs)
+			assert.Equal(t, test.expectedTrace, auctionReq.PrivacyTrace)
+
+			traceJSON, err := jsonutil.Marshal(auctionReq.PrivacyTrace)
+			require.NoError(t, err)
+			assert.NotContains(t, string(traceJSON), "secret", "the trace must not leak request values")
+		})
+	}
+}
+
+func allowedActivitiesTrace() map[string]openrtb_ext.ExtActivityTrace {
+	return map[string]openrtb_ext.ExtActivityTrace{
+		"fetchBids":          {Allowed: true},
+		"transmitUfpd":       {Allowed: true},
+		"transmitPreciseGeo": {Allowed: true},
+		"transmitEids":       {Allowed: true},
+		"transmitTid":        {Allowed: true},
+	}
+}
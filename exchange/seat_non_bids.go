@@ -75,3 +75,16 @@ func (b SeatNonBidBuilder) append(nonBids ...SeatNonBidBuilder) {
 		}
 	}
 }
+
+// appendSeatNonBids adds the nonBids reported outside of the exchange, e.g. by module hooks.
+// This method is not thread safe as we are initializing and writing to map
+func (b SeatNonBidBuilder) appendSeatNonBids(seatNonBids []openrtb_ext.SeatNonBid) {
+	if b == nil {
+		return
+	}
+	for _, seatNonBid := range seatNonBids {
+		if len(seatNonBid.NonBid) > 0 {
+			b[seatNonBid.Seat] = append(b[seatNonBid.Seat], seatNonBid.NonBid...)
+		}
+	}
+}
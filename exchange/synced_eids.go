@@ -0,0 +1,102 @@
+package exchange
+
+import (
+	"sort"
+
+	"github.com/prebid/openrtb/v20/adcom1"
+	"github.com/prebid/openrtb/v20/openrtb2"
+	"github.com/prebid/prebid-server/v3/config"
+	"github.com/prebid/prebid-server/v3/usersync"
+)
+
+// syncerEID is the user.eids entry configured for a syncer key
+type syncerEID struct {
+	syncerKey string
+	eid       config.SyncerEID
+}
+
+// buildSyncerEIDs returns the eids configured for the syncers, sorted by syncer key so the eids are
+// always added in the same order.
+func buildSyncerEIDs(syncersByBidder map[string]usersync.Syncer, infos config.BidderInfos) []syncerEID {
+	eidsBySyncerKey := make(map[string]config.SyncerEID)
+	for bidder, syncer := range syncersByBidder {
+		if info, ok := infos[bidder]; ok && info.Syncer != nil && info.Syncer.EID != nil {
+			eidsBySyncerKey[syncer.Key()] = *info.Syncer.EID
+		}
+	}
+
+	syncerEIDs := make([]syncerEID, 0, len(eidsBySyncerKey))
+	for syncerKey, eid := range eidsBySyncerKey {
+		syncerEIDs = append(syncerEIDs, syncerEID{syncerKey: syncerKey, eid: eid})
+	}
+	sort.Slice(syncerEIDs, func(i, j int) bool {
+		return syncerEIDs[i].syncerKey < syncerEIDs[j].syncerKey
+	})
+	return syncerEIDs
+}
+
+// syncedEID is an eid built from the UID synced under a syncer key
+type syncedEID struct {
+	syncerKey string
+	eid       openrtb2.EID
+}
+
+// buildSyncedEIDs returns the eids of the live UIDs synced under the syncer keys configured with an eid
+func buildSyncedEIDs(syncerEIDs []syncerEID, usersyncs IdFetcher) []syncedEID {
+	if usersyncs == nil {
+		return nil
+	}
+
+	var syncedEIDs []syncedEID
+	for _, syncer := range syncerEIDs {
+		uid, _, notExpired := usersyncs.GetUID(syncer.syncerKey)
+		if uid == "" || !notExpired {
+			continue
+		}
+		syncedEIDs = append(syncedEIDs, syncedEID{
+			syncerKey: syncer.syncerKey,
+			eid: openrtb2.EID{
+				Source: syncer.eid.Source,
+				UIDs:   []openrtb2.UID{{ID: uid, AType: adcom1.AgentType(syncer.eid.AType)}},
+			},
+		})
+	}
+	return syncedEIDs
+}
+
+// appendSyncedEIDs adds the synced eids to the user of the bidder request, except for the UID of the
+// bidder's own syncer, which is sent as the buyeruid, and the sources the request already has eids for.
+// The user is copied, so the request of the other bidders is left untouched.
+func appendSyncedEIDs(req *openrtb2.BidRequest, bidderSyncerKey string, syncedEIDs []syncedEID) {
+	if len(syncedEIDs) == 0 {
+		return
+	}
+
+	var user openrtb2.User
+	if req.User != nil {
+		user = *req.User
+	}
+
+	sources := make(map[string]struct{}, len(user.EIDs))
+	for _, eid := range user.EIDs {
+		sources[eid.Source] = struct{}{}
+	}
+
+	eids := append([]openrtb2.EID(nil), user.EIDs...)
+	for _, synced := range syncedEIDs {
+		if synced.syncerKey == bidderSyncerKey {
+			continue
+		}
+		if _, exists := sources[synced.eid.Source]; exists {
+			continue
+		}
+		sources[synced.eid.Source] = struct{}{}
+		eids = append(eids, synced.eid)
+	}
+
+	if len(eids) == len(user.EIDs) {
+		return
+	}
+	user.EIDs = eids
+	req.User = &user
+}
@@ -0,0 +1,264 @@
+package exchange
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/prebid/openrtb/v20/adcom1"
+	"github.com/prebid/openrtb/v20/openrtb2"
+	"github.com/prebid/prebid-server/v3/config"
+	"github.com/prebid/prebid-server/v3/gdpr"
+	"github.com/prebid/prebid-server/v3/metrics"
+	"github.com/prebid/prebid-server/v3/openrtb_ext"
+	"github.com/prebid/prebid-server/v3/privacy"
+	"github.com/prebid/prebid-server/v3/usersync"
+	"github.com/prebid/prebid-server/v3/util/ptrutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildSyncerEIDs(t *testing.T) {
+	syncersByBidder := map[string]usersync.Syncer{
+		"appnexus": keyedSyncer{key: "adnxs"},
+		"idprov":   keyedSyncer{key: "idprov"},
+		"rubicon":  keyedSyncer{key: "rubicon"},
+		"alias":    keyedSyncer{key: "idprov"},
+	}
+	infos := config.BidderInfos{
+		"appnexus": config.BidderInfo{Syncer: &config.Syncer{EID: &config.SyncerEID{Source: "adnxs.com", AType: 1}}},
+		"idprov":   config.BidderInfo{Syncer: &config.Syncer{EID: &config.SyncerEID{Source: "idprov.com", AType: 3}}},
+		"rubicon":  config.BidderInfo{Syncer: &config.Syncer{}},
+	}
+
+	expected := []syncerEID{
+		{syncerKey: "adnxs", eid: config.SyncerEID{Source: "adnxs.com", AType: 1}},
+		{syncerKey: "idprov", eid: config.SyncerEID{Source: "idprov.com", AType: 3}},
+	}
+	assert.Equal(t, expected, buildSyncerEIDs(syncersByBidder, infos))
+}
+
+func TestBuildSyncedEIDs(t *testing.T) {
+	syncerEIDs := []syncerEID{
+		{syncerKey: "adnxs", eid: config.SyncerEID{Source: "adnxs.com", AType: 1}},
+		{syncerKey: "expired", eid: config.SyncerEID{Source: "expired.com", AType: 1}},
+		{syncerKey: "idprov", eid: config.SyncerEID{Source: "idprov.com", AType: 3}},
+		{syncerKey: "unsynced", eid: config.SyncerEID{Source: "unsynced.com", AType: 1}},
+	}
+	usersyncs := &syncedUsersync{
+		uids:    map[string]string{"adnxs": "an-uid", "expired": "old-uid", "idprov": "id-uid"},
+		expired: map[string]bool{"expired": true},
+	}
+
+	expected := []syncedEID{
+		{syncerKey: "adnxs", eid: openrtb2.EID{Source: "adnxs.com", UIDs: []openrtb2.UID{{ID: "an-uid", AType: adcom1.AgentTypeWeb}}}},
+		{syncerKey: "idprov", eid: openrtb2.EID{Source: "idprov.com", UIDs: []openrtb2.UID{{ID: "id-uid", AType: adcom1.AgentTypePerson}}}},
+	}
+	assert.Equal(t, expected, buildSyncedEIDs(syncerEIDs, usersyncs))
+	assert.Nil(t, buildSyncedEIDs(syncerEIDs, nil))
+}
+
+func TestAppendSyncedEIDs(t *testing.T) {
+	syncedEIDs := []syncedEID{
+		{syncerKey: "adnxs", eid: openrtb2.EID{Source: "adnxs.com", UIDs: []openrtb2.UID{{ID: "an-uid"}}}},
+		{syncerKey: "idprov", eid: openrtb2.EID{Source: "idprov.com", UIDs: []openrtb2.UID{{ID: "id-uid"}}}},
+	}
+
+	testCases := []struct {
+		description     string
+		user            *openrtb2.User
+		bidderSyncerKey string
+		syncedEIDs      []syncedEID
+		expectedUser    *openrtb2.User
+	}{
+		{
+			description:     "No Synced EIDs",
+			user:            &openrtb2.User{ID: "user"},
+			bidderSyncerKey: "rubicon",
+			expectedUser:    &openrtb2.User{ID: "user"},
+		},
+		{
+			description:     "No User",
+			bidderSyncerKey: "rubicon",
+			syncedEIDs:      syncedEIDs,
+			expectedUser:    &openrtb2.User{EIDs: []openrtb2.EID{syncedEIDs[0].eid, syncedEIDs[1].eid}},
+		},
+		{
+			description:     "Own UID Skipped",
+			user:            &openrtb2.User{ID: "user"},
+			bidderSyncerKey: "adnxs",
+			syncedEIDs:      syncedEIDs,
+			expectedUser:    &openrtb2.User{ID: "user", EIDs: []openrtb2.EID{syncedEIDs[1].eid}},
+		},
+		{
+			description:     "Request EIDs Win",
+			user:            &openrtb2.User{EIDs: []openrtb2.EID{{Source: "idprov.com", UIDs: []openrtb2.UID{{ID: "request-uid"}}}}},
+			bidderSyncerKey: "rubicon",
+			syncedEIDs:      syncedEIDs,
+			expectedUser:    &openrtb2.User{EIDs: []openrtb2.EID{{Source: "idprov.com", UIDs: []openrtb2.UID{{ID: "request-uid"}}}, syncedEIDs[0].eid}},
+		},
+		{
+			description:     "Nothing To Add",
+			user:            &openrtb2.User{ID: "user"},
+			bidderSyncerKey: "adnxs",
+			syncedEIDs:      syncedEIDs[:1],
+			expectedUser:    &openrtb2.User{ID: "user"},
+		},
+	}
+
+	for _, test := range testCases {
+		var original *openrtb2.User
+		if test.user != nil {
+			userCopy := *test.user
+			original = &userCopy
+		}
+		req := &openrtb2.BidRequest{User: test.user}
+
+		appendSyncedEIDs(req, test.bidderSyncerKey, test.syncedEIDs)
+
+		assert.Equal(t, test.expectedUser, req.User, test.description)
+		assert.Equal(t, original, test.user, test.description+": the request user must not change")
+	}
+}
+
+func TestCleanOpenRTBRequestsSyncedEIDs(t *testing.T) {
+	idProviderEID := openrtb2.EID{Source: "idprov.com", UIDs: []openrtb2.UID{{ID: "id-uid", AType: 3}}}
+	appnexusEID := openrtb2.EID{Source: "adnxs.com", UIDs: []openrtb2.UID{{ID: "an-uid", AType: 1}}}
+
+	testCases := []struct {
+		description   string
+		requestExt    json.RawMessage
+		regs          *openrtb2.Regs
+		gdprEnforced  bool
+		privacyConfig config.AccountPrivacy
+		expectedEIDs  map[string][]openrtb2.EID
+	}{
+		{
+			description: "Synced EIDs Added",
+			expectedEIDs: map[string][]openrtb2.EID{
+				"appnexus": {idProviderEID},
+				"pubmatic": {appnexusEID, idProviderEID},
+			},
+		},
+		{
+			description: "EID Permissions Enforced",
+			requestExt:  json.RawMessage(`{"prebid":{"data":{"eidpermissions":[{"source":"idprov.com","bidders":["pubmatic"]}]}}}`),
+			expectedEIDs: map[string][]openrtb2.EID{
+				"appnexus": nil,
+				"pubmatic": {appnexusEID, idProviderEID},
+			},
+		},
+		{
+			description:   "Transmit UFPD Denied",
+			privacyConfig: getTransmitUFPDActivityConfig("pubmatic", false),
+			expectedEIDs: map[string][]openrtb2.EID{
+				"appnexus": {idProviderEID},
+				"pubmatic": nil,
+			},
+		},
+		{
+			description:  "GDPR Without Consent",
+			regs:         &openrtb2.Regs{GDPR: ptrutil.ToPtr[int8](1)},
+			gdprEnforced: true,
+			expectedEIDs: map[string][]openrtb2.EID{
+				"appnexus": nil,
+				"pubmatic": nil,
+			},
+		},
+		{
+			description: "CCPA Opt-Out",
+			regs:        &openrtb2.Regs{USPrivacy: "1-Y-"},
+			expectedEIDs: map[string][]openrtb2.EID{
+				"appnexus": nil,
+				"pubmatic": nil,
+			},
+		},
+		{
+			description: "COPPA",
+			regs:        &openrtb2.Regs{COPPA: 1},
+			expectedEIDs: map[string][]openrtb2.EID{
+				"appnexus": nil,
+				"pubmatic": nil,
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.description, func(t *testing.T) {
+			req := &openrtb2.BidRequest{
+				Site: &openrtb2.Site{Publisher: &openrtb2.Publisher{ID: "some-publisher-id"}},
+				Imp: []openrtb2.Imp{{
+					ID:     "some-imp-id",
+					Banner: &openrtb2.Banner{Format: []openrtb2.Format{{W: 300, H: 250}}},
+					Ext:    json.RawMessage(`{"prebid":{"bidder":{"appnexus":{"placementId":1},"pubmatic":{"publisherId":"abc"}}}}`),
+				}},
+				Regs: test.regs,
+				Ext:  test.requestExt,
+			}
+
+			auctionReq := AuctionRequest{
+				BidRequestWrapper: &openrtb_ext.RequestWrapper{BidRequest: req},
+				UserSyncs:         &syncedUsersync{uids: map[string]string{"adnxs": "an-uid", "idprov": "id-uid"}},
+				Activities:        privacy.NewActivityControl(&test.privacyConfig),
+				TCF2Config:        gdpr.NewTCF2Config(config.TCF2{}, config.AccountGDPR{}),
+			}
+
+			metricsMock := metrics.MetricsEngineMock{}
+			metricsMock.Mock.On("RecordAdapterBuyerUIDScrubbed", mock.Anything).Return()
+
+			reqSplitter := &requestSplitter{
+				bidderToSyncerKey: map[string]string{"appnexus": "adnxs", "pubmatic": "pubmatic"},
+				syncerEIDs: []syncerEID{
+					{syncerKey: "adnxs", eid: config.SyncerEID{Source: "adnxs.com", AType: 1}},
+					{syncerKey: "idprov", eid: config.SyncerEID{Source: "idprov.com", AType: 3}},
+				},
+				me:               &metricsMock,
+				privacyConfig:    config.Privacy{CCPA: config.CCPA{Enforce: true}},
+				gdprPermsBuilder: fakePermissionsBuilder{permissions: &permissionsMock{allowAllBidders: true}}.Builder,
+				bidderInfo: config.BidderInfos{
+					"appnexus": config.BidderInfo{OpenRTB: &config.OpenRTBInfo{Version: "2.6"}},
+					"pubmatic": config.BidderInfo{OpenRTB: &config.OpenRTBInfo{Version: "2.6"}},
+				},
+			}
+
+			bidderRequests, _, errs := reqSplitter.cleanOpenRTBRequests(context.Background(), auctionReq, nil, gdpr.SignalNo, test.gdprEnforced, map[string]float64{})
+			assert.Empty(t, errs)
+			require.Len(t, bidderRequests, 2)
+
+			for _, bidderRequest := range bidderRequests {
+				var eids []openrtb2.EID
+				if bidderRequest.BidRequest.User != nil {
+					eids = bidderRequest.BidRequest.User.EIDs
+				}
+				assert.Equal(t, test.expectedEIDs[string(bidderRequest.BidderName)], eids, string(bidderRequest.BidderName))
+			}
+			assert.Nil(t, req.User, "the incoming request must not change")
+		})
+	}
+}
+
+// keyedSyncer is a syncer only known by its key
+type keyedSyncer struct {
+	usersync.Syncer
+	key string
+}
+
+func (s keyedSyncer) Key() string {
+	return s.key
+}
+
+// syncedUsersync returns the live UIDs synced under a syncer key, unless the key is expired
+type syncedUsersync struct {
+	uids    map[string]string
+	expired map[string]bool
+}
+
+func (s *syncedUsersync) GetUID(key string) (string, bool, bool) {
+	uid, exists := s.uids[key]
+	return uid, exists, exists && !s.expired[key]
+}
+
+func (s *syncedUsersync) HasAnyLiveSyncs() bool {
+	return len(s.uids) > 0
+}
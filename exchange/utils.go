@@ -12,6 +12,7 @@ import (
 	gpplib "github.com/prebid/go-gpp"
 	gppConstants "github.com/prebid/go-gpp/constants"
 	"github.com/prebid/openrtb/v20/openrtb2"
+	"go.opentelemetry.io/otel/attribute"
 
 	"github.com/prebid/prebid-server/v3/config"
 	"github.com/prebid/prebid-server/v3/errortypes"
@@ -25,6 +26,7 @@ import (
 	"github.com/prebid/prebid-server/v3/privacy/lmt"
 	"github.com/prebid/prebid-server/v3/schain"
 	"github.com/prebid/prebid-server/v3/stored_responses"
+	"github.com/prebid/prebid-server/v3/tracing"
 	"github.com/prebid/prebid-server/v3/util/jsonutil"
 	"github.com/prebid/prebid-server/v3/util/ptrutil"
 )
@@ -43,6 +45,7 @@ const unknownBidder string = ""
 
 type requestSplitter struct {
 	bidderToSyncerKey map[string]string
+	syncerEIDs        []syncerEID
 	me                metrics.MetricsEngine
 	privacyConfig     config.Privacy
 	gdprPermsBuilder  gdpr.PermissionsBuilder
@@ -63,14 +66,67 @@ func (rs *requestSplitter) cleanOpenRTBRequests(ctx context.Context,
 	gdprEnforced bool,
 	bidAdjustmentFactors map[string]float64,
 ) (bidderRequests []BidderRequest, privacyLabels metrics.PrivacyLabels, errs []error) {
+	ctx, span := tracing.Start(ctx, "exchange.clean_openrtb_requests")
+	defer func() {
+		span.SetAttributes(attribute.Int("pbs.bidder_requests", len(bidderRequests)))
+		span.End()
+	}()
+
+	split, errs := rs.splitRequest(auctionReq, requestExt, gdprSignal, gdprEnforced, bidAdjustmentFactors)
+	if split == nil {
+		return
+	}
+	privacyLabels = split.reqPrivacy.labels
+
+	newPrivacyTracer := func(reqWrapper *openrtb_ext.RequestWrapper, bidder string, coreBidder openrtb_ext.BidderName) bidderPrivacyRecorder {
+		return newBidderPrivacyTracer(ctx, reqWrapper, bidder, coreBidder, auctionReq, split.reqPrivacy, gdprEnforced)
+	}
+
+	bidderRequests = make([]BidderRequest, 0, len(split.impsByBidder))
+	for bidder, imps := range split.impsByBidder {
+		bidderRequest, ok, err := rs.buildBidderRequest(ctx, split, bidder, imps, newPrivacyTracer)
+		if err != nil {
+			errs = append(errs, err)
+		}
+		if ok {
+			bidderRequests = append(bidderRequests, bidderRequest)
+		}
+	}
+
+	return
+}
+
+// splitRequestState holds the request level state shared by the bidder requests of an auction
+type splitRequestState struct {
+	auctionReq                 AuctionRequest
+	requestAliases             map[string]string
+	impsByBidder               map[string][]openrtb2.Imp
+	bidderImpWithBidResp       stored_responses.BidderImpsWithBidResponses
+	lowerCaseExplicitBuyerUIDs map[string]string
+	bidderParamsInReqExt       map[string]json.RawMessage
+	sChainWriter               *schain.SChainWriter
+	reqPrivacy                 requestPrivacy
+	syncedEIDs                 []syncedEID
+	gdprEnforced               bool
+	bidAdjustmentFactors       map[string]float64
+}
+
+// splitRequest reads the request level state needed to build the bidder requests. The state is nil
+// when the request can't be split; the privacy errors are returned along with the state.
+func (rs *requestSplitter) splitRequest(auctionReq AuctionRequest,
+	requestExt *openrtb_ext.ExtRequest,
+	gdprSignal gdpr.Signal,
+	gdprEnforced bool,
+	bidAdjustmentFactors map[string]float64,
+) (*splitRequestState, []error) {
 	req := auctionReq.BidRequestWrapper
 	if err := PreloadExts(req); err != nil {
-		return
+		return nil, nil
 	}
 
 	requestAliases, requestAliasesGVLIDs, errs := getRequestAliases(req)
 	if len(errs) > 0 {
-		return
+		return nil, errs
 	}
 
 	bidderImpWithBidResp := stored_responses.InitStoredBidResponses(req.BidRequest, auctionReq.StoredBidResponses)
@@ -78,14 +134,12 @@ func (rs *requestSplitter) cleanOpenRTBRequests(ctx context.Context,
 
 	impsByBidder, err := splitImps(req.BidRequest.Imp, rs.requestValidator, requestAliases, hasStoredAuctionResponses, auctionReq.StoredBidResponses)
 	if err != nil {
-		errs = []error{err}
-		return
+		return nil, []error{err}
 	}
 
 	explicitBuyerUIDs, err := extractAndCleanBuyerUIDs(req)
 	if err != nil {
-		errs = []error{err}
-		return
+		return nil, []error{err}
 	}
 
 	lowerCaseExplicitBuyerUIDs := make(map[string]string)
@@ -96,16 +150,174 @@ func (rs *requestSplitter) cleanOpenRTBRequests(ctx context.Context,
 
 	bidderParamsInReqExt, err := ExtractReqExtBidderParamsMap(req.BidRequest)
 	if err != nil {
-		errs = []error{err}
-		return
+		return nil, []error{err}
 	}
 
 	sChainWriter, err := schain.NewSChainWriter(requestExt, rs.hostSChainNode)
 	if err != nil {
-		errs = []error{err}
-		return
+		return nil, []error{err}
+	}
+
+	reqPrivacy, privacyErrs := rs.readRequestPrivacy(auctionReq, requestAliases, requestAliasesGVLIDs, gdprSignal, gdprEnforced)
+
+	return &splitRequestState{
+		auctionReq:                 auctionReq,
+		requestAliases:             requestAliases,
+		impsByBidder:               impsByBidder,
+		bidderImpWithBidResp:       bidderImpWithBidResp,
+		lowerCaseExplicitBuyerUIDs: lowerCaseExplicitBuyerUIDs,
+		bidderParamsInReqExt:       bidderParamsInReqExt,
+		sChainWriter:               sChainWriter,
+		reqPrivacy:                 reqPrivacy,
+		syncedEIDs:                 buildSyncedEIDs(rs.syncerEIDs, auctionReq.UserSyncs),
+		gdprEnforced:               gdprEnforced,
+		bidAdjustmentFactors:       bidAdjustmentFactors,
+	}, privacyErrs
+}
+
+// bidderPrivacyRecorder records the privacy enforcement applied to a bidder request
+type bidderPrivacyRecorder interface {
+	beforeScrub(reqWrapper *openrtb_ext.RequestWrapper)
+	afterScrub(reqWrapper *openrtb_ext.RequestWrapper)
+	blocked()
+}
+
+// newPrivacyRecorderFunc starts the privacy recording of a bidder request
+type newPrivacyRecorderFunc func(reqWrapper *openrtb_ext.RequestWrapper, bidder string, coreBidder openrtb_ext.BidderName) bidderPrivacyRecorder
+
+// buildBidderRequest builds the request of the bidder, enforcing the privacy policies and recording them
+// with the recorder. It reports false when no request is sent to the bidder, either because privacy
+// blocks it or because of the returned error.
+func (rs *requestSplitter) buildBidderRequest(ctx context.Context, split *splitRequestState, bidder string, imps []openrtb2.Imp, newPrivacyRecorder newPrivacyRecorderFunc) (BidderRequest, bool, error) {
+	auctionReq := split.auctionReq
+	reqPrivacy := split.reqPrivacy
+	req := auctionReq.BidRequestWrapper
+
+	fpdUserEIDsPresent := fpdUserEIDExists(req, auctionReq.FirstPartyData, bidder)
+	reqWrapperCopy := req.CloneAndClearImpWrappers()
+	bidRequestCopy := *req.BidRequest
+	reqWrapperCopy.BidRequest = &bidRequestCopy
+	reqWrapperCopy.Imp = imps
+
+	coreBidder, isRequestAlias := resolveBidder(bidder, split.requestAliases)
+
+	// apply bidder-specific schains
+	split.sChainWriter.Write(reqWrapperCopy, bidder)
+
+	auctionPermissions := reqPrivacy.gdprPerms.AuctionActivitiesAllowed(ctx, coreBidder, openrtb_ext.BidderName(bidder))
+
+	// add the eids of the other bidders' synced UIDs, before the eid permissions are enforced. The
+	// privacy scrubbing only removes user.ext.eids, so they're left out when the user ids can't be passed.
+	syncerKey := rs.bidderToSyncerKey[string(coreBidder)]
+	if auctionPermissions.PassID && !reqPrivacy.ccpaEnforcer.ShouldEnforce(bidder) && !reqPrivacy.lmt && !reqPrivacy.coppa {
+		appendSyncedEIDs(reqWrapperCopy.BidRequest, syncerKey, split.syncedEIDs)
+	}
+
+	privacyRecorder := newPrivacyRecorder(reqWrapperCopy, bidder, coreBidder)
+
+	// eid scrubbing
+	privacyRecorder.beforeScrub(reqWrapperCopy)
+	if err := removeUnpermissionedEids(reqWrapperCopy, bidder); err != nil {
+		return BidderRequest{}, false, fmt.Errorf("unable to enforce request.ext.prebid.data.eidpermissions because %v", err)
+	}
+	privacyRecorder.afterScrub(reqWrapperCopy)
+
+	// generate bidder-specific request ext
+	if err := buildRequestExtForBidder(bidder, reqWrapperCopy, split.bidderParamsInReqExt, auctionReq.Account.AlternateBidderCodes); err != nil {
+		return BidderRequest{}, false, err
+	}
+
+	// apply bid adjustments
+	if auctionReq.Account.PriceFloors.IsAdjustForBidAdjustmentEnabled() {
+		applyBidAdjustmentToFloor(reqWrapperCopy, bidder, split.bidAdjustmentFactors)
+	}
+
+	// prepare user
+	hadSync := prepareUser(reqWrapperCopy, bidder, syncerKey, split.lowerCaseExplicitBuyerUIDs, auctionReq.UserSyncs)
+
+	// privacy blocking
+	if rs.isBidderBlockedByPrivacy(reqWrapperCopy, auctionReq.Activities, auctionPermissions, coreBidder, openrtb_ext.BidderName(bidder)) {
+		privacyRecorder.blocked()
+		return BidderRequest{}, false, nil
+	}
+
+	// fpd
+	applyFPD(auctionReq.FirstPartyData, coreBidder, openrtb_ext.BidderName(bidder), isRequestAlias, reqWrapperCopy, fpdUserEIDsPresent)
+
+	// privacy scrubbing
+	privacyRecorder.beforeScrub(reqWrapperCopy)
+	if err := rs.applyPrivacy(reqWrapperCopy, coreBidder, bidder, auctionReq, auctionPermissions, reqPrivacy.ccpaEnforcer, reqPrivacy.lmt, reqPrivacy.coppa); err != nil {
+		return BidderRequest{}, false, err
+	}
+	privacyRecorder.afterScrub(reqWrapperCopy)
+
+	// GPP downgrade: always downgrade unless we can confirm GPP is supported
+	if shouldSetLegacyPrivacy(rs.bidderInfo, string(coreBidder)) {
+		setLegacyGDPRFromGPP(reqWrapperCopy, reqPrivacy.gpp)
+		setLegacyUSPFromGPP(reqWrapperCopy, reqPrivacy.gpp)
+	}
+
+	// remove imps with stored responses so they aren't sent to the bidder
+	if impResponses, ok := split.bidderImpWithBidResp[openrtb_ext.BidderName(bidder)]; ok {
+		removeImpsWithStoredResponses(reqWrapperCopy, impResponses)
 	}
 
+	// down convert
+	info, ok := rs.bidderInfo[bidder]
+	if !ok || info.OpenRTB == nil || info.OpenRTB.Version != "2.6" {
+		reqWrapperCopy.Regs = ortb.CloneRegs(reqWrapperCopy.Regs)
+		if err := openrtb_ext.ConvertDownTo25(reqWrapperCopy); err != nil {
+			return BidderRequest{}, false, err
+		}
+	}
+
+	// sync wrapper
+	if err := reqWrapperCopy.RebuildRequest(); err != nil {
+		return BidderRequest{}, false, err
+	}
+
+	// choose labels
+	bidderLabels := metrics.AdapterLabels{
+		Adapter: coreBidder,
+	}
+	if !hadSync && req.BidRequest.App == nil {
+		bidderLabels.CookieFlag = metrics.CookieFlagNo
+	} else {
+		bidderLabels.CookieFlag = metrics.CookieFlagYes
+	}
+	if len(reqWrapperCopy.Imp) > 0 {
+		bidderLabels.Source = auctionReq.LegacyLabels.Source
+		bidderLabels.RType = auctionReq.LegacyLabels.RType
+		bidderLabels.PubID = auctionReq.LegacyLabels.PubID
+		bidderLabels.CookieFlag = auctionReq.LegacyLabels.CookieFlag
+		bidderLabels.AdapterBids = metrics.AdapterBidPresent
+	}
+
+	return BidderRequest{
+		BidderName:            openrtb_ext.BidderName(bidder),
+		BidderCoreName:        coreBidder,
+		BidRequest:            reqWrapperCopy.BidRequest,
+		IsRequestAlias:        isRequestAlias,
+		BidderStoredResponses: split.bidderImpWithBidResp[openrtb_ext.BidderName(bidder)],
+		ImpReplaceImpId:       auctionReq.BidderImpReplaceImpID[bidder],
+		BidderLabels:          bidderLabels,
+	}, true, nil
+}
+
+// requestPrivacy holds the request level privacy policies enforced for every bidder
+type requestPrivacy struct {
+	gpp          gpplib.GppContainer
+	ccpaEnforcer privacy.PolicyEnforcer
+	lmt          bool
+	coppa        bool
+	gdprPerms    gdpr.Permissions
+	labels       metrics.PrivacyLabels
+}
+
+// readRequestPrivacy reads the privacy signals of the request and builds the GDPR permissions
+func (rs *requestSplitter) readRequestPrivacy(auctionReq AuctionRequest, requestAliases map[string]string, requestAliasesGVLIDs map[string]uint16, gdprSignal gdpr.Signal, gdprEnforced bool) (reqPrivacy requestPrivacy, errs []error) {
+	req := auctionReq.BidRequestWrapper
+
 	var gpp gpplib.GppContainer
 	if req.BidRequest.Regs != nil && len(req.BidRequest.Regs.GPP) > 0 {
 		var gppErrs []error
@@ -131,19 +343,19 @@ func (rs *requestSplitter) cleanOpenRTBRequests(ctx context.Context,
 	coppa := req.BidRequest.Regs != nil && req.BidRequest.Regs.COPPA == 1
 	lmt := lmtEnforcer.ShouldEnforce(unknownBidder)
 
-	privacyLabels.CCPAProvided = ccpaEnforcer.CanEnforce()
-	privacyLabels.CCPAEnforced = ccpaEnforcer.ShouldEnforce(unknownBidder)
-	privacyLabels.COPPAEnforced = coppa
-	privacyLabels.LMTEnforced = lmt
+	reqPrivacy.labels.CCPAProvided = ccpaEnforcer.CanEnforce()
+	reqPrivacy.labels.CCPAEnforced = ccpaEnforcer.ShouldEnforce(unknownBidder)
+	reqPrivacy.labels.COPPAEnforced = coppa
+	reqPrivacy.labels.LMTEnforced = lmt
 
 	var gdprPerms gdpr.Permissions = &gdpr.AlwaysAllow{}
 
 	if gdprEnforced {
-		privacyLabels.GDPREnforced = true
+		reqPrivacy.labels.GDPREnforced = true
 		parsedConsent, err := vendorconsent.ParseString(consent)
 		if err == nil {
 			version := int(parsedConsent.Version())
-			privacyLabels.GDPRTCFVersion = metrics.TCFVersionToValue(version)
+			reqPrivacy.labels.GDPRTCFVersion = metrics.TCFVersionToValue(version)
 		}
 
 		gdprRequestInfo := gdpr.RequestInfo{
@@ -155,114 +367,11 @@ func (rs *requestSplitter) cleanOpenRTBRequests(ctx context.Context,
 		gdprPerms = rs.gdprPermsBuilder(auctionReq.TCF2Config, gdprRequestInfo)
 	}
 
-	bidderRequests = make([]BidderRequest, 0, len(impsByBidder))
-
-	for bidder, imps := range impsByBidder {
-		fpdUserEIDsPresent := fpdUserEIDExists(req, auctionReq.FirstPartyData, bidder)
-		reqWrapperCopy := req.CloneAndClearImpWrappers()
-		bidRequestCopy := *req.BidRequest
-		reqWrapperCopy.BidRequest = &bidRequestCopy
-		reqWrapperCopy.Imp = imps
-
-		coreBidder, isRequestAlias := resolveBidder(bidder, requestAliases)
-
-		// apply bidder-specific schains
-		sChainWriter.Write(reqWrapperCopy, bidder)
-
-		// eid scrubbing
-		if err := removeUnpermissionedEids(reqWrapperCopy, bidder); err != nil {
-			errs = append(errs, fmt.Errorf("unable to enforce request.ext.prebid.data.eidpermissions because %v", err))
-			continue
-		}
-
-		// generate bidder-specific request ext
-		err = buildRequestExtForBidder(bidder, reqWrapperCopy, bidderParamsInReqExt, auctionReq.Account.AlternateBidderCodes)
-		if err != nil {
-			errs = append(errs, err)
-			continue
-		}
-
-		// apply bid adjustments
-		if auctionReq.Account.PriceFloors.IsAdjustForBidAdjustmentEnabled() {
-			applyBidAdjustmentToFloor(reqWrapperCopy, bidder, bidAdjustmentFactors)
-		}
-
-		// prepare user
-		syncerKey := rs.bidderToSyncerKey[string(coreBidder)]
-		hadSync := prepareUser(reqWrapperCopy, bidder, syncerKey, lowerCaseExplicitBuyerUIDs, auctionReq.UserSyncs)
-
-		auctionPermissions := gdprPerms.AuctionActivitiesAllowed(ctx, coreBidder, openrtb_ext.BidderName(bidder))
-
-		// privacy blocking
-		if rs.isBidderBlockedByPrivacy(reqWrapperCopy, auctionReq.Activities, auctionPermissions, coreBidder, openrtb_ext.BidderName(bidder)) {
-			continue
-		}
-
-		// fpd
-		applyFPD(auctionReq.FirstPartyData, coreBidder, openrtb_ext.BidderName(bidder), isRequestAlias, reqWrapperCopy, fpdUserEIDsPresent)
-
-		// privacy scrubbing
-		if err := rs.applyPrivacy(reqWrapperCopy, coreBidder, bidder, auctionReq, auctionPermissions, ccpaEnforcer, lmt, coppa); err != nil {
-			errs = append(errs, err)
-			continue
-		}
-
-		// GPP downgrade: always downgrade unless we can confirm GPP is supported
-		if shouldSetLegacyPrivacy(rs.bidderInfo, string(coreBidder)) {
-			setLegacyGDPRFromGPP(reqWrapperCopy, gpp)
-			setLegacyUSPFromGPP(reqWrapperCopy, gpp)
-		}
-
-		// remove imps with stored responses so they aren't sent to the bidder
-		if impResponses, ok := bidderImpWithBidResp[openrtb_ext.BidderName(bidder)]; ok {
-			removeImpsWithStoredResponses(reqWrapperCopy, impResponses)
-		}
-
-		// down convert
-		info, ok := rs.bidderInfo[bidder]
-		if !ok || info.OpenRTB == nil || info.OpenRTB.Version != "2.6" {
-			reqWrapperCopy.Regs = ortb.CloneRegs(reqWrapperCopy.Regs)
-			if err := openrtb_ext.ConvertDownTo25(reqWrapperCopy); err != nil {
-				errs = append(errs, err)
-				continue
-			}
-		}
-
-		// sync wrapper
-		if err := reqWrapperCopy.RebuildRequest(); err != nil {
-			errs = append(errs, err)
-			continue
-		}
-
-		// choose labels
-		bidderLabels := metrics.AdapterLabels{
-			Adapter: coreBidder,
-		}
-		if !hadSync && req.BidRequest.App == nil {
-			bidderLabels.CookieFlag = metrics.CookieFlagNo
-		} else {
-			bidderLabels.CookieFlag = metrics.CookieFlagYes
-		}
-		if len(reqWrapperCopy.Imp) > 0 {
-			bidderLabels.Source = auctionReq.LegacyLabels.Source
-			bidderLabels.RType = auctionReq.LegacyLabels.RType
-			bidderLabels.PubID = auctionReq.LegacyLabels.PubID
-			bidderLabels.CookieFlag = auctionReq.LegacyLabels.CookieFlag
-			bidderLabels.AdapterBids = metrics.AdapterBidPresent
-		}
-
-		bidderRequest := BidderRequest{
-			BidderName:            openrtb_ext.BidderName(bidder),
-			BidderCoreName:        coreBidder,
-			BidRequest:            reqWrapperCopy.BidRequest,
-			IsRequestAlias:        isRequestAlias,
-			BidderStoredResponses: bidderImpWithBidResp[openrtb_ext.BidderName(bidder)],
-			ImpReplaceImpId:       auctionReq.BidderImpReplaceImpID[bidder],
-			BidderLabels:          bidderLabels,
-		}
-		bidderRequests = append(bidderRequests, bidderRequest)
-	}
-
+	reqPrivacy.gpp = gpp
+	reqPrivacy.ccpaEnforcer = ccpaEnforcer
+	reqPrivacy.lmt = lmt
+	reqPrivacy.coppa = coppa
+	reqPrivacy.gdprPerms = gdprPerms
 	return
 }
 
@@ -408,6 +517,13 @@ func (rs *requestSplitter) applyPrivacy(reqWrapper *openrtb_ext.RequestWrapper,
 		privacy.ScrubDeviceIDsIPsUserDemoExt(reqWrapper, ipConf, "eids", coppa)
 	}
 
+	passEIDsAllowed := auctionReq.Activities.Allow(privacy.ActivityTransmitEIDs, scope, privacy.NewRequestFromBidRequest(*reqWrapper))
+	if !passEIDsAllowed {
+		if err := privacy.ScrubEIDs(reqWrapper); err != nil {
+			return err
+		}
+	}
+
 	passTIDAllowed := auctionReq.Activities.Allow(privacy.ActivityTransmitTIDs, scope, privacy.NewRequestFromBidRequest(*reqWrapper))
 	if !passTIDAllowed {
 		privacy.ScrubTID(reqWrapper)
@@ -2,6 +2,7 @@ package floors
 
 import (
 	"fmt"
+	"maps"
 	"strings"
 
 	"github.com/prebid/prebid-server/v3/config"
@@ -117,3 +118,28 @@ func selectValidFloorModelGroups(modelGroups []openrtb_ext.PriceFloorModelGroup,
 	}
 	return validModelGroups, errs
 }
+
+// Validate returns the errors of floors rules, for the account. The auction reports them as warnings and
+// ignores the invalid rules, model groups or floors.
+func Validate(floors *openrtb_ext.PriceFloorRules, account config.Account) []error {
+	if floors == nil {
+		return nil
+	}
+	if err := validateFloorParams(floors); err != nil {
+		return []error{err}
+	}
+	if floors.Data == nil {
+		return nil
+	}
+
+	validModelGroups, errs := selectValidFloorModelGroups(floors.Data.ModelGroups, account)
+	for _, modelGroup := range validModelGroups {
+		delimiter := modelGroup.Schema.Delimiter
+		if delimiter == "" {
+			delimiter = defaultDelimiter
+		}
+		// the invalid rules are dropped from the values, which mustn't change
+		errs = append(errs, validateFloorRulesAndLowerValidRuleKey(modelGroup.Schema, delimiter, maps.Clone(modelGroup.Values))...)
+	}
+	return errs
+}
@@ -3,6 +3,7 @@ package floors
 import (
 	"errors"
 	"fmt"
+	"maps"
 	"testing"
 
 	"github.com/prebid/prebid-server/v3/config"
@@ -428,3 +429,55 @@ func TestValidateSchemaDimensions(t *testing.T) {
 		})
 	}
 }
+
+func TestValidate(t *testing.T) {
+	tt := []struct {
+		name   string
+		floors *openrtb_ext.PriceFloorRules
+		errs   []error
+	}{
+		{
+			name: "No Floors",
+		},
+		{
+			name:   "Invalid Params",
+			floors: &openrtb_ext.PriceFloorRules{FloorMin: -1},
+			errs:   []error{errors.New("Invalid FloorMin = '-1', value should be >= 0")},
+		},
+		{
+			name: "Invalid Model Group And Rule",
+			floors: &openrtb_ext.PriceFloorRules{Data: &openrtb_ext.PriceFloorData{
+				ModelGroups: []openrtb_ext.PriceFloorModelGroup{
+					{
+						ModelVersion: "Version 1",
+						Schema:       openrtb_ext.PriceFloorSchema{Fields: []string{"mediaType", "size"}},
+						Values: map[string]float64{
+							"Banner|300x250":   1.01,
+							"banner|300x250|*": 2.01,
+						},
+					},
+					{
+						ModelVersion: "Version 2",
+						Schema:       openrtb_ext.PriceFloorSchema{Fields: []string{"unknown"}},
+					},
+				}}},
+			errs: []error{
+				errors.New("Invalid schema dimension provided = 'unknown' in Schema Fields = '[unknown]'"),
+				errors.New("Invalid Floor Rule = 'banner|300x250|*' for Schema Fields = '[mediaType size]'"),
+			},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			var values map[string]float64
+			if tc.floors != nil && tc.floors.Data != nil {
+				values = maps.Clone(tc.floors.Data.ModelGroups[0].Values)
+			}
+			assert.Equal(t, tc.errs, Validate(tc.floors, config.Account{}))
+			if values != nil {
+				assert.Equal(t, values, tc.floors.Data.ModelGroups[0].Values, "The floors shouldn't be changed")
+			}
+		})
+	}
+}
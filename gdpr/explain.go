@@ -0,0 +1,72 @@
+package gdpr
+
+import (
+	"context"
+
+	"github.com/prebid/prebid-server/v3/openrtb_ext"
+)
+
+// Reasons reported by the permissions explanations
+const (
+	ReasonNotApplicable         = "gdpr_not_applicable"
+	ReasonNonStandardPublisher  = "non_standard_publisher"
+	ReasonConsentMissing        = "consent_missing"
+	ReasonConsentMalformed      = "consent_malformed"
+	ReasonVendorListUnavailable = "vendor_list_unavailable"
+	ReasonConsent               = "consent"
+	ReasonNotEnforced           = "not_enforced"
+	ReasonVendorException       = "vendor_exception"
+	ReasonLegalBasis            = "legal_basis"
+	ReasonNoLegalBasis          = "no_legal_basis"
+	ReasonNoOptIn               = "no_special_feature_opt_in"
+	ReasonVendorNotDeclared     = "vendor_not_declared"
+)
+
+// PermissionsExplainer is implemented by the Permissions able to explain their auction decisions.
+type PermissionsExplainer interface {
+	ExplainAuctionActivities(ctx context.Context, bidderCoreName openrtb_ext.BidderName, bidder openrtb_ext.BidderName) AuctionPermissionsExplanation
+}
+
+// AuctionPermissionsExplanation details how the auction permissions of a bidder were computed.
+type AuctionPermissionsExplanation struct {
+	// Reason explains the request level outcome, the permission decisions are only computed from the
+	// consent string when the reason is ReasonConsent.
+	Reason     string             `json:"reason"`
+	VendorID   uint16             `json:"vendorId,omitempty"`
+	BidRequest PermissionDecision `json:"bidRequest"`
+	Geo        PermissionDecision `json:"geo"`
+	ID         PermissionDecision `json:"id"`
+}
+
+// PermissionDecision identifies the purpose or special feature which was decisive for a permission.
+type PermissionDecision struct {
+	Allowed         bool   `json:"allowed"`
+	Purpose         int    `json:"purpose,omitempty"`
+	SpecialFeature  int    `json:"specialFeature,omitempty"`
+	EnforceAlgo     string `json:"enforceAlgo,omitempty"`
+	VendorException bool   `json:"vendorException,omitempty"`
+	Reason          string `json:"reason"`
+}
+
+// Permissions returns the auction permissions explained.
+func (e AuctionPermissionsExplanation) Permissions() AuctionPermissions {
+	return AuctionPermissions{
+		AllowBidRequest: e.BidRequest.Allowed,
+		PassGeo:         e.Geo.Allowed,
+		PassID:          e.ID.Allowed,
+	}
+}
+
+func allowAllExplanation(reason string) AuctionPermissionsExplanation {
+	return AuctionPermissionsExplanation{
+		Reason:     reason,
+		BidRequest: PermissionDecision{Allowed: true, Reason: reason},
+		Geo:        PermissionDecision{Allowed: true, Reason: reason},
+		ID:         PermissionDecision{Allowed: true, Reason: reason},
+	}
+}
+
+// ExplainAuctionActivities always allows all auction activities
+func (a AlwaysAllow) ExplainAuctionActivities(ctx context.Context, bidderCoreName openrtb_ext.BidderName, bidder openrtb_ext.BidderName) AuctionPermissionsExplanation {
+	return allowAllExplanation(ReasonNotApplicable)
+}
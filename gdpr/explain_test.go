@@ -0,0 +1,134 @@
+package gdpr
+
+import (
+	"context"
+	"testing"
+
+	"github.com/prebid/go-gdpr/consentconstants"
+	"github.com/prebid/go-gdpr/vendorlist"
+	"github.com/prebid/prebid-server/v3/config"
+	"github.com/prebid/prebid-server/v3/openrtb_ext"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExplainAuctionActivities(t *testing.T) {
+	bidder := openrtb_ext.BidderPangle
+	purpose2Consent := "CPuDXznPuDXznMOAAAENCZCAAEAAAAAAAAAAAAAAAAAA"
+
+	tests := []struct {
+		name                  string
+		consent               string
+		gdprSignal            Signal
+		publisherID           string
+		vendorExceptions      map[string]struct{}
+		vendorListUnavailable bool
+		expected              AuctionPermissionsExplanation
+	}{
+		{
+			name:       "gdpr_not_applicable",
+			gdprSignal: SignalNo,
+			expected:   allowAllExplanation(ReasonNotApplicable),
+		},
+		{
+			name:        "non_standard_publisher",
+			gdprSignal:  SignalYes,
+			publisherID: "nonStandardPub",
+			expected:    allowAllExplanation(ReasonNonStandardPublisher),
+		},
+		{
+			name:       "consent_missing",
+			gdprSignal: SignalYes,
+			expected: AuctionPermissionsExplanation{
+				Reason:     ReasonConsentMissing,
+				BidRequest: PermissionDecision{Purpose: 2, Reason: ReasonConsentMissing},
+				Geo:        PermissionDecision{SpecialFeature: 1, Reason: ReasonConsentMissing},
+				ID:         PermissionDecision{Reason: ReasonConsentMissing},
+			},
+		},
+		{
+			name:       "consent_malformed",
+			gdprSignal: SignalYes,
+			consent:    "malformed",
+			expected: AuctionPermissionsExplanation{
+				Reason:     ReasonConsentMalformed,
+				BidRequest: PermissionDecision{Purpose: 2, Reason: ReasonConsentMalformed},
+				Geo:        PermissionDecision{SpecialFeature: 1, Reason: ReasonConsentMalformed},
+				ID:         PermissionDecision{Reason: ReasonConsentMalformed},
+			},
+		},
+		{
+			name:                  "vendor_list_unavailable",
+			gdprSignal:            SignalYes,
+			consent:               purpose2Consent,
+			vendorListUnavailable: true,
+			expected: AuctionPermissionsExplanation{
+				Reason:     ReasonVendorListUnavailable,
+				BidRequest: PermissionDecision{Purpose: 2, Reason: ReasonVendorListUnavailable},
+				Geo:        PermissionDecision{SpecialFeature: 1, Reason: ReasonVendorListUnavailable},
+				ID:         PermissionDecision{Reason: ReasonVendorListUnavailable},
+			},
+		},
+		{
+			name:       "no_legal_basis",
+			gdprSignal: SignalYes,
+			consent:    purpose2Consent,
+			expected: AuctionPermissionsExplanation{
+				Reason:     ReasonConsent,
+				BidRequest: PermissionDecision{Purpose: 2, EnforceAlgo: config.TCF2EnforceAlgoFull, Reason: ReasonNoLegalBasis},
+				Geo:        PermissionDecision{SpecialFeature: 1, Reason: ReasonNoOptIn},
+				ID:         PermissionDecision{Reason: ReasonNoLegalBasis},
+			},
+		},
+		{
+			name:             "vendor_exception",
+			gdprSignal:       SignalYes,
+			consent:          purpose2Consent,
+			vendorExceptions: map[string]struct{}{string(bidder): {}},
+			expected: AuctionPermissionsExplanation{
+				Reason:     ReasonConsent,
+				BidRequest: PermissionDecision{Allowed: true, Purpose: 2, EnforceAlgo: config.TCF2EnforceAlgoFull, VendorException: true, Reason: ReasonVendorException},
+				Geo:        PermissionDecision{SpecialFeature: 1, Reason: ReasonNoOptIn},
+				ID:         PermissionDecision{Allowed: true, Purpose: 2, EnforceAlgo: config.TCF2EnforceAlgoFull, VendorException: true, Reason: ReasonVendorException},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tcf2AggConfig := allPurposesEnabledTCF2Config()
+			tcf2AggConfig.HostConfig.Purpose2.VendorExceptionMap = tt.vendorExceptions
+			tcf2AggConfig.HostConfig.Purpose2.EnforceAlgoID = config.TCF2FullEnforcement
+			tcf2AggConfig.HostConfig.PurposeConfigs[consentconstants.Purpose(2)] = &tcf2AggConfig.HostConfig.Purpose2
+
+			vendorLists := map[uint16]map[uint16]vendorlist.VendorList{
+				2: {
+					153: parseVendorListDataV2(t, MarshalVendorList(vendorList{GVLSpecificationVersion: 2, VendorListVersion: 153, Vendors: map[string]*vendor{}})),
+				},
+			}
+			if tt.vendorListUnavailable {
+				vendorLists = nil
+			}
+
+			perms := permissionsImpl{
+				cfg:                    &tcf2AggConfig,
+				consent:                tt.consent,
+				gdprSignal:             tt.gdprSignal,
+				publisherID:            tt.publisherID,
+				nonStandardPublishers:  map[string]struct{}{"nonStandardPub": {}},
+				vendorIDs:              map[openrtb_ext.BidderName]uint16{},
+				fetchVendorList:        listFetcher(vendorLists),
+				purposeEnforcerBuilder: NewPurposeEnforcerBuilder(&tcf2AggConfig),
+			}
+
+			explanation := perms.ExplainAuctionActivities(context.Background(), bidder, bidder)
+			assert.Equal(t, tt.expected, explanation)
+			assert.Equal(t, perms.AuctionActivitiesAllowed(context.Background(), bidder, bidder), explanation.Permissions())
+		})
+	}
+}
+
+func TestAlwaysAllowExplainAuctionActivities(t *testing.T) {
+	explanation := AlwaysAllow{}.ExplainAuctionActivities(context.Background(), openrtb_ext.BidderAppnexus, openrtb_ext.BidderAppnexus)
+	assert.Equal(t, AllowAll, explanation.Permissions())
+	assert.Equal(t, ReasonNotApplicable, explanation.Reason)
+}
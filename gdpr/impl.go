@@ -6,6 +6,7 @@ import (
 	"github.com/prebid/go-gdpr/api"
 	"github.com/prebid/go-gdpr/consentconstants"
 	tcf2 "github.com/prebid/go-gdpr/vendorconsent/tcf2"
+	"github.com/prebid/prebid-server/v3/config"
 	"github.com/prebid/prebid-server/v3/openrtb_ext"
 )
 
@@ -57,34 +58,45 @@ func (p *permissionsImpl) BidderSyncAllowed(ctx context.Context, bidder openrtb_
 
 // AuctionActivitiesAllowed determines whether auction activities are permitted for a given bidder
 func (p *permissionsImpl) AuctionActivitiesAllowed(ctx context.Context, bidderCoreName openrtb_ext.BidderName, bidder openrtb_ext.BidderName) AuctionPermissions {
+	return p.auctionActivities(ctx, bidderCoreName, bidder).Permissions()
+}
+
+// ExplainAuctionActivities determines the auction permissions of a given bidder and the reasons behind them
+func (p *permissionsImpl) ExplainAuctionActivities(ctx context.Context, bidderCoreName openrtb_ext.BidderName, bidder openrtb_ext.BidderName) AuctionPermissionsExplanation {
+	return p.auctionActivities(ctx, bidderCoreName, bidder)
+}
+
+func (p *permissionsImpl) auctionActivities(ctx context.Context, bidderCoreName openrtb_ext.BidderName, bidder openrtb_ext.BidderName) AuctionPermissionsExplanation {
 	if _, ok := p.nonStandardPublishers[p.publisherID]; ok {
-		return AllowAll
+		return allowAllExplanation(ReasonNonStandardPublisher)
 	}
 
 	if p.gdprSignal != SignalYes {
-		return AllowAll
+		return allowAllExplanation(ReasonNotApplicable)
 	}
 
 	if p.consent == "" {
-		return p.defaultPermissions()
+		return p.defaultPermissions(ReasonConsentMissing)
 	}
 
 	pc, err := parseConsent(p.consent)
 	if err != nil {
-		return p.defaultPermissions()
+		return p.defaultPermissions(ReasonConsentMalformed)
 	}
 
 	vendorID, _ := p.resolveVendorID(bidderCoreName, bidder)
 	vendor, err := p.getVendor(ctx, vendorID, *pc)
 	if err != nil {
-		return p.defaultPermissions()
+		return p.defaultPermissions(ReasonVendorListUnavailable)
 	}
 
 	vendorInfo := VendorInfo{vendorID: vendorID, vendor: vendor}
-	return AuctionPermissions{
-		AllowBidRequest: p.allowBidRequest(bidderCoreName, pc.consentMeta, vendorInfo),
-		PassGeo:         p.allowGeo(bidderCoreName, pc.consentMeta, vendor),
-		PassID:          p.allowID(bidderCoreName, pc.consentMeta, vendorInfo),
+	return AuctionPermissionsExplanation{
+		Reason:     ReasonConsent,
+		VendorID:   vendorID,
+		BidRequest: p.allowBidRequest(bidderCoreName, pc.consentMeta, vendorInfo),
+		Geo:        p.allowGeo(bidderCoreName, pc.consentMeta, vendor),
+		ID:         p.allowID(bidderCoreName, pc.consentMeta, vendorInfo),
 	}
 }
 
@@ -92,14 +104,19 @@ func (p *permissionsImpl) AuctionActivitiesAllowed(ctx context.Context, bidderCo
 // allowing passing geo information and sending bid requests based on whether purpose 2
 // and feature one are enforced respectively
 // if the consent string is empty or malformed we should use the default permissions
-func (p *permissionsImpl) defaultPermissions() AuctionPermissions {
-	perms := AuctionPermissions{}
+func (p *permissionsImpl) defaultPermissions(reason string) AuctionPermissionsExplanation {
+	perms := AuctionPermissionsExplanation{
+		Reason:     reason,
+		BidRequest: PermissionDecision{Purpose: 2, Reason: reason},
+		Geo:        PermissionDecision{SpecialFeature: 1, Reason: reason},
+		ID:         PermissionDecision{Reason: reason},
+	}
 
 	if !p.cfg.PurposeEnforced(consentconstants.Purpose(2)) {
-		perms.AllowBidRequest = true
+		perms.BidRequest = PermissionDecision{Allowed: true, Purpose: 2, Reason: ReasonNotEnforced}
 	}
 	if !p.cfg.FeatureOneEnforced() {
-		perms.PassGeo = true
+		perms.Geo = PermissionDecision{Allowed: true, SpecialFeature: 1, Reason: ReasonNotEnforced}
 	}
 	return perms
 }
@@ -151,36 +168,51 @@ func (p *permissionsImpl) allowSync(ctx context.Context, vendorID uint16, bidder
 
 // allowBidRequest computes legal basis for a given bidder using the enforcement algorithms selected
 // by the purpose enforcer builder
-func (p *permissionsImpl) allowBidRequest(bidder openrtb_ext.BidderName, consentMeta tcf2.ConsentMetadata, vendorInfo VendorInfo) bool {
-	enforcer := p.purposeEnforcerBuilder(consentconstants.Purpose(2), string(bidder))
+func (p *permissionsImpl) allowBidRequest(bidder openrtb_ext.BidderName, consentMeta tcf2.ConsentMetadata, vendorInfo VendorInfo) PermissionDecision {
+	purpose := consentconstants.Purpose(2)
+	enforcer := p.purposeEnforcerBuilder(purpose, string(bidder))
 
 	overrides := Overrides{}
 	if _, ok := enforcer.(*BasicEnforcement); ok {
 		overrides.allowLITransparency = true
 	}
-	return enforcer.LegalBasis(vendorInfo, string(bidder), consentMeta, overrides)
+	allowed := enforcer.LegalBasis(vendorInfo, string(bidder), consentMeta, overrides)
+	return p.purposeDecision(allowed, purpose, bidder, enforcer)
 }
 
 // allowGeo computes legal basis for a given bidder using the configs, consent and GVL pertaining to
 // feature one
-func (p *permissionsImpl) allowGeo(bidder openrtb_ext.BidderName, consentMeta tcf2.ConsentMetadata, vendor api.Vendor) bool {
+func (p *permissionsImpl) allowGeo(bidder openrtb_ext.BidderName, consentMeta tcf2.ConsentMetadata, vendor api.Vendor) PermissionDecision {
+	decision := PermissionDecision{SpecialFeature: 1}
 	if !p.cfg.FeatureOneEnforced() {
-		return true
+		decision.Allowed, decision.Reason = true, ReasonNotEnforced
+		return decision
 	}
 	if p.cfg.FeatureOneVendorException(bidder) {
-		return true
+		decision.Allowed, decision.Reason, decision.VendorException = true, ReasonVendorException, true
+		return decision
 	}
 
 	basicEnforcementVendors := p.cfg.BasicEnforcementVendors()
 	_, weakVendorEnforcement := basicEnforcementVendors[string(bidder)]
-	return consentMeta.SpecialFeatureOptIn(1) && ((vendor != nil && vendor.SpecialFeature(1)) || weakVendorEnforcement)
+	switch {
+	case !consentMeta.SpecialFeatureOptIn(1):
+		decision.Reason = ReasonNoOptIn
+	case weakVendorEnforcement:
+		decision.Allowed, decision.Reason, decision.EnforceAlgo = true, ReasonLegalBasis, config.TCF2EnforceAlgoBasic
+	case vendor != nil && vendor.SpecialFeature(1):
+		decision.Allowed, decision.Reason = true, ReasonLegalBasis
+	default:
+		decision.Reason = ReasonVendorNotDeclared
+	}
+	return decision
 }
 
 // allowID computes the pass user ID activity legal basis for a given bidder using the enforcement algorithms
 // selected by the purpose enforcer builder. For the user ID activity, the selected enforcement algorithm must
 // always assume we are enforcing the purpose.
 // If the purpose for which we are computing legal basis is purpose 2, the algorithm should allow LI transparency.
-func (p *permissionsImpl) allowID(bidder openrtb_ext.BidderName, consentMeta tcf2.ConsentMetadata, vendorInfo VendorInfo) bool {
+func (p *permissionsImpl) allowID(bidder openrtb_ext.BidderName, consentMeta tcf2.ConsentMetadata, vendorInfo VendorInfo) PermissionDecision {
 	for i := 2; i <= 10; i++ {
 		purpose := consentconstants.Purpose(i)
 		enforcer := p.purposeEnforcerBuilder(purpose, string(bidder))
@@ -190,11 +222,38 @@ func (p *permissionsImpl) allowID(bidder openrtb_ext.BidderName, consentMeta tcf
 			overrides.allowLITransparency = true
 		}
 		if enforcer.LegalBasis(vendorInfo, string(bidder), consentMeta, overrides) {
-			return true
+			return p.purposeDecision(true, purpose, bidder, enforcer)
 		}
 	}
 
-	return false
+	return PermissionDecision{Reason: ReasonNoLegalBasis}
+}
+
+// purposeDecision describes the legal basis computed by a purpose enforcer
+func (p *permissionsImpl) purposeDecision(allowed bool, purpose consentconstants.Purpose, bidder openrtb_ext.BidderName, enforcer PurposeEnforcer) PermissionDecision {
+	decision := PermissionDecision{
+		Allowed: allowed,
+		Purpose: int(purpose),
+		Reason:  ReasonNoLegalBasis,
+	}
+
+	if _, ok := enforcer.(*BasicEnforcement); ok {
+		decision.EnforceAlgo = config.TCF2EnforceAlgoBasic
+	} else {
+		decision.EnforceAlgo = config.TCF2EnforceAlgoFull
+	}
+
+	_, decision.VendorException = p.cfg.PurposeVendorExceptions(purpose)[string(bidder)]
+	switch {
+	case !allowed:
+	case decision.VendorException:
+		decision.Reason = ReasonVendorException
+	case !p.cfg.PurposeEnforced(purpose) && !p.cfg.PurposeEnforcingVendors(purpose):
+		decision.Reason = ReasonNotEnforced
+	default:
+		decision.Reason = ReasonLegalBasis
+	}
+	return decision
 }
 
 // getVendor retrieves the GVL vendor information for a particular bidder
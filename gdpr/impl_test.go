@@ -1314,7 +1314,7 @@ func TestDefaultPermissions(t *testing.T) {
 		tcf2AggConfig.HostConfig.PurposeConfigs[consentconstants.Purpose(2)] = &tcf2AggConfig.HostConfig.Purpose2
 		perms.cfg = &tcf2AggConfig
 
-		result := perms.defaultPermissions()
+		result := perms.defaultPermissions(ReasonConsentMissing).Permissions()
 
 		assert.Equal(t, result, tt.wantPermissions, tt.description)
 	}
@@ -3,8 +3,9 @@ package gdpr
 import (
 	"context"
 	"fmt"
-	"io"
+	"maps"
 	"net/http"
+	"slices"
 	"strconv"
 	"sync"
 	"sync/atomic"
@@ -13,9 +14,7 @@ import (
 	"github.com/golang/glog"
 	"github.com/prebid/go-gdpr/api"
 	"github.com/prebid/go-gdpr/vendorlist"
-	"github.com/prebid/go-gdpr/vendorlist2"
 	"github.com/prebid/prebid-server/v3/config"
-	"golang.org/x/net/context/ctxhttp"
 )
 
 type saveVendors func(uint16, uint16, api.VendorList)
@@ -25,43 +24,105 @@ type VendorListFetcher func(ctx context.Context, specVersion uint16, listVersion
 //
 // For more info, see https://github.com/prebid/prebid-server/issues/504
 //
-// Nothing in this file is exported. Public APIs can be found in gdpr.go
+// The backends the vendor lists are loaded from can be found in vendorlist-source.go
 
 func NewVendorListFetcher(initCtx context.Context, cfg config.GDPR, client *http.Client, urlMaker func(uint16, uint16) string) VendorListFetcher {
-	cacheSave, cacheLoad := newVendorListCache()
+	return NewVendorLists(initCtx, cfg, NewHTTPVendorListSource(client, urlMaker)).Fetch
+}
+
+// VendorLists holds the vendor lists loaded from a vendor list source.
+type VendorLists struct {
+	source             VendorListSource
+	initTimeout        time.Duration
+	cacheSave          saveVendors
+	cacheLoad          func(specVersion, listVersion uint16) api.VendorList
+	cacheVersions      func() map[uint16][]uint16
+	saveOneRateLimited func(ctx context.Context, source VendorListSource, specVersion, listVersion uint16, saver saveVendors)
+	pinned             map[uint16]uint16
+}
+
+// NewVendorLists preloads the vendor lists of the source, bounded by the init timeout.
+func NewVendorLists(initCtx context.Context, cfg config.GDPR, source VendorListSource) *VendorLists {
+	cacheSave, cacheLoad, cacheVersions := newVendorListCache()
+
+	pinned := make(map[uint16]uint16, len(cfg.VendorList.Pinned))
+	for _, pin := range cfg.VendorList.Pinned {
+		pinned[pin.SpecVersion] = pin.ListVersion
+	}
+
+	lists := &VendorLists{
+		source:             source,
+		initTimeout:        cfg.Timeouts.InitTimeout(),
+		cacheSave:          cacheSave,
+		cacheLoad:          cacheLoad,
+		cacheVersions:      cacheVersions,
+		saveOneRateLimited: newOccasionalSaver(cfg.Timeouts.ActiveTimeout()),
+		pinned:             pinned,
+	}
+	lists.Preload(initCtx)
+	return lists
+}
+
+// Fetch implements VendorListFetcher.
+func (v *VendorLists) Fetch(ctx context.Context, specVersion, listVersion uint16) (vendorlist.VendorList, error) {
+	if pinnedVersion, ok := v.pinned[specVersion]; ok {
+		listVersion = pinnedVersion
+	}
+
+	// Attempt To Load From Cache
+	if list := v.cacheLoad(specVersion, listVersion); list != nil {
+		return list, nil
+	}
+
+	// Attempt To Load From Source
+	// - May not add to cache immediately.
+	v.saveOneRateLimited(ctx, v.source, specVersion, listVersion, v.cacheSave)
+
+	// Attempt To Load From Cache Again
+	// - May have been added by the call to saveOneRateLimited.
+	if list := v.cacheLoad(specVersion, listVersion); list != nil {
+		return list, nil
+	}
+
+	// Give Up
+	return nil, makeVendorListNotFoundError(specVersion, listVersion)
+}
 
-	preloadContext, cancel := context.WithTimeout(initCtx, cfg.Timeouts.InitTimeout())
+// Preload saves all the vendor lists known to the source and the pinned vendor lists.
+func (v *VendorLists) Preload(ctx context.Context) {
+	preloadContext, cancel := context.WithTimeout(ctx, v.initTimeout)
 	defer cancel()
-	preloadCache(preloadContext, client, urlMaker, cacheSave)
 
-	saveOneRateLimited := newOccasionalSaver(cfg.Timeouts.ActiveTimeout())
-	return func(ctx context.Context, specVersion, listVersion uint16) (vendorlist.VendorList, error) {
-		// Attempt To Load From Cache
-		if list := cacheLoad(specVersion, listVersion); list != nil {
-			return list, nil
+	v.source.Preload(preloadContext, v.cacheSave, nil)
+	for specVersion, listVersion := range v.pinned {
+		if v.cacheLoad(specVersion, listVersion) == nil {
+			saveOne(preloadContext, v.source, specVersion, listVersion, v.cacheSave)
 		}
+	}
+}
 
-		// Attempt To Download
-		// - May not add to cache immediately.
-		saveOneRateLimited(ctx, client, urlMaker(specVersion, listVersion), cacheSave)
+// LoadedVersions returns the sorted list versions per spec version currently loaded.
+func (v *VendorLists) LoadedVersions() map[uint16][]uint16 {
+	return v.cacheVersions()
+}
 
-		// Attempt To Load From Cache Again
-		// - May have been added by the call to saveOneRateLimited.
-		if list := cacheLoad(specVersion, listVersion); list != nil {
-			return list, nil
-		}
+// Source returns the name of the vendor list source.
+func (v *VendorLists) Source() string {
+	return v.source.Name()
+}
 
-		// Give Up
-		return nil, makeVendorListNotFoundError(specVersion, listVersion)
-	}
+// Pinned returns the pinned list version per spec version.
+func (v *VendorLists) Pinned() map[uint16]uint16 {
+	return maps.Clone(v.pinned)
 }
 
 func makeVendorListNotFoundError(specVersion, listVersion uint16) error {
 	return fmt.Errorf("gdpr vendor list spec version %d list version %d does not exist, or has not been loaded yet. Try again in a few minutes", specVersion, listVersion)
 }
 
-// preloadCache saves all the known versions of the vendor list for future use.
-func preloadCache(ctx context.Context, client *http.Client, urlMaker func(uint16, uint16) string, saver saveVendors) {
+// preloadCache saves all the known versions of the vendor list for future use, except the preloaded ones.
+// The latest version is always loaded, since it tells which versions exist.
+func preloadCache(ctx context.Context, client *http.Client, urlMaker func(uint16, uint16) string, saver saveVendors, preloaded preloadedVendorLists) {
 	versions := [2]struct {
 		specVersion      uint16
 		firstListVersion uint16
@@ -75,11 +136,14 @@ func preloadCache(ctx context.Context, client *http.Client, urlMaker func(uint16
 			firstListVersion: 1,
 		},
 	}
+	source := &httpVendorListSource{client: client, urlMaker: urlMaker}
 	for _, v := range versions {
-		latestVersion := saveOne(ctx, client, urlMaker(v.specVersion, 0), saver)
+		latestVersion := saveOne(ctx, source, v.specVersion, 0, saver)
 
 		for i := v.firstListVersion; i < latestVersion; i++ {
-			saveOne(ctx, client, urlMaker(v.specVersion, i), saver)
+			if !preloaded.has(v.specVersion, i) {
+				saveOne(ctx, source, v.specVersion, i, saver)
+			}
 		}
 	}
 }
@@ -98,50 +162,27 @@ func VendorListURLMaker(specVersion, listVersion uint16) string {
 // The goal here is to update quickly when new versions of the VendorList are released, but not wreck
 // server performance if a bad CMP starts sending us malformed consent strings that advertize a version
 // that doesn't exist yet.
-func newOccasionalSaver(timeout time.Duration) func(ctx context.Context, client *http.Client, url string, saver saveVendors) {
+func newOccasionalSaver(timeout time.Duration) func(ctx context.Context, source VendorListSource, specVersion, listVersion uint16, saver saveVendors) {
 	lastSaved := &atomic.Value{}
 	lastSaved.Store(time.Time{})
 
-	return func(ctx context.Context, client *http.Client, url string, saver saveVendors) {
+	return func(ctx context.Context, source VendorListSource, specVersion, listVersion uint16, saver saveVendors) {
 		now := time.Now()
 		timeSinceLastSave := now.Sub(lastSaved.Load().(time.Time))
 
 		if timeSinceLastSave.Minutes() > 10 {
 			withTimeout, cancel := context.WithTimeout(ctx, timeout)
 			defer cancel()
-			saveOne(withTimeout, client, url, saver)
+			saveOne(withTimeout, source, specVersion, listVersion, saver)
 			lastSaved.Store(now)
 		}
 	}
 }
 
-func saveOne(ctx context.Context, client *http.Client, url string, saver saveVendors) uint16 {
-	req, err := http.NewRequest("GET", url, nil)
-	if err != nil {
-		glog.Errorf("Failed to build GET %s request. Cookie syncs may be affected: %v", url, err)
-		return 0
-	}
-
-	resp, err := ctxhttp.Do(ctx, client, req)
-	if err != nil {
-		glog.Errorf("Error calling GET %s. Cookie syncs may be affected: %v", url, err)
-		return 0
-	}
-	defer resp.Body.Close()
-
-	respBody, err := io.ReadAll(resp.Body)
-	if err != nil {
-		glog.Errorf("Error reading response body from GET %s. Cookie syncs may be affected: %v", url, err)
-		return 0
-	}
-	if resp.StatusCode != http.StatusOK {
-		glog.Errorf("GET %s returned %d. Cookie syncs may be affected.", url, resp.StatusCode)
-		return 0
-	}
-	var newList api.VendorList
-	newList, err = vendorlist2.ParseEagerly(respBody)
+func saveOne(ctx context.Context, source VendorListSource, specVersion, listVersion uint16, saver saveVendors) uint16 {
+	newList, err := source.Load(ctx, specVersion, listVersion)
 	if err != nil {
-		glog.Errorf("GET %s returned malformed JSON. Cookie syncs may be affected. Error was %v. Body was %s", url, err, string(respBody))
+		glog.Errorf("Failed to load vendor list spec version %d list version %d. Cookie syncs may be affected: %v", specVersion, listVersion, err)
 		return 0
 	}
 
@@ -149,7 +190,7 @@ func saveOne(ctx context.Context, client *http.Client, url string, saver saveVen
 	return newList.Version()
 }
 
-func newVendorListCache() (save func(specVersion, listVersion uint16, list api.VendorList), load func(specVersion, listVersion uint16) api.VendorList) {
+func newVendorListCache() (save func(specVersion, listVersion uint16, list api.VendorList), load func(specVersion, listVersion uint16) api.VendorList, versions func() map[uint16][]uint16) {
 	cache := &sync.Map{}
 
 	save = func(specVersion uint16, listVersion uint16, list api.VendorList) {
@@ -165,5 +206,18 @@ func newVendorListCache() (save func(specVersion, listVersion uint16, list api.V
 		}
 		return nil
 	}
+
+	versions = func() map[uint16][]uint16 {
+		loaded := make(map[uint16][]uint16)
+		cache.Range(func(_, value any) bool {
+			list := value.(api.VendorList)
+			loaded[list.SpecVersion()] = append(loaded[list.SpecVersion()], list.Version())
+			return true
+		})
+		for _, listVersions := range loaded {
+			slices.Sort(listVersions)
+		}
+		return loaded
+	}
 	return
 }
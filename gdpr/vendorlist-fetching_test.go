@@ -253,7 +253,7 @@ func TestPreloadCache(t *testing.T) {
 	defer server.Close()
 
 	s := make(saver, 0, 5)
-	preloadCache(context.Background(), server.Client(), testURLMaker(server), s.saveVendorLists)
+	preloadCache(context.Background(), server.Client(), testURLMaker(server), s.saveVendorLists, nil)
 
 	expectedLoadedVersions := []versionInfo{
 		{specVersion: 2, listVersion: 2},
@@ -0,0 +1,281 @@
+package gdpr
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/golang/glog"
+	"github.com/prebid/go-gdpr/api"
+	"github.com/prebid/go-gdpr/vendorlist2"
+	"github.com/prebid/prebid-server/v3/config"
+	"golang.org/x/net/context/ctxhttp"
+)
+
+// VendorListSource loads global vendor lists from a backend. A list version of 0 refers to the
+// latest list version of the spec version known to the backend.
+type VendorListSource interface {
+	Load(ctx context.Context, specVersion, listVersion uint16) (api.VendorList, error)
+	// Preload saves all the vendor lists the backend is expected to serve, except the preloaded ones.
+	Preload(ctx context.Context, saver saveVendors, preloaded preloadedVendorLists)
+	Name() string
+}
+
+// preloadedVendorLists tells whether a vendor list was already preloaded, so it isn't loaded again.
+// A nil preloadedVendorLists holds no list.
+type preloadedVendorLists func(specVersion, listVersion uint16) bool
+
+func (p preloadedVendorLists) has(specVersion, listVersion uint16) bool {
+	return p != nil && p(specVersion, listVersion)
+}
+
+// NewVendorListSource builds the vendor list source chain described by the host config.
+func NewVendorListSource(cfg config.GDPRVendorList, client *http.Client) (VendorListSource, error) {
+	sources := make([]VendorListSource, 0, len(cfg.Sources))
+	for _, name := range cfg.Sources {
+		switch name {
+		case config.GDPRVendorListSourceLocal:
+			sources = append(sources, NewLocalVendorListSource(cfg.Local.Dir))
+		case config.GDPRVendorListSourceHTTP:
+			httpClient, err := proxiedClient(client, cfg.HTTP.Proxy)
+			if err != nil {
+				return nil, err
+			}
+			sources = append(sources, NewHTTPVendorListSource(httpClient, VendorListURLTemplateMaker(cfg.HTTP.URL, cfg.HTTP.LatestURL)))
+		default:
+			return nil, fmt.Errorf("unknown vendor list source %q", name)
+		}
+	}
+
+	if len(sources) == 1 {
+		return sources[0], nil
+	}
+	return NewChainedVendorListSource(sources...), nil
+}
+
+// proxiedClient returns a copy of the client which sends its requests through the proxy.
+func proxiedClient(client *http.Client, proxy string) (*http.Client, error) {
+	if proxy == "" {
+		return client, nil
+	}
+
+	proxyURL, err := url.Parse(proxy)
+	if err != nil {
+		return nil, fmt.Errorf("invalid vendor list proxy: %v", err)
+	}
+
+	transport, ok := client.Transport.(*http.Transport)
+	if !ok || transport == nil {
+		transport = http.DefaultTransport.(*http.Transport)
+	}
+	transport = transport.Clone()
+	transport.Proxy = http.ProxyURL(proxyURL)
+
+	proxied := *client
+	proxied.Transport = transport
+	return &proxied, nil
+}
+
+// VendorListURLTemplateMaker makes vendor list URLs from templates with the {spec} and {version} macros.
+// The latest template is used when the list version is 0.
+func VendorListURLTemplateMaker(template, latestTemplate string) func(uint16, uint16) string {
+	return func(specVersion, listVersion uint16) string {
+		if listVersion == 0 {
+			return strings.NewReplacer("{spec}", strconv.Itoa(int(specVersion))).Replace(latestTemplate)
+		}
+		return strings.NewReplacer("{spec}", strconv.Itoa(int(specVersion)), "{version}", strconv.Itoa(int(listVersion))).Replace(template)
+	}
+}
+
+type httpVendorListSource struct {
+	client   *http.Client
+	urlMaker func(uint16, uint16) string
+}
+
+// NewHTTPVendorListSource downloads vendor lists from the URLs built by urlMaker.
+func NewHTTPVendorListSource(client *http.Client, urlMaker func(uint16, uint16) string) VendorListSource {
+	return &httpVendorListSource{client: client, urlMaker: urlMaker}
+}
+
+func (s *httpVendorListSource) Name() string {
+	return config.GDPRVendorListSourceHTTP
+}
+
+func (s *httpVendorListSource) Load(ctx context.Context, specVersion, listVersion uint16) (api.VendorList, error) {
+	url := s.urlMaker(specVersion, listVersion)
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build GET %s request: %v", url, err)
+	}
+
+	resp, err := ctxhttp.Do(ctx, s.client, req)
+	if err != nil {
+		return nil, fmt.Errorf("error calling GET %s: %v", url, err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("error reading response body from GET %s: %v", url, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("GET %s returned %d", url, resp.StatusCode)
+	}
+
+	list, err := vendorlist2.ParseEagerly(respBody)
+	if err != nil {
+		return nil, fmt.Errorf("GET %s returned malformed JSON. Error was %v. Body was %s", url, err, string(respBody))
+	}
+	return list, nil
+}
+
+func (s *httpVendorListSource) Preload(ctx context.Context, saver saveVendors, preloaded preloadedVendorLists) {
+	preloadCache(ctx, s.client, s.urlMaker, saver, preloaded)
+}
+
+var localVendorListFileName = regexp.MustCompile(`^vendor-list-v(\d+)-(\d+)\.json$`)
+
+type localVendorListSource struct {
+	dir string
+}
+
+// NewLocalVendorListSource reads vendor lists stored as vendor-list-v{spec}-{version}.json files in dir.
+func NewLocalVendorListSource(dir string) VendorListSource {
+	return &localVendorListSource{dir: dir}
+}
+
+func (s *localVendorListSource) Name() string {
+	return config.GDPRVendorListSourceLocal
+}
+
+func (s *localVendorListSource) Load(_ context.Context, specVersion, listVersion uint16) (api.VendorList, error) {
+	if listVersion == 0 {
+		versions, err := s.versions()
+		if err != nil {
+			return nil, err
+		}
+		for _, v := range versions[specVersion] {
+			listVersion = max(listVersion, v)
+		}
+		if listVersion == 0 {
+			return nil, fmt.Errorf("no vendor list for spec version %d in %s", specVersion, s.dir)
+		}
+	}
+	return s.read(specVersion, listVersion)
+}
+
+func (s *localVendorListSource) Preload(_ context.Context, saver saveVendors, preloaded preloadedVendorLists) {
+	versions, err := s.versions()
+	if err != nil {
+		glog.Errorf("Failed to list the vendor lists in %s: %v", s.dir, err)
+		return
+	}
+
+	for specVersion, listVersions := range versions {
+		for _, listVersion := range listVersions {
+			if preloaded.has(specVersion, listVersion) {
+				continue
+			}
+			list, err := s.read(specVersion, listVersion)
+			if err != nil {
+				glog.Errorf("Failed to load the vendor list. Cookie syncs may be affected: %v", err)
+				continue
+			}
+			saver(list.SpecVersion(), list.Version(), list)
+		}
+	}
+}
+
+// versions returns the list versions per spec version held in the directory.
+func (s *localVendorListSource) versions() (map[uint16][]uint16, error) {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return nil, err
+	}
+
+	versions := make(map[uint16][]uint16)
+	for _, entry := range entries {
+		match := localVendorListFileName.FindStringSubmatch(entry.Name())
+		if entry.IsDir() || match == nil {
+			continue
+		}
+		specVersion, errSpec := strconv.ParseUint(match[1], 10, 16)
+		listVersion, errList := strconv.ParseUint(match[2], 10, 16)
+		if errSpec != nil || errList != nil {
+			continue
+		}
+		versions[uint16(specVersion)] = append(versions[uint16(specVersion)], uint16(listVersion))
+	}
+	return versions, nil
+}
+
+func (s *localVendorListSource) read(specVersion, listVersion uint16) (api.VendorList, error) {
+	path := filepath.Join(s.dir, fmt.Sprintf("vendor-list-v%d-%d.json", specVersion, listVersion))
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	list, err := vendorlist2.ParseEagerly(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s is malformed: %v", path, err)
+	}
+	if list.SpecVersion() != specVersion || list.Version() != listVersion {
+		return nil, fmt.Errorf("%s holds spec version %d list version %d", path, list.SpecVersion(), list.Version())
+	}
+	return list, nil
+}
+
+type chainedVendorListSource struct {
+	sources []VendorListSource
+}
+
+// NewChainedVendorListSource loads vendor lists from the first of the sources able to provide them.
+func NewChainedVendorListSource(sources ...VendorListSource) VendorListSource {
+	return &chainedVendorListSource{sources: sources}
+}
+
+func (s *chainedVendorListSource) Name() string {
+	names := make([]string, 0, len(s.sources))
+	for _, source := range s.sources {
+		names = append(names, source.Name())
+	}
+	return strings.Join(names, ",")
+}
+
+func (s *chainedVendorListSource) Load(ctx context.Context, specVersion, listVersion uint16) (api.VendorList, error) {
+	var errs []error
+	for _, source := range s.sources {
+		list, err := source.Load(ctx, specVersion, listVersion)
+		if err == nil {
+			return list, nil
+		}
+		errs = append(errs, fmt.Errorf("%s: %v", source.Name(), err))
+	}
+	return nil, errors.Join(errs...)
+}
+
+// Preload preloads the sources in order, each of them only for the lists the sources before it didn't provide.
+func (s *chainedVendorListSource) Preload(ctx context.Context, saver saveVendors, preloaded preloadedVendorLists) {
+	saved := make(map[[2]uint16]struct{})
+	recordingSaver := func(specVersion, listVersion uint16, list api.VendorList) {
+		saved[[2]uint16{specVersion, listVersion}] = struct{}{}
+		saver(specVersion, listVersion, list)
+	}
+	chainPreloaded := func(specVersion, listVersion uint16) bool {
+		_, ok := saved[[2]uint16{specVersion, listVersion}]
+		return ok || preloaded.has(specVersion, listVersion)
+	}
+
+	for _, source := range s.sources {
+		source.Preload(ctx, recordingSaver, chainPreloaded)
+	}
+}
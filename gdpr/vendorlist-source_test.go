@@ -0,0 +1,275 @@
+package gdpr
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/prebid/go-gdpr/api"
+	"github.com/prebid/go-gdpr/vendorlist2"
+	"github.com/prebid/prebid-server/v3/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeTestVendorLists(t *testing.T, files map[string]string) string {
+	dir := t.TempDir()
+	for name, content := range files {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0644))
+	}
+	return dir
+}
+
+func testVendorListVersion(specVersion, listVersion uint16) string {
+	return MarshalVendorList(vendorList{
+		GVLSpecificationVersion: specVersion,
+		VendorListVersion:       listVersion,
+		Vendors:                 map[string]*vendor{"12": {ID: 12, Purposes: []int{1}}},
+	})
+}
+
+func TestLocalVendorListSourceLoad(t *testing.T) {
+	dir := writeTestVendorLists(t, map[string]string{
+		"vendor-list-v3-1.json":  testVendorListVersion(3, 1),
+		"vendor-list-v3-12.json": testVendorListVersion(3, 12),
+		"vendor-list-v2-5.json":  testVendorListVersion(2, 5),
+		"vendor-list-v3-7.json":  testVendorListVersion(3, 8),
+		"vendor-list-v3-9.json":  "malformed",
+		"readme.txt":             "ignored",
+	})
+	source := NewLocalVendorListSource(dir)
+
+	testCases := []struct {
+		name                string
+		specVersion         uint16
+		listVersion         uint16
+		expectedListVersion uint16
+		expectedError       bool
+	}{
+		{name: "exact_version", specVersion: 3, listVersion: 1, expectedListVersion: 1},
+		{name: "latest_version", specVersion: 3, listVersion: 0, expectedListVersion: 12},
+		{name: "latest_version_other_spec", specVersion: 2, listVersion: 0, expectedListVersion: 5},
+		{name: "missing_version", specVersion: 3, listVersion: 2, expectedError: true},
+		{name: "missing_spec", specVersion: 4, listVersion: 0, expectedError: true},
+		{name: "file_version_mismatch", specVersion: 3, listVersion: 7, expectedError: true},
+		{name: "malformed", specVersion: 3, listVersion: 9, expectedError: true},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.name, func(t *testing.T) {
+			list, err := source.Load(context.Background(), test.specVersion, test.listVersion)
+			if test.expectedError {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.specVersion, list.SpecVersion())
+			assert.Equal(t, test.expectedListVersion, list.Version())
+		})
+	}
+}
+
+func TestLocalVendorListSourcePreload(t *testing.T) {
+	dir := writeTestVendorLists(t, map[string]string{
+		"vendor-list-v3-1.json": testVendorListVersion(3, 1),
+		"vendor-list-v3-4.json": testVendorListVersion(3, 4),
+		"vendor-list-v2-4.json": testVendorListVersion(2, 4),
+		"vendor-list-v2-5.json": testVendorListVersion(2, 5),
+		"vendor-list-v2-6.json": "malformed",
+	})
+
+	s := make(saver, 0, 3)
+	NewLocalVendorListSource(dir).Preload(context.Background(), s.saveVendorLists, func(specVersion, listVersion uint16) bool {
+		return specVersion == 2 && listVersion == 4
+	})
+
+	assert.ElementsMatch(t, []versionInfo{
+		{specVersion: 3, listVersion: 1},
+		{specVersion: 3, listVersion: 4},
+		{specVersion: 2, listVersion: 5},
+	}, s)
+}
+
+func TestLocalVendorListSourceMissingDir(t *testing.T) {
+	source := NewLocalVendorListSource(filepath.Join(t.TempDir(), "missing"))
+
+	_, err := source.Load(context.Background(), 3, 0)
+	assert.Error(t, err)
+
+	s := make(saver, 0)
+	source.Preload(context.Background(), s.saveVendorLists, nil)
+	assert.Empty(t, s)
+}
+
+type fakeVendorListSource struct {
+	name  string
+	lists map[uint16]api.VendorList
+	loads int
+}
+
+func (s *fakeVendorListSource) Name() string {
+	return s.name
+}
+
+func (s *fakeVendorListSource) Load(_ context.Context, _, listVersion uint16) (api.VendorList, error) {
+	s.loads++
+	if list, ok := s.lists[listVersion]; ok {
+		return list, nil
+	}
+	return nil, errors.New("not found")
+}
+
+func (s *fakeVendorListSource) Preload(_ context.Context, saver saveVendors, preloaded preloadedVendorLists) {
+	for _, list := range s.lists {
+		if !preloaded.has(list.SpecVersion(), list.Version()) {
+			saver(list.SpecVersion(), list.Version(), list)
+		}
+	}
+}
+
+func parseTestVendorList(t *testing.T, specVersion, listVersion uint16) api.VendorList {
+	list, err := vendorlist2.ParseEagerly([]byte(testVendorListVersion(specVersion, listVersion)))
+	require.NoError(t, err)
+	return list
+}
+
+func TestChainedVendorListSource(t *testing.T) {
+	first := &fakeVendorListSource{name: "local", lists: map[uint16]api.VendorList{1: parseTestVendorList(t, 3, 1)}}
+	second := &fakeVendorListSource{name: "http", lists: map[uint16]api.VendorList{1: parseTestVendorList(t, 3, 1), 2: parseTestVendorList(t, 3, 2)}}
+	source := NewChainedVendorListSource(first, second)
+
+	assert.Equal(t, "local,http", source.Name())
+
+	list, err := source.Load(context.Background(), 3, 1)
+	require.NoError(t, err)
+	assert.Equal(t, uint16(1), list.Version())
+	assert.Equal(t, 0, second.loads, "the second source should not be used when the first source has the list")
+
+	list, err = source.Load(context.Background(), 3, 2)
+	require.NoError(t, err)
+	assert.Equal(t, uint16(2), list.Version())
+
+	_, err = source.Load(context.Background(), 3, 3)
+	assert.EqualError(t, err, "local: not found\nhttp: not found")
+
+	s := make(saver, 0, 2)
+	source.Preload(context.Background(), s.saveVendorLists, nil)
+	assert.ElementsMatch(t, []versionInfo{
+		{specVersion: 3, listVersion: 1},
+		{specVersion: 3, listVersion: 2},
+	}, s, "the second source should only preload the list the first source doesn't have")
+}
+
+func TestVendorListURLTemplateMaker(t *testing.T) {
+	urlMaker := VendorListURLTemplateMaker("https://gvl.example.com/v{spec}/archives/vendor-list-v{version}.json", "https://gvl.example.com/v{spec}/vendor-list.json")
+
+	assert.Equal(t, "https://gvl.example.com/v3/vendor-list.json", urlMaker(3, 0))
+	assert.Equal(t, "https://gvl.example.com/v3/archives/vendor-list-v42.json", urlMaker(3, 42))
+	assert.Equal(t, VendorListURLMaker(2, 42), VendorListURLTemplateMaker("https://vendor-list.consensu.org/v{spec}/archives/vendor-list-v{version}.json", "https://vendor-list.consensu.org/v{spec}/vendor-list.json")(2, 42))
+}
+
+func TestNewVendorListSource(t *testing.T) {
+	testCases := []struct {
+		name          string
+		cfg           config.GDPRVendorList
+		expectedName  string
+		expectedError bool
+	}{
+		{
+			name:         "http",
+			cfg:          config.GDPRVendorList{Sources: []string{"http"}},
+			expectedName: "http",
+		},
+		{
+			name:         "local_with_http_fallback",
+			cfg:          config.GDPRVendorList{Sources: []string{"local", "http"}, Local: config.GDPRVendorListDir{Dir: "/gvl"}},
+			expectedName: "local,http",
+		},
+		{
+			name:         "http_with_proxy",
+			cfg:          config.GDPRVendorList{Sources: []string{"http"}, HTTP: config.GDPRVendorListHTTP{Proxy: "http://proxy.example.com:3128"}},
+			expectedName: "http",
+		},
+		{
+			name:          "invalid_proxy",
+			cfg:           config.GDPRVendorList{Sources: []string{"http"}, HTTP: config.GDPRVendorListHTTP{Proxy: "://invalid"}},
+			expectedError: true,
+		},
+		{
+			name:          "unknown_source",
+			cfg:           config.GDPRVendorList{Sources: []string{"ftp"}},
+			expectedError: true,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.name, func(t *testing.T) {
+			source, err := NewVendorListSource(test.cfg, &http.Client{})
+			if test.expectedError {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.expectedName, source.Name())
+		})
+	}
+}
+
+func TestProxiedClient(t *testing.T) {
+	client := &http.Client{}
+
+	unchanged, err := proxiedClient(client, "")
+	require.NoError(t, err)
+	assert.Same(t, client, unchanged)
+
+	proxied, err := proxiedClient(client, "http://proxy.example.com:3128")
+	require.NoError(t, err)
+	transport, ok := proxied.Transport.(*http.Transport)
+	require.True(t, ok)
+
+	proxyURL, err := transport.Proxy(httptest.NewRequest(http.MethodGet, "https://vendor-list.consensu.org/v3/vendor-list.json", nil))
+	require.NoError(t, err)
+	assert.Equal(t, "http://proxy.example.com:3128", proxyURL.String())
+	assert.Nil(t, client.Transport, "the original client should not be modified")
+}
+
+func TestVendorListsPinned(t *testing.T) {
+	source := &fakeVendorListSource{name: "local", lists: map[uint16]api.VendorList{
+		1: parseTestVendorList(t, 3, 1),
+		2: parseTestVendorList(t, 3, 2),
+	}}
+	cfg := testConfig()
+	cfg.VendorList.Pinned = []config.GDPRVendorListPin{{SpecVersion: 3, ListVersion: 1}}
+
+	lists := NewVendorLists(context.Background(), cfg, source)
+
+	list, err := lists.Fetch(context.Background(), 3, 2)
+	require.NoError(t, err)
+	assert.Equal(t, uint16(1), list.Version(), "the pinned version should be used whatever the requested version")
+	assert.Equal(t, map[uint16]uint16{3: 1}, lists.Pinned())
+	assert.Equal(t, "local", lists.Source())
+}
+
+func TestVendorListsLoadedVersions(t *testing.T) {
+	dir := writeTestVendorLists(t, map[string]string{
+		"vendor-list-v3-4.json": testVendorListVersion(3, 4),
+		"vendor-list-v3-1.json": testVendorListVersion(3, 1),
+		"vendor-list-v2-5.json": testVendorListVersion(2, 5),
+	})
+
+	lists := NewVendorLists(context.Background(), testConfig(), NewLocalVendorListSource(dir))
+	assert.Equal(t, map[uint16][]uint16{2: {5}, 3: {1, 4}}, lists.LoadedVersions())
+
+	// an operator drops a new list in the directory and triggers a preload
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "vendor-list-v3-5.json"), []byte(testVendorListVersion(3, 5)), 0644))
+	lists.Preload(context.Background())
+	assert.Equal(t, map[uint16][]uint16{2: {5}, 3: {1, 4, 5}}, lists.LoadedVersions())
+
+	list, err := lists.Fetch(context.Background(), 3, 5)
+	require.NoError(t, err)
+	assert.Equal(t, uint16(5), list.Version())
+}
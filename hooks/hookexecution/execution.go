@@ -16,7 +16,10 @@ import (
 	"github.com/prebid/prebid-server/v3/openrtb_ext"
 	"github.com/prebid/prebid-server/v3/ortb"
 	"github.com/prebid/prebid-server/v3/privacy"
+	"github.com/prebid/prebid-server/v3/tracing"
 	"github.com/prebid/prebid-server/v3/util/iputil"
+	"go.opentelemetry.io/otel/attribute"
+	oteltrace "go.opentelemetry.io/otel/trace"
 )
 
 type hookResponse[T any] struct {
@@ -40,6 +43,16 @@ func executeStage[H any, P any](
 	hookHandler hookHandler[H, P],
 	metricEngine metrics.MetricsEngine,
 ) (StageOutcome, P, stageModuleContext, *RejectError) {
+	traceCtx := executionCtx.traceCtx
+	if traceCtx == nil {
+		traceCtx = context.Background()
+	}
+	var span oteltrace.Span
+	executionCtx.traceCtx, span = tracing.Start(traceCtx, "hooks."+executionCtx.stage,
+		oteltrace.WithAttributes(attribute.String("pbs.endpoint", executionCtx.endpoint)),
+	)
+	defer span.End()
+
 	stageOutcome := StageOutcome{}
 	stageOutcome.Groups = make([]GroupOutcome, 0, len(plan))
 	stageModuleCtx := stageModuleContext{}
@@ -51,6 +64,7 @@ func executeStage[H any, P any](
 		stageOutcome.Groups = append(stageOutcome.Groups, groupOutcome)
 		stageModuleCtx.groupCtx = append(stageModuleCtx.groupCtx, moduleContexts)
 		if rejectErr != nil {
+			span.SetAttributes(attribute.Bool("pbs.rejected", true))
 			return stageOutcome, payload, stageModuleCtx, rejectErr
 		}
 
@@ -77,7 +91,7 @@ func executeGroup[H any, P any](
 		wg.Add(1)
 		go func(hw hooks.HookWrapper[H], moduleCtx hookstage.ModuleInvocationContext) {
 			defer wg.Done()
-			executeHook(moduleCtx, hw, newPayload, hookHandler, group.Timeout, resp, rejected)
+			executeHook(executionCtx.traceCtx, moduleCtx, hw, newPayload, hookHandler, group.Timeout, resp, rejected)
 		}(hook, mCtx)
 	}
 
@@ -92,6 +106,7 @@ func executeGroup[H any, P any](
 }
 
 func executeHook[H any, P any](
+	traceCtx context.Context,
 	moduleCtx hookstage.ModuleInvocationContext,
 	hw hooks.HookWrapper[H],
 	payload P,
@@ -103,6 +118,10 @@ func executeHook[H any, P any](
 	hookRespCh := make(chan hookResponse[P], 1)
 	startTime := time.Now()
 	hookId := HookID{ModuleCode: hw.Module, HookImplCode: hw.Code}
+	traceCtx, span := tracing.Start(traceCtx, "hooks.module", oteltrace.WithAttributes(
+		attribute.String("pbs.module", hw.Module),
+		attribute.String("pbs.hook", hw.Code),
+	))
 
 	go func() {
 		defer func() {
@@ -112,7 +131,7 @@ func executeHook[H any, P any](
 			}
 		}()
 
-		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		ctx, cancel := context.WithTimeout(traceCtx, timeout)
 		defer cancel()
 		result, err := hookHandler(ctx, moduleCtx, hw.Hook, payload)
 		hookRespCh <- hookResponse[P]{
@@ -125,8 +144,10 @@ func executeHook[H any, P any](
 	case res := <-hookRespCh:
 		res.HookID = hookId
 		res.ExecutionTime = time.Since(startTime)
+		tracing.End(span, res.Err)
 		resp <- res
 	case <-time.After(timeout):
+		tracing.End(span, TimeoutError{})
 		resp <- hookResponse[P]{
 			Err:           TimeoutError{},
 			ExecutionTime: time.Since(startTime),
@@ -134,6 +155,7 @@ func executeHook[H any, P any](
 			Result:        hookstage.HookResult[P]{},
 		}
 	case <-rejected:
+		span.End()
 		return
 	}
 }
@@ -315,6 +337,11 @@ func handleHookMutations[P any](
 		successfulMutations++
 	}
 
+	// the bids rejected by the hook are only reported if all its mutations, which remove them, were applied
+	if successfulMutations == len(hr.Result.ChangeSet.Mutations()) {
+		hookOutcome.SeatNonBid = hr.Result.SeatNonBid
+	}
+
 	// if at least one mutation from a given module was successfully applied
 	// we consider that the module was processed successfully
 	if successfulMutations > 0 {
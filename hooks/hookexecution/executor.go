@@ -39,6 +39,7 @@ type StageExecutor interface {
 	ExecuteRawBidderResponseStage(response *adapters.BidderResponse, bidder string) *RejectError
 	ExecuteAllProcessedBidResponsesStage(adapterBids map[openrtb_ext.BidderName]*entities.PbsOrtbSeatBid)
 	ExecuteAuctionResponseStage(response *openrtb2.BidResponse)
+	GetSeatNonBid() []openrtb_ext.SeatNonBid
 }
 
 type HookStageExecutor interface {
@@ -57,6 +58,7 @@ type hookExecutor struct {
 	moduleContexts  *moduleContexts
 	metricEngine    metrics.MetricsEngine
 	activityControl privacy.ActivityControl
+	traceCtx        context.Context
 	// Mutex needed for BidderRequest and RawBidderResponse Stages as they are run in several goroutines
 	sync.Mutex
 }
@@ -68,9 +70,16 @@ func NewHookExecutor(builder hooks.ExecutionPlanBuilder, endpoint string, me met
 		stageOutcomes:  []StageOutcome{},
 		moduleContexts: &moduleContexts{ctxs: make(map[string]hookstage.ModuleContext)},
 		metricEngine:   me,
+		traceCtx:       context.Background(),
 	}
 }
 
+// SetTraceContext sets the context carrying the span of the request, which the spans of the stages are
+// children of. It must not be cancelled before the last stage runs.
+func (e *hookExecutor) SetTraceContext(ctx context.Context) {
+	e.traceCtx = ctx
+}
+
 func (e *hookExecutor) SetAccount(account *config.Account) {
 	if account == nil {
 		return
@@ -88,6 +97,22 @@ func (e *hookExecutor) GetOutcomes() []StageOutcome {
 	return e.stageOutcomes
 }
 
+// GetSeatNonBid returns the bids rejected by hooks across all executed stages.
+func (e *hookExecutor) GetSeatNonBid() []openrtb_ext.SeatNonBid {
+	e.Lock()
+	defer e.Unlock()
+
+	var seatNonBid []openrtb_ext.SeatNonBid
+	for _, stageOutcome := range e.stageOutcomes {
+		for _, groupOutcome := range stageOutcome.Groups {
+			for _, hookOutcome := range groupOutcome.InvocationResults {
+				seatNonBid = append(seatNonBid, hookOutcome.SeatNonBid...)
+			}
+		}
+	}
+	return seatNonBid
+}
+
 func (e *hookExecutor) ExecuteEntrypointStage(req *http.Request, body []byte) ([]byte, *RejectError) {
 	plan := e.planBuilder.PlanForEntrypointStage(e.endpoint)
 	if len(plan) == 0 {
@@ -303,6 +328,7 @@ func (e *hookExecutor) newContext(stage string) executionContext {
 		moduleContexts:  e.moduleContexts,
 		stage:           stage,
 		activityControl: e.activityControl,
+		traceCtx:        e.traceCtx,
 	}
 }
 
@@ -330,6 +356,10 @@ func (executor EmptyHookExecutor) GetOutcomes() []StageOutcome {
 	return []StageOutcome{}
 }
 
+func (executor EmptyHookExecutor) GetSeatNonBid() []openrtb_ext.SeatNonBid {
+	return nil
+}
+
 func (executor EmptyHookExecutor) ExecuteEntrypointStage(_ *http.Request, body []byte) ([]byte, *RejectError) {
 	return body, nil
 }
@@ -2,6 +2,7 @@ package hookexecution
 
 import (
 	"bytes"
+	"context"
 	"fmt"
 	"net/http"
 	"net/url"
@@ -19,9 +20,14 @@ import (
 	metricsConfig "github.com/prebid/prebid-server/v3/metrics/config"
 	"github.com/prebid/prebid-server/v3/openrtb_ext"
 	"github.com/prebid/prebid-server/v3/privacy"
+	"github.com/prebid/prebid-server/v3/tracing"
+	"github.com/prebid/prebid-server/v3/tracing/tracingtest"
 	"github.com/prebid/prebid-server/v3/util/ptrutil"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
 )
 
 func TestEmptyHookExecutor(t *testing.T) {
@@ -44,6 +50,7 @@ func TestEmptyHookExecutor(t *testing.T) {
 	outcomes := executor.GetOutcomes()
 	assert.Equal(t, EmptyHookExecutor{}, executor, "EmptyHookExecutor shouldn't be changed.")
 	assert.Empty(t, outcomes, "EmptyHookExecutor shouldn't return stage outcomes.")
+	assert.Empty(t, executor.GetSeatNonBid(), "EmptyHookExecutor shouldn't return seat non bids.")
 
 	assert.Nil(t, entrypointRejectErr, "EmptyHookExecutor shouldn't return reject error at entrypoint stage.")
 	assert.Equal(t, body, entrypointBody, "EmptyHookExecutor shouldn't change body at entrypoint stage.")
@@ -1433,6 +1440,26 @@ func TestExecuteRawBidderResponseStage(t *testing.T) {
 	}
 }
 
+func TestGetSeatNonBid(t *testing.T) {
+	exec := NewHookExecutor(TestSeatNonBidPlanBuilder{}, EndpointAuction, &metricsConfig.NilMetricsEngine{})
+	exec.SetAccount(&config.Account{ID: "some-account"})
+
+	assert.Empty(t, exec.GetSeatNonBid(), "No seat non bids expected before hooks execution.")
+
+	for _, bidder := range []string{"bidder-1", "bidder-2"} {
+		resp := &adapters.BidderResponse{Bids: []*adapters.TypedBid{{Bid: &openrtb2.Bid{ImpID: "imp-1"}}, {Bid: &openrtb2.Bid{ImpID: "imp-2"}}}}
+		reject := exec.ExecuteRawBidderResponseStage(resp, bidder)
+		assert.Nil(t, reject, "Unexpected stage rejection.")
+		assert.Equal(t, []*adapters.TypedBid{{Bid: &openrtb2.Bid{ImpID: "imp-2"}}}, resp.Bids, "Only the bids of imp-1 should be removed.")
+	}
+
+	expectedSeatNonBid := []openrtb_ext.SeatNonBid{
+		{Seat: "bidder-1", NonBid: []openrtb_ext.NonBid{{ImpId: "imp-1", StatusCode: 350}}},
+		{Seat: "bidder-2", NonBid: []openrtb_ext.NonBid{{ImpId: "imp-1", StatusCode: 350}}},
+	}
+	assert.ElementsMatch(t, expectedSeatNonBid, exec.GetSeatNonBid(), "Only the bids removed by the applied mutations should be reported.")
+}
+
 func TestExecuteAllProcessedBidResponsesStage(t *testing.T) {
 	foobarModuleCtx := &moduleContexts{ctxs: map[string]hookstage.ModuleContext{"foobar": nil}}
 
@@ -1952,6 +1979,45 @@ func TestExecuteAuctionResponseStage(t *testing.T) {
 	}
 }
 
+func TestHookExecutionSpans(t *testing.T) {
+	exporter := tracingtest.Setup(t)
+
+	ctx, parent := tracing.Start(context.Background(), "openrtb2.auction")
+	exec := NewHookExecutor(TestApplyHookMutationsBuilder{}, EndpointAuction, &metricsConfig.NilMetricsEngine{})
+	exec.SetTraceContext(ctx)
+
+	_, reject := exec.ExecuteRawAuctionStage([]byte(`{"name": "John"}`))
+	parent.End()
+	assert.Nil(t, reject)
+
+	spans := exporter.GetSpans()
+	stageName := "hooks." + hooks.StageRawAuctionRequest.String()
+	assert.ElementsMatch(t, []string{"hooks.module", "hooks.module", "hooks.module", stageName, "openrtb2.auction"}, tracingtest.SpanNames(spans))
+
+	var stageSpan tracetest.SpanStub
+	for _, span := range spans {
+		if span.Name == stageName {
+			stageSpan = span
+		}
+	}
+	assert.Equal(t, parent.SpanContext().SpanID(), stageSpan.Parent.SpanID(), "the stage span must be a child of the endpoint span")
+	assert.Contains(t, stageSpan.Attributes, attribute.String("pbs.endpoint", EndpointAuction))
+
+	moduleStatuses := map[string]codes.Code{}
+	for _, span := range spans {
+		if span.Name != "hooks.module" {
+			continue
+		}
+		assert.Equal(t, stageSpan.SpanContext.SpanID(), span.Parent.SpanID(), "the module spans must be children of the stage span")
+		for _, attr := range span.Attributes {
+			if attr.Key == "pbs.hook" {
+				moduleStatuses[attr.Value.AsString()] = span.Status.Code
+			}
+		}
+	}
+	assert.Equal(t, map[string]codes.Code{"foo": codes.Unset, "bar": codes.Unset, "baz": codes.Error}, moduleStatuses)
+}
+
 func TestInterStageContextCommunication(t *testing.T) {
 	body := []byte(`{"foo": "bar"}`)
 	reader := bytes.NewReader(body)
@@ -2612,3 +2678,19 @@ func (e TestAllHookResultsBuilder) PlanForEntrypointStage(_ string) hooks.Plan[h
 		},
 	}
 }
+
+type TestSeatNonBidPlanBuilder struct {
+	hooks.EmptyPlanBuilder
+}
+
+func (e TestSeatNonBidPlanBuilder) PlanForRawBidderResponseStage(_ string, _ *config.Account) hooks.Plan[hookstage.RawBidderResponse] {
+	return hooks.Plan[hookstage.RawBidderResponse]{
+		hooks.Group[hookstage.RawBidderResponse]{
+			Timeout: 10 * time.Millisecond,
+			Hooks: []hooks.HookWrapper[hookstage.RawBidderResponse]{
+				{Module: "foobar", Code: "foo", Hook: mockSeatNonBidHook{impID: "imp-1"}},
+				{Module: "foobar", Code: "bar", Hook: mockSeatNonBidHook{impID: "imp-2", failMutation: true}},
+			},
+		},
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"time"
 
+	"github.com/prebid/prebid-server/v3/adapters"
 	"github.com/prebid/prebid-server/v3/hooks/hookstage"
 	"github.com/prebid/prebid-server/v3/openrtb_ext"
 	"github.com/prebid/prebid-server/v3/util/ptrutil"
@@ -385,3 +386,33 @@ func (e mockUpdateBidResponseHook) HandleAuctionResponseHook(_ context.Context,
 
 	return hookstage.HookResult[hookstage.AuctionResponsePayload]{ChangeSet: c}, nil
 }
+
+// mockSeatNonBidHook removes the bids of the imp and reports them as seat non bids. Its mutation fails if failMutation is set.
+type mockSeatNonBidHook struct {
+	impID        string
+	failMutation bool
+}
+
+func (e mockSeatNonBidHook) HandleRawBidderResponseHook(_ context.Context, _ hookstage.ModuleInvocationContext, payload hookstage.RawBidderResponsePayload) (hookstage.HookResult[hookstage.RawBidderResponsePayload], error) {
+	seatNonBid := openrtb_ext.SeatNonBid{
+		Seat:   payload.Bidder,
+		NonBid: []openrtb_ext.NonBid{{ImpId: e.impID, StatusCode: 350}},
+	}
+
+	c := hookstage.ChangeSet[hookstage.RawBidderResponsePayload]{}
+	c.AddMutation(func(payload hookstage.RawBidderResponsePayload) (hookstage.RawBidderResponsePayload, error) {
+		if e.failMutation {
+			return payload, errors.New("failed to remove the bids")
+		}
+		var bids []*adapters.TypedBid
+		for _, bid := range payload.BidderResponse.Bids {
+			if bid.Bid.ImpID != e.impID {
+				bids = append(bids, bid)
+			}
+		}
+		payload.BidderResponse.Bids = bids
+		return payload, nil
+	}, hookstage.MutationUpdate, "bids")
+
+	return hookstage.HookResult[hookstage.RawBidderResponsePayload]{ChangeSet: c, SeatNonBid: []openrtb_ext.SeatNonBid{seatNonBid}}, nil
+}
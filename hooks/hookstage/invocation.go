@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 
 	"github.com/prebid/prebid-server/v3/hooks/hookanalytics"
+	"github.com/prebid/prebid-server/v3/openrtb_ext"
 )
 
 // HookResult represents the result of execution the concrete hook instance.
@@ -16,7 +17,8 @@ type HookResult[T any] struct {
 	Warnings      []string
 	DebugMessages []string
 	AnalyticsTags hookanalytics.Analytics
-	ModuleContext ModuleContext // holds values that the module wants to pass to itself at later stages
+	ModuleContext ModuleContext            // holds values that the module wants to pass to itself at later stages
+	SeatNonBid    []openrtb_ext.SeatNonBid // holds bids removed by the hook mutations, reported in bidresponse.ext.prebid.seatnonbid if all of them are applied
 }
 
 // ModuleInvocationContext holds data passed to the module hook during invocation.
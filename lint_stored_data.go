@@ -0,0 +1,63 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/prebid/prebid-server/v3/config"
+	"github.com/prebid/prebid-server/v3/exchange"
+	"github.com/prebid/prebid-server/v3/openrtb_ext"
+	"github.com/prebid/prebid-server/v3/ortb"
+	"github.com/prebid/prebid-server/v3/stored_requests/lint"
+	"github.com/prebid/prebid-server/v3/stored_requests/management"
+)
+
+const lintStoredDataCommand = "lint-stored-data"
+const paramsDirectory = "./static/bidder-params"
+
+// lintStoredData validates all the stored data of the filesystem and database backends of the config, and
+// writes the report as JSON to stdout. It returns the exit code: 0 if the stored data is valid, 1 if it has
+// issues, and 2 if it couldn't be loaded.
+func lintStoredData(cfg *config.Configuration, args []string, stdout io.Writer, stderr io.Writer) int {
+	flags := flag.NewFlagSet(lintStoredDataCommand, flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	dbQuery := flags.String("db-query", "", "The query which returns the id, data and type of all the stored data of the databases. Defaults to their database.initialize_caches.query.")
+	timeout := flags.Duration("timeout", 30*time.Second, "The timeout of the database queries.")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	paramsValidator, err := openrtb_ext.NewBidderParamsValidator(paramsDirectory)
+	if err != nil {
+		fmt.Fprintf(stderr, "Failed to create the bidder params validator: %v\n", err)
+		return 2
+	}
+	activeBidders := exchange.GetActiveBidders(cfg.BidderInfos)
+	disabledBidders := exchange.GetDisabledBidderWarningMessages(cfg.BidderInfos)
+	requestValidator := ortb.NewRequestValidator(activeBidders, disabledBidders, paramsValidator)
+	linter := lint.NewLinter(management.NewValidator(requestValidator, paramsValidator, activeBidders), cfg.AccountDefaults, cfg.AccountDefaultsJSON())
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+	data, err := lint.Load(ctx, []*config.StoredRequests{&cfg.StoredRequests, &cfg.StoredRequestsAMP, &cfg.StoredVideo, &cfg.StoredResponses, &cfg.Accounts}, *dbQuery)
+	if err != nil {
+		fmt.Fprintf(stderr, "Failed to load the stored data: %v\n", err)
+		return 2
+	}
+
+	report := linter.Lint(data)
+	encoder := json.NewEncoder(stdout)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(report); err != nil {
+		fmt.Fprintf(stderr, "Failed to write the report: %v\n", err)
+		return 2
+	}
+	if len(report.Issues) > 0 {
+		return 1
+	}
+	return 0
+}
@@ -3,6 +3,7 @@ package main
 import (
 	"flag"
 	"net/http"
+	"os"
 	"path/filepath"
 	"runtime"
 	"time"
@@ -41,6 +42,10 @@ func main() {
 		glog.Exitf("Configuration could not be loaded or did not pass validation: %v", err)
 	}
 
+	if flag.Arg(0) == lintStoredDataCommand {
+		os.Exit(lintStoredData(cfg, flag.Args()[1:], os.Stdout, os.Stderr))
+	}
+
 	// Create a soft memory limit on the total amount of memory that PBS uses to tune the behavior
 	// of the Go garbage collector. In summary, `cfg.GarbageCollectorThreshold` serves as a fixed cost
 	// of memory that is going to be held garbage before a garbage collection cycle is triggered.
@@ -78,7 +83,7 @@ func serve(cfg *config.Configuration) error {
 	}
 
 	corsRouter := router.SupportCORS(r)
-	if err := server.Listen(cfg, router.NoCache{Handler: corsRouter}, router.Admin(currencyConverter, fetchingInterval), r.MetricsEngine); err != nil {
+	if err := server.Listen(cfg, router.NoCache{Handler: corsRouter}, router.Admin(currencyConverter, fetchingInterval, r.AdminHandlers), r.MetricsEngine); err != nil {
 		glog.Fatalf("prebid-server returned an error: %v", err)
 	}
 
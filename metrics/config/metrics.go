@@ -3,9 +3,11 @@ package config
 import (
 	"time"
 
+	"github.com/golang/glog"
 	"github.com/prebid/prebid-server/v3/config"
 	"github.com/prebid/prebid-server/v3/metrics"
 	prometheusmetrics "github.com/prebid/prebid-server/v3/metrics/prometheus"
+	statsdmetrics "github.com/prebid/prebid-server/v3/metrics/statsd"
 	"github.com/prebid/prebid-server/v3/openrtb_ext"
 	gometrics "github.com/rcrowley/go-metrics"
 	influxdb "github.com/vrischmann/go-metrics-influxdb"
@@ -15,9 +17,9 @@ import (
 // for this instance.
 func NewMetricsEngine(cfg *config.Configuration, adapterList []openrtb_ext.BidderName, syncerKeys []string, moduleStageNames map[string][]string) *DetailedMetricsEngine {
 	// Create a list of metrics engines to use.
-	// Capacity of 2, as unlikely to have more than 2 metrics backends, and in the case
+	// Capacity of 3, as unlikely to have more than 3 metrics backends, and in the case
 	// of 1 we won't use the list so it will be garbage collected.
-	engineList := make(MultiMetricsEngine, 0, 2)
+	engineList := make(MultiMetricsEngine, 0, 3)
 	returnEngine := DetailedMetricsEngine{}
 
 	if cfg.Metrics.Influxdb.Host != "" {
@@ -43,6 +45,14 @@ func NewMetricsEngine(cfg *config.Configuration, adapterList []openrtb_ext.Bidde
 		returnEngine.PrometheusMetrics = prometheusmetrics.NewMetrics(cfg.Metrics.Prometheus, cfg.Metrics.Disabled, syncerKeys, moduleStageNames)
 		engineList = append(engineList, returnEngine.PrometheusMetrics)
 	}
+	if cfg.Metrics.StatsD.Address != "" {
+		statsDMetrics, err := statsdmetrics.NewMetrics(cfg.Metrics.StatsD, cfg.Metrics.Disabled)
+		if err != nil {
+			glog.Fatalf("Failed to create the StatsD metrics: %v", err)
+		}
+		returnEngine.StatsDMetrics = statsDMetrics
+		engineList = append(engineList, returnEngine.StatsDMetrics)
+	}
 
 	// Now return the proper metrics engine
 	if len(engineList) > 1 {
@@ -61,6 +71,7 @@ type DetailedMetricsEngine struct {
 	metrics.MetricsEngine
 	GoMetrics         *metrics.Metrics
 	PrometheusMetrics *prometheusmetrics.Metrics
+	StatsDMetrics     *statsdmetrics.Metrics
 }
 
 // MultiMetricsEngine logs metrics to multiple metrics databases The can be useful in transitioning
@@ -217,6 +228,20 @@ func (me *MultiMetricsEngine) RecordSyncerSet(key string, status metrics.SyncerS
 	}
 }
 
+// RecordUIDCookieTampered across all engines
+func (me *MultiMetricsEngine) RecordUIDCookieTampered() {
+	for _, thisME := range *me {
+		thisME.RecordUIDCookieTampered()
+	}
+}
+
+// RecordUIDCookieLegacyRejected across all engines
+func (me *MultiMetricsEngine) RecordUIDCookieLegacyRejected() {
+	for _, thisME := range *me {
+		thisME.RecordUIDCookieLegacyRejected()
+	}
+}
+
 // RecordStoredReqCacheResult across all engines
 func (me *MultiMetricsEngine) RecordStoredReqCacheResult(cacheResult metrics.CacheResult, inc int) {
 	for _, thisME := range *me {
@@ -238,6 +263,48 @@ func (me *MultiMetricsEngine) RecordAccountCacheResult(cacheResult metrics.Cache
 	}
 }
 
+// RecordAnalyticsEvents across all engines
+func (me *MultiMetricsEngine) RecordAnalyticsEvents(destination string, status metrics.AnalyticsEventStatus, inc int) {
+	for _, thisME := range *me {
+		thisME.RecordAnalyticsEvents(destination, status, inc)
+	}
+}
+
+// RecordAdapterAuctionBids across all engines
+func (me *MultiMetricsEngine) RecordAdapterAuctionBids(labels metrics.AdapterLabels, bids int) {
+	for _, thisME := range *me {
+		thisME.RecordAdapterAuctionBids(labels, bids)
+	}
+}
+
+// RecordAdapterWin across all engines
+func (me *MultiMetricsEngine) RecordAdapterWin(labels metrics.AdapterLabels, bidType openrtb_ext.BidType, cpm float64) {
+	for _, thisME := range *me {
+		thisME.RecordAdapterWin(labels, bidType, cpm)
+	}
+}
+
+// RecordAdapterWinPriceGap across all engines
+func (me *MultiMetricsEngine) RecordAdapterWinPriceGap(labels metrics.AdapterLabels, gap float64) {
+	for _, thisME := range *me {
+		thisME.RecordAdapterWinPriceGap(labels, gap)
+	}
+}
+
+// RecordImpBids across all engines
+func (me *MultiMetricsEngine) RecordImpBids(pubID string, bids int) {
+	for _, thisME := range *me {
+		thisME.RecordImpBids(pubID, bids)
+	}
+}
+
+// RecordBidNotification across all engines
+func (me *MultiMetricsEngine) RecordBidNotification(bidder openrtb_ext.BidderName, notificationType metrics.BidNotificationType, status metrics.BidNotificationStatus) {
+	for _, thisME := range *me {
+		thisME.RecordBidNotification(bidder, notificationType, status)
+	}
+}
+
 // RecordPrebidCacheRequestTime across all engines
 func (me *MultiMetricsEngine) RecordPrebidCacheRequestTime(success bool, length time.Duration) {
 	for _, thisME := range *me {
@@ -463,6 +530,14 @@ func (me *NilMetricsEngine) RecordSetUid(status metrics.SetUidStatus) {
 func (me *NilMetricsEngine) RecordSyncerSet(key string, status metrics.SyncerSetUidStatus) {
 }
 
+// RecordUIDCookieTampered as a noop
+func (me *NilMetricsEngine) RecordUIDCookieTampered() {
+}
+
+// RecordUIDCookieLegacyRejected as a noop
+func (me *NilMetricsEngine) RecordUIDCookieLegacyRejected() {
+}
+
 // RecordStoredReqCacheResult as a noop
 func (me *NilMetricsEngine) RecordStoredReqCacheResult(cacheResult metrics.CacheResult, inc int) {
 }
@@ -475,6 +550,30 @@ func (me *NilMetricsEngine) RecordStoredImpCacheResult(cacheResult metrics.Cache
 func (me *NilMetricsEngine) RecordAccountCacheResult(cacheResult metrics.CacheResult, inc int) {
 }
 
+// RecordAnalyticsEvents as a noop
+func (me *NilMetricsEngine) RecordAnalyticsEvents(destination string, status metrics.AnalyticsEventStatus, inc int) {
+}
+
+// RecordAdapterAuctionBids as a noop
+func (me *NilMetricsEngine) RecordAdapterAuctionBids(labels metrics.AdapterLabels, bids int) {
+}
+
+// RecordAdapterWin as a noop
+func (me *NilMetricsEngine) RecordAdapterWin(labels metrics.AdapterLabels, bidType openrtb_ext.BidType, cpm float64) {
+}
+
+// RecordAdapterWinPriceGap as a noop
+func (me *NilMetricsEngine) RecordAdapterWinPriceGap(labels metrics.AdapterLabels, gap float64) {
+}
+
+// RecordImpBids as a noop
+func (me *NilMetricsEngine) RecordImpBids(pubID string, bids int) {
+}
+
+// RecordBidNotification as a noop
+func (me *NilMetricsEngine) RecordBidNotification(bidder openrtb_ext.BidderName, notificationType metrics.BidNotificationType, status metrics.BidNotificationStatus) {
+}
+
 // RecordPrebidCacheRequestTime as a noop
 func (me *NilMetricsEngine) RecordPrebidCacheRequestTime(success bool, length time.Duration) {
 }
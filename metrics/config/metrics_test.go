@@ -8,6 +8,7 @@ import (
 
 	mainConfig "github.com/prebid/prebid-server/v3/config"
 	"github.com/prebid/prebid-server/v3/metrics"
+	statsdmetrics "github.com/prebid/prebid-server/v3/metrics/statsd"
 	"github.com/prebid/prebid-server/v3/openrtb_ext"
 
 	gometrics "github.com/rcrowley/go-metrics"
@@ -39,6 +40,25 @@ func TestGoMetricsEngine(t *testing.T) {
 	}
 }
 
+func TestStatsDMetricsEngine(t *testing.T) {
+	cfg := mainConfig.Configuration{}
+	cfg.Metrics.StatsD = mainConfig.StatsDMetrics{
+		Address:               "127.0.0.1:8125",
+		SampleRate:            1,
+		BufferFlushIntervalMS: 100,
+		MaxMessagesPerPayload: 1000,
+		WriteTimeoutMS:        100,
+	}
+	adapterList := make([]openrtb_ext.BidderName, 0, 2)
+	syncerKeys := []string{"keyA", "keyB"}
+	testEngine := NewMetricsEngine(&cfg, adapterList, syncerKeys, modulesStages)
+	defer testEngine.StatsDMetrics.Shutdown()
+	_, ok := testEngine.MetricsEngine.(*statsdmetrics.Metrics)
+	if !ok {
+		t.Error("Expected a StatsD Metrics as MetricsEngine, but didn't get it")
+	}
+}
+
 func TestMultiMetricsEngine(t *testing.T) {
 	cfg := mainConfig.Configuration{}
 	cfg.Metrics.Influxdb.Host = "localhost"
@@ -36,15 +36,22 @@ type Metrics struct {
 	BidderServerResponseTimer      metrics.Timer
 	StoredResponsesMeter           metrics.Meter
 
+	// Auction outcome metrics of the imps
+	AuctionImpMeter      metrics.Meter
+	AuctionNoBidImpMeter metrics.Meter
+	ImpBidsHistogram     metrics.Histogram
+
 	// Metrics for OpenRTB requests specifically
-	RequestStatuses       map[RequestType]map[RequestStatus]metrics.Meter
-	AmpNoCookieMeter      metrics.Meter
-	CookieSyncMeter       metrics.Meter
-	CookieSyncStatusMeter map[CookieSyncStatus]metrics.Meter
-	SyncerRequestsMeter   map[string]map[SyncerCookieSyncStatus]metrics.Meter
-	SetUidMeter           metrics.Meter
-	SetUidStatusMeter     map[SetUidStatus]metrics.Meter
-	SyncerSetsMeter       map[string]map[SyncerSetUidStatus]metrics.Meter
+	RequestStatuses              map[RequestType]map[RequestStatus]metrics.Meter
+	AmpNoCookieMeter             metrics.Meter
+	CookieSyncMeter              metrics.Meter
+	CookieSyncStatusMeter        map[CookieSyncStatus]metrics.Meter
+	SyncerRequestsMeter          map[string]map[SyncerCookieSyncStatus]metrics.Meter
+	SetUidMeter                  metrics.Meter
+	SetUidStatusMeter            map[SetUidStatus]metrics.Meter
+	SyncerSetsMeter              map[string]map[SyncerSetUidStatus]metrics.Meter
+	UIDCookieTamperedMeter       metrics.Meter
+	UIDCookieLegacyRejectedMeter metrics.Meter
 
 	// Media types found in the "imp" JSON object
 	ImpsTypeBanner metrics.Meter
@@ -107,6 +114,16 @@ type AdapterMetrics struct {
 
 	BidValidationSecureMarkupErrorMeter metrics.Meter
 	BidValidationSecureMarkupWarnMeter  metrics.Meter
+
+	AuctionOutcome *AuctionOutcomeMetrics
+}
+
+// AuctionOutcomeMetrics houses the metrics of the bids of an adapter which entered the auction
+type AuctionOutcomeMetrics struct {
+	BidsMeter            metrics.Meter
+	WinsMeter            metrics.Meter
+	WinPriceHistograms   map[openrtb_ext.BidType]metrics.Histogram
+	WinPriceGapHistogram metrics.Histogram
 }
 
 type MarkupDeliveryMetrics struct {
@@ -123,6 +140,11 @@ type accountMetrics struct {
 	adapterMetrics       map[string]*AdapterMetrics
 	moduleMetrics        map[string]*ModuleMetrics
 	storedResponsesMeter metrics.Meter
+	// store account by adapter auction outcome metrics. Type is map[PBSBidder.BidderCode]
+	auctionOutcomeMetrics map[string]*AuctionOutcomeMetrics
+	auctionImpMeter       metrics.Meter
+	auctionNoBidImpMeter  metrics.Meter
+	impBidsHistogram      metrics.Histogram
 
 	bidValidationCreativeSizeMeter     metrics.Meter
 	bidValidationCreativeSizeWarnMeter metrics.Meter
@@ -180,8 +202,14 @@ func NewBlankMetrics(registry metrics.Registry, exchanges []string, disabledMetr
 		SetUidMeter:                    blankMeter,
 		SetUidStatusMeter:              make(map[SetUidStatus]metrics.Meter),
 		SyncerSetsMeter:                make(map[string]map[SyncerSetUidStatus]metrics.Meter),
+		UIDCookieTamperedMeter:         blankMeter,
+		UIDCookieLegacyRejectedMeter:   blankMeter,
 		StoredResponsesMeter:           blankMeter,
 
+		AuctionImpMeter:      blankMeter,
+		AuctionNoBidImpMeter: blankMeter,
+		ImpBidsHistogram:     &metrics.NilHistogram{},
+
 		ImpsTypeBanner: blankMeter,
 		ImpsTypeVideo:  blankMeter,
 		ImpsTypeAudio:  blankMeter,
@@ -326,6 +354,9 @@ func NewMetrics(registry metrics.Registry, exchanges []openrtb_ext.BidderName, d
 		newMetrics.SetUidStatusMeter[s] = metrics.GetOrRegisterMeter(fmt.Sprintf("setuid_requests.%s", s), registry)
 	}
 
+	newMetrics.UIDCookieTamperedMeter = metrics.GetOrRegisterMeter("uids_cookie_tampered", registry)
+	newMetrics.UIDCookieLegacyRejectedMeter = metrics.GetOrRegisterMeter("uids_cookie_legacy_rejected", registry)
+
 	for _, syncerKey := range syncerKeys {
 		newMetrics.SyncerRequestsMeter[syncerKey] = make(map[SyncerCookieSyncStatus]metrics.Meter)
 		for _, status := range SyncerRequestStatuses() {
@@ -340,8 +371,13 @@ func NewMetrics(registry metrics.Registry, exchanges []openrtb_ext.BidderName, d
 
 	for _, a := range lowerCaseExchanges {
 		registerAdapterMetrics(registry, "adapter", string(a), newMetrics.AdapterMetrics[a])
+		registerAuctionOutcomeMetrics(registry, "adapter."+a, newMetrics.AdapterMetrics[a].AuctionOutcome)
 	}
 
+	newMetrics.AuctionImpMeter = metrics.GetOrRegisterMeter("auction.imps", registry)
+	newMetrics.AuctionNoBidImpMeter = metrics.GetOrRegisterMeter("auction.imps.nobid", registry)
+	newMetrics.ImpBidsHistogram = metrics.GetOrRegisterHistogram("auction.imp_bids", registry, metrics.NewExpDecaySample(1028, 0.015))
+
 	for typ, statusMap := range newMetrics.RequestStatuses {
 		for stat := range statusMap {
 			statusMap[stat] = metrics.GetOrRegisterMeter("requests."+string(stat)+"."+string(typ), registry)
@@ -401,6 +437,7 @@ func makeBlankAdapterMetrics(disabledMetrics config.DisabledMetrics) *AdapterMet
 		BidsReceivedMeter: blankMeter,
 		PanicMeter:        blankMeter,
 		MarkupMetrics:     makeBlankBidMarkupMetrics(),
+		AuctionOutcome:    makeBlankAuctionOutcomeMetrics(),
 	}
 	if !disabledMetrics.AdapterConnectionMetrics {
 		newAdapter.ConnCreated = metrics.NilCounter{}
@@ -450,6 +487,21 @@ func makeBlankBidMarkupMetrics() map[openrtb_ext.BidType]*MarkupDeliveryMetrics
 	}
 }
 
+func makeBlankAuctionOutcomeMetrics() *AuctionOutcomeMetrics {
+	blankHistogram := &metrics.NilHistogram{}
+	return &AuctionOutcomeMetrics{
+		BidsMeter: &metrics.NilMeter{},
+		WinsMeter: &metrics.NilMeter{},
+		WinPriceHistograms: map[openrtb_ext.BidType]metrics.Histogram{
+			openrtb_ext.BidTypeAudio:  blankHistogram,
+			openrtb_ext.BidTypeBanner: blankHistogram,
+			openrtb_ext.BidTypeNative: blankHistogram,
+			openrtb_ext.BidTypeVideo:  blankHistogram,
+		},
+		WinPriceGapHistogram: blankHistogram,
+	}
+}
+
 func makeBlankMarkupDeliveryMetrics() *MarkupDeliveryMetrics {
 	return &MarkupDeliveryMetrics{
 		AdmMeter:  &metrics.NilMeter{},
@@ -498,6 +550,15 @@ func registerAdapterMetrics(registry metrics.Registry, adapterOrAccount string,
 	am.BidValidationSecureMarkupWarnMeter = metrics.GetOrRegisterMeter(fmt.Sprintf("%[1]s.%[2]s.response.validation.secure.warn", adapterOrAccount, exchange), registry)
 }
 
+func registerAuctionOutcomeMetrics(registry metrics.Registry, prefix string, om *AuctionOutcomeMetrics) {
+	om.BidsMeter = metrics.GetOrRegisterMeter(prefix+".auction_bids", registry)
+	om.WinsMeter = metrics.GetOrRegisterMeter(prefix+".wins", registry)
+	for bidType := range om.WinPriceHistograms {
+		om.WinPriceHistograms[bidType] = metrics.GetOrRegisterHistogram(prefix+"."+string(bidType)+".win_prices", registry, metrics.NewExpDecaySample(1028, 0.015))
+	}
+	om.WinPriceGapHistogram = metrics.GetOrRegisterHistogram(prefix+".win_price_gap", registry, metrics.NewExpDecaySample(1028, 0.015))
+}
+
 func registerModuleMetrics(registry metrics.Registry, module string, stages []string, mm map[string]*ModuleMetrics) {
 	for _, stage := range stages {
 		mm[stage].DurationTimer = metrics.GetOrRegisterTimer(fmt.Sprintf("modules.module.%s.stage.%s.duration", module, stage), registry)
@@ -572,6 +633,17 @@ func (me *Metrics) getAccountMetrics(id string) *accountMetrics {
 	am.bidValidationSecureMarkupMeter = metrics.GetOrRegisterMeter(fmt.Sprintf("account.%s.response.validation.secure.err", id), me.MetricsRegistry)
 	am.bidValidationSecureMarkupWarnMeter = metrics.GetOrRegisterMeter(fmt.Sprintf("account.%s.response.validation.secure.warn", id), me.MetricsRegistry)
 
+	if !me.MetricsDisabled.AccountAuctionOutcome {
+		am.auctionOutcomeMetrics = make(map[string]*AuctionOutcomeMetrics, len(me.exchanges))
+		for _, a := range me.exchanges {
+			am.auctionOutcomeMetrics[a] = makeBlankAuctionOutcomeMetrics()
+			registerAuctionOutcomeMetrics(me.MetricsRegistry, fmt.Sprintf("account.%s.adapter.%s", id, a), am.auctionOutcomeMetrics[a])
+		}
+		am.auctionImpMeter = metrics.GetOrRegisterMeter(fmt.Sprintf("account.%s.auction.imps", id), me.MetricsRegistry)
+		am.auctionNoBidImpMeter = metrics.GetOrRegisterMeter(fmt.Sprintf("account.%s.auction.imps.nobid", id), me.MetricsRegistry)
+		am.impBidsHistogram = metrics.GetOrRegisterHistogram(fmt.Sprintf("account.%s.auction.imp_bids", id), me.MetricsRegistry, metrics.NewExpDecaySample(1028, 0.015))
+	}
+
 	if !me.MetricsDisabled.AccountModulesMetrics {
 		for _, mod := range me.modules {
 			am.moduleMetrics[mod] = makeBlankModuleMetrics()
@@ -863,6 +935,18 @@ func (me *Metrics) RecordSyncerSet(key string, status SyncerSetUidStatus) {
 	}
 }
 
+// RecordUIDCookieTampered implements a part of the MetricsEngine interface. Records a uids cookie
+// which failed its integrity check
+func (me *Metrics) RecordUIDCookieTampered() {
+	me.UIDCookieTamperedMeter.Mark(1)
+}
+
+// RecordUIDCookieLegacyRejected implements a part of the MetricsEngine interface. Records a legacy uids
+// cookie, neither signed nor encrypted, which was rejected
+func (me *Metrics) RecordUIDCookieLegacyRejected() {
+	me.UIDCookieLegacyRejectedMeter.Mark(1)
+}
+
 // RecordStoredReqCacheResult implements a part of the MetricsEngine interface. Records the
 // cache hits and misses when looking up stored requests
 func (me *Metrics) RecordStoredReqCacheResult(cacheResult CacheResult, inc int) {
@@ -881,6 +965,20 @@ func (me *Metrics) RecordAccountCacheResult(cacheResult CacheResult, inc int) {
 	me.AccountCacheMeter[cacheResult].Mark(int64(inc))
 }
 
+// RecordAnalyticsEvents implements a part of the MetricsEngine interface. Records the outcome of the
+// events of an analytics destination. The destinations are configured, so their meters are registered
+// on first use.
+func (me *Metrics) RecordAnalyticsEvents(destination string, status AnalyticsEventStatus, inc int) {
+	metrics.GetOrRegisterMeter(fmt.Sprintf("analytics_events.%s.%s", destination, status), me.MetricsRegistry).Mark(int64(inc))
+}
+
+// RecordBidNotification implements a part of the MetricsEngine interface. Records the outcome of a
+// notification URL of a bid.
+func (me *Metrics) RecordBidNotification(bidder openrtb_ext.BidderName, notificationType BidNotificationType, status BidNotificationStatus) {
+	name := fmt.Sprintf("adapter.%s.bid_notifications.%s.%s", strings.ToLower(string(bidder)), notificationType, status)
+	metrics.GetOrRegisterMeter(name, me.MetricsRegistry).Mark(1)
+}
+
 // RecordPrebidCacheRequestTime implements a part of the MetricsEngine interface. Records the
 // amount of time taken to store the auction result in Prebid Cache.
 func (me *Metrics) RecordPrebidCacheRequestTime(success bool, length time.Duration) {
@@ -973,6 +1071,71 @@ func (me *Metrics) RecordAdsCertSignTime(adsCertSignTime time.Duration) {
 	me.adsCertSignTimer.Update(adsCertSignTime)
 }
 
+// RecordAdapterAuctionBids implements a part of the MetricsEngine interface. Records the bids of an adapter
+// which entered the auction, from which its win rate is computed
+func (me *Metrics) RecordAdapterAuctionBids(labels AdapterLabels, bids int) {
+	me.recordAuctionOutcome(labels, func(om *AuctionOutcomeMetrics) {
+		om.BidsMeter.Mark(int64(bids))
+	})
+}
+
+// RecordAdapterWin implements a part of the MetricsEngine interface. Generates a histogram of winning bid
+// prices by media type
+func (me *Metrics) RecordAdapterWin(labels AdapterLabels, bidType openrtb_ext.BidType, cpm float64) {
+	me.recordAuctionOutcome(labels, func(om *AuctionOutcomeMetrics) {
+		om.WinsMeter.Mark(1)
+		if histogram, ok := om.WinPriceHistograms[bidType]; ok {
+			histogram.Update(int64(cpm))
+		}
+	})
+}
+
+// RecordAdapterWinPriceGap implements a part of the MetricsEngine interface. Generates a histogram of the
+// gaps between the winning bid prices and the best prices of the other adapters
+func (me *Metrics) RecordAdapterWinPriceGap(labels AdapterLabels, gap float64) {
+	me.recordAuctionOutcome(labels, func(om *AuctionOutcomeMetrics) {
+		om.WinPriceGapHistogram.Update(int64(gap))
+	})
+}
+
+// recordAuctionOutcome records an auction outcome metric of the adapter, and of its account unless disabled
+func (me *Metrics) recordAuctionOutcome(labels AdapterLabels, record func(om *AuctionOutcomeMetrics)) {
+	adapterStr := string(labels.Adapter)
+	lowercaseAdapter := strings.ToLower(adapterStr)
+	am, ok := me.AdapterMetrics[lowercaseAdapter]
+	if !ok {
+		glog.Errorf("Trying to run adapter auction outcome metrics on %s: adapter metrics not found", adapterStr)
+		return
+	}
+	// Adapter metrics
+	record(am.AuctionOutcome)
+	// Account-Adapter metrics
+	if labels.PubID != PublisherUnknown && !me.MetricsDisabled.AccountAuctionOutcome {
+		if aom, ok := me.getAccountMetrics(labels.PubID).auctionOutcomeMetrics[lowercaseAdapter]; ok {
+			record(aom)
+		}
+	}
+}
+
+// RecordImpBids implements a part of the MetricsEngine interface. Generates a histogram of the bids per
+// imp, and counts the imps without bids
+func (me *Metrics) RecordImpBids(pubID string, bids int) {
+	me.AuctionImpMeter.Mark(1)
+	if bids == 0 {
+		me.AuctionNoBidImpMeter.Mark(1)
+	}
+	me.ImpBidsHistogram.Update(int64(bids))
+
+	if pubID != PublisherUnknown && !me.MetricsDisabled.AccountAuctionOutcome {
+		am := me.getAccountMetrics(pubID)
+		am.auctionImpMeter.Mark(1)
+		if bids == 0 {
+			am.auctionNoBidImpMeter.Mark(1)
+		}
+		am.impBidsHistogram.Update(int64(bids))
+	}
+}
+
 func (me *Metrics) RecordBidValidationCreativeSizeError(adapter openrtb_ext.BidderName, pubID string) {
 	adapterStr := string(adapter)
 	am, ok := me.AdapterMetrics[strings.ToLower(adapterStr)]
@@ -36,6 +36,10 @@ func TestNewMetrics(t *testing.T) {
 	ensureContains(t, registry, "setuid_requests.gdpr_blocked_host_cookie", m.SetUidStatusMeter[SetUidGDPRHostCookieBlocked])
 	ensureContains(t, registry, "setuid_requests.syncer_unknown", m.SetUidStatusMeter[SetUidSyncerUnknown])
 	ensureContains(t, registry, "stored_responses", m.StoredResponsesMeter)
+	ensureContains(t, registry, "auction.imps", m.AuctionImpMeter)
+	ensureContains(t, registry, "auction.imps.nobid", m.AuctionNoBidImpMeter)
+	ensureContains(t, registry, "auction.imp_bids", m.ImpBidsHistogram)
+	ensureContainsAuctionOutcomeMetrics(t, registry, "adapter.adapter1", m.AdapterMetrics["adapter1"].AuctionOutcome)
 
 	ensureContains(t, registry, "prebid_cache_request_time.ok", m.PrebidCacheRequestTimerSuccess)
 	ensureContains(t, registry, "prebid_cache_request_time.err", m.PrebidCacheRequestTimerError)
@@ -85,6 +89,8 @@ func TestNewMetrics(t *testing.T) {
 	ensureContains(t, registry, "request_over_head_time.make-bidder-requests", m.OverheadTimer[MakeBidderRequests])
 	ensureContains(t, registry, "bidder_server_response_time_seconds", m.BidderServerResponseTimer)
 	ensureContains(t, registry, "tmax_timeout", m.TMaxTimeoutCounter)
+	ensureContains(t, registry, "uids_cookie_tampered", m.UIDCookieTamperedMeter)
+	ensureContains(t, registry, "uids_cookie_legacy_rejected", m.UIDCookieLegacyRejectedMeter)
 
 	for module, stages := range moduleStageNames {
 		for _, stage := range stages {
@@ -146,6 +152,16 @@ func ensureContainsAdapterMetrics(t *testing.T, registry metrics.Registry, name
 
 }
 
+func ensureContainsAuctionOutcomeMetrics(t *testing.T, registry metrics.Registry, name string, outcomeMetrics *AuctionOutcomeMetrics) {
+	t.Helper()
+	ensureContains(t, registry, name+".auction_bids", outcomeMetrics.BidsMeter)
+	ensureContains(t, registry, name+".wins", outcomeMetrics.WinsMeter)
+	for _, bidType := range openrtb_ext.BidTypes() {
+		ensureContains(t, registry, name+"."+string(bidType)+".win_prices", outcomeMetrics.WinPriceHistograms[bidType])
+	}
+	ensureContains(t, registry, name+".win_price_gap", outcomeMetrics.WinPriceGapHistogram)
+}
+
 func ensureContainsModuleMetrics(t *testing.T, registry metrics.Registry, name string, moduleMetrics *ModuleMetrics) {
 	t.Helper()
 	ensureContains(t, registry, name+".duration", moduleMetrics.DurationTimer)
@@ -684,6 +700,11 @@ func TestRecordStoredDataError(t *testing.T) {
 			dataType:    AccountDataType,
 			errorType:   StoredDataErrorNetwork,
 		},
+		{
+			description: "Increment stored_request_error.invalid meter",
+			dataType:    RequestDataType,
+			errorType:   StoredDataErrorInvalid,
+		},
 		{
 			description: "Increment stored_amp_error.network meter",
 			dataType:    AMPDataType,
@@ -929,6 +950,113 @@ func TestRecordSetUid(t *testing.T) {
 	assert.Equal(t, m.SetUidStatusMeter[SetUidSyncerUnknown].Count(), int64(0))
 }
 
+func TestRecordUIDCookieTampered(t *testing.T) {
+	registry := metrics.NewRegistry()
+	m := NewMetrics(registry, []openrtb_ext.BidderName{openrtb_ext.BidderName("Foo")}, config.DisabledMetrics{}, nil, nil)
+
+	m.RecordUIDCookieTampered()
+
+	assert.Equal(t, int64(1), m.UIDCookieTamperedMeter.Count())
+}
+
+func TestRecordUIDCookieLegacyRejected(t *testing.T) {
+	registry := metrics.NewRegistry()
+	m := NewMetrics(registry, []openrtb_ext.BidderName{openrtb_ext.BidderName("Foo")}, config.DisabledMetrics{}, nil, nil)
+
+	m.RecordUIDCookieLegacyRejected()
+
+	assert.Equal(t, int64(1), m.UIDCookieLegacyRejectedMeter.Count())
+}
+
+func TestRecordAnalyticsEvents(t *testing.T) {
+	registry := metrics.NewRegistry()
+	m := NewMetrics(registry, []openrtb_ext.BidderName{openrtb_ext.BidderName("Foo")}, config.DisabledMetrics{}, nil, nil)
+
+	m.RecordAnalyticsEvents("warehouse", AnalyticsEventSent, 100)
+	m.RecordAnalyticsEvents("warehouse", AnalyticsEventDropped, 3)
+	m.RecordAnalyticsEvents("warehouse", AnalyticsEventDropped, 2)
+
+	assert.Equal(t, int64(100), registry.Get("analytics_events.warehouse.sent").(metrics.Meter).Count())
+	assert.Equal(t, int64(5), registry.Get("analytics_events.warehouse.dropped").(metrics.Meter).Count())
+}
+
+func TestRecordBidNotification(t *testing.T) {
+	registry := metrics.NewRegistry()
+	m := NewMetrics(registry, []openrtb_ext.BidderName{openrtb_ext.BidderName("Foo")}, config.DisabledMetrics{}, nil, nil)
+
+	m.RecordBidNotification(openrtb_ext.BidderName("Foo"), BidNotificationLoss, BidNotificationSent)
+	m.RecordBidNotification(openrtb_ext.BidderName("foo"), BidNotificationLoss, BidNotificationSent)
+	m.RecordBidNotification(openrtb_ext.BidderName("foo"), BidNotificationWin, BidNotificationDropped)
+
+	assert.Equal(t, int64(2), registry.Get("adapter.foo.bid_notifications.loss.sent").(metrics.Meter).Count())
+	assert.Equal(t, int64(1), registry.Get("adapter.foo.bid_notifications.win.dropped").(metrics.Meter).Count())
+}
+
+func TestRecordAuctionOutcome(t *testing.T) {
+	testCases := []struct {
+		name                 string
+		disabled             config.DisabledMetrics
+		pubID                string
+		expectedAccountCount int64
+	}{
+		{
+			name:                 "account-enabled",
+			disabled:             config.DisabledMetrics{AccountAuctionOutcome: false},
+			pubID:                "acct",
+			expectedAccountCount: 1,
+		},
+		{
+			name:                 "account-disabled",
+			disabled:             config.DisabledMetrics{AccountAuctionOutcome: true},
+			pubID:                "acct",
+			expectedAccountCount: 0,
+		},
+		{
+			name:                 "account-unknown",
+			disabled:             config.DisabledMetrics{AccountAuctionOutcome: false},
+			pubID:                PublisherUnknown,
+			expectedAccountCount: 0,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.name, func(t *testing.T) {
+			registry := metrics.NewRegistry()
+			m := NewMetrics(registry, []openrtb_ext.BidderName{openrtb_ext.BidderName("Foo")}, test.disabled, nil, nil)
+			labels := AdapterLabels{Adapter: openrtb_ext.BidderName("Foo"), PubID: test.pubID}
+
+			m.RecordAdapterAuctionBids(labels, 3)
+			m.RecordAdapterWin(labels, openrtb_ext.BidTypeVideo, 1500)
+			m.RecordAdapterWinPriceGap(labels, 250)
+			m.RecordImpBids(test.pubID, 3)
+			m.RecordImpBids(test.pubID, 0)
+			m.RecordAdapterWin(AdapterLabels{Adapter: openrtb_ext.BidderName("unknown"), PubID: test.pubID}, openrtb_ext.BidTypeVideo, 1500)
+
+			assert.Equal(t, int64(3), registry.Get("adapter.foo.auction_bids").(metrics.Meter).Count())
+			assert.Equal(t, int64(1), registry.Get("adapter.foo.wins").(metrics.Meter).Count())
+			assert.Equal(t, int64(1500), registry.Get("adapter.foo.video.win_prices").(metrics.Histogram).Sum())
+			assert.Equal(t, int64(0), registry.Get("adapter.foo.banner.win_prices").(metrics.Histogram).Count())
+			assert.Equal(t, int64(250), registry.Get("adapter.foo.win_price_gap").(metrics.Histogram).Sum())
+			assert.Equal(t, int64(2), registry.Get("auction.imps").(metrics.Meter).Count())
+			assert.Equal(t, int64(1), registry.Get("auction.imps.nobid").(metrics.Meter).Count())
+			assert.Equal(t, int64(3), registry.Get("auction.imp_bids").(metrics.Histogram).Sum())
+
+			if test.expectedAccountCount == 0 {
+				assert.Nil(t, registry.Get("account."+test.pubID+".adapter.foo.wins"))
+				assert.Nil(t, registry.Get("account."+test.pubID+".auction.imps"))
+				return
+			}
+			assert.Equal(t, int64(3), registry.Get("account.acct.adapter.foo.auction_bids").(metrics.Meter).Count())
+			assert.Equal(t, int64(1), registry.Get("account.acct.adapter.foo.wins").(metrics.Meter).Count())
+			assert.Equal(t, int64(1500), registry.Get("account.acct.adapter.foo.video.win_prices").(metrics.Histogram).Sum())
+			assert.Equal(t, int64(250), registry.Get("account.acct.adapter.foo.win_price_gap").(metrics.Histogram).Sum())
+			assert.Equal(t, int64(2), registry.Get("account.acct.auction.imps").(metrics.Meter).Count())
+			assert.Equal(t, int64(1), registry.Get("account.acct.auction.imps.nobid").(metrics.Meter).Count())
+			assert.Equal(t, int64(2), registry.Get("account.acct.auction.imp_bids").(metrics.Histogram).Count())
+		})
+	}
+}
+
 func TestRecordSyncerSet(t *testing.T) {
 	registry := metrics.NewRegistry()
 	syncerKeys := []string{"foo"}
@@ -120,12 +120,14 @@ type StoredDataLabels struct {
 type StoredDataError string
 
 const (
+	StoredDataErrorInvalid   StoredDataError = "invalid"
 	StoredDataErrorNetwork   StoredDataError = "network"
 	StoredDataErrorUndefined StoredDataError = "undefined"
 )
 
 func StoredDataErrors() []StoredDataError {
 	return []StoredDataError{
+		StoredDataErrorInvalid,
 		StoredDataErrorNetwork,
 		StoredDataErrorUndefined,
 	}
@@ -293,6 +295,11 @@ const (
 	// CacheMiss represents a cache miss i.e that key wasn't found in cache
 	// and had to be fetched from the backend
 	CacheMiss CacheResult = "miss"
+	// CacheCoalesced represents a cache miss whose key was already being fetched from the backend by
+	// another request, whose result was used
+	CacheCoalesced CacheResult = "coalesced"
+	// CacheNegativeHit represents a cache miss whose key is known to be missing from the backend
+	CacheNegativeHit CacheResult = "negative_hit"
 )
 
 // CacheResults returns possible cache results i.e. cache hit or miss
@@ -300,6 +307,71 @@ func CacheResults() []CacheResult {
 	return []CacheResult{
 		CacheHit,
 		CacheMiss,
+		CacheCoalesced,
+		CacheNegativeHit,
+	}
+}
+
+// AnalyticsEventStatus is the outcome of the events of an analytics destination
+type AnalyticsEventStatus string
+
+const (
+	// AnalyticsEventSent represents the events which were posted to the destination
+	AnalyticsEventSent AnalyticsEventStatus = "sent"
+	// AnalyticsEventDropped represents the events which were dropped because the queue of the destination was full
+	AnalyticsEventDropped AnalyticsEventStatus = "dropped"
+	// AnalyticsEventFailed represents the events whose post failed after all the retries
+	AnalyticsEventFailed AnalyticsEventStatus = "failed"
+)
+
+// AnalyticsEventStatuses returns the possible outcomes of the analytics events
+func AnalyticsEventStatuses() []AnalyticsEventStatus {
+	return []AnalyticsEventStatus{
+		AnalyticsEventSent,
+		AnalyticsEventDropped,
+		AnalyticsEventFailed,
+	}
+}
+
+// BidNotificationType is the notification URL of a bid which is fired
+type BidNotificationType string
+
+const (
+	// BidNotificationWin represents the bid.nurl, fired on the win event of the bid
+	BidNotificationWin BidNotificationType = "win"
+	// BidNotificationBilling represents the bid.burl, fired on the impression event of the bid
+	BidNotificationBilling BidNotificationType = "billing"
+	// BidNotificationLoss represents the bid.lurl, fired once the bid lost the auction
+	BidNotificationLoss BidNotificationType = "loss"
+)
+
+// BidNotificationTypes returns the notification URLs of the bids
+func BidNotificationTypes() []BidNotificationType {
+	return []BidNotificationType{
+		BidNotificationWin,
+		BidNotificationBilling,
+		BidNotificationLoss,
+	}
+}
+
+// BidNotificationStatus is the outcome of a bid notification
+type BidNotificationStatus string
+
+const (
+	// BidNotificationSent represents the notifications whose URL responded with a 2xx status
+	BidNotificationSent BidNotificationStatus = "sent"
+	// BidNotificationFailed represents the notifications whose URL failed or responded with another status
+	BidNotificationFailed BidNotificationStatus = "failed"
+	// BidNotificationDropped represents the notifications which were dropped because the queue was full
+	BidNotificationDropped BidNotificationStatus = "dropped"
+)
+
+// BidNotificationStatuses returns the possible outcomes of the bid notifications
+func BidNotificationStatuses() []BidNotificationStatus {
+	return []BidNotificationStatus{
+		BidNotificationSent,
+		BidNotificationFailed,
+		BidNotificationDropped,
 	}
 }
 
@@ -442,13 +514,21 @@ type MetricsEngine interface {
 	RecordAdapterBidReceived(labels AdapterLabels, bidType openrtb_ext.BidType, hasAdm bool)
 	RecordAdapterPrice(labels AdapterLabels, cpm float64)
 	RecordAdapterTime(labels AdapterLabels, length time.Duration)
+	RecordAdapterAuctionBids(labels AdapterLabels, bids int)                         // bids of the adapter which entered the auction
+	RecordAdapterWin(labels AdapterLabels, bidType openrtb_ext.BidType, cpm float64) // winning bid of an imp
+	RecordAdapterWinPriceGap(labels AdapterLabels, gap float64)                      // winning price minus the best price of the other adapters
+	RecordImpBids(pubID string, bids int)                                            // bids which entered the auction of an imp, 0 if it got none
 	RecordCookieSync(status CookieSyncStatus)
 	RecordSyncerRequest(key string, status SyncerCookieSyncStatus)
 	RecordSetUid(status SetUidStatus)
 	RecordSyncerSet(key string, status SyncerSetUidStatus)
+	RecordUIDCookieTampered()
+	RecordUIDCookieLegacyRejected()
 	RecordStoredReqCacheResult(cacheResult CacheResult, inc int)
 	RecordStoredImpCacheResult(cacheResult CacheResult, inc int)
 	RecordAccountCacheResult(cacheResult CacheResult, inc int)
+	RecordAnalyticsEvents(destination string, status AnalyticsEventStatus, inc int)
+	RecordBidNotification(bidder openrtb_ext.BidderName, notificationType BidNotificationType, status BidNotificationStatus)
 	RecordStoredDataFetchTime(labels StoredDataLabels, length time.Duration)
 	RecordStoredDataError(labels StoredDataLabels)
 	RecordPrebidCacheRequestTime(success bool, length time.Duration)
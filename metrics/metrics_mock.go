@@ -121,6 +121,16 @@ func (me *MetricsEngineMock) RecordSyncerSet(key string, status SyncerSetUidStat
 	me.Called(key, status)
 }
 
+// RecordUIDCookieTampered mock
+func (me *MetricsEngineMock) RecordUIDCookieTampered() {
+	me.Called()
+}
+
+// RecordUIDCookieLegacyRejected mock
+func (me *MetricsEngineMock) RecordUIDCookieLegacyRejected() {
+	me.Called()
+}
+
 // RecordStoredReqCacheResult mock
 func (me *MetricsEngineMock) RecordStoredReqCacheResult(cacheResult CacheResult, inc int) {
 	me.Called(cacheResult, inc)
@@ -136,6 +146,36 @@ func (me *MetricsEngineMock) RecordAccountCacheResult(cacheResult CacheResult, i
 	me.Called(cacheResult, inc)
 }
 
+// RecordAnalyticsEvents mock
+func (me *MetricsEngineMock) RecordAnalyticsEvents(destination string, status AnalyticsEventStatus, inc int) {
+	me.Called(destination, status, inc)
+}
+
+// RecordAdapterAuctionBids mock
+func (me *MetricsEngineMock) RecordAdapterAuctionBids(labels AdapterLabels, bids int) {
+	me.Called(labels, bids)
+}
+
+// RecordAdapterWin mock
+func (me *MetricsEngineMock) RecordAdapterWin(labels AdapterLabels, bidType openrtb_ext.BidType, cpm float64) {
+	me.Called(labels, bidType, cpm)
+}
+
+// RecordAdapterWinPriceGap mock
+func (me *MetricsEngineMock) RecordAdapterWinPriceGap(labels AdapterLabels, gap float64) {
+	me.Called(labels, gap)
+}
+
+// RecordImpBids mock
+func (me *MetricsEngineMock) RecordImpBids(pubID string, bids int) {
+	me.Called(pubID, bids)
+}
+
+// RecordBidNotification mock
+func (me *MetricsEngineMock) RecordBidNotification(bidder openrtb_ext.BidderName, notificationType BidNotificationType, status BidNotificationStatus) {
+	me.Called(bidder, notificationType, status)
+}
+
 // RecordPrebidCacheRequestTime mock
 func (me *MetricsEngineMock) RecordPrebidCacheRequestTime(success bool, length time.Duration) {
 	me.Called(success, length)
@@ -168,6 +168,12 @@ func preloadLabelValues(m *Metrics, syncerKeys []string, moduleStageNames map[st
 		hasBidsLabel: boolValues,
 	})
 
+	// The auction outcome metrics of the adapters aren't preloaded, since most adapters of a host never enter its
+	// auctions and they would add 7 metrics per adapter
+	preloadLabelValuesForCounter(m.auctionImps, map[string][]string{
+		hasBidsLabel: boolValues,
+	})
+
 	preloadLabelValuesForCounter(m.adsCertRequests, map[string][]string{
 		successLabel: boolValues,
 	})
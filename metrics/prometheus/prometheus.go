@@ -25,6 +25,8 @@ type Metrics struct {
 	connectionsOpened            prometheus.Counter
 	cookieSync                   *prometheus.CounterVec
 	setUid                       *prometheus.CounterVec
+	uidCookieTampered            prometheus.Counter
+	uidCookieLegacyRejected      prometheus.Counter
 	impressions                  *prometheus.CounterVec
 	prebidCacheWriteTimer        *prometheus.HistogramVec
 	requests                     *prometheus.CounterVec
@@ -35,6 +37,8 @@ type Metrics struct {
 	storedImpressionsCacheResult *prometheus.CounterVec
 	storedRequestCacheResult     *prometheus.CounterVec
 	accountCacheResult           *prometheus.CounterVec
+	analyticsEvents              *prometheus.CounterVec
+	bidNotifications             *prometheus.CounterVec
 	storedAccountFetchTimer      *prometheus.HistogramVec
 	storedAccountErrors          *prometheus.CounterVec
 	storedAMPFetchTimer          *prometheus.HistogramVec
@@ -58,6 +62,8 @@ type Metrics struct {
 	adsCertRequests              *prometheus.CounterVec
 	adsCertSignTimer             prometheus.Histogram
 	bidderServerResponseTimer    prometheus.Histogram
+	auctionImps                  *prometheus.CounterVec
+	impBids                      prometheus.Histogram
 
 	// Adapter Metrics
 	adapterBids                           *prometheus.CounterVec
@@ -76,6 +82,10 @@ type Metrics struct {
 	adapterBidResponseValidationSizeWarn  *prometheus.CounterVec
 	adapterBidResponseSecureMarkupError   *prometheus.CounterVec
 	adapterBidResponseSecureMarkupWarn    *prometheus.CounterVec
+	adapterAuctionBids                    *prometheus.CounterVec
+	adapterWins                           *prometheus.CounterVec
+	adapterWinPrices                      *prometheus.HistogramVec
+	adapterWinPriceGaps                   *prometheus.HistogramVec
 
 	// Syncer Metrics
 	syncerRequests *prometheus.CounterVec
@@ -89,6 +99,12 @@ type Metrics struct {
 	accountBidResponseValidationSizeWarn  *prometheus.CounterVec
 	accountBidResponseSecureMarkupError   *prometheus.CounterVec
 	accountBidResponseSecureMarkupWarn    *prometheus.CounterVec
+	accountAdapterAuctionBids             *prometheus.CounterVec
+	accountAdapterWins                    *prometheus.CounterVec
+	accountAdapterWinPrices               *prometheus.HistogramVec
+	accountAdapterWinPriceGaps            *prometheus.HistogramVec
+	accountAuctionImps                    *prometheus.CounterVec
+	accountImpBids                        *prometheus.HistogramVec
 
 	// Module Metrics as a map where the key is the module name
 	moduleDuration        map[string]*prometheus.HistogramVec
@@ -110,9 +126,11 @@ const (
 	adapterLabel         = "adapter"
 	bidTypeLabel         = "bid_type"
 	cacheResultLabel     = "cache_result"
+	destinationLabel     = "destination"
 	connectionErrorLabel = "connection_error"
 	cookieLabel          = "cookie"
 	hasBidsLabel         = "has_bids"
+	notificationLabel    = "notification"
 	isAudioLabel         = "audio"
 	isBannerLabel        = "banner"
 	isNativeLabel        = "native"
@@ -167,6 +185,8 @@ func NewMetrics(cfg config.PrometheusMetrics, disabledMetrics config.DisabledMet
 	priceBuckets := []float64{250, 500, 750, 1000, 1500, 2000, 2500, 3000, 3500, 4000}
 	queuedRequestTimeBuckets := []float64{0, 1, 5, 30, 60, 120, 180, 240, 300}
 	overheadTimeBuckets := []float64{0.05, 0.06, 0.07, 0.08, 0.09, 0.1, 0.2, 0.3, 0.4, 0.5, 0.6, 0.7, 0.8, 0.9, 1}
+	priceGapBuckets := []float64{10, 25, 50, 100, 250, 500, 1000, 2000}
+	impBidsBuckets := []float64{0, 1, 2, 3, 4, 5, 10, 20}
 
 	metrics := Metrics{}
 	reg := prometheus.NewRegistry()
@@ -199,6 +219,24 @@ func NewMetrics(cfg config.PrometheusMetrics, disabledMetrics config.DisabledMet
 		"Count of set uid requests to Prebid Server.",
 		[]string{statusLabel})
 
+	metrics.uidCookieTampered = newCounterWithoutLabels(cfg, reg,
+		"uids_cookie_tampered",
+		"Count of uids cookies which failed their integrity check and were treated as empty.")
+
+	metrics.uidCookieLegacyRejected = newCounterWithoutLabels(cfg, reg,
+		"uids_cookie_legacy_rejected",
+		"Count of legacy uids cookies, neither signed nor encrypted, which were rejected and treated as empty.")
+
+	metrics.analyticsEvents = newCounter(cfg, reg,
+		"analytics_events",
+		"Count of the events of the analytics destinations labeled by destination and status.",
+		[]string{destinationLabel, statusLabel})
+
+	metrics.bidNotifications = newCounter(cfg, reg,
+		"bid_notifications",
+		"Count of the notification URLs of the bids fired by Prebid Server labeled by adapter, notification and status.",
+		[]string{adapterLabel, notificationLabel, statusLabel})
+
 	metrics.impressions = newCounter(cfg, reg,
 		"impressions_requests",
 		"Count of requested impressions to Prebid Server labeled by type.",
@@ -389,6 +427,38 @@ func NewMetrics(cfg config.PrometheusMetrics, disabledMetrics config.DisabledMet
 		"Count of requests labeled by adapter, if has a cookie, and if it resulted in bids.",
 		[]string{adapterLabel, cookieLabel, hasBidsLabel})
 
+	metrics.adapterAuctionBids = newCounter(cfg, reg,
+		"adapter_auction_bids",
+		"Count of bids which entered the auction labeled by adapter.",
+		[]string{adapterLabel})
+
+	metrics.adapterWins = newCounter(cfg, reg,
+		"adapter_wins",
+		"Count of imps won labeled by adapter.",
+		[]string{adapterLabel})
+
+	metrics.adapterWinPrices = newHistogramVec(cfg, reg,
+		"adapter_win_prices",
+		"Monetary value of the winning bids labeled by adapter and media type.",
+		[]string{adapterLabel, bidTypeLabel},
+		priceBuckets)
+
+	metrics.adapterWinPriceGaps = newHistogramVec(cfg, reg,
+		"adapter_win_price_gap",
+		"Monetary value by which the winning bids beat the best bids of the other adapters labeled by adapter.",
+		[]string{adapterLabel},
+		priceGapBuckets)
+
+	metrics.auctionImps = newCounter(cfg, reg,
+		"auction_imps",
+		"Count of imps auctioned labeled by whether they received bids.",
+		[]string{hasBidsLabel})
+
+	metrics.impBids = newHistogram(cfg, reg,
+		"imp_bids",
+		"Number of bids which entered the auction of an imp.",
+		impBidsBuckets)
+
 	if !metrics.metricsDisabled.AdapterConnectionMetrics {
 		metrics.adapterCreatedConnections = newCounter(cfg, reg,
 			"adapter_connection_created",
@@ -490,6 +560,39 @@ func NewMetrics(cfg config.PrometheusMetrics, disabledMetrics config.DisabledMet
 		[]string{requestTypeLabel, requestStatusLabel},
 		queuedRequestTimeBuckets)
 
+	metrics.accountAdapterAuctionBids = newCounter(cfg, reg,
+		"account_adapter_auction_bids",
+		"Count of bids which entered the auction labeled by account and adapter.",
+		[]string{accountLabel, adapterLabel})
+
+	metrics.accountAdapterWins = newCounter(cfg, reg,
+		"account_adapter_wins",
+		"Count of imps won labeled by account and adapter.",
+		[]string{accountLabel, adapterLabel})
+
+	metrics.accountAdapterWinPrices = newHistogramVec(cfg, reg,
+		"account_adapter_win_prices",
+		"Monetary value of the winning bids labeled by account, adapter and media type.",
+		[]string{accountLabel, adapterLabel, bidTypeLabel},
+		priceBuckets)
+
+	metrics.accountAdapterWinPriceGaps = newHistogramVec(cfg, reg,
+		"account_adapter_win_price_gap",
+		"Monetary value by which the winning bids beat the best bids of the other adapters labeled by account and adapter.",
+		[]string{accountLabel, adapterLabel},
+		priceGapBuckets)
+
+	metrics.accountAuctionImps = newCounter(cfg, reg,
+		"account_auction_imps",
+		"Count of imps auctioned labeled by account and whether they received bids.",
+		[]string{accountLabel, hasBidsLabel})
+
+	metrics.accountImpBids = newHistogramVec(cfg, reg,
+		"account_imp_bids",
+		"Number of bids which entered the auction of an imp labeled by account.",
+		[]string{accountLabel},
+		impBidsBuckets)
+
 	metrics.accountStoredResponses = newCounter(cfg, reg,
 		"account_stored_responses",
 		"Count of total requests to Prebid Server that have stored responses labled by account",
@@ -844,6 +947,79 @@ func (m *Metrics) RecordAdapterPrice(labels metrics.AdapterLabels, cpm float64)
 	}).Observe(cpm)
 }
 
+func (m *Metrics) RecordAdapterAuctionBids(labels metrics.AdapterLabels, bids int) {
+	lowerCasedAdapter := strings.ToLower(string(labels.Adapter))
+	m.adapterAuctionBids.With(prometheus.Labels{
+		adapterLabel: lowerCasedAdapter,
+	}).Add(float64(bids))
+
+	if m.accountAuctionOutcomeEnabled(labels.PubID) {
+		m.accountAdapterAuctionBids.With(prometheus.Labels{
+			accountLabel: labels.PubID,
+			adapterLabel: lowerCasedAdapter,
+		}).Add(float64(bids))
+	}
+}
+
+func (m *Metrics) RecordAdapterWin(labels metrics.AdapterLabels, bidType openrtb_ext.BidType, cpm float64) {
+	lowerCasedAdapter := strings.ToLower(string(labels.Adapter))
+	m.adapterWins.With(prometheus.Labels{
+		adapterLabel: lowerCasedAdapter,
+	}).Inc()
+	m.adapterWinPrices.With(prometheus.Labels{
+		adapterLabel: lowerCasedAdapter,
+		bidTypeLabel: string(bidType),
+	}).Observe(cpm)
+
+	if m.accountAuctionOutcomeEnabled(labels.PubID) {
+		m.accountAdapterWins.With(prometheus.Labels{
+			accountLabel: labels.PubID,
+			adapterLabel: lowerCasedAdapter,
+		}).Inc()
+		m.accountAdapterWinPrices.With(prometheus.Labels{
+			accountLabel: labels.PubID,
+			adapterLabel: lowerCasedAdapter,
+			bidTypeLabel: string(bidType),
+		}).Observe(cpm)
+	}
+}
+
+func (m *Metrics) RecordAdapterWinPriceGap(labels metrics.AdapterLabels, gap float64) {
+	lowerCasedAdapter := strings.ToLower(string(labels.Adapter))
+	m.adapterWinPriceGaps.With(prometheus.Labels{
+		adapterLabel: lowerCasedAdapter,
+	}).Observe(gap)
+
+	if m.accountAuctionOutcomeEnabled(labels.PubID) {
+		m.accountAdapterWinPriceGaps.With(prometheus.Labels{
+			accountLabel: labels.PubID,
+			adapterLabel: lowerCasedAdapter,
+		}).Observe(gap)
+	}
+}
+
+func (m *Metrics) RecordImpBids(pubID string, bids int) {
+	hasBids := strconv.FormatBool(bids > 0)
+	m.auctionImps.With(prometheus.Labels{
+		hasBidsLabel: hasBids,
+	}).Inc()
+	m.impBids.Observe(float64(bids))
+
+	if m.accountAuctionOutcomeEnabled(pubID) {
+		m.accountAuctionImps.With(prometheus.Labels{
+			accountLabel: pubID,
+			hasBidsLabel: hasBids,
+		}).Inc()
+		m.accountImpBids.With(prometheus.Labels{
+			accountLabel: pubID,
+		}).Observe(float64(bids))
+	}
+}
+
+func (m *Metrics) accountAuctionOutcomeEnabled(pubID string) bool {
+	return !m.metricsDisabled.AccountAuctionOutcome && pubID != metrics.PublisherUnknown
+}
+
 func (m *Metrics) RecordOverheadTime(overhead metrics.OverheadType, duration time.Duration) {
 	m.overheadTimer.With(prometheus.Labels{
 		overheadTypeLabel: overhead.String(),
@@ -884,6 +1060,29 @@ func (m *Metrics) RecordSyncerSet(key string, status metrics.SyncerSetUidStatus)
 	}).Inc()
 }
 
+func (m *Metrics) RecordUIDCookieTampered() {
+	m.uidCookieTampered.Inc()
+}
+
+func (m *Metrics) RecordUIDCookieLegacyRejected() {
+	m.uidCookieLegacyRejected.Inc()
+}
+
+func (m *Metrics) RecordAnalyticsEvents(destination string, status metrics.AnalyticsEventStatus, inc int) {
+	m.analyticsEvents.With(prometheus.Labels{
+		destinationLabel: destination,
+		statusLabel:      string(status),
+	}).Add(float64(inc))
+}
+
+func (m *Metrics) RecordBidNotification(bidder openrtb_ext.BidderName, notificationType metrics.BidNotificationType, status metrics.BidNotificationStatus) {
+	m.bidNotifications.With(prometheus.Labels{
+		adapterLabel:      strings.ToLower(string(bidder)),
+		notificationLabel: string(notificationType),
+		statusLabel:       string(status),
+	}).Inc()
+}
+
 func (m *Metrics) RecordStoredReqCacheResult(cacheResult metrics.CacheResult, inc int) {
 	m.storedRequestCacheResult.With(prometheus.Labels{
 		cacheResultLabel: string(cacheResult),
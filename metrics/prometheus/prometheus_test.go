@@ -677,6 +677,12 @@ func TestRecordStoredDataError(t *testing.T) {
 			errorType:   metrics.StoredDataErrorNetwork,
 			metricName:  "stored_account_errors",
 		},
+		{
+			description: "Update stored_request_errors counter with invalid label",
+			dataType:    metrics.RequestDataType,
+			errorType:   metrics.StoredDataErrorInvalid,
+			metricName:  "stored_request_errors",
+		},
 		{
 			description: "Update stored_amp_errors counter with network label",
 			dataType:    metrics.AMPDataType,
@@ -1179,6 +1185,107 @@ func TestAccountCacheResultMetric(t *testing.T) {
 		})
 }
 
+func TestAnalyticsEventsMetric(t *testing.T) {
+	m := createMetricsForTesting()
+
+	m.RecordAnalyticsEvents("warehouse", metrics.AnalyticsEventSent, 100)
+	m.RecordAnalyticsEvents("warehouse", metrics.AnalyticsEventDropped, 3)
+
+	assertCounterVecValue(t, "", "analyticsEvents:sent", m.analyticsEvents,
+		100,
+		prometheus.Labels{
+			destinationLabel: "warehouse",
+			statusLabel:      string(metrics.AnalyticsEventSent),
+		})
+	assertCounterVecValue(t, "", "analyticsEvents:dropped", m.analyticsEvents,
+		3,
+		prometheus.Labels{
+			destinationLabel: "warehouse",
+			statusLabel:      string(metrics.AnalyticsEventDropped),
+		})
+}
+
+func TestBidNotificationMetric(t *testing.T) {
+	m := createMetricsForTesting()
+
+	m.RecordBidNotification(openrtb_ext.BidderName("AppNexus"), metrics.BidNotificationLoss, metrics.BidNotificationSent)
+	m.RecordBidNotification(openrtb_ext.BidderName("appnexus"), metrics.BidNotificationLoss, metrics.BidNotificationSent)
+	m.RecordBidNotification(openrtb_ext.BidderName("appnexus"), metrics.BidNotificationWin, metrics.BidNotificationFailed)
+
+	assertCounterVecValue(t, "", "bidNotifications:loss:sent", m.bidNotifications,
+		2,
+		prometheus.Labels{
+			adapterLabel:      "appnexus",
+			notificationLabel: string(metrics.BidNotificationLoss),
+			statusLabel:       string(metrics.BidNotificationSent),
+		})
+	assertCounterVecValue(t, "", "bidNotifications:win:failed", m.bidNotifications,
+		1,
+		prometheus.Labels{
+			adapterLabel:      "appnexus",
+			notificationLabel: string(metrics.BidNotificationWin),
+			statusLabel:       string(metrics.BidNotificationFailed),
+		})
+}
+
+func TestAuctionOutcomeMetrics(t *testing.T) {
+	testCases := []struct {
+		name                 string
+		accountOutcome       bool
+		pubID                string
+		expectedAccountCount float64
+	}{
+		{
+			name:                 "account-enabled",
+			accountOutcome:       true,
+			pubID:                "acct",
+			expectedAccountCount: 1,
+		},
+		{
+			name:                 "account-disabled",
+			accountOutcome:       false,
+			pubID:                "acct",
+			expectedAccountCount: 0,
+		},
+		{
+			name:                 "account-unknown",
+			accountOutcome:       true,
+			pubID:                metrics.PublisherUnknown,
+			expectedAccountCount: 0,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.name, func(t *testing.T) {
+			m := NewMetrics(config.PrometheusMetrics{}, config.DisabledMetrics{AccountAuctionOutcome: !test.accountOutcome}, nil, nil)
+			labels := metrics.AdapterLabels{Adapter: openrtb_ext.BidderName("AppNexus"), PubID: test.pubID}
+
+			m.RecordAdapterAuctionBids(labels, 3)
+			m.RecordAdapterWin(labels, openrtb_ext.BidTypeVideo, 1500)
+			m.RecordAdapterWinPriceGap(labels, 250)
+			m.RecordImpBids(test.pubID, 3)
+			m.RecordImpBids(test.pubID, 0)
+
+			adapter := prometheus.Labels{adapterLabel: "appnexus"}
+			assertCounterVecValue(t, "", "adapterAuctionBids", m.adapterAuctionBids, 3, adapter)
+			assertCounterVecValue(t, "", "adapterWins", m.adapterWins, 1, adapter)
+			assertHistogram(t, "adapterWinPrices", getHistogramFromHistogramVecByTwoKeys(m.adapterWinPrices, adapterLabel, "appnexus", bidTypeLabel, "video"), 1, 1500)
+			assertHistogram(t, "adapterWinPriceGaps", getHistogramFromHistogramVec(m.adapterWinPriceGaps, adapterLabel, "appnexus"), 1, 250)
+			assertCounterVecValue(t, "", "auctionImps:true", m.auctionImps, 1, prometheus.Labels{hasBidsLabel: "true"})
+			assertCounterVecValue(t, "", "auctionImps:false", m.auctionImps, 1, prometheus.Labels{hasBidsLabel: "false"})
+			impBids := dto.Metric{}
+			m.impBids.Write(&impBids)
+			assertHistogram(t, "impBids", *impBids.GetHistogram(), 2, 3)
+
+			accountAdapter := prometheus.Labels{accountLabel: test.pubID, adapterLabel: "appnexus"}
+			assertCounterVecValue(t, "", "accountAdapterAuctionBids", m.accountAdapterAuctionBids, 3*test.expectedAccountCount, accountAdapter)
+			assertCounterVecValue(t, "", "accountAdapterWins", m.accountAdapterWins, test.expectedAccountCount, accountAdapter)
+			assertCounterVecValue(t, "", "accountAuctionImps", m.accountAuctionImps, test.expectedAccountCount, prometheus.Labels{accountLabel: test.pubID, hasBidsLabel: "false"})
+			assertHistogram(t, "accountImpBids", getHistogramFromHistogramVec(m.accountImpBids, accountLabel, test.pubID), uint64(2*test.expectedAccountCount), 3*test.expectedAccountCount)
+		})
+	}
+}
+
 func TestCookieSyncMetric(t *testing.T) {
 	tests := []struct {
 		status metrics.CookieSyncStatus
@@ -1294,6 +1401,22 @@ func TestSetUidMetric(t *testing.T) {
 	}
 }
 
+func TestRecordUIDCookieTamperedMetric(t *testing.T) {
+	m := createMetricsForTesting()
+
+	m.RecordUIDCookieTampered()
+
+	assertCounterValue(t, "", "uids_cookie_tampered", m.uidCookieTampered, 1)
+}
+
+func TestRecordUIDCookieLegacyRejectedMetric(t *testing.T) {
+	m := createMetricsForTesting()
+
+	m.RecordUIDCookieLegacyRejected()
+
+	assertCounterValue(t, "", "uids_cookie_legacy_rejected", m.uidCookieLegacyRejected, 1)
+}
+
 func TestRecordSyncerSetMetric(t *testing.T) {
 	key := "anyKey"
 
@@ -0,0 +1,511 @@
+package statsdmetrics
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/DataDog/datadog-go/v5/statsd"
+	"github.com/golang/glog"
+
+	"github.com/prebid/prebid-server/v3/config"
+	"github.com/prebid/prebid-server/v3/metrics"
+	"github.com/prebid/prebid-server/v3/openrtb_ext"
+)
+
+// Metrics sends the metrics of the MetricsEngine to a StatsD agent in the DogStatsD format. The metrics are
+// named as their Prometheus counterparts, and their labels are sent as tags.
+type Metrics struct {
+	client          *statsd.Client
+	sampleRate      float64
+	metricsDisabled config.DisabledMetrics
+}
+
+const (
+	accountTag         = "account"
+	adapterErrorTag    = "adapter_error"
+	adapterTag         = "adapter"
+	bidTypeTag         = "bid_type"
+	cacheResultTag     = "cache_result"
+	connectionErrorTag = "connection_error"
+	cookieTag          = "cookie"
+	destinationTag     = "destination"
+	hasBidsTag         = "has_bids"
+	isAudioTag         = "audio"
+	isBannerTag        = "banner"
+	isNativeTag        = "native"
+	isVideoTag         = "video"
+	markupDeliveryTag  = "delivery"
+	moduleTag          = "module"
+	notificationTag    = "notification"
+	optOutTag          = "opt_out"
+	overheadTypeTag    = "overhead_type"
+	requestStatusTag   = "request_status"
+	requestTypeTag     = "request_type"
+	sourceTag          = "source"
+	stageTag           = "stage"
+	statusTag          = "status"
+	storedDataErrorTag = "stored_data_error"
+	storedDataFetchTag = "stored_data_fetch_type"
+	storedDataTypeTag  = "stored_data_type"
+	successTag         = "success"
+	syncerTag          = "syncer"
+	versionTag         = "version"
+)
+
+const (
+	connectionAcceptError = "accept"
+	connectionCloseError  = "close"
+)
+
+const (
+	markupDeliveryAdm  = "adm"
+	markupDeliveryNurl = "nurl"
+)
+
+const (
+	requestAccepted = "accepted"
+	requestRejected = "rejected"
+)
+
+const (
+	requestSuccessful = "ok"
+	requestFailed     = "failed"
+)
+
+const sourceRequest = "request"
+
+// tagValueReplacer replaces the characters which delimit the tags in the DogStatsD format, since the
+// accounts and the syncer keys come from the requests and the host config
+var tagValueReplacer = strings.NewReplacer("|", "_", ",", "_", "#", "_", "\n", "_")
+
+// NewMetrics returns the StatsD metrics, which send the metrics in the background until Shutdown is called
+func NewMetrics(cfg config.StatsDMetrics, disabledMetrics config.DisabledMetrics) (*Metrics, error) {
+	options := []statsd.Option{
+		statsd.WithNamespace(cfg.Namespace),
+		statsd.WithTags(cfg.Tags),
+		statsd.WithBufferFlushInterval(time.Duration(cfg.BufferFlushIntervalMS) * time.Millisecond),
+		statsd.WithMaxMessagesPerPayload(cfg.MaxMessagesPerPayload),
+		statsd.WithWriteTimeout(time.Duration(cfg.WriteTimeoutMS) * time.Millisecond),
+	}
+	if cfg.ClientSideAggregation {
+		options = append(options,
+			statsd.WithClientSideAggregation(),
+			statsd.WithAggregationInterval(time.Duration(cfg.AggregationFlushIntervalMS)*time.Millisecond),
+		)
+		if cfg.ExtendedAggregation {
+			options = append(options, statsd.WithExtendedClientSideAggregation())
+		}
+	} else {
+		options = append(options, statsd.WithoutClientSideAggregation())
+	}
+	if !cfg.Telemetry {
+		options = append(options, statsd.WithoutTelemetry())
+	}
+
+	client, err := statsd.New(cfg.Address, options...)
+	if err != nil {
+		return nil, err
+	}
+	return &Metrics{
+		client:          client,
+		sampleRate:      cfg.SampleRate,
+		metricsDisabled: disabledMetrics,
+	}, nil
+}
+
+// Shutdown sends the buffered and aggregated metrics, and closes the connection to the agent
+func (m *Metrics) Shutdown() {
+	if err := m.client.Close(); err != nil {
+		glog.Errorf("Failed to close the StatsD client: %v", err)
+	}
+}
+
+func (m *Metrics) incr(name string, tags ...string) {
+	m.client.Incr(name, tags, m.sampleRate)
+}
+
+func (m *Metrics) count(name string, value int, tags ...string) {
+	m.client.Count(name, int64(value), tags, m.sampleRate)
+}
+
+func (m *Metrics) timing(name string, value time.Duration, tags ...string) {
+	m.client.Timing(name, value, tags, m.sampleRate)
+}
+
+func (m *Metrics) histogram(name string, value float64, tags ...string) {
+	m.client.Histogram(name, value, tags, m.sampleRate)
+}
+
+func tag(key, value string) string {
+	return key + ":" + tagValueReplacer.Replace(value)
+}
+
+func adapterTagOf(adapter openrtb_ext.BidderName) string {
+	return tag(adapterTag, strings.ToLower(string(adapter)))
+}
+
+// withAccount adds the account tag to the adapter metrics, unless the account adapter details are disabled
+func (m *Metrics) withAccount(tags []string, pubID string) []string {
+	if m.metricsDisabled.AccountAdapterDetails || pubID == metrics.PublisherUnknown || pubID == "" {
+		return tags
+	}
+	return append(tags, tag(accountTag, pubID))
+}
+
+// withAuctionOutcomeAccount adds the account tag to the auction outcome metrics, unless they are disabled
+func (m *Metrics) withAuctionOutcomeAccount(tags []string, pubID string) []string {
+	if m.metricsDisabled.AccountAuctionOutcome || pubID == metrics.PublisherUnknown || pubID == "" {
+		return tags
+	}
+	return append(tags, tag(accountTag, pubID))
+}
+
+func (m *Metrics) RecordConnectionAccept(success bool) {
+	if success {
+		m.incr("connections_opened")
+	} else {
+		m.incr("connections_error", tag(connectionErrorTag, connectionAcceptError))
+	}
+}
+
+func (m *Metrics) RecordTMaxTimeout() {
+	m.incr("tmax_timeout")
+}
+
+func (m *Metrics) RecordConnectionClose(success bool) {
+	if success {
+		m.incr("connections_closed")
+	} else {
+		m.incr("connections_error", tag(connectionErrorTag, connectionCloseError))
+	}
+}
+
+func (m *Metrics) RecordRequest(labels metrics.Labels) {
+	m.incr("requests",
+		tag(requestTypeTag, string(labels.RType)),
+		tag(requestStatusTag, string(labels.RequestStatus)),
+	)
+
+	if labels.CookieFlag == metrics.CookieFlagNo {
+		m.incr("requests_without_cookie", tag(requestTypeTag, string(labels.RType)))
+	}
+
+	if labels.PubID != metrics.PublisherUnknown {
+		m.incr("account_requests", tag(accountTag, labels.PubID))
+	}
+}
+
+func (m *Metrics) RecordDebugRequest(debugEnabled bool, pubID string) {
+	if debugEnabled {
+		m.incr("debug_requests")
+		if !m.metricsDisabled.AccountDebug && pubID != metrics.PublisherUnknown {
+			m.incr("account_debug_requests", tag(accountTag, pubID))
+		}
+	}
+}
+
+func (m *Metrics) RecordStoredResponse(pubId string) {
+	m.incr("stored_responses")
+	if !m.metricsDisabled.AccountStoredResponses && pubId != metrics.PublisherUnknown {
+		m.incr("account_stored_responses", tag(accountTag, pubId))
+	}
+}
+
+func (m *Metrics) RecordImps(labels metrics.ImpLabels) {
+	m.incr("impressions_requests",
+		tag(isBannerTag, strconv.FormatBool(labels.BannerImps)),
+		tag(isVideoTag, strconv.FormatBool(labels.VideoImps)),
+		tag(isAudioTag, strconv.FormatBool(labels.AudioImps)),
+		tag(isNativeTag, strconv.FormatBool(labels.NativeImps)),
+	)
+}
+
+func (m *Metrics) RecordRequestTime(labels metrics.Labels, length time.Duration) {
+	if labels.RequestStatus == metrics.RequestStatusOK {
+		m.timing("request_time", length, tag(requestTypeTag, string(labels.RType)))
+	}
+}
+
+func (m *Metrics) RecordStoredDataFetchTime(labels metrics.StoredDataLabels, length time.Duration) {
+	m.timing("stored_data_fetch_time", length,
+		tag(storedDataTypeTag, string(labels.DataType)),
+		tag(storedDataFetchTag, string(labels.DataFetchType)),
+	)
+}
+
+func (m *Metrics) RecordStoredDataError(labels metrics.StoredDataLabels) {
+	m.incr("stored_data_errors",
+		tag(storedDataTypeTag, string(labels.DataType)),
+		tag(storedDataErrorTag, string(labels.Error)),
+	)
+}
+
+func (m *Metrics) RecordAdapterRequest(labels metrics.AdapterLabels) {
+	adapter := adapterTagOf(labels.Adapter)
+	m.incr("adapter_requests", m.withAccount([]string{
+		adapter,
+		tag(cookieTag, string(labels.CookieFlag)),
+		tag(hasBidsTag, strconv.FormatBool(labels.AdapterBids == metrics.AdapterBidPresent)),
+	}, labels.PubID)...)
+
+	for err := range labels.AdapterErrors {
+		m.incr("adapter_errors", adapter, tag(adapterErrorTag, string(err)))
+	}
+}
+
+// Keeps track of created and reused connections to adapter bidders and the time from the
+// connection request, to the connection creation, or reuse from the pool across all engines
+func (m *Metrics) RecordAdapterConnections(adapterName openrtb_ext.BidderName, connWasReused bool, connWaitTime time.Duration) {
+	if m.metricsDisabled.AdapterConnectionMetrics {
+		return
+	}
+
+	adapter := adapterTagOf(adapterName)
+	if connWasReused {
+		m.incr("adapter_connection_reused", adapter)
+	} else {
+		m.incr("adapter_connection_created", adapter)
+	}
+	m.timing("adapter_connection_wait", connWaitTime, adapter)
+}
+
+func (m *Metrics) RecordDNSTime(dnsLookupTime time.Duration) {
+	m.timing("dns_lookup_time", dnsLookupTime)
+}
+
+func (m *Metrics) RecordTLSHandshakeTime(tlsHandshakeTime time.Duration) {
+	m.timing("tls_handshake_time", tlsHandshakeTime)
+}
+
+func (m *Metrics) RecordBidderServerResponseTime(bidderServerResponseTime time.Duration) {
+	m.timing("bidder_server_response_time", bidderServerResponseTime)
+}
+
+func (m *Metrics) RecordAdapterPanic(labels metrics.AdapterLabels) {
+	m.incr("adapter_panics", adapterTagOf(labels.Adapter))
+}
+
+func (m *Metrics) RecordAdapterBidReceived(labels metrics.AdapterLabels, bidType openrtb_ext.BidType, hasAdm bool) {
+	markupDelivery := markupDeliveryNurl
+	if hasAdm {
+		markupDelivery = markupDeliveryAdm
+	}
+
+	m.incr("adapter_bids", m.withAccount([]string{
+		adapterTagOf(labels.Adapter),
+		tag(bidTypeTag, string(bidType)),
+		tag(markupDeliveryTag, markupDelivery),
+	}, labels.PubID)...)
+}
+
+func (m *Metrics) RecordAdapterPrice(labels metrics.AdapterLabels, cpm float64) {
+	m.histogram("adapter_prices", cpm, m.withAccount([]string{adapterTagOf(labels.Adapter)}, labels.PubID)...)
+}
+
+func (m *Metrics) RecordOverheadTime(overhead metrics.OverheadType, duration time.Duration) {
+	m.timing("overhead_time", duration, tag(overheadTypeTag, overhead.String()))
+}
+
+func (m *Metrics) RecordAdapterTime(labels metrics.AdapterLabels, length time.Duration) {
+	if len(labels.AdapterErrors) == 0 {
+		m.timing("adapter_request_time", length, m.withAccount([]string{adapterTagOf(labels.Adapter)}, labels.PubID)...)
+	}
+}
+
+func (m *Metrics) RecordAdapterAuctionBids(labels metrics.AdapterLabels, bids int) {
+	m.count("adapter_auction_bids", bids, m.withAuctionOutcomeAccount([]string{adapterTagOf(labels.Adapter)}, labels.PubID)...)
+}
+
+func (m *Metrics) RecordAdapterWin(labels metrics.AdapterLabels, bidType openrtb_ext.BidType, cpm float64) {
+	m.incr("adapter_wins", m.withAuctionOutcomeAccount([]string{adapterTagOf(labels.Adapter)}, labels.PubID)...)
+	m.histogram("adapter_win_prices", cpm, m.withAuctionOutcomeAccount([]string{
+		adapterTagOf(labels.Adapter),
+		tag(bidTypeTag, string(bidType)),
+	}, labels.PubID)...)
+}
+
+func (m *Metrics) RecordAdapterWinPriceGap(labels metrics.AdapterLabels, gap float64) {
+	m.histogram("adapter_win_price_gap", gap, m.withAuctionOutcomeAccount([]string{adapterTagOf(labels.Adapter)}, labels.PubID)...)
+}
+
+func (m *Metrics) RecordImpBids(pubID string, bids int) {
+	m.incr("auction_imps", m.withAuctionOutcomeAccount([]string{tag(hasBidsTag, strconv.FormatBool(bids > 0))}, pubID)...)
+	m.histogram("imp_bids", float64(bids), m.withAuctionOutcomeAccount(nil, pubID)...)
+}
+
+func (m *Metrics) RecordCookieSync(status metrics.CookieSyncStatus) {
+	m.incr("cookie_sync_requests", tag(statusTag, string(status)))
+}
+
+func (m *Metrics) RecordSyncerRequest(key string, status metrics.SyncerCookieSyncStatus) {
+	m.incr("syncer_requests", tag(syncerTag, key), tag(statusTag, string(status)))
+}
+
+func (m *Metrics) RecordSetUid(status metrics.SetUidStatus) {
+	m.incr("setuid_requests", tag(statusTag, string(status)))
+}
+
+func (m *Metrics) RecordSyncerSet(key string, status metrics.SyncerSetUidStatus) {
+	m.incr("syncer_sets", tag(syncerTag, key), tag(statusTag, string(status)))
+}
+
+func (m *Metrics) RecordUIDCookieTampered() {
+	m.incr("uids_cookie_tampered")
+}
+
+func (m *Metrics) RecordUIDCookieLegacyRejected() {
+	m.incr("uids_cookie_legacy_rejected")
+}
+
+func (m *Metrics) RecordAnalyticsEvents(destination string, status metrics.AnalyticsEventStatus, inc int) {
+	m.count("analytics_events", inc, tag(destinationTag, destination), tag(statusTag, string(status)))
+}
+
+func (m *Metrics) RecordBidNotification(bidder openrtb_ext.BidderName, notificationType metrics.BidNotificationType, status metrics.BidNotificationStatus) {
+	m.incr("bid_notifications",
+		adapterTagOf(bidder),
+		tag(notificationTag, string(notificationType)),
+		tag(statusTag, string(status)),
+	)
+}
+
+func (m *Metrics) RecordStoredReqCacheResult(cacheResult metrics.CacheResult, inc int) {
+	m.count("stored_request_cache_performance", inc, tag(cacheResultTag, string(cacheResult)))
+}
+
+func (m *Metrics) RecordStoredImpCacheResult(cacheResult metrics.CacheResult, inc int) {
+	m.count("stored_impressions_cache_performance", inc, tag(cacheResultTag, string(cacheResult)))
+}
+
+func (m *Metrics) RecordAccountCacheResult(cacheResult metrics.CacheResult, inc int) {
+	m.count("account_cache_performance", inc, tag(cacheResultTag, string(cacheResult)))
+}
+
+func (m *Metrics) RecordPrebidCacheRequestTime(success bool, length time.Duration) {
+	m.timing("prebidcache_write_time", length, tag(successTag, strconv.FormatBool(success)))
+}
+
+func (m *Metrics) RecordRequestQueueTime(success bool, requestType metrics.RequestType, length time.Duration) {
+	status := requestRejected
+	if success {
+		status = requestAccepted
+	}
+	m.timing("request_queue_time", length, tag(requestTypeTag, string(requestType)), tag(requestStatusTag, status))
+}
+
+func (m *Metrics) RecordTimeoutNotice(success bool) {
+	if success {
+		m.incr("timeout_notification", tag(successTag, requestSuccessful))
+	} else {
+		m.incr("timeout_notification", tag(successTag, requestFailed))
+	}
+}
+
+func (m *Metrics) RecordRequestPrivacy(privacy metrics.PrivacyLabels) {
+	if privacy.CCPAProvided {
+		m.incr("privacy_ccpa", tag(sourceTag, sourceRequest), tag(optOutTag, strconv.FormatBool(privacy.CCPAEnforced)))
+	}
+
+	if privacy.COPPAEnforced {
+		m.incr("privacy_coppa", tag(sourceTag, sourceRequest))
+	}
+
+	if privacy.GDPREnforced {
+		m.incr("privacy_tcf", tag(versionTag, string(privacy.GDPRTCFVersion)), tag(sourceTag, sourceRequest))
+	}
+
+	if privacy.LMTEnforced {
+		m.incr("privacy_lmt", tag(sourceTag, sourceRequest))
+	}
+}
+
+func (m *Metrics) RecordAdapterBuyerUIDScrubbed(adapterName openrtb_ext.BidderName) {
+	if m.metricsDisabled.AdapterBuyerUIDScrubbed {
+		return
+	}
+	m.incr("adapter_buyeruids_scrubbed", adapterTagOf(adapterName))
+}
+
+func (m *Metrics) RecordAdapterGDPRRequestBlocked(adapterName openrtb_ext.BidderName) {
+	if m.metricsDisabled.AdapterGDPRRequestBlocked {
+		return
+	}
+	m.incr("adapter_gdpr_requests_blocked", adapterTagOf(adapterName))
+}
+
+func (m *Metrics) RecordAdsCertReq(success bool) {
+	if success {
+		m.incr("ads_cert_requests", tag(successTag, requestSuccessful))
+	} else {
+		m.incr("ads_cert_requests", tag(successTag, requestFailed))
+	}
+}
+
+func (m *Metrics) RecordAdsCertSignTime(adsCertSignTime time.Duration) {
+	m.timing("ads_cert_sign_time", adsCertSignTime)
+}
+
+func (m *Metrics) RecordBidValidationCreativeSizeError(adapter openrtb_ext.BidderName, account string) {
+	m.recordBidValidation("response_validation_size_err", adapter, account)
+}
+
+func (m *Metrics) RecordBidValidationCreativeSizeWarn(adapter openrtb_ext.BidderName, account string) {
+	m.recordBidValidation("response_validation_size_warn", adapter, account)
+}
+
+func (m *Metrics) RecordBidValidationSecureMarkupError(adapter openrtb_ext.BidderName, account string) {
+	m.recordBidValidation("response_validation_secure_err", adapter, account)
+}
+
+func (m *Metrics) RecordBidValidationSecureMarkupWarn(adapter openrtb_ext.BidderName, account string) {
+	m.recordBidValidation("response_validation_secure_warn", adapter, account)
+}
+
+func (m *Metrics) recordBidValidation(name string, adapter openrtb_ext.BidderName, account string) {
+	m.incr("adapter_"+name, adapterTagOf(adapter))
+
+	if !m.metricsDisabled.AccountAdapterDetails && account != metrics.PublisherUnknown {
+		m.incr("account_"+name, tag(accountTag, account))
+	}
+}
+
+// moduleTags returns the tags of the module metrics, with the account unless the account modules metrics
+// are disabled
+func (m *Metrics) moduleTags(labels metrics.ModuleLabels) []string {
+	tags := []string{tag(moduleTag, labels.Module), tag(stageTag, labels.Stage)}
+	if !m.metricsDisabled.AccountModulesMetrics && labels.AccountID != "" {
+		tags = append(tags, tag(accountTag, labels.AccountID))
+	}
+	return tags
+}
+
+func (m *Metrics) RecordModuleCalled(labels metrics.ModuleLabels, duration time.Duration) {
+	tags := m.moduleTags(labels)
+	m.incr("modules_called", tags...)
+	m.timing("modules_duration", duration, tags...)
+}
+
+func (m *Metrics) RecordModuleFailed(labels metrics.ModuleLabels) {
+	m.incr("modules_failed", m.moduleTags(labels)...)
+}
+
+func (m *Metrics) RecordModuleSuccessNooped(labels metrics.ModuleLabels) {
+	m.incr("modules_success_noops", m.moduleTags(labels)...)
+}
+
+func (m *Metrics) RecordModuleSuccessUpdated(labels metrics.ModuleLabels) {
+	m.incr("modules_success_updates", m.moduleTags(labels)...)
+}
+
+func (m *Metrics) RecordModuleSuccessRejected(labels metrics.ModuleLabels) {
+	m.incr("modules_success_rejects", m.moduleTags(labels)...)
+}
+
+func (m *Metrics) RecordModuleExecutionError(labels metrics.ModuleLabels) {
+	m.incr("modules_execution_errors", m.moduleTags(labels)...)
+}
+
+func (m *Metrics) RecordModuleTimeout(labels metrics.ModuleLabels) {
+	m.incr("modules_timeouts", m.moduleTags(labels)...)
+}
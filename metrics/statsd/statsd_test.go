@@ -0,0 +1,493 @@
+package statsdmetrics
+
+import (
+	"net"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/prebid/prebid-server/v3/config"
+	"github.com/prebid/prebid-server/v3/metrics"
+	"github.com/prebid/prebid-server/v3/openrtb_ext"
+)
+
+func TestRecordMetrics(t *testing.T) {
+	testCases := []struct {
+		name     string
+		disabled config.DisabledMetrics
+		record   func(m *Metrics)
+		expected []string
+	}{
+		{
+			name: "request",
+			record: func(m *Metrics) {
+				labels := metrics.Labels{
+					RType:         metrics.ReqTypeORTB2Web,
+					RequestStatus: metrics.RequestStatusOK,
+					PubID:         "acct",
+					CookieFlag:    metrics.CookieFlagNo,
+				}
+				m.RecordRequest(labels)
+				m.RecordRequestTime(labels, 1500*time.Millisecond)
+			},
+			expected: []string{
+				"test.account_requests:1|c|#account:acct",
+				"test.request_time:1500.000000|ms|#request_type:openrtb2-web",
+				"test.requests:1|c|#request_type:openrtb2-web,request_status:ok",
+				"test.requests_without_cookie:1|c|#request_type:openrtb2-web",
+			},
+		},
+		{
+			name: "request-unknown-publisher-failed",
+			record: func(m *Metrics) {
+				labels := metrics.Labels{
+					RType:         metrics.ReqTypeAMP,
+					RequestStatus: metrics.RequestStatusErr,
+					PubID:         metrics.PublisherUnknown,
+					CookieFlag:    metrics.CookieFlagYes,
+				}
+				m.RecordRequest(labels)
+				m.RecordRequestTime(labels, time.Second)
+			},
+			expected: []string{
+				"test.requests:1|c|#request_type:amp,request_status:err",
+			},
+		},
+		{
+			name: "adapter-with-account",
+			record: func(m *Metrics) {
+				labels := metrics.AdapterLabels{
+					Adapter:       "AppNexus",
+					PubID:         "acct",
+					CookieFlag:    metrics.CookieFlagYes,
+					AdapterBids:   metrics.AdapterBidPresent,
+					AdapterErrors: map[metrics.AdapterError]struct{}{metrics.AdapterErrorTimeout: {}},
+				}
+				m.RecordAdapterRequest(labels)
+				m.RecordAdapterBidReceived(labels, openrtb_ext.BidTypeVideo, true)
+				m.RecordAdapterPrice(labels, 1.25)
+				m.RecordAdapterTime(labels, time.Second)
+			},
+			expected: []string{
+				"test.adapter_bids:1|c|#adapter:appnexus,bid_type:video,delivery:adm,account:acct",
+				"test.adapter_errors:1|c|#adapter:appnexus,adapter_error:timeout",
+				"test.adapter_prices:1.25|h|#adapter:appnexus,account:acct",
+				"test.adapter_requests:1|c|#adapter:appnexus,cookie:exists,has_bids:true,account:acct",
+			},
+		},
+		{
+			name:     "adapter-account-details-disabled",
+			disabled: config.DisabledMetrics{AccountAdapterDetails: true},
+			record: func(m *Metrics) {
+				labels := metrics.AdapterLabels{
+					Adapter:     "appnexus",
+					PubID:       "acct",
+					CookieFlag:  metrics.CookieFlagNo,
+					AdapterBids: metrics.AdapterBidNone,
+				}
+				m.RecordAdapterRequest(labels)
+				m.RecordAdapterTime(labels, 250*time.Millisecond)
+				m.RecordBidValidationCreativeSizeError("appnexus", "acct")
+			},
+			expected: []string{
+				"test.adapter_request_time:250.000000|ms|#adapter:appnexus",
+				"test.adapter_requests:1|c|#adapter:appnexus,cookie:no,has_bids:false",
+				"test.adapter_response_validation_size_err:1|c|#adapter:appnexus",
+			},
+		},
+		{
+			name: "bid-validation-with-account",
+			record: func(m *Metrics) {
+				m.RecordBidValidationSecureMarkupWarn("appnexus", "acct")
+			},
+			expected: []string{
+				"test.account_response_validation_secure_warn:1|c|#account:acct",
+				"test.adapter_response_validation_secure_warn:1|c|#adapter:appnexus",
+			},
+		},
+		{
+			name: "adapter-connections",
+			record: func(m *Metrics) {
+				m.RecordAdapterConnections("appnexus", true, 20*time.Millisecond)
+				m.RecordAdapterBuyerUIDScrubbed("appnexus")
+				m.RecordAdapterGDPRRequestBlocked("appnexus")
+			},
+			expected: []string{
+				"test.adapter_buyeruids_scrubbed:1|c|#adapter:appnexus",
+				"test.adapter_connection_reused:1|c|#adapter:appnexus",
+				"test.adapter_connection_wait:20.000000|ms|#adapter:appnexus",
+				"test.adapter_gdpr_requests_blocked:1|c|#adapter:appnexus",
+			},
+		},
+		{
+			name: "adapter-connections-disabled",
+			disabled: config.DisabledMetrics{
+				AdapterConnectionMetrics:  true,
+				AdapterBuyerUIDScrubbed:   true,
+				AdapterGDPRRequestBlocked: true,
+			},
+			record: func(m *Metrics) {
+				m.RecordAdapterConnections("appnexus", false, 20*time.Millisecond)
+				m.RecordAdapterBuyerUIDScrubbed("appnexus")
+				m.RecordAdapterGDPRRequestBlocked("appnexus")
+				m.RecordTMaxTimeout()
+			},
+			expected: []string{
+				"test.tmax_timeout:1|c",
+			},
+		},
+		{
+			name: "account-debug-and-stored-responses",
+			record: func(m *Metrics) {
+				m.RecordDebugRequest(true, "acct")
+				m.RecordStoredResponse("acct")
+			},
+			expected: []string{
+				"test.account_debug_requests:1|c|#account:acct",
+				"test.account_stored_responses:1|c|#account:acct",
+				"test.debug_requests:1|c",
+				"test.stored_responses:1|c",
+			},
+		},
+		{
+			name:     "account-debug-and-stored-responses-disabled",
+			disabled: config.DisabledMetrics{AccountDebug: true, AccountStoredResponses: true},
+			record: func(m *Metrics) {
+				m.RecordDebugRequest(true, "acct")
+				m.RecordDebugRequest(false, "acct")
+				m.RecordStoredResponse("acct")
+			},
+			expected: []string{
+				"test.debug_requests:1|c",
+				"test.stored_responses:1|c",
+			},
+		},
+		{
+			name: "modules-with-account",
+			record: func(m *Metrics) {
+				labels := metrics.ModuleLabels{Module: "foobar", Stage: "entrypoint", AccountID: "acct"}
+				m.RecordModuleCalled(labels, 5*time.Millisecond)
+				m.RecordModuleTimeout(labels)
+			},
+			expected: []string{
+				"test.modules_called:1|c|#module:foobar,stage:entrypoint,account:acct",
+				"test.modules_duration:5.000000|ms|#module:foobar,stage:entrypoint,account:acct",
+				"test.modules_timeouts:1|c|#module:foobar,stage:entrypoint,account:acct",
+			},
+		},
+		{
+			name:     "modules-account-disabled",
+			disabled: config.DisabledMetrics{AccountModulesMetrics: true},
+			record: func(m *Metrics) {
+				labels := metrics.ModuleLabels{Module: "foobar", Stage: "entrypoint", AccountID: "acct"}
+				m.RecordModuleFailed(labels)
+				m.RecordModuleSuccessNooped(labels)
+				m.RecordModuleSuccessUpdated(labels)
+				m.RecordModuleSuccessRejected(labels)
+				m.RecordModuleExecutionError(labels)
+			},
+			expected: []string{
+				"test.modules_execution_errors:1|c|#module:foobar,stage:entrypoint",
+				"test.modules_failed:1|c|#module:foobar,stage:entrypoint",
+				"test.modules_success_noops:1|c|#module:foobar,stage:entrypoint",
+				"test.modules_success_rejects:1|c|#module:foobar,stage:entrypoint",
+				"test.modules_success_updates:1|c|#module:foobar,stage:entrypoint",
+			},
+		},
+		{
+			name: "stored-data",
+			record: func(m *Metrics) {
+				m.RecordStoredDataFetchTime(metrics.StoredDataLabels{DataType: metrics.AccountDataType, DataFetchType: metrics.FetchAll}, 10*time.Millisecond)
+				m.RecordStoredDataError(metrics.StoredDataLabels{DataType: metrics.RequestDataType, Error: metrics.StoredDataErrorNetwork})
+				m.RecordStoredReqCacheResult(metrics.CacheHit, 3)
+				m.RecordStoredImpCacheResult(metrics.CacheMiss, 2)
+				m.RecordAccountCacheResult(metrics.CacheHit, 1)
+			},
+			expected: []string{
+				"test.account_cache_performance:1|c|#cache_result:hit",
+				"test.stored_data_errors:1|c|#stored_data_type:request,stored_data_error:network",
+				"test.stored_data_fetch_time:10.000000|ms|#stored_data_type:account,stored_data_fetch_type:all",
+				"test.stored_impressions_cache_performance:2|c|#cache_result:miss",
+				"test.stored_request_cache_performance:3|c|#cache_result:hit",
+			},
+		},
+		{
+			name: "privacy",
+			record: func(m *Metrics) {
+				m.RecordRequestPrivacy(metrics.PrivacyLabels{
+					CCPAProvided:   true,
+					CCPAEnforced:   true,
+					COPPAEnforced:  true,
+					GDPREnforced:   true,
+					GDPRTCFVersion: metrics.TCFVersionV2,
+					LMTEnforced:    true,
+				})
+			},
+			expected: []string{
+				"test.privacy_ccpa:1|c|#source:request,opt_out:true",
+				"test.privacy_coppa:1|c|#source:request",
+				"test.privacy_lmt:1|c|#source:request",
+				"test.privacy_tcf:1|c|#version:v2,source:request",
+			},
+		},
+		{
+			name: "user-sync",
+			record: func(m *Metrics) {
+				m.RecordCookieSync(metrics.CookieSyncOK)
+				m.RecordSyncerRequest("adnxs", metrics.SyncerCookieSyncOK)
+				m.RecordSetUid(metrics.SetUidOK)
+				m.RecordSyncerSet("adnxs", metrics.SyncerSetUidCleared)
+				m.RecordUIDCookieTampered()
+				m.RecordUIDCookieLegacyRejected()
+			},
+			expected: []string{
+				"test.cookie_sync_requests:1|c|#status:ok",
+				"test.setuid_requests:1|c|#status:ok",
+				"test.syncer_requests:1|c|#syncer:adnxs,status:ok",
+				"test.syncer_sets:1|c|#syncer:adnxs,status:cleared",
+				"test.uids_cookie_legacy_rejected:1|c",
+				"test.uids_cookie_tampered:1|c",
+			},
+		},
+		{
+			name: "connections",
+			record: func(m *Metrics) {
+				m.RecordConnectionAccept(true)
+				m.RecordConnectionAccept(false)
+				m.RecordConnectionClose(true)
+				m.RecordConnectionClose(false)
+			},
+			expected: []string{
+				"test.connections_closed:1|c",
+				"test.connections_error:1|c|#connection_error:accept",
+				"test.connections_error:1|c|#connection_error:close",
+				"test.connections_opened:1|c",
+			},
+		},
+		{
+			name: "timings",
+			record: func(m *Metrics) {
+				m.RecordOverheadTime(metrics.PreBidder, 2*time.Millisecond)
+				m.RecordDNSTime(3 * time.Millisecond)
+				m.RecordTLSHandshakeTime(4 * time.Millisecond)
+				m.RecordBidderServerResponseTime(5 * time.Millisecond)
+				m.RecordPrebidCacheRequestTime(true, 6*time.Millisecond)
+				m.RecordRequestQueueTime(false, metrics.ReqTypeVideo, 7*time.Millisecond)
+				m.RecordAdsCertSignTime(8 * time.Millisecond)
+			},
+			expected: []string{
+				"test.ads_cert_sign_time:8.000000|ms",
+				"test.bidder_server_response_time:5.000000|ms",
+				"test.dns_lookup_time:3.000000|ms",
+				"test.overhead_time:2.000000|ms|#overhead_type:pre-bidder",
+				"test.prebidcache_write_time:6.000000|ms|#success:true",
+				"test.request_queue_time:7.000000|ms|#request_type:video,request_status:rejected",
+				"test.tls_handshake_time:4.000000|ms",
+			},
+		},
+		{
+			name: "notices-and-events",
+			record: func(m *Metrics) {
+				m.RecordImps(metrics.ImpLabels{BannerImps: true, NativeImps: true})
+				m.RecordTimeoutNotice(false)
+				m.RecordAdsCertReq(true)
+				m.RecordAdapterPanic(metrics.AdapterLabels{Adapter: "appnexus"})
+				m.RecordAnalyticsEvents("http", metrics.AnalyticsEventDropped, 4)
+				m.RecordBidNotification("appnexus", metrics.BidNotificationLoss, metrics.BidNotificationSent)
+			},
+			expected: []string{
+				"test.adapter_panics:1|c|#adapter:appnexus",
+				"test.ads_cert_requests:1|c|#success:ok",
+				"test.analytics_events:4|c|#destination:http,status:dropped",
+				"test.bid_notifications:1|c|#adapter:appnexus,notification:loss,status:sent",
+				"test.impressions_requests:1|c|#banner:true,video:false,audio:false,native:true",
+				"test.timeout_notification:1|c|#success:failed",
+			},
+		},
+		{
+			name: "auction-outcome",
+			record: func(m *Metrics) {
+				labels := metrics.AdapterLabels{Adapter: "AppNexus", PubID: "acct"}
+				m.RecordAdapterAuctionBids(labels, 3)
+				m.RecordAdapterWin(labels, openrtb_ext.BidTypeBanner, 1500)
+				m.RecordAdapterWinPriceGap(labels, 250)
+				m.RecordImpBids("acct", 0)
+			},
+			expected: []string{
+				"test.adapter_auction_bids:3|c|#adapter:appnexus,account:acct",
+				"test.adapter_win_price_gap:250|h|#adapter:appnexus,account:acct",
+				"test.adapter_win_prices:1500|h|#adapter:appnexus,bid_type:banner,account:acct",
+				"test.adapter_wins:1|c|#adapter:appnexus,account:acct",
+				"test.auction_imps:1|c|#has_bids:false,account:acct",
+				"test.imp_bids:0|h|#account:acct",
+			},
+		},
+		{
+			name:     "auction-outcome-account-disabled",
+			disabled: config.DisabledMetrics{AccountAuctionOutcome: true},
+			record: func(m *Metrics) {
+				m.RecordAdapterWin(metrics.AdapterLabels{Adapter: "appnexus", PubID: "acct"}, openrtb_ext.BidTypeVideo, 1500)
+				m.RecordImpBids("acct", 2)
+			},
+			expected: []string{
+				"test.adapter_win_prices:1500|h|#adapter:appnexus,bid_type:video",
+				"test.adapter_wins:1|c|#adapter:appnexus",
+				"test.auction_imps:1|c|#has_bids:true",
+				"test.imp_bids:2|h",
+			},
+		},
+		{
+			name: "tag-values-sanitized",
+			record: func(m *Metrics) {
+				m.RecordRequest(metrics.Labels{RType: metrics.ReqTypeORTB2App, RequestStatus: metrics.RequestStatusOK, PubID: "a|b,c#d"})
+			},
+			expected: []string{
+				"test.account_requests:1|c|#account:a_b_c_d",
+				"test.requests:1|c|#request_type:openrtb2-app,request_status:ok",
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.name, func(t *testing.T) {
+			conn := listenUDP(t)
+			cfg := testConfig(conn.LocalAddr().String())
+
+			m, err := NewMetrics(cfg, test.disabled)
+			require.NoError(t, err)
+			test.record(m)
+			m.Shutdown()
+
+			assert.Equal(t, test.expected, readLines(t, conn))
+		})
+	}
+}
+
+func TestGlobalTags(t *testing.T) {
+	conn := listenUDP(t)
+	cfg := testConfig(conn.LocalAddr().String())
+	cfg.Namespace = "pbs."
+	cfg.Tags = []string{"env:test"}
+
+	m, err := NewMetrics(cfg, config.DisabledMetrics{})
+	require.NoError(t, err)
+	m.RecordCookieSync(metrics.CookieSyncOK)
+	m.Shutdown()
+
+	assert.Equal(t, []string{"pbs.cookie_sync_requests:1|c|#env:test,status:ok"}, readLines(t, conn))
+}
+
+func TestClientSideAggregation(t *testing.T) {
+	conn := listenUDP(t)
+	cfg := testConfig(conn.LocalAddr().String())
+	cfg.ClientSideAggregation = true
+	cfg.AggregationFlushIntervalMS = 60000
+
+	m, err := NewMetrics(cfg, config.DisabledMetrics{})
+	require.NoError(t, err)
+	for i := 0; i < 3; i++ {
+		m.RecordUIDCookieTampered()
+	}
+	m.RecordAdapterPrice(metrics.AdapterLabels{Adapter: "appnexus"}, 2)
+	m.Shutdown()
+
+	assert.Equal(t, []string{
+		"test.adapter_prices:2|h|#adapter:appnexus",
+		"test.uids_cookie_tampered:3|c",
+	}, readLines(t, conn), "the counts must be summed, and the histograms sent as they are")
+}
+
+func TestSampleRate(t *testing.T) {
+	conn := listenUDP(t)
+	cfg := testConfig(conn.LocalAddr().String())
+	cfg.SampleRate = 0.5
+
+	m, err := NewMetrics(cfg, config.DisabledMetrics{})
+	require.NoError(t, err)
+	const sent = 400
+	for i := 0; i < sent; i++ {
+		m.RecordUIDCookieTampered()
+	}
+	m.Shutdown()
+
+	lines := readLines(t, conn)
+	for _, line := range lines {
+		assert.Equal(t, "test.uids_cookie_tampered:1|c|@0.5", line)
+	}
+	assert.Greater(t, len(lines), sent/4, "about half the values must be sent")
+	assert.Less(t, len(lines), sent*3/4, "about half the values must be sent")
+}
+
+func TestUnixSocket(t *testing.T) {
+	socketPath := filepath.Join(t.TempDir(), "statsd.sock")
+	conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: socketPath, Net: "unixgram"})
+	if err != nil {
+		t.Skipf("Unix datagram sockets aren't supported: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	m, err := NewMetrics(testConfig("unix://"+socketPath), config.DisabledMetrics{})
+	require.NoError(t, err)
+	m.RecordSetUid(metrics.SetUidOK)
+	m.Shutdown()
+
+	assert.Equal(t, []string{"test.setuid_requests:1|c|#status:ok"}, readLines(t, conn))
+}
+
+func TestNewMetricsInvalidAddress(t *testing.T) {
+	_, err := NewMetrics(testConfig("not a host:port"), config.DisabledMetrics{})
+	assert.Error(t, err)
+}
+
+func testConfig(address string) config.StatsDMetrics {
+	return config.StatsDMetrics{
+		Address:               address,
+		Namespace:             "test.",
+		SampleRate:            1,
+		BufferFlushIntervalMS: 60000,
+		MaxMessagesPerPayload: 1000,
+		WriteTimeoutMS:        100,
+	}
+}
+
+func listenUDP(t *testing.T) net.PacketConn {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+// readLines returns the sorted metrics received by conn, without the fields the client may add such as
+// the container ID
+func readLines(t *testing.T, conn net.PacketConn) []string {
+	var lines []string
+	buf := make([]byte, 65536)
+	for {
+		conn.SetReadDeadline(time.Now().Add(100 * time.Millisecond))
+		n, _, err := conn.ReadFrom(buf)
+		if err != nil {
+			break
+		}
+		for _, line := range strings.Split(strings.TrimSpace(string(buf[:n])), "\n") {
+			lines = append(lines, stripFields(line))
+		}
+	}
+	sort.Strings(lines)
+	return lines
+}
+
+func stripFields(line string) string {
+	fields := strings.Split(line, "|")
+	kept := fields[:2]
+	for _, field := range fields[2:] {
+		if strings.HasPrefix(field, "@") || strings.HasPrefix(field, "#") {
+			kept = append(kept, field)
+		}
+	}
+	return strings.Join(kept, "|")
+}
@@ -2,6 +2,7 @@ package modules
 
 import (
 	fiftyonedegreesDevicedetection "github.com/prebid/prebid-server/v3/modules/fiftyonedegrees/devicedetection"
+	prebidCreativescan "github.com/prebid/prebid-server/v3/modules/prebid/creativescan"
 	prebidOrtb2blocking "github.com/prebid/prebid-server/v3/modules/prebid/ortb2blocking"
 )
 
@@ -13,6 +14,7 @@ func builders() ModuleBuilders {
 			"devicedetection": fiftyonedegreesDevicedetection.Builder,
 		},
 		"prebid": {
+			"creativescan":  prebidCreativescan.Builder,
 			"ortb2blocking": prebidOrtb2blocking.Builder,
 		},
 	}
@@ -0,0 +1,55 @@
+package creativescan
+
+import (
+	"github.com/prebid/prebid-server/v3/hooks/hookanalytics"
+	"github.com/prebid/prebid-server/v3/hooks/hookstage"
+)
+
+const scanCreativeTag = "scan_creative"
+
+const (
+	domainAnalyticKey = "domain"
+	ruleAnalyticKey   = "rule"
+	entryAnalyticKey  = "entry"
+)
+
+// creativescan module has only 1 activity: `scan_creative` which will be used in further result processing
+func newScanCreativeTags() hookanalytics.Analytics {
+	return hookanalytics.Analytics{
+		Activities: []hookanalytics.Activity{
+			{
+				Name:   scanCreativeTag,
+				Status: hookanalytics.ActivityStatusSuccess,
+			},
+		},
+	}
+}
+
+func addAllowedAnalyticTag(result *hookstage.HookResult[hookstage.RawBidderResponsePayload], bidder, impID string) {
+	newAllowedResult := hookanalytics.Result{
+		Status: hookanalytics.ResultStatusAllow,
+		AppliedTo: hookanalytics.AppliedTo{
+			Bidder: bidder,
+			ImpIds: []string{impID},
+		},
+	}
+
+	result.AnalyticsTags.Activities[0].Results = append(result.AnalyticsTags.Activities[0].Results, newAllowedResult)
+}
+
+func addBlockedAnalyticTag(result *hookstage.HookResult[hookstage.RawBidderResponsePayload], bidder, impID string, m match) {
+	newBlockedResult := hookanalytics.Result{
+		Status: hookanalytics.ResultStatusBlock,
+		Values: map[string]interface{}{
+			domainAnalyticKey: m.url.host,
+			ruleAnalyticKey:   m.rule,
+			entryAnalyticKey:  m.entry,
+		},
+		AppliedTo: hookanalytics.AppliedTo{
+			Bidder: bidder,
+			ImpIds: []string{impID},
+		},
+	}
+
+	result.AnalyticsTags.Activities[0].Results = append(result.AnalyticsTags.Activities[0].Results, newBlockedResult)
+}
@@ -0,0 +1,66 @@
+package creativescan
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/prebid/prebid-server/v3/openrtb_ext"
+	"github.com/prebid/prebid-server/v3/util/jsonutil"
+)
+
+// hostConfig is the module config provided by the host under hooks.modules.prebid.creativescan
+type hostConfig struct {
+	ThreatList threatListConfig `json:"threat_list"`
+}
+
+type threatListConfig struct {
+	// File points to a local threat list, one domain or URL pattern per line
+	File    string   `json:"file"`
+	Domains []string `json:"domains"`
+	URLs    []string `json:"urls"`
+}
+
+// accountConfig is the module config provided at the account level
+type accountConfig struct {
+	BlockedDomains  []string              `json:"blocked_domains"`
+	AllowedDomains  []string              `json:"allowed_domains"`
+	BlockThreats    bool                  `json:"block_threats"`
+	MediaTypes      []openrtb_ext.BidType `json:"media_types"`
+	PopulateAdomain bool                  `json:"populate_adomain"`
+}
+
+func newHostConfig(data json.RawMessage) (hostConfig, error) {
+	var cfg hostConfig
+	if len(data) == 0 {
+		return cfg, nil
+	}
+	if err := jsonutil.UnmarshalValid(data, &cfg); err != nil {
+		return cfg, fmt.Errorf("failed to parse config: %s", err)
+	}
+	return cfg, nil
+}
+
+func newAccountConfig(data json.RawMessage) (accountConfig, error) {
+	cfg := accountConfig{BlockThreats: true}
+	if len(data) == 0 {
+		return cfg, nil
+	}
+	if err := jsonutil.UnmarshalValid(data, &cfg); err != nil {
+		return cfg, fmt.Errorf("failed to parse config: %s", err)
+	}
+	return cfg, nil
+}
+
+// scansMediaType reports whether bids of the given media type should be scanned,
+// all media types are scanned if the account does not restrict them
+func (c accountConfig) scansMediaType(bidType openrtb_ext.BidType) bool {
+	if len(c.MediaTypes) == 0 {
+		return true
+	}
+	for _, mediaType := range c.MediaTypes {
+		if mediaType == bidType {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,262 @@
+package creativescan
+
+import (
+	"encoding/xml"
+	"errors"
+	"io"
+	"regexp"
+	"strings"
+
+	"github.com/prebid/openrtb/v20/native1/response"
+	"github.com/prebid/prebid-server/v3/openrtb_ext"
+	"github.com/prebid/prebid-server/v3/util/jsonutil"
+	"golang.org/x/net/html"
+	"golang.org/x/net/publicsuffix"
+)
+
+// creativeURL is a URL referenced by the bid markup
+type creativeURL struct {
+	raw  string
+	host string
+	// landing is true if the URL is the destination of a click
+	landing bool
+}
+
+// absoluteURLRegexp finds absolute and protocol-relative URLs in free text such as inline scripts and styles
+var absoluteURLRegexp = regexp.MustCompile(`(?i)(?:https?:)?//[a-z0-9-]+(?:\.[a-z0-9-]+)+[^\s"'<>()\\]*`)
+
+// urlAttributes lists the html attributes which may reference external resources
+var urlAttributes = map[string]struct{}{
+	"action":     {},
+	"background": {},
+	"data":       {},
+	"data-src":   {},
+	"href":       {},
+	"poster":     {},
+	"src":        {},
+}
+
+type urlCollector struct {
+	urls []creativeURL
+	seen map[string]struct{}
+}
+
+func newURLCollector() *urlCollector {
+	return &urlCollector{seen: make(map[string]struct{})}
+}
+
+func (c *urlCollector) add(raw string, landing bool) {
+	raw = strings.TrimSpace(raw)
+	host := hostFromURL(raw)
+	if host == "" {
+		return
+	}
+
+	key := raw
+	if landing {
+		key = "landing:" + raw
+	}
+	if _, ok := c.seen[key]; ok {
+		return
+	}
+	c.seen[key] = struct{}{}
+	c.urls = append(c.urls, creativeURL{raw: raw, host: host, landing: landing})
+}
+
+// extractURLs returns all URLs referenced by the bid markup.
+// Banner markup is parsed as HTML, video and audio markup as VAST and native markup as a native response.
+func extractURLs(adm string, bidType openrtb_ext.BidType) []creativeURL {
+	c := newURLCollector()
+	switch bidType {
+	case openrtb_ext.BidTypeBanner:
+		c.scanHTML(adm)
+	case openrtb_ext.BidTypeVideo, openrtb_ext.BidTypeAudio:
+		c.scanVAST(adm)
+	case openrtb_ext.BidTypeNative:
+		c.scanNative(adm)
+	default:
+		c.scanText(adm)
+	}
+	return c.urls
+}
+
+func (c *urlCollector) scanText(text string) {
+	for _, match := range absoluteURLRegexp.FindAllString(text, -1) {
+		c.add(match, false)
+	}
+}
+
+func (c *urlCollector) scanHTML(markup string) {
+	tokenizer := html.NewTokenizer(strings.NewReader(markup))
+	for {
+		switch tokenizer.Next() {
+		case html.ErrorToken:
+			return
+		case html.StartTagToken, html.SelfClosingTagToken:
+			token := tokenizer.Token()
+			isLink := token.Data == "a" || token.Data == "area"
+			for _, attr := range token.Attr {
+				key := strings.ToLower(attr.Key)
+				if key == "srcset" {
+					for _, candidate := range strings.Split(attr.Val, ",") {
+						if fields := strings.Fields(candidate); len(fields) > 0 {
+							c.add(fields[0], false)
+						}
+					}
+					continue
+				}
+				if _, ok := urlAttributes[key]; ok {
+					c.add(attr.Val, isLink && key == "href")
+					continue
+				}
+				if key == "style" || strings.HasPrefix(key, "on") {
+					c.scanText(attr.Val)
+				}
+			}
+		case html.TextToken:
+			c.scanText(string(tokenizer.Text()))
+		}
+	}
+}
+
+func (c *urlCollector) scanVAST(markup string) {
+	decoder := xml.NewDecoder(strings.NewReader(markup))
+	decoder.Strict = false
+
+	var elements []string
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			if !errors.Is(err, io.EOF) {
+				// malformed VAST, fall back to a plain text scan of the unparsed markup
+				c.scanText(markup[decoder.InputOffset():])
+			}
+			return
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			elements = append(elements, t.Name.Local)
+		case xml.EndElement:
+			if len(elements) > 0 {
+				elements = elements[:len(elements)-1]
+			}
+		case xml.CharData:
+			if len(elements) == 0 {
+				continue
+			}
+			text := strings.TrimSpace(string(t))
+			switch elements[len(elements)-1] {
+			case "HTMLResource":
+				c.scanHTML(text)
+			case "ClickThrough", "NonLinearClickThrough", "CompanionClickThrough":
+				c.add(text, true)
+			default:
+				if hostFromURL(text) != "" {
+					c.add(text, false)
+				} else {
+					c.scanText(text)
+				}
+			}
+		}
+	}
+}
+
+func (c *urlCollector) scanNative(markup string) {
+	var wrapped struct {
+		Native *response.Response `json:"native"`
+	}
+	if err := jsonutil.Unmarshal([]byte(markup), &wrapped); err != nil {
+		c.scanText(markup)
+		return
+	}
+
+	native := wrapped.Native
+	if native == nil {
+		native = &response.Response{}
+		if err := jsonutil.Unmarshal([]byte(markup), native); err != nil {
+			c.scanText(markup)
+			return
+		}
+	}
+
+	c.addNativeLink(native.Link)
+	c.add(native.AssetsURL, false)
+	c.add(native.DCOURL, false)
+	for _, tracker := range native.ImpTrackers {
+		c.add(tracker, false)
+	}
+	for _, tracker := range native.EventTrackers {
+		c.add(tracker.URL, false)
+	}
+	if native.JSTracker != "" {
+		c.scanHTML(native.JSTracker)
+	}
+
+	for _, asset := range native.Assets {
+		if asset.Img != nil {
+			c.add(asset.Img.URL, false)
+		}
+		if asset.Video != nil {
+			c.scanVAST(asset.Video.VASTTag)
+		}
+		if asset.Link != nil {
+			c.addNativeLink(*asset.Link)
+		}
+	}
+}
+
+func (c *urlCollector) addNativeLink(link response.Link) {
+	c.add(link.URL, true)
+	c.add(link.Fallback, true)
+	for _, tracker := range link.ClickTrackers {
+		c.add(tracker, false)
+	}
+}
+
+// hostFromURL returns the lower-cased host of an absolute or protocol-relative URL.
+// The URL is not fully parsed as bid markup often contains unescaped macros.
+func hostFromURL(raw string) string {
+	s := strings.TrimSpace(raw)
+	rest := stripScheme(strings.ToLower(s))
+	if len(rest) == len(s) {
+		return ""
+	}
+
+	if i := strings.IndexAny(rest, "/?#\\"); i >= 0 {
+		rest = rest[:i]
+	}
+	if i := strings.LastIndex(rest, "@"); i >= 0 {
+		rest = rest[i+1:]
+	}
+	if i := strings.Index(rest, ":"); i >= 0 {
+		rest = rest[:i]
+	}
+
+	host := strings.TrimSuffix(rest, ".")
+	if !strings.Contains(host, ".") || strings.ContainsAny(host, "${}% ") {
+		return ""
+	}
+	return host
+}
+
+// landingDomains returns the registrable domains of the click destinations found in the markup
+func landingDomains(urls []creativeURL) []string {
+	var domains []string
+	seen := make(map[string]struct{})
+	for _, u := range urls {
+		if !u.landing {
+			continue
+		}
+		domain, err := publicsuffix.EffectiveTLDPlusOne(u.host)
+		if err != nil {
+			domain = u.host
+		}
+		if _, ok := seen[domain]; ok {
+			continue
+		}
+		seen[domain] = struct{}{}
+		domains = append(domains, domain)
+	}
+	return domains
+}
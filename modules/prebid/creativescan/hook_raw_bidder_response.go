@@ -0,0 +1,149 @@
+package creativescan
+
+import (
+	"fmt"
+
+	"github.com/prebid/openrtb/v20/openrtb2"
+	"github.com/prebid/prebid-server/v3/adapters"
+	"github.com/prebid/prebid-server/v3/hooks/hookstage"
+	"github.com/prebid/prebid-server/v3/openrtb_ext"
+)
+
+// nonBidReasonInvalidCreative is the seat-non-bid status code "Response Rejected - Invalid Creative"
+const nonBidReasonInvalidCreative = 350
+
+const (
+	ruleThreatList     = "threat_list"
+	ruleBlockedDomains = "blocked_domains"
+)
+
+// match describes the rule a creative URL violated
+type match struct {
+	url   creativeURL
+	rule  string
+	entry string
+}
+
+func handleRawBidderResponseHook(
+	cfg accountConfig,
+	threats threatList,
+	payload hookstage.RawBidderResponsePayload,
+) (result hookstage.HookResult[hookstage.RawBidderResponsePayload], err error) {
+	bidder := payload.Bidder
+	result.AnalyticsTags = newScanCreativeTags()
+
+	// allowedBids will store all bids that have passed the creative scan
+	allowedBids := make([]*adapters.TypedBid, 0, len(payload.BidderResponse.Bids))
+	nonBids := make(map[string][]openrtb_ext.NonBid)
+	var seats []string
+	updated := false
+
+	for _, bid := range payload.BidderResponse.Bids {
+		if bid == nil || bid.Bid == nil || !cfg.scansMediaType(bid.BidType) {
+			allowedBids = append(allowedBids, bid)
+			continue
+		}
+
+		urls := extractURLs(bid.Bid.AdM, bid.BidType)
+		if m, blocked := findViolation(cfg, threats, urls); blocked {
+			seat := bidder
+			if bid.Seat != "" {
+				seat = bid.Seat.String()
+			}
+			if _, ok := nonBids[seat]; !ok {
+				seats = append(seats, seat)
+			}
+			nonBids[seat] = append(nonBids[seat], newNonBid(bid.Bid, m))
+
+			addBlockedAnalyticTag(&result, bidder, bid.Bid.ImpID, m)
+			addDebugMessage(&result, bid.Bid, bidder, m)
+			continue
+		}
+
+		addAllowedAnalyticTag(&result, bidder, bid.Bid.ImpID)
+		if cfg.PopulateAdomain && len(bid.Bid.ADomain) == 0 {
+			if domains := landingDomains(urls); len(domains) > 0 {
+				bid = withADomain(bid, domains)
+				updated = true
+			}
+		}
+		allowedBids = append(allowedBids, bid)
+	}
+
+	for _, seat := range seats {
+		result.SeatNonBid = append(result.SeatNonBid, openrtb_ext.SeatNonBid{Seat: seat, NonBid: nonBids[seat]})
+	}
+
+	if updated || len(payload.BidderResponse.Bids) != len(allowedBids) {
+		changeSet := hookstage.ChangeSet[hookstage.RawBidderResponsePayload]{}
+		changeSet.RawBidderResponse().Bids().UpdateBids(allowedBids)
+		result.ChangeSet = changeSet
+	}
+
+	return result, nil
+}
+
+// findViolation returns the first creative URL matching the threat list or the account block list.
+// URLs on the account allow list are never blocked.
+func findViolation(cfg accountConfig, threats threatList, urls []creativeURL) (match, bool) {
+	for _, u := range urls {
+		if _, ok := matchDomain(u.host, cfg.AllowedDomains); ok {
+			continue
+		}
+		if cfg.BlockThreats {
+			if entry, ok := threats.match(u); ok {
+				return match{url: u, rule: ruleThreatList, entry: entry}, true
+			}
+		}
+		if entry, ok := matchDomain(u.host, cfg.BlockedDomains); ok {
+			return match{url: u, rule: ruleBlockedDomains, entry: entry}, true
+		}
+	}
+	return match{}, false
+}
+
+// withADomain returns a copy of the bid with the detected landing domains set as adomain,
+// the payload bid is left unchanged as hooks must only modify the payload through mutations
+func withADomain(bid *adapters.TypedBid, domains []string) *adapters.TypedBid {
+	ortbBid := *bid.Bid
+	ortbBid.ADomain = domains
+
+	typedBid := *bid
+	typedBid.Bid = &ortbBid
+	return &typedBid
+}
+
+func newNonBid(bid *openrtb2.Bid, m match) openrtb_ext.NonBid {
+	return openrtb_ext.NonBid{
+		ImpId:      bid.ImpID,
+		StatusCode: nonBidReasonInvalidCreative,
+		Ext: &openrtb_ext.NonBidExt{
+			Prebid: openrtb_ext.ExtResponseNonBidPrebid{
+				Bid: openrtb_ext.NonBidObject{
+					Price:   bid.Price,
+					ADomain: bid.ADomain,
+					CatTax:  bid.CatTax,
+					Cat:     bid.Cat,
+					DealID:  bid.DealID,
+					W:       bid.W,
+					H:       bid.H,
+					Dur:     bid.Dur,
+					MType:   bid.MType,
+				},
+				Rejection: &openrtb_ext.NonBidRejection{Rule: m.rule, Entry: m.entry},
+			},
+		},
+	}
+}
+
+func addDebugMessage(
+	result *hookstage.HookResult[hookstage.RawBidderResponsePayload],
+	bid *openrtb2.Bid,
+	bidder string,
+	m match,
+) {
+	result.DebugMessages = append(
+		result.DebugMessages,
+		fmt.Sprintf("Bid %s from bidder %s has been rejected, creative references %s matching %s rule %s", bid.ID, bidder, m.url.host, m.rule, m.entry),
+	)
+}
@@ -0,0 +1,45 @@
+package creativescan
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/prebid/prebid-server/v3/hooks/hookstage"
+	"github.com/prebid/prebid-server/v3/modules/moduledeps"
+)
+
+func Builder(rawConfig json.RawMessage, _ moduledeps.ModuleDeps) (interface{}, error) {
+	cfg, err := newHostConfig(rawConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	threats, err := newThreatList(cfg.ThreatList)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load threat list: %s", err)
+	}
+
+	return Module{threats: threats}, nil
+}
+
+type Module struct {
+	threats threatList
+}
+
+// HandleRawBidderResponseHook scans the markup of every bid returned by a bidder
+// and rejects bids referencing blocked or known-malicious domains.
+func (m Module) HandleRawBidderResponseHook(
+	_ context.Context,
+	miCtx hookstage.ModuleInvocationContext,
+	payload hookstage.RawBidderResponsePayload,
+) (hookstage.HookResult[hookstage.RawBidderResponsePayload], error) {
+	result := hookstage.HookResult[hookstage.RawBidderResponsePayload]{}
+
+	cfg, err := newAccountConfig(miCtx.AccountConfig)
+	if err != nil {
+		return result, err
+	}
+
+	return handleRawBidderResponseHook(cfg, m.threats, payload)
+}
@@ -0,0 +1,264 @@
+package creativescan
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/prebid/openrtb/v20/openrtb2"
+	"github.com/prebid/prebid-server/v3/adapters"
+	"github.com/prebid/prebid-server/v3/hooks/hookanalytics"
+	"github.com/prebid/prebid-server/v3/hooks/hookstage"
+	"github.com/prebid/prebid-server/v3/modules/moduledeps"
+	"github.com/prebid/prebid-server/v3/openrtb_ext"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	cleanBanner   = `<a href="https://www.shop.com/offer"><img src="https://img.cdn.com/a.png"></a>`
+	threatBanner  = `<a href="https://www.shop.com/offer"><img src="https://img.cdn.com/a.png"></a><script src="https://cdn.evil.com/miner.js"></script>`
+	blockedBanner = `<a href="https://ads.competitor.com/offer"><img src="https://img.cdn.com/a.png"></a>`
+)
+
+func TestBuilder(t *testing.T) {
+	threatFile := filepath.Join(t.TempDir(), "threats.txt")
+	err := os.WriteFile(threatFile, []byte("# known threats\n\nmalware.com\nHTTPS://cdn.evil.com/miner.js\n"), 0644)
+	assert.NoError(t, err)
+
+	testCases := []struct {
+		description     string
+		config          json.RawMessage
+		expectedModule  interface{}
+		expectedErrorOk bool
+	}{
+		{
+			description:    "empty_config",
+			config:         nil,
+			expectedModule: Module{},
+		},
+		{
+			description: "inline_and_file_threat_list",
+			config:      json.RawMessage(`{"enabled": true, "threat_list": {"file": "` + threatFile + `", "domains": ["bad.com"], "urls": ["tracker.com/steal"]}}`),
+			expectedModule: Module{threats: threatList{
+				domains: []string{"bad.com", "malware.com"},
+				urls:    []string{"tracker.com/steal", "cdn.evil.com/miner.js"},
+			}},
+		},
+		{
+			description:     "missing_threat_list_file",
+			config:          json.RawMessage(`{"threat_list": {"file": "` + filepath.Join(t.TempDir(), "missing.txt") + `"}}`),
+			expectedErrorOk: true,
+		},
+		{
+			description:     "invalid_config",
+			config:          json.RawMessage(`{"threat_list": []}`),
+			expectedErrorOk: true,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.description, func(t *testing.T) {
+			module, err := Builder(test.config, moduledeps.ModuleDeps{})
+			if test.expectedErrorOk {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, test.expectedModule, module)
+		})
+	}
+}
+
+func TestHandleRawBidderResponseHook(t *testing.T) {
+	module := Module{threats: threatList{urls: []string{"cdn.evil.com/miner.js"}}}
+
+	testCases := []struct {
+		description        string
+		accountConfig      json.RawMessage
+		bids               []*adapters.TypedBid
+		expectedBids       []*adapters.TypedBid
+		expectedSeatNonBid []openrtb_ext.SeatNonBid
+		expectedResults    []hookanalytics.Result
+		expectedDebug      []string
+		expectedError      bool
+	}{
+		{
+			description:   "clean_bid_allowed_without_changes",
+			accountConfig: nil,
+			bids: []*adapters.TypedBid{
+				{Bid: &openrtb2.Bid{ID: "1", ImpID: "imp-1", AdM: cleanBanner}, BidType: openrtb_ext.BidTypeBanner},
+			},
+			expectedResults: []hookanalytics.Result{
+				{Status: hookanalytics.ResultStatusAllow, AppliedTo: hookanalytics.AppliedTo{Bidder: "appnexus", ImpIds: []string{"imp-1"}}},
+			},
+		},
+		{
+			description:   "threat_rejected_with_seat_non_bid",
+			accountConfig: nil,
+			bids: []*adapters.TypedBid{
+				{Bid: &openrtb2.Bid{ID: "1", ImpID: "imp-1", AdM: threatBanner, Price: 1.5, ADomain: []string{"shop.com"}}, BidType: openrtb_ext.BidTypeBanner},
+				{Bid: &openrtb2.Bid{ID: "2", ImpID: "imp-2", AdM: cleanBanner}, BidType: openrtb_ext.BidTypeBanner},
+			},
+			expectedBids: []*adapters.TypedBid{
+				{Bid: &openrtb2.Bid{ID: "2", ImpID: "imp-2", AdM: cleanBanner}, BidType: openrtb_ext.BidTypeBanner},
+			},
+			expectedSeatNonBid: []openrtb_ext.SeatNonBid{
+				{
+					Seat: "appnexus",
+					NonBid: []openrtb_ext.NonBid{
+						{
+							ImpId:      "imp-1",
+							StatusCode: 350,
+							Ext: &openrtb_ext.NonBidExt{Prebid: openrtb_ext.ExtResponseNonBidPrebid{
+								Bid: openrtb_ext.NonBidObject{
+									Price:   1.5,
+									ADomain: []string{"shop.com"},
+								},
+								Rejection: &openrtb_ext.NonBidRejection{Rule: "threat_list", Entry: "cdn.evil.com/miner.js"},
+							}},
+						},
+					},
+				},
+			},
+			expectedResults: []hookanalytics.Result{
+				{
+					Status:    hookanalytics.ResultStatusBlock,
+					Values:    map[string]interface{}{"domain": "cdn.evil.com", "rule": "threat_list", "entry": "cdn.evil.com/miner.js"},
+					AppliedTo: hookanalytics.AppliedTo{Bidder: "appnexus", ImpIds: []string{"imp-1"}},
+				},
+				{Status: hookanalytics.ResultStatusAllow, AppliedTo: hookanalytics.AppliedTo{Bidder: "appnexus", ImpIds: []string{"imp-2"}}},
+			},
+			expectedDebug: []string{"Bid 1 from bidder appnexus has been rejected, creative references cdn.evil.com matching threat_list rule cdn.evil.com/miner.js"},
+		},
+		{
+			description:   "threats_not_blocked_if_disabled_by_account",
+			accountConfig: json.RawMessage(`{"block_threats": false}`),
+			bids: []*adapters.TypedBid{
+				{Bid: &openrtb2.Bid{ID: "1", ImpID: "imp-1", AdM: threatBanner}, BidType: openrtb_ext.BidTypeBanner},
+			},
+			expectedResults: []hookanalytics.Result{
+				{Status: hookanalytics.ResultStatusAllow, AppliedTo: hookanalytics.AppliedTo{Bidder: "appnexus", ImpIds: []string{"imp-1"}}},
+			},
+		},
+		{
+			description:   "threats_not_blocked_if_allowed_by_account",
+			accountConfig: json.RawMessage(`{"allowed_domains": ["evil.com"]}`),
+			bids: []*adapters.TypedBid{
+				{Bid: &openrtb2.Bid{ID: "1", ImpID: "imp-1", AdM: threatBanner}, BidType: openrtb_ext.BidTypeBanner},
+			},
+			expectedResults: []hookanalytics.Result{
+				{Status: hookanalytics.ResultStatusAllow, AppliedTo: hookanalytics.AppliedTo{Bidder: "appnexus", ImpIds: []string{"imp-1"}}},
+			},
+		},
+		{
+			description:   "account_blocked_domain_rejected_for_alternate_seat",
+			accountConfig: json.RawMessage(`{"blocked_domains": ["competitor.com"]}`),
+			bids: []*adapters.TypedBid{
+				{Bid: &openrtb2.Bid{ID: "1", ImpID: "imp-1", AdM: blockedBanner}, BidType: openrtb_ext.BidTypeBanner, Seat: "groupm"},
+			},
+			expectedBids: []*adapters.TypedBid{},
+			expectedSeatNonBid: []openrtb_ext.SeatNonBid{
+				{
+					Seat: "groupm",
+					NonBid: []openrtb_ext.NonBid{
+						{
+							ImpId:      "imp-1",
+							StatusCode: 350,
+							Ext: &openrtb_ext.NonBidExt{Prebid: openrtb_ext.ExtResponseNonBidPrebid{
+								Rejection: &openrtb_ext.NonBidRejection{Rule: "blocked_domains", Entry: "competitor.com"},
+							}},
+						},
+					},
+				},
+			},
+			expectedResults: []hookanalytics.Result{
+				{
+					Status:    hookanalytics.ResultStatusBlock,
+					Values:    map[string]interface{}{"domain": "ads.competitor.com", "rule": "blocked_domains", "entry": "competitor.com"},
+					AppliedTo: hookanalytics.AppliedTo{Bidder: "appnexus", ImpIds: []string{"imp-1"}},
+				},
+			},
+			expectedDebug: []string{"Bid 1 from bidder appnexus has been rejected, creative references ads.competitor.com matching blocked_domains rule competitor.com"},
+		},
+		{
+			description:   "media_type_not_scanned",
+			accountConfig: json.RawMessage(`{"blocked_domains": ["competitor.com"], "media_types": ["video"]}`),
+			bids: []*adapters.TypedBid{
+				{Bid: &openrtb2.Bid{ID: "1", ImpID: "imp-1", AdM: blockedBanner}, BidType: openrtb_ext.BidTypeBanner},
+			},
+		},
+		{
+			description:   "landing_domains_populated_into_empty_adomain",
+			accountConfig: json.RawMessage(`{"populate_adomain": true}`),
+			bids: []*adapters.TypedBid{
+				{Bid: &openrtb2.Bid{ID: "1", ImpID: "imp-1", AdM: cleanBanner}, BidType: openrtb_ext.BidTypeBanner},
+				{Bid: &openrtb2.Bid{ID: "2", ImpID: "imp-2", AdM: cleanBanner, ADomain: []string{"reported.com"}}, BidType: openrtb_ext.BidTypeBanner},
+			},
+			expectedBids: []*adapters.TypedBid{
+				{Bid: &openrtb2.Bid{ID: "1", ImpID: "imp-1", AdM: cleanBanner, ADomain: []string{"shop.com"}}, BidType: openrtb_ext.BidTypeBanner},
+				{Bid: &openrtb2.Bid{ID: "2", ImpID: "imp-2", AdM: cleanBanner, ADomain: []string{"reported.com"}}, BidType: openrtb_ext.BidTypeBanner},
+			},
+			expectedResults: []hookanalytics.Result{
+				{Status: hookanalytics.ResultStatusAllow, AppliedTo: hookanalytics.AppliedTo{Bidder: "appnexus", ImpIds: []string{"imp-1"}}},
+				{Status: hookanalytics.ResultStatusAllow, AppliedTo: hookanalytics.AppliedTo{Bidder: "appnexus", ImpIds: []string{"imp-2"}}},
+			},
+		},
+		{
+			description:   "invalid_account_config",
+			accountConfig: json.RawMessage(`{"blocked_domains": "competitor.com"}`),
+			expectedError: true,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.description, func(t *testing.T) {
+			payload := hookstage.RawBidderResponsePayload{
+				BidderResponse: &adapters.BidderResponse{Bids: test.bids},
+				Bidder:         "appnexus",
+			}
+			originalBids := cloneTypedBids(test.bids)
+
+			result, err := module.HandleRawBidderResponseHook(
+				context.Background(),
+				hookstage.ModuleInvocationContext{AccountConfig: test.accountConfig},
+				payload,
+			)
+			if test.expectedError {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, originalBids, payload.BidderResponse.Bids, "hook must not modify the payload directly")
+
+			for _, mut := range result.ChangeSet.Mutations() {
+				payload, err = mut.Apply(payload)
+				assert.NoError(t, err)
+			}
+			if test.expectedBids != nil {
+				assert.Equal(t, test.expectedBids, payload.BidderResponse.Bids)
+			} else {
+				assert.Empty(t, result.ChangeSet.Mutations())
+			}
+
+			assert.Equal(t, test.expectedSeatNonBid, result.SeatNonBid)
+			assert.Equal(t, test.expectedDebug, result.DebugMessages)
+			assert.Equal(t, test.expectedResults, result.AnalyticsTags.Activities[0].Results)
+		})
+	}
+}
+
+func cloneTypedBids(bids []*adapters.TypedBid) []*adapters.TypedBid {
+	if bids == nil {
+		return nil
+	}
+	clones := make([]*adapters.TypedBid, len(bids))
+	for i, bid := range bids {
+		ortbBid := *bid.Bid
+		clone := *bid
+		clone.Bid = &ortbBid
+		clones[i] = &clone
+	}
+	return clones
+}
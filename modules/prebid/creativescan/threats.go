@@ -0,0 +1,94 @@
+package creativescan
+
+import (
+	"bufio"
+	"os"
+	"strings"
+)
+
+// threatList holds the domains and URL patterns of known-malicious creatives and scripts.
+// URL patterns are matched against the referenced URL without its scheme.
+type threatList struct {
+	domains []string
+	urls    []string
+}
+
+func newThreatList(cfg threatListConfig) (threatList, error) {
+	var threats threatList
+	for _, domain := range cfg.Domains {
+		threats.add(domain)
+	}
+	for _, url := range cfg.URLs {
+		threats.add(url)
+	}
+
+	if cfg.File == "" {
+		return threats, nil
+	}
+
+	file, err := os.Open(cfg.File)
+	if err != nil {
+		return threats, err
+	}
+	defer file.Close()
+
+	// the file contains one entry per line, lines starting with # are comments
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		threats.add(line)
+	}
+
+	return threats, scanner.Err()
+}
+
+// add registers an entry as a URL pattern if it contains a path, otherwise as a domain
+func (t *threatList) add(entry string) {
+	entry = stripScheme(strings.ToLower(strings.TrimSpace(entry)))
+	if entry == "" {
+		return
+	}
+	if strings.Contains(entry, "/") {
+		t.urls = append(t.urls, entry)
+	} else {
+		t.domains = append(t.domains, strings.TrimSuffix(entry, "."))
+	}
+}
+
+// match returns the threat list entry the creative URL matched
+func (t threatList) match(u creativeURL) (string, bool) {
+	if domain, ok := matchDomain(u.host, t.domains); ok {
+		return domain, true
+	}
+
+	url := stripScheme(strings.ToLower(u.raw))
+	for _, pattern := range t.urls {
+		if strings.Contains(url, pattern) {
+			return pattern, true
+		}
+	}
+	return "", false
+}
+
+// matchDomain returns the entry of the list which is equal to the host or one of its parent domains
+func matchDomain(host string, domains []string) (string, bool) {
+	for _, domain := range domains {
+		domain = strings.ToLower(domain)
+		if host == domain || strings.HasSuffix(host, "."+domain) {
+			return domain, true
+		}
+	}
+	return "", false
+}
+
+func stripScheme(url string) string {
+	for _, prefix := range []string{"https://", "http://", "//"} {
+		if strings.HasPrefix(url, prefix) {
+			return url[len(prefix):]
+		}
+	}
+	return url
+}
@@ -74,6 +74,8 @@ type ExtImpPrebidFloors struct {
 // ExtStoredRequest defines the contract for bidrequest.imp[i].ext.prebid.storedrequest
 type ExtStoredRequest struct {
 	ID string `json:"id"`
+	// Params are the parameters of a parameterized stored request, resolving its ext.prebid.storedrequest.params placeholders
+	Params json.RawMessage `json:"params,omitempty"`
 }
 
 // ExtStoredAuctionResponse defines the contract for bidrequest.imp[i].ext.prebid.storedauctionresponse
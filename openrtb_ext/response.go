@@ -31,6 +31,46 @@ type ExtResponseDebug struct {
 	HttpCalls map[BidderName][]*ExtHttpCall `json:"httpcalls,omitempty"`
 	// Request after resolution of stored requests and debug overrides
 	ResolvedRequest json.RawMessage `json:"resolvedrequest,omitempty"`
+	// Privacy defines the contract for bidresponse.ext.debug.privacy
+	Privacy map[BidderName]*ExtBidderPrivacyTrace `json:"privacy,omitempty"`
+}
+
+// ExtBidderPrivacyTrace defines the contract for bidresponse.ext.debug.privacy.{bidder}. It lists the
+// privacy decisions made for the bidder and the paths of the request fields they changed, never their values.
+type ExtBidderPrivacyTrace struct {
+	Blocked bool `json:"blocked"`
+	// BlockedBy is either the fetchBids activity or gdpr
+	BlockedBy    string                      `json:"blockedby,omitempty"`
+	Activities   map[string]ExtActivityTrace `json:"activities,omitempty"`
+	TCF          *ExtTCFTrace                `json:"tcf,omitempty"`
+	CCPAEnforced bool                        `json:"ccpaenforced"`
+	Scrubbed     []string                    `json:"scrubbed,omitempty"`
+}
+
+// ExtActivityTrace defines the contract for bidresponse.ext.debug.privacy.{bidder}.activities.{activity}
+type ExtActivityTrace struct {
+	Allowed bool `json:"allowed"`
+	// Rule describes the account rule which decided the result, empty when the default applied
+	Rule string `json:"rule,omitempty"`
+}
+
+// ExtTCFTrace defines the contract for bidresponse.ext.debug.privacy.{bidder}.tcf
+type ExtTCFTrace struct {
+	Reason     string             `json:"reason"`
+	VendorID   uint16             `json:"vendorid,omitempty"`
+	BidRequest ExtTCFPurposeTrace `json:"bidrequest"`
+	Geo        ExtTCFPurposeTrace `json:"geo"`
+	ID         ExtTCFPurposeTrace `json:"id"`
+}
+
+// ExtTCFPurposeTrace defines the outcome of the TCF purpose or special feature deciding a permission
+type ExtTCFPurposeTrace struct {
+	Allowed         bool   `json:"allowed"`
+	Purpose         int    `json:"purpose,omitempty"`
+	SpecialFeature  int    `json:"specialfeature,omitempty"`
+	EnforceAlgo     string `json:"enforcealgo,omitempty"`
+	VendorException bool   `json:"vendorexception,omitempty"`
+	Reason          string `json:"reason"`
 }
 
 // ExtResponseSyncData defines the contract for bidresponse.ext.usersync.{bidder}
@@ -49,6 +89,14 @@ type ExtResponsePrebid struct {
 	Targeting        map[string]string `json:"targeting,omitempty"`
 	// SeatNonBid holds the array of Bids which are either rejected, no bids inside bidresponse.ext.prebid.seatnonbid
 	SeatNonBid []SeatNonBid `json:"seatnonbid,omitempty"`
+	// StoredVersions holds the versions of the versioned stored data used by the request, only present when debug is enabled
+	StoredVersions *ExtStoredVersions `json:"storedversions,omitempty"`
+}
+
+// ExtStoredVersions defines the contract for bidresponse.ext.prebid.storedversions
+type ExtStoredVersions struct {
+	Requests map[string]string `json:"requests,omitempty"`
+	Imps     map[string]string `json:"imps,omitempty"`
 }
 
 // FledgeResponse defines the contract for bidresponse.ext.fledge
@@ -124,6 +172,14 @@ type NonBidObject struct {
 // ExtResponseNonBidPrebid represents bidresponse.ext.prebid.seatnonbid[].nonbid[].ext
 type ExtResponseNonBidPrebid struct {
 	Bid NonBidObject `json:"bid"`
+	// Rejection details the rule which rejected the bid, when the rejecting module reports it
+	Rejection *NonBidRejection `json:"rejection,omitempty"`
+}
+
+// NonBidRejection represents bidresponse.ext.prebid.seatnonbid[].nonbid[].ext.prebid.rejection
+type NonBidRejection struct {
+	Rule  string `json:"rule"`
+	Entry string `json:"entry,omitempty"`
 }
 
 type NonBidExt struct {
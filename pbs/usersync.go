@@ -23,6 +23,7 @@ type UserSyncDeps struct {
 	RecaptchaSecret  string
 	HostCookieConfig *config.HostCookie
 	PriorityGroups   [][]string
+	CookieCodec      usersync.Codec
 }
 
 // Struct for parsing json in google's response
@@ -59,8 +60,6 @@ func (deps *UserSyncDeps) VerifyRecaptcha(response string) error {
 func (deps *UserSyncDeps) OptOut(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
 	optout := r.FormValue("optout")
 	rr := r.FormValue("g-recaptcha-response")
-	encoder := usersync.Base64Encoder{}
-	decoder := usersync.Base64Decoder{}
 
 	if rr == "" {
 		http.Redirect(w, r, fmt.Sprintf("%s/static/optout.html", deps.ExternalUrl), http.StatusMovedPermanently)
@@ -77,12 +76,12 @@ func (deps *UserSyncDeps) OptOut(w http.ResponseWriter, r *http.Request, _ httpr
 	}
 
 	// Read Cookie
-	pc := usersync.ReadCookie(r, decoder, deps.HostCookieConfig)
+	pc := usersync.ReadCookie(r, deps.CookieCodec, deps.HostCookieConfig)
 	usersync.SyncHostCookie(r, pc, deps.HostCookieConfig)
 	pc.SetOptOut(optout != "")
 
 	// Write Cookie
-	encodedCookie, err := encoder.Encode(pc)
+	encodedCookie, err := deps.CookieCodec.Encode(pc)
 	if err != nil {
 		w.WriteHeader(http.StatusBadRequest)
 		return
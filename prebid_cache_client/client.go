@@ -14,9 +14,12 @@ import (
 
 	"github.com/prebid/prebid-server/v3/config"
 	"github.com/prebid/prebid-server/v3/metrics"
+	"github.com/prebid/prebid-server/v3/tracing"
 
 	"github.com/buger/jsonparser"
 	"github.com/golang/glog"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 	"golang.org/x/net/context/ctxhttp"
 )
 
@@ -90,6 +93,18 @@ func (c *clientImpl) PutJson(ctx context.Context, values []Cacheable) (uuids []s
 		return nil, errs
 	}
 
+	ctx, span := tracing.Start(ctx, "prebid_cache.put",
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attribute.Int("pbs.cache_items", len(values))),
+	)
+	defer func() {
+		var err error
+		if len(errs) > 0 {
+			err = errs[0]
+		}
+		tracing.End(span, err)
+	}()
+
 	uuidsToReturn := make([]string, len(values))
 
 	postBody, err := encodeValues(values)
@@ -13,6 +13,7 @@ const (
 	ActivityTransmitPreciseGeo
 	ActivityTransmitUniqueRequestIDs
 	ActivityTransmitTIDs
+	ActivityTransmitEIDs
 )
 
 func (a Activity) String() string {
@@ -33,6 +34,8 @@ func (a Activity) String() string {
 		return "transmitUniqueRequestIds"
 	case ActivityTransmitTIDs:
 		return "transmitTid"
+	case ActivityTransmitEIDs:
+		return "transmitEids"
 	}
 
 	return ""
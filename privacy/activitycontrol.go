@@ -49,15 +49,18 @@ func NewActivityControl(cfg *config.AccountPrivacy) ActivityControl {
 		return ac
 	}
 
-	plans := make(map[Activity]ActivityPlan, 8)
-	plans[ActivitySyncUser] = buildPlan(cfg.AllowActivities.SyncUser)
-	plans[ActivityFetchBids] = buildPlan(cfg.AllowActivities.FetchBids)
-	plans[ActivityEnrichUserFPD] = buildPlan(cfg.AllowActivities.EnrichUserFPD)
-	plans[ActivityReportAnalytics] = buildPlan(cfg.AllowActivities.ReportAnalytics)
-	plans[ActivityTransmitUserFPD] = buildPlan(cfg.AllowActivities.TransmitUserFPD)
-	plans[ActivityTransmitPreciseGeo] = buildPlan(cfg.AllowActivities.TransmitPreciseGeo)
-	plans[ActivityTransmitUniqueRequestIDs] = buildPlan(cfg.AllowActivities.TransmitUniqueRequestIds)
-	plans[ActivityTransmitTIDs] = buildPlan(cfg.AllowActivities.TransmitTids)
+	modules := newPrivacyModules(cfg.Modules)
+
+	plans := make(map[Activity]ActivityPlan, 9)
+	plans[ActivitySyncUser] = buildPlan(ActivitySyncUser, cfg.AllowActivities.SyncUser, modules)
+	plans[ActivityFetchBids] = buildPlan(ActivityFetchBids, cfg.AllowActivities.FetchBids, modules)
+	plans[ActivityEnrichUserFPD] = buildPlan(ActivityEnrichUserFPD, cfg.AllowActivities.EnrichUserFPD, modules)
+	plans[ActivityReportAnalytics] = buildPlan(ActivityReportAnalytics, cfg.AllowActivities.ReportAnalytics, modules)
+	plans[ActivityTransmitUserFPD] = buildPlan(ActivityTransmitUserFPD, cfg.AllowActivities.TransmitUserFPD, modules)
+	plans[ActivityTransmitPreciseGeo] = buildPlan(ActivityTransmitPreciseGeo, cfg.AllowActivities.TransmitPreciseGeo, modules)
+	plans[ActivityTransmitUniqueRequestIDs] = buildPlan(ActivityTransmitUniqueRequestIDs, cfg.AllowActivities.TransmitUniqueRequestIds, modules)
+	plans[ActivityTransmitTIDs] = buildPlan(ActivityTransmitTIDs, cfg.AllowActivities.TransmitTids, modules)
+	plans[ActivityTransmitEIDs] = buildPlan(ActivityTransmitEIDs, cfg.AllowActivities.TransmitEids, modules)
 	ac.plans = plans
 
 	ac.IPv4Config = cfg.IPv4Config
@@ -66,17 +69,22 @@ func NewActivityControl(cfg *config.AccountPrivacy) ActivityControl {
 	return ac
 }
 
-func buildPlan(activity config.Activity) ActivityPlan {
+func buildPlan(activity Activity, activityCfg config.Activity, modules privacyModules) ActivityPlan {
 	return ActivityPlan{
-		rules:         cfgToRules(activity.Rules),
-		defaultResult: cfgToDefaultResult(activity.Default),
+		rules:         cfgToRules(activity, activityCfg.Rules, modules),
+		defaultResult: cfgToDefaultResult(activityCfg.Default),
 	}
 }
 
-func cfgToRules(rules []config.ActivityRule) []Rule {
+func cfgToRules(activity Activity, rules []config.ActivityRule, modules privacyModules) []Rule {
 	var enfRules []Rule
 
 	for _, r := range rules {
+		if len(r.PrivacyReg) > 0 {
+			enfRules = append(enfRules, modules.rules(activity, r)...)
+			continue
+		}
+
 		result := ActivityDeny
 		if r.Allow {
 			result = ActivityAllow
@@ -115,11 +123,36 @@ type ActivityPlan struct {
 }
 
 func (p ActivityPlan) Evaluate(target Component, request ActivityRequest) bool {
-	for _, rule := range p.rules {
+	return p.explain(target, request).Allowed
+}
+
+// ActivityDecision explains the result of an activity evaluation
+type ActivityDecision struct {
+	Allowed bool `json:"allowed"`
+	// Rule describes the decisive rule, it is empty when the default result of the activity applied
+	Rule string `json:"rule,omitempty"`
+}
+
+// Explain evaluates the activity like Allow and reports the rule which decided the result
+func (e ActivityControl) Explain(activity Activity, target Component, request ActivityRequest) ActivityDecision {
+	plan, planDefined := e.plans[activity]
+
+	if !planDefined {
+		return ActivityDecision{Allowed: defaultActivityResult}
+	}
+
+	return plan.explain(target, request)
+}
+
+func (p ActivityPlan) explain(target Component, request ActivityRequest) ActivityDecision {
+	for i, rule := range p.rules {
 		result := rule.Evaluate(target, request)
 		if result == ActivityDeny || result == ActivityAllow {
-			return result == ActivityAllow
+			return ActivityDecision{
+				Allowed: result == ActivityAllow,
+				Rule:    describeRule(i, rule),
+			}
 		}
 	}
-	return p.defaultResult
+	return ActivityDecision{Allowed: p.defaultResult}
 }
@@ -32,6 +32,7 @@ func TestNewActivityControl(t *testing.T) {
 					TransmitPreciseGeo:       getTestActivityConfig(false),
 					TransmitUniqueRequestIds: getTestActivityConfig(true),
 					TransmitTids:             getTestActivityConfig(true),
+					TransmitEids:             getTestActivityConfig(true),
 				},
 				IPv6Config: config.IPv6{AnonKeepBits: 32},
 				IPv4Config: config.IPv4{AnonKeepBits: 16},
@@ -46,6 +47,7 @@ func TestNewActivityControl(t *testing.T) {
 					ActivityTransmitPreciseGeo:       getTestActivityPlan(ActivityDeny),
 					ActivityTransmitUniqueRequestIDs: getTestActivityPlan(ActivityAllow),
 					ActivityTransmitTIDs:             getTestActivityPlan(ActivityAllow),
+					ActivityTransmitEIDs:             getTestActivityPlan(ActivityAllow),
 				},
 				IPv6Config: config.IPv6{AnonKeepBits: 32},
 				IPv4Config: config.IPv4{AnonKeepBits: 16},
@@ -142,6 +144,51 @@ func TestActivityControlAllow(t *testing.T) {
 	}
 }
 
+func TestActivityControlExplain(t *testing.T) {
+	testCases := []struct {
+		name             string
+		activityControl  ActivityControl
+		target           Component
+		expectedDecision ActivityDecision
+	}{
+		{
+			name:             "plans_is_nil",
+			activityControl:  ActivityControl{plans: nil},
+			target:           Component{Type: "bidder", Name: "bidderA"},
+			expectedDecision: ActivityDecision{Allowed: true},
+		},
+		{
+			name: "rule_not_matched_default_returned",
+			activityControl: ActivityControl{plans: map[Activity]ActivityPlan{
+				ActivityFetchBids: getTestActivityPlan(ActivityDeny)}},
+			target:           Component{Type: "bidder", Name: "bidderB"},
+			expectedDecision: ActivityDecision{Allowed: true},
+		},
+		{
+			name: "rule_denies",
+			activityControl: ActivityControl{plans: map[Activity]ActivityPlan{
+				ActivityFetchBids: getTestActivityPlan(ActivityDeny)}},
+			target:           Component{Type: "bidder", Name: "bidderA"},
+			expectedDecision: ActivityDecision{Allowed: false, Rule: "rules[0] componentName=[bidderA] componentType=[bidder] deny"},
+		},
+		{
+			name: "rule_allows",
+			activityControl: ActivityControl{plans: map[Activity]ActivityPlan{
+				ActivityFetchBids: getTestActivityPlan(ActivityAllow)}},
+			target:           Component{Type: "bidder", Name: "bidderA"},
+			expectedDecision: ActivityDecision{Allowed: true, Rule: "rules[0] componentName=[bidderA] componentType=[bidder] allow"},
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.name, func(t *testing.T) {
+			decision := test.activityControl.Explain(ActivityFetchBids, test.target, ActivityRequest{})
+			assert.Equal(t, test.expectedDecision, decision)
+			assert.Equal(t, test.activityControl.Allow(ActivityFetchBids, test.target, ActivityRequest{}), decision.Allowed)
+		})
+	}
+}
+
 func TestActivityRequest(t *testing.T) {
 	t.Run("empty", func(t *testing.T) {
 		r := ActivityRequest{}
@@ -3,4 +3,5 @@ package privacy
 // Policies contains privacy signals and consent for non-OpenRTB activities.
 type Policies struct {
 	GPPSID []int8
+	GPP    string
 }
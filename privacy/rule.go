@@ -1,5 +1,15 @@
 package privacy
 
+import "fmt"
+
 type Rule interface {
 	Evaluate(target Component, request ActivityRequest) ActivityResult
 }
+
+// describeRule identifies the rule at the index of an activity plan
+func describeRule(index int, rule Rule) string {
+	if stringer, ok := rule.(fmt.Stringer); ok {
+		return fmt.Sprintf("rules[%d] %s", index, stringer)
+	}
+	return fmt.Sprintf("rules[%d]", index)
+}
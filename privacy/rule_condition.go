@@ -1,5 +1,10 @@
 package privacy
 
+import (
+	"fmt"
+	"strings"
+)
+
 // noClausesDefinedResult represents the default return when there is no matching criteria specified.
 const noClausesDefinedResult = true
 
@@ -26,6 +31,25 @@ func (r ConditionRule) Evaluate(target Component, request ActivityRequest) Activ
 	return r.result
 }
 
+func (r ConditionRule) String() string {
+	var conditions []string
+	if len(r.componentName) > 0 {
+		conditions = append(conditions, fmt.Sprintf("componentName=%v", r.componentName))
+	}
+	if len(r.componentType) > 0 {
+		conditions = append(conditions, fmt.Sprintf("componentType=%v", r.componentType))
+	}
+	if len(r.gppSID) > 0 {
+		conditions = append(conditions, fmt.Sprintf("gppSid=%v", r.gppSID))
+	}
+
+	result := "deny"
+	if r.result == ActivityAllow {
+		result = "allow"
+	}
+	return strings.TrimSpace(strings.Join(conditions, " ") + " " + result)
+}
+
 func evaluateComponentName(target Component, componentNames []string) bool {
 	// no clauses are considered a match
 	if len(componentNames) == 0 {
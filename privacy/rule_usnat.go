@@ -0,0 +1,173 @@
+package privacy
+
+import (
+	"sync"
+
+	gpplib "github.com/prebid/go-gpp"
+	gppConstants "github.com/prebid/go-gpp/constants"
+	"github.com/prebid/prebid-server/v3/config"
+	gppPrivacy "github.com/prebid/prebid-server/v3/privacy/gpp"
+	"github.com/prebid/prebid-server/v3/privacy/usnat"
+)
+
+// privacyModules holds the privacy modules enabled for the account
+type privacyModules struct {
+	usnat *usnatModule
+}
+
+type usnatModule struct {
+	skipSIDs  []int8
+	normalize bool
+	// parser is shared by all activity rules of the module so the GPP string is parsed once per request
+	parser *gppParser
+}
+
+func newPrivacyModules(cfg config.AccountPrivacyModules) privacyModules {
+	var modules privacyModules
+	if cfg.USNat.Enabled {
+		normalize := true
+		if cfg.USNat.Normalize != nil {
+			normalize = *cfg.USNat.Normalize
+		}
+		modules.usnat = &usnatModule{
+			skipSIDs:  cfg.USNat.SkipSIDs,
+			normalize: normalize,
+			parser:    &gppParser{},
+		}
+	}
+	return modules
+}
+
+// rules returns the rules of the enabled privacy modules referenced by the privacyreg condition
+func (m privacyModules) rules(activity Activity, rule config.ActivityRule) []Rule {
+	var rules []Rule
+	for _, reg := range rule.PrivacyReg {
+		if (reg == config.PrivacyRegUSNat || reg == config.PrivacyRegAll) && m.usnat != nil {
+			rules = append(rules, USNatRule{
+				activity:      activity,
+				componentName: rule.Condition.ComponentName,
+				componentType: rule.Condition.ComponentType,
+				module:        m.usnat,
+			})
+			break
+		}
+	}
+	return rules
+}
+
+// USNatRule enforces the MSPA signals of the GPP US national and state sections applicable to the request.
+// It abstains for activities not covered by the MSPA and for requests without a US section.
+type USNatRule struct {
+	activity      Activity
+	componentName []string
+	componentType []string
+	module        *usnatModule
+}
+
+func (r USNatRule) Evaluate(target Component, request ActivityRequest) ActivityResult {
+	if !evaluateComponentName(target, r.componentName) || !evaluateComponentType(target, r.componentType) {
+		return ActivityAbstain
+	}
+
+	allows := usnatEnforcement(r.activity)
+	if allows == nil {
+		return ActivityAbstain
+	}
+
+	gppString := getGPP(request)
+	if gppString == "" {
+		return ActivityAbstain
+	}
+	gpp := r.module.parser.parse(gppString)
+
+	sids := getGPPSID(request)
+	if len(sids) == 0 {
+		for _, sid := range gpp.SectionTypes {
+			sids = append(sids, int8(sid))
+		}
+	}
+
+	result := ActivityAbstain
+	for _, sid := range sids {
+		sectionID := gppConstants.SectionID(sid)
+		if !r.module.enforces(sectionID) {
+			continue
+		}
+
+		i := gppPrivacy.IndexOfSID(gpp, sectionID)
+		if i < 0 {
+			continue
+		}
+
+		// an applicable section which cannot be decoded does not allow the activity
+		fields, ok := usnat.Normalize(gpp.Sections[i])
+		if !ok || !allows(fields) {
+			return ActivityDeny
+		}
+		result = ActivityAllow
+	}
+
+	return result
+}
+
+func (r USNatRule) String() string {
+	return "privacyreg=" + config.PrivacyRegUSNat
+}
+
+func (m *usnatModule) enforces(sid gppConstants.SectionID) bool {
+	if !usnat.IsUSSection(sid) {
+		return false
+	}
+	if !m.normalize && sid != gppConstants.SectionUSPNAT {
+		return false
+	}
+	for _, skip := range m.skipSIDs {
+		if int8(sid) == skip {
+			return false
+		}
+	}
+	return true
+}
+
+// usnatEnforcement returns the check of the normalized section fields governing the activity
+func usnatEnforcement(activity Activity) func(usnat.Fields) bool {
+	switch activity {
+	case ActivityTransmitUserFPD, ActivityTransmitEIDs, ActivitySyncUser:
+		return usnat.Fields.AllowsTransmitUserFPD
+	case ActivityTransmitPreciseGeo:
+		return usnat.Fields.AllowsTransmitPreciseGeo
+	}
+	return nil
+}
+
+func getGPP(request ActivityRequest) string {
+	if request.IsPolicies() {
+		return request.policies.GPP
+	}
+
+	if request.IsBidRequest() && request.bidRequest.Regs != nil {
+		return request.bidRequest.Regs.GPP
+	}
+
+	return ""
+}
+
+// gppParser memoizes the last parsed GPP string, the activity control is built per request
+// and its rules are evaluated for every bidder with the same GPP string
+type gppParser struct {
+	mu        sync.Mutex
+	gppString string
+	gpp       gpplib.GppContainer
+}
+
+func (p *gppParser) parse(gppString string) gpplib.GppContainer {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.gppString != gppString {
+		// sections which fail to decode are kept with zero values and rejected by the usnat normalization
+		p.gpp, _ = gpplib.Parse(gppString)
+		p.gppString = gppString
+	}
+	return p.gpp
+}
@@ -0,0 +1,202 @@
+package privacy
+
+import (
+	"testing"
+
+	gpplib "github.com/prebid/go-gpp"
+	gppConstants "github.com/prebid/go-gpp/constants"
+	"github.com/prebid/go-gpp/sections"
+	"github.com/prebid/go-gpp/sections/uspnat"
+	"github.com/prebid/go-gpp/sections/uspva"
+	"github.com/prebid/openrtb/v20/openrtb2"
+	"github.com/prebid/prebid-server/v3/config"
+	"github.com/prebid/prebid-server/v3/openrtb_ext"
+	"github.com/prebid/prebid-server/v3/util/ptrutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUSNatRuleEvaluate(t *testing.T) {
+	nationalOptOut := encodeTestGPP(t, uspnat.USPNAT{
+		SectionID: gppConstants.SectionUSPNAT,
+		CoreSegment: uspnat.USPNATCoreSegment{
+			Version:                         1,
+			SaleOptOutNotice:                1,
+			SaleOptOut:                      1,
+			SensitiveDataProcessing:         make([]byte, 12),
+			KnownChildSensitiveDataConsents: make([]byte, 2),
+		},
+		GPCSegment: sections.CommonUSGPCSegment{SubsectionType: 1},
+	})
+	nationalNoOptOut := encodeTestGPP(t, uspnat.USPNAT{
+		SectionID: gppConstants.SectionUSPNAT,
+		CoreSegment: uspnat.USPNATCoreSegment{
+			Version:                         1,
+			SaleOptOutNotice:                1,
+			SaleOptOut:                      2,
+			SensitiveDataProcessing:         make([]byte, 12),
+			KnownChildSensitiveDataConsents: make([]byte, 2),
+		},
+		GPCSegment: sections.CommonUSGPCSegment{SubsectionType: 1},
+	})
+	virginiaOptOut := encodeTestGPP(t, uspva.USPVA{
+		SectionID: gppConstants.SectionUSPVA,
+		CoreSegment: sections.CommonUSCoreSegment{
+			Version:                         1,
+			TargetedAdvertisingOptOut:       1,
+			SensitiveDataProcessing:         make([]byte, 8),
+			KnownChildSensitiveDataConsents: make([]byte, 1),
+		},
+	})
+
+	testCases := []struct {
+		name           string
+		activity       Activity
+		config         config.AccountUSNat
+		componentName  []string
+		request        ActivityRequest
+		expectedResult ActivityResult
+	}{
+		{
+			name:           "opt_out_denies",
+			activity:       ActivityTransmitUserFPD,
+			request:        NewRequestFromPolicies(Policies{GPP: nationalOptOut, GPPSID: []int8{7}}),
+			expectedResult: ActivityDeny,
+		},
+		{
+			name:           "no_opt_out_allows",
+			activity:       ActivityTransmitUserFPD,
+			request:        NewRequestFromPolicies(Policies{GPP: nationalNoOptOut, GPPSID: []int8{7}}),
+			expectedResult: ActivityAllow,
+		},
+		{
+			name:           "section_ids_from_gpp_string",
+			activity:       ActivitySyncUser,
+			request:        NewRequestFromPolicies(Policies{GPP: nationalOptOut}),
+			expectedResult: ActivityDeny,
+		},
+		{
+			name:           "section_not_applicable",
+			activity:       ActivityTransmitUserFPD,
+			request:        NewRequestFromPolicies(Policies{GPP: nationalOptOut, GPPSID: []int8{2}}),
+			expectedResult: ActivityAbstain,
+		},
+		{
+			name:           "activity_not_covered",
+			activity:       ActivityFetchBids,
+			request:        NewRequestFromPolicies(Policies{GPP: nationalOptOut, GPPSID: []int8{7}}),
+			expectedResult: ActivityAbstain,
+		},
+		{
+			name:           "opt_out_does_not_apply_to_precise_geo",
+			activity:       ActivityTransmitPreciseGeo,
+			request:        NewRequestFromPolicies(Policies{GPP: nationalOptOut, GPPSID: []int8{7}}),
+			expectedResult: ActivityAllow,
+		},
+		{
+			name:           "no_gpp",
+			activity:       ActivityTransmitUserFPD,
+			request:        NewRequestFromPolicies(Policies{GPPSID: []int8{7}}),
+			expectedResult: ActivityAbstain,
+		},
+		{
+			name:           "component_not_matched",
+			activity:       ActivityTransmitUserFPD,
+			componentName:  []string{"otherBidder"},
+			request:        NewRequestFromPolicies(Policies{GPP: nationalOptOut, GPPSID: []int8{7}}),
+			expectedResult: ActivityAbstain,
+		},
+		{
+			name:           "state_section_normalized",
+			activity:       ActivityTransmitEIDs,
+			request:        NewRequestFromPolicies(Policies{GPP: virginiaOptOut, GPPSID: []int8{9}}),
+			expectedResult: ActivityDeny,
+		},
+		{
+			name:           "state_section_not_enforced_without_normalization",
+			activity:       ActivityTransmitEIDs,
+			config:         config.AccountUSNat{Normalize: ptrutil.ToPtr(false)},
+			request:        NewRequestFromPolicies(Policies{GPP: virginiaOptOut, GPPSID: []int8{9}}),
+			expectedResult: ActivityAbstain,
+		},
+		{
+			name:           "skipped_section",
+			activity:       ActivityTransmitUserFPD,
+			config:         config.AccountUSNat{SkipSIDs: []int8{7}},
+			request:        NewRequestFromPolicies(Policies{GPP: nationalOptOut, GPPSID: []int8{7}}),
+			expectedResult: ActivityAbstain,
+		},
+		{
+			name:           "invalid_section_denies",
+			activity:       ActivityTransmitUserFPD,
+			request:        NewRequestFromPolicies(Policies{GPP: "DBABLA~invalid", GPPSID: []int8{7}}),
+			expectedResult: ActivityDeny,
+		},
+		{
+			name:     "bid_request",
+			activity: ActivityTransmitUserFPD,
+			request: NewRequestFromBidRequest(openrtb_ext.RequestWrapper{BidRequest: &openrtb2.BidRequest{
+				Regs: &openrtb2.Regs{GPP: nationalOptOut, GPPSID: []int8{7}},
+			}}),
+			expectedResult: ActivityDeny,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.name, func(t *testing.T) {
+			test.config.Enabled = true
+			modules := newPrivacyModules(config.AccountPrivacyModules{USNat: test.config})
+			rules := modules.rules(test.activity, config.ActivityRule{
+				Condition:  config.ActivityCondition{ComponentName: test.componentName},
+				PrivacyReg: []string{config.PrivacyRegUSNat},
+			})
+			require.Len(t, rules, 1)
+
+			target := Component{Type: ComponentTypeBidder, Name: "bidderA"}
+			assert.Equal(t, test.expectedResult, rules[0].Evaluate(target, test.request))
+		})
+	}
+}
+
+func TestPrivacyModulesRules(t *testing.T) {
+	enabled := newPrivacyModules(config.AccountPrivacyModules{USNat: config.AccountUSNat{Enabled: true}})
+	disabled := newPrivacyModules(config.AccountPrivacyModules{})
+
+	assert.Len(t, enabled.rules(ActivityTransmitUserFPD, config.ActivityRule{PrivacyReg: []string{config.PrivacyRegUSNat}}), 1)
+	assert.Len(t, enabled.rules(ActivityTransmitUserFPD, config.ActivityRule{PrivacyReg: []string{config.PrivacyRegAll, config.PrivacyRegUSNat}}), 1)
+	assert.Empty(t, enabled.rules(ActivityTransmitUserFPD, config.ActivityRule{PrivacyReg: []string{"other"}}))
+	assert.Empty(t, disabled.rules(ActivityTransmitUserFPD, config.ActivityRule{PrivacyReg: []string{config.PrivacyRegAll}}))
+}
+
+func TestNewActivityControlWithUSNat(t *testing.T) {
+	nationalOptOut := encodeTestGPP(t, uspnat.USPNAT{
+		SectionID: gppConstants.SectionUSPNAT,
+		CoreSegment: uspnat.USPNATCoreSegment{
+			Version:                         1,
+			SharingOptOut:                   1,
+			SensitiveDataProcessing:         make([]byte, 12),
+			KnownChildSensitiveDataConsents: make([]byte, 2),
+		},
+		GPCSegment: sections.CommonUSGPCSegment{SubsectionType: 1},
+	})
+
+	privacyConf := &config.AccountPrivacy{
+		AllowActivities: &config.AllowActivities{
+			TransmitUserFPD: config.Activity{
+				Rules: []config.ActivityRule{{PrivacyReg: []string{config.PrivacyRegAll}}},
+			},
+		},
+		Modules: config.AccountPrivacyModules{USNat: config.AccountUSNat{Enabled: true}},
+	}
+	activityControl := NewActivityControl(privacyConf)
+	target := Component{Type: ComponentTypeBidder, Name: "bidderA"}
+
+	assert.False(t, activityControl.Allow(ActivityTransmitUserFPD, target, NewRequestFromPolicies(Policies{GPP: nationalOptOut})))
+	assert.True(t, activityControl.Allow(ActivityTransmitUserFPD, target, NewRequestFromPolicies(Policies{})))
+}
+
+func encodeTestGPP(t *testing.T, section gpplib.Section) string {
+	gpp, err := gpplib.Encode([]gpplib.Section{section})
+	require.NoError(t, err)
+	return gpp
+}
@@ -0,0 +1,55 @@
+package usnat
+
+// AllowsTransmitUserFPD returns false if the user opted out of the sale, sharing or targeted advertising
+// use of their data, did not receive the required notices, is a known child or withheld consent to
+// process sensitive or personal data. It governs the transmitUfpd, transmitEids and syncUser activities.
+func (f Fields) AllowsTransmitUserFPD() bool {
+	if f.Gpc || f.MspaServiceProviderMode == optedOut {
+		return false
+	}
+
+	if f.SaleOptOut == optedOut || f.SharingOptOut == optedOut || f.TargetedAdvertisingOptOut == optedOut {
+		return false
+	}
+
+	if f.SaleOptOutNotice == notOptedOut || f.SharingOptOutNotice == notOptedOut || f.TargetedAdvertisingOptOutNotice == notOptedOut {
+		return false
+	}
+
+	if f.isKnownChild() || f.PersonalDataConsents == optedOut {
+		return false
+	}
+
+	for _, value := range f.SensitiveDataProcessing {
+		if value == optedOut {
+			return false
+		}
+	}
+
+	return true
+}
+
+// AllowsTransmitPreciseGeo returns false if the user opted out of, or did not consent to, the processing
+// of their precise geolocation, did not receive the sensitive data notices or is a known child.
+func (f Fields) AllowsTransmitPreciseGeo() bool {
+	if f.SensitiveDataProcessing[sensitivePreciseGeolocation] == optedOut {
+		return false
+	}
+
+	if f.SensitiveDataProcessingOptOutNotice == notOptedOut || f.SensitiveDataLimitUseNotice == notOptedOut {
+		return false
+	}
+
+	return !f.isKnownChild()
+}
+
+// isKnownChild returns true if any child consent is applicable, the MSPA does not allow targeted
+// advertising to known children even with a parental consent
+func (f Fields) isKnownChild() bool {
+	for _, value := range f.KnownChildSensitiveDataConsents {
+		if value != notApplicable {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,57 @@
+package usnat
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAllowsTransmitUserFPD(t *testing.T) {
+	testCases := []struct {
+		name     string
+		fields   Fields
+		expected bool
+	}{
+		{name: "no_signals", fields: Fields{}, expected: true},
+		{name: "notices_provided_and_not_opted_out", fields: Fields{SaleOptOutNotice: 1, SaleOptOut: 2, TargetedAdvertisingOptOutNotice: 1, TargetedAdvertisingOptOut: 2}, expected: true},
+		{name: "gpc", fields: Fields{Gpc: true}, expected: false},
+		{name: "service_provider_mode", fields: Fields{MspaServiceProviderMode: 1}, expected: false},
+		{name: "sale_opt_out", fields: Fields{SaleOptOut: 1}, expected: false},
+		{name: "sharing_opt_out", fields: Fields{SharingOptOut: 1}, expected: false},
+		{name: "targeted_advertising_opt_out", fields: Fields{TargetedAdvertisingOptOut: 1}, expected: false},
+		{name: "sale_notice_not_provided", fields: Fields{SaleOptOutNotice: 2}, expected: false},
+		{name: "targeted_advertising_notice_not_provided", fields: Fields{TargetedAdvertisingOptOutNotice: 2}, expected: false},
+		{name: "known_child_with_consent", fields: Fields{KnownChildSensitiveDataConsents: []byte{0, 2}}, expected: false},
+		{name: "personal_data_no_consent", fields: Fields{PersonalDataConsents: 1}, expected: false},
+		{name: "sensitive_data_no_consent", fields: Fields{SensitiveDataProcessing: [sensitiveDataCategories]byte{sensitiveHealth: 1}}, expected: false},
+		{name: "sensitive_data_consent", fields: Fields{SensitiveDataProcessing: [sensitiveDataCategories]byte{sensitiveHealth: 2}}, expected: true},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.expected, test.fields.AllowsTransmitUserFPD())
+		})
+	}
+}
+
+func TestAllowsTransmitPreciseGeo(t *testing.T) {
+	testCases := []struct {
+		name     string
+		fields   Fields
+		expected bool
+	}{
+		{name: "no_signals", fields: Fields{}, expected: true},
+		{name: "sale_opt_out_does_not_apply", fields: Fields{SaleOptOut: 1}, expected: true},
+		{name: "geolocation_consent", fields: Fields{SensitiveDataProcessing: [sensitiveDataCategories]byte{sensitivePreciseGeolocation: 2}}, expected: true},
+		{name: "geolocation_no_consent", fields: Fields{SensitiveDataProcessing: [sensitiveDataCategories]byte{sensitivePreciseGeolocation: 1}}, expected: false},
+		{name: "sensitive_data_notice_not_provided", fields: Fields{SensitiveDataProcessingOptOutNotice: 2}, expected: false},
+		{name: "limit_use_notice_not_provided", fields: Fields{SensitiveDataLimitUseNotice: 2}, expected: false},
+		{name: "known_child", fields: Fields{KnownChildSensitiveDataConsents: []byte{1}}, expected: false},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.expected, test.fields.AllowsTransmitPreciseGeo())
+		})
+	}
+}
@@ -0,0 +1,246 @@
+package usnat
+
+import (
+	gpplib "github.com/prebid/go-gpp"
+	gppConstants "github.com/prebid/go-gpp/constants"
+	"github.com/prebid/go-gpp/sections"
+	"github.com/prebid/go-gpp/sections/uspca"
+	"github.com/prebid/go-gpp/sections/uspco"
+	"github.com/prebid/go-gpp/sections/uspct"
+	"github.com/prebid/go-gpp/sections/uspnat"
+	"github.com/prebid/go-gpp/sections/usput"
+	"github.com/prebid/go-gpp/sections/uspva"
+)
+
+// Values of the MSPA notice, opt-out and consent fields shared by all US sections.
+const (
+	notApplicable byte = 0
+	// optedOut means the user opted out or did not consent, a notice was provided for notice fields
+	optedOut byte = 1
+	// notOptedOut means the user did not opt out or consented, a notice was not provided for notice fields
+	notOptedOut byte = 2
+)
+
+// Indexes of the national section sensitive data categories.
+const (
+	sensitiveRacialOrEthnicOrigin = iota
+	sensitiveReligiousBeliefs
+	sensitiveHealth
+	sensitiveSexLifeOrOrientation
+	sensitiveCitizenshipStatus
+	sensitiveGeneticData
+	sensitiveBiometricData
+	sensitivePreciseGeolocation
+	sensitiveIdentificationNumbers
+	sensitiveAccountCredentials
+	sensitiveUnionMembership
+	sensitiveCommunicationContents
+	sensitiveDataCategories
+)
+
+// stateSensitiveDataMapping maps the sensitive data categories of a state section, in their section order,
+// to the national section categories. A single state category may cover several national categories.
+var stateSensitiveDataMapping = map[gppConstants.SectionID][][]int{
+	gppConstants.SectionUSPCA: {
+		{sensitiveIdentificationNumbers},
+		{sensitiveAccountCredentials},
+		{sensitivePreciseGeolocation},
+		{sensitiveRacialOrEthnicOrigin, sensitiveReligiousBeliefs, sensitiveUnionMembership},
+		{sensitiveCommunicationContents},
+		{sensitiveGeneticData},
+		{sensitiveBiometricData},
+		{sensitiveHealth},
+		{sensitiveSexLifeOrOrientation},
+	},
+	gppConstants.SectionUSPVA: {
+		{sensitiveRacialOrEthnicOrigin},
+		{sensitiveReligiousBeliefs},
+		{sensitiveHealth},
+		{sensitiveSexLifeOrOrientation},
+		{sensitiveCitizenshipStatus},
+		{sensitiveGeneticData},
+		{sensitiveBiometricData},
+		{sensitivePreciseGeolocation},
+	},
+	gppConstants.SectionUSPCO: {
+		{sensitiveRacialOrEthnicOrigin},
+		{sensitiveReligiousBeliefs},
+		{sensitiveHealth},
+		{sensitiveSexLifeOrOrientation},
+		{sensitiveCitizenshipStatus},
+		{sensitiveGeneticData},
+		{sensitiveBiometricData},
+	},
+	gppConstants.SectionUSPUT: {
+		{sensitiveRacialOrEthnicOrigin},
+		{sensitiveReligiousBeliefs},
+		{sensitiveSexLifeOrOrientation},
+		{sensitiveCitizenshipStatus},
+		{sensitiveHealth},
+		{sensitiveGeneticData},
+		{sensitiveBiometricData},
+		{sensitivePreciseGeolocation},
+	},
+	gppConstants.SectionUSPCT: {
+		{sensitiveRacialOrEthnicOrigin},
+		{sensitiveReligiousBeliefs},
+		{sensitiveHealth},
+		{sensitiveSexLifeOrOrientation},
+		{sensitiveCitizenshipStatus},
+		{sensitiveGeneticData},
+		{sensitiveBiometricData},
+		{sensitivePreciseGeolocation},
+	},
+}
+
+// Fields holds the MSPA signals of a US section normalized to the national section layout.
+type Fields struct {
+	SectionID                           gppConstants.SectionID
+	SaleOptOutNotice                    byte
+	SharingOptOutNotice                 byte
+	TargetedAdvertisingOptOutNotice     byte
+	SensitiveDataProcessingOptOutNotice byte
+	SensitiveDataLimitUseNotice         byte
+	SaleOptOut                          byte
+	SharingOptOut                       byte
+	TargetedAdvertisingOptOut           byte
+	SensitiveDataProcessing             [sensitiveDataCategories]byte
+	KnownChildSensitiveDataConsents     []byte
+	PersonalDataConsents                byte
+	MspaServiceProviderMode             byte
+	Gpc                                 bool
+}
+
+// IsUSSection returns true if the section id is the national or one of the supported state sections.
+func IsUSSection(sid gppConstants.SectionID) bool {
+	return sid == gppConstants.SectionUSPNAT || stateSensitiveDataMapping[sid] != nil
+}
+
+// Normalize converts the national or a state section to the national section layout.
+// It returns false if the section is not a supported US section or could not be decoded.
+func Normalize(section gpplib.Section) (Fields, bool) {
+	switch s := section.(type) {
+	case uspnat.USPNAT:
+		return normalizeNational(s)
+	case uspca.USPCA:
+		return normalizeCalifornia(s)
+	case uspva.USPVA:
+		return normalizeCommon(s.SectionID, s.CoreSegment, sections.CommonUSGPCSegment{})
+	case uspco.USPCO:
+		return normalizeCommon(s.SectionID, s.CoreSegment, s.GPCSegment)
+	case uspct.USPCT:
+		return normalizeCommon(s.SectionID, s.CoreSegment, s.GPCSegment)
+	case usput.USPUT:
+		return normalizeUtah(s)
+	}
+	return Fields{}, false
+}
+
+func normalizeNational(s uspnat.USPNAT) (Fields, bool) {
+	core := s.CoreSegment
+	// sections which failed to decode are returned by the gpp library with a zero version
+	if core.Version == 0 {
+		return Fields{}, false
+	}
+
+	fields := Fields{
+		SectionID:                           gppConstants.SectionUSPNAT,
+		SaleOptOutNotice:                    core.SaleOptOutNotice,
+		SharingOptOutNotice:                 core.SharingOptOutNotice,
+		TargetedAdvertisingOptOutNotice:     core.TargetedAdvertisingOptOutNotice,
+		SensitiveDataProcessingOptOutNotice: core.SensitiveDataProcessingOptOutNotice,
+		SensitiveDataLimitUseNotice:         core.SensitiveDataLimitUseNotice,
+		SaleOptOut:                          core.SaleOptOut,
+		SharingOptOut:                       core.SharingOptOut,
+		TargetedAdvertisingOptOut:           core.TargetedAdvertisingOptOut,
+		KnownChildSensitiveDataConsents:     core.KnownChildSensitiveDataConsents,
+		PersonalDataConsents:                core.PersonalDataConsents,
+		MspaServiceProviderMode:             core.MspaServiceProviderMode,
+		Gpc:                                 s.GPCSegment.Gpc,
+	}
+	copy(fields.SensitiveDataProcessing[:], core.SensitiveDataProcessing)
+	return fields, true
+}
+
+// normalizeCalifornia maps the California sharing opt-out, which covers cross-context behavioral
+// advertising, to both the sharing and the targeted advertising opt-outs.
+func normalizeCalifornia(s uspca.USPCA) (Fields, bool) {
+	core := s.CoreSegment
+	if core.Version == 0 {
+		return Fields{}, false
+	}
+
+	fields := Fields{
+		SectionID:                       gppConstants.SectionUSPCA,
+		SaleOptOutNotice:                core.SaleOptOutNotice,
+		SharingOptOutNotice:             core.SharingOptOutNotice,
+		TargetedAdvertisingOptOutNotice: core.SharingOptOutNotice,
+		SensitiveDataLimitUseNotice:     core.SensitiveDataLimitUseNotice,
+		SaleOptOut:                      core.SaleOptOut,
+		SharingOptOut:                   core.SharingOptOut,
+		TargetedAdvertisingOptOut:       core.SharingOptOut,
+		KnownChildSensitiveDataConsents: core.KnownChildSensitiveDataConsents,
+		PersonalDataConsents:            core.PersonalDataConsents,
+		MspaServiceProviderMode:         core.MspaServiceProviderMode,
+		Gpc:                             s.GPCSegment.Gpc,
+	}
+	fields.SensitiveDataProcessing = mapSensitiveData(gppConstants.SectionUSPCA, core.SensitiveDataProcessing)
+	return fields, true
+}
+
+func normalizeUtah(s usput.USPUT) (Fields, bool) {
+	core := s.CoreSegment
+	if core.Version == 0 {
+		return Fields{}, false
+	}
+
+	fields := Fields{
+		SectionID:                           gppConstants.SectionUSPUT,
+		SaleOptOutNotice:                    core.SaleOptOutNotice,
+		TargetedAdvertisingOptOutNotice:     core.TargetedAdvertisingOptOutNotice,
+		SensitiveDataProcessingOptOutNotice: core.SensitiveDataProcessingOptOutNotice,
+		SaleOptOut:                          core.SaleOptOut,
+		TargetedAdvertisingOptOut:           core.TargetedAdvertisingOptOut,
+		KnownChildSensitiveDataConsents:     []byte{core.KnownChildSensitiveDataConsents},
+		MspaServiceProviderMode:             core.MspaServiceProviderMode,
+	}
+	fields.SensitiveDataProcessing = mapSensitiveData(gppConstants.SectionUSPUT, core.SensitiveDataProcessing)
+	return fields, true
+}
+
+func normalizeCommon(sid gppConstants.SectionID, core sections.CommonUSCoreSegment, gpc sections.CommonUSGPCSegment) (Fields, bool) {
+	if core.Version == 0 {
+		return Fields{}, false
+	}
+
+	fields := Fields{
+		SectionID:                       sid,
+		SaleOptOutNotice:                core.SaleOptOutNotice,
+		TargetedAdvertisingOptOutNotice: core.TargetedAdvertisingOptOutNotice,
+		SaleOptOut:                      core.SaleOptOut,
+		TargetedAdvertisingOptOut:       core.TargetedAdvertisingOptOut,
+		KnownChildSensitiveDataConsents: core.KnownChildSensitiveDataConsents,
+		MspaServiceProviderMode:         core.MspaServiceProviderMode,
+		Gpc:                             gpc.Gpc,
+	}
+	fields.SensitiveDataProcessing = mapSensitiveData(sid, core.SensitiveDataProcessing)
+	return fields, true
+}
+
+// mapSensitiveData moves the state sensitive data values to their national categories,
+// an opt-out or missing consent takes precedence when several state categories share a national one
+func mapSensitiveData(sid gppConstants.SectionID, values []byte) [sensitiveDataCategories]byte {
+	var national [sensitiveDataCategories]byte
+	mapping := stateSensitiveDataMapping[sid]
+	for i, value := range values {
+		if i >= len(mapping) {
+			break
+		}
+		for _, category := range mapping[i] {
+			if national[category] != optedOut {
+				national[category] = value
+			}
+		}
+	}
+	return national
+}
@@ -0,0 +1,151 @@
+package usnat
+
+import (
+	"testing"
+
+	gpplib "github.com/prebid/go-gpp"
+	gppConstants "github.com/prebid/go-gpp/constants"
+	"github.com/prebid/go-gpp/sections"
+	"github.com/prebid/go-gpp/sections/uspca"
+	"github.com/prebid/go-gpp/sections/uspco"
+	"github.com/prebid/go-gpp/sections/uspnat"
+	"github.com/prebid/go-gpp/sections/usput"
+	"github.com/prebid/go-gpp/sections/uspva"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalize(t *testing.T) {
+	testCases := []struct {
+		name           string
+		section        gpplib.Section
+		expectedFields Fields
+		expectedOk     bool
+	}{
+		{
+			name: "national",
+			section: uspnat.USPNAT{
+				SectionID: gppConstants.SectionUSPNAT,
+				CoreSegment: uspnat.USPNATCoreSegment{
+					Version:                         1,
+					SaleOptOutNotice:                1,
+					SaleOptOut:                      2,
+					SensitiveDataProcessing:         []byte{0, 0, 0, 0, 0, 0, 0, 1, 0, 0, 0, 0},
+					KnownChildSensitiveDataConsents: []byte{0, 0},
+					MspaServiceProviderMode:         2,
+				},
+				GPCSegment: sections.CommonUSGPCSegment{Gpc: true},
+			},
+			expectedFields: Fields{
+				SectionID:                       gppConstants.SectionUSPNAT,
+				SaleOptOutNotice:                1,
+				SaleOptOut:                      2,
+				SensitiveDataProcessing:         [sensitiveDataCategories]byte{7: 1},
+				KnownChildSensitiveDataConsents: []byte{0, 0},
+				MspaServiceProviderMode:         2,
+				Gpc:                             true,
+			},
+			expectedOk: true,
+		},
+		{
+			name: "california_sharing_covers_targeted_advertising",
+			section: uspca.USPCA{
+				SectionID: gppConstants.SectionUSPCA,
+				CoreSegment: uspca.USPCACoreSegment{
+					Version:                 1,
+					SharingOptOutNotice:     1,
+					SharingOptOut:           1,
+					SensitiveDataProcessing: []byte{0, 0, 1, 2, 0, 0, 0, 0, 0},
+				},
+			},
+			expectedFields: Fields{
+				SectionID:                       gppConstants.SectionUSPCA,
+				SharingOptOutNotice:             1,
+				TargetedAdvertisingOptOutNotice: 1,
+				SharingOptOut:                   1,
+				TargetedAdvertisingOptOut:       1,
+				SensitiveDataProcessing: [sensitiveDataCategories]byte{
+					sensitivePreciseGeolocation:   1,
+					sensitiveRacialOrEthnicOrigin: 2,
+					sensitiveReligiousBeliefs:     2,
+					sensitiveUnionMembership:      2,
+				},
+			},
+			expectedOk: true,
+		},
+		{
+			name: "virginia",
+			section: uspva.USPVA{
+				SectionID: gppConstants.SectionUSPVA,
+				CoreSegment: sections.CommonUSCoreSegment{
+					Version:                   1,
+					TargetedAdvertisingOptOut: 1,
+					SensitiveDataProcessing:   []byte{0, 0, 0, 0, 0, 0, 0, 2},
+				},
+			},
+			expectedFields: Fields{
+				SectionID:                 gppConstants.SectionUSPVA,
+				TargetedAdvertisingOptOut: 1,
+				SensitiveDataProcessing:   [sensitiveDataCategories]byte{sensitivePreciseGeolocation: 2},
+			},
+			expectedOk: true,
+		},
+		{
+			name: "colorado_with_gpc",
+			section: uspco.USPCO{
+				SectionID:   gppConstants.SectionUSPCO,
+				CoreSegment: sections.CommonUSCoreSegment{Version: 1, KnownChildSensitiveDataConsents: []byte{1}},
+				GPCSegment:  sections.CommonUSGPCSegment{Gpc: true},
+			},
+			expectedFields: Fields{
+				SectionID:                       gppConstants.SectionUSPCO,
+				KnownChildSensitiveDataConsents: []byte{1},
+				Gpc:                             true,
+			},
+			expectedOk: true,
+		},
+		{
+			name: "utah_reorders_sensitive_data",
+			section: usput.USPUT{
+				SectionID: gppConstants.SectionUSPUT,
+				CoreSegment: usput.USPUTCoreSegment{
+					Version:                 1,
+					SensitiveDataProcessing: []byte{0, 0, 1, 0, 2, 0, 0, 0},
+				},
+			},
+			expectedFields: Fields{
+				SectionID: gppConstants.SectionUSPUT,
+				SensitiveDataProcessing: [sensitiveDataCategories]byte{
+					sensitiveSexLifeOrOrientation: 1,
+					sensitiveHealth:               2,
+				},
+				KnownChildSensitiveDataConsents: []byte{0},
+			},
+			expectedOk: true,
+		},
+		{
+			name:       "undecoded_section",
+			section:    uspnat.USPNAT{},
+			expectedOk: false,
+		},
+		{
+			name:       "unsupported_section",
+			section:    gpplib.GenericSection{},
+			expectedOk: false,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.name, func(t *testing.T) {
+			fields, ok := Normalize(test.section)
+			assert.Equal(t, test.expectedOk, ok)
+			assert.Equal(t, test.expectedFields, fields)
+		})
+	}
+}
+
+func TestIsUSSection(t *testing.T) {
+	assert.True(t, IsUSSection(gppConstants.SectionUSPNAT))
+	assert.True(t, IsUSSection(gppConstants.SectionUSPCT))
+	assert.False(t, IsUSSection(gppConstants.SectionUSPV1))
+	assert.False(t, IsUSSection(gppConstants.SectionTCFEU2))
+}
@@ -0,0 +1,51 @@
+package aspects
+
+import (
+	"net/http"
+
+	"github.com/julienschmidt/httprouter"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/prebid/prebid-server/v3/tracing"
+)
+
+// Traced starts a span for each request of the endpoint. The span is the child of the traceparent header of
+// the request, if any, and is passed to the endpoint in the request context.
+func Traced(f httprouter.Handle, spanName string) httprouter.Handle {
+	return func(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
+		ctx := tracing.Extract(r.Context(), r.Header)
+		ctx, span := tracing.Start(ctx, spanName,
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(
+				attribute.String("http.request.method", r.Method),
+				attribute.String("url.path", r.URL.Path),
+			),
+		)
+		defer span.End()
+
+		recorder := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		f(recorder, r.WithContext(ctx), params)
+
+		span.SetAttributes(attribute.Int("http.response.status_code", recorder.status))
+		if recorder.status >= http.StatusInternalServerError {
+			span.SetStatus(codes.Error, http.StatusText(recorder.status))
+		}
+	}
+}
+
+// statusRecorder records the status code of the response
+type statusRecorder struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	if !r.wroteHeader {
+		r.status = status
+		r.wroteHeader = true
+	}
+	r.ResponseWriter.WriteHeader(status)
+}
@@ -0,0 +1,80 @@
+package aspects
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/prebid/prebid-server/v3/tracing/tracingtest"
+)
+
+func TestTraced(t *testing.T) {
+	testCases := []struct {
+		name                 string
+		traceparent          string
+		status               int
+		expectedTraceID      string
+		expectedRemoteParent bool
+		expectedStatusCode   codes.Code
+	}{
+		{
+			name:               "new-trace",
+			status:             http.StatusOK,
+			expectedStatusCode: codes.Unset,
+		},
+		{
+			name:                 "inbound-traceparent",
+			traceparent:          "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+			status:               http.StatusOK,
+			expectedTraceID:      "4bf92f3577b34da6a3ce929d0e0e4736",
+			expectedRemoteParent: true,
+			expectedStatusCode:   codes.Unset,
+		},
+		{
+			name:               "server-error",
+			status:             http.StatusServiceUnavailable,
+			expectedStatusCode: codes.Error,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.name, func(t *testing.T) {
+			exporter := tracingtest.Setup(t)
+
+			var handlerSpan trace.SpanContext
+			handler := Traced(func(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+				handlerSpan = trace.SpanContextFromContext(r.Context())
+				w.WriteHeader(test.status)
+			}, "openrtb2.auction")
+
+			req := httptest.NewRequest(http.MethodPost, "/openrtb2/auction", nil)
+			if test.traceparent != "" {
+				req.Header.Set("traceparent", test.traceparent)
+			}
+			recorder := httptest.NewRecorder()
+			handler(recorder, req, nil)
+
+			assert.Equal(t, test.status, recorder.Code)
+			spans := exporter.GetSpans()
+			if !assert.Len(t, spans, 1) {
+				return
+			}
+			span := spans[0]
+			assert.Equal(t, "openrtb2.auction", span.Name)
+			assert.Equal(t, trace.SpanKindServer, span.SpanKind)
+			assert.Equal(t, handlerSpan, span.SpanContext, "the handler runs in the span")
+			assert.Equal(t, test.expectedRemoteParent, span.Parent.IsRemote())
+			if test.expectedTraceID != "" {
+				assert.Equal(t, test.expectedTraceID, span.SpanContext.TraceID().String())
+			}
+			assert.Contains(t, span.Attributes, attribute.Int("http.response.status_code", test.status))
+			assert.Equal(t, test.expectedStatusCode, span.Status.Code)
+		})
+	}
+}
@@ -12,6 +12,7 @@ import (
 
 	openrtb2model "github.com/prebid/openrtb/v20/openrtb2"
 	analyticsBuild "github.com/prebid/prebid-server/v3/analytics/build"
+	"github.com/prebid/prebid-server/v3/bidnotifications"
 	"github.com/prebid/prebid-server/v3/config"
 	"github.com/prebid/prebid-server/v3/currency"
 	"github.com/prebid/prebid-server/v3/endpoints"
@@ -35,12 +36,16 @@ import (
 	pbc "github.com/prebid/prebid-server/v3/prebid_cache_client"
 	"github.com/prebid/prebid-server/v3/router/aspects"
 	"github.com/prebid/prebid-server/v3/server/ssl"
+	"github.com/prebid/prebid-server/v3/stored_requests"
 	storedRequestsConf "github.com/prebid/prebid-server/v3/stored_requests/config"
+	"github.com/prebid/prebid-server/v3/stored_requests/management"
+	"github.com/prebid/prebid-server/v3/tracing"
 	"github.com/prebid/prebid-server/v3/usersync"
 	"github.com/prebid/prebid-server/v3/util/jsonutil"
 	"github.com/prebid/prebid-server/v3/util/uuidutil"
 	"github.com/prebid/prebid-server/v3/version"
 
+	"github.com/benbjohnson/clock"
 	_ "github.com/go-sql-driver/mysql"
 	"github.com/golang/glog"
 	"github.com/julienschmidt/httprouter"
@@ -116,6 +121,8 @@ type Router struct {
 	*httprouter.Router
 	MetricsEngine   *metricsConf.DetailedMetricsEngine
 	ParamsValidator openrtb_ext.BidderParamValidator
+	// AdminHandlers holds the handlers served by the admin server, keyed by path
+	AdminHandlers map[string]http.HandlerFunc
 
 	shutdowns []func()
 }
@@ -191,11 +198,24 @@ func New(cfg *config.Configuration, rateConvertor *currency.RateConverter) (r *R
 		glog.Fatalf("Failed to init hook modules: %v", err)
 	}
 
+	shutdownTracing, err := tracing.Setup(cfg.Tracing)
+	if err != nil {
+		glog.Fatalf("Failed to set up tracing: %v", err)
+	}
+	r.shutdowns = append(r.shutdowns, shutdownTracing)
+
 	// Metrics engine
 	r.MetricsEngine = metricsConf.NewMetricsEngine(cfg, openrtb_ext.CoreBidderNames(), syncerKeys, moduleStageNames)
+
+	var liveBidderWeights usersync.BidderWeights
+	if cfg.UserSync.Chooser.LiveStats.Enabled {
+		bidderStats := usersync.NewBidderStats(time.Duration(cfg.UserSync.Chooser.LiveStats.HalfLifeSeconds) * time.Second)
+		r.MetricsEngine.MetricsEngine = &metricsConf.MultiMetricsEngine{r.MetricsEngine.MetricsEngine, bidderStats}
+		liveBidderWeights = bidderStats
+	}
 	shutdown, fetcher, ampFetcher, accounts, categoriesFetcher, videoFetcher, storedRespFetcher := storedRequestsConf.NewStoredRequests(cfg, r.MetricsEngine, generalHttpClient, r.Router)
 
-	analyticsRunner := analyticsBuild.New(&cfg.Analytics)
+	analyticsRunner := analyticsBuild.New(&cfg.Analytics, r.MetricsEngine)
 
 	// register the analytics runner for shutdown
 	r.shutdowns = append(r.shutdowns, shutdown, analyticsRunner.Shutdown)
@@ -211,8 +231,12 @@ func New(cfg *config.Configuration, rateConvertor *currency.RateConverter) (r *R
 	defReqJSON := readDefaultRequest(cfg.DefReqConfig)
 
 	gvlVendorIDs := cfg.BidderInfos.ToGVLVendorIDMap()
-	vendorListFetcher := gdpr.NewVendorListFetcher(context.Background(), cfg.GDPR, generalHttpClient, gdpr.VendorListURLMaker)
-	gdprPermsBuilder := gdpr.NewPermissionsBuilder(cfg.GDPR, gvlVendorIDs, vendorListFetcher)
+	vendorListSource, err := gdpr.NewVendorListSource(cfg.GDPR.VendorList, generalHttpClient)
+	if err != nil {
+		glog.Fatalf("Failed to create the gdpr vendor list source: %v", err)
+	}
+	vendorLists := gdpr.NewVendorLists(context.Background(), cfg.GDPR, vendorListSource)
+	gdprPermsBuilder := gdpr.NewPermissionsBuilder(cfg.GDPR, gvlVendorIDs, vendorLists.Fetch)
 	tcf2CfgBuilder := gdpr.NewTCF2Config
 
 	cacheClient := pbc.NewClient(cacheHttpClient, &cfg.CacheURL, &cfg.ExtCacheURL, r.MetricsEngine)
@@ -233,19 +257,51 @@ func New(cfg *config.Configuration, rateConvertor *currency.RateConverter) (r *R
 	tmaxAdjustments := exchange.ProcessTMaxAdjustments(cfg.TmaxAdjustments)
 	planBuilder := hooks.NewExecutionPlanBuilder(cfg.Hooks, repo)
 	macroReplacer := macros.NewStringIndexBasedReplacer()
-	theExchange := exchange.NewExchange(adapters, cacheClient, cfg, requestValidator, syncersByBidder, r.MetricsEngine, cfg.BidderInfos, gdprPermsBuilder, rateConvertor, categoriesFetcher, adsCertSigner, macroReplacer, priceFloorFetcher, singleFormatAdapters)
+	bidNotifier := bidnotifications.NewNotifier(cfg.BidNotifications, generalHttpClient, r.MetricsEngine, clock.New())
+	if bidNotifier != nil {
+		r.shutdowns = append(r.shutdowns, bidNotifier.Shutdown)
+	}
+	theExchange := exchange.NewExchange(adapters, cacheClient, cfg, requestValidator, syncersByBidder, r.MetricsEngine, cfg.BidderInfos, gdprPermsBuilder, rateConvertor, categoriesFetcher, adsCertSigner, macroReplacer, priceFloorFetcher, singleFormatAdapters, bidNotifier)
+	r.AdminHandlers = map[string]http.HandlerFunc{
+		"/gdpr/vendorlists": endpoints.NewVendorListsEndpoint(vendorLists),
+	}
+	if explainer, ok := theExchange.(exchange.PrivacyExplainer); ok {
+		r.AdminHandlers["/privacy/explain"] = endpoints.NewPrivacyExplainEndpoint(cfg, accounts, explainer, r.MetricsEngine)
+	}
+	if resolver, ok := accounts.(stored_requests.AccountResolver); ok {
+		r.AdminHandlers["/accounts/resolved"] = endpoints.NewResolvedAccountEndpoint(resolver, cfg.AccountDefaultsJSON())
+	}
+	if cfg.StoredDataManagement.Enabled {
+		storedDataStore, shutdownStore := storedRequestsConf.NewStoredDataStore(cfg.StoredDataManagement)
+		r.shutdowns = append(r.shutdowns, shutdownStore)
+		storedDataValidator := management.NewValidator(requestValidator, paramsValidator, activeBidders)
+		r.AdminHandlers["/stored_data/"] = endpoints.NewStoredDataEndpoint(storedDataStore, storedDataValidator, cfg.MaxRequestSize, time.Duration(cfg.StoredDataManagement.TimeoutMS)*time.Millisecond)
+	}
+
+	cookieCodec, err := usersync.NewCodec(cfg.HostCookie, r.MetricsEngine)
+	if err != nil {
+		glog.Fatalf("Failed to create the uids cookie codec. %v", err)
+	}
+
+	var uidMappingStore usersync.UIDMappingStore
+	var uidMappings *usersync.UIDMappings
+	if cfg.UserSync.UIDMapping.Enabled {
+		uidMappingStore = usersync.NewMemoryUIDMappingStore(cfg.UserSync.UIDMapping.Memory.SizeBytes)
+		uidMappings = usersync.NewUIDMappings(uidMappingStore, cfg.HostCookie.Family, cfg.UserSync.UIDMapping.HashedEmailEIDSource, time.Duration(cfg.UserSync.UIDMapping.TimeoutMS)*time.Millisecond)
+	}
+
 	var uuidGenerator uuidutil.UUIDRandomGenerator
-	openrtbEndpoint, err := openrtb2.NewEndpoint(uuidGenerator, theExchange, requestValidator, fetcher, accounts, cfg, r.MetricsEngine, analyticsRunner, disabledBidders, defReqJSON, activeBidders, storedRespFetcher, planBuilder, tmaxAdjustments)
+	openrtbEndpoint, err := openrtb2.NewEndpoint(uuidGenerator, theExchange, requestValidator, fetcher, accounts, cfg, r.MetricsEngine, analyticsRunner, disabledBidders, defReqJSON, activeBidders, storedRespFetcher, planBuilder, tmaxAdjustments, cookieCodec, uidMappings)
 	if err != nil {
 		glog.Fatalf("Failed to create the openrtb2 endpoint handler. %v", err)
 	}
 
-	ampEndpoint, err := openrtb2.NewAmpEndpoint(uuidGenerator, theExchange, requestValidator, ampFetcher, accounts, cfg, r.MetricsEngine, analyticsRunner, disabledBidders, defReqJSON, activeBidders, storedRespFetcher, planBuilder, tmaxAdjustments)
+	ampEndpoint, err := openrtb2.NewAmpEndpoint(uuidGenerator, theExchange, requestValidator, ampFetcher, accounts, cfg, r.MetricsEngine, analyticsRunner, disabledBidders, defReqJSON, activeBidders, storedRespFetcher, planBuilder, tmaxAdjustments, cookieCodec, uidMappings)
 	if err != nil {
 		glog.Fatalf("Failed to create the amp endpoint handler. %v", err)
 	}
 
-	videoEndpoint, err := openrtb2.NewVideoEndpoint(uuidGenerator, theExchange, requestValidator, fetcher, videoFetcher, accounts, cfg, r.MetricsEngine, analyticsRunner, disabledBidders, defReqJSON, activeBidders, cacheClient, tmaxAdjustments)
+	videoEndpoint, err := openrtb2.NewVideoEndpoint(uuidGenerator, theExchange, requestValidator, fetcher, videoFetcher, accounts, cfg, r.MetricsEngine, analyticsRunner, disabledBidders, defReqJSON, activeBidders, cacheClient, tmaxAdjustments, cookieCodec, uidMappings)
 	if err != nil {
 		glog.Fatalf("Failed to create the video endpoint handler. %v", err)
 	}
@@ -255,13 +311,19 @@ func New(cfg *config.Configuration, rateConvertor *currency.RateConverter) (r *R
 		videoEndpoint = aspects.QueuedRequestTimeout(videoEndpoint, cfg.RequestTimeoutHeaders, r.MetricsEngine, metrics.ReqTypeVideo)
 	}
 
+	if cfg.Tracing.Enabled {
+		openrtbEndpoint = aspects.Traced(openrtbEndpoint, "openrtb2.auction")
+		ampEndpoint = aspects.Traced(ampEndpoint, "openrtb2.amp")
+		videoEndpoint = aspects.Traced(videoEndpoint, "openrtb2.video")
+	}
+
 	r.POST("/openrtb2/auction", openrtbEndpoint)
 	r.POST("/openrtb2/video", videoEndpoint)
 	r.GET("/openrtb2/amp", ampEndpoint)
 	r.GET("/info/bidders", infoEndpoints.NewBiddersEndpoint(cfg.BidderInfos))
 	r.GET("/info/bidders/:bidderName", infoEndpoints.NewBiddersDetailEndpoint(cfg.BidderInfos))
 	r.GET("/bidders/params", NewJsonDirectoryServer(schemaDirectory, paramsValidator))
-	r.POST("/cookie_sync", endpoints.NewCookieSyncEndpoint(syncersByBidder, cfg, gdprPermsBuilder, tcf2CfgBuilder, r.MetricsEngine, analyticsRunner, accounts, activeBidders).Handle)
+	r.POST("/cookie_sync", endpoints.NewCookieSyncEndpoint(syncersByBidder, cfg, gdprPermsBuilder, tcf2CfgBuilder, r.MetricsEngine, analyticsRunner, accounts, activeBidders, cookieCodec, liveBidderWeights).Handle)
 	r.GET("/status", endpoints.NewStatusEndpoint(cfg.StatusResponse))
 	r.GET("/", serveIndex)
 	r.Handler("GET", "/version", endpoints.NewVersionEndpoint(version.Ver, version.Rev))
@@ -274,7 +336,7 @@ func New(cfg *config.Configuration, rateConvertor *currency.RateConverter) (r *R
 	}
 
 	// event endpoint
-	eventEndpoint := events.NewEventEndpoint(cfg, accounts, analyticsRunner, r.MetricsEngine)
+	eventEndpoint := events.NewEventEndpoint(cfg, accounts, analyticsRunner, r.MetricsEngine, bidNotifier)
 	r.GET("/event", eventEndpoint)
 
 	userSyncDeps := &pbs.UserSyncDeps{
@@ -282,13 +344,22 @@ func New(cfg *config.Configuration, rateConvertor *currency.RateConverter) (r *R
 		ExternalUrl:      cfg.ExternalURL,
 		RecaptchaSecret:  cfg.RecaptchaSecret,
 		PriorityGroups:   cfg.UserSync.PriorityGroups,
+		CookieCodec:      cookieCodec,
 	}
 
-	r.GET("/setuid", endpoints.NewSetUIDEndpoint(cfg, syncersByBidder, gdprPermsBuilder, tcf2CfgBuilder, analyticsRunner, accounts, r.MetricsEngine))
-	r.GET("/getuids", endpoints.NewGetUIDsEndpoint(cfg.HostCookie))
+	r.GET("/setuid", endpoints.NewSetUIDEndpoint(cfg, syncersByBidder, gdprPermsBuilder, tcf2CfgBuilder, analyticsRunner, accounts, r.MetricsEngine, cookieCodec))
+	r.GET("/getuids", endpoints.NewGetUIDsEndpoint(cfg.HostCookie, cookieCodec))
+	if uidMappingStore != nil {
+		r.POST("/uid_mapping", endpoints.NewUIDMappingEndpoint(cfg, syncersByBidder, gdprPermsBuilder, tcf2CfgBuilder, accounts, r.MetricsEngine, uidMappingStore))
+	}
 	r.POST("/optout", userSyncDeps.OptOut)
 	r.GET("/optout", userSyncDeps.OptOut)
 
+	// the StatsD metrics are sent last, after the shutdowns which record metrics
+	if r.MetricsEngine.StatsDMetrics != nil {
+		r.shutdowns = append(r.shutdowns, r.MetricsEngine.StatsDMetrics.Shutdown)
+	}
+
 	return r, nil
 }
 
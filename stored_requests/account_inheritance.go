@@ -0,0 +1,289 @@
+package stored_requests
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+
+	jsonpatch "gopkg.in/evanphx/json-patch.v5"
+
+	"github.com/prebid/prebid-server/v3/util/jsonutil"
+)
+
+// AccountDefaultsLayer is the name of the account defaults in the layers of a ResolvedAccount
+const AccountDefaultsLayer = "defaults"
+
+// AccountResolver resolves the accounts along with the accounts they inherit from
+type AccountResolver interface {
+	// ResolveAccount returns the account merged over its parent chain and the account defaults
+	ResolveAccount(ctx context.Context, accountDefaultsJSON json.RawMessage, accountID string) (ResolvedAccount, []error)
+}
+
+// ResolvedAccount is an account merged over the accounts it inherits from
+type ResolvedAccount struct {
+	// Account is the merged account config
+	Account json.RawMessage
+	// Chain is the IDs of the account and its ancestors, from the root ancestor to the account
+	Chain []string
+	// Layers is the data of the account defaults and then of each account of the Chain, in the order they're merged
+	Layers []AccountLayer
+}
+
+// AccountLayer is a config merged into a ResolvedAccount
+type AccountLayer struct {
+	Name string
+	Data json.RawMessage
+}
+
+// AccountInheritanceError is returned when the parent chain of an account is broken
+type AccountInheritanceError struct {
+	AccountID string
+	Message   string
+}
+
+func (e AccountInheritanceError) Error() string {
+	return fmt.Sprintf("The parent chain of account %s is invalid: %s", e.AccountID, e.Message)
+}
+
+type accountInheritanceFetcher struct {
+	AllFetcher
+	resolved *ResolvedAccountCache
+}
+
+// WithAccountInheritance returns a fetcher which merges the accounts over the chain of their parent accounts,
+// declared by their "parent" field, and the account defaults. The accounts of the fetcher must not be merged
+// with the account defaults. The resolved accounts are cached in resolved, unless it's nil.
+func WithAccountInheritance(fetcher AllFetcher, resolved *ResolvedAccountCache) AllFetcher {
+	return &accountInheritanceFetcher{
+		AllFetcher: fetcher,
+		resolved:   resolved,
+	}
+}
+
+func (f *accountInheritanceFetcher) FetchAccount(ctx context.Context, accountDefaultsJSON json.RawMessage, accountID string) (json.RawMessage, []error) {
+	if account, ok := f.resolved.get(ctx, accountID); ok {
+		return account, nil
+	}
+
+	generation := f.resolved.currentGeneration()
+	resolved, errs := f.ResolveAccount(ctx, accountDefaultsJSON, accountID)
+	if len(errs) > 0 {
+		return nil, errs
+	}
+	f.resolved.save(ctx, accountID, resolved, generation)
+	return resolved.Account, nil
+}
+
+func (f *accountInheritanceFetcher) ResolveAccount(ctx context.Context, accountDefaultsJSON json.RawMessage, accountID string) (ResolvedAccount, []error) {
+	var chain []string
+	var layers []AccountLayer
+	seen := make(map[string]bool)
+	for id := accountID; id != ""; {
+		if seen[id] {
+			return ResolvedAccount{}, []error{AccountInheritanceError{
+				AccountID: accountID,
+				Message:   fmt.Sprintf("it has a cycle: %s -> %s", strings.Join(chain, " -> "), id),
+			}}
+		}
+		seen[id] = true
+
+		account, errs := f.AllFetcher.FetchAccount(ctx, nil, id)
+		if len(errs) > 0 {
+			if id == accountID {
+				return ResolvedAccount{}, errs
+			}
+			return ResolvedAccount{}, []error{AccountInheritanceError{
+				AccountID: accountID,
+				Message:   fmt.Sprintf("the parent account %s can't be fetched: %v", id, errs[0]),
+			}}
+		}
+
+		var parent struct {
+			Parent string `json:"parent"`
+		}
+		if err := jsonutil.Unmarshal(account, &parent); err != nil {
+			if id == accountID {
+				// the account is returned as is, so that it's reported as malformed
+				return ResolvedAccount{Account: account, Chain: []string{id}, Layers: []AccountLayer{{Name: id, Data: account}}}, nil
+			}
+			return ResolvedAccount{}, []error{AccountInheritanceError{
+				AccountID: accountID,
+				Message:   fmt.Sprintf("the parent account %s is malformed: %v", id, err),
+			}}
+		}
+		chain = append(chain, id)
+		layers = append(layers, AccountLayer{Name: id, Data: account})
+		id = parent.Parent
+	}
+
+	resolved := ResolvedAccount{Chain: reversed(chain)}
+	if accountDefaultsJSON != nil {
+		resolved.Layers = append(resolved.Layers, AccountLayer{Name: AccountDefaultsLayer, Data: accountDefaultsJSON})
+	}
+	resolved.Layers = append(resolved.Layers, reversed(layers)...)
+
+	for _, layer := range resolved.Layers {
+		if resolved.Account == nil {
+			resolved.Account = layer.Data
+			continue
+		}
+		merged, err := jsonpatch.MergePatch(resolved.Account, layer.Data)
+		if err != nil {
+			return ResolvedAccount{}, []error{err}
+		}
+		resolved.Account = merged
+	}
+	return resolved, nil
+}
+
+// ResolvedAccountCache caches the resolved accounts. Saving or invalidating an account also invalidates the
+// accounts which inherit from it. It's a CacheJSON, so that the event producers of the accounts update it.
+type ResolvedAccountCache struct {
+	cache CacheJSON
+
+	mutex sync.Mutex
+	// generation is incremented by every update, so that the accounts resolved before it aren't cached
+	generation uint64
+	// chains are the ancestors of each cached account, and descendants the cached accounts which inherit from
+	// each account
+	chains      map[string][]string
+	descendants map[string]map[string]struct{}
+}
+
+// NewResolvedAccountCache returns a cache of the resolved accounts, which stores them in cache
+func NewResolvedAccountCache(cache CacheJSON) *ResolvedAccountCache {
+	return &ResolvedAccountCache{
+		cache:       cache,
+		chains:      make(map[string][]string),
+		descendants: make(map[string]map[string]struct{}),
+	}
+}
+
+func (c *ResolvedAccountCache) Get(ctx context.Context, ids []string) map[string]json.RawMessage {
+	return c.cache.Get(ctx, ids)
+}
+
+// Save invalidates the accounts, since the data of the events is the data of the account rather than the
+// resolved account
+func (c *ResolvedAccountCache) Save(ctx context.Context, data map[string]json.RawMessage) {
+	ids := make([]string, 0, len(data))
+	for id := range data {
+		ids = append(ids, id)
+	}
+	c.Invalidate(ctx, ids)
+}
+
+func (c *ResolvedAccountCache) Invalidate(ctx context.Context, ids []string) {
+	c.mutex.Lock()
+	c.generation++
+	invalidated := make([]string, 0, len(ids))
+	for _, id := range ids {
+		invalidated = append(invalidated, id)
+		for descendant := range c.descendants[id] {
+			invalidated = append(invalidated, descendant)
+		}
+	}
+	for _, id := range invalidated {
+		c.forget(id)
+	}
+	c.cache.Invalidate(ctx, invalidated)
+	c.mutex.Unlock()
+}
+
+func (c *ResolvedAccountCache) get(ctx context.Context, id string) (json.RawMessage, bool) {
+	if c == nil {
+		return nil, false
+	}
+	account, ok := c.cache.Get(ctx, []string{id})[id]
+	return account, ok
+}
+
+func (c *ResolvedAccountCache) currentGeneration() uint64 {
+	if c == nil {
+		return 0
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.generation
+}
+
+// save caches the resolved account, unless an account was updated since generation
+func (c *ResolvedAccountCache) save(ctx context.Context, id string, resolved ResolvedAccount, generation uint64) {
+	if c == nil {
+		return
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if c.generation != generation {
+		return
+	}
+
+	c.forget(id)
+	ancestors := resolved.Chain[:len(resolved.Chain)-1]
+	c.chains[id] = ancestors
+	for _, ancestor := range ancestors {
+		if c.descendants[ancestor] == nil {
+			c.descendants[ancestor] = make(map[string]struct{})
+		}
+		c.descendants[ancestor][id] = struct{}{}
+	}
+	// the cache is saved while holding the lock, so that an update can't be overwritten
+	c.cache.Save(ctx, map[string]json.RawMessage{id: resolved.Account})
+}
+
+// forget removes an account from the descendants of its ancestors. It must be called while holding the mutex.
+func (c *ResolvedAccountCache) forget(id string) {
+	for _, ancestor := range c.chains[id] {
+		delete(c.descendants[ancestor], id)
+		if len(c.descendants[ancestor]) == 0 {
+			delete(c.descendants, ancestor)
+		}
+	}
+	delete(c.chains, id)
+}
+
+// AccountSources returns the layer each field of a resolved account comes from, by the dot-separated path of
+// the field. The fields are the values other than objects, so that the objects merged from several layers are
+// traced field by field.
+func AccountSources(layers []AccountLayer) (map[string]string, error) {
+	sources := make(map[string]string)
+	for _, layer := range layers {
+		var data map[string]interface{}
+		if err := jsonutil.Unmarshal(layer.Data, &data); err != nil {
+			return nil, fmt.Errorf("the %s layer is malformed: %v", layer.Name, err)
+		}
+		addSources(sources, layer.Name, "", data)
+	}
+	return sources, nil
+}
+
+// addSources sets the layer as the source of the fields of data, as a JSON merge patch replaces them
+func addSources(sources map[string]string, layer string, prefix string, data map[string]interface{}) {
+	for key, value := range data {
+		path := prefix + key
+		// the leaf at path is replaced by an object, and the fields under path are replaced by a leaf
+		delete(sources, path)
+		if object, ok := value.(map[string]interface{}); ok {
+			addSources(sources, layer, path+".", object)
+			continue
+		}
+		for field := range sources {
+			if strings.HasPrefix(field, path+".") {
+				delete(sources, field)
+			}
+		}
+		if value != nil {
+			sources[path] = layer
+		}
+	}
+}
+
+func reversed[T any](values []T) []T {
+	result := make([]T, len(values))
+	for i, value := range values {
+		result[len(values)-1-i] = value
+	}
+	return result
+}
@@ -0,0 +1,199 @@
+package stored_requests
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// accountsFetcher fetches the accounts of a map, and counts the fetches of each account
+type accountsFetcher struct {
+	AllFetcher
+	accounts map[string]json.RawMessage
+	fetches  map[string]int
+}
+
+func (f *accountsFetcher) FetchAccount(ctx context.Context, accountDefaultsJSON json.RawMessage, accountID string) (json.RawMessage, []error) {
+	f.fetches[accountID]++
+	if account, ok := f.accounts[accountID]; ok {
+		return account, nil
+	}
+	return nil, []error{NotFoundError{ID: accountID, DataType: "Account"}}
+}
+
+// mapCache is a CacheJSON backed by a map
+type mapCache map[string]json.RawMessage
+
+func (c mapCache) Get(ctx context.Context, ids []string) map[string]json.RawMessage {
+	data := make(map[string]json.RawMessage)
+	for _, id := range ids {
+		if value, ok := c[id]; ok {
+			data[id] = value
+		}
+	}
+	return data
+}
+
+func (c mapCache) Save(ctx context.Context, data map[string]json.RawMessage) {
+	for id, value := range data {
+		c[id] = value
+	}
+}
+
+func (c mapCache) Invalidate(ctx context.Context, ids []string) {
+	for _, id := range ids {
+		delete(c, id)
+	}
+}
+
+func TestResolveAccount(t *testing.T) {
+	accounts := map[string]json.RawMessage{
+		"network":   json.RawMessage(`{"gdpr":{"enabled":true,"purpose1":{"enforce_vendors":true}},"price_floors":{"enabled":true}}`),
+		"publisher": json.RawMessage(`{"parent":"network","gdpr":{"purpose1":{"enforce_vendors":false}}}`),
+		"site":      json.RawMessage(`{"parent":"publisher","price_floors":null}`),
+		"orphan":    json.RawMessage(`{"parent":"missing"}`),
+		"cycle-a":   json.RawMessage(`{"parent":"cycle-b"}`),
+		"cycle-b":   json.RawMessage(`{"parent":"cycle-a"}`),
+		"self":      json.RawMessage(`{"parent":"self"}`),
+		"malformed": json.RawMessage(`{"parent":1}`),
+		"broken":    json.RawMessage(`{"parent":"malformed"}`),
+	}
+	defaults := json.RawMessage(`{"disabled":false,"gdpr":{"enabled":false}}`)
+
+	testCases := []struct {
+		name            string
+		accountID       string
+		defaults        json.RawMessage
+		expectedAccount string
+		expectedChain   []string
+		expectedError   string
+	}{
+		{
+			name:            "no_parent",
+			accountID:       "network",
+			defaults:        defaults,
+			expectedAccount: `{"disabled":false,"gdpr":{"enabled":true,"purpose1":{"enforce_vendors":true}},"price_floors":{"enabled":true}}`,
+			expectedChain:   []string{"network"},
+		},
+		{
+			name:            "parent_chain",
+			accountID:       "site",
+			defaults:        defaults,
+			expectedAccount: `{"disabled":false,"gdpr":{"enabled":true,"purpose1":{"enforce_vendors":false}},"parent":"publisher"}`,
+			expectedChain:   []string{"network", "publisher", "site"},
+		},
+		{
+			name:            "no_defaults",
+			accountID:       "publisher",
+			expectedAccount: `{"gdpr":{"enabled":true,"purpose1":{"enforce_vendors":false}},"price_floors":{"enabled":true},"parent":"network"}`,
+			expectedChain:   []string{"network", "publisher"},
+		},
+		{
+			name:          "not_found",
+			accountID:     "unknown",
+			expectedError: "Stored Account with ID=\"unknown\" not found.",
+		},
+		{
+			name:          "missing_parent",
+			accountID:     "orphan",
+			expectedError: "The parent chain of account orphan is invalid: the parent account missing can't be fetched: Stored Account with ID=\"missing\" not found.",
+		},
+		{
+			name:          "cycle",
+			accountID:     "cycle-a",
+			expectedError: "The parent chain of account cycle-a is invalid: it has a cycle: cycle-a -> cycle-b -> cycle-a",
+		},
+		{
+			name:          "own_parent",
+			accountID:     "self",
+			expectedError: "The parent chain of account self is invalid: it has a cycle: self -> self",
+		},
+		{
+			name:          "malformed_parent",
+			accountID:     "broken",
+			expectedError: "The parent chain of account broken is invalid: the parent account malformed is malformed: cannot unmarshal Parent",
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.name, func(t *testing.T) {
+			fetcher := WithAccountInheritance(&accountsFetcher{accounts: accounts, fetches: map[string]int{}}, nil)
+			account, errs := fetcher.FetchAccount(context.Background(), test.defaults, test.accountID)
+			if test.expectedError != "" {
+				require.Len(t, errs, 1)
+				assert.ErrorContains(t, errs[0], test.expectedError)
+				assert.Nil(t, account)
+				return
+			}
+			assert.Empty(t, errs)
+			assert.JSONEq(t, test.expectedAccount, string(account))
+
+			resolved, errs := fetcher.(AccountResolver).ResolveAccount(context.Background(), test.defaults, test.accountID)
+			assert.Empty(t, errs)
+			assert.Equal(t, test.expectedChain, resolved.Chain)
+		})
+	}
+}
+
+func TestResolvedAccountCache(t *testing.T) {
+	accounts := map[string]json.RawMessage{
+		"network":   json.RawMessage(`{"disabled":false}`),
+		"publisher": json.RawMessage(`{"parent":"network"}`),
+		"site":      json.RawMessage(`{"parent":"publisher"}`),
+		"other":     json.RawMessage(`{}`),
+	}
+	backend := &accountsFetcher{accounts: accounts, fetches: map[string]int{}}
+	resolved := NewResolvedAccountCache(mapCache{})
+	fetcher := WithAccountInheritance(backend, resolved)
+	ctx := context.Background()
+
+	for _, id := range []string{"site", "publisher", "other", "site"} {
+		_, errs := fetcher.FetchAccount(ctx, nil, id)
+		require.Empty(t, errs)
+	}
+	assert.Equal(t, map[string]int{"network": 2, "publisher": 2, "site": 1, "other": 1}, backend.fetches, "The resolved accounts should be cached")
+
+	accounts["network"] = json.RawMessage(`{"disabled":true}`)
+	resolved.Save(ctx, map[string]json.RawMessage{"network": accounts["network"]})
+	account, errs := fetcher.FetchAccount(ctx, nil, "site")
+	require.Empty(t, errs)
+	assert.JSONEq(t, `{"disabled":true,"parent":"publisher"}`, string(account), "The update of a parent should invalidate its children")
+	_, errs = fetcher.FetchAccount(ctx, nil, "other")
+	require.Empty(t, errs)
+	assert.Equal(t, 1, backend.fetches["other"], "The accounts which don't inherit from the parent should stay cached")
+
+	resolved.Invalidate(ctx, []string{"publisher"})
+	assert.Equal(t, map[string][]string{"other": {}}, resolved.chains, "The invalidated accounts should be forgotten")
+	assert.Empty(t, resolved.descendants)
+}
+
+func TestResolvedAccountCacheGeneration(t *testing.T) {
+	resolved := NewResolvedAccountCache(mapCache{})
+	generation := resolved.currentGeneration()
+	resolved.Invalidate(context.Background(), []string{"network"})
+	resolved.save(context.Background(), "site", ResolvedAccount{Account: json.RawMessage(`{}`), Chain: []string{"network", "site"}}, generation)
+	assert.Empty(t, resolved.Get(context.Background(), []string{"site"}), "An account resolved before an update shouldn't be cached")
+}
+
+func TestAccountSources(t *testing.T) {
+	sources, err := AccountSources([]AccountLayer{
+		{Name: AccountDefaultsLayer, Data: json.RawMessage(`{"disabled":false,"gdpr":{"enabled":false,"purpose1":{"enforce_vendors":true}},"cookie_sync":{"default_limit":8}}`)},
+		{Name: "network", Data: json.RawMessage(`{"gdpr":{"enabled":true},"price_floors":{"enabled":true,"max_rules":10}}`)},
+		{Name: "publisher", Data: json.RawMessage(`{"parent":"network","gdpr":{"purpose1":null},"price_floors":{"max_rules":5},"cookie_sync":"replaced"}`)},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"disabled":               AccountDefaultsLayer,
+		"gdpr.enabled":           "network",
+		"price_floors.enabled":   "network",
+		"price_floors.max_rules": "publisher",
+		"parent":                 "publisher",
+		"cookie_sync":            "publisher",
+	}, sources)
+
+	_, err = AccountSources([]AccountLayer{{Name: "network", Data: json.RawMessage(`[]`)}})
+	assert.Error(t, err)
+}
@@ -1,6 +1,7 @@
 package db_fetcher
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 
@@ -86,6 +87,9 @@ func (fetcher *dbFetcher) FetchRequests(ctx context.Context, requestIDs []string
 		if err := rows.Scan(&id, &data, &dataType); err != nil {
 			return nil, nil, []error{err}
 		}
+		if isDeleted(data) {
+			continue
+		}
 
 		switch dataType {
 		case "request":
@@ -140,6 +144,9 @@ func (fetcher *dbFetcher) FetchResponses(ctx context.Context, ids []string) (dat
 		if err := rows.Scan(&id, &data, &dataType); err != nil {
 			return nil, []error{err}
 		}
+		if isDeleted(data) {
+			continue
+		}
 		storedData[id] = data
 	}
 
@@ -171,6 +178,11 @@ func appendErrors(dataType string, ids []string, data map[string]json.RawMessage
 	return errs
 }
 
+// isDeleted returns true if the data of a row was deleted, so the row is treated as not found
+func isDeleted(data []byte) bool {
+	return len(data) == 0 || bytes.Equal(data, []byte("null"))
+}
+
 // Returns true if the Postgres error signifies some sort of bad user input, and false otherwise.
 //
 // These errors are documented here: https://www.postgresql.org/docs/9.3/static/errcodes-appendix.html
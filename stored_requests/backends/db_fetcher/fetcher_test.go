@@ -132,6 +132,26 @@ func TestPartialResponse(t *testing.T) {
 	assertHasData(t, storedReqs, "stored-req-id", "{}")
 }
 
+// TestDeletedResponse makes sure rows whose data was deleted are treated as not found.
+func TestDeletedResponse(t *testing.T) {
+	mockQuery := "SELECT id, data, 'request' AS dataType FROM req_table WHERE id IN (?, ?) UNION ALL SELECT id, data, 'imp' as dataType FROM imp_table WHERE id IN (?)"
+	mockReturn := sqlmock.NewRows([]string{"id", "data", "dataType"}).
+		AddRow("stored-req-id", "{}", "request").
+		AddRow("deleted-req-id", nil, "request").
+		AddRow("deleted-imp-id", "null", "imp")
+
+	mock, fetcher := newFetcher(t, mockReturn, mockQuery, "stored-req-id", "deleted-req-id", "deleted-imp-id")
+	defer fetcher.provider.Close()
+
+	storedReqs, storedImps, errs := fetcher.FetchRequests(context.Background(), []string{"stored-req-id", "deleted-req-id"}, []string{"deleted-imp-id"})
+
+	assertMockExpectations(t, mock)
+	assertErrorCount(t, 2, errs)
+	assertMapLength(t, 1, storedReqs)
+	assertMapLength(t, 0, storedImps)
+	assertHasData(t, storedReqs, "stored-req-id", "{}")
+}
+
 // TestEmptyResponse makes sure we handle empty DB responses properly.
 func TestEmptyResponse(t *testing.T) {
 	mockQuery := "SELECT id, data, dataType FROM my_table WHERE id IN (?, ?)"
@@ -16,6 +16,7 @@ type DbProvider interface {
 	Ping() error
 	PrepareQuery(template string, params ...QueryParam) (query string, args []interface{})
 	QueryContext(ctx context.Context, template string, params ...QueryParam) (*sql.Rows, error)
+	BeginTx(ctx context.Context) (*sql.Tx, error)
 }
 
 func NewDbProvider(dataType config.DataType, cfg config.DatabaseConnection) DbProvider {
@@ -65,3 +65,7 @@ func (provider DbProviderMock) QueryContext(ctx context.Context, template string
 
 	return provider.db.QueryContext(ctx, query, args...)
 }
+
+func (provider DbProviderMock) BeginTx(ctx context.Context) (*sql.Tx, error) {
+	return provider.db.BeginTx(ctx, nil)
+}
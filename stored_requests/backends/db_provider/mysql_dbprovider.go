@@ -241,6 +241,10 @@ func (provider *MySqlDbProvider) QueryContext(ctx context.Context, template stri
 	return provider.db.QueryContext(ctx, query, args...)
 }
 
+func (provider *MySqlDbProvider) BeginTx(ctx context.Context) (*sql.Tx, error) {
+	return provider.db.BeginTx(ctx, nil)
+}
+
 func (provider *MySqlDbProvider) createIdList(numArgs int) string {
 	// Any empty list like "()" is illegal in MySql. A (NULL) is the next best thing,
 	// though, since `id IN (NULL)` is valid for all "id" column types, and evaluates to an empty set.
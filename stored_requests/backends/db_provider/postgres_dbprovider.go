@@ -158,6 +158,10 @@ func (provider *PostgresDbProvider) QueryContext(ctx context.Context, template s
 	return provider.db.QueryContext(ctx, query, args...)
 }
 
+func (provider *PostgresDbProvider) BeginTx(ctx context.Context) (*sql.Tx, error) {
+	return provider.db.BeginTx(ctx, nil)
+}
+
 func (provider *PostgresDbProvider) createIdList(numSoFar int, numArgs int) string {
 	// Any empty list like "()" is illegal in Postgres. A (NULL) is the next best thing,
 	// though, since `id IN (NULL)` is valid for all "id" column types, and evaluates to an empty set.
@@ -118,6 +118,11 @@ type FileSystem struct {
 	Files       map[string]json.RawMessage
 }
 
+// NewFileSystem reads the files of the directory and its sub-directories, without parsing them
+func NewFileSystem(directory string) (FileSystem, error) {
+	return collectStoredData(directory, FileSystem{make(map[string]FileSystem), make(map[string]json.RawMessage)}, nil)
+}
+
 func collectStoredData(directory string, fileSystem FileSystem, err error) (FileSystem, error) {
 	if err != nil {
 		return FileSystem{nil, nil}, err
@@ -0,0 +1,286 @@
+package file_fetcher
+
+import (
+	"context"
+	"encoding/json"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/golang/glog"
+	"github.com/prebid/prebid-server/v3/config"
+	"github.com/prebid/prebid-server/v3/metrics"
+	"github.com/prebid/prebid-server/v3/stored_requests"
+	"github.com/prebid/prebid-server/v3/stored_requests/events"
+)
+
+// WatchingFileFetcher serves the stored data of a directory like the FileFetcher does, and reloads it
+// whenever files are added, changed or removed. The changes of each reload are published as cache
+// events, so the caches in front of the fetcher don't keep serving the previous version.
+//
+// A file which doesn't hold valid JSON is rejected, and the last good version of it is kept.
+type WatchingFileFetcher struct {
+	directory     string
+	cfg           config.FileWatchConfig
+	dataType      metrics.StoredDataType
+	metricsEngine metrics.MetricsEngine
+	publishEvents bool
+
+	fetcher atomic.Pointer[eagerFetcher]
+	// invalidFiles holds the content of the files rejected by the last reloads, so that an
+	// invalid file is only reported once and not on every reload.
+	invalidFiles map[string]string
+
+	saves         chan events.Save
+	invalidations chan events.Invalidation
+	stop          chan struct{}
+	done          chan struct{}
+}
+
+// NewWatchingFileFetcher loads the stored data of the directory and starts watching it for changes.
+// The reloads are only published as events when publishEvents is true, in which case the events must
+// be listened to or the reloads stop after the first change. Stop must be called on shutdown.
+func NewWatchingFileFetcher(directory string, cfg config.FileWatchConfig, dataType metrics.StoredDataType, metricsEngine metrics.MetricsEngine, publishEvents bool) (*WatchingFileFetcher, error) {
+	w := &WatchingFileFetcher{
+		directory:     directory,
+		cfg:           cfg,
+		dataType:      dataType,
+		metricsEngine: metricsEngine,
+		publishEvents: publishEvents,
+		invalidFiles:  make(map[string]string),
+		saves:         make(chan events.Save),
+		invalidations: make(chan events.Invalidation),
+		stop:          make(chan struct{}),
+		done:          make(chan struct{}),
+	}
+
+	fileSystem, err := collectStoredData(directory, FileSystem{make(map[string]FileSystem), make(map[string]json.RawMessage)}, nil)
+	if err != nil {
+		return nil, err
+	}
+	w.keepLastGood(directory, fileSystem, FileSystem{})
+	w.fetcher.Store(&eagerFetcher{fileSystem, nil})
+
+	if cfg.Polling {
+		go w.poll()
+		return w, nil
+	}
+
+	watcher, err := w.newWatcher()
+	if err != nil {
+		glog.Warningf("Failed to watch %s for changes, polling it every %dms instead: %v", directory, cfg.PollIntervalMS, err)
+		go w.poll()
+		return w, nil
+	}
+	go w.watch(watcher)
+	return w, nil
+}
+
+func (w *WatchingFileFetcher) FetchRequests(ctx context.Context, requestIDs []string, impIDs []string) (map[string]json.RawMessage, map[string]json.RawMessage, []error) {
+	return w.fetcher.Load().FetchRequests(ctx, requestIDs, impIDs)
+}
+
+func (w *WatchingFileFetcher) FetchResponses(ctx context.Context, ids []string) (map[string]json.RawMessage, []error) {
+	return w.fetcher.Load().FetchResponses(ctx, ids)
+}
+
+func (w *WatchingFileFetcher) FetchAccount(ctx context.Context, accountDefaultsJSON json.RawMessage, accountID string) (json.RawMessage, []error) {
+	return w.fetcher.Load().FetchAccount(ctx, accountDefaultsJSON, accountID)
+}
+
+func (w *WatchingFileFetcher) FetchCategories(ctx context.Context, primaryAdServer, publisherId, iabCategory string) (string, error) {
+	return w.fetcher.Load().FetchCategories(ctx, primaryAdServer, publisherId, iabCategory)
+}
+
+func (w *WatchingFileFetcher) Saves() <-chan events.Save {
+	return w.saves
+}
+
+func (w *WatchingFileFetcher) Invalidations() <-chan events.Invalidation {
+	return w.invalidations
+}
+
+// Stop stops watching the directory
+func (w *WatchingFileFetcher) Stop() {
+	close(w.stop)
+	<-w.done
+}
+
+// newWatcher watches the directory and all of its subdirectories
+func (w *WatchingFileFetcher) newWatcher() (*fsnotify.Watcher, error) {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, err
+	}
+	if err := addWatches(watcher, w.directory); err != nil {
+		watcher.Close()
+		return nil, err
+	}
+	return watcher, nil
+}
+
+func addWatches(watcher *fsnotify.Watcher, directory string) error {
+	return filepath.WalkDir(directory, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() {
+			return watcher.Add(path)
+		}
+		return nil
+	})
+}
+
+// watch reloads the files once they've gone unchanged for the debounce duration
+func (w *WatchingFileFetcher) watch(watcher *fsnotify.Watcher) {
+	defer close(w.done)
+	defer watcher.Close()
+
+	debounce := time.Duration(w.cfg.DebounceMS) * time.Millisecond
+	timer := time.NewTimer(debounce)
+	timer.Stop()
+
+	for {
+		select {
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return
+			}
+			if event.Op&fsnotify.Create != 0 {
+				if info, err := os.Stat(event.Name); err == nil && info.IsDir() {
+					if err := addWatches(watcher, event.Name); err != nil {
+						glog.Warningf("Failed to watch %s for changes: %v", event.Name, err)
+					}
+				}
+			}
+			timer.Reset(debounce)
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return
+			}
+			glog.Warningf("Error watching %s for changes: %v", w.directory, err)
+		case <-timer.C:
+			w.reload()
+		case <-w.stop:
+			return
+		}
+	}
+}
+
+// poll reloads the files every poll interval
+func (w *WatchingFileFetcher) poll() {
+	defer close(w.done)
+
+	ticker := time.NewTicker(time.Duration(w.cfg.PollIntervalMS) * time.Millisecond)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			w.reload()
+		case <-w.stop:
+			return
+		}
+	}
+}
+
+// reload reads the files again, serves the new data and publishes what changed. The previous data
+// is kept when the directory can't be read.
+func (w *WatchingFileFetcher) reload() {
+	fileSystem, err := collectStoredData(w.directory, FileSystem{make(map[string]FileSystem), make(map[string]json.RawMessage)}, nil)
+	if err != nil {
+		glog.Errorf("Failed to reload the stored %s data from %s: %v", w.dataType, w.directory, err)
+		w.recordError(metrics.StoredDataErrorUndefined)
+		return
+	}
+
+	previous := w.fetcher.Load().FileSystem
+	w.keepLastGood(w.directory, fileSystem, previous)
+	w.fetcher.Store(&eagerFetcher{fileSystem, nil})
+
+	if !w.publishEvents {
+		return
+	}
+	save, invalidation := diffFileSystems(previous, fileSystem)
+	if len(save.Requests)+len(save.Imps)+len(save.Accounts)+len(save.Responses) > 0 {
+		select {
+		case w.saves <- save:
+		case <-w.stop:
+			return
+		}
+	}
+	if len(invalidation.Requests)+len(invalidation.Imps)+len(invalidation.Accounts)+len(invalidation.Responses) > 0 {
+		select {
+		case w.invalidations <- invalidation:
+		case <-w.stop:
+		}
+	}
+}
+
+// keepLastGood replaces the files which don't hold valid JSON with their previous version, or drops
+// them if there is none.
+func (w *WatchingFileFetcher) keepLastGood(path string, current, previous FileSystem) {
+	for name, directory := range current.Directories {
+		w.keepLastGood(path+"/"+name, directory, previous.Directories[name])
+	}
+
+	for id, data := range current.Files {
+		file := path + "/" + id + ".json"
+		if json.Valid(data) {
+			delete(w.invalidFiles, file)
+			continue
+		}
+
+		if reported, ok := w.invalidFiles[file]; !ok || reported != string(data) {
+			glog.Errorf("Rejected the stored %s data in %s, the file doesn't hold valid JSON", w.dataType, file)
+			w.recordError(metrics.StoredDataErrorInvalid)
+			w.invalidFiles[file] = string(data)
+		}
+
+		// FetchCategories clears the files it has already parsed, which can't be served again
+		if lastGood, ok := previous.Files[id]; ok && lastGood != nil {
+			current.Files[id] = lastGood
+		} else {
+			delete(current.Files, id)
+		}
+	}
+}
+
+func (w *WatchingFileFetcher) recordError(errorType metrics.StoredDataError) {
+	w.metricsEngine.RecordStoredDataError(metrics.StoredDataLabels{
+		DataType: w.dataType,
+		Error:    errorType,
+	})
+}
+
+// diffFileSystems returns the stored data which was added or changed, and the stored data which was removed
+func diffFileSystems(previous, current FileSystem) (save events.Save, invalidation events.Invalidation) {
+	save.Requests, invalidation.Requests = diffFiles(previous.Directories["stored_requests"].Files, current.Directories["stored_requests"].Files)
+	save.Imps, invalidation.Imps = diffFiles(previous.Directories["stored_imps"].Files, current.Directories["stored_imps"].Files)
+	save.Accounts, invalidation.Accounts = diffFiles(previous.Directories["accounts"].Files, current.Directories["accounts"].Files)
+	save.Responses, invalidation.Responses = diffFiles(previous.Directories["stored_responses"].Files, current.Directories["stored_responses"].Files)
+	return
+}
+
+func diffFiles(previous, current map[string]json.RawMessage) (saved map[string]json.RawMessage, removed []string) {
+	for id, data := range current {
+		if previousData, ok := previous[id]; !ok || string(previousData) != string(data) {
+			if saved == nil {
+				saved = make(map[string]json.RawMessage)
+			}
+			saved[id] = data
+		}
+	}
+	for id := range previous {
+		if _, ok := current[id]; !ok {
+			removed = append(removed, id)
+		}
+	}
+	return
+}
+
+var _ stored_requests.AllFetcher = (*WatchingFileFetcher)(nil)
+var _ events.EventProducer = (*WatchingFileFetcher)(nil)
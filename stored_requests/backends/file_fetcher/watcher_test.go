@@ -0,0 +1,156 @@
+package file_fetcher
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/prebid/prebid-server/v3/config"
+	"github.com/prebid/prebid-server/v3/metrics"
+	"github.com/prebid/prebid-server/v3/stored_requests/events"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWatchingFileFetcher(t *testing.T) {
+	testCases := []struct {
+		description string
+		cfg         config.FileWatchConfig
+	}{
+		{
+			description: "Notifications",
+			cfg:         config.FileWatchConfig{Enabled: true, PollIntervalMS: 10, DebounceMS: 10},
+		},
+		{
+			description: "Polling",
+			cfg:         config.FileWatchConfig{Enabled: true, Polling: true, PollIntervalMS: 10},
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.description, func(t *testing.T) {
+			directory := t.TempDir()
+			writeStoredData(t, directory, "stored_requests", "1", `{"id":"1"}`)
+			writeStoredData(t, directory, "accounts", "acct", `{"disabled":false}`)
+
+			storedDataErrors := make(chan metrics.StoredDataLabels, 10)
+			metricsMock := &metrics.MetricsEngineMock{}
+			metricsMock.Mock.On("RecordStoredDataError", mock.Anything).Run(func(args mock.Arguments) {
+				storedDataErrors <- args.Get(0).(metrics.StoredDataLabels)
+			}).Return()
+
+			fetcher, err := NewWatchingFileFetcher(directory, test.cfg, metrics.RequestDataType, metricsMock, true)
+			require.NoError(t, err)
+			defer fetcher.Stop()
+
+			// a changed file is saved
+			writeStoredData(t, directory, "stored_requests", "1", `{"id":"1","v":2}`)
+			save := waitForSave(t, fetcher)
+			assert.Equal(t, map[string]json.RawMessage{"1": json.RawMessage(`{"id":"1","v":2}`)}, save.Requests)
+			assert.Empty(t, save.Accounts)
+			assertStoredRequest(t, fetcher, "1", `{"id":"1","v":2}`)
+
+			// an invalid file is rejected and the last good version is kept
+			writeStoredData(t, directory, "stored_requests", "1", `{"id":`)
+			select {
+			case labels := <-storedDataErrors:
+				assert.Equal(t, metrics.StoredDataLabels{DataType: metrics.RequestDataType, Error: metrics.StoredDataErrorInvalid}, labels)
+			case <-time.After(5 * time.Second):
+				require.Fail(t, "the invalid file wasn't reported")
+			}
+			assertStoredRequest(t, fetcher, "1", `{"id":"1","v":2}`)
+
+			// a removed file is invalidated
+			require.NoError(t, os.Remove(filepath.Join(directory, "accounts", "acct.json")))
+			invalidation := waitForInvalidation(t, fetcher)
+			assert.Equal(t, []string{"acct"}, invalidation.Accounts)
+			assert.Empty(t, invalidation.Requests)
+
+			_, errs := fetcher.FetchAccount(context.Background(), nil, "acct")
+			assert.Len(t, errs, 1)
+			assert.Empty(t, storedDataErrors, "an invalid file should only be reported once")
+		})
+	}
+}
+
+func TestWatchingFileFetcherWithoutEvents(t *testing.T) {
+	directory := t.TempDir()
+	writeStoredData(t, directory, "stored_requests", "1", `{"id":"1"}`)
+
+	cfg := config.FileWatchConfig{Enabled: true, Polling: true, PollIntervalMS: 10}
+	fetcher, err := NewWatchingFileFetcher(directory, cfg, metrics.RequestDataType, &metrics.MetricsEngineMock{}, false)
+	require.NoError(t, err)
+	defer fetcher.Stop()
+
+	writeStoredData(t, directory, "stored_requests", "1", `{"id":"1","v":2}`)
+	writeStoredData(t, directory, "stored_requests", "2", `{"id":"2"}`)
+
+	assert.Eventually(t, func() bool {
+		requests, _, errs := fetcher.FetchRequests(context.Background(), []string{"1", "2"}, nil)
+		return len(errs) == 0 && string(requests["1"]) == `{"id":"1","v":2}`
+	}, 5*time.Second, 10*time.Millisecond, "the changes should be served without an event listener")
+}
+
+func TestDiffFiles(t *testing.T) {
+	previous := map[string]json.RawMessage{
+		"same":    json.RawMessage(`{}`),
+		"changed": json.RawMessage(`{"v":1}`),
+		"removed": json.RawMessage(`{}`),
+	}
+	current := map[string]json.RawMessage{
+		"same":    json.RawMessage(`{}`),
+		"changed": json.RawMessage(`{"v":2}`),
+		"added":   json.RawMessage(`{}`),
+	}
+
+	saved, removed := diffFiles(previous, current)
+	assert.Equal(t, map[string]json.RawMessage{"changed": json.RawMessage(`{"v":2}`), "added": json.RawMessage(`{}`)}, saved)
+	assert.Equal(t, []string{"removed"}, removed)
+
+	saved, removed = diffFiles(current, current)
+	assert.Nil(t, saved)
+	assert.Nil(t, removed)
+}
+
+func writeStoredData(t *testing.T, directory, dataDirectory, id, data string) {
+	t.Helper()
+
+	require.NoError(t, os.MkdirAll(filepath.Join(directory, dataDirectory), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(directory, dataDirectory, id+".json"), []byte(data), 0644))
+}
+
+func assertStoredRequest(t *testing.T, fetcher *WatchingFileFetcher, id, expected string) {
+	t.Helper()
+
+	requests, _, errs := fetcher.FetchRequests(context.Background(), []string{id}, nil)
+	assert.Empty(t, errs)
+	assert.JSONEq(t, expected, string(requests[id]))
+}
+
+func waitForSave(t *testing.T, fetcher *WatchingFileFetcher) events.Save {
+	t.Helper()
+
+	select {
+	case save := <-fetcher.Saves():
+		return save
+	case <-time.After(5 * time.Second):
+		require.Fail(t, "no save was published")
+	}
+	return events.Save{}
+}
+
+func waitForInvalidation(t *testing.T, fetcher *WatchingFileFetcher) events.Invalidation {
+	t.Helper()
+
+	select {
+	case invalidation := <-fetcher.Invalidations():
+		return invalidation
+	case <-time.After(5 * time.Second):
+		require.Fail(t, "no invalidation was published")
+	}
+	return events.Invalidation{}
+}
@@ -0,0 +1,297 @@
+package s3_fetcher
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/prebid/prebid-server/v3/config"
+	"golang.org/x/net/context/ctxhttp"
+)
+
+// ErrNotFound is returned when the object doesn't exist
+var ErrNotFound = errors.New("object not found")
+
+// emptyPayloadHash is the SHA-256 of the empty body of the GET requests
+const emptyPayloadHash = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+
+// Object is the content of an object along with its ETag
+type Object struct {
+	ETag string
+	Data []byte
+}
+
+// Client is a minimal client of the S3 API which reads the stored data objects of a bucket. The
+// requests are signed with AWS Signature Version 4 when credentials are configured.
+type Client struct {
+	httpClient      *http.Client
+	endpoint        *url.URL
+	region          string
+	bucket          string
+	forcePathStyle  bool
+	accessKeyID     string
+	secretAccessKey string
+	gzip            bool
+	now             func() time.Time
+}
+
+func NewClient(httpClient *http.Client, cfg config.S3FetcherConfig) (*Client, error) {
+	endpoint, err := url.Parse(cfg.Endpoint)
+	if err != nil {
+		return nil, err
+	}
+	return &Client{
+		httpClient:      httpClient,
+		endpoint:        endpoint,
+		region:          cfg.Region,
+		bucket:          cfg.Bucket,
+		forcePathStyle:  cfg.ForcePathStyle,
+		accessKeyID:     cfg.AccessKeyID,
+		secretAccessKey: cfg.SecretAccessKey,
+		gzip:            cfg.Gzip,
+		now:             time.Now,
+	}, nil
+}
+
+// ObjectKey returns the key of the object holding the stored data of the ID
+func (c *Client) ObjectKey(prefix, id string) string {
+	if c.gzip {
+		return prefix + id + ".json.gz"
+	}
+	return prefix + id + ".json"
+}
+
+// ObjectID returns the ID of the stored data held by the object, or false if the object
+// doesn't hold stored data.
+func (c *Client) ObjectID(prefix, key string) (string, bool) {
+	suffix := ".json"
+	if c.gzip {
+		suffix = ".json.gz"
+	}
+	id, found := strings.CutPrefix(key, prefix)
+	if !found {
+		return "", false
+	}
+	id, found = strings.CutSuffix(id, suffix)
+	if !found || id == "" {
+		return "", false
+	}
+	return id, true
+}
+
+// GetObject downloads the object
+func (c *Client) GetObject(ctx context.Context, key string) (Object, error) {
+	req, err := http.NewRequest(http.MethodGet, c.objectURL(key, nil).String(), nil)
+	if err != nil {
+		return Object{}, err
+	}
+
+	resp, err := c.do(ctx, req)
+	if err != nil {
+		return Object{}, err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		return Object{}, ErrNotFound
+	default:
+		return Object{}, statusError(resp)
+	}
+
+	var body io.Reader = resp.Body
+	if c.gzip {
+		gzipReader, err := gzip.NewReader(resp.Body)
+		if err != nil {
+			return Object{}, fmt.Errorf("failed to decompress %s: %v", key, err)
+		}
+		defer gzipReader.Close()
+		body = gzipReader
+	}
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return Object{}, fmt.Errorf("failed to read %s: %v", key, err)
+	}
+	return Object{ETag: resp.Header.Get("ETag"), Data: data}, nil
+}
+
+type listBucketResult struct {
+	IsTruncated           bool   `xml:"IsTruncated"`
+	NextContinuationToken string `xml:"NextContinuationToken"`
+	Contents              []struct {
+		Key  string `xml:"Key"`
+		ETag string `xml:"ETag"`
+	} `xml:"Contents"`
+}
+
+// ListObjects returns the ETag of every object under the prefix, by key
+func (c *Client) ListObjects(ctx context.Context, prefix string) (map[string]string, error) {
+	etags := make(map[string]string)
+	continuationToken := ""
+	for {
+		query := url.Values{"list-type": []string{"2"}, "prefix": []string{prefix}}
+		if continuationToken != "" {
+			query.Set("continuation-token", continuationToken)
+		}
+		req, err := http.NewRequest(http.MethodGet, c.objectURL("", query).String(), nil)
+		if err != nil {
+			return nil, err
+		}
+
+		resp, err := c.do(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+		if resp.StatusCode != http.StatusOK {
+			err := statusError(resp)
+			resp.Body.Close()
+			return nil, err
+		}
+		var result listBucketResult
+		err = xml.NewDecoder(resp.Body).Decode(&result)
+		resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse the objects of %s: %v", prefix, err)
+		}
+
+		for _, content := range result.Contents {
+			etags[content.Key] = content.ETag
+		}
+		if !result.IsTruncated || result.NextContinuationToken == "" {
+			return etags, nil
+		}
+		continuationToken = result.NextContinuationToken
+	}
+}
+
+func (c *Client) do(ctx context.Context, req *http.Request) (*http.Response, error) {
+	c.sign(req)
+	return ctxhttp.Do(ctx, c.httpClient, req)
+}
+
+// objectURL returns the URL of the object, or of the bucket when the key is empty. The path and
+// query are escaped the way they're signed.
+func (c *Client) objectURL(key string, query url.Values) *url.URL {
+	objectURL := *c.endpoint
+	path := strings.TrimSuffix(objectURL.Path, "/")
+	if c.forcePathStyle {
+		path += "/" + c.bucket
+	} else {
+		objectURL.Host = c.bucket + "." + objectURL.Host
+	}
+	path += "/" + key
+
+	objectURL.Path = path
+	objectURL.RawPath = escape(path, true)
+	objectURL.RawQuery = canonicalQuery(query)
+	return &objectURL
+}
+
+// sign adds the AWS Signature Version 4 authorization to the request
+func (c *Client) sign(req *http.Request) {
+	if c.accessKeyID == "" {
+		return
+	}
+
+	now := c.now().UTC()
+	amzDate := now.Format("20060102T150405Z")
+	date := now.Format("20060102")
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", emptyPayloadHash)
+
+	headers := map[string]string{"host": req.URL.Host}
+	for name, values := range req.Header {
+		name = strings.ToLower(name)
+		if name == "range" || strings.HasPrefix(name, "x-amz-") {
+			headers[name] = strings.TrimSpace(strings.Join(values, ","))
+		}
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		req.URL.RawQuery,
+		canonicalHeaders.String(),
+		signedHeaders,
+		emptyPayloadHash,
+	}, "\n")
+	canonicalRequestHash := sha256.Sum256([]byte(canonicalRequest))
+
+	scope := date + "/" + c.region + "/s3/aws4_request"
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + hex.EncodeToString(canonicalRequestHash[:])
+
+	signingKey := hmacSHA256([]byte("AWS4"+c.secretAccessKey), date)
+	signingKey = hmacSHA256(signingKey, c.region)
+	signingKey = hmacSHA256(signingKey, "s3")
+	signingKey = hmacSHA256(signingKey, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(signingKey, stringToSign))
+
+	req.Header.Set("Authorization", "AWS4-HMAC-SHA256 Credential="+c.accessKeyID+"/"+scope+", SignedHeaders="+signedHeaders+", Signature="+signature)
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+// canonicalQuery encodes the query sorted by name, as required by the signature
+func canonicalQuery(query url.Values) string {
+	names := make([]string, 0, len(query))
+	for name := range query {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	params := make([]string, 0, len(names))
+	for _, name := range names {
+		for _, value := range query[name] {
+			params = append(params, escape(name, false)+"="+escape(value, false))
+		}
+	}
+	return strings.Join(params, "&")
+}
+
+// escape percent-encodes everything but the unreserved characters of RFC 3986, and the slashes
+// of paths.
+func escape(s string, path bool) string {
+	var escaped bytes.Buffer
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if 'A' <= c && c <= 'Z' || 'a' <= c && c <= 'z' || '0' <= c && c <= '9' || c == '-' || c == '_' || c == '.' || c == '~' || path && c == '/' {
+			escaped.WriteByte(c)
+		} else {
+			fmt.Fprintf(&escaped, "%%%02X", c)
+		}
+	}
+	return escaped.String()
+}
+
+func statusError(resp *http.Response) error {
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+	return fmt.Errorf("unexpected status %d from %s: %s", resp.StatusCode, resp.Request.URL.Redacted(), body)
+}
@@ -0,0 +1,116 @@
+package s3_fetcher
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/prebid/prebid-server/v3/config"
+	"github.com/prebid/prebid-server/v3/stored_requests"
+	jsonpatch "gopkg.in/evanphx/json-patch.v5"
+)
+
+// NewFetcher returns a Fetcher which reads the stored data from the objects of an S3 compatible bucket.
+//
+// Each object holds the data of a single ID, and is named "{prefix}{id}.json", or "{prefix}{id}.json.gz"
+// when the objects are gzip compressed. For example, with the "stored_requests/" prefix, the request with
+// ID == "23" is read from the "stored_requests/23.json" object.
+//
+// The objects are downloaded on every fetch. They're meant to be kept by the in-memory cache, which the
+// S3 event producer updates when the objects change.
+func NewFetcher(client *Client, prefixes config.S3Prefixes) *S3Fetcher {
+	return &S3Fetcher{
+		client:   client,
+		prefixes: prefixes,
+	}
+}
+
+type S3Fetcher struct {
+	client   *Client
+	prefixes config.S3Prefixes
+}
+
+func (fetcher *S3Fetcher) FetchRequests(ctx context.Context, requestIDs []string, impIDs []string) (map[string]json.RawMessage, map[string]json.RawMessage, []error) {
+	requestData, errs := fetcher.fetchObjects(ctx, fetcher.prefixes.Requests, "Request", requestIDs, nil)
+	impData, errs := fetcher.fetchObjects(ctx, fetcher.prefixes.Imps, "Imp", impIDs, errs)
+	return requestData, impData, errs
+}
+
+func (fetcher *S3Fetcher) FetchResponses(ctx context.Context, ids []string) (map[string]json.RawMessage, []error) {
+	return fetcher.fetchObjects(ctx, fetcher.prefixes.Responses, "Response", ids, nil)
+}
+
+// FetchAccount fetches the host account configuration for a publisher
+func (fetcher *S3Fetcher) FetchAccount(ctx context.Context, accountDefaultsJSON json.RawMessage, accountID string) (json.RawMessage, []error) {
+	if len(accountID) == 0 {
+		return nil, []error{fmt.Errorf("Cannot look up an empty accountID")}
+	}
+	accountData, errs := fetcher.fetchObjects(ctx, fetcher.prefixes.Accounts, "Account", []string{accountID}, nil)
+	if len(errs) > 0 {
+		return nil, errs
+	}
+
+	accountJSON := accountData[accountID]
+	if accountDefaultsJSON == nil {
+		return accountJSON, nil
+	}
+	completeJSON, err := jsonpatch.MergePatch(accountDefaultsJSON, accountJSON)
+	if err != nil {
+		return nil, []error{err}
+	}
+	return completeJSON, nil
+}
+
+func (fetcher *S3Fetcher) FetchCategories(ctx context.Context, primaryAdServer, publisherId, iabCategory string) (string, error) {
+	return "", errors.New("Categories are not supported by the S3 fetcher")
+}
+
+// fetchObjects fetches the objects of the IDs in parallel
+func (fetcher *S3Fetcher) fetchObjects(ctx context.Context, prefix, dataType string, ids []string, errs []error) (map[string]json.RawMessage, []error) {
+	if len(ids) == 0 {
+		return nil, errs
+	}
+
+	type result struct {
+		id   string
+		data json.RawMessage
+		err  error
+	}
+	results := make(chan result, len(ids))
+	for _, id := range ids {
+		go func(id string) {
+			data, err := fetcher.fetchObject(ctx, prefix, dataType, id)
+			results <- result{id: id, data: data, err: err}
+		}(id)
+	}
+
+	data := make(map[string]json.RawMessage, len(ids))
+	for range ids {
+		result := <-results
+		if result.err != nil {
+			errs = append(errs, result.err)
+			continue
+		}
+		data[result.id] = result.data
+	}
+	return data, errs
+}
+
+// fetchObject fetches the object of the ID
+func (fetcher *S3Fetcher) fetchObject(ctx context.Context, prefix, dataType, id string) (json.RawMessage, error) {
+	key := fetcher.client.ObjectKey(prefix, id)
+
+	object, err := fetcher.client.GetObject(ctx, key)
+	if err == ErrNotFound {
+		return nil, stored_requests.NotFoundError{ID: id, DataType: dataType}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("Failed to fetch the stored %s %s: %v", dataType, id, err)
+	}
+
+	if !json.Valid(object.Data) {
+		return nil, fmt.Errorf("The stored %s %s in %s isn't valid JSON", dataType, id, key)
+	}
+	return object.Data, nil
+}
@@ -0,0 +1,110 @@
+package s3_fetcher
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/prebid/prebid-server/v3/config"
+	"github.com/prebid/prebid-server/v3/stored_requests"
+	"github.com/prebid/prebid-server/v3/stored_requests/backends/s3_fetcher/s3test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testPrefixes = config.S3Prefixes{
+	Requests:  "stored_requests/",
+	Imps:      "stored_imps/",
+	Responses: "stored_responses/",
+	Accounts:  "accounts/",
+}
+
+func TestFetchRequests(t *testing.T) {
+	server := s3test.NewServer("bucket", 1000)
+	defer server.Close()
+	server.PutObject("stored_requests/req-1.json", []byte(`{"id":"req-1"}`))
+	server.PutObject("stored_imps/imp-1.json", []byte(`{"id":"imp-1"}`))
+	server.PutObject("stored_imps/broken.json", []byte(`{"id":`))
+
+	fetcher := NewFetcher(newTestClient(t, server, false), testPrefixes)
+
+	requests, imps, errs := fetcher.FetchRequests(context.Background(), []string{"req-1", "req-2"}, []string{"imp-1", "broken"})
+	assert.Equal(t, map[string]json.RawMessage{"req-1": json.RawMessage(`{"id":"req-1"}`)}, requests)
+	assert.Equal(t, map[string]json.RawMessage{"imp-1": json.RawMessage(`{"id":"imp-1"}`)}, imps)
+	require.Len(t, errs, 2)
+	assert.Equal(t, stored_requests.NotFoundError{ID: "req-2", DataType: "Request"}, errs[0])
+	assert.EqualError(t, errs[1], "The stored Imp broken in stored_imps/broken.json isn't valid JSON")
+
+	requests, _, errs = fetcher.FetchRequests(context.Background(), nil, nil)
+	assert.Empty(t, requests)
+	assert.Empty(t, errs)
+}
+
+func TestFetchChangedObjects(t *testing.T) {
+	server := s3test.NewServer("bucket", 1000)
+	defer server.Close()
+	server.PutObject("stored_responses/resp-1.json", []byte(`{"v":1}`))
+
+	fetcher := NewFetcher(newTestClient(t, server, false), testPrefixes)
+
+	responses, errs := fetcher.FetchResponses(context.Background(), []string{"resp-1"})
+	assert.Empty(t, errs)
+	assert.Equal(t, map[string]json.RawMessage{"resp-1": json.RawMessage(`{"v":1}`)}, responses)
+
+	server.PutObject("stored_responses/resp-1.json", []byte(`{"v":2}`))
+	responses, errs = fetcher.FetchResponses(context.Background(), []string{"resp-1"})
+	assert.Empty(t, errs)
+	assert.Equal(t, map[string]json.RawMessage{"resp-1": json.RawMessage(`{"v":2}`)}, responses)
+
+	server.DeleteObject("stored_responses/resp-1.json")
+	_, errs = fetcher.FetchResponses(context.Background(), []string{"resp-1"})
+	assert.Equal(t, []error{stored_requests.NotFoundError{ID: "resp-1", DataType: "Response"}}, errs)
+}
+
+func TestFetchAccount(t *testing.T) {
+	server := s3test.NewServer("bucket", 1000)
+	defer server.Close()
+	server.PutObject("accounts/acct.json", []byte(`{"disabled":true}`))
+
+	fetcher := NewFetcher(newTestClient(t, server, false), testPrefixes)
+
+	testCases := []struct {
+		description     string
+		accountID       string
+		defaults        json.RawMessage
+		expectedAccount json.RawMessage
+		expectedErrs    []error
+	}{
+		{
+			description:     "Account",
+			accountID:       "acct",
+			expectedAccount: json.RawMessage(`{"disabled":true}`),
+		},
+		{
+			description:     "Account Merged With Defaults",
+			accountID:       "acct",
+			defaults:        json.RawMessage(`{"disabled":false,"id":"default"}`),
+			expectedAccount: json.RawMessage(`{"disabled":true,"id":"default"}`),
+		},
+		{
+			description:  "Unknown Account",
+			accountID:    "unknown",
+			expectedErrs: []error{stored_requests.NotFoundError{ID: "unknown", DataType: "Account"}},
+		},
+	}
+
+	for _, test := range testCases {
+		account, errs := fetcher.FetchAccount(context.Background(), test.defaults, test.accountID)
+		assert.Equal(t, test.expectedErrs, errs, test.description)
+		if test.expectedAccount != nil {
+			assert.JSONEq(t, string(test.expectedAccount), string(account), test.description)
+		}
+	}
+}
+
+func TestFetchCategories(t *testing.T) {
+	fetcher := NewFetcher(&Client{}, testPrefixes)
+
+	_, err := fetcher.FetchCategories(context.Background(), "freewheel", "", "IAB1-1")
+	assert.Error(t, err)
+}
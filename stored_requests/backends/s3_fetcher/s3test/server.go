@@ -0,0 +1,134 @@
+// Package s3test provides a fake S3 compatible service for the tests of the S3 backends.
+package s3test
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"encoding/xml"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Server serves the objects of a single bucket with path style URLs. It supports the
+// GetObject and ListObjectsV2 requests.
+type Server struct {
+	*httptest.Server
+
+	bucket   string
+	pageSize int
+
+	mutex          sync.Mutex
+	objects        map[string][]byte
+	gets           int
+	authorizations []string
+}
+
+// NewServer starts a fake service for the bucket, which lists pageSize objects per page
+func NewServer(bucket string, pageSize int) *Server {
+	s := &Server{
+		bucket:   bucket,
+		pageSize: pageSize,
+		objects:  make(map[string][]byte),
+	}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.handle))
+	return s
+}
+
+func (s *Server) PutObject(key string, data []byte) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.objects[key] = data
+}
+
+func (s *Server) DeleteObject(key string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	delete(s.objects, key)
+}
+
+// Gets returns how many objects were requested
+func (s *Server) Gets() int {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.gets
+}
+
+// Authorizations returns the Authorization header of every request
+func (s *Server) Authorizations() []string {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return append([]string(nil), s.authorizations...)
+}
+
+type listBucketResult struct {
+	XMLName               xml.Name `xml:"ListBucketResult"`
+	IsTruncated           bool     `xml:"IsTruncated"`
+	NextContinuationToken string   `xml:"NextContinuationToken,omitempty"`
+	Contents              []object `xml:"Contents"`
+}
+
+type object struct {
+	Key  string `xml:"Key"`
+	ETag string `xml:"ETag"`
+}
+
+func (s *Server) handle(w http.ResponseWriter, r *http.Request) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.authorizations = append(s.authorizations, r.Header.Get("Authorization"))
+
+	key, found := strings.CutPrefix(r.URL.Path, "/"+s.bucket+"/")
+	if r.Method != http.MethodGet || !found {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	if key == "" {
+		s.list(w, r)
+		return
+	}
+
+	s.gets++
+	data, ok := s.objects[key]
+	if !ok {
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte("<Error><Code>NoSuchKey</Code></Error>"))
+		return
+	}
+	w.Header().Set("ETag", etag(data))
+	w.Write(data)
+}
+
+func (s *Server) list(w http.ResponseWriter, r *http.Request) {
+	prefix := r.URL.Query().Get("prefix")
+	after := r.URL.Query().Get("continuation-token")
+
+	keys := make([]string, 0, len(s.objects))
+	for key := range s.objects {
+		if strings.HasPrefix(key, prefix) && key > after {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	var result listBucketResult
+	if len(keys) > s.pageSize {
+		keys = keys[:s.pageSize]
+		result.IsTruncated = true
+		result.NextContinuationToken = keys[len(keys)-1]
+	}
+	for _, key := range keys {
+		result.Contents = append(result.Contents, object{Key: key, ETag: etag(s.objects[key])})
+	}
+
+	data, _ := xml.Marshal(result)
+	w.Header().Set("Content-Type", "application/xml")
+	w.Write(data)
+}
+
+func etag(data []byte) string {
+	sum := md5.Sum(data)
+	return `"` + hex.EncodeToString(sum[:]) + `"`
+}
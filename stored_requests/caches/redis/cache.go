@@ -0,0 +1,150 @@
+// Package redis is a cache of the stored data in a server speaking the Redis protocol, which the PBS
+// instances share behind their in-memory caches, so that a new instance doesn't fetch everything from the
+// backends.
+package redis
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/golang/glog"
+	"github.com/prebid/prebid-server/v3/stored_requests"
+)
+
+// maxKeysPerCommand is the maximum number of keys of an MGET or a DEL. Larger batches are split into
+// several commands of a single pipeline.
+const maxKeysPerCommand = 100
+
+// NewCache returns a cache of the entries of a data type, stored under keyPrefix. The entries expire after
+// ttl, or never if it's 0.
+//
+// The cache is bypassed for retryInterval when the server fails, so that the backends are used directly
+// rather than waiting on every request for a server which is down. The changes made in the meantime are
+// lost, so the entries may be stale until they expire.
+func NewCache(client *Client, keyPrefix string, ttl time.Duration, retryInterval time.Duration, dataType string) stored_requests.CacheJSON {
+	glog.Infof("Using a Stored %s Redis cache. Key prefix: %s. TTL: %s.", dataType, keyPrefix, ttl)
+	return &cache{
+		client:        client,
+		keyPrefix:     keyPrefix,
+		ttl:           ttl,
+		retryInterval: retryInterval,
+		dataType:      dataType,
+		now:           time.Now,
+	}
+}
+
+type cache struct {
+	client        *Client
+	keyPrefix     string
+	ttl           time.Duration
+	retryInterval time.Duration
+	dataType      string
+	now           func() time.Time
+
+	mutex     sync.Mutex
+	downUntil time.Time
+}
+
+func (c *cache) Get(ctx context.Context, ids []string) (data map[string]json.RawMessage) {
+	data = make(map[string]json.RawMessage, len(ids))
+	if len(ids) == 0 || !c.available() {
+		return
+	}
+
+	commands := c.commands("MGET", ids)
+	replies, err := c.client.Do(ctx, commands...)
+	if err != nil {
+		c.failed(ctx, "get", err)
+		return
+	}
+
+	for i, reply := range replies {
+		values, ok := reply.([]interface{})
+		if !ok {
+			glog.Warningf("The Stored %s Redis cache replied to MGET with %v", c.dataType, reply)
+			continue
+		}
+		batch := ids[i*maxKeysPerCommand:]
+		for j, value := range values {
+			if value, ok := value.([]byte); ok && j < len(batch) {
+				data[batch[j]] = value
+			}
+		}
+	}
+	return
+}
+
+func (c *cache) Save(ctx context.Context, data map[string]json.RawMessage) {
+	if len(data) == 0 || !c.available() {
+		return
+	}
+
+	commands := make([][]string, 0, len(data))
+	for id, value := range data {
+		command := []string{"SET", c.keyPrefix + id, string(value)}
+		if c.ttl > 0 {
+			command = append(command, "PX", strconv.FormatInt(c.ttl.Milliseconds(), 10))
+		}
+		commands = append(commands, command)
+	}
+	c.do(ctx, "save", commands)
+}
+
+func (c *cache) Invalidate(ctx context.Context, ids []string) {
+	if len(ids) == 0 || !c.available() {
+		return
+	}
+	c.do(ctx, "invalidate", c.commands("DEL", ids))
+}
+
+// commands returns the commands of the keys of ids, split in batches of maxKeysPerCommand
+func (c *cache) commands(name string, ids []string) [][]string {
+	commands := make([][]string, 0, len(ids)/maxKeysPerCommand+1)
+	for start := 0; start < len(ids); start += maxKeysPerCommand {
+		end := start + maxKeysPerCommand
+		if end > len(ids) {
+			end = len(ids)
+		}
+		command := make([]string, 0, end-start+1)
+		command = append(command, name)
+		for _, id := range ids[start:end] {
+			command = append(command, c.keyPrefix+id)
+		}
+		commands = append(commands, command)
+	}
+	return commands
+}
+
+func (c *cache) do(ctx context.Context, operation string, commands [][]string) {
+	replies, err := c.client.Do(ctx, commands...)
+	if err != nil {
+		c.failed(ctx, operation, err)
+		return
+	}
+	for _, reply := range replies {
+		if replyErr, ok := reply.(Error); ok {
+			glog.Warningf("Failed to %s the Stored %s Redis cache entries: %v", operation, c.dataType, replyErr)
+			return
+		}
+	}
+}
+
+func (c *cache) available() bool {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return !c.now().Before(c.downUntil)
+}
+
+func (c *cache) failed(ctx context.Context, operation string, err error) {
+	// the requests which ran out of time don't mean that the server is down
+	if ctx.Err() != nil {
+		return
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.downUntil = c.now().Add(c.retryInterval)
+	glog.Warningf("Failed to %s the Stored %s Redis cache entries, it's bypassed for %s: %v", operation, c.dataType, c.retryInterval, err)
+}
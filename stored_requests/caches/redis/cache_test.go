@@ -0,0 +1,137 @@
+package redis
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/prebid/prebid-server/v3/config"
+	"github.com/prebid/prebid-server/v3/stored_requests/caches/redis/redistest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestCache(t *testing.T, password string) (*cache, *redistest.Server) {
+	t.Helper()
+	server, err := redistest.NewServer(password)
+	require.NoError(t, err)
+	t.Cleanup(server.Close)
+
+	client := NewClient(config.RedisCache{
+		Address:   server.Addr(),
+		Password:  password,
+		TimeoutMS: 1000,
+		PoolSize:  2,
+	})
+	t.Cleanup(client.Close)
+	return NewCache(client, "pbs:imps:", time.Hour, time.Minute, "Imps").(*cache), server
+}
+
+func TestCacheSaveAndGet(t *testing.T) {
+	c, server := newTestCache(t, "secret")
+	ctx := context.Background()
+
+	c.Save(ctx, map[string]json.RawMessage{"1": json.RawMessage(`{"banner":{}}`)})
+	value, ok := server.Value("pbs:imps:1")
+	assert.True(t, ok, "the entry should be stored under the key prefix")
+	assert.Equal(t, `{"banner":{}}`, value)
+	assert.Equal(t, time.Hour, server.TTL("pbs:imps:1"), "the entry should expire after the TTL")
+
+	data := c.Get(ctx, []string{"1", "2"})
+	assert.Equal(t, map[string]json.RawMessage{"1": json.RawMessage(`{"banner":{}}`)}, data)
+
+	c.Invalidate(ctx, []string{"1"})
+	_, ok = server.Value("pbs:imps:1")
+	assert.False(t, ok, "the entry should be deleted")
+	assert.Empty(t, c.Get(ctx, []string{"1"}))
+}
+
+func TestCacheWithoutTTL(t *testing.T) {
+	c, server := newTestCache(t, "")
+	c.ttl = 0
+
+	c.Save(context.Background(), map[string]json.RawMessage{"1": json.RawMessage(`{}`)})
+	assert.Equal(t, time.Duration(0), server.TTL("pbs:imps:1"), "the entry shouldn't expire")
+}
+
+func TestCacheGetBatches(t *testing.T) {
+	c, server := newTestCache(t, "")
+
+	ids := make([]string, 2*maxKeysPerCommand+10)
+	for i := range ids {
+		ids[i] = strconv.Itoa(i)
+		if i%2 == 0 {
+			server.Set("pbs:imps:"+ids[i], `{"id":"`+ids[i]+`"}`)
+		}
+	}
+
+	data := c.Get(context.Background(), ids)
+	assert.Len(t, data, len(ids)/2)
+	for i := 0; i < len(ids); i += 2 {
+		assert.JSONEq(t, `{"id":"`+ids[i]+`"}`, string(data[ids[i]]), ids[i])
+	}
+
+	commands := server.Commands()
+	require.Len(t, commands, 3, "the IDs should be fetched in batches of maxKeysPerCommand")
+	assert.Len(t, commands[0], maxKeysPerCommand+1)
+	assert.Len(t, commands[1], maxKeysPerCommand+1)
+	assert.Equal(t, []string{"MGET", "pbs:imps:200", "pbs:imps:201"}, commands[2][:3])
+}
+
+func TestCacheFailureIsolation(t *testing.T) {
+	c, server := newTestCache(t, "")
+	now := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	c.now = func() time.Time { return now }
+	ctx := context.Background()
+	server.Set("pbs:imps:1", `{}`)
+
+	server.SetFailing(true)
+	assert.Empty(t, c.Get(ctx, []string{"1"}), "nothing should be found while the server fails")
+	server.SetFailing(false)
+
+	commands := len(server.Commands())
+	assert.Empty(t, c.Get(ctx, []string{"1"}), "the cache should be bypassed during the retry interval")
+	c.Save(ctx, map[string]json.RawMessage{"2": json.RawMessage(`{}`)})
+	assert.Len(t, server.Commands(), commands, "no commands should be sent during the retry interval")
+
+	now = now.Add(time.Minute)
+	assert.Len(t, c.Get(ctx, []string{"1"}), 1, "the cache should be used again after the retry interval")
+}
+
+func TestCacheCanceledContext(t *testing.T) {
+	c, server := newTestCache(t, "")
+	server.Set("pbs:imps:1", `{}`)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	assert.Empty(t, c.Get(ctx, []string{"1"}))
+	assert.True(t, c.available(), "the requests which ran out of time shouldn't bypass the cache")
+	assert.Len(t, c.Get(context.Background(), []string{"1"}), 1)
+}
+
+func TestClientWrongPassword(t *testing.T) {
+	server, err := redistest.NewServer("secret")
+	require.NoError(t, err)
+	defer server.Close()
+
+	client := NewClient(config.RedisCache{Address: server.Addr(), Password: "wrong", TimeoutMS: 1000, PoolSize: 1})
+	defer client.Close()
+
+	_, err = client.Do(context.Background(), []string{"PING"})
+	assert.EqualError(t, err, "failed to set up the connection: WRONGPASS invalid password")
+}
+
+func TestClientErrorReply(t *testing.T) {
+	server, err := redistest.NewServer("")
+	require.NoError(t, err)
+	defer server.Close()
+
+	client := NewClient(config.RedisCache{Address: server.Addr(), TimeoutMS: 1000, PoolSize: 1})
+	defer client.Close()
+
+	replies, err := client.Do(context.Background(), []string{"UNKNOWN"}, []string{"PING"}, []string{"DEL", "missing"})
+	require.NoError(t, err, "the error replies shouldn't fail the pipeline")
+	assert.Equal(t, []interface{}{Error("ERR unknown command 'UNKNOWN'"), "PONG", int64(0)}, replies)
+}
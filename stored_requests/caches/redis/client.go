@@ -0,0 +1,237 @@
+package redis
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"time"
+
+	"github.com/prebid/prebid-server/v3/config"
+)
+
+// Error is an error reply of the server. The connection is still usable after it.
+type Error string
+
+func (e Error) Error() string {
+	return string(e)
+}
+
+// Client is a minimal client of the Redis protocol (RESP2). It keeps a pool of idle connections, and runs
+// the commands in pipelines: every command of a call is written before the replies are read.
+type Client struct {
+	address   string
+	password  string
+	db        int
+	tlsConfig *tls.Config
+	timeout   time.Duration
+
+	idle chan *conn
+}
+
+type conn struct {
+	net.Conn
+	reader *bufio.Reader
+	writer *bufio.Writer
+}
+
+// NewClient returns a client of the server configured by cfg. The connections are opened when they're needed.
+func NewClient(cfg config.RedisCache) *Client {
+	client := &Client{
+		address:  cfg.Address,
+		password: cfg.Password,
+		db:       cfg.DB,
+		timeout:  time.Duration(cfg.TimeoutMS) * time.Millisecond,
+		idle:     make(chan *conn, cfg.PoolSize),
+	}
+	if cfg.TLS {
+		host, _, _ := net.SplitHostPort(cfg.Address)
+		client.tlsConfig = &tls.Config{ServerName: host}
+	}
+	return client
+}
+
+// Do runs the commands in a pipeline, and returns their replies: nil, a string, an int64, a []byte, an
+// Error or a []interface{} of replies. The error is only returned when the connection failed.
+func (c *Client) Do(ctx context.Context, commands ...[]string) ([]interface{}, error) {
+	cn, err := c.get(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	replies, err := cn.do(ctx, c.timeout, commands)
+	if err != nil {
+		cn.Close()
+		return nil, err
+	}
+	c.put(cn)
+	return replies, nil
+}
+
+// Close closes the idle connections
+func (c *Client) Close() {
+	for {
+		select {
+		case cn := <-c.idle:
+			cn.Close()
+		default:
+			return
+		}
+	}
+}
+
+func (c *Client) get(ctx context.Context) (*conn, error) {
+	select {
+	case cn := <-c.idle:
+		return cn, nil
+	default:
+	}
+
+	dialer := &net.Dialer{Timeout: c.timeout}
+	var netConn net.Conn
+	var err error
+	if c.tlsConfig != nil {
+		netConn, err = (&tls.Dialer{NetDialer: dialer, Config: c.tlsConfig}).DialContext(ctx, "tcp", c.address)
+	} else {
+		netConn, err = dialer.DialContext(ctx, "tcp", c.address)
+	}
+	if err != nil {
+		return nil, err
+	}
+	cn := &conn{Conn: netConn, reader: bufio.NewReader(netConn), writer: bufio.NewWriter(netConn)}
+
+	var setup [][]string
+	if c.password != "" {
+		setup = append(setup, []string{"AUTH", c.password})
+	}
+	if c.db != 0 {
+		setup = append(setup, []string{"SELECT", strconv.Itoa(c.db)})
+	}
+	if len(setup) == 0 {
+		return cn, nil
+	}
+	replies, err := cn.do(ctx, c.timeout, setup)
+	if err == nil {
+		for _, reply := range replies {
+			if replyErr, ok := reply.(Error); ok {
+				err = replyErr
+			}
+		}
+	}
+	if err != nil {
+		cn.Close()
+		return nil, fmt.Errorf("failed to set up the connection: %v", err)
+	}
+	return cn, nil
+}
+
+func (c *Client) put(cn *conn) {
+	select {
+	case c.idle <- cn:
+	default:
+		cn.Close()
+	}
+}
+
+func (cn *conn) do(ctx context.Context, timeout time.Duration, commands [][]string) ([]interface{}, error) {
+	deadline := time.Now().Add(timeout)
+	if ctxDeadline, ok := ctx.Deadline(); ok && ctxDeadline.Before(deadline) {
+		deadline = ctxDeadline
+	}
+	if err := cn.SetDeadline(deadline); err != nil {
+		return nil, err
+	}
+
+	for _, command := range commands {
+		writeCommand(cn.writer, command)
+	}
+	if err := cn.writer.Flush(); err != nil {
+		return nil, err
+	}
+
+	replies := make([]interface{}, len(commands))
+	for i := range commands {
+		reply, err := readReply(cn.reader)
+		if err != nil {
+			return nil, err
+		}
+		replies[i] = reply
+	}
+	return replies, nil
+}
+
+func writeCommand(w *bufio.Writer, command []string) {
+	w.WriteByte('*')
+	w.WriteString(strconv.Itoa(len(command)))
+	w.WriteString("\r\n")
+	for _, arg := range command {
+		w.WriteByte('$')
+		w.WriteString(strconv.Itoa(len(arg)))
+		w.WriteString("\r\n")
+		w.WriteString(arg)
+		w.WriteString("\r\n")
+	}
+}
+
+func readReply(r *bufio.Reader) (interface{}, error) {
+	line, err := readLine(r)
+	if err != nil {
+		return nil, err
+	}
+	if len(line) == 0 {
+		return nil, errors.New("empty reply")
+	}
+
+	switch line[0] {
+	case '+':
+		return string(line[1:]), nil
+	case '-':
+		return Error(line[1:]), nil
+	case ':':
+		return strconv.ParseInt(string(line[1:]), 10, 64)
+	case '$':
+		size, err := strconv.Atoi(string(line[1:]))
+		if err != nil {
+			return nil, err
+		}
+		if size < 0 {
+			return nil, nil
+		}
+		value := make([]byte, size+2)
+		if _, err := io.ReadFull(r, value); err != nil {
+			return nil, err
+		}
+		return value[:size], nil
+	case '*':
+		size, err := strconv.Atoi(string(line[1:]))
+		if err != nil {
+			return nil, err
+		}
+		if size < 0 {
+			return nil, nil
+		}
+		values := make([]interface{}, size)
+		for i := range values {
+			if values[i], err = readReply(r); err != nil {
+				return nil, err
+			}
+		}
+		return values, nil
+	}
+	return nil, fmt.Errorf("unexpected reply %q", line)
+}
+
+func readLine(r *bufio.Reader) ([]byte, error) {
+	line, err := r.ReadBytes('\n')
+	if err != nil {
+		return nil, err
+	}
+	if len(line) < 2 || line[len(line)-2] != '\r' {
+		return nil, fmt.Errorf("malformed reply %q", line)
+	}
+	return line[:len(line)-2], nil
+}
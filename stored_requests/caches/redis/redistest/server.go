@@ -0,0 +1,291 @@
+// Package redistest provides an in-process server speaking the Redis protocol for the tests of the Redis caches.
+package redistest
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Server stores the keys of a single database in memory. It supports the PING, AUTH, SELECT, GET, MGET,
+// SET (with EX or PX), DEL and FLUSHALL commands.
+type Server struct {
+	listener net.Listener
+	password string
+
+	mutex    sync.Mutex
+	values   map[string]string
+	ttls     map[string]time.Duration
+	commands [][]string
+	failing  bool
+	conns    map[net.Conn]struct{}
+	wg       sync.WaitGroup
+}
+
+// NewServer starts a server on a random local port. The clients must AUTH with the password if it isn't empty.
+func NewServer(password string) (*Server, error) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, err
+	}
+	s := &Server{
+		listener: listener,
+		password: password,
+		values:   make(map[string]string),
+		ttls:     make(map[string]time.Duration),
+		conns:    make(map[net.Conn]struct{}),
+	}
+	s.wg.Add(1)
+	go s.serve()
+	return s, nil
+}
+
+// Addr returns the host:port of the server
+func (s *Server) Addr() string {
+	return s.listener.Addr().String()
+}
+
+// Close stops the server and closes its connections
+func (s *Server) Close() {
+	s.listener.Close()
+	s.mutex.Lock()
+	for c := range s.conns {
+		c.Close()
+	}
+	s.mutex.Unlock()
+	s.wg.Wait()
+}
+
+// SetFailing makes the server close the connections on their next command while failing is true
+func (s *Server) SetFailing(failing bool) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.failing = failing
+}
+
+// Value returns the value of a key, and whether it exists
+func (s *Server) Value(key string) (string, bool) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	value, ok := s.values[key]
+	return value, ok
+}
+
+// TTL returns the time to live set on a key, or 0 if it doesn't expire
+func (s *Server) TTL(key string) time.Duration {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.ttls[key]
+}
+
+// Set stores the value of a key without a TTL
+func (s *Server) Set(key string, value string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.values[key] = value
+	delete(s.ttls, key)
+}
+
+// Commands returns the commands run since the server started, except AUTH and SELECT
+func (s *Server) Commands() [][]string {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return append([][]string(nil), s.commands...)
+}
+
+func (s *Server) serve() {
+	defer s.wg.Done()
+	for {
+		c, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		s.mutex.Lock()
+		s.conns[c] = struct{}{}
+		s.mutex.Unlock()
+		s.wg.Add(1)
+		go s.handle(c)
+	}
+}
+
+func (s *Server) handle(c net.Conn) {
+	defer s.wg.Done()
+	defer func() {
+		s.mutex.Lock()
+		delete(s.conns, c)
+		s.mutex.Unlock()
+		c.Close()
+	}()
+
+	reader := bufio.NewReader(c)
+	writer := bufio.NewWriter(c)
+	authenticated := s.password == ""
+	for {
+		command, err := readCommand(reader)
+		if err != nil {
+			return
+		}
+		if len(command) == 0 {
+			writer.WriteString("-ERR empty command\r\n")
+		} else if reply, ok := s.run(command, &authenticated); ok {
+			writer.WriteString(reply)
+		} else {
+			return
+		}
+		// replies are flushed once the pipelined commands which were already received are run
+		if reader.Buffered() == 0 {
+			if err := writer.Flush(); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// run returns the reply of the command, or false if the connection must be closed
+func (s *Server) run(command []string, authenticated *bool) (string, bool) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.failing {
+		return "", false
+	}
+
+	name := strings.ToUpper(command[0])
+	args := command[1:]
+	switch name {
+	case "AUTH":
+		if len(args) != 1 || args[0] != s.password {
+			return "-WRONGPASS invalid password\r\n", true
+		}
+		*authenticated = true
+		return "+OK\r\n", true
+	case "SELECT":
+		return "+OK\r\n", true
+	}
+	if !*authenticated {
+		return "-NOAUTH Authentication required.\r\n", true
+	}
+
+	s.commands = append(s.commands, command)
+	switch name {
+	case "PING":
+		return "+PONG\r\n", true
+	case "GET":
+		if len(args) != 1 {
+			return wrongArgs(name), true
+		}
+		return s.bulk(args[0]), true
+	case "MGET":
+		if len(args) == 0 {
+			return wrongArgs(name), true
+		}
+		var reply strings.Builder
+		fmt.Fprintf(&reply, "*%d\r\n", len(args))
+		for _, key := range args {
+			reply.WriteString(s.bulk(key))
+		}
+		return reply.String(), true
+	case "SET":
+		if len(args) != 2 && len(args) != 4 {
+			return wrongArgs(name), true
+		}
+		var ttl time.Duration
+		if len(args) == 4 {
+			amount, err := strconv.ParseInt(args[3], 10, 64)
+			if err != nil || amount <= 0 {
+				return "-ERR invalid expire time in 'set' command\r\n", true
+			}
+			switch strings.ToUpper(args[2]) {
+			case "EX":
+				ttl = time.Duration(amount) * time.Second
+			case "PX":
+				ttl = time.Duration(amount) * time.Millisecond
+			default:
+				return "-ERR syntax error\r\n", true
+			}
+		}
+		s.values[args[0]] = args[1]
+		if ttl > 0 {
+			s.ttls[args[0]] = ttl
+		} else {
+			delete(s.ttls, args[0])
+		}
+		return "+OK\r\n", true
+	case "DEL":
+		if len(args) == 0 {
+			return wrongArgs(name), true
+		}
+		deleted := 0
+		for _, key := range args {
+			if _, ok := s.values[key]; ok {
+				deleted++
+			}
+			delete(s.values, key)
+			delete(s.ttls, key)
+		}
+		return fmt.Sprintf(":%d\r\n", deleted), true
+	case "FLUSHALL":
+		s.values = make(map[string]string)
+		s.ttls = make(map[string]time.Duration)
+		return "+OK\r\n", true
+	}
+	return fmt.Sprintf("-ERR unknown command '%s'\r\n", command[0]), true
+}
+
+func (s *Server) bulk(key string) string {
+	value, ok := s.values[key]
+	if !ok {
+		return "$-1\r\n"
+	}
+	return fmt.Sprintf("$%d\r\n%s\r\n", len(value), value)
+}
+
+func wrongArgs(name string) string {
+	return fmt.Sprintf("-ERR wrong number of arguments for '%s' command\r\n", strings.ToLower(name))
+}
+
+func readCommand(r *bufio.Reader) ([]string, error) {
+	line, err := readLine(r)
+	if err != nil {
+		return nil, err
+	}
+	if len(line) == 0 || line[0] != '*' {
+		return nil, fmt.Errorf("expected an array, got %q", line)
+	}
+	size, err := strconv.Atoi(line[1:])
+	if err != nil {
+		return nil, err
+	}
+	command := make([]string, size)
+	for i := range command {
+		line, err := readLine(r)
+		if err != nil {
+			return nil, err
+		}
+		if len(line) == 0 || line[0] != '$' {
+			return nil, fmt.Errorf("expected a bulk string, got %q", line)
+		}
+		length, err := strconv.Atoi(line[1:])
+		if err != nil {
+			return nil, err
+		}
+		value := make([]byte, length+2)
+		if _, err := io.ReadFull(r, value); err != nil {
+			return nil, err
+		}
+		command[i] = string(value[:length])
+	}
+	return command, nil
+}
+
+func readLine(r *bufio.Reader) (string, error) {
+	line, err := r.ReadString('\n')
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSuffix(line, "\r\n"), nil
+}
@@ -0,0 +1,118 @@
+package stored_requests
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+)
+
+// flight is a fetch of an ID from the backend, which the concurrent requests for the same ID wait for
+// rather than fetching it again.
+type flight struct {
+	done chan struct{}
+	data json.RawMessage
+	errs []error
+	// notFound is true if the backend returned a NotFoundError for the ID
+	notFound bool
+}
+
+// flights coalesces the concurrent fetches of the same IDs. It's safe for concurrent use.
+type flights struct {
+	mutex    sync.Mutex
+	inFlight map[string]*flight
+}
+
+func newFlights() *flights {
+	return &flights{inFlight: make(map[string]*flight)}
+}
+
+// board splits the ids into those which the caller must fetch and then land, and those which are already
+// being fetched by another request, whose flights the caller must wait for.
+func (f *flights) board(dataType string, ids []string) (fetch []string, started map[string]*flight, joined map[string]*flight) {
+	fetch = make([]string, 0, len(ids))
+	started = make(map[string]*flight, len(ids))
+	joined = make(map[string]*flight)
+
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	for _, id := range ids {
+		key := dataType + ":" + id
+		if fl, ok := f.inFlight[key]; ok {
+			joined[id] = fl
+			continue
+		}
+		if _, ok := started[id]; ok {
+			continue
+		}
+		fl := &flight{done: make(chan struct{})}
+		f.inFlight[key] = fl
+		started[id] = fl
+		fetch = append(fetch, id)
+	}
+	return
+}
+
+// land publishes the results of the started flights to the requests waiting for them. The IDs missing from
+// data get their NotFoundError, or the other errors of the fetch.
+func (f *flights) land(dataType string, started map[string]*flight, data map[string]json.RawMessage, errs []error) {
+	f.mutex.Lock()
+	for id := range started {
+		delete(f.inFlight, dataType+":"+id)
+	}
+	f.mutex.Unlock()
+
+	var otherErrs []error
+	notFoundErrs := make(map[string]error)
+	for _, err := range errs {
+		if notFoundErr, ok := err.(NotFoundError); ok {
+			if notFoundErr.DataType == dataType {
+				notFoundErrs[notFoundErr.ID] = err
+			}
+			continue
+		}
+		otherErrs = append(otherErrs, err)
+	}
+
+	for id, fl := range started {
+		if value, ok := data[id]; ok {
+			fl.data = value
+		} else if err, ok := notFoundErrs[id]; ok {
+			fl.errs = []error{err}
+			fl.notFound = true
+		} else if len(otherErrs) > 0 {
+			fl.errs = otherErrs
+		} else {
+			fl.errs = []error{NotFoundError{ID: id, DataType: dataType}}
+		}
+		close(fl.done)
+	}
+}
+
+// wait returns the data and the errors of the joined flights once they've landed. The flights which didn't
+// land before ctx is done return its error.
+func wait(ctx context.Context, joined map[string]*flight) (data map[string]json.RawMessage, errs []error) {
+	data = make(map[string]json.RawMessage, len(joined))
+	for id, fl := range joined {
+		select {
+		case <-fl.done:
+		case <-ctx.Done():
+			return data, append(errs, ctx.Err())
+		}
+		if fl.data != nil {
+			data[id] = fl.data
+		}
+		errs = append(errs, fl.errs...)
+	}
+	return
+}
+
+// notFound returns the IDs of the landed flights which the backend didn't find
+func notFound(started map[string]*flight) []string {
+	ids := make([]string, 0, len(started))
+	for id, fl := range started {
+		if fl.notFound {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
@@ -16,15 +16,28 @@ import (
 	"github.com/prebid/prebid-server/v3/stored_requests/backends/empty_fetcher"
 	"github.com/prebid/prebid-server/v3/stored_requests/backends/file_fetcher"
 	"github.com/prebid/prebid-server/v3/stored_requests/backends/http_fetcher"
+	"github.com/prebid/prebid-server/v3/stored_requests/backends/s3_fetcher"
 	"github.com/prebid/prebid-server/v3/stored_requests/caches/memory"
 	"github.com/prebid/prebid-server/v3/stored_requests/caches/nil_cache"
+	"github.com/prebid/prebid-server/v3/stored_requests/caches/redis"
 	"github.com/prebid/prebid-server/v3/stored_requests/events"
 	apiEvents "github.com/prebid/prebid-server/v3/stored_requests/events/api"
 	databaseEvents "github.com/prebid/prebid-server/v3/stored_requests/events/database"
 	httpEvents "github.com/prebid/prebid-server/v3/stored_requests/events/http"
+	s3Events "github.com/prebid/prebid-server/v3/stored_requests/events/s3"
+	"github.com/prebid/prebid-server/v3/stored_requests/management"
 	"github.com/prebid/prebid-server/v3/util/task"
 )
 
+var storedDataTypeMetricMap = map[config.DataType]metrics.StoredDataType{
+	config.RequestDataType:    metrics.RequestDataType,
+	config.CategoryDataType:   metrics.CategoryDataType,
+	config.VideoDataType:      metrics.VideoDataType,
+	config.AMPRequestDataType: metrics.AMPDataType,
+	config.AccountDataType:    metrics.AccountDataType,
+	config.ResponseDataType:   metrics.ResponseDataType,
+}
+
 // CreateStoredRequests returns three things:
 //
 // 1. A Fetcher which can be used to get Stored Requests
@@ -35,7 +48,7 @@ import (
 //
 // As a side-effect, it will add some endpoints to the router if the config calls for it.
 // In the future we should look for ways to simplify this so that it's not doing two things.
-func CreateStoredRequests(cfg *config.StoredRequests, metricsEngine metrics.MetricsEngine, client *http.Client, router *httprouter.Router, provider db_provider.DbProvider) (fetcher stored_requests.AllFetcher, shutdown func()) {
+func CreateStoredRequests(cfg *config.StoredRequests, timeout time.Duration, metricsEngine metrics.MetricsEngine, client *http.Client, router *httprouter.Router, provider db_provider.DbProvider) (fetcher stored_requests.AllFetcher, shutdown func()) {
 	// Create database connection if given options for one
 	if cfg.Database.ConnectionInfo.Database != "" {
 		if provider == nil {
@@ -56,14 +69,37 @@ func CreateStoredRequests(cfg *config.StoredRequests, metricsEngine metrics.Metr
 	}
 
 	eventProducers := newEventProducers(cfg, client, provider, metricsEngine, router)
-	fetcher = newFetcher(cfg, client, provider)
+	fetcher, fileWatcher := newFetcher(cfg, client, provider, metricsEngine)
+	fetcher = stored_requests.WithTracing(fetcher, string(cfg.DataType()))
+	if fileWatcher != nil {
+		eventProducers = append(eventProducers, fileWatcher)
+	}
 
 	var shutdown1 func()
 
+	var closeRedisCache func()
+
+	var resolvedAccounts *stored_requests.ResolvedAccountCache
+
 	if cfg.InMemoryCache.Type != "" {
 		cache := newCache(cfg)
-		fetcher = stored_requests.WithCache(fetcher, cache, metricsEngine)
-		shutdown1 = addListeners(cache, eventProducers)
+		cache, closeRedisCache = withRedisCache(cfg, cache)
+		negativeCache := stored_requests.NewNegativeCache(time.Duration(cfg.InMemoryCache.NegativeTTL) * time.Second)
+		fetcher = stored_requests.WithCache(fetcher, cache, negativeCache, timeout, metricsEngine)
+		listenerCache := negativeCache.Compose(cache)
+		if cfg.DataType() == config.AccountDataType && cfg.InMemoryCache.Type != "none" {
+			// the resolved accounts are invalidated last, so that they're resolved again from the updated accounts
+			resolvedAccounts = stored_requests.NewResolvedAccountCache(memory.NewCache(cfg.InMemoryCache.Size, cfg.InMemoryCache.TTL, "Resolved Accounts"))
+			listenerCache.Accounts = stored_requests.ComposedCache{listenerCache.Accounts, resolvedAccounts}
+		}
+		shutdown1 = addListeners(listenerCache, eventProducers)
+	}
+
+	// the versions are resolved whether the data is cached or not, for each request
+	fetcher = stored_requests.WithVersions(fetcher)
+
+	if cfg.DataType() == config.AccountDataType {
+		fetcher = stored_requests.WithAccountInheritance(fetcher, resolvedAccounts)
 	}
 
 	shutdown = func() {
@@ -71,6 +107,14 @@ func CreateStoredRequests(cfg *config.StoredRequests, metricsEngine metrics.Metr
 			shutdown1()
 		}
 
+		if closeRedisCache != nil {
+			closeRedisCache()
+		}
+
+		if fileWatcher != nil {
+			fileWatcher.Stop()
+		}
+
 		if provider == nil {
 			return
 		}
@@ -106,13 +150,14 @@ func NewStoredRequests(cfg *config.Configuration, metricsEngine metrics.MetricsE
 	storedRespFetcher stored_requests.Fetcher) {
 
 	var provider db_provider.DbProvider
+	timeout := time.Duration(cfg.StoredRequestsTimeout) * time.Millisecond
 
-	fetcher1, shutdown1 := CreateStoredRequests(&cfg.StoredRequests, metricsEngine, client, router, provider)
-	fetcher2, shutdown2 := CreateStoredRequests(&cfg.StoredRequestsAMP, metricsEngine, client, router, provider)
-	fetcher3, shutdown3 := CreateStoredRequests(&cfg.CategoryMapping, metricsEngine, client, router, provider)
-	fetcher4, shutdown4 := CreateStoredRequests(&cfg.StoredVideo, metricsEngine, client, router, provider)
-	fetcher5, shutdown5 := CreateStoredRequests(&cfg.Accounts, metricsEngine, client, router, provider)
-	fetcher6, shutdown6 := CreateStoredRequests(&cfg.StoredResponses, metricsEngine, client, router, provider)
+	fetcher1, shutdown1 := CreateStoredRequests(&cfg.StoredRequests, timeout, metricsEngine, client, router, provider)
+	fetcher2, shutdown2 := CreateStoredRequests(&cfg.StoredRequestsAMP, timeout, metricsEngine, client, router, provider)
+	fetcher3, shutdown3 := CreateStoredRequests(&cfg.CategoryMapping, timeout, metricsEngine, client, router, provider)
+	fetcher4, shutdown4 := CreateStoredRequests(&cfg.StoredVideo, timeout, metricsEngine, client, router, provider)
+	fetcher5, shutdown5 := CreateStoredRequests(&cfg.Accounts, timeout, metricsEngine, client, router, provider)
+	fetcher6, shutdown6 := CreateStoredRequests(&cfg.StoredResponses, timeout, metricsEngine, client, router, provider)
 
 	fetcher = fetcher1.(stored_requests.Fetcher)
 	ampFetcher = fetcher2.(stored_requests.Fetcher)
@@ -133,6 +178,57 @@ func NewStoredRequests(cfg *config.Configuration, metricsEngine metrics.MetricsE
 	return
 }
 
+// NewStoredDataStore returns the store of the stored data management API, and a function which should be
+// called on shutdown. The caches are updated by the event producers of the stored data config, which read
+// the same tables or directory.
+func NewStoredDataStore(cfg config.StoredDataManagement) (store management.Store, shutdown func()) {
+	if cfg.Backend == config.StoredDataManagementBackendFilesystem {
+		return management.NewFileStore(cfg.Filesystem.DirectoryPath, cfg.Filesystem.AuditPath), func() {}
+	}
+
+	glog.Infof("Connecting to Database for the Stored Data Management. Driver=%s, DB=%s, host=%s, port=%d, user=%s",
+		cfg.Database.Connection.Driver,
+		cfg.Database.Connection.Database,
+		cfg.Database.Connection.Host,
+		cfg.Database.Connection.Port,
+		cfg.Database.Connection.Username)
+	provider := db_provider.NewDbProvider(config.DataType("Data Management"), cfg.Database.Connection)
+	shutdown = func() {
+		if err := provider.Close(); err != nil {
+			glog.Errorf("Error closing DB connection: %v", err)
+		}
+	}
+	return management.NewDatabaseStore(provider, cfg.Database.Tables), shutdown
+}
+
+// withRedisCache puts the Redis cache of the config behind the in-memory caches, and returns a function which
+// closes its connections
+func withRedisCache(cfg *config.StoredRequests, cache stored_requests.Cache) (stored_requests.Cache, func()) {
+	if !cfg.RedisCache.Enabled {
+		return cache, nil
+	}
+
+	client := redis.NewClient(cfg.RedisCache)
+	ttl := time.Duration(cfg.RedisCache.TTL) * time.Second
+	retryInterval := time.Duration(cfg.RedisCache.RetryIntervalMS) * time.Millisecond
+	compose := func(memoryCache stored_requests.CacheJSON, keyPrefix string, dataType string) stored_requests.CacheJSON {
+		redisCache := redis.NewCache(client, keyPrefix, ttl, retryInterval, dataType)
+		if _, ok := memoryCache.(*nil_cache.NilCache); ok {
+			return redisCache
+		}
+		return stored_requests.ComposedCache{memoryCache, redisCache}
+	}
+
+	if cfg.DataType() == config.AccountDataType {
+		cache.Accounts = compose(cache.Accounts, cfg.RedisCache.Prefixes.Accounts, "Accounts")
+	} else {
+		cache.Requests = compose(cache.Requests, cfg.RedisCache.Prefixes.Requests, "Requests")
+		cache.Imps = compose(cache.Imps, cfg.RedisCache.Prefixes.Imps, "Imps")
+		cache.Responses = compose(cache.Responses, cfg.RedisCache.Prefixes.Responses, "Responses")
+	}
+	return cache, client.Close
+}
+
 func addListeners(cache stored_requests.Cache, eventProducers []events.EventProducer) (shutdown func()) {
 	listeners := make([]*events.EventListener, 0, len(eventProducers))
 
@@ -149,10 +245,15 @@ func addListeners(cache stored_requests.Cache, eventProducers []events.EventProd
 	}
 }
 
-func newFetcher(cfg *config.StoredRequests, client *http.Client, provider db_provider.DbProvider) (fetcher stored_requests.AllFetcher) {
+// newFetcher returns the fetcher of the configured backends, along with the file watcher when the files
+// are reloaded on change.
+func newFetcher(cfg *config.StoredRequests, client *http.Client, provider db_provider.DbProvider, metricsEngine metrics.MetricsEngine) (fetcher stored_requests.AllFetcher, fileWatcher *file_fetcher.WatchingFileFetcher) {
 	idList := make(stored_requests.MultiFetcher, 0, 3)
 
-	if cfg.Files.Enabled {
+	if cfg.Files.Enabled && cfg.Files.Watch.Enabled {
+		fileWatcher = newWatchingFilesystem(cfg, metricsEngine)
+		idList = append(idList, fileWatcher)
+	} else if cfg.Files.Enabled {
 		fFetcher := newFilesystem(cfg.DataType(), cfg.Files.Path)
 		idList = append(idList, fFetcher)
 	}
@@ -168,6 +269,10 @@ func newFetcher(cfg *config.StoredRequests, client *http.Client, provider db_pro
 		glog.Infof("Loading Stored %s data via HTTP. endpoint=%s", cfg.DataType(), cfg.HTTP.Endpoint)
 		idList = append(idList, http_fetcher.NewFetcher(client, cfg.HTTP.Endpoint))
 	}
+	if cfg.S3.Enabled {
+		glog.Infof("Loading Stored %s data via S3. endpoint=%s, bucket=%s", cfg.DataType(), cfg.S3.Endpoint, cfg.S3.Bucket)
+		idList = append(idList, s3_fetcher.NewFetcher(newS3Client(cfg, client), cfg.S3.Prefixes))
+	}
 
 	fetcher = consolidate(cfg.DataType(), idList)
 	return
@@ -216,6 +321,12 @@ func newEventProducers(cfg *config.StoredRequests, client *http.Client, provider
 		dbEventTickerTask.Start()
 		eventProducers = append(eventProducers, dbEventProducer)
 	}
+	if cfg.S3.Enabled && cfg.S3.RefreshRateSeconds > 0 {
+		s3EventProducer := s3Events.NewS3EventProducer(newS3Client(cfg, client), cfg.DataType(), cfg.S3.Prefixes, time.Duration(cfg.S3.TimeoutMS)*time.Millisecond)
+		s3EventTickerTask := task.NewTickerTask(time.Duration(cfg.S3.RefreshRateSeconds)*time.Second, s3EventProducer)
+		s3EventTickerTask.Start()
+		eventProducers = append(eventProducers, s3EventProducer)
+	}
 	return
 }
 
@@ -242,6 +353,25 @@ func newFilesystem(dataType config.DataType, configPath string) stored_requests.
 	return fetcher
 }
 
+// newWatchingFilesystem returns a filesystem fetcher which reloads the files on change. The changes are
+// only published when there is a cache to listen to them.
+func newWatchingFilesystem(cfg *config.StoredRequests, metricsEngine metrics.MetricsEngine) *file_fetcher.WatchingFileFetcher {
+	glog.Infof("Loading Stored %s data from filesystem at path %s and watching it for changes", cfg.DataType(), cfg.Files.Path)
+	fetcher, err := file_fetcher.NewWatchingFileFetcher(cfg.Files.Path, cfg.Files.Watch, storedDataTypeMetricMap[cfg.DataType()], metricsEngine, cfg.InMemoryCache.Type != "")
+	if err != nil {
+		glog.Fatalf("Failed to create a %s FileFetcher: %v", cfg.DataType(), err)
+	}
+	return fetcher
+}
+
+func newS3Client(cfg *config.StoredRequests, client *http.Client) *s3_fetcher.Client {
+	s3Client, err := s3_fetcher.NewClient(client, cfg.S3)
+	if err != nil {
+		glog.Fatalf("Failed to create a %s S3 client: %v", cfg.DataType(), err)
+	}
+	return s3Client
+}
+
 // consolidate returns a single Fetcher from an array of fetchers of any size.
 func consolidate(dataType config.DataType, fetchers []stored_requests.AllFetcher) stored_requests.AllFetcher {
 	if len(fetchers) == 0 {
@@ -6,10 +6,14 @@ import (
 	"errors"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"regexp"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	sqlmock "github.com/DATA-DOG/go-sqlmock"
 	"github.com/julienschmidt/httprouter"
@@ -19,6 +23,8 @@ import (
 	"github.com/prebid/prebid-server/v3/stored_requests/backends/db_provider"
 	"github.com/prebid/prebid-server/v3/stored_requests/backends/empty_fetcher"
 	"github.com/prebid/prebid-server/v3/stored_requests/backends/http_fetcher"
+	"github.com/prebid/prebid-server/v3/stored_requests/backends/s3_fetcher"
+	"github.com/prebid/prebid-server/v3/stored_requests/caches/redis/redistest"
 	"github.com/prebid/prebid-server/v3/stored_requests/events"
 	httpEvents "github.com/prebid/prebid-server/v3/stored_requests/events/http"
 	"github.com/stretchr/testify/mock"
@@ -117,7 +123,7 @@ func TestNewEmptyFetcher(t *testing.T) {
 	}
 
 	for _, test := range testCases {
-		fetcher := newFetcher(test.config, nil, db_provider.DbProviderMock{})
+		fetcher, _ := newFetcher(test.config, nil, db_provider.DbProviderMock{}, &metrics.MetricsEngineMock{})
 		assert.NotNil(t, fetcher, "The fetcher should be non-nil.")
 		if test.emptyFetcher {
 			assert.Equal(t, empty_fetcher.EmptyFetcher{}, fetcher, "Empty fetcher should be returned")
@@ -128,11 +134,11 @@ func TestNewEmptyFetcher(t *testing.T) {
 }
 
 func TestNewHTTPFetcher(t *testing.T) {
-	fetcher := newFetcher(&config.StoredRequests{
+	fetcher, _ := newFetcher(&config.StoredRequests{
 		HTTP: config.HTTPFetcherConfig{
 			Endpoint: "stored-requests.prebid.com",
 		},
-	}, nil, nil)
+	}, nil, nil, &metrics.MetricsEngineMock{})
 	if httpFetcher, ok := fetcher.(*http_fetcher.HttpFetcher); ok {
 		if httpFetcher.Endpoint != "stored-requests.prebid.com?" {
 			t.Errorf("The HTTP fetcher is using the wrong endpoint. Expected %s, got %s", "stored-requests.prebid.com?", httpFetcher.Endpoint)
@@ -142,6 +148,35 @@ func TestNewHTTPFetcher(t *testing.T) {
 	}
 }
 
+func TestNewS3Fetcher(t *testing.T) {
+	fetcher, _ := newFetcher(&config.StoredRequests{
+		S3: config.S3FetcherConfig{
+			Enabled:  true,
+			Endpoint: "https://s3.amazonaws.com",
+			Region:   "us-east-1",
+			Bucket:   "stored-data",
+		},
+	}, nil, nil, &metrics.MetricsEngineMock{})
+	assert.IsType(t, &s3_fetcher.S3Fetcher{}, fetcher, "An S3 config should return an S3Fetcher")
+}
+
+func TestNewWatchingFileFetcher(t *testing.T) {
+	metricsMock := &metrics.MetricsEngineMock{}
+	metricsMock.Mock.On("RecordStoredDataError", mock.Anything).Return()
+
+	fetcher, fileWatcher := newFetcher(&config.StoredRequests{
+		Files: config.FileFetcherConfig{
+			Enabled: true,
+			Path:    "../backends/file_fetcher/test",
+			Watch:   config.FileWatchConfig{Enabled: true, Polling: true, PollIntervalMS: 1000},
+		},
+	}, nil, nil, metricsMock)
+	require.NotNil(t, fileWatcher, "A watched filesystem config should return the file watcher")
+	defer fileWatcher.Stop()
+
+	assert.Equal(t, fileWatcher, fetcher)
+}
+
 func TestNewHTTPEvents(t *testing.T) {
 	handler := func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusInternalServerError)
@@ -199,6 +234,39 @@ func TestNewInMemoryAccountCache(t *testing.T) {
 	assert.True(t, isEmptyCacheType(cache.Responses), "The newCache method should return an empty Responses cache for Accounts config")
 }
 
+func TestNewRedisCache(t *testing.T) {
+	server, err := redistest.NewServer("")
+	require.NoError(t, err)
+	defer server.Close()
+	redisCache := config.RedisCache{Enabled: true, Address: server.Addr(), TimeoutMS: 1000, PoolSize: 1, Prefixes: config.RedisCachePrefixes{Accounts: "accounts:"}}
+
+	cache, closeRedisCache := withRedisCache(&config.StoredRequests{RedisCache: redisCache}, newCache(&config.StoredRequests{
+		InMemoryCache: config.InMemoryCache{
+			TTL:              60,
+			RequestCacheSize: 100,
+			ImpCacheSize:     100,
+			RespCacheSize:    100,
+		},
+	}))
+	require.NotNil(t, closeRedisCache)
+	defer closeRedisCache()
+	assert.IsType(t, stored_requests.ComposedCache{}, cache.Requests, "The Redis cache should be behind the in-memory Request cache")
+	assert.IsType(t, stored_requests.ComposedCache{}, cache.Imps, "The Redis cache should be behind the in-memory Imp cache")
+	assert.IsType(t, stored_requests.ComposedCache{}, cache.Responses, "The Redis cache should be behind the in-memory Responses cache")
+	assert.True(t, isEmptyCacheType(cache.Accounts), "The Redis cache shouldn't cache Accounts for StoredRequests config")
+
+	cache, closeRedisCache = withRedisCache(typedConfig(config.AccountDataType, &config.StoredRequests{RedisCache: redisCache}), newCache(&config.StoredRequests{InMemoryCache: config.InMemoryCache{Type: "none"}}))
+	require.NotNil(t, closeRedisCache)
+	defer closeRedisCache()
+	assert.True(t, isMemoryCacheType(cache.Accounts), "The Redis cache should replace the empty Account cache")
+	_, ok := server.Value("accounts:foo")
+	assert.True(t, ok, "The Accounts should be saved in the Redis cache")
+	assert.True(t, isEmptyCacheType(cache.Requests), "The Redis cache shouldn't cache Requests for Accounts config")
+
+	_, closeRedisCache = withRedisCache(&config.StoredRequests{}, newCache(&config.StoredRequests{InMemoryCache: config.InMemoryCache{Type: "none"}}))
+	assert.Nil(t, closeRedisCache, "The Redis cache should be disabled by default")
+}
+
 func TestNewDatabaseEventProducers(t *testing.T) {
 	metricsMock := &metrics.MetricsEngineMock{}
 	metricsMock.Mock.On("RecordStoredDataFetchTime", mock.Anything, mock.Anything).Return()
@@ -278,3 +346,23 @@ func assertStringsEqual(t *testing.T, actual string, expected string) {
 		t.Fatalf("String %s did not match expected %s", actual, expected)
 	}
 }
+
+func TestCreateStoredAccountsInheritance(t *testing.T) {
+	directory := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(directory, "accounts"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(directory, "accounts", "network.json"), []byte(`{"price_floors":{"enabled":true}}`), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(directory, "accounts", "publisher.json"), []byte(`{"parent":"network"}`), 0644))
+
+	metricsEngine := &metrics.MetricsEngineMock{}
+	metricsEngine.On("RecordAccountCacheResult", mock.Anything, mock.Anything).Return()
+	fetcher, shutdown := CreateStoredRequests(typedConfig(config.AccountDataType, &config.StoredRequests{
+		Files:         config.FileFetcherConfig{Enabled: true, Path: directory},
+		InMemoryCache: config.InMemoryCache{Type: "lru", TTL: 60, Size: 1000},
+	}), time.Second, metricsEngine, nil, nil, nil)
+	defer shutdown()
+
+	account, errs := fetcher.FetchAccount(context.Background(), json.RawMessage(`{"disabled":false}`), "publisher")
+	assert.Empty(t, errs)
+	assert.JSONEq(t, `{"disabled":false,"price_floors":{"enabled":true},"parent":"network"}`, string(account))
+	assert.Implements(t, (*stored_requests.AccountResolver)(nil), fetcher, "The accounts fetcher should resolve the parent chains")
+}
@@ -178,10 +178,12 @@ func (e *DatabaseEventProducer) sendEvents(rows *sql.Rows) (err error) {
 	storedRequestData := make(map[string]json.RawMessage)
 	storedImpData := make(map[string]json.RawMessage)
 	storedRespData := make(map[string]json.RawMessage)
+	storedAccountData := make(map[string]json.RawMessage)
 
 	var requestInvalidations []string
 	var impInvalidations []string
 	var respInvalidations []string
+	var accountInvalidations []string
 
 	for rows.Next() {
 		var id string
@@ -212,6 +214,12 @@ func (e *DatabaseEventProducer) sendEvents(rows *sql.Rows) (err error) {
 			} else {
 				storedRespData[id] = data
 			}
+		case "account":
+			if len(data) == 0 || bytes.Equal(data, bytesNull()) {
+				accountInvalidations = append(accountInvalidations, id)
+			} else {
+				storedAccountData[id] = data
+			}
 		default:
 			glog.Warningf("Stored Data with id=%s has invalid type: %s. This will be ignored.", id, dataType)
 		}
@@ -222,19 +230,21 @@ func (e *DatabaseEventProducer) sendEvents(rows *sql.Rows) (err error) {
 		return rows.Err()
 	}
 
-	if len(storedRequestData) > 0 || len(storedImpData) > 0 || len(storedRespData) > 0 {
+	if len(storedRequestData) > 0 || len(storedImpData) > 0 || len(storedRespData) > 0 || len(storedAccountData) > 0 {
 		e.saves <- events.Save{
 			Requests:  storedRequestData,
 			Imps:      storedImpData,
 			Responses: storedRespData,
+			Accounts:  storedAccountData,
 		}
 	}
 
-	if (len(requestInvalidations) > 0 || len(impInvalidations) > 0 || len(respInvalidations) > 0) && !e.lastUpdate.IsZero() {
+	if (len(requestInvalidations) > 0 || len(impInvalidations) > 0 || len(respInvalidations) > 0 || len(accountInvalidations) > 0) && !e.lastUpdate.IsZero() {
 		e.invalidations <- events.Invalidation{
 			Requests:  requestInvalidations,
 			Imps:      impInvalidations,
 			Responses: respInvalidations,
+			Accounts:  accountInvalidations,
 		}
 	}
 
@@ -397,6 +397,45 @@ func TestFetchDeltaSuccess(t *testing.T) {
 	}
 }
 
+func TestFetchDeltaAccounts(t *testing.T) {
+	provider, dbMock, _ := db_provider.NewDbProviderMock()
+	dbMock.ExpectQuery(fakeQueryRegex()).WillReturnRows(sqlmock.NewRows([]string{"id", "data", "dataType"}).
+		AddRow("account-1", `{"id":"account-1"}`, "account").
+		AddRow("account-2", "null", "account"))
+
+	metricsMock := &metrics.MetricsEngineMock{}
+	metricsMock.Mock.On("RecordStoredDataFetchTime", metrics.StoredDataLabels{
+		DataType:      metrics.AccountDataType,
+		DataFetchType: metrics.FetchDelta,
+	}, mock.Anything).Return()
+
+	eventProducer := NewDatabaseEventProducer(DatabaseEventProducerConfig{
+		Provider:           provider,
+		RequestType:        config.AccountDataType,
+		CacheUpdateTimeout: 100 * time.Millisecond,
+		CacheUpdateQuery:   fakeQuery,
+		MetricsEngine:      metricsMock,
+	})
+	eventProducer.lastUpdate = time.Date(2020, time.June, 30, 6, 0, 0, 0, time.UTC)
+	eventProducer.time = &FakeTime{time: time.Date(2020, time.July, 1, 12, 30, 0, 0, time.UTC)}
+	assert.NoError(t, eventProducer.Run())
+
+	var saves events.Save
+	select {
+	case saves = <-eventProducer.Saves():
+	case <-time.After(20 * time.Millisecond):
+	}
+	var invalidations events.Invalidation
+	select {
+	case invalidations = <-eventProducer.Invalidations():
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	assert.Equal(t, map[string]json.RawMessage{"account-1": json.RawMessage(`{"id":"account-1"}`)}, saves.Accounts)
+	assert.Equal(t, []string{"account-2"}, invalidations.Accounts)
+	metricsMock.AssertExpectations(t)
+}
+
 func TestFetchDeltaErrors(t *testing.T) {
 	tests := []struct {
 		description       string
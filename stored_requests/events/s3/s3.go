@@ -0,0 +1,147 @@
+package s3
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/golang/glog"
+	"github.com/prebid/prebid-server/v3/config"
+	"github.com/prebid/prebid-server/v3/stored_requests/backends/s3_fetcher"
+	"github.com/prebid/prebid-server/v3/stored_requests/events"
+)
+
+// S3EventProducer lists the objects of an S3 compatible bucket on every run, and compares their ETag
+// with the previous listing. The objects which were added or changed are downloaded and saved, and
+// the objects which were deleted are invalidated.
+//
+// The first run only records the ETags, since the caches are filled as the objects are fetched.
+type S3EventProducer struct {
+	client   *s3_fetcher.Client
+	dataType config.DataType
+	prefixes config.S3Prefixes
+	timeout  time.Duration
+
+	// etags are the ETags of the objects found by the last listing, by key
+	etags         map[string]string
+	saves         chan events.Save
+	invalidations chan events.Invalidation
+}
+
+func NewS3EventProducer(client *s3_fetcher.Client, dataType config.DataType, prefixes config.S3Prefixes, timeout time.Duration) *S3EventProducer {
+	return &S3EventProducer{
+		client:        client,
+		dataType:      dataType,
+		prefixes:      prefixes,
+		timeout:       timeout,
+		saves:         make(chan events.Save, 1),
+		invalidations: make(chan events.Invalidation, 1),
+	}
+}
+
+func (e *S3EventProducer) Saves() <-chan events.Save {
+	return e.saves
+}
+
+func (e *S3EventProducer) Invalidations() <-chan events.Invalidation {
+	return e.invalidations
+}
+
+// storedData is the stored data of a prefix, along with where its changes go in the events
+type storedData struct {
+	prefix  string
+	saved   *map[string]json.RawMessage
+	removed *[]string
+}
+
+func (e *S3EventProducer) Run() error {
+	ctx, cancel := context.WithTimeout(context.Background(), e.timeout)
+	defer cancel()
+
+	var save events.Save
+	var invalidation events.Invalidation
+	var data []storedData
+	if e.dataType == config.AccountDataType {
+		data = []storedData{{e.prefixes.Accounts, &save.Accounts, &invalidation.Accounts}}
+	} else {
+		data = []storedData{
+			{e.prefixes.Requests, &save.Requests, &invalidation.Requests},
+			{e.prefixes.Imps, &save.Imps, &invalidation.Imps},
+			{e.prefixes.Responses, &save.Responses, &invalidation.Responses},
+		}
+	}
+
+	etags := make(map[string]string)
+	for _, storedData := range data {
+		prefixETags, err := e.client.ListObjects(ctx, storedData.prefix)
+		if err != nil {
+			glog.Warningf("Failed to list the Stored %s data under %s: %v", e.dataType, storedData.prefix, err)
+			return err
+		}
+		for key, etag := range prefixETags {
+			if _, ok := e.client.ObjectID(storedData.prefix, key); ok {
+				etags[key] = etag
+			}
+		}
+	}
+
+	if e.etags != nil {
+		for _, storedData := range data {
+			if err := e.diff(ctx, storedData, etags); err != nil {
+				return err
+			}
+		}
+	}
+	e.etags = etags
+
+	if len(save.Requests)+len(save.Imps)+len(save.Responses)+len(save.Accounts) > 0 {
+		e.saves <- save
+	}
+	if len(invalidation.Requests)+len(invalidation.Imps)+len(invalidation.Responses)+len(invalidation.Accounts) > 0 {
+		e.invalidations <- invalidation
+	}
+	return nil
+}
+
+// diff downloads the objects of the prefix which were added or changed since the last listing, and finds
+// the objects which were removed.
+func (e *S3EventProducer) diff(ctx context.Context, storedData storedData, etags map[string]string) error {
+	for key, etag := range etags {
+		id, ok := e.client.ObjectID(storedData.prefix, key)
+		if !ok || e.etags[key] == etag {
+			continue
+		}
+		object, err := e.client.GetObject(ctx, key)
+		if err == s3_fetcher.ErrNotFound {
+			delete(etags, key)
+			continue
+		}
+		if err != nil {
+			glog.Warningf("Failed to fetch the updated Stored %s %s: %v", e.dataType, key, err)
+			return err
+		}
+		if !json.Valid(object.Data) {
+			// keep the previous ETag, so the object is retried on the next run without being invalidated
+			glog.Warningf("Ignored the updated Stored %s %s, it isn't valid JSON", e.dataType, key)
+			if previousETag, ok := e.etags[key]; ok {
+				etags[key] = previousETag
+			} else {
+				delete(etags, key)
+			}
+			continue
+		}
+		if *storedData.saved == nil {
+			*storedData.saved = make(map[string]json.RawMessage)
+		}
+		(*storedData.saved)[id] = object.Data
+	}
+
+	for key := range e.etags {
+		if id, ok := e.client.ObjectID(storedData.prefix, key); ok {
+			if _, exists := etags[key]; !exists {
+				*storedData.removed = append(*storedData.removed, id)
+			}
+		}
+	}
+	return nil
+}
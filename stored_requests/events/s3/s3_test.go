@@ -0,0 +1,122 @@
+package s3
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/prebid/prebid-server/v3/config"
+	"github.com/prebid/prebid-server/v3/stored_requests/backends/s3_fetcher"
+	"github.com/prebid/prebid-server/v3/stored_requests/backends/s3_fetcher/s3test"
+	"github.com/prebid/prebid-server/v3/stored_requests/events"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testPrefixes = config.S3Prefixes{
+	Requests:  "stored_requests/",
+	Imps:      "stored_imps/",
+	Responses: "stored_responses/",
+	Accounts:  "accounts/",
+}
+
+func TestS3EventProducer(t *testing.T) {
+	server := s3test.NewServer("bucket", 2)
+	defer server.Close()
+	server.PutObject("stored_requests/1.json", []byte(`{"id":"1"}`))
+	server.PutObject("stored_requests/2.json", []byte(`{"id":"2"}`))
+	server.PutObject("stored_imps/1.json", []byte(`{"id":"imp-1"}`))
+	server.PutObject("accounts/acct.json", []byte(`{}`))
+
+	producer := NewS3EventProducer(newTestClient(t, server), config.RequestDataType, testPrefixes, time.Second)
+
+	// the first run only lists the objects
+	require.NoError(t, producer.Run())
+	assertNoEvents(t, producer)
+	assert.Zero(t, server.Gets())
+
+	server.PutObject("stored_requests/1.json", []byte(`{"id":"1","v":2}`))
+	server.PutObject("stored_imps/2.json", []byte(`{"id":"imp-2"}`))
+	server.PutObject("stored_imps/notes.txt", []byte(`not stored data`))
+	server.PutObject("accounts/acct.json", []byte(`{"disabled":true}`))
+	server.DeleteObject("stored_requests/2.json")
+	require.NoError(t, producer.Run())
+
+	save := <-producer.Saves()
+	assert.Equal(t, map[string]json.RawMessage{"1": json.RawMessage(`{"id":"1","v":2}`)}, save.Requests)
+	assert.Equal(t, map[string]json.RawMessage{"2": json.RawMessage(`{"id":"imp-2"}`)}, save.Imps)
+	assert.Empty(t, save.Accounts, "the accounts aren't stored requests data")
+
+	invalidation := <-producer.Invalidations()
+	assert.Equal(t, []string{"2"}, invalidation.Requests)
+	assert.Empty(t, invalidation.Imps)
+
+	require.NoError(t, producer.Run())
+	assertNoEvents(t, producer)
+}
+
+func TestS3EventProducerAccounts(t *testing.T) {
+	server := s3test.NewServer("bucket", 2)
+	defer server.Close()
+	server.PutObject("accounts/acct.json", []byte(`{}`))
+
+	producer := NewS3EventProducer(newTestClient(t, server), config.AccountDataType, testPrefixes, time.Second)
+	require.NoError(t, producer.Run())
+
+	server.PutObject("accounts/acct.json", []byte(`{"disabled":true}`))
+	server.PutObject("stored_requests/1.json", []byte(`{"id":"1"}`))
+	require.NoError(t, producer.Run())
+
+	save := <-producer.Saves()
+	assert.Equal(t, events.Save{Accounts: map[string]json.RawMessage{"acct": json.RawMessage(`{"disabled":true}`)}}, save)
+}
+
+func TestS3EventProducerInvalidObject(t *testing.T) {
+	server := s3test.NewServer("bucket", 2)
+	defer server.Close()
+	server.PutObject("stored_requests/1.json", []byte(`{"id":"1"}`))
+
+	producer := NewS3EventProducer(newTestClient(t, server), config.RequestDataType, testPrefixes, time.Second)
+	require.NoError(t, producer.Run())
+
+	server.PutObject("stored_requests/1.json", []byte(`{"id":`))
+	require.NoError(t, producer.Run())
+	assertNoEvents(t, producer)
+
+	server.PutObject("stored_requests/1.json", []byte(`{"id":"1","v":2}`))
+	require.NoError(t, producer.Run())
+	save := <-producer.Saves()
+	assert.Equal(t, map[string]json.RawMessage{"1": json.RawMessage(`{"id":"1","v":2}`)}, save.Requests)
+}
+
+func TestS3EventProducerListError(t *testing.T) {
+	server := s3test.NewServer("bucket", 2)
+	defer server.Close()
+
+	client, err := s3_fetcher.NewClient(server.Client(), config.S3FetcherConfig{Endpoint: server.URL, Bucket: "other", ForcePathStyle: true})
+	require.NoError(t, err)
+
+	producer := NewS3EventProducer(client, config.RequestDataType, testPrefixes, time.Second)
+	assert.Error(t, producer.Run())
+	assertNoEvents(t, producer)
+}
+
+func newTestClient(t *testing.T, server *s3test.Server) *s3_fetcher.Client {
+	t.Helper()
+
+	client, err := s3_fetcher.NewClient(server.Client(), config.S3FetcherConfig{
+		Endpoint:       server.URL,
+		Region:         "us-east-1",
+		Bucket:         "bucket",
+		ForcePathStyle: true,
+	})
+	require.NoError(t, err)
+	return client
+}
+
+func assertNoEvents(t *testing.T, producer *S3EventProducer) {
+	t.Helper()
+
+	assert.Empty(t, producer.Saves())
+	assert.Empty(t, producer.Invalidations())
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"time"
 
 	"github.com/prebid/prebid-server/v3/metrics"
 )
@@ -94,14 +95,21 @@ type CacheJSON interface {
 type ComposedCache []CacheJSON
 
 // Get will attempt to Get from the caches in the order in which they are in the slice,
-// stopping as soon as a value is found (or when all caches have been exhausted)
+// stopping as soon as a value is found (or when all caches have been exhausted).
+// The values found in a cache are saved in the caches before it, so that a shared cache
+// behind an in-memory cache fills the in-memory cache.
 func (c ComposedCache) Get(ctx context.Context, ids []string) (data map[string]json.RawMessage) {
 	data = make(map[string]json.RawMessage, len(ids))
 
 	remainingIDs := ids
 
-	for _, cache := range c {
+	for i, cache := range c {
 		cachedData := cache.Get(ctx, remainingIDs)
+		if i > 0 && len(cachedData) > 0 {
+			for _, previous := range c[:i] {
+				previous.Save(ctx, cachedData)
+			}
+		}
 		data, remainingIDs = updateFromCache(data, remainingIDs, cachedData)
 
 		// finish early if all ids filled
@@ -148,6 +156,9 @@ func (c ComposedCache) Save(ctx context.Context, data map[string]json.RawMessage
 type fetcherWithCache struct {
 	fetcher       AllFetcher
 	cache         Cache
+	negativeCache NegativeCache
+	flights       *flights
+	timeout       time.Duration
 	metricsEngine metrics.MetricsEngine
 }
 
@@ -155,9 +166,17 @@ type fetcherWithCache struct {
 // This can be called multiple times to compose Cache layers onto the backing Fetcher, though
 // it is usually more desirable to first compose caches with Compose, ensuring propagation of updates
 // and invalidations through all cache layers.
-func WithCache(fetcher AllFetcher, cache Cache, metricsEngine metrics.MetricsEngine) AllFetcher {
+//
+// The concurrent fetches of the same IDs are coalesced into a single fetch from the original, and
+// the IDs which it didn't find are kept in the negativeCache. The negativeCache should be composed
+// with the Caches which receive the updates, so that the IDs which are saved later are found. The
+// coalesced fetches outlive the request which started them, up to the timeout.
+func WithCache(fetcher AllFetcher, cache Cache, negativeCache NegativeCache, timeout time.Duration, metricsEngine metrics.MetricsEngine) AllFetcher {
 	return &fetcherWithCache{
 		cache:         cache,
+		negativeCache: negativeCache,
+		flights:       newFlights(),
+		timeout:       timeout,
 		fetcher:       fetcher,
 		metricsEngine: metricsEngine,
 	}
@@ -175,21 +194,57 @@ func (f *fetcherWithCache) FetchRequests(ctx context.Context, requestIDs []strin
 	// Record cache hits for stored requests and stored imps
 	f.metricsEngine.RecordStoredReqCacheResult(metrics.CacheHit, len(requestIDs)-len(leftoverReqs))
 	f.metricsEngine.RecordStoredImpCacheResult(metrics.CacheHit, len(impIDs)-len(leftoverImps))
-	// Record cache misses for stored requests and stored imps
-	f.metricsEngine.RecordStoredReqCacheResult(metrics.CacheMiss, len(leftoverReqs))
-	f.metricsEngine.RecordStoredImpCacheResult(metrics.CacheMiss, len(leftoverImps))
 
-	if len(leftoverReqs) > 0 || len(leftoverImps) > 0 {
-		fetcherReqData, fetcherImpData, fetcherErrs := f.fetcher.FetchRequests(ctx, leftoverReqs, leftoverImps)
-		errs = fetcherErrs
+	leftoverReqs, notFoundErrs := f.negativeCache.Requests.Filter("Request", leftoverReqs)
+	recordCacheResult(f.metricsEngine.RecordStoredReqCacheResult, metrics.CacheNegativeHit, len(notFoundErrs))
+	errs = append(errs, notFoundErrs...)
+	leftoverImps, notFoundErrs = f.negativeCache.Imps.Filter("Imp", leftoverImps)
+	recordCacheResult(f.metricsEngine.RecordStoredImpCacheResult, metrics.CacheNegativeHit, len(notFoundErrs))
+	errs = append(errs, notFoundErrs...)
+
+	fetchReqs, startedReqs, joinedReqs := f.flights.board("Request", leftoverReqs)
+	fetchImps, startedImps, joinedImps := f.flights.board("Imp", leftoverImps)
+	recordCacheResult(f.metricsEngine.RecordStoredReqCacheResult, metrics.CacheCoalesced, len(joinedReqs))
+	recordCacheResult(f.metricsEngine.RecordStoredImpCacheResult, metrics.CacheCoalesced, len(joinedImps))
+
+	// Record cache misses for stored requests and stored imps
+	f.metricsEngine.RecordStoredReqCacheResult(metrics.CacheMiss, len(fetchReqs))
+	f.metricsEngine.RecordStoredImpCacheResult(metrics.CacheMiss, len(fetchImps))
+
+	if len(fetchReqs) > 0 || len(fetchImps) > 0 {
+		var fetcherReqData, fetcherImpData map[string]json.RawMessage
+		var fetcherErrs []error
+		func() {
+			// the waiting requests must not hang if the fetcher panics
+			defer func() {
+				f.flights.land("Request", startedReqs, fetcherReqData, fetcherErrs)
+				f.flights.land("Imp", startedImps, fetcherImpData, fetcherErrs)
+			}()
+			flightCtx, cancel := f.flightContext(ctx)
+			defer cancel()
+			fetcherReqData, fetcherImpData, fetcherErrs = f.fetcher.FetchRequests(flightCtx, fetchReqs, fetchImps)
+		}()
+		errs = append(errs, fetcherErrs...)
 
 		f.cache.Requests.Save(ctx, fetcherReqData)
 		f.cache.Imps.Save(ctx, fetcherImpData)
+		f.negativeCache.Requests.Add(notFound(startedReqs))
+		f.negativeCache.Imps.Add(notFound(startedImps))
 
 		requestData = mergeData(requestData, fetcherReqData)
 		impData = mergeData(impData, fetcherImpData)
 	}
 
+	if len(joinedReqs) > 0 || len(joinedImps) > 0 {
+		coalescedReqData, coalescedErrs := wait(ctx, joinedReqs)
+		errs = append(errs, coalescedErrs...)
+		coalescedImpData, coalescedErrs := wait(ctx, joinedImps)
+		errs = append(errs, coalescedErrs...)
+
+		requestData = mergeData(requestData, coalescedReqData)
+		impData = mergeData(impData, coalescedImpData)
+	}
+
 	return
 }
 
@@ -197,32 +252,71 @@ func (f *fetcherWithCache) FetchResponses(ctx context.Context, ids []string) (da
 	data = f.cache.Responses.Get(ctx, ids)
 
 	leftoverResp := findLeftovers(ids, data)
-
-	if len(leftoverResp) > 0 {
-		fetcherRespData, fetcherErrs := f.fetcher.FetchResponses(ctx, leftoverResp)
-		errs = fetcherErrs
+	leftoverResp, errs = f.negativeCache.Responses.Filter("Response", leftoverResp)
+	fetchResp, startedResp, joinedResp := f.flights.board("Response", leftoverResp)
+
+	if len(fetchResp) > 0 {
+		var fetcherRespData map[string]json.RawMessage
+		var fetcherErrs []error
+		func() {
+			defer func() { f.flights.land("Response", startedResp, fetcherRespData, fetcherErrs) }()
+			flightCtx, cancel := f.flightContext(ctx)
+			defer cancel()
+			fetcherRespData, fetcherErrs = f.fetcher.FetchResponses(flightCtx, fetchResp)
+		}()
+		errs = append(errs, fetcherErrs...)
 
 		f.cache.Responses.Save(ctx, fetcherRespData)
+		f.negativeCache.Responses.Add(notFound(startedResp))
 
 		data = mergeData(data, fetcherRespData)
 	}
 
+	if len(joinedResp) > 0 {
+		coalescedData, coalescedErrs := wait(ctx, joinedResp)
+		errs = append(errs, coalescedErrs...)
+		data = mergeData(data, coalescedData)
+	}
+
 	return
 }
 
 func (f *fetcherWithCache) FetchAccount(ctx context.Context, acccountDefaultJSON json.RawMessage, accountID string) (account json.RawMessage, errs []error) {
 	accountData := f.cache.Accounts.Get(ctx, []string{accountID})
-	// TODO: add metrics
 	if account, ok := accountData[accountID]; ok {
 		f.metricsEngine.RecordAccountCacheResult(metrics.CacheHit, 1)
 		return account, errs
-	} else {
-		f.metricsEngine.RecordAccountCacheResult(metrics.CacheMiss, 1)
 	}
-	account, errs = f.fetcher.FetchAccount(ctx, acccountDefaultJSON, accountID)
+
+	if _, notFoundErrs := f.negativeCache.Accounts.Filter("Account", []string{accountID}); len(notFoundErrs) > 0 {
+		f.metricsEngine.RecordAccountCacheResult(metrics.CacheNegativeHit, 1)
+		return nil, notFoundErrs
+	}
+
+	_, started, joined := f.flights.board("Account", []string{accountID})
+	if len(joined) > 0 {
+		f.metricsEngine.RecordAccountCacheResult(metrics.CacheCoalesced, 1)
+		accountData, errs = wait(ctx, joined)
+		return accountData[accountID], errs
+	}
+
+	f.metricsEngine.RecordAccountCacheResult(metrics.CacheMiss, 1)
+	func() {
+		defer func() {
+			var data map[string]json.RawMessage
+			if len(errs) == 0 {
+				data = map[string]json.RawMessage{accountID: account}
+			}
+			f.flights.land("Account", started, data, errs)
+		}()
+		flightCtx, cancel := f.flightContext(ctx)
+		defer cancel()
+		account, errs = f.fetcher.FetchAccount(flightCtx, acccountDefaultJSON, accountID)
+	}()
 	if len(errs) == 0 {
 		f.cache.Accounts.Save(ctx, map[string]json.RawMessage{accountID: account})
 	}
+	f.negativeCache.Accounts.Add(notFound(started))
 	return account, errs
 }
 
@@ -230,6 +324,20 @@ func (f *fetcherWithCache) FetchCategories(ctx context.Context, primaryAdServer,
 	return "", nil
 }
 
+// flightContext returns the context of a fetch which other requests may wait on. It keeps the values of
+// ctx, but isn't cancelled with it, so that the waiting requests don't fail when the request which started
+// the fetch is cancelled or times out.
+func (f *fetcherWithCache) flightContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	return context.WithTimeout(context.WithoutCancel(ctx), f.timeout)
+}
+
+// recordCacheResult records the cache results other than the hits and misses, only when they occurred
+func recordCacheResult(record func(metrics.CacheResult, int), cacheResult metrics.CacheResult, inc int) {
+	if inc > 0 {
+		record(cacheResult, inc)
+	}
+}
+
 func findLeftovers(ids []string, data map[string]json.RawMessage) (leftovers []string) {
 	leftovers = make([]string, 0, len(ids)-len(data))
 	for _, id := range ids {
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"errors"
 	"testing"
+	"time"
 
 	"github.com/prebid/prebid-server/v3/metrics"
 	"github.com/prebid/prebid-server/v3/stored_requests/caches/nil_cache"
@@ -19,7 +20,7 @@ func setupFetcherWithCacheDeps() (*mockCache, *mockCache, *mockCache, *mockFetch
 	respCache := &mockCache{}
 	metricsEngine := &metrics.MetricsEngineMock{}
 	fetcher := &mockFetcher{}
-	afetcherWithCache := WithCache(fetcher, Cache{reqCache, impCache, respCache, &nil_cache.NilCache{}}, metricsEngine)
+	afetcherWithCache := WithCache(fetcher, Cache{reqCache, impCache, respCache, &nil_cache.NilCache{}}, NegativeCache{}, time.Second, metricsEngine)
 
 	return reqCache, impCache, respCache, fetcher, afetcherWithCache, metricsEngine
 }
@@ -80,7 +81,7 @@ func TestImperfectCache(t *testing.T) {
 			"cached": json.RawMessage(`true`),
 		})
 
-	fetcher.On("FetchRequests", ctx, []string{}, []string{"uncached"}).Return(
+	fetcher.On("FetchRequests", mock.Anything, []string{}, []string{"uncached"}).Return(
 		map[string]json.RawMessage{},
 		map[string]json.RawMessage{
 			"uncached": json.RawMessage(`false`),
@@ -92,7 +93,7 @@ func TestImperfectCache(t *testing.T) {
 			"uncached": json.RawMessage(`false`),
 		})
 
-	fetcher.On("FetchResponses", ctx, []string{"uncached"}).Return(
+	fetcher.On("FetchResponses", mock.Anything, []string{"uncached"}).Return(
 		map[string]json.RawMessage{
 			"uncached": json.RawMessage(`false`),
 		},
@@ -141,7 +142,7 @@ func TestMissingData(t *testing.T) {
 	respCache.On("Get", ctx, []string(respIDs)).Return(
 		map[string]json.RawMessage{})
 
-	fetcher.On("FetchRequests", ctx, []string{}, impIDs).Return(
+	fetcher.On("FetchRequests", mock.Anything, []string{}, impIDs).Return(
 		map[string]json.RawMessage{},
 		map[string]json.RawMessage{},
 		[]error{
@@ -149,7 +150,7 @@ func TestMissingData(t *testing.T) {
 		},
 	)
 
-	fetcher.On("FetchResponses", ctx, respIDs).Return(
+	fetcher.On("FetchResponses", mock.Anything, respIDs).Return(
 		map[string]json.RawMessage{},
 		[]error{
 			errors.New("Data not found"),
@@ -222,11 +223,50 @@ func TestCacheSaves(t *testing.T) {
 	assert.Len(t, fetchRespErrs, 0, "FetchResponses with duplicate IDs shouldn't return an error")
 }
 
+func TestCacheVersionedData(t *testing.T) {
+	reqCache, impCache, _, fetcher, aFetcherWithCache, metricsEngine := setupFetcherWithCacheDeps()
+	reqIDs := []string{"req"}
+	impIDs := []string{"imp"}
+	selection := &VersionSelection{Bucket: 50, Now: time.Now()}
+	ctx := WithVersionSelection(context.Background(), selection)
+
+	versionedReq := json.RawMessage(`{"versions":{"v1":{"tmax":100},"v2":{"tmax":200}},"rollout":{"default":"v1","split":[{"version":"v2","percent":60}]}}`)
+	reqCache.On("Get", ctx, reqIDs).Return(
+		map[string]json.RawMessage{
+			"req": versionedReq,
+		})
+	impCache.On("Get", ctx, impIDs).Return(
+		map[string]json.RawMessage{})
+	fetcher.On("FetchRequests", mock.Anything, []string{}, impIDs).Return(
+		map[string]json.RawMessage{},
+		map[string]json.RawMessage{
+			"imp": json.RawMessage(`{"versions":{"v1":{}},"rollout":{"default":"v2"}}`),
+		},
+		[]error{},
+	)
+	reqCache.On("Save", ctx, map[string]json.RawMessage{})
+	impCache.On("Save", ctx, map[string]json.RawMessage{"imp": json.RawMessage(`{"versions":{"v1":{}},"rollout":{"default":"v2"}}`)})
+	metricsEngine.On("RecordStoredReqCacheResult", metrics.CacheHit, 1)
+	metricsEngine.On("RecordStoredReqCacheResult", metrics.CacheMiss, 0)
+	metricsEngine.On("RecordStoredImpCacheResult", metrics.CacheHit, 0)
+	metricsEngine.On("RecordStoredImpCacheResult", metrics.CacheMiss, 1)
+
+	reqData, impData, errs := WithVersions(aFetcherWithCache).FetchRequests(ctx, reqIDs, impIDs)
+
+	reqCache.AssertExpectations(t)
+	impCache.AssertExpectations(t)
+	fetcher.AssertExpectations(t)
+	assert.JSONEq(t, `{"tmax":200}`, string(reqData["req"]), "FetchRequests should resolve the version of the cached request")
+	assert.Empty(t, impData, "FetchRequests shouldn't return the imps without a valid version")
+	assert.Len(t, errs, 1, "FetchRequests should return an error for the imps without a valid version")
+	assert.Equal(t, map[string]string{"req": "v2"}, selection.Resolved().Requests, "The resolved version should be recorded")
+}
+
 func setupAccountFetcherWithCacheDeps() (*mockCache, *mockFetcher, AllFetcher, *metrics.MetricsEngineMock) {
 	accCache := &mockCache{}
 	metricsEngine := &metrics.MetricsEngineMock{}
 	fetcher := &mockFetcher{}
-	afetcherWithCache := WithCache(fetcher, Cache{&nil_cache.NilCache{}, &nil_cache.NilCache{}, &nil_cache.NilCache{}, accCache}, metricsEngine)
+	afetcherWithCache := WithCache(fetcher, Cache{&nil_cache.NilCache{}, &nil_cache.NilCache{}, &nil_cache.NilCache{}, accCache}, NegativeCache{}, time.Second, metricsEngine)
 
 	return accCache, fetcher, afetcherWithCache, metricsEngine
 }
@@ -263,7 +303,7 @@ func TestAccountCacheMiss(t *testing.T) {
 	// Test read from cache
 	accCache.On("Get", ctx, uncachedAccounts).Return(map[string]json.RawMessage{})
 	accCache.On("Save", ctx, uncachedAccountsData)
-	fetcher.On("FetchAccount", ctx, json.RawMessage("{}"), "uncached").Return(uncachedAccountsData["uncached"], []error{})
+	fetcher.On("FetchAccount", mock.Anything, json.RawMessage("{}"), "uncached").Return(uncachedAccountsData["uncached"], []error{})
 	metricsEngine.On("RecordAccountCacheResult", metrics.CacheMiss, 1)
 
 	account, errs := aFetcherWithCache.FetchAccount(ctx, json.RawMessage("{}"), "uncached")
@@ -274,6 +314,202 @@ func TestAccountCacheMiss(t *testing.T) {
 	assert.JSONEq(t, `true`, string(account), "FetchAccount should fetch the right account data")
 	assert.Len(t, errs, 0, "FetchAccount shouldn't return any errors")
 }
+
+// blockingFetcher is a fetcher whose fetches wait until they're released
+type blockingFetcher struct {
+	mockFetcher
+	started chan struct{}
+	release chan struct{}
+}
+
+func (f *blockingFetcher) FetchRequests(ctx context.Context, requestIDs []string, impIDs []string) (map[string]json.RawMessage, map[string]json.RawMessage, []error) {
+	f.started <- struct{}{}
+	<-f.release
+	return f.mockFetcher.FetchRequests(ctx, requestIDs, impIDs)
+}
+
+func (f *blockingFetcher) FetchAccount(ctx context.Context, defaultAccountsJSON json.RawMessage, accountID string) (json.RawMessage, []error) {
+	f.started <- struct{}{}
+	<-f.release
+	return f.mockFetcher.FetchAccount(ctx, defaultAccountsJSON, accountID)
+}
+
+func TestCoalescedFetchRequests(t *testing.T) {
+	fetcher := &blockingFetcher{started: make(chan struct{}, 1), release: make(chan struct{})}
+	metricsEngine := &metrics.MetricsEngineMock{}
+	aFetcherWithCache := WithCache(fetcher, Cache{&nil_cache.NilCache{}, &nil_cache.NilCache{}, &nil_cache.NilCache{}, &nil_cache.NilCache{}}, NegativeCache{}, time.Second, metricsEngine)
+	ctx := context.Background()
+
+	fetcher.On("FetchRequests", mock.Anything, []string{"req"}, []string{"imp", "missing"}).Return(
+		map[string]json.RawMessage{"req": json.RawMessage(`{"req":true}`)},
+		map[string]json.RawMessage{"imp": json.RawMessage(`{"imp":true}`)},
+		[]error{NotFoundError{ID: "missing", DataType: "Imp"}},
+	).Once()
+	metricsEngine.On("RecordStoredReqCacheResult", metrics.CacheHit, 0)
+	metricsEngine.On("RecordStoredImpCacheResult", metrics.CacheHit, 0)
+	metricsEngine.On("RecordStoredReqCacheResult", metrics.CacheMiss, 1).Once()
+	metricsEngine.On("RecordStoredImpCacheResult", metrics.CacheMiss, 2).Once()
+	metricsEngine.On("RecordStoredReqCacheResult", metrics.CacheMiss, 0).Once()
+	metricsEngine.On("RecordStoredImpCacheResult", metrics.CacheMiss, 0).Once()
+	metricsEngine.On("RecordStoredReqCacheResult", metrics.CacheCoalesced, 1).Once()
+	coalesced := make(chan struct{})
+	metricsEngine.On("RecordStoredImpCacheResult", metrics.CacheCoalesced, 2).Once().Run(func(mock.Arguments) { close(coalesced) })
+
+	type result struct {
+		reqData map[string]json.RawMessage
+		impData map[string]json.RawMessage
+		errs    []error
+	}
+	results := make(chan result, 2)
+	fetch := func() {
+		reqData, impData, errs := aFetcherWithCache.FetchRequests(ctx, []string{"req"}, []string{"imp", "missing"})
+		results <- result{reqData, impData, errs}
+	}
+
+	go fetch()
+	<-fetcher.started
+	go fetch()
+	<-coalesced
+	close(fetcher.release)
+
+	for i := 0; i < 2; i++ {
+		r := <-results
+		assert.Equal(t, map[string]json.RawMessage{"req": json.RawMessage(`{"req":true}`)}, r.reqData, "Both requests should get the request")
+		assert.Equal(t, map[string]json.RawMessage{"imp": json.RawMessage(`{"imp":true}`)}, r.impData, "Both requests should get the imp")
+		assert.Equal(t, []error{NotFoundError{ID: "missing", DataType: "Imp"}}, r.errs, "Both requests should get the not found error")
+	}
+	fetcher.AssertExpectations(t)
+	metricsEngine.AssertExpectations(t)
+}
+
+func TestCoalescedFetchOutlivesCancelledRequest(t *testing.T) {
+	fetcher := &blockingFetcher{started: make(chan struct{}, 1), release: make(chan struct{})}
+	metricsEngine := &metrics.MetricsEngineMock{}
+	aFetcherWithCache := WithCache(fetcher, Cache{&nil_cache.NilCache{}, &nil_cache.NilCache{}, &nil_cache.NilCache{}, &nil_cache.NilCache{}}, NegativeCache{}, time.Second, metricsEngine)
+	leaderCtx, cancelLeader := context.WithCancel(context.Background())
+
+	fetcher.On("FetchAccount", mock.Anything, json.RawMessage("{}"), "account").Return(json.RawMessage(`{"id":"account"}`), []error{}).Once().Run(func(args mock.Arguments) {
+		assert.NoError(t, args.Get(0).(context.Context).Err(), "The fetch should not be cancelled with the request which started it")
+	})
+	metricsEngine.On("RecordAccountCacheResult", metrics.CacheMiss, 1).Once()
+	coalesced := make(chan struct{})
+	metricsEngine.On("RecordAccountCacheResult", metrics.CacheCoalesced, 1).Once().Run(func(mock.Arguments) { close(coalesced) })
+
+	leaderDone := make(chan struct{})
+	go func() {
+		aFetcherWithCache.FetchAccount(leaderCtx, json.RawMessage("{}"), "account")
+		close(leaderDone)
+	}()
+	<-fetcher.started
+
+	accounts := make(chan json.RawMessage, 1)
+	go func() {
+		account, errs := aFetcherWithCache.FetchAccount(context.Background(), json.RawMessage("{}"), "account")
+		assert.Empty(t, errs)
+		accounts <- account
+	}()
+	<-coalesced
+	cancelLeader()
+	close(fetcher.release)
+
+	assert.JSONEq(t, `{"id":"account"}`, string(<-accounts))
+	<-leaderDone
+	fetcher.AssertExpectations(t)
+	metricsEngine.AssertExpectations(t)
+}
+
+func TestCoalescedFetchAccount(t *testing.T) {
+	fetcher := &blockingFetcher{started: make(chan struct{}, 1), release: make(chan struct{})}
+	metricsEngine := &metrics.MetricsEngineMock{}
+	aFetcherWithCache := WithCache(fetcher, Cache{&nil_cache.NilCache{}, &nil_cache.NilCache{}, &nil_cache.NilCache{}, &nil_cache.NilCache{}}, NegativeCache{}, time.Second, metricsEngine)
+	ctx := context.Background()
+
+	fetcher.On("FetchAccount", mock.Anything, json.RawMessage("{}"), "account").Return(json.RawMessage(`{"id":"account"}`), []error{}).Once()
+	metricsEngine.On("RecordAccountCacheResult", metrics.CacheMiss, 1).Once()
+	coalesced := make(chan struct{})
+	metricsEngine.On("RecordAccountCacheResult", metrics.CacheCoalesced, 1).Once().Run(func(mock.Arguments) { close(coalesced) })
+
+	accounts := make(chan json.RawMessage, 2)
+	fetch := func() {
+		account, errs := aFetcherWithCache.FetchAccount(ctx, json.RawMessage("{}"), "account")
+		assert.Empty(t, errs)
+		accounts <- account
+	}
+
+	go fetch()
+	<-fetcher.started
+	go fetch()
+	<-coalesced
+	close(fetcher.release)
+
+	assert.JSONEq(t, `{"id":"account"}`, string(<-accounts))
+	assert.JSONEq(t, `{"id":"account"}`, string(<-accounts))
+	fetcher.AssertExpectations(t)
+	metricsEngine.AssertExpectations(t)
+}
+
+func TestNegativeCache(t *testing.T) {
+	fetcher := &mockFetcher{}
+	metricsEngine := &metrics.MetricsEngineMock{}
+	cache := Cache{&nil_cache.NilCache{}, &nil_cache.NilCache{}, &nil_cache.NilCache{}, &nil_cache.NilCache{}}
+	negativeCache := NewNegativeCache(time.Minute)
+	aFetcherWithCache := WithCache(fetcher, cache, negativeCache, time.Second, metricsEngine)
+	ctx := context.Background()
+	notFoundErrs := []error{NotFoundError{ID: "unknown", DataType: "Account"}}
+
+	fetcher.On("FetchAccount", mock.Anything, json.RawMessage("{}"), "unknown").Return(json.RawMessage(nil), notFoundErrs).Twice()
+	metricsEngine.On("RecordAccountCacheResult", metrics.CacheMiss, 1).Twice()
+	metricsEngine.On("RecordAccountCacheResult", metrics.CacheNegativeHit, 1).Once()
+
+	_, errs := aFetcherWithCache.FetchAccount(ctx, json.RawMessage("{}"), "unknown")
+	assert.Equal(t, notFoundErrs, errs, "The first fetch should reach the backend")
+
+	_, errs = aFetcherWithCache.FetchAccount(ctx, json.RawMessage("{}"), "unknown")
+	assert.Equal(t, notFoundErrs, errs, "The second fetch should be answered by the negative cache")
+
+	// an event saving the account should make the negative cache forget it
+	negativeCache.Compose(cache).Accounts.Save(ctx, map[string]json.RawMessage{"unknown": json.RawMessage(`{}`)})
+	_, errs = aFetcherWithCache.FetchAccount(ctx, json.RawMessage("{}"), "unknown")
+	assert.Equal(t, notFoundErrs, errs, "The fetch after the event should reach the backend")
+
+	fetcher.AssertExpectations(t)
+	metricsEngine.AssertExpectations(t)
+}
+
+func TestNegativeCacheRequests(t *testing.T) {
+	fetcher := &mockFetcher{}
+	metricsEngine := &metrics.MetricsEngineMock{}
+	aFetcherWithCache := WithCache(fetcher, Cache{&nil_cache.NilCache{}, &nil_cache.NilCache{}, &nil_cache.NilCache{}, &nil_cache.NilCache{}}, NewNegativeCache(time.Minute), time.Second, metricsEngine)
+	ctx := context.Background()
+
+	fetcher.On("FetchRequests", mock.Anything, []string{"unknown"}, []string{"failed"}).Return(
+		map[string]json.RawMessage{},
+		map[string]json.RawMessage{},
+		[]error{NotFoundError{ID: "unknown", DataType: "Request"}, errors.New("timeout")},
+	).Once()
+	fetcher.On("FetchRequests", mock.Anything, []string{}, []string{"failed"}).Return(
+		map[string]json.RawMessage{},
+		map[string]json.RawMessage{"failed": json.RawMessage(`{}`)},
+		[]error{},
+	).Once()
+	metricsEngine.On("RecordStoredReqCacheResult", metrics.CacheHit, 0)
+	metricsEngine.On("RecordStoredImpCacheResult", metrics.CacheHit, 0)
+	metricsEngine.On("RecordStoredReqCacheResult", metrics.CacheMiss, 1).Once()
+	metricsEngine.On("RecordStoredReqCacheResult", metrics.CacheMiss, 0).Once()
+	metricsEngine.On("RecordStoredImpCacheResult", metrics.CacheMiss, 1).Twice()
+	metricsEngine.On("RecordStoredReqCacheResult", metrics.CacheNegativeHit, 1).Once()
+
+	_, _, errs := aFetcherWithCache.FetchRequests(ctx, []string{"unknown"}, []string{"failed"})
+	assert.Len(t, errs, 2)
+
+	_, impData, errs := aFetcherWithCache.FetchRequests(ctx, []string{"unknown"}, []string{"failed"})
+	assert.Equal(t, []error{NotFoundError{ID: "unknown", DataType: "Request"}}, errs, "Only the IDs which weren't found should be cached")
+	assert.Equal(t, map[string]json.RawMessage{"failed": json.RawMessage(`{}`)}, impData)
+
+	fetcher.AssertExpectations(t)
+	metricsEngine.AssertExpectations(t)
+}
+
 func TestComposedCache(t *testing.T) {
 	c1 := &mockCache{}
 	c2 := &mockCache{}
@@ -287,7 +523,7 @@ func TestComposedCache(t *testing.T) {
 	}
 	metricsEngine := &metrics.MetricsEngineMock{}
 	fetcher := &mockFetcher{}
-	aFetcherWithCache := WithCache(fetcher, cache, metricsEngine)
+	aFetcherWithCache := WithCache(fetcher, cache, NegativeCache{}, time.Second, metricsEngine)
 	reqIDs := []string{"1", "2", "3"}
 	impIDs := []string{}
 	ctx := context.Background()
@@ -306,6 +542,11 @@ func TestComposedCache(t *testing.T) {
 		})
 	impCache.On("Get", ctx, []string{}).Return(map[string]json.RawMessage{})
 
+	// the values found in a cache are saved in the caches before it
+	c1.On("Save", ctx, map[string]json.RawMessage{"2": json.RawMessage(`{"id": "2"}`)})
+	c1.On("Save", ctx, map[string]json.RawMessage{"3": json.RawMessage(`{"id": "3"}`)})
+	c2.On("Save", ctx, map[string]json.RawMessage{"3": json.RawMessage(`{"id": "3"}`)})
+
 	metricsEngine.On("RecordStoredReqCacheResult", metrics.CacheHit, 3)
 	metricsEngine.On("RecordStoredReqCacheResult", metrics.CacheMiss, 0)
 	metricsEngine.On("RecordStoredImpCacheResult", metrics.CacheHit, 0)
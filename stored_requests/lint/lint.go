@@ -0,0 +1,310 @@
+// Package lint validates all the stored data of a backend offline, so that the broken entries are found before
+// the auctions which use them.
+package lint
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/buger/jsonparser"
+	"github.com/prebid/openrtb/v20/openrtb2"
+	jsonpatch "gopkg.in/evanphx/json-patch.v5"
+
+	"github.com/prebid/prebid-server/v3/bidadjustment"
+	"github.com/prebid/prebid-server/v3/config"
+	"github.com/prebid/prebid-server/v3/floors"
+	"github.com/prebid/prebid-server/v3/openrtb_ext"
+	"github.com/prebid/prebid-server/v3/stored_requests/management"
+	"github.com/prebid/prebid-server/v3/stored_requests/templates"
+	"github.com/prebid/prebid-server/v3/util/jsonutil"
+)
+
+// The stored requests of the AMP and video endpoints, which are reported apart from those of the auction endpoint
+const (
+	AMPRequestDataType   management.DataType = "amp_requests"
+	VideoRequestDataType management.DataType = "video_requests"
+)
+
+// Data is the stored data of each type, by ID
+type Data struct {
+	Requests      map[string]json.RawMessage
+	AMPRequests   map[string]json.RawMessage
+	VideoRequests map[string]json.RawMessage
+	Imps          map[string]json.RawMessage
+	Responses     map[string]json.RawMessage
+	Accounts      map[string]json.RawMessage
+}
+
+// Issue is an error of a stored entry
+type Issue struct {
+	DataType management.DataType `json:"type"`
+	ID       string              `json:"id"`
+	Message  string              `json:"message"`
+}
+
+// Report is the result of a lint. It's written as JSON.
+type Report struct {
+	Checked Checked `json:"checked"`
+	Issues  []Issue `json:"issues"`
+}
+
+// Checked is the number of entries of each type which were checked
+type Checked struct {
+	Requests      int `json:"requests"`
+	AMPRequests   int `json:"amp_requests"`
+	VideoRequests int `json:"video_requests"`
+	Imps          int `json:"imps"`
+	Responses     int `json:"responses"`
+	Accounts      int `json:"accounts"`
+}
+
+// Linter validates the stored data as the auctions use it: the stored requests are merged with the stored imps
+// they reference, and the accounts with the account defaults.
+type Linter struct {
+	validator           *management.Validator
+	accountDefaultsJSON json.RawMessage
+	accountDefaults     config.Account
+}
+
+// NewLinter returns a Linter which validates the stored data with the validator. The floors rules are validated
+// against the accountDefaults, whose JSON is merged into the stored accounts.
+func NewLinter(validator *management.Validator, accountDefaults config.Account, accountDefaultsJSON json.RawMessage) *Linter {
+	return &Linter{
+		validator:           validator,
+		accountDefaultsJSON: accountDefaultsJSON,
+		accountDefaults:     accountDefaults,
+	}
+}
+
+// Lint returns the issues of every entry of data
+func (l *Linter) Lint(data Data) Report {
+	report := Report{
+		Checked: Checked{
+			Requests:      len(data.Requests),
+			AMPRequests:   len(data.AMPRequests),
+			VideoRequests: len(data.VideoRequests),
+			Imps:          len(data.Imps),
+			Responses:     len(data.Responses),
+			Accounts:      len(data.Accounts),
+		},
+		Issues: []Issue{},
+	}
+	add := func(dataType management.DataType, id string, errs []error) {
+		for _, err := range errs {
+			report.Issues = append(report.Issues, Issue{DataType: dataType, ID: id, Message: err.Error()})
+		}
+	}
+
+	for id, request := range data.Requests {
+		errs := l.validator.Validate(management.RequestDataType, id, request)
+		if len(errs) == 0 {
+			errs = l.lintRequest(request, data.Imps)
+		}
+		add(management.RequestDataType, id, errs)
+	}
+	for id, request := range data.AMPRequests {
+		errs := l.validator.Validate(management.RequestDataType, id, request)
+		if len(errs) == 0 {
+			errs = l.lintAMPRequest(request)
+		}
+		add(AMPRequestDataType, id, errs)
+	}
+	for id, request := range data.VideoRequests {
+		add(VideoRequestDataType, id, lintVideoRequest(request))
+	}
+	for id, imp := range data.Imps {
+		add(management.ImpDataType, id, l.validator.Validate(management.ImpDataType, id, imp))
+	}
+	for id, response := range data.Responses {
+		add(management.ResponseDataType, id, l.validator.Validate(management.ResponseDataType, id, response))
+	}
+	for id, account := range data.Accounts {
+		errs := l.validator.Validate(management.AccountDataType, id, account)
+		if len(errs) == 0 {
+			errs = l.lintAccount(account)
+		}
+		add(management.AccountDataType, id, errs)
+	}
+
+	sort.Slice(report.Issues, func(i, j int) bool {
+		a, b := report.Issues[i], report.Issues[j]
+		if a.DataType != b.DataType {
+			return a.DataType < b.DataType
+		}
+		if a.ID != b.ID {
+			return a.ID < b.ID
+		}
+		return a.Message < b.Message
+	})
+	return report
+}
+
+// lintRequest validates the imps of every version of a stored request merged with the stored imps they
+// reference, and its floors and bid adjustments
+func (l *Linter) lintRequest(request json.RawMessage, storedImps map[string]json.RawMessage) []error {
+	var errs []error
+	for version, data := range versions(request) {
+		prefix := versionPrefix(version)
+		bidRequest, err := parseRequest(data)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s%v", prefix, err))
+			continue
+		}
+
+		// the imps are merged as stored, so that the validator skips the bidders whose params are templated
+		index := 0
+		jsonparser.ArrayEach(data, func(imp []byte, _ jsonparser.ValueType, _ int, _ error) {
+			for _, err := range l.lintImp(index, imp, storedImps) {
+				errs = append(errs, fmt.Errorf("%s%v", prefix, err))
+			}
+			index++
+		}, "imp")
+
+		for _, err := range l.lintRequestExt(bidRequest) {
+			errs = append(errs, fmt.Errorf("%s%v", prefix, err))
+		}
+	}
+	return errs
+}
+
+// lintAMPRequest validates every version of a stored AMP request as the AMP endpoint uses it: it's the whole
+// request, so it has a single imp, no app, and its stored imps aren't merged
+func (l *Linter) lintAMPRequest(request json.RawMessage) []error {
+	var errs []error
+	for version, data := range versions(request) {
+		prefix := versionPrefix(version)
+		bidRequest, err := parseRequest(data)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s%v", prefix, err))
+			continue
+		}
+
+		if len(bidRequest.Imp) != 1 {
+			errs = append(errs, fmt.Errorf("%sthe AMP request has %d imps, but exactly one is required", prefix, len(bidRequest.Imp)))
+		}
+		if bidRequest.App != nil {
+			errs = append(errs, fmt.Errorf("%srequest.app must not exist in AMP stored requests", prefix))
+		}
+		for _, err := range l.lintRequestExt(bidRequest) {
+			errs = append(errs, fmt.Errorf("%s%v", prefix, err))
+		}
+	}
+	return errs
+}
+
+// lintVideoRequest validates that every version of a stored video request is a video request
+func lintVideoRequest(request json.RawMessage) []error {
+	var errs []error
+	for version, data := range versions(request) {
+		var videoRequest openrtb_ext.BidRequestVideo
+		if err := jsonutil.UnmarshalValid(renderDefaults(data), &videoRequest); err != nil {
+			errs = append(errs, fmt.Errorf("%s%v", versionPrefix(version), err))
+		}
+	}
+	return errs
+}
+
+// lintRequestExt validates the floors and bid adjustments of a stored request
+func (l *Linter) lintRequestExt(bidRequest openrtb2.BidRequest) []error {
+	if len(bidRequest.Ext) == 0 {
+		return nil
+	}
+	var requestExt openrtb_ext.ExtRequest
+	if err := jsonutil.UnmarshalValid(bidRequest.Ext, &requestExt); err != nil {
+		return []error{fmt.Errorf("request.ext is invalid: %v", err)}
+	}
+
+	var errs []error
+	for _, err := range floors.Validate(requestExt.Prebid.Floors, l.accountDefaults) {
+		errs = append(errs, fmt.Errorf("ext.prebid.floors: %v", err))
+	}
+	if !bidadjustment.Validate(requestExt.Prebid.BidAdjustments) {
+		errs = append(errs, errors.New("ext.prebid.bidadjustments is invalid"))
+	}
+	return errs
+}
+
+// lintImp validates an imp of a stored request merged with every version of the stored imp it references
+func (l *Linter) lintImp(index int, imp json.RawMessage, storedImps map[string]json.RawMessage) []error {
+	storedImpID, err := jsonparser.GetString(imp, "ext", "prebid", "storedrequest", "id")
+	if err != nil || storedImpID == "" {
+		return nil
+	}
+	storedImp, ok := storedImps[storedImpID]
+	if !ok {
+		return []error{fmt.Errorf("imp[%d].ext.prebid.storedrequest.id %s doesn't exist", index, storedImpID)}
+	}
+
+	var errs []error
+	for version, data := range versions(storedImp) {
+		prefix := fmt.Sprintf("imp[%d] merged with the stored imp %s", index, storedImpID)
+		if version != "" {
+			prefix += fmt.Sprintf(" version %s", version)
+		}
+		merged, err := jsonpatch.MergePatch(data, imp)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %v", prefix, err))
+			continue
+		}
+		for _, err := range l.validator.Validate(management.ImpDataType, storedImpID, merged) {
+			errs = append(errs, fmt.Errorf("%s: %v", prefix, err))
+		}
+	}
+	return errs
+}
+
+// lintAccount validates the account merged with the account defaults
+func (l *Linter) lintAccount(accountJSON json.RawMessage) []error {
+	merged := accountJSON
+	if len(l.accountDefaultsJSON) > 0 {
+		var err error
+		if merged, err = jsonpatch.MergePatch(l.accountDefaultsJSON, accountJSON); err != nil {
+			return []error{fmt.Errorf("the account config can't be merged with the account defaults: %v", err)}
+		}
+	}
+
+	var account config.Account
+	if err := jsonutil.UnmarshalValid(merged, &account); err != nil {
+		return []error{fmt.Errorf("the account config merged with the account defaults is malformed: %v", err)}
+	}
+	if !bidadjustment.Validate(account.BidAdjustments) {
+		return []error{errors.New("bidadjustments is invalid")}
+	}
+	return nil
+}
+
+// parseRequest parses a version of a stored request. The placeholders of a template are replaced by their
+// defaults, as they can be of any type until the template is rendered.
+func parseRequest(data json.RawMessage) (openrtb2.BidRequest, error) {
+	var bidRequest openrtb2.BidRequest
+	err := jsonutil.UnmarshalValid(renderDefaults(data), &bidRequest)
+	return bidRequest, err
+}
+
+// renderDefaults returns data with the placeholders of a template replaced by their defaults
+func renderDefaults(data json.RawMessage) json.RawMessage {
+	if template := templates.Parse(data); template != nil {
+		return template.RenderDefaults()
+	}
+	return data
+}
+
+func versionPrefix(version string) string {
+	if version == "" {
+		return ""
+	}
+	return fmt.Sprintf("version %s: ", version)
+}
+
+// versions returns the versions of a versioned stored request or imp, or else the data as the "" version
+func versions(data json.RawMessage) map[string]json.RawMessage {
+	var versioned struct {
+		Versions map[string]json.RawMessage `json:"versions"`
+	}
+	if err := jsonutil.Unmarshal(data, &versioned); err == nil && versioned.Versions != nil {
+		return versioned.Versions
+	}
+	return map[string]json.RawMessage{"": data}
+}
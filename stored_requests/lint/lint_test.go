@@ -0,0 +1,86 @@
+package lint
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/prebid/prebid-server/v3/config"
+	"github.com/prebid/prebid-server/v3/openrtb_ext"
+	"github.com/prebid/prebid-server/v3/ortb"
+	"github.com/prebid/prebid-server/v3/stored_requests/management"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestLinter(t *testing.T) *Linter {
+	paramsValidator, err := openrtb_ext.NewBidderParamsValidator("../../static/bidder-params")
+	require.NoError(t, err)
+	bidderMap := openrtb_ext.BuildBidderMap()
+	validator := management.NewValidator(ortb.NewRequestValidator(bidderMap, map[string]string{}, paramsValidator), paramsValidator, bidderMap)
+	return NewLinter(validator, config.Account{}, json.RawMessage(`{"disabled":false}`))
+}
+
+func TestLint(t *testing.T) {
+	data := Data{
+		Requests: map[string]json.RawMessage{
+			"valid":           json.RawMessage(`{"imp":[{"id":"1","ext":{"prebid":{"storedrequest":{"id":"banner"}}}}]}`),
+			"missing-imp":     json.RawMessage(`{"imp":[{"id":"1","ext":{"prebid":{"storedrequest":{"id":"unknown"}}}}]}`),
+			"invalid-merge":   json.RawMessage(`{"imp":[{"id":"1","banner":{"format":[{"w":-1,"h":250}]},"ext":{"prebid":{"storedrequest":{"id":"banner"}}}}]}`),
+			"invalid-floors":  json.RawMessage(`{"ext":{"prebid":{"floors":{"floormin":-1}}}}`),
+			"invalid-bidadj":  json.RawMessage(`{"ext":{"prebid":{"bidadjustments":{"mediatype":{"banner":{"appnexus":{"*":[{"adjtype":"multiplier","value":-1}]}}}}}}}`),
+			"versioned":       json.RawMessage(`{"versions":{"v1":{"imp":[{"id":"1","ext":{"prebid":{"storedrequest":{"id":"unknown"}}}}]}}}`),
+			"malformed-json":  json.RawMessage(`{`),
+			"template-params": json.RawMessage(`{"tmax":"{{ext.prebid.storedrequest.params.tmax}}"}`),
+			"template-floors": json.RawMessage(`{"ext":{"prebid":{"floors":{"floormin":"{{ext.prebid.storedrequest.params.floor|-1}}"}}}}`),
+			"template-bidder": json.RawMessage(`{"imp":[{"id":"1","ext":{"prebid":{"bidder":{"appnexus":{"placementId":"{{ext.placementId}}"}},"storedrequest":{"id":"banner"}}}}]}`),
+		},
+		AMPRequests: map[string]json.RawMessage{
+			"valid":       json.RawMessage(`{"imp":[{"id":"1","banner":{"format":[{"w":300,"h":250}]},"ext":{"prebid":{"bidder":{"appnexus":{"placementId":12345}}}}}]}`),
+			"missing-imp": json.RawMessage(`{"site":{"page":"prebid.org"}}`),
+			"app":         json.RawMessage(`{"app":{"bundle":"org.prebid"},"imp":[{"id":"1","banner":{"format":[{"w":300,"h":250}]}}]}`),
+		},
+		VideoRequests: map[string]json.RawMessage{
+			"valid":     json.RawMessage(`{"accountid":"11223344","site":{"page":"{{site.page|prebid.org}}"}}`),
+			"malformed": json.RawMessage(`{"podconfig":"pods"}`),
+		},
+		Imps: map[string]json.RawMessage{
+			"banner": json.RawMessage(`{"banner":{"format":[{"w":300,"h":250}]},"ext":{"prebid":{"bidder":{"appnexus":{"placementId":12345}}}}}`),
+		},
+		Responses: map[string]json.RawMessage{
+			"response": json.RawMessage(`[{"bidder":"appnexus"}]`),
+		},
+		Accounts: map[string]json.RawMessage{
+			"valid":     json.RawMessage(`{"id":"valid"}`),
+			"malformed": json.RawMessage(`{"disabled":"yes"}`),
+		},
+	}
+
+	report := newTestLinter(t).Lint(data)
+
+	assert.Equal(t, Checked{Requests: 10, AMPRequests: 3, VideoRequests: 2, Imps: 1, Responses: 1, Accounts: 2}, report.Checked)
+	issues := make(map[string][]string)
+	for _, issue := range report.Issues {
+		key := string(issue.DataType) + "/" + issue.ID
+		issues[key] = append(issues[key], issue.Message)
+	}
+	assert.Len(t, issues, 11, "Every invalid entry should have issues")
+	assert.Equal(t, []string{"imp[0].ext.prebid.storedrequest.id unknown doesn't exist"}, issues["requests/missing-imp"])
+	assert.Equal(t, []string{"imp[0] merged with the stored imp banner: request.imp[0].banner.format[0].w must be a positive number"}, issues["requests/invalid-merge"])
+	assert.Equal(t, []string{"ext.prebid.floors: Invalid FloorMin = '-1', value should be >= 0"}, issues["requests/invalid-floors"])
+	assert.Equal(t, []string{"ext.prebid.bidadjustments is invalid"}, issues["requests/invalid-bidadj"])
+	assert.Equal(t, []string{"version v1: imp[0].ext.prebid.storedrequest.id unknown doesn't exist"}, issues["requests/versioned"])
+	assert.Equal(t, []string{"the stored data isn't valid JSON"}, issues["requests/malformed-json"])
+	assert.Equal(t, []string{"ext.prebid.floors: Invalid FloorMin = '-1', value should be >= 0"}, issues["requests/template-floors"], "The templates should be validated with their defaults")
+	assert.Equal(t, []string{"the AMP request has 0 imps, but exactly one is required"}, issues["amp_requests/missing-imp"])
+	assert.Equal(t, []string{"request.app must not exist in AMP stored requests"}, issues["amp_requests/app"])
+	assert.Len(t, issues["video_requests/malformed"], 1)
+	assert.Len(t, issues["accounts/malformed"], 1)
+}
+
+func TestLintAccountDefaults(t *testing.T) {
+	linter := newTestLinter(t)
+	linter.accountDefaultsJSON = json.RawMessage(`{"bidadjustments":{"mediatype":{"banner":{"appnexus":{"*":[{"adjtype":"multiplier","value":-1}]}}}}}`)
+
+	report := linter.Lint(Data{Accounts: map[string]json.RawMessage{"account": json.RawMessage(`{}`)}})
+	assert.Equal(t, []Issue{{DataType: management.AccountDataType, ID: "account", Message: "bidadjustments is invalid"}}, report.Issues, "The accounts should be merged with the account defaults")
+}
@@ -0,0 +1,150 @@
+package lint
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/prebid/prebid-server/v3/config"
+	"github.com/prebid/prebid-server/v3/stored_requests/backends/db_provider"
+	"github.com/prebid/prebid-server/v3/stored_requests/backends/file_fetcher"
+)
+
+// LoadFiles reads the stored data of a directory of the filesystem backend. Its stored_requests, stored_imps,
+// stored_responses and accounts sub-directories hold a {id}.json file for each entry.
+func LoadFiles(directory string) (Data, error) {
+	fileSystem, err := file_fetcher.NewFileSystem(directory)
+	if err != nil {
+		return Data{}, err
+	}
+	return Data{
+		Requests:  fileSystem.Directories["stored_requests"].Files,
+		Imps:      fileSystem.Directories["stored_imps"].Files,
+		Responses: fileSystem.Directories["stored_responses"].Files,
+		Accounts:  fileSystem.Directories["accounts"].Files,
+	}, nil
+}
+
+// LoadDatabase runs a query which returns the id, data and type of all the stored data, like the
+// database.initialize_caches.query of the database backend. The types are request, imp, response or account.
+func LoadDatabase(ctx context.Context, provider db_provider.DbProvider, query string) (Data, error) {
+	rows, err := provider.QueryContext(ctx, query)
+	if err != nil {
+		return Data{}, err
+	}
+	defer rows.Close()
+
+	data := Data{
+		Requests:  make(map[string]json.RawMessage),
+		Imps:      make(map[string]json.RawMessage),
+		Responses: make(map[string]json.RawMessage),
+		Accounts:  make(map[string]json.RawMessage),
+	}
+	for rows.Next() {
+		var id, dataType string
+		var value []byte
+		if err := rows.Scan(&id, &value, &dataType); err != nil {
+			return Data{}, err
+		}
+		// the deleted entries are NULL
+		if value == nil {
+			continue
+		}
+
+		switch dataType {
+		case "request":
+			data.Requests[id] = value
+		case "imp":
+			data.Imps[id] = value
+		case "response":
+			data.Responses[id] = value
+		case "account":
+			data.Accounts[id] = value
+		default:
+			return Data{}, fmt.Errorf("the stored data %s has an invalid type %s", id, dataType)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return Data{}, err
+	}
+	return data, nil
+}
+
+// Merge adds the entries of other to the data. The entries of other replace those with the same ID.
+func (d *Data) Merge(other Data) {
+	merge := func(data *map[string]json.RawMessage, otherData map[string]json.RawMessage) {
+		if *data == nil {
+			*data = make(map[string]json.RawMessage, len(otherData))
+		}
+		for id, value := range otherData {
+			(*data)[id] = value
+		}
+	}
+	merge(&d.Requests, other.Requests)
+	merge(&d.AMPRequests, other.AMPRequests)
+	merge(&d.VideoRequests, other.VideoRequests)
+	merge(&d.Imps, other.Imps)
+	merge(&d.Responses, other.Responses)
+	merge(&d.Accounts, other.Accounts)
+}
+
+// forSection returns the data the config section of the dataType serves. The AMP and video endpoints read their
+// stored requests from their own sections, so they're kept apart from those of the auction endpoint, whose IDs may
+// be the same.
+func (d Data) forSection(dataType config.DataType) Data {
+	switch dataType {
+	case config.RequestDataType:
+		return Data{Requests: d.Requests, Imps: d.Imps}
+	case config.AMPRequestDataType:
+		return Data{AMPRequests: d.Requests}
+	case config.VideoDataType:
+		return Data{VideoRequests: d.Requests}
+	case config.ResponseDataType:
+		return Data{Responses: d.Responses}
+	case config.AccountDataType:
+		return Data{Accounts: d.Accounts}
+	}
+	return Data{}
+}
+
+// Load reads the stored data of the filesystem and database backends of the config sections. Each section only
+// adds the type of data it serves. The database backends run their database.initialize_caches.query, unless
+// dbQuery is set.
+func Load(ctx context.Context, sections []*config.StoredRequests, dbQuery string) (Data, error) {
+	var data Data
+	loaded := false
+	for _, section := range sections {
+		if section.Files.Enabled {
+			files, err := LoadFiles(section.Files.Path)
+			if err != nil {
+				return Data{}, fmt.Errorf("%s.filesystem: %v", section.Section(), err)
+			}
+			data.Merge(files.forSection(section.DataType()))
+			loaded = true
+		}
+
+		if section.Database.ConnectionInfo.Database != "" {
+			query := dbQuery
+			if query == "" {
+				query = section.Database.CacheInitialization.Query
+			}
+			if query == "" {
+				return Data{}, fmt.Errorf("%s.database: the initialize_caches.query or a query which lists all the stored data is required", section.Section())
+			}
+			provider := db_provider.NewDbProvider(section.DataType(), section.Database.ConnectionInfo)
+			rows, err := LoadDatabase(ctx, provider, query)
+			provider.Close()
+			if err != nil {
+				return Data{}, fmt.Errorf("%s.database: %v", section.Section(), err)
+			}
+			data.Merge(rows.forSection(section.DataType()))
+			loaded = true
+		}
+	}
+
+	if !loaded {
+		return Data{}, errors.New("no filesystem or database backend of the stored data is configured")
+	}
+	return data, nil
+}
@@ -0,0 +1,83 @@
+package lint
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/prebid/prebid-server/v3/config"
+	"github.com/prebid/prebid-server/v3/stored_requests/backends/db_provider"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadFiles(t *testing.T) {
+	directory := t.TempDir()
+	for _, file := range []struct{ dir, id, data string }{
+		{"stored_requests", "request", `{"id":"request"}`},
+		{"stored_imps", "imp", `{"banner":{}}`},
+		{"accounts", "account", `{`},
+	} {
+		require.NoError(t, os.MkdirAll(filepath.Join(directory, file.dir), 0755))
+		require.NoError(t, os.WriteFile(filepath.Join(directory, file.dir, file.id+".json"), []byte(file.data), 0644))
+	}
+
+	data, err := LoadFiles(directory)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]json.RawMessage{"request": json.RawMessage(`{"id":"request"}`)}, data.Requests)
+	assert.Equal(t, map[string]json.RawMessage{"imp": json.RawMessage(`{"banner":{}}`)}, data.Imps)
+	assert.Empty(t, data.Responses)
+	assert.Equal(t, map[string]json.RawMessage{"account": json.RawMessage(`{`)}, data.Accounts, "The files should be loaded without being parsed")
+
+	_, err = LoadFiles(filepath.Join(directory, "missing"))
+	assert.Error(t, err)
+}
+
+func TestLoadDatabase(t *testing.T) {
+	provider, dbMock, err := db_provider.NewDbProviderMock()
+	require.NoError(t, err)
+	query := "SELECT id, data, 'request' AS type FROM stored_requests"
+
+	dbMock.ExpectQuery(query).WillReturnRows(sqlmock.NewRows([]string{"id", "data", "type"}).
+		AddRow("request", []byte(`{"id":"request"}`), "request").
+		AddRow("deleted", nil, "imp").
+		AddRow("account", []byte(`{}`), "account"))
+	data, err := LoadDatabase(context.Background(), provider, query)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]json.RawMessage{"request": json.RawMessage(`{"id":"request"}`)}, data.Requests)
+	assert.Empty(t, data.Imps, "The deleted entries should be skipped")
+	assert.Equal(t, map[string]json.RawMessage{"account": json.RawMessage(`{}`)}, data.Accounts)
+
+	dbMock.ExpectQuery(query).WillReturnRows(sqlmock.NewRows([]string{"id", "data", "type"}).AddRow("category", []byte(`{}`), "category"))
+	_, err = LoadDatabase(context.Background(), provider, query)
+	assert.EqualError(t, err, "the stored data category has an invalid type category")
+	assert.NoError(t, dbMock.ExpectationsWereMet())
+}
+
+func TestLoad(t *testing.T) {
+	directory := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(directory, "accounts"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(directory, "accounts", "account.json"), []byte(`{}`), 0644))
+
+	require.NoError(t, os.MkdirAll(filepath.Join(directory, "stored_requests"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(directory, "stored_requests", "request.json"), []byte(`{"id":"request"}`), 0644))
+
+	accounts := &config.StoredRequests{Files: config.FileFetcherConfig{Enabled: true, Path: directory}}
+	accounts.SetDataType(config.AccountDataType)
+	amp := &config.StoredRequests{Files: config.FileFetcherConfig{Enabled: true, Path: directory}}
+	amp.SetDataType(config.AMPRequestDataType)
+	data, err := Load(context.Background(), []*config.StoredRequests{{}, accounts, amp}, "")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]json.RawMessage{"account": json.RawMessage(`{}`)}, data.Accounts)
+	assert.Empty(t, data.Requests, "The sections should only add the type of data they serve")
+	assert.Equal(t, map[string]json.RawMessage{"request": json.RawMessage(`{"id":"request"}`)}, data.AMPRequests, "The AMP requests should be kept apart")
+
+	_, err = Load(context.Background(), []*config.StoredRequests{{}}, "")
+	assert.EqualError(t, err, "no filesystem or database backend of the stored data is configured")
+
+	_, err = Load(context.Background(), []*config.StoredRequests{{Database: config.DatabaseConfig{ConnectionInfo: config.DatabaseConnection{Database: "prebid"}}}}, "")
+	assert.ErrorContains(t, err, "initialize_caches.query")
+}
@@ -0,0 +1,277 @@
+package management
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/prebid/prebid-server/v3/config"
+	"github.com/prebid/prebid-server/v3/stored_requests/backends/db_provider"
+)
+
+// DatabaseStore persists the stored data in the tables read by the database backends. A delete keeps the
+// row with a null data and a new last_updated, so that the database event producers invalidate the entry
+// in the caches of every instance.
+type DatabaseStore struct {
+	provider db_provider.DbProvider
+	tables   map[DataType]string
+	audit    string
+	now      func() time.Time
+}
+
+// NewDatabaseStore returns a DatabaseStore which writes to the configured tables
+func NewDatabaseStore(provider db_provider.DbProvider, tables config.StoredDataTables) *DatabaseStore {
+	return &DatabaseStore{
+		provider: provider,
+		tables: map[DataType]string{
+			RequestDataType:  tables.Requests,
+			ImpDataType:      tables.Imps,
+			ResponseDataType: tables.Responses,
+			AccountDataType:  tables.Accounts,
+		},
+		audit: tables.Audit,
+		now:   time.Now,
+	}
+}
+
+func (s *DatabaseStore) Get(ctx context.Context, dataType DataType, id string) (Entry, error) {
+	table, err := s.table(dataType)
+	if err != nil {
+		return Entry{}, err
+	}
+
+	rows, err := s.provider.QueryContext(ctx, "SELECT data, version FROM "+table+" WHERE id = $STORED_ID",
+		db_provider.QueryParam{Name: "STORED_ID", Value: id})
+	if err != nil {
+		return Entry{}, err
+	}
+	defer rows.Close()
+
+	entry := Entry{ID: id}
+	if !rows.Next() {
+		if err := rows.Err(); err != nil {
+			return Entry{}, err
+		}
+		return Entry{}, ErrNotFound
+	}
+	var data []byte
+	if err := rows.Scan(&data, &entry.Version); err != nil {
+		return Entry{}, err
+	}
+	if data == nil {
+		return Entry{}, ErrNotFound
+	}
+	entry.Data = data
+	return entry, nil
+}
+
+func (s *DatabaseStore) Put(ctx context.Context, dataType DataType, id string, data json.RawMessage, expectedVersion int, author string) (Entry, error) {
+	entry := Entry{ID: id, Data: data}
+	err := s.change(ctx, dataType, id, func(tx *sql.Tx, table string, currentVersion int, exists bool, found bool) (AuditRecord, error) {
+		version, err := nextVersion(currentVersion, exists, expectedVersion)
+		if err != nil {
+			return AuditRecord{}, err
+		}
+		entry.Version = version
+
+		action := ActionUpdate
+		if !exists {
+			action = ActionCreate
+		}
+		params := []db_provider.QueryParam{
+			{Name: "STORED_ID", Value: id},
+			{Name: "STORED_DATA", Value: string(data)},
+			{Name: "NEW_VERSION", Value: version},
+			{Name: "UPDATED_AT", Value: s.now().UTC()},
+		}
+		if found {
+			err = s.update(ctx, tx, "UPDATE "+table+" SET data = $STORED_DATA, version = $NEW_VERSION, last_updated = $UPDATED_AT WHERE id = $STORED_ID AND version = $CUR_VERSION",
+				append(params, db_provider.QueryParam{Name: "CUR_VERSION", Value: currentVersion}))
+		} else {
+			err = s.exec(ctx, tx, "INSERT INTO "+table+" (id, data, version, last_updated) VALUES ($STORED_ID, $STORED_DATA, $NEW_VERSION, $UPDATED_AT)", params)
+		}
+		return AuditRecord{Version: version, Action: action, Data: data, Author: author}, err
+	})
+	if err != nil {
+		return Entry{}, err
+	}
+	return entry, nil
+}
+
+func (s *DatabaseStore) Delete(ctx context.Context, dataType DataType, id string, expectedVersion int, author string) error {
+	return s.change(ctx, dataType, id, func(tx *sql.Tx, table string, currentVersion int, exists bool, found bool) (AuditRecord, error) {
+		if !exists {
+			return AuditRecord{}, ErrNotFound
+		}
+		version, err := nextVersion(currentVersion, exists, expectedVersion)
+		if err != nil {
+			return AuditRecord{}, err
+		}
+
+		err = s.update(ctx, tx, "UPDATE "+table+" SET data = NULL, version = $NEW_VERSION, last_updated = $UPDATED_AT WHERE id = $STORED_ID AND version = $CUR_VERSION", []db_provider.QueryParam{
+			{Name: "STORED_ID", Value: id},
+			{Name: "NEW_VERSION", Value: version},
+			{Name: "UPDATED_AT", Value: s.now().UTC()},
+			{Name: "CUR_VERSION", Value: currentVersion},
+		})
+		return AuditRecord{Version: version, Action: ActionDelete, Author: author}, err
+	})
+}
+
+func (s *DatabaseStore) Audit(ctx context.Context, dataType DataType, id string) ([]AuditRecord, error) {
+	if _, err := s.table(dataType); err != nil {
+		return nil, err
+	}
+
+	rows, err := s.provider.QueryContext(ctx, "SELECT version, action, data, author, changed_at FROM "+s.audit+" WHERE data_type = $DATA_TYPE AND id = $STORED_ID ORDER BY version",
+		db_provider.QueryParam{Name: "DATA_TYPE", Value: string(dataType)},
+		db_provider.QueryParam{Name: "STORED_ID", Value: id})
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	records := []AuditRecord{}
+	for rows.Next() {
+		record := AuditRecord{DataType: dataType, ID: id}
+		var action string
+		var data []byte
+		var author sql.NullString
+		var at timestamp
+		if err := rows.Scan(&record.Version, &action, &data, &author, &at); err != nil {
+			return nil, err
+		}
+		record.Action = Action(action)
+		record.Data = data
+		record.Author = author.String
+		record.At = at.Time
+		records = append(records, record)
+	}
+	return records, rows.Err()
+}
+
+// changeFunc writes a change of the entry in the transaction, and returns its audit record. exists is false
+// if the entry doesn't exist or was deleted, and found is false if it doesn't have a row.
+type changeFunc func(tx *sql.Tx, table string, currentVersion int, exists bool, found bool) (AuditRecord, error)
+
+// change runs a change of the entry and inserts its audit record in a single transaction
+func (s *DatabaseStore) change(ctx context.Context, dataType DataType, id string, write changeFunc) (err error) {
+	table, err := s.table(dataType)
+	if err != nil {
+		return err
+	}
+
+	tx, err := s.provider.BeginTx(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+			return
+		}
+		err = tx.Commit()
+	}()
+
+	currentVersion, exists, found, err := s.current(ctx, tx, table, id)
+	if err != nil {
+		return err
+	}
+	record, err := write(tx, table, currentVersion, exists, found)
+	if err != nil {
+		return err
+	}
+
+	data := sql.NullString{String: string(record.Data), Valid: record.Data != nil}
+	return s.exec(ctx, tx, "INSERT INTO "+s.audit+" (data_type, id, version, action, data, author, changed_at) VALUES ($DATA_TYPE, $STORED_ID, $NEW_VERSION, $ACTION, $STORED_DATA, $AUTHOR, $UPDATED_AT)", []db_provider.QueryParam{
+		{Name: "DATA_TYPE", Value: string(dataType)},
+		{Name: "STORED_ID", Value: id},
+		{Name: "NEW_VERSION", Value: record.Version},
+		{Name: "ACTION", Value: string(record.Action)},
+		{Name: "STORED_DATA", Value: data},
+		{Name: "AUTHOR", Value: record.Author},
+		{Name: "UPDATED_AT", Value: s.now().UTC()},
+	})
+}
+
+// current returns the version of the entry, locking its row until the end of the transaction
+func (s *DatabaseStore) current(ctx context.Context, tx *sql.Tx, table string, id string) (version int, exists bool, found bool, err error) {
+	query, args := s.provider.PrepareQuery("SELECT data IS NOT NULL, version FROM "+table+" WHERE id = $STORED_ID FOR UPDATE",
+		db_provider.QueryParam{Name: "STORED_ID", Value: id})
+	err = tx.QueryRowContext(ctx, query, args...).Scan(&exists, &version)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, false, false, nil
+	}
+	if err != nil {
+		return 0, false, false, err
+	}
+	return version, exists, true, nil
+}
+
+func (s *DatabaseStore) exec(ctx context.Context, tx *sql.Tx, template string, params []db_provider.QueryParam) error {
+	query, args := s.provider.PrepareQuery(template, params...)
+	_, err := tx.ExecContext(ctx, query, args...)
+	return err
+}
+
+// update runs an update conditioned on the current version, which fails with ErrVersionConflict if the
+// entry was changed concurrently
+func (s *DatabaseStore) update(ctx context.Context, tx *sql.Tx, template string, params []db_provider.QueryParam) error {
+	query, args := s.provider.PrepareQuery(template, params...)
+	result, err := tx.ExecContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return ErrVersionConflict
+	}
+	return nil
+}
+
+func (s *DatabaseStore) table(dataType DataType) (string, error) {
+	table, ok := s.tables[dataType]
+	if !ok {
+		return "", fmt.Errorf("unknown stored data type %s", dataType)
+	}
+	return table, nil
+}
+
+// timestamp scans the timestamps of the drivers which don't parse them, such as MySQL without parseTime
+type timestamp struct {
+	time.Time
+}
+
+var timestampLayouts = []string{time.RFC3339Nano, "2006-01-02 15:04:05.999999999", "2006-01-02 15:04:05.999999999-07:00"}
+
+func (t *timestamp) Scan(value interface{}) error {
+	var text string
+	switch v := value.(type) {
+	case time.Time:
+		t.Time = v
+		return nil
+	case nil:
+		t.Time = time.Time{}
+		return nil
+	case []byte:
+		text = string(v)
+	case string:
+		text = v
+	default:
+		return fmt.Errorf("can't scan a timestamp from %T", value)
+	}
+
+	for _, layout := range timestampLayouts {
+		if parsed, err := time.Parse(layout, text); err == nil {
+			t.Time = parsed
+			return nil
+		}
+	}
+	return fmt.Errorf("can't parse the timestamp %s", text)
+}
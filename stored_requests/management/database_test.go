@@ -0,0 +1,216 @@
+package management
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/prebid/prebid-server/v3/config"
+	"github.com/prebid/prebid-server/v3/stored_requests/backends/db_provider"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testTables = config.StoredDataTables{
+	Requests:  "stored_requests",
+	Imps:      "stored_imps",
+	Responses: "stored_responses",
+	Accounts:  "accounts",
+	Audit:     "stored_data_audit",
+}
+
+const (
+	selectCurrentQuery = "SELECT data IS NOT NULL, version FROM stored_imps WHERE id = $STORED_ID FOR UPDATE"
+	insertQuery        = "INSERT INTO stored_imps (id, data, version, last_updated) VALUES ($STORED_ID, $STORED_DATA, $NEW_VERSION, $UPDATED_AT)"
+	updateQuery        = "UPDATE stored_imps SET data = $STORED_DATA, version = $NEW_VERSION, last_updated = $UPDATED_AT WHERE id = $STORED_ID AND version = $CUR_VERSION"
+	deleteQuery        = "UPDATE stored_imps SET data = NULL, version = $NEW_VERSION, last_updated = $UPDATED_AT WHERE id = $STORED_ID AND version = $CUR_VERSION"
+	insertAuditQuery   = "INSERT INTO stored_data_audit (data_type, id, version, action, data, author, changed_at) VALUES ($DATA_TYPE, $STORED_ID, $NEW_VERSION, $ACTION, $STORED_DATA, $AUTHOR, $UPDATED_AT)"
+)
+
+func newTestDatabaseStore(t *testing.T) (*DatabaseStore, sqlmock.Sqlmock, time.Time) {
+	provider, dbMock, err := db_provider.NewDbProviderMock()
+	require.NoError(t, err)
+
+	now := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	store := NewDatabaseStore(provider, testTables)
+	store.now = func() time.Time { return now }
+	return store, dbMock, now
+}
+
+func TestDatabaseStorePut(t *testing.T) {
+	data := json.RawMessage(`{"banner":{}}`)
+
+	testCases := []struct {
+		description     string
+		expectedVersion int
+		setup           func(dbMock sqlmock.Sqlmock, now time.Time)
+		wantVersion     int
+		wantErr         error
+	}{
+		{
+			description:     "Create",
+			expectedVersion: 0,
+			setup: func(dbMock sqlmock.Sqlmock, now time.Time) {
+				dbMock.ExpectBegin()
+				dbMock.ExpectQuery(regexp.QuoteMeta(selectCurrentQuery)).WithArgs("imp").WillReturnRows(sqlmock.NewRows([]string{"exists", "version"}))
+				dbMock.ExpectExec(regexp.QuoteMeta(insertQuery)).WithArgs("imp", string(data), 1, now).WillReturnResult(sqlmock.NewResult(0, 1))
+				dbMock.ExpectExec(regexp.QuoteMeta(insertAuditQuery)).WithArgs("imps", "imp", 1, "create", string(data), "alice", now).WillReturnResult(sqlmock.NewResult(0, 1))
+				dbMock.ExpectCommit()
+			},
+			wantVersion: 1,
+		},
+		{
+			description:     "Update",
+			expectedVersion: 3,
+			setup: func(dbMock sqlmock.Sqlmock, now time.Time) {
+				dbMock.ExpectBegin()
+				dbMock.ExpectQuery(regexp.QuoteMeta(selectCurrentQuery)).WithArgs("imp").WillReturnRows(sqlmock.NewRows([]string{"exists", "version"}).AddRow(true, 3))
+				dbMock.ExpectExec(regexp.QuoteMeta(updateQuery)).WithArgs("imp", string(data), 4, now, 3).WillReturnResult(sqlmock.NewResult(0, 1))
+				dbMock.ExpectExec(regexp.QuoteMeta(insertAuditQuery)).WithArgs("imps", "imp", 4, "update", string(data), "alice", now).WillReturnResult(sqlmock.NewResult(0, 1))
+				dbMock.ExpectCommit()
+			},
+			wantVersion: 4,
+		},
+		{
+			description:     "Create After Delete",
+			expectedVersion: 0,
+			setup: func(dbMock sqlmock.Sqlmock, now time.Time) {
+				dbMock.ExpectBegin()
+				dbMock.ExpectQuery(regexp.QuoteMeta(selectCurrentQuery)).WithArgs("imp").WillReturnRows(sqlmock.NewRows([]string{"exists", "version"}).AddRow(false, 5))
+				dbMock.ExpectExec(regexp.QuoteMeta(updateQuery)).WithArgs("imp", string(data), 6, now, 5).WillReturnResult(sqlmock.NewResult(0, 1))
+				dbMock.ExpectExec(regexp.QuoteMeta(insertAuditQuery)).WithArgs("imps", "imp", 6, "create", string(data), "alice", now).WillReturnResult(sqlmock.NewResult(0, 1))
+				dbMock.ExpectCommit()
+			},
+			wantVersion: 6,
+		},
+		{
+			description:     "Stale Version",
+			expectedVersion: 2,
+			setup: func(dbMock sqlmock.Sqlmock, now time.Time) {
+				dbMock.ExpectBegin()
+				dbMock.ExpectQuery(regexp.QuoteMeta(selectCurrentQuery)).WithArgs("imp").WillReturnRows(sqlmock.NewRows([]string{"exists", "version"}).AddRow(true, 3))
+				dbMock.ExpectRollback()
+			},
+			wantErr: ErrVersionConflict,
+		},
+		{
+			description:     "Concurrent Update",
+			expectedVersion: 3,
+			setup: func(dbMock sqlmock.Sqlmock, now time.Time) {
+				dbMock.ExpectBegin()
+				dbMock.ExpectQuery(regexp.QuoteMeta(selectCurrentQuery)).WithArgs("imp").WillReturnRows(sqlmock.NewRows([]string{"exists", "version"}).AddRow(true, 3))
+				dbMock.ExpectExec(regexp.QuoteMeta(updateQuery)).WithArgs("imp", string(data), 4, now, 3).WillReturnResult(sqlmock.NewResult(0, 0))
+				dbMock.ExpectRollback()
+			},
+			wantErr: ErrVersionConflict,
+		},
+		{
+			description:     "Audit Failure",
+			expectedVersion: 0,
+			setup: func(dbMock sqlmock.Sqlmock, now time.Time) {
+				dbMock.ExpectBegin()
+				dbMock.ExpectQuery(regexp.QuoteMeta(selectCurrentQuery)).WithArgs("imp").WillReturnRows(sqlmock.NewRows([]string{"exists", "version"}))
+				dbMock.ExpectExec(regexp.QuoteMeta(insertQuery)).WillReturnResult(sqlmock.NewResult(0, 1))
+				dbMock.ExpectExec(regexp.QuoteMeta(insertAuditQuery)).WillReturnError(errors.New("audit failure"))
+				dbMock.ExpectRollback()
+			},
+			wantErr: errors.New("audit failure"),
+		},
+	}
+
+	for _, test := range testCases {
+		store, dbMock, now := newTestDatabaseStore(t)
+		test.setup(dbMock, now)
+
+		entry, err := store.Put(context.Background(), ImpDataType, "imp", data, test.expectedVersion, "alice")
+		if test.wantErr != nil {
+			assert.EqualError(t, err, test.wantErr.Error(), test.description)
+		} else {
+			assert.NoError(t, err, test.description)
+			assert.Equal(t, Entry{ID: "imp", Version: test.wantVersion, Data: data}, entry, test.description)
+		}
+		assert.NoError(t, dbMock.ExpectationsWereMet(), test.description)
+	}
+}
+
+func TestDatabaseStoreDelete(t *testing.T) {
+	testCases := []struct {
+		description     string
+		expectedVersion int
+		setup           func(dbMock sqlmock.Sqlmock, now time.Time)
+		wantErr         error
+	}{
+		{
+			description:     "Delete",
+			expectedVersion: 2,
+			setup: func(dbMock sqlmock.Sqlmock, now time.Time) {
+				dbMock.ExpectBegin()
+				dbMock.ExpectQuery(regexp.QuoteMeta(selectCurrentQuery)).WithArgs("imp").WillReturnRows(sqlmock.NewRows([]string{"exists", "version"}).AddRow(true, 2))
+				dbMock.ExpectExec(regexp.QuoteMeta(deleteQuery)).WithArgs("imp", 3, now, 2).WillReturnResult(sqlmock.NewResult(0, 1))
+				dbMock.ExpectExec(regexp.QuoteMeta(insertAuditQuery)).WithArgs("imps", "imp", 3, "delete", nil, "bob", now).WillReturnResult(sqlmock.NewResult(0, 1))
+				dbMock.ExpectCommit()
+			},
+		},
+		{
+			description:     "Already Deleted",
+			expectedVersion: 2,
+			setup: func(dbMock sqlmock.Sqlmock, now time.Time) {
+				dbMock.ExpectBegin()
+				dbMock.ExpectQuery(regexp.QuoteMeta(selectCurrentQuery)).WithArgs("imp").WillReturnRows(sqlmock.NewRows([]string{"exists", "version"}).AddRow(false, 3))
+				dbMock.ExpectRollback()
+			},
+			wantErr: ErrNotFound,
+		},
+	}
+
+	for _, test := range testCases {
+		store, dbMock, now := newTestDatabaseStore(t)
+		test.setup(dbMock, now)
+
+		err := store.Delete(context.Background(), ImpDataType, "imp", test.expectedVersion, "bob")
+		assert.Equal(t, test.wantErr, err, test.description)
+		assert.NoError(t, dbMock.ExpectationsWereMet(), test.description)
+	}
+}
+
+func TestDatabaseStoreGet(t *testing.T) {
+	store, dbMock, _ := newTestDatabaseStore(t)
+	query := regexp.QuoteMeta("SELECT data, version FROM accounts WHERE id = $STORED_ID")
+
+	dbMock.ExpectQuery(query).WithArgs("account").WillReturnRows(sqlmock.NewRows([]string{"data", "version"}).AddRow(`{"disabled":true}`, 2))
+	entry, err := store.Get(context.Background(), AccountDataType, "account")
+	assert.NoError(t, err)
+	assert.Equal(t, Entry{ID: "account", Version: 2, Data: json.RawMessage(`{"disabled":true}`)}, entry)
+
+	dbMock.ExpectQuery(query).WithArgs("account").WillReturnRows(sqlmock.NewRows([]string{"data", "version"}).AddRow(nil, 3))
+	_, err = store.Get(context.Background(), AccountDataType, "account")
+	assert.Equal(t, ErrNotFound, err, "deleted")
+
+	dbMock.ExpectQuery(query).WithArgs("account").WillReturnRows(sqlmock.NewRows([]string{"data", "version"}))
+	_, err = store.Get(context.Background(), AccountDataType, "account")
+	assert.Equal(t, ErrNotFound, err, "missing")
+
+	assert.NoError(t, dbMock.ExpectationsWereMet())
+}
+
+func TestDatabaseStoreAudit(t *testing.T) {
+	store, dbMock, now := newTestDatabaseStore(t)
+
+	dbMock.ExpectQuery(regexp.QuoteMeta("SELECT version, action, data, author, changed_at FROM stored_data_audit WHERE data_type = $DATA_TYPE AND id = $STORED_ID ORDER BY version")).
+		WithArgs("requests", "request").
+		WillReturnRows(sqlmock.NewRows([]string{"version", "action", "data", "author", "changed_at"}).
+			AddRow(1, "create", `{"id":"1"}`, "alice", now).
+			AddRow(2, "delete", nil, nil, []byte("2026-10-01 12:00:00.5")))
+
+	records, err := store.Audit(context.Background(), RequestDataType, "request")
+	assert.NoError(t, err)
+	assert.Equal(t, []AuditRecord{
+		{DataType: RequestDataType, ID: "request", Version: 1, Action: ActionCreate, Data: json.RawMessage(`{"id":"1"}`), Author: "alice", At: now},
+		{DataType: RequestDataType, ID: "request", Version: 2, Action: ActionDelete, At: now.Add(500 * time.Millisecond)},
+	}, records)
+	assert.NoError(t, dbMock.ExpectationsWereMet())
+}
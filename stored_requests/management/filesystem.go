@@ -0,0 +1,245 @@
+package management
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/prebid/prebid-server/v3/util/jsonutil"
+)
+
+// directories are the directories of the stored data, as the filesystem backend reads them
+var directories = map[DataType]string{
+	RequestDataType:  "stored_requests",
+	ImpDataType:      "stored_imps",
+	ResponseDataType: "stored_responses",
+	AccountDataType:  "accounts",
+}
+
+// FileStore persists the stored data in the directory read by the filesystem backend, one file per entry,
+// and appends the audit records to a JSON lines file. The versions are derived from the audit log, and the
+// files which were written without the API are at version 1. The caches of the other instances are updated
+// by watching the directory.
+type FileStore struct {
+	directory string
+	auditPath string
+	now       func() time.Time
+
+	mutex sync.Mutex
+}
+
+// NewFileStore returns a FileStore which writes to directory, and appends the audit records to auditPath
+func NewFileStore(directory string, auditPath string) *FileStore {
+	return &FileStore{
+		directory: directory,
+		auditPath: auditPath,
+		now:       time.Now,
+	}
+}
+
+func (s *FileStore) Get(ctx context.Context, dataType DataType, id string) (Entry, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	path, err := s.path(dataType, id)
+	if err != nil {
+		return Entry{}, err
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return Entry{}, ErrNotFound
+	}
+	if err != nil {
+		return Entry{}, err
+	}
+
+	version, err := s.version(dataType, id, true)
+	if err != nil {
+		return Entry{}, err
+	}
+	return Entry{ID: id, Version: version, Data: data}, nil
+}
+
+func (s *FileStore) Put(ctx context.Context, dataType DataType, id string, data json.RawMessage, expectedVersion int, author string) (Entry, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	path, err := s.path(dataType, id)
+	if err != nil {
+		return Entry{}, err
+	}
+	exists, err := fileExists(path)
+	if err != nil {
+		return Entry{}, err
+	}
+	currentVersion, err := s.version(dataType, id, exists)
+	if err != nil {
+		return Entry{}, err
+	}
+	version, err := nextVersion(currentVersion, exists, expectedVersion)
+	if err != nil {
+		return Entry{}, err
+	}
+
+	if err := writeFile(path, data); err != nil {
+		return Entry{}, err
+	}
+	action := ActionUpdate
+	if !exists {
+		action = ActionCreate
+	}
+	if err := s.appendAudit(AuditRecord{DataType: dataType, ID: id, Version: version, Action: action, Data: data, Author: author}); err != nil {
+		return Entry{}, err
+	}
+	return Entry{ID: id, Version: version, Data: data}, nil
+}
+
+func (s *FileStore) Delete(ctx context.Context, dataType DataType, id string, expectedVersion int, author string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	path, err := s.path(dataType, id)
+	if err != nil {
+		return err
+	}
+	exists, err := fileExists(path)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return ErrNotFound
+	}
+	currentVersion, err := s.version(dataType, id, exists)
+	if err != nil {
+		return err
+	}
+	version, err := nextVersion(currentVersion, exists, expectedVersion)
+	if err != nil {
+		return err
+	}
+
+	if err := os.Remove(path); err != nil {
+		return err
+	}
+	return s.appendAudit(AuditRecord{DataType: dataType, ID: id, Version: version, Action: ActionDelete, Author: author})
+}
+
+func (s *FileStore) Audit(ctx context.Context, dataType DataType, id string) ([]AuditRecord, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if _, err := s.path(dataType, id); err != nil {
+		return nil, err
+	}
+	return s.records(dataType, id)
+}
+
+// path returns the file of the entry. The IDs are file names, so they can't hold a path.
+func (s *FileStore) path(dataType DataType, id string) (string, error) {
+	directory, ok := directories[dataType]
+	if !ok {
+		return "", fmt.Errorf("unknown stored data type %s", dataType)
+	}
+	if id == "" || id == "." || id == ".." || filepath.Base(id) != id {
+		return "", fmt.Errorf("%w, it must be a file name: %q", ErrInvalidID, id)
+	}
+	return filepath.Join(s.directory, directory, id+".json"), nil
+}
+
+// version returns the current version of the entry, which is the version of its last audit record
+func (s *FileStore) version(dataType DataType, id string, exists bool) (int, error) {
+	records, err := s.records(dataType, id)
+	if err != nil {
+		return 0, err
+	}
+	if len(records) > 0 {
+		return records[len(records)-1].Version, nil
+	}
+	if exists {
+		return 1, nil
+	}
+	return 0, nil
+}
+
+func (s *FileStore) records(dataType DataType, id string) ([]AuditRecord, error) {
+	file, err := os.Open(s.auditPath)
+	if errors.Is(err, fs.ErrNotExist) {
+		return []AuditRecord{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	records := []AuditRecord{}
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(nil, 16*1024*1024)
+	for scanner.Scan() {
+		var record AuditRecord
+		if err := jsonutil.UnmarshalValid(scanner.Bytes(), &record); err != nil {
+			return nil, fmt.Errorf("the audit log %s is malformed: %v", s.auditPath, err)
+		}
+		if record.DataType == dataType && record.ID == id {
+			records = append(records, record)
+		}
+	}
+	return records, scanner.Err()
+}
+
+func (s *FileStore) appendAudit(record AuditRecord) error {
+	record.At = s.now().UTC()
+	line, err := jsonutil.Marshal(record)
+	if err != nil {
+		return err
+	}
+
+	file, err := os.OpenFile(s.auditPath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err := file.Write(append(line, '\n')); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+func fileExists(path string) (bool, error) {
+	_, err := os.Stat(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// writeFile replaces the file through a rename, so that the directory watchers never read a partial file
+func writeFile(path string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".tmp-*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,89 @@
+package management
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileStore(t *testing.T) {
+	directory := t.TempDir()
+	now := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	store := NewFileStore(directory, filepath.Join(directory, "audit.jsonl"))
+	store.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	_, err := store.Get(ctx, RequestDataType, "request")
+	assert.Equal(t, ErrNotFound, err, "missing")
+
+	entry, err := store.Put(ctx, RequestDataType, "request", json.RawMessage(`{"id":"1"}`), 0, "alice")
+	require.NoError(t, err, "create")
+	assert.Equal(t, Entry{ID: "request", Version: 1, Data: json.RawMessage(`{"id":"1"}`)}, entry, "create")
+
+	data, err := os.ReadFile(filepath.Join(directory, "stored_requests", "request.json"))
+	require.NoError(t, err, "the file should be read by the filesystem backend")
+	assert.JSONEq(t, `{"id":"1"}`, string(data))
+
+	_, err = store.Put(ctx, RequestDataType, "request", json.RawMessage(`{"id":"2"}`), 0, "bob")
+	assert.Equal(t, ErrVersionConflict, err, "create an existing entry")
+
+	entry, err = store.Put(ctx, RequestDataType, "request", json.RawMessage(`{"id":"2"}`), 1, "bob")
+	require.NoError(t, err, "update")
+	assert.Equal(t, 2, entry.Version, "update")
+
+	entry, err = store.Get(ctx, RequestDataType, "request")
+	require.NoError(t, err, "get")
+	assert.Equal(t, Entry{ID: "request", Version: 2, Data: json.RawMessage(`{"id":"2"}`)}, entry, "get")
+
+	assert.Equal(t, ErrVersionConflict, store.Delete(ctx, RequestDataType, "request", 1, "alice"), "delete a stale version")
+	assert.NoError(t, store.Delete(ctx, RequestDataType, "request", 2, "alice"), "delete")
+	assert.NoFileExists(t, filepath.Join(directory, "stored_requests", "request.json"))
+	assert.Equal(t, ErrNotFound, store.Delete(ctx, RequestDataType, "request", 3, "alice"), "delete a deleted entry")
+
+	entry, err = store.Put(ctx, RequestDataType, "request", json.RawMessage(`{"id":"3"}`), 0, "alice")
+	require.NoError(t, err, "create after delete")
+	assert.Equal(t, 4, entry.Version, "the versions should keep increasing after a delete")
+
+	records, err := store.Audit(ctx, RequestDataType, "request")
+	require.NoError(t, err)
+	assert.Equal(t, []AuditRecord{
+		{DataType: RequestDataType, ID: "request", Version: 1, Action: ActionCreate, Data: json.RawMessage(`{"id":"1"}`), Author: "alice", At: now},
+		{DataType: RequestDataType, ID: "request", Version: 2, Action: ActionUpdate, Data: json.RawMessage(`{"id":"2"}`), Author: "bob", At: now},
+		{DataType: RequestDataType, ID: "request", Version: 3, Action: ActionDelete, Author: "alice", At: now},
+		{DataType: RequestDataType, ID: "request", Version: 4, Action: ActionCreate, Data: json.RawMessage(`{"id":"3"}`), Author: "alice", At: now},
+	}, records)
+
+	records, err = store.Audit(ctx, ImpDataType, "request")
+	require.NoError(t, err)
+	assert.Empty(t, records, "the audit records should be filtered by type")
+}
+
+func TestFileStoreUnauditedFile(t *testing.T) {
+	directory := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(directory, "accounts"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(directory, "accounts", "account.json"), []byte(`{"disabled":false}`), 0644))
+	store := NewFileStore(directory, filepath.Join(directory, "audit.jsonl"))
+
+	entry, err := store.Get(context.Background(), AccountDataType, "account")
+	require.NoError(t, err)
+	assert.Equal(t, 1, entry.Version, "the files written without the API should be at version 1")
+
+	entry, err = store.Put(context.Background(), AccountDataType, "account", json.RawMessage(`{"disabled":true}`), 1, "")
+	require.NoError(t, err)
+	assert.Equal(t, 2, entry.Version)
+}
+
+func TestFileStoreInvalidID(t *testing.T) {
+	store := NewFileStore(t.TempDir(), filepath.Join(t.TempDir(), "audit.jsonl"))
+
+	for _, id := range []string{"..", "../account", "a/b"} {
+		_, err := store.Put(context.Background(), AccountDataType, id, json.RawMessage(`{}`), 0, "")
+		assert.ErrorIs(t, err, ErrInvalidID, id)
+	}
+}
@@ -0,0 +1,97 @@
+// Package management persists the stored requests, imps, responses and accounts written through the admin
+// API. Each entry has a version number, which a write must match, so that concurrent changes don't overwrite
+// each other. Every change is recorded in an audit log.
+package management
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+// DataType is the kind of stored data, as written in the admin API paths
+type DataType string
+
+const (
+	RequestDataType  DataType = "requests"
+	ImpDataType      DataType = "imps"
+	ResponseDataType DataType = "responses"
+	AccountDataType  DataType = "accounts"
+)
+
+// DataTypes are the kinds of stored data which can be managed
+var DataTypes = []DataType{RequestDataType, ImpDataType, ResponseDataType, AccountDataType}
+
+// Action is the change recorded by an audit record
+type Action string
+
+const (
+	ActionCreate Action = "create"
+	ActionUpdate Action = "update"
+	ActionDelete Action = "delete"
+)
+
+var (
+	// ErrNotFound is returned when the entry doesn't exist, or was deleted
+	ErrNotFound = errors.New("the stored data doesn't exist")
+	// ErrVersionConflict is returned when the expected version isn't the current version of the entry
+	ErrVersionConflict = errors.New("the stored data was changed since the expected version")
+	// ErrInvalidID is returned when the id can't be stored by the store
+	ErrInvalidID = errors.New("the stored data id isn't valid")
+)
+
+// Entry is a version of stored data
+type Entry struct {
+	ID      string          `json:"id"`
+	Version int             `json:"version"`
+	Data    json.RawMessage `json:"data"`
+}
+
+// AuditRecord is a change of stored data. The deletes have no data.
+type AuditRecord struct {
+	DataType DataType        `json:"type"`
+	ID       string          `json:"id"`
+	Version  int             `json:"version"`
+	Action   Action          `json:"action"`
+	Data     json.RawMessage `json:"data,omitempty"`
+	Author   string          `json:"author,omitempty"`
+	At       time.Time       `json:"at"`
+}
+
+// Store persists the stored data.
+//
+// The writes take the version the caller expects the entry to have. A version of 0 means that the entry
+// must not exist, so it's created. A write whose expected version doesn't match returns ErrVersionConflict.
+type Store interface {
+	// Get returns the current version of an entry, or ErrNotFound
+	Get(ctx context.Context, dataType DataType, id string) (Entry, error)
+	// Put creates or updates an entry, and returns its new version
+	Put(ctx context.Context, dataType DataType, id string, data json.RawMessage, expectedVersion int, author string) (Entry, error)
+	// Delete deletes an entry
+	Delete(ctx context.Context, dataType DataType, id string, expectedVersion int, author string) error
+	// Audit returns the changes of an entry, the oldest first
+	Audit(ctx context.Context, dataType DataType, id string) ([]AuditRecord, error)
+}
+
+// IsDataType returns true if dataType is a kind of stored data which can be managed
+func IsDataType(dataType DataType) bool {
+	for _, t := range DataTypes {
+		if t == dataType {
+			return true
+		}
+	}
+	return false
+}
+
+// nextVersion returns the version written by a change, or ErrVersionConflict. The version of a deleted entry
+// is kept, so that it keeps increasing when the entry is created again.
+func nextVersion(currentVersion int, exists bool, expectedVersion int) (int, error) {
+	if exists && expectedVersion != currentVersion {
+		return 0, ErrVersionConflict
+	}
+	if !exists && expectedVersion != 0 {
+		return 0, ErrVersionConflict
+	}
+	return currentVersion + 1, nil
+}
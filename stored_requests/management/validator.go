@@ -0,0 +1,251 @@
+package management
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/buger/jsonparser"
+	"github.com/prebid/openrtb/v20/openrtb2"
+
+	"github.com/prebid/prebid-server/v3/config"
+	"github.com/prebid/prebid-server/v3/errortypes"
+	"github.com/prebid/prebid-server/v3/openrtb_ext"
+	"github.com/prebid/prebid-server/v3/ortb"
+	"github.com/prebid/prebid-server/v3/stored_requests/templates"
+	"github.com/prebid/prebid-server/v3/util/jsonutil"
+)
+
+// Validator validates the stored data before it's persisted. The stored requests and imps are often partial,
+// and completed by the incoming requests, so only the parts which are present are validated.
+type Validator struct {
+	requestValidator ortb.RequestValidator
+	paramsValidator  openrtb_ext.BidderParamValidator
+	bidderMap        map[string]openrtb_ext.BidderName
+}
+
+// NewValidator returns a Validator of the stored data of the given bidders
+func NewValidator(requestValidator ortb.RequestValidator, paramsValidator openrtb_ext.BidderParamValidator, bidderMap map[string]openrtb_ext.BidderName) *Validator {
+	return &Validator{
+		requestValidator: requestValidator,
+		paramsValidator:  paramsValidator,
+		bidderMap:        bidderMap,
+	}
+}
+
+// versionedData is a stored request or imp which holds several versions
+type versionedData struct {
+	Versions map[string]json.RawMessage   `json:"versions"`
+	Rollout  *config.StoredVersionRollout `json:"rollout"`
+}
+
+// Validate returns the errors of the stored data
+func (v *Validator) Validate(dataType DataType, id string, data json.RawMessage) []error {
+	if !json.Valid(data) {
+		return []error{errors.New("the stored data isn't valid JSON")}
+	}
+
+	switch dataType {
+	case RequestDataType, ImpDataType:
+		var versioned versionedData
+		if err := jsonutil.Unmarshal(data, &versioned); err == nil && versioned.Versions != nil {
+			return v.validateVersions(dataType, versioned)
+		}
+		return v.validateData(dataType, data)
+	case ResponseDataType:
+		return nil
+	case AccountDataType:
+		return validateAccount(id, data)
+	}
+	return []error{fmt.Errorf("unknown stored data type %s", dataType)}
+}
+
+func (v *Validator) validateVersions(dataType DataType, versioned versionedData) []error {
+	var errs []error
+	for version, data := range versioned.Versions {
+		for _, err := range v.validateData(dataType, data) {
+			errs = append(errs, fmt.Errorf("version %s: %v", version, err))
+		}
+	}
+	if versioned.Rollout != nil {
+		if err := versioned.Rollout.Validate(); err != nil {
+			errs = append(errs, fmt.Errorf("rollout: %v", err))
+		}
+	}
+	return errs
+}
+
+func (v *Validator) validateData(dataType DataType, data json.RawMessage) []error {
+	// the placeholders of templates are replaced by values of any type, so the templates are validated with
+	// their defaults, except for the bidder params which hold placeholders
+	var templatedBidders []map[string]struct{}
+	if template := templates.Parse(data); template != nil {
+		templatedBidders = findTemplatedBidders(dataType, data)
+		data = template.RenderDefaults()
+	}
+
+	if dataType == ImpDataType {
+		return v.validateImp(data, 0, nil, bidderSet(templatedBidders, 0))
+	}
+
+	var request openrtb2.BidRequest
+	if err := jsonutil.UnmarshalValid(data, &request); err != nil {
+		return []error{err}
+	}
+	var aliases map[string]string
+	if requestExt, err := requestPrebidExt(request.Ext); err != nil {
+		return []error{err}
+	} else if requestExt != nil {
+		aliases = requestExt.Aliases
+	}
+
+	var errs []error
+	for i, imp := range request.Imp {
+		impJSON, err := jsonutil.Marshal(imp)
+		if err != nil {
+			return []error{err}
+		}
+		errs = append(errs, v.validateImp(impJSON, i, aliases, bidderSet(templatedBidders, i))...)
+	}
+	return errs
+}
+
+// validateImp runs the OpenRTB validation of the imps which have a media type and bidders. The bidder
+// params of the other imps are validated on their own. The skipped bidders are left out.
+func (v *Validator) validateImp(data json.RawMessage, index int, aliases map[string]string, skippedBidders map[string]struct{}) []error {
+	for bidder := range skippedBidders {
+		data = jsonparser.Delete(data, "ext", "prebid", "bidder", bidder)
+		data = jsonparser.Delete(data, "ext", bidder)
+	}
+
+	var imp openrtb2.Imp
+	if err := jsonutil.UnmarshalValid(data, &imp); err != nil {
+		return []error{err}
+	}
+
+	bidders, err := impBidders(imp.Ext)
+	if err != nil {
+		return []error{fmt.Errorf("imp[%d].ext is invalid: %v", index, err)}
+	}
+	if (imp.Banner != nil || imp.Video != nil || imp.Audio != nil || imp.Native != nil) && len(bidders) > 0 {
+		if imp.ID == "" {
+			// the imp ID is often set by the incoming imp
+			imp.ID = "stored-imp"
+		}
+		errs := v.requestValidator.ValidateImp(&openrtb_ext.ImpWrapper{Imp: &imp}, ortb.ValidationConfig{}, index, aliases, false, nil)
+		return errortypes.FatalOnly(errs)
+	}
+
+	var errs []error
+	for bidder, params := range bidders {
+		bidderName, ok := v.bidderMap[bidder]
+		if !ok {
+			// an unknown bidder may be an alias of the incoming request
+			continue
+		}
+		if err := v.paramsValidator.Validate(bidderName, params); err != nil {
+			errs = append(errs, fmt.Errorf("imp[%d].ext.prebid.bidder.%s failed validation.\n%v", index, bidder, err))
+		}
+	}
+	return errs
+}
+
+// findTemplatedBidders returns the bidders of each imp of the stored data whose params hold placeholders
+func findTemplatedBidders(dataType DataType, data json.RawMessage) []map[string]struct{} {
+	if dataType == ImpDataType {
+		ext, _, _, _ := jsonparser.Get(data, "ext")
+		return []map[string]struct{}{templatedImpBidders(ext)}
+	}
+
+	var bidders []map[string]struct{}
+	jsonparser.ArrayEach(data, func(imp []byte, _ jsonparser.ValueType, _ int, _ error) {
+		ext, _, _, _ := jsonparser.Get(imp, "ext")
+		bidders = append(bidders, templatedImpBidders(ext))
+	}, "imp")
+	return bidders
+}
+
+func templatedImpBidders(ext json.RawMessage) map[string]struct{} {
+	bidders, err := impBidders(ext)
+	if err != nil {
+		return nil
+	}
+	templated := make(map[string]struct{})
+	for bidder, params := range bidders {
+		if templates.Parse(params) != nil {
+			templated[bidder] = struct{}{}
+		}
+	}
+	return templated
+}
+
+func bidderSet(bidders []map[string]struct{}, index int) map[string]struct{} {
+	if index < len(bidders) {
+		return bidders[index]
+	}
+	return nil
+}
+
+// impBidders returns the bidder params of imp.ext.prebid.bidder, or else of imp.ext
+func impBidders(ext json.RawMessage) (map[string]json.RawMessage, error) {
+	if len(ext) == 0 {
+		return nil, nil
+	}
+	var impExt map[string]json.RawMessage
+	if err := jsonutil.UnmarshalValid(ext, &impExt); err != nil {
+		return nil, err
+	}
+
+	var prebid openrtb_ext.ExtImpPrebid
+	if prebidJSON, ok := impExt["prebid"]; ok {
+		if err := jsonutil.UnmarshalValid(prebidJSON, &prebid); err != nil {
+			return nil, err
+		}
+	}
+	if prebid.Bidder != nil {
+		return prebid.Bidder, nil
+	}
+
+	bidders := make(map[string]json.RawMessage)
+	for key, params := range impExt {
+		if openrtb_ext.IsPotentialBidder(key) {
+			bidders[key] = params
+		}
+	}
+	return bidders, nil
+}
+
+func requestPrebidExt(ext json.RawMessage) (*openrtb_ext.ExtRequestPrebid, error) {
+	if len(ext) == 0 {
+		return nil, nil
+	}
+	var requestExt openrtb_ext.ExtRequest
+	if err := jsonutil.UnmarshalValid(ext, &requestExt); err != nil {
+		return nil, fmt.Errorf("request.ext is invalid: %v", err)
+	}
+	return &requestExt.Prebid, nil
+}
+
+// validateAccount parses the account config as it's parsed when it's fetched
+func validateAccount(id string, data json.RawMessage) []error {
+	var account config.Account
+	if err := jsonutil.UnmarshalValid(data, &account); err != nil {
+		return []error{fmt.Errorf("the account config is malformed: %v", err)}
+	}
+	if account.ID != "" && account.ID != id {
+		return []error{fmt.Errorf("the account config id %s doesn't match the account id %s", account.ID, id)}
+	}
+
+	var errs []error
+	if err := config.UnpackDSADefault(account.Privacy.DSA); err != nil {
+		errs = append(errs, fmt.Errorf("the account config privacy.dsa is malformed: %v", err))
+	}
+	errs = account.Privacy.IPv6Config.Validate(errs)
+	errs = account.Privacy.IPv4Config.Validate(errs)
+	for storedID, rollout := range account.StoredRequestVersions {
+		if err := rollout.Validate(); err != nil {
+			errs = append(errs, fmt.Errorf("stored_request_versions.%s: %v", storedID, err))
+		}
+	}
+	return errs
+}
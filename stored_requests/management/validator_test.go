@@ -0,0 +1,136 @@
+package management
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/prebid/prebid-server/v3/openrtb_ext"
+	"github.com/prebid/prebid-server/v3/ortb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidate(t *testing.T) {
+	paramsValidator, err := openrtb_ext.NewBidderParamsValidator("../../static/bidder-params")
+	require.NoError(t, err)
+	bidderMap := openrtb_ext.BuildBidderMap()
+	validator := NewValidator(ortb.NewRequestValidator(bidderMap, map[string]string{}, paramsValidator), paramsValidator, bidderMap)
+
+	testCases := []struct {
+		description    string
+		dataType       DataType
+		id             string
+		data           string
+		wantErrsCount  int
+		wantErrMessage string
+	}{
+		{
+			description: "Valid Imp",
+			dataType:    ImpDataType,
+			data:        `{"banner":{"format":[{"w":300,"h":250}]},"ext":{"prebid":{"bidder":{"appnexus":{"placementId":12345}}}}}`,
+		},
+		{
+			description:    "Invalid Banner",
+			dataType:       ImpDataType,
+			data:           `{"banner":{"format":[{"w":-1,"h":250}]},"ext":{"prebid":{"bidder":{"appnexus":{"placementId":12345}}}}}`,
+			wantErrsCount:  1,
+			wantErrMessage: "request.imp[0].banner.format[0].w must be a positive number",
+		},
+		{
+			description:   "Partial Imp With Invalid Params",
+			dataType:      ImpDataType,
+			data:          `{"ext":{"appnexus":{"placementId":true}}}`,
+			wantErrsCount: 1,
+		},
+		{
+			description: "Partial Imp With Unknown Bidder",
+			dataType:    ImpDataType,
+			data:        `{"ext":{"prebid":{"bidder":{"myalias":{"anything":true}}}}}`,
+		},
+		{
+			description: "Template",
+			dataType:    ImpDataType,
+			data:        `{"ext":{"appnexus":{"placementId":"{{ext.prebid.storedrequest.params.placementId}}"}}}`,
+		},
+		{
+			description: "Template With Valid Structure",
+			dataType:    RequestDataType,
+			data:        `{"site":{"domain":"{{site.domain}}"},"imp":[{"id":"1","banner":{"format":[{"w":300,"h":250}]},"bidfloor":"{{imp.bidfloor|0.01}}","ext":{"prebid":{"bidder":{"appnexus":{"placementId":"{{ext.prebid.storedrequest.params.placementId}}"},"rubicon":{"accountId":1,"siteId":2,"zoneId":3}}}}}]}`,
+		},
+		{
+			description:    "Template With Invalid Structure",
+			dataType:       ImpDataType,
+			data:           `{"tagid":"{{imp.tagid}}","banner":{"format":[{"w":-1,"h":250}]},"ext":{"prebid":{"bidder":{"appnexus":{"placementId":12345}}}}}`,
+			wantErrsCount:  1,
+			wantErrMessage: "request.imp[0].banner.format[0].w must be a positive number",
+		},
+		{
+			description:    "Template With Invalid Params",
+			dataType:       RequestDataType,
+			data:           `{"imp":[{"id":"1","tagid":"{{imp.tagid}}","ext":{"appnexus":{"placementId":"{{ext.prebid.storedrequest.params.placementId}}"},"rubicon":{"accountId":true}}}]}`,
+			wantErrsCount:  1,
+			wantErrMessage: "imp[0].ext.prebid.bidder.rubicon failed validation.",
+		},
+		{
+			description:    "Request Imp",
+			dataType:       RequestDataType,
+			data:           `{"imp":[{"id":"1","banner":{"format":[{"w":300,"h":250}]},"ext":{"prebid":{"bidder":{"appnexus":{"placementId":true}}}}}]}`,
+			wantErrsCount:  1,
+			wantErrMessage: "request.imp[0].ext.prebid.bidder.appnexus failed validation.",
+		},
+		{
+			description:   "Malformed Request",
+			dataType:      RequestDataType,
+			data:          `{"imp":{}}`,
+			wantErrsCount: 1,
+		},
+		{
+			description:    "Versioned Request",
+			dataType:       RequestDataType,
+			data:           `{"versions":{"v1":{"tmax":500},"v2":{"tmax":"late"}},"rollout":{"default":"v3","split":[{"version":"v2","percent":120}]}}`,
+			wantErrsCount:  2,
+			wantErrMessage: "version v2:",
+		},
+		{
+			description: "Response",
+			dataType:    ResponseDataType,
+			data:        `[{"bidder":"appnexus"}]`,
+		},
+		{
+			description: "Valid Account",
+			dataType:    AccountDataType,
+			id:          "account",
+			data:        `{"id":"account","privacy":{"ipv6":{"anon_keep_bits":56}}}`,
+		},
+		{
+			description:    "Account ID Mismatch",
+			dataType:       AccountDataType,
+			id:             "account",
+			data:           `{"id":"other"}`,
+			wantErrsCount:  1,
+			wantErrMessage: "the account config id other doesn't match the account id account",
+		},
+		{
+			description:   "Malformed Account",
+			dataType:      AccountDataType,
+			id:            "account",
+			data:          `{"disabled":"yes"}`,
+			wantErrsCount: 1,
+		},
+		{
+			description:    "Invalid JSON",
+			dataType:       AccountDataType,
+			data:           `{`,
+			wantErrsCount:  1,
+			wantErrMessage: "the stored data isn't valid JSON",
+		},
+	}
+
+	for _, test := range testCases {
+		errs := validator.Validate(test.dataType, test.id, json.RawMessage(test.data))
+		assert.Len(t, errs, test.wantErrsCount, test.description)
+		if test.wantErrMessage != "" && len(errs) > 0 {
+			assert.Contains(t, errs[0].Error(), test.wantErrMessage, test.description)
+		}
+	}
+}
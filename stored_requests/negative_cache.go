@@ -0,0 +1,135 @@
+package stored_requests
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+)
+
+// maxNotFoundIDs is the maximum number of IDs a NotFoundCache remembers, so that the requests for random IDs
+// can't grow it without bound. The IDs which don't fit aren't cached.
+const maxNotFoundIDs = 100000
+
+// NegativeCache holds the IDs of each data type which the backend didn't find.
+// The nil NotFoundCaches don't cache anything.
+type NegativeCache struct {
+	Requests  *NotFoundCache
+	Imps      *NotFoundCache
+	Responses *NotFoundCache
+	Accounts  *NotFoundCache
+}
+
+// NewNegativeCache returns the caches of the IDs which aren't found, which are kept for ttl.
+// Nothing is cached if ttl <= 0.
+func NewNegativeCache(ttl time.Duration) NegativeCache {
+	if ttl <= 0 {
+		return NegativeCache{}
+	}
+	return NegativeCache{
+		Requests:  NewNotFoundCache(ttl),
+		Imps:      NewNotFoundCache(ttl),
+		Responses: NewNotFoundCache(ttl),
+		Accounts:  NewNotFoundCache(ttl),
+	}
+}
+
+// Compose returns the cache composed with the NotFoundCaches, so that the events which save or invalidate
+// IDs in the cache make the NotFoundCaches forget them.
+func (n NegativeCache) Compose(cache Cache) Cache {
+	compose := func(c CacheJSON, notFound *NotFoundCache) CacheJSON {
+		if notFound == nil {
+			return c
+		}
+		return ComposedCache{c, notFound}
+	}
+	return Cache{
+		Requests:  compose(cache.Requests, n.Requests),
+		Imps:      compose(cache.Imps, n.Imps),
+		Responses: compose(cache.Responses, n.Responses),
+		Accounts:  compose(cache.Accounts, n.Accounts),
+	}
+}
+
+// NotFoundCache remembers the IDs which the backend didn't find for a TTL. It's a CacheJSON which never
+// returns any data: saving or invalidating IDs forgets them.
+type NotFoundCache struct {
+	ttl time.Duration
+	now func() time.Time
+
+	mutex   sync.Mutex
+	expires map[string]time.Time
+}
+
+// NewNotFoundCache returns a cache which remembers the IDs for ttl
+func NewNotFoundCache(ttl time.Duration) *NotFoundCache {
+	return &NotFoundCache{
+		ttl:     ttl,
+		now:     time.Now,
+		expires: make(map[string]time.Time),
+	}
+}
+
+// Filter returns the ids which aren't known to be missing, and a NotFoundError for each of the others
+func (c *NotFoundCache) Filter(dataType string, ids []string) (unknown []string, errs []error) {
+	if c == nil || len(ids) == 0 {
+		return ids, nil
+	}
+
+	unknown = make([]string, 0, len(ids))
+	now := c.now()
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	for _, id := range ids {
+		if expires, ok := c.expires[id]; ok && now.Before(expires) {
+			errs = append(errs, NotFoundError{ID: id, DataType: dataType})
+		} else {
+			unknown = append(unknown, id)
+		}
+	}
+	return
+}
+
+// Add remembers that the ids weren't found
+func (c *NotFoundCache) Add(ids []string) {
+	if c == nil || len(ids) == 0 {
+		return
+	}
+
+	now := c.now()
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if len(c.expires)+len(ids) > maxNotFoundIDs {
+		for id, expires := range c.expires {
+			if !now.Before(expires) {
+				delete(c.expires, id)
+			}
+		}
+	}
+	for _, id := range ids {
+		if len(c.expires) >= maxNotFoundIDs {
+			return
+		}
+		c.expires[id] = now.Add(c.ttl)
+	}
+}
+
+func (c *NotFoundCache) Get(ctx context.Context, ids []string) map[string]json.RawMessage {
+	return map[string]json.RawMessage{}
+}
+
+func (c *NotFoundCache) Save(ctx context.Context, data map[string]json.RawMessage) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	for id := range data {
+		delete(c.expires, id)
+	}
+}
+
+func (c *NotFoundCache) Invalidate(ctx context.Context, ids []string) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	for _, id := range ids {
+		delete(c.expires, id)
+	}
+}
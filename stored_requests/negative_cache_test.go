@@ -0,0 +1,63 @@
+package stored_requests
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNotFoundCache(t *testing.T) {
+	now := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	cache := NewNotFoundCache(time.Minute)
+	cache.now = func() time.Time { return now }
+
+	cache.Add([]string{"a", "b", "c"})
+	unknown, errs := cache.Filter("Request", []string{"a", "b", "c", "d"})
+	assert.Equal(t, []string{"d"}, unknown)
+	assert.Equal(t, []error{
+		NotFoundError{ID: "a", DataType: "Request"},
+		NotFoundError{ID: "b", DataType: "Request"},
+		NotFoundError{ID: "c", DataType: "Request"},
+	}, errs)
+	assert.Empty(t, cache.Get(context.Background(), []string{"a"}), "The cache should never return data")
+
+	cache.Save(context.Background(), map[string]json.RawMessage{"a": json.RawMessage(`{}`)})
+	cache.Invalidate(context.Background(), []string{"b"})
+	unknown, _ = cache.Filter("Request", []string{"a", "b", "c"})
+	assert.Equal(t, []string{"a", "b"}, unknown, "The saved and invalidated IDs should be forgotten")
+
+	now = now.Add(time.Minute)
+	unknown, errs = cache.Filter("Request", []string{"c"})
+	assert.Equal(t, []string{"c"}, unknown, "The IDs should expire after the TTL")
+	assert.Empty(t, errs)
+}
+
+func TestNotFoundCacheSize(t *testing.T) {
+	now := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	cache := NewNotFoundCache(time.Minute)
+	cache.now = func() time.Time { return now }
+
+	ids := make([]string, maxNotFoundIDs+1)
+	for i := range ids {
+		ids[i] = strconv.Itoa(i)
+	}
+	cache.Add(ids)
+	assert.Len(t, cache.expires, maxNotFoundIDs, "The IDs beyond the maximum shouldn't be cached")
+
+	now = now.Add(time.Minute)
+	cache.Add([]string{"new"})
+	assert.Len(t, cache.expires, 1, "The expired IDs should be removed when the cache is full")
+}
+
+func TestNilNotFoundCache(t *testing.T) {
+	var cache *NotFoundCache
+	cache.Add([]string{"a"})
+	unknown, errs := cache.Filter("Account", []string{"a"})
+	assert.Equal(t, []string{"a"}, unknown)
+	assert.Empty(t, errs)
+	assert.Equal(t, NegativeCache{}, NewNegativeCache(0), "Nothing should be cached without a TTL")
+}
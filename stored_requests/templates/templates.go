@@ -0,0 +1,352 @@
+// Package templates resolves the placeholders of parameterized stored requests and stored imps.
+//
+// A placeholder is written inside a JSON string, e.g. "{{site.domain}}" or "{{imp.bidfloor|0.01}}". If it's
+// the whole string, the string is replaced by the JSON value of the parameter, so numbers stay numbers.
+// Otherwise the value is embedded in the string. The text after the | is the default value, used when the
+// parameter is missing. A missing parameter without a default is an error.
+package templates
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/buger/jsonparser"
+)
+
+// Params are the values the placeholders are resolved against
+type Params struct {
+	// Request is the incoming request, which resolves the site., app., device. ... placeholders
+	Request []byte
+	// Imp is the incoming imp, which resolves the imp. placeholders of stored imps
+	Imp []byte
+	// Reference is the request or imp which references the stored data. It resolves the ext. placeholders,
+	// such as ext.prebid.storedrequest.params.placementId
+	Reference []byte
+	// AccountID resolves the account.id placeholder
+	AccountID string
+}
+
+// roots are the first path elements of the placeholders. Any other text between braces, such as the
+// {{UUID}} request ID, is left as is.
+var roots = map[string]struct{}{
+	"account": {},
+	"app":     {},
+	"device":  {},
+	"dooh":    {},
+	"ext":     {},
+	"imp":     {},
+	"regs":    {},
+	"site":    {},
+	"source":  {},
+	"user":    {},
+}
+
+var placeholderPattern = regexp.MustCompile(`\{\{\s*([A-Za-z0-9_]+(?:\.[A-Za-z0-9_]+)*)\s*(?:\|([^}]*))?\}\}`)
+
+// Template is stored data with placeholders
+type Template struct {
+	parts []part
+}
+
+// part is either a literal piece of the JSON, or a string value with placeholders
+type part struct {
+	literal []byte
+	value   *stringValue
+}
+
+// stringValue is a JSON string made of literals, escaped as in the JSON, around placeholders
+type stringValue struct {
+	literals     []string
+	placeholders []placeholder
+}
+
+type placeholder struct {
+	name         string
+	path         []string
+	defaultValue string
+	hasDefault   bool
+}
+
+// Parse finds the placeholders of data. It returns nil if data has none.
+func Parse(data []byte) *Template {
+	var t Template
+	literalStart := 0
+	for i := 0; i < len(data); i++ {
+		if data[i] != '"' {
+			continue
+		}
+		end := stringEnd(data, i)
+		if end < 0 {
+			break
+		}
+		if value := parseString(data[i+1 : end]); value != nil && !isKey(data, end+1) {
+			t.parts = append(t.parts, part{literal: data[literalStart:i]}, part{value: value})
+			literalStart = end + 1
+		}
+		i = end
+	}
+	if len(t.parts) == 0 {
+		return nil
+	}
+	t.parts = append(t.parts, part{literal: data[literalStart:]})
+	return &t
+}
+
+// Render returns the JSON of the template with the placeholders resolved against params
+func (t *Template) Render(params Params) (json.RawMessage, error) {
+	var buffer bytes.Buffer
+	for _, part := range t.parts {
+		if part.value == nil {
+			buffer.Write(part.literal)
+			continue
+		}
+		if err := part.value.render(&buffer, params); err != nil {
+			return nil, err
+		}
+	}
+	return buffer.Bytes(), nil
+}
+
+// RenderDefaults returns the JSON of the template with the placeholders resolved to their defaults, before
+// any request provides the parameters. A placeholder without a default is rendered as null if it's the
+// whole string, and as empty text otherwise.
+func (t *Template) RenderDefaults() json.RawMessage {
+	var buffer bytes.Buffer
+	for _, part := range t.parts {
+		if part.value == nil {
+			buffer.Write(part.literal)
+			continue
+		}
+		// every placeholder resolves to its default, which is never an object or array, so it can't fail
+		_ = part.value.withDefaults().render(&buffer, Params{})
+	}
+	return buffer.Bytes()
+}
+
+// stringEnd returns the index of the quote closing the string which starts at start, or -1
+func stringEnd(data []byte, start int) int {
+	for i := start + 1; i < len(data); i++ {
+		switch data[i] {
+		case '\\':
+			i++
+		case '"':
+			return i
+		}
+	}
+	return -1
+}
+
+// isKey returns true if the string ending before pos is an object key
+func isKey(data []byte, pos int) bool {
+	for ; pos < len(data); pos++ {
+		switch data[pos] {
+		case ' ', '\t', '\n', '\r':
+			continue
+		case ':':
+			return true
+		default:
+			return false
+		}
+	}
+	return false
+}
+
+func parseString(content []byte) *stringValue {
+	if !bytes.Contains(content, []byte("{{")) {
+		return nil
+	}
+
+	var value stringValue
+	literalStart := 0
+	for _, match := range placeholderPattern.FindAllSubmatchIndex(content, -1) {
+		name := string(content[match[2]:match[3]])
+		path := strings.Split(name, ".")
+		if _, ok := roots[path[0]]; !ok {
+			continue
+		}
+		p := placeholder{name: name, path: path}
+		if match[4] >= 0 {
+			p.defaultValue = strings.TrimSpace(string(content[match[4]:match[5]]))
+			p.hasDefault = true
+		}
+		value.literals = append(value.literals, string(content[literalStart:match[0]]))
+		value.placeholders = append(value.placeholders, p)
+		literalStart = match[1]
+	}
+	if len(value.placeholders) == 0 {
+		return nil
+	}
+	value.literals = append(value.literals, string(content[literalStart:]))
+	return &value
+}
+
+func (v *stringValue) render(buffer *bytes.Buffer, params Params) error {
+	// a placeholder which is the whole string is replaced by the typed value
+	if len(v.placeholders) == 1 && v.literals[0] == "" && v.literals[1] == "" {
+		value, err := v.placeholders[0].json(params)
+		if err != nil {
+			return err
+		}
+		buffer.Write(value)
+		return nil
+	}
+
+	buffer.WriteByte('"')
+	for i, p := range v.placeholders {
+		buffer.WriteString(v.literals[i])
+		text, err := p.text(params)
+		if err != nil {
+			return err
+		}
+		buffer.Write(text)
+	}
+	buffer.WriteString(v.literals[len(v.literals)-1])
+	buffer.WriteByte('"')
+	return nil
+}
+
+// withDefaults returns the value with a default for each placeholder which has none
+func (v *stringValue) withDefaults() *stringValue {
+	whole := len(v.placeholders) == 1 && v.literals[0] == "" && v.literals[1] == ""
+	value := stringValue{literals: v.literals, placeholders: make([]placeholder, len(v.placeholders))}
+	for i, p := range v.placeholders {
+		if !p.hasDefault {
+			p.hasDefault = true
+			if whole {
+				p.defaultValue = "null"
+			}
+		}
+		value.placeholders[i] = p
+	}
+	return &value
+}
+
+// json returns the value of the placeholder as JSON
+func (p placeholder) json(params Params) ([]byte, error) {
+	value, dataType, err := p.resolve(params)
+	if err != nil {
+		return nil, err
+	}
+	if dataType == jsonparser.String {
+		return quote(value), nil
+	}
+	return value, nil
+}
+
+// text returns the value of the placeholder as the escaped content of a JSON string
+func (p placeholder) text(params Params) ([]byte, error) {
+	value, dataType, err := p.resolve(params)
+	if err != nil {
+		return nil, err
+	}
+	if dataType == jsonparser.Object || dataType == jsonparser.Array {
+		return nil, fmt.Errorf("the parameter %s can't be embedded in a string", p.name)
+	}
+	return value, nil
+}
+
+// resolve returns the value of the placeholder, with strings as their escaped content
+func (p placeholder) resolve(params Params) ([]byte, jsonparser.ValueType, error) {
+	var value []byte
+	dataType := jsonparser.NotExist
+	switch p.path[0] {
+	case "account":
+		if len(p.path) == 2 && p.path[1] == "id" && params.AccountID != "" {
+			quoted, _ := json.Marshal(params.AccountID)
+			value, dataType = quoted[1:len(quoted)-1], jsonparser.String
+		}
+	case "imp":
+		value, dataType = get(params.Imp, p.path[1:])
+	case "ext":
+		value, dataType = get(params.Reference, p.path)
+	default:
+		value, dataType = get(params.Request, p.path)
+	}
+	if dataType != jsonparser.NotExist && dataType != jsonparser.Null {
+		return value, dataType, nil
+	}
+
+	if !p.hasDefault {
+		return nil, jsonparser.NotExist, fmt.Errorf("the required parameter %s is missing", p.name)
+	}
+	return defaultValue(p.defaultValue)
+}
+
+func get(data []byte, path []string) ([]byte, jsonparser.ValueType) {
+	if len(data) == 0 || len(path) == 0 {
+		return nil, jsonparser.NotExist
+	}
+	value, dataType, _, err := jsonparser.Get(data, path...)
+	if err != nil {
+		return nil, jsonparser.NotExist
+	}
+	return value, dataType
+}
+
+// defaultValue types the default: numbers, true, false and null are JSON, and anything else is a string
+func defaultValue(text string) ([]byte, jsonparser.ValueType, error) {
+	if text != "" && text[0] != '"' && text[0] != '{' && text[0] != '[' && json.Valid([]byte(text)) {
+		_, dataType, _, _ := jsonparser.Get([]byte(text))
+		return []byte(text), dataType, nil
+	}
+	return []byte(text), jsonparser.String, nil
+}
+
+func quote(content []byte) []byte {
+	quoted := make([]byte, 0, len(content)+2)
+	quoted = append(quoted, '"')
+	quoted = append(quoted, content...)
+	return append(quoted, '"')
+}
+
+// Cache keeps the parsed templates of the stored data, so they're only parsed once
+type Cache struct {
+	size int
+
+	mutex     sync.RWMutex
+	templates map[string]*Template
+}
+
+// NewCache returns a Cache of up to size templates. It's emptied when it's full.
+func NewCache(size int) *Cache {
+	return &Cache{
+		size:      size,
+		templates: make(map[string]*Template),
+	}
+}
+
+// Render returns data with its placeholders resolved against params, or data itself if it has none
+func (c *Cache) Render(data json.RawMessage, params Params) (json.RawMessage, error) {
+	if !bytes.Contains(data, []byte("{{")) {
+		return data, nil
+	}
+
+	t := c.get(data)
+	if t == nil {
+		return data, nil
+	}
+	return t.Render(params)
+}
+
+func (c *Cache) get(data []byte) *Template {
+	c.mutex.RLock()
+	t, ok := c.templates[string(data)]
+	c.mutex.RUnlock()
+	if ok {
+		return t
+	}
+
+	// the template keeps slices of the data, so it must not share it with the caller
+	t = Parse(append([]byte(nil), data...))
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if len(c.templates) >= c.size {
+		c.templates = make(map[string]*Template)
+	}
+	c.templates[string(data)] = t
+	return t
+}
@@ -0,0 +1,93 @@
+package templates
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRender(t *testing.T) {
+	params := Params{
+		Request:   []byte(`{"site":{"domain":"example.com","page":"https://example.com/a\"b"},"device":{"w":320}}`),
+		Imp:       []byte(`{"id":"imp-1","tagid":"tag-1","bidfloor":0.5}`),
+		Reference: []byte(`{"ext":{"prebid":{"storedrequest":{"id":"stored","params":{"placementId":123,"sizes":[[300,250]]}}}}}`),
+		AccountID: "acct",
+	}
+
+	testCases := []struct {
+		description   string
+		data          string
+		expectedData  string
+		expectedError string
+	}{
+		{
+			description:  "Typed Values",
+			data:         `{"tagid":"{{imp.tagid}}","bidfloor":"{{imp.bidfloor}}","ext":{"bidder":{"placementId":"{{ext.prebid.storedrequest.params.placementId}}","sizes":"{{ext.prebid.storedrequest.params.sizes}}"}}}`,
+			expectedData: `{"tagid":"tag-1","bidfloor":0.5,"ext":{"bidder":{"placementId":123,"sizes":[[300,250]]}}}`,
+		},
+		{
+			description:  "Embedded Values",
+			data:         `{"ext":{"key":"{{ account.id }}-{{site.domain}}-{{device.w}}","page":"{{site.page}}"}}`,
+			expectedData: `{"ext":{"key":"acct-example.com-320","page":"https://example.com/a\"b"}}`,
+		},
+		{
+			description:  "Defaults",
+			data:         `{"bidfloor":"{{ext.prebid.storedrequest.params.floor|0.01}}","secure":"{{imp.secure | 1}}","domain":"{{app.domain|app.example.com}}","key":"{{user.id|unknown}}-1"}`,
+			expectedData: `{"bidfloor":0.01,"secure":1,"domain":"app.example.com","key":"unknown-1"}`,
+		},
+		{
+			description:  "Not A Placeholder",
+			data:         `{"id":"{{UUID}}","{{site.domain}}":"{{unknown.path}}"}`,
+			expectedData: `{"id":"{{UUID}}","{{site.domain}}":"{{unknown.path}}"}`,
+		},
+		{
+			description:   "Missing Required Parameter",
+			data:          `{"tagid":"{{ext.prebid.storedrequest.params.tagid}}"}`,
+			expectedError: "the required parameter ext.prebid.storedrequest.params.tagid is missing",
+		},
+		{
+			description:   "Object Embedded In String",
+			data:          `{"tagid":"sizes-{{ext.prebid.storedrequest.params.sizes}}"}`,
+			expectedError: "the parameter ext.prebid.storedrequest.params.sizes can't be embedded in a string",
+		},
+	}
+
+	cache := NewCache(10)
+	for _, test := range testCases {
+		data, err := cache.Render(json.RawMessage(test.data), params)
+		if test.expectedError != "" {
+			assert.EqualError(t, err, test.expectedError, test.description)
+			continue
+		}
+		assert.NoError(t, err, test.description)
+		assert.JSONEq(t, test.expectedData, string(data), test.description)
+	}
+}
+
+func TestRenderDefaults(t *testing.T) {
+	data := `{"tagid":"{{imp.tagid}}","bidfloor":"{{imp.bidfloor|0.01}}","ext":{"key":"{{account.id}}-{{site.domain|example.com}}","id":"{{UUID}}"}}`
+
+	template := Parse([]byte(data))
+	if assert.NotNil(t, template) {
+		assert.JSONEq(t, `{"tagid":null,"bidfloor":0.01,"ext":{"key":"-example.com","id":"{{UUID}}"}}`, string(template.RenderDefaults()))
+	}
+}
+
+func TestCache(t *testing.T) {
+	cache := NewCache(2)
+
+	data := json.RawMessage(`{"tagid":"{{imp.tagid}}"}`)
+	first := cache.get(data)
+	assert.Same(t, first, cache.get(data), "the template should be parsed once")
+
+	cache.get([]byte(`{"id":"{{UUID}}"}`))
+	assert.Contains(t, cache.templates, `{"id":"{{UUID}}"}`, "the data without placeholders should be cached too")
+
+	cache.get([]byte(`{"tagid":"{{imp.id}}"}`))
+	assert.Len(t, cache.templates, 1, "the cache should be emptied once it's full")
+
+	rendered, err := cache.Render(json.RawMessage(`{"id":"1"}`), Params{})
+	assert.NoError(t, err)
+	assert.Equal(t, json.RawMessage(`{"id":"1"}`), rendered)
+}
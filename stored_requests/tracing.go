@@ -0,0 +1,74 @@
+package stored_requests
+
+import (
+	"context"
+	"encoding/json"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/prebid/prebid-server/v3/tracing"
+)
+
+type tracingFetcher struct {
+	fetcher  AllFetcher
+	dataType attribute.KeyValue
+}
+
+// WithTracing returns a fetcher which records a span for each fetch from the given fetcher. The spans are
+// tagged with the data type, since the requests, imps, responses and accounts may come from different backends.
+func WithTracing(fetcher AllFetcher, dataType string) AllFetcher {
+	return &tracingFetcher{
+		fetcher:  fetcher,
+		dataType: attribute.String("pbs.data_type", dataType),
+	}
+}
+
+func (f *tracingFetcher) FetchRequests(ctx context.Context, requestIDs []string, impIDs []string) (map[string]json.RawMessage, map[string]json.RawMessage, []error) {
+	ctx, span := tracing.Start(ctx, "stored_requests.fetch_requests", trace.WithAttributes(
+		f.dataType,
+		attribute.Int("pbs.request_ids", len(requestIDs)),
+		attribute.Int("pbs.imp_ids", len(impIDs)),
+	))
+	requestData, impData, errs := f.fetcher.FetchRequests(ctx, requestIDs, impIDs)
+	tracing.End(span, firstError(errs))
+	return requestData, impData, errs
+}
+
+func (f *tracingFetcher) FetchResponses(ctx context.Context, ids []string) (map[string]json.RawMessage, []error) {
+	ctx, span := tracing.Start(ctx, "stored_requests.fetch_responses", trace.WithAttributes(
+		f.dataType,
+		attribute.Int("pbs.response_ids", len(ids)),
+	))
+	data, errs := f.fetcher.FetchResponses(ctx, ids)
+	tracing.End(span, firstError(errs))
+	return data, errs
+}
+
+func (f *tracingFetcher) FetchAccount(ctx context.Context, accountDefaultJSON json.RawMessage, accountID string) (json.RawMessage, []error) {
+	ctx, span := tracing.Start(ctx, "stored_requests.fetch_account", trace.WithAttributes(
+		f.dataType,
+		attribute.String("pbs.account", accountID),
+	))
+	account, errs := f.fetcher.FetchAccount(ctx, accountDefaultJSON, accountID)
+	tracing.End(span, firstError(errs))
+	return account, errs
+}
+
+func (f *tracingFetcher) FetchCategories(ctx context.Context, primaryAdServer, publisherId, iabCategory string) (string, error) {
+	ctx, span := tracing.Start(ctx, "stored_requests.fetch_categories", trace.WithAttributes(f.dataType))
+	category, err := f.fetcher.FetchCategories(ctx, primaryAdServer, publisherId, iabCategory)
+	tracing.End(span, err)
+	return category, err
+}
+
+// firstError returns the first of errs which isn't a NotFoundError, since the IDs missing from a fetcher
+// are expected when several fetchers are chained.
+func firstError(errs []error) error {
+	for _, err := range errs {
+		if _, ok := err.(NotFoundError); !ok {
+			return err
+		}
+	}
+	return nil
+}
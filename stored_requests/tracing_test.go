@@ -0,0 +1,79 @@
+package stored_requests
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+
+	"github.com/prebid/prebid-server/v3/tracing/tracingtest"
+)
+
+func TestTracingFetcherFetchRequests(t *testing.T) {
+	testCases := []struct {
+		name               string
+		errs               []error
+		expectedStatusCode codes.Code
+	}{
+		{
+			name:               "found",
+			expectedStatusCode: codes.Unset,
+		},
+		{
+			name:               "not-found",
+			errs:               []error{NotFoundError{ID: "imp", DataType: "Imp"}},
+			expectedStatusCode: codes.Unset,
+		},
+		{
+			name:               "failed",
+			errs:               []error{NotFoundError{ID: "imp", DataType: "Imp"}, errors.New("backend down")},
+			expectedStatusCode: codes.Error,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.name, func(t *testing.T) {
+			exporter := tracingtest.Setup(t)
+
+			fetcher := &mockFetcher{}
+			fetcher.On("FetchRequests", mock.Anything, []string{"req"}, []string{"imp"}).Return(
+				map[string]json.RawMessage{"req": json.RawMessage(`{}`)}, map[string]json.RawMessage{}, test.errs)
+
+			requestData, _, errs := WithTracing(fetcher, "Request").FetchRequests(context.Background(), []string{"req"}, []string{"imp"})
+
+			assert.Equal(t, map[string]json.RawMessage{"req": json.RawMessage(`{}`)}, requestData)
+			assert.Equal(t, test.errs, errs)
+
+			spans := exporter.GetSpans()
+			if assert.Len(t, spans, 1) {
+				assert.Equal(t, "stored_requests.fetch_requests", spans[0].Name)
+				assert.Equal(t, test.expectedStatusCode, spans[0].Status.Code)
+				assert.Contains(t, spans[0].Attributes, attribute.String("pbs.data_type", "Request"))
+				assert.Contains(t, spans[0].Attributes, attribute.Int("pbs.imp_ids", 1))
+			}
+		})
+	}
+}
+
+func TestTracingFetcherFetchAccount(t *testing.T) {
+	exporter := tracingtest.Setup(t)
+
+	fetcher := &mockFetcher{}
+	fetcher.On("FetchAccount", mock.Anything, json.RawMessage(`{}`), "account").Return(json.RawMessage(`{"id":"account"}`), []error{})
+
+	account, errs := WithTracing(fetcher, "Account").FetchAccount(context.Background(), json.RawMessage(`{}`), "account")
+
+	assert.Equal(t, json.RawMessage(`{"id":"account"}`), account)
+	assert.Empty(t, errs)
+
+	spans := exporter.GetSpans()
+	if assert.Len(t, spans, 1) {
+		assert.Equal(t, "stored_requests.fetch_account", spans[0].Name)
+		assert.Contains(t, spans[0].Attributes, attribute.String("pbs.account", "account"))
+	}
+}
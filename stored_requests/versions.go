@@ -0,0 +1,248 @@
+package stored_requests
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/buger/jsonparser"
+	"github.com/prebid/prebid-server/v3/config"
+	"github.com/prebid/prebid-server/v3/openrtb_ext"
+	"github.com/prebid/prebid-server/v3/util/jsonutil"
+)
+
+// versionedData is a stored request or imp which holds several versions, e.g.
+//
+//	{"versions": {"v1": {...}, "v2": {...}}, "rollout": {"default": "v1", "split": [{"version": "v2", "percent": 10}]}}
+type versionedData struct {
+	Versions map[string]json.RawMessage   `json:"versions"`
+	Rollout  *config.StoredVersionRollout `json:"rollout"`
+}
+
+type versionSelectionKey struct{}
+
+// VersionSelection selects the versions of the versioned stored requests and imps fetched for a request,
+// and collects the versions which were resolved. It's passed to the Fetcher through the context.
+type VersionSelection struct {
+	// Pinned is the version requested for QA. It's used by every versioned entry which has it.
+	Pinned string
+	// Bucket places the request in the split of the rollouts, from 0 to 99
+	Bucket int
+	// Now is compared to the activation times of the rollouts
+	Now time.Time
+
+	mutex    sync.Mutex
+	rollouts map[string]config.StoredVersionRollout
+	requests map[string]string
+	imps     map[string]string
+}
+
+// NewVersionSelection returns the selection of a request, placed in a random bucket of the splits
+func NewVersionSelection(pinned string) *VersionSelection {
+	return &VersionSelection{
+		Pinned: pinned,
+		Bucket: rand.Intn(100),
+		Now:    time.Now(),
+	}
+}
+
+// WithVersionSelection returns a copy of ctx which carries the selection
+func WithVersionSelection(ctx context.Context, selection *VersionSelection) context.Context {
+	if selection == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, versionSelectionKey{}, selection)
+}
+
+// VersionSelectionFromContext returns the selection carried by ctx, or nil
+func VersionSelectionFromContext(ctx context.Context) *VersionSelection {
+	selection, _ := ctx.Value(versionSelectionKey{}).(*VersionSelection)
+	return selection
+}
+
+// UseAccountRollouts sets the rollouts of the account, which win over the rollouts of the stored entries.
+// It returns true if they apply to the entries resolved so far, which must then be fetched again.
+func (s *VersionSelection) UseAccountRollouts(rollouts map[string]config.StoredVersionRollout) bool {
+	if s == nil {
+		return false
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.rollouts = rollouts
+	for id := range rollouts {
+		if _, ok := s.requests[id]; ok {
+			return true
+		}
+		if _, ok := s.imps[id]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// Resolved returns the versions resolved for the request, or nil if it didn't use any versioned entry
+func (s *VersionSelection) Resolved() *openrtb_ext.ExtStoredVersions {
+	if s == nil {
+		return nil
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if len(s.requests) == 0 && len(s.imps) == 0 {
+		return nil
+	}
+	resolved := &openrtb_ext.ExtStoredVersions{}
+	for id, version := range s.requests {
+		if resolved.Requests == nil {
+			resolved.Requests = make(map[string]string, len(s.requests))
+		}
+		resolved.Requests[id] = version
+	}
+	for id, version := range s.imps {
+		if resolved.Imps == nil {
+			resolved.Imps = make(map[string]string, len(s.imps))
+		}
+		resolved.Imps[id] = version
+	}
+	return resolved
+}
+
+func (s *VersionSelection) resolve(id string, data json.RawMessage) (string, json.RawMessage, error) {
+	var versioned versionedData
+	if err := jsonutil.UnmarshalValid(data, &versioned); err != nil {
+		return "", nil, err
+	}
+
+	if _, ok := versioned.Versions[s.Pinned]; ok && s.Pinned != "" {
+		return s.Pinned, versioned.Versions[s.Pinned], nil
+	}
+
+	s.mutex.Lock()
+	rollout, ok := s.rollouts[id]
+	s.mutex.Unlock()
+	if !ok && versioned.Rollout != nil {
+		rollout = *versioned.Rollout
+	}
+
+	version, err := s.selectVersion(rollout)
+	if err != nil {
+		return "", nil, err
+	}
+	versionData, ok := versioned.Versions[version]
+	if !ok {
+		return "", nil, fmt.Errorf("version %s doesn't exist", version)
+	}
+	return version, versionData, nil
+}
+
+func (s *VersionSelection) selectVersion(rollout config.StoredVersionRollout) (string, error) {
+	if err := rollout.Validate(); err != nil {
+		return "", err
+	}
+
+	cumulative := 0
+	for _, split := range rollout.Split {
+		cumulative += split.Percent
+		if s.Bucket < cumulative {
+			return split.Version, nil
+		}
+	}
+
+	version := rollout.Default
+	var activatedAt time.Time
+	for _, activation := range rollout.Schedule {
+		at, _ := time.Parse(time.RFC3339, activation.ActivateAt)
+		if !at.After(s.Now) && !at.Before(activatedAt) {
+			version = activation.Version
+			activatedAt = at
+		}
+	}
+	if version == "" {
+		return "", fmt.Errorf("no version is selected by default")
+	}
+	return version, nil
+}
+
+func (s *VersionSelection) record(dataType string, id string, version string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	resolved := &s.requests
+	if dataType == "Imp" {
+		resolved = &s.imps
+	}
+	if *resolved == nil {
+		*resolved = make(map[string]string)
+	}
+	(*resolved)[id] = version
+}
+
+// isVersioned returns true if the stored data holds several versions
+func isVersioned(data json.RawMessage) bool {
+	_, valueType, _, err := jsonparser.Get(data, "versions")
+	return err == nil && valueType == jsonparser.Object
+}
+
+type versionsFetcher struct {
+	AllFetcher
+}
+
+// WithVersions returns a fetcher which resolves the versioned stored requests and imps of the given fetcher
+// to the version selected by the VersionSelection of the context. It must wrap the cache, since the cache
+// holds every version of the versioned entries.
+func WithVersions(fetcher AllFetcher) AllFetcher {
+	return &versionsFetcher{
+		AllFetcher: fetcher,
+	}
+}
+
+func (f *versionsFetcher) FetchRequests(ctx context.Context, requestIDs []string, impIDs []string) (map[string]json.RawMessage, map[string]json.RawMessage, []error) {
+	requestData, impData, errs := f.AllFetcher.FetchRequests(ctx, requestIDs, impIDs)
+	requestData, versionErrs := resolveVersions(ctx, requestData, "Request")
+	errs = append(errs, versionErrs...)
+	impData, versionErrs = resolveVersions(ctx, impData, "Imp")
+	errs = append(errs, versionErrs...)
+	return requestData, impData, errs
+}
+
+// resolveVersions replaces the versioned entries of data with the version selected by the selection of ctx.
+// The entries which can't be resolved are removed, and an error is returned for each of them. The data map
+// isn't written to.
+func resolveVersions(ctx context.Context, data map[string]json.RawMessage, dataType string) (map[string]json.RawMessage, []error) {
+	var resolved map[string]json.RawMessage
+	var errs []error
+
+	selection := VersionSelectionFromContext(ctx)
+	for id, entry := range data {
+		if !isVersioned(entry) {
+			continue
+		}
+		if resolved == nil {
+			resolved = make(map[string]json.RawMessage, len(data))
+			for id, entry := range data {
+				resolved[id] = entry
+			}
+		}
+		if selection == nil {
+			selection = NewVersionSelection("")
+		}
+
+		version, versionData, err := selection.resolve(id, entry)
+		if err != nil {
+			delete(resolved, id)
+			errs = append(errs, fmt.Errorf(`Stored %s with ID="%s" has no valid version: %v`, dataType, id, err))
+			continue
+		}
+		resolved[id] = versionData
+		selection.record(dataType, id, version)
+	}
+
+	if resolved == nil {
+		return data, nil
+	}
+	return resolved, errs
+}
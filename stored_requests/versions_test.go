@@ -0,0 +1,155 @@
+package stored_requests
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/prebid/prebid-server/v3/config"
+	"github.com/prebid/prebid-server/v3/openrtb_ext"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResolveVersions(t *testing.T) {
+	now := time.Date(2026, 6, 1, 12, 0, 0, 0, time.UTC)
+	versioned := json.RawMessage(`{
+		"versions": {"v1": {"v":1}, "v2": {"v":2}, "v3": {"v":3}},
+		"rollout": {
+			"default": "v1",
+			"schedule": [{"version": "v2", "activate_at": "2026-05-01T00:00:00Z"}, {"version": "v3", "activate_at": "2026-07-01T00:00:00Z"}],
+			"split": [{"version": "v3", "percent": 10}]
+		}
+	}`)
+
+	testCases := []struct {
+		description       string
+		selection         *VersionSelection
+		accountRollouts   map[string]config.StoredVersionRollout
+		data              json.RawMessage
+		expectedData      json.RawMessage
+		expectedVersion   string
+		expectedErrsCount int
+	}{
+		{
+			description:  "Not Versioned",
+			selection:    &VersionSelection{Now: now},
+			data:         json.RawMessage(`{"id":"1"}`),
+			expectedData: json.RawMessage(`{"id":"1"}`),
+		},
+		{
+			description:     "Split",
+			selection:       &VersionSelection{Bucket: 5, Now: now},
+			data:            versioned,
+			expectedData:    json.RawMessage(`{"v":3}`),
+			expectedVersion: "v3",
+		},
+		{
+			description:     "Activated Schedule",
+			selection:       &VersionSelection{Bucket: 50, Now: now},
+			data:            versioned,
+			expectedData:    json.RawMessage(`{"v":2}`),
+			expectedVersion: "v2",
+		},
+		{
+			description:     "Default Before Schedule",
+			selection:       &VersionSelection{Bucket: 50, Now: now.AddDate(-1, 0, 0)},
+			data:            versioned,
+			expectedData:    json.RawMessage(`{"v":1}`),
+			expectedVersion: "v1",
+		},
+		{
+			description:     "Pinned",
+			selection:       &VersionSelection{Pinned: "v1", Bucket: 5, Now: now},
+			data:            versioned,
+			expectedData:    json.RawMessage(`{"v":1}`),
+			expectedVersion: "v1",
+		},
+		{
+			description:     "Pinned Version Missing",
+			selection:       &VersionSelection{Pinned: "v9", Bucket: 50, Now: now},
+			data:            versioned,
+			expectedData:    json.RawMessage(`{"v":2}`),
+			expectedVersion: "v2",
+		},
+		{
+			description:     "Account Rollout",
+			selection:       &VersionSelection{Bucket: 50, Now: now},
+			accountRollouts: map[string]config.StoredVersionRollout{"id": {Default: "v3"}},
+			data:            versioned,
+			expectedData:    json.RawMessage(`{"v":3}`),
+			expectedVersion: "v3",
+		},
+		{
+			description:       "No Default",
+			selection:         &VersionSelection{Now: now},
+			data:              json.RawMessage(`{"versions":{"v1":{}}}`),
+			expectedErrsCount: 1,
+		},
+		{
+			description:       "Unknown Version",
+			selection:         &VersionSelection{Now: now},
+			data:              json.RawMessage(`{"versions":{"v1":{}},"rollout":{"default":"v2"}}`),
+			expectedErrsCount: 1,
+		},
+		{
+			description:       "Invalid Split",
+			selection:         &VersionSelection{Now: now},
+			data:              json.RawMessage(`{"versions":{"v1":{}},"rollout":{"default":"v1","split":[{"version":"v1","percent":101}]}}`),
+			expectedErrsCount: 1,
+		},
+	}
+
+	for _, test := range testCases {
+		test.selection.UseAccountRollouts(test.accountRollouts)
+		ctx := WithVersionSelection(context.Background(), test.selection)
+
+		data := map[string]json.RawMessage{"id": test.data}
+		resolved, errs := resolveVersions(ctx, data, "Request")
+		assert.Len(t, errs, test.expectedErrsCount, test.description)
+		assert.Equal(t, test.data, data["id"], test.description+": the data shouldn't be written to")
+		if test.expectedData != nil {
+			assert.JSONEq(t, string(test.expectedData), string(resolved["id"]), test.description)
+		} else {
+			assert.NotContains(t, resolved, "id", test.description)
+		}
+		if test.expectedVersion != "" {
+			assert.Equal(t, &openrtb_ext.ExtStoredVersions{Requests: map[string]string{"id": test.expectedVersion}}, test.selection.Resolved(), test.description)
+		} else {
+			assert.Nil(t, test.selection.Resolved(), test.description)
+		}
+	}
+}
+
+func TestUseAccountRollouts(t *testing.T) {
+	selection := &VersionSelection{}
+	selection.record("Imp", "imp", "v1")
+
+	assert.False(t, selection.UseAccountRollouts(nil), "no rollouts")
+	assert.False(t, selection.UseAccountRollouts(map[string]config.StoredVersionRollout{"other": {Default: "v2"}}), "rollouts of other entries")
+	assert.True(t, selection.UseAccountRollouts(map[string]config.StoredVersionRollout{"imp": {Default: "v2"}}), "rollout of a resolved imp")
+
+	var nilSelection *VersionSelection
+	assert.False(t, nilSelection.UseAccountRollouts(map[string]config.StoredVersionRollout{"imp": {Default: "v2"}}), "nil selection")
+	assert.Nil(t, nilSelection.Resolved(), "nil selection")
+}
+
+func TestWithVersions(t *testing.T) {
+	selection := &VersionSelection{Pinned: "v2", Now: time.Now()}
+	ctx := WithVersionSelection(context.Background(), selection)
+
+	fetcher := &mockFetcher{}
+	fetcher.On("FetchRequests", ctx, []string{"req"}, []string{"imp"}).Return(
+		map[string]json.RawMessage{"req": json.RawMessage(`{"versions":{"v1":{"tmax":100},"v2":{"tmax":200}},"rollout":{"default":"v1"}}`)},
+		map[string]json.RawMessage{"imp": json.RawMessage(`{"id":"imp"}`)},
+		[]error{},
+	)
+
+	reqData, impData, errs := WithVersions(fetcher).FetchRequests(ctx, []string{"req"}, []string{"imp"})
+
+	fetcher.AssertExpectations(t)
+	assert.Empty(t, errs)
+	assert.JSONEq(t, `{"tmax":200}`, string(reqData["req"]), "The version of the request should be resolved without a cache")
+	assert.JSONEq(t, `{"id":"imp"}`, string(impData["imp"]), "The imps without versions should be returned as is")
+	assert.Equal(t, map[string]string{"req": "v2"}, selection.Resolved().Requests)
+}
@@ -0,0 +1,68 @@
+package tracing
+
+import (
+	"context"
+	"time"
+
+	"github.com/golang/glog"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+
+	"github.com/prebid/prebid-server/v3/config"
+	"github.com/prebid/prebid-server/v3/version"
+)
+
+const shutdownTimeout = 5 * time.Second
+
+// Setup registers the global tracer provider, which exports the spans over OTLP. The returned function flushes
+// the spans and stops the exporter.
+func Setup(cfg config.Tracing) (func(), error) {
+	if !cfg.Enabled {
+		return func() {}, nil
+	}
+
+	options := []otlptracehttp.Option{
+		otlptracehttp.WithEndpoint(cfg.OTLP.Endpoint),
+		otlptracehttp.WithTimeout(time.Duration(cfg.OTLP.TimeoutMS) * time.Millisecond),
+	}
+	if cfg.OTLP.Insecure {
+		options = append(options, otlptracehttp.WithInsecure())
+	}
+	if len(cfg.OTLP.Headers) > 0 {
+		options = append(options, otlptracehttp.WithHeaders(cfg.OTLP.Headers))
+	}
+	exporter, err := otlptracehttp.New(context.Background(), options...)
+	if err != nil {
+		return nil, err
+	}
+
+	provider := newTracerProvider(cfg, sdktrace.WithBatcher(exporter))
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagator)
+
+	return func() {
+		ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+		if err := provider.Shutdown(ctx); err != nil {
+			glog.Errorf("Failed to flush the spans: %v", err)
+		}
+	}, nil
+}
+
+func newTracerProvider(cfg config.Tracing, options ...sdktrace.TracerProviderOption) *sdktrace.TracerProvider {
+	serviceVersion := version.Ver
+	if serviceVersion == "" {
+		serviceVersion = version.VerUnknown
+	}
+	options = append(options,
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(cfg.SampleRate))),
+		sdktrace.WithResource(resource.NewSchemaless(
+			attribute.String("service.name", cfg.ServiceName),
+			attribute.String("service.version", serviceVersion),
+		)),
+	)
+	return sdktrace.NewTracerProvider(options...)
+}
@@ -0,0 +1,49 @@
+// Package tracing instruments the requests with OpenTelemetry spans. The spans are no-ops unless Setup
+// registers a tracer provider, so the instrumented code calls Start and End whether tracing is enabled or not.
+package tracing
+
+import (
+	"context"
+	"net/http"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const instrumentationName = "github.com/prebid/prebid-server/v3"
+
+// propagator reads and writes the W3C traceparent and tracestate headers
+var propagator = propagation.TraceContext{}
+
+// Start starts a span, child of the span of ctx if any
+func Start(ctx context.Context, name string, opts ...trace.SpanStartOption) (context.Context, trace.Span) {
+	// The tracer is fetched from the global provider on each span, so that the spans follow the provider set last
+	return otel.Tracer(instrumentationName).Start(ctx, name, opts...)
+}
+
+// End ends the span, marking it as failed if err isn't nil
+func End(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// Detach returns a context carrying the span of ctx, but none of its deadline, cancellation and values. The
+// auctions run on such contexts, so that they carry on if the client goes away.
+func Detach(ctx context.Context) context.Context {
+	return trace.ContextWithSpan(context.Background(), trace.SpanFromContext(ctx))
+}
+
+// Extract returns ctx with the remote span of the traceparent header, if any
+func Extract(ctx context.Context, header http.Header) context.Context {
+	return propagator.Extract(ctx, propagation.HeaderCarrier(header))
+}
+
+// Inject sets the traceparent header of the span of ctx, if any
+func Inject(ctx context.Context, header http.Header) {
+	propagator.Inject(ctx, propagation.HeaderCarrier(header))
+}
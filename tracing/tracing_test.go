@@ -0,0 +1,145 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/prebid/prebid-server/v3/config"
+	"github.com/prebid/prebid-server/v3/tracing/tracingtest"
+)
+
+type contextKey string
+
+func TestEnd(t *testing.T) {
+	exporter := tracingtest.Setup(t)
+
+	_, succeeded := Start(context.Background(), "succeeded")
+	End(succeeded, nil)
+	_, failed := Start(context.Background(), "failed")
+	End(failed, errors.New("timed out"))
+
+	spans := exporter.GetSpans()
+	if assert.Len(t, spans, 2) {
+		assert.Equal(t, codes.Unset, spans[0].Status.Code)
+		assert.Empty(t, spans[0].Events)
+		assert.Equal(t, codes.Error, spans[1].Status.Code)
+		assert.Equal(t, "timed out", spans[1].Status.Description)
+		assert.Len(t, spans[1].Events, 1)
+	}
+}
+
+func TestDetach(t *testing.T) {
+	tracingtest.Setup(t)
+
+	ctx, span := Start(context.WithValue(context.Background(), contextKey("key"), "value"), "parent")
+	defer span.End()
+	ctx, cancel := context.WithTimeout(ctx, time.Millisecond)
+	cancel()
+
+	detached := Detach(ctx)
+
+	assert.NoError(t, detached.Err())
+	_, hasDeadline := detached.Deadline()
+	assert.False(t, hasDeadline)
+	assert.Nil(t, detached.Value(contextKey("key")))
+	assert.Equal(t, span.SpanContext(), trace.SpanContextFromContext(detached))
+}
+
+func TestInjectExtract(t *testing.T) {
+	tracingtest.Setup(t)
+
+	ctx, span := Start(context.Background(), "caller")
+	defer span.End()
+
+	header := http.Header{}
+	Inject(ctx, header)
+	assert.NotEmpty(t, header.Get("traceparent"))
+
+	remote := trace.SpanContextFromContext(Extract(context.Background(), header))
+	assert.True(t, remote.IsRemote())
+	assert.Equal(t, span.SpanContext().TraceID(), remote.TraceID())
+	assert.Equal(t, span.SpanContext().SpanID(), remote.SpanID())
+}
+
+func TestInjectWithoutSpan(t *testing.T) {
+	header := http.Header{}
+	Inject(context.Background(), header)
+	assert.Empty(t, header)
+}
+
+func TestSetupDisabled(t *testing.T) {
+	shutdown, err := Setup(config.Tracing{Enabled: false})
+	assert.NoError(t, err)
+	assert.NotNil(t, shutdown)
+	shutdown()
+}
+
+func TestNewTracerProviderSampling(t *testing.T) {
+	sampled := trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    trace.TraceID{0x4b, 0xf9},
+		SpanID:     trace.SpanID{0x01},
+		TraceFlags: trace.FlagsSampled,
+		Remote:     true,
+	})
+	notSampled := sampled.WithTraceFlags(0)
+
+	testCases := []struct {
+		name            string
+		sampleRate      float64
+		parent          trace.SpanContext
+		expectedSampled bool
+	}{
+		{
+			name:            "no-parent-never-sampled",
+			sampleRate:      0,
+			expectedSampled: false,
+		},
+		{
+			name:            "no-parent-always-sampled",
+			sampleRate:      1,
+			expectedSampled: true,
+		},
+		{
+			name:            "sampled-parent",
+			sampleRate:      0,
+			parent:          sampled,
+			expectedSampled: true,
+		},
+		{
+			name:            "not-sampled-parent",
+			sampleRate:      1,
+			parent:          notSampled,
+			expectedSampled: false,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.name, func(t *testing.T) {
+			exporter := tracetest.NewInMemoryExporter()
+			provider := newTracerProvider(config.Tracing{ServiceName: "pbs", SampleRate: test.sampleRate}, sdktrace.WithSyncer(exporter))
+			defer provider.Shutdown(context.Background())
+
+			ctx := trace.ContextWithRemoteSpanContext(context.Background(), test.parent)
+			_, span := provider.Tracer(instrumentationName).Start(ctx, "span")
+			span.End()
+
+			assert.Equal(t, test.expectedSampled, span.SpanContext().IsSampled())
+			if test.expectedSampled {
+				spans := exporter.GetSpans()
+				if assert.Len(t, spans, 1) {
+					serviceName, _ := spans[0].Resource.Set().Value("service.name")
+					assert.Equal(t, "pbs", serviceName.AsString())
+				}
+			}
+		})
+	}
+}
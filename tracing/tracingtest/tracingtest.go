@@ -0,0 +1,35 @@
+// Package tracingtest records the spans of the tests in memory
+package tracingtest
+
+import (
+	"context"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+// Setup registers a global tracer provider which samples all the spans, and records them in the returned
+// exporter once they end. The previous provider is restored once the test is over.
+func Setup(t *testing.T) *tracetest.InMemoryExporter {
+	exporter := tracetest.NewInMemoryExporter()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter), sdktrace.WithSampler(sdktrace.AlwaysSample()))
+
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(provider)
+	t.Cleanup(func() {
+		otel.SetTracerProvider(previous)
+		provider.Shutdown(context.Background())
+	})
+	return exporter
+}
+
+// SpanNames returns the names of the spans, in the order they ended
+func SpanNames(spans tracetest.SpanStubs) []string {
+	names := make([]string, 0, len(spans))
+	for _, span := range spans {
+		names = append(names, span.Name)
+	}
+	return names
+}
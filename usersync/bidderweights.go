@@ -0,0 +1,125 @@
+package usersync
+
+import (
+	"math"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prebid/prebid-server/v3/metrics"
+	metricsConf "github.com/prebid/prebid-server/v3/metrics/config"
+	"github.com/prebid/prebid-server/v3/util/timeutil"
+)
+
+// BidderWeights tells how much syncing each bidder is worth to the weighted chooser strategy.
+type BidderWeights interface {
+	// Weight returns the weight of the bidder, or false when the weight of the bidder is unknown.
+	Weight(bidder string) (float64, bool)
+}
+
+// StaticBidderWeights are bidder weights set in config, keyed by lower case bidder name.
+type StaticBidderWeights map[string]float64
+
+// NewStaticBidderWeights returns the weights of the bidders, matching the bidder names case insensitively.
+func NewStaticBidderWeights(weights map[string]float64) StaticBidderWeights {
+	staticWeights := make(StaticBidderWeights, len(weights))
+	for bidder, weight := range weights {
+		staticWeights[strings.ToLower(bidder)] = weight
+	}
+	return staticWeights
+}
+
+func (w StaticBidderWeights) Weight(bidder string) (float64, bool) {
+	weight, ok := w[strings.ToLower(bidder)]
+	return weight, ok
+}
+
+// BidderStats weighs the bidders by their bid rate times their average CPM, as seen in the auctions.
+// It gathers the stats as a metrics engine, so it must be added to the metrics engines of the auction.
+// Older auction outcomes count less, halving in weight every half life.
+type BidderStats struct {
+	metricsConf.NilMetricsEngine
+
+	halfLife time.Duration
+	time     timeutil.Time
+
+	mutex   sync.Mutex
+	bidders map[string]*bidderStat
+}
+
+// bidderStat holds the decayed sums of the auction outcomes of a bidder
+type bidderStat struct {
+	requests     float64
+	requestsBids float64
+	bids         float64
+	cpm          float64
+	updated      time.Time
+}
+
+// NewBidderStats returns empty bidder stats decaying with halfLife.
+func NewBidderStats(halfLife time.Duration) *BidderStats {
+	return &BidderStats{
+		halfLife: halfLife,
+		time:     &timeutil.RealTime{},
+		bidders:  make(map[string]*bidderStat),
+	}
+}
+
+// RecordAdapterRequest counts a bidder request, and whether it returned bids
+func (s *BidderStats) RecordAdapterRequest(labels metrics.AdapterLabels) {
+	s.update(string(labels.Adapter), func(stat *bidderStat) {
+		stat.requests++
+		if labels.AdapterBids == metrics.AdapterBidPresent {
+			stat.requestsBids++
+		}
+	})
+}
+
+// RecordAdapterPrice adds up the bid prices of a bidder
+func (s *BidderStats) RecordAdapterPrice(labels metrics.AdapterLabels, cpm float64) {
+	s.update(string(labels.Adapter), func(stat *bidderStat) {
+		stat.bids++
+		stat.cpm += cpm
+	})
+}
+
+func (s *BidderStats) update(bidder string, record func(stat *bidderStat)) {
+	now := s.time.Now()
+	key := strings.ToLower(bidder)
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	stat, ok := s.bidders[key]
+	if !ok {
+		stat = &bidderStat{updated: now}
+		s.bidders[key] = stat
+	}
+	if elapsed := now.Sub(stat.updated); elapsed > 0 {
+		decay := math.Pow(0.5, float64(elapsed)/float64(s.halfLife))
+		stat.requests *= decay
+		stat.requestsBids *= decay
+		stat.bids *= decay
+		stat.cpm *= decay
+		stat.updated = now
+	}
+	record(stat)
+}
+
+// Weight returns the bid rate of the bidder times its average CPM, or false when the bidder hasn't
+// been requested yet.
+func (s *BidderStats) Weight(bidder string) (float64, bool) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	stat, ok := s.bidders[strings.ToLower(bidder)]
+	if !ok || stat.requests == 0 {
+		return 0, false
+	}
+	if stat.bids == 0 {
+		return 0, true
+	}
+	bidRate := stat.requestsBids / stat.requests
+	averageCPM := stat.cpm / stat.bids
+	return bidRate * averageCPM, true
+}
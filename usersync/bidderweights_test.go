@@ -0,0 +1,91 @@
+package usersync
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prebid/prebid-server/v3/metrics"
+	"github.com/prebid/prebid-server/v3/openrtb_ext"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStaticBidderWeights(t *testing.T) {
+	weights := NewStaticBidderWeights(map[string]float64{"AppNexus": 2})
+
+	weight, known := weights.Weight("appnexus")
+	assert.True(t, known)
+	assert.Equal(t, 2.0, weight)
+
+	_, known = weights.Weight("rubicon")
+	assert.False(t, known)
+}
+
+func TestBidderStatsWeight(t *testing.T) {
+	testCases := []struct {
+		description    string
+		record         func(stats *BidderStats)
+		expectedWeight float64
+		expectedKnown  bool
+	}{
+		{
+			description:   "Never Requested",
+			record:        func(stats *BidderStats) {},
+			expectedKnown: false,
+		},
+		{
+			description: "No Bids",
+			record: func(stats *BidderStats) {
+				stats.RecordAdapterRequest(metrics.AdapterLabels{Adapter: "appnexus", AdapterBids: metrics.AdapterBidNone})
+			},
+			expectedWeight: 0,
+			expectedKnown:  true,
+		},
+		{
+			description: "Bid Rate Times Average CPM",
+			record: func(stats *BidderStats) {
+				stats.RecordAdapterPrice(metrics.AdapterLabels{Adapter: "appnexus"}, 2)
+				stats.RecordAdapterPrice(metrics.AdapterLabels{Adapter: "appnexus"}, 4)
+				stats.RecordAdapterRequest(metrics.AdapterLabels{Adapter: "appnexus", AdapterBids: metrics.AdapterBidPresent})
+				stats.RecordAdapterRequest(metrics.AdapterLabels{Adapter: "appnexus", AdapterBids: metrics.AdapterBidNone})
+				stats.RecordAdapterRequest(metrics.AdapterLabels{Adapter: "rubicon", AdapterBids: metrics.AdapterBidNone})
+			},
+			expectedWeight: 1.5,
+			expectedKnown:  true,
+		},
+	}
+
+	for _, test := range testCases {
+		stats := NewBidderStats(time.Hour)
+		stats.time = &fakeTime{time: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+		test.record(stats)
+
+		weight, known := stats.Weight("AppNexus")
+		assert.Equal(t, test.expectedKnown, known, test.description)
+		assert.Equal(t, test.expectedWeight, weight, test.description)
+	}
+}
+
+func TestBidderStatsDecay(t *testing.T) {
+	now := &fakeTime{time: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+	stats := NewBidderStats(time.Hour)
+	stats.time = now
+	labels := metrics.AdapterLabels{Adapter: openrtb_ext.BidderAppnexus}
+
+	// an hour old request without bids counts half as much as a recent one with a bid
+	stats.RecordAdapterRequest(metrics.AdapterLabels{Adapter: openrtb_ext.BidderAppnexus, AdapterBids: metrics.AdapterBidNone})
+	now.time = now.time.Add(time.Hour)
+	stats.RecordAdapterPrice(labels, 3)
+	stats.RecordAdapterRequest(metrics.AdapterLabels{Adapter: openrtb_ext.BidderAppnexus, AdapterBids: metrics.AdapterBidPresent})
+
+	weight, known := stats.Weight("appnexus")
+	assert.True(t, known)
+	assert.InDelta(t, 2.0, weight, 1e-9)
+}
+
+type fakeTime struct {
+	time time.Time
+}
+
+func (t *fakeTime) Now() time.Time {
+	return t.time
+}
@@ -26,6 +26,7 @@ func NewChooser(bidderSyncerLookup map[string]Syncer, biddersKnown map[string]st
 		bidderSyncerLookup:       bidderSyncerLookup,
 		biddersAvailable:         bidders,
 		bidderChooser:            standardBidderChooser{shuffler: randomShuffler{}},
+		random:                   globalRandomSource{},
 		normalizeValidBidderName: openrtb_ext.NormalizeBidderName,
 		biddersKnown:             biddersKnown,
 		bidderInfo:               bidderInfo,
@@ -41,6 +42,8 @@ type Request struct {
 	SyncTypeFilter SyncTypeFilter
 	GPPSID         string
 	Debug          bool
+	// BidderWeights orders the bidders by weight rather than at random when set.
+	BidderWeights BidderWeights
 }
 
 // Cooperative specifies the settings for cooperative syncing for a given request, where bidders
@@ -55,6 +58,8 @@ type Result struct {
 	BiddersEvaluated []BidderEvaluation
 	Status           Status
 	SyncersChosen    []SyncerChoice
+	// BidderOrder holds the weights of the evaluated bidders, in order, when the bidders are ordered by weight.
+	BidderOrder []BidderWeight
 }
 
 // BidderEvaluation specifies which bidders were considered to be synced.
@@ -64,6 +69,13 @@ type BidderEvaluation struct {
 	Status    Status
 }
 
+// BidderWeight specifies the weight of a bidder, if known.
+type BidderWeight struct {
+	Bidder string
+	Weight float64
+	Known  bool
+}
+
 // SyncerChoice specifies a syncer chosen.
 type SyncerChoice struct {
 	Bidder string
@@ -122,6 +134,7 @@ type standardChooser struct {
 	normalizeValidBidderName func(name string) (openrtb_ext.BidderName, bool)
 	biddersKnown             map[string]struct{}
 	bidderInfo               map[string]config.BidderInfo
+	random                   randomSource
 }
 
 // Choose randomly selects user syncers which are permitted by the user's privacy settings and
@@ -142,7 +155,12 @@ func (c standardChooser) Choose(request Request, cookie *Cookie) Result {
 	biddersEvaluated := make([]BidderEvaluation, 0)
 	syncersChosen := make([]SyncerChoice, 0)
 
-	bidders := c.bidderChooser.choose(request.Bidders, c.biddersAvailable, request.Cooperative)
+	bidderChooser := c.bidderChooser
+	if request.BidderWeights != nil {
+		bidderChooser = standardBidderChooser{shuffler: weightedShuffler{weights: request.BidderWeights, random: c.random}}
+	}
+
+	bidders := bidderChooser.choose(request.Bidders, c.biddersAvailable, request.Cooperative)
 	for i := 0; i < len(bidders) && (limitDisabled || len(syncersChosen) < request.Limit); i++ {
 		if _, ok := biddersSeen[bidders[i]]; ok {
 			continue
@@ -156,7 +174,15 @@ func (c standardChooser) Choose(request Request, cookie *Cookie) Result {
 		biddersSeen[bidders[i]] = struct{}{}
 	}
 
-	return Result{Status: StatusOK, BiddersEvaluated: biddersEvaluated, SyncersChosen: syncersChosen}
+	result := Result{Status: StatusOK, BiddersEvaluated: biddersEvaluated, SyncersChosen: syncersChosen}
+	if request.BidderWeights != nil {
+		result.BidderOrder = make([]BidderWeight, 0, len(biddersEvaluated))
+		for _, evaluation := range biddersEvaluated {
+			weight, known := request.BidderWeights.Weight(evaluation.Bidder)
+			result.BidderOrder = append(result.BidderOrder, BidderWeight{Bidder: evaluation.Bidder, Weight: weight, Known: known})
+		}
+	}
+	return result
 }
 
 func (c standardChooser) evaluate(bidder string, syncersSeen map[string]struct{}, syncTypeFilter SyncTypeFilter, privacy Privacy, cookie *Cookie, GPPSID string) (Syncer, BidderEvaluation) {
@@ -1,6 +1,7 @@
 package usersync
 
 import (
+	"math/rand"
 	"testing"
 	"time"
 
@@ -451,6 +452,46 @@ func TestChooserChoose(t *testing.T) {
 	}
 }
 
+func TestChooserChooseWeighted(t *testing.T) {
+	bidderSyncerLookup := map[string]Syncer{
+		"a": fakeSyncer{key: "keyA", supportsIFrame: true},
+		"b": fakeSyncer{key: "keyB", supportsIFrame: true},
+		"c": fakeSyncer{key: "keyC", supportsIFrame: true},
+	}
+	normalizedBidderNamesLookup := func(name string) (openrtb_ext.BidderName, bool) {
+		return openrtb_ext.BidderName(name), true
+	}
+	chooser := standardChooser{
+		bidderSyncerLookup:       bidderSyncerLookup,
+		biddersAvailable:         []string{"a", "b", "c"},
+		bidderChooser:            standardBidderChooser{shuffler: reverseShuffler{}},
+		normalizeValidBidderName: normalizedBidderNamesLookup,
+		biddersKnown:             map[string]struct{}{"a": {}, "b": {}, "c": {}},
+		bidderInfo:               map[string]config.BidderInfo{},
+		random:                   rand.New(rand.NewSource(1)),
+	}
+	request := Request{
+		Bidders: []string{"a", "b", "c"},
+		Limit:   2,
+		Privacy: &fakePrivacy{gdprAllowsHostCookie: true, gdprAllowsBidderSync: true, ccpaAllowsBidderSync: true, activityAllowUserSync: true},
+		SyncTypeFilter: SyncTypeFilter{
+			IFrame:   NewUniformBidderFilter(BidderFilterModeInclude),
+			Redirect: NewUniformBidderFilter(BidderFilterModeExclude),
+		},
+		BidderWeights: StaticBidderWeights{"a": 1, "b": 0, "c": 1e6},
+	}
+
+	result := chooser.Choose(request, &Cookie{})
+
+	assert.Equal(t, []SyncerChoice{{Bidder: "c", Syncer: bidderSyncerLookup["c"]}, {Bidder: "a", Syncer: bidderSyncerLookup["a"]}}, result.SyncersChosen)
+	assert.Equal(t, []BidderWeight{{Bidder: "c", Weight: 1e6, Known: true}, {Bidder: "a", Weight: 1, Known: true}}, result.BidderOrder)
+
+	request.BidderWeights = nil
+	result = chooser.Choose(request, &Cookie{})
+	assert.Equal(t, []SyncerChoice{{Bidder: "c", Syncer: bidderSyncerLookup["c"]}, {Bidder: "b", Syncer: bidderSyncerLookup["b"]}}, result.SyncersChosen, "the bidder chooser should shuffle without weights")
+	assert.Nil(t, result.BidderOrder)
+}
+
 func TestChooserEvaluate(t *testing.T) {
 	fakeSyncerA := fakeSyncer{key: "keyA", supportsIFrame: true}
 	fakeSyncerB := fakeSyncer{key: "keyB", supportsIFrame: false}
@@ -0,0 +1,40 @@
+package usersync
+
+import (
+	"time"
+
+	"github.com/prebid/prebid-server/v3/config"
+	"github.com/prebid/prebid-server/v3/metrics"
+)
+
+// Codec reads and writes the uids cookie
+type Codec interface {
+	Encoder
+	Decoder
+}
+
+// Base64Codec keeps the UIDs in the uids cookie
+type Base64Codec struct {
+	Base64Encoder
+	Base64Decoder
+}
+
+// NewCodec returns the Codec for the uids cookie format configured by the host
+func NewCodec(cfg config.HostCookie, metricsEngine metrics.MetricsEngine) (Codec, error) {
+	var cookieCodec Codec = Base64Codec{}
+	switch cfg.Security.Mode {
+	case config.UIDCookieSecurityModeSign, config.UIDCookieSecurityModeEncrypt:
+		secureCodec, err := NewSecureCodec(cfg.Security, metricsEngine)
+		if err != nil {
+			return nil, err
+		}
+		cookieCodec = secureCodec
+	}
+
+	if !cfg.UIDStore.Enabled {
+		return cookieCodec, nil
+	}
+
+	store := NewMemoryUIDStore(cfg.UIDStore.Memory.SizeBytes, cfg.TTLDuration())
+	return NewStoreCodec(store, cfg.UIDStore.SigningKey, time.Duration(cfg.UIDStore.TimeoutMS)*time.Millisecond, cookieCodec), nil
+}
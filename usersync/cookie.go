@@ -23,6 +23,11 @@ const uidTTL = 14 * 24 * time.Hour
 type Cookie struct {
 	uids   map[string]UIDEntry
 	optOut bool
+	// hostID identifies the user in the UIDStore, empty when the UIDs are held by the cookie
+	hostID string
+	// hostUIDsUnread is set when the UIDs of the host ID couldn't be read from the UIDStore, so the
+	// cookie must not replace them
+	hostUIDsUnread bool
 }
 
 // UIDEntry bundles the UID with an Expiration date.
@@ -61,7 +66,7 @@ func (cookie *Cookie) PrepareCookieForWrite(cfg *config.HostCookie, encoder Enco
 	for len(cookie.uids) > 0 {
 		encodedCookie, err := encoder.Encode(cookie)
 		if err != nil {
-			return encodedCookie, nil
+			return "", err
 		}
 
 		// Convert to HTTP Cookie to Get Size
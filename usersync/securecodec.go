@@ -0,0 +1,214 @@
+package usersync
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/prebid/prebid-server/v3/config"
+	"github.com/prebid/prebid-server/v3/metrics"
+	"github.com/prebid/prebid-server/v3/util/jsonutil"
+)
+
+// securedCookieSeparator separates the parts of an encrypted or signed cookie. It is not part of the
+// base 64 URL alphabet, so it tells the secured cookies from the legacy ones.
+const securedCookieSeparator = "~"
+
+const (
+	encryptedCookieVersion = "e"
+	signedCookieVersion    = "s"
+)
+
+// cookieKey holds the keys derived from a keyring secret
+type cookieKey struct {
+	aead       cipher.AEAD
+	signingKey []byte
+}
+
+// SecureCodec encrypts or signs the uids cookie with the active key of a keyring, and reads the cookies
+// written with any key of the keyring. Legacy base 64 cookies are still read until the legacy cutoff, so
+// they are secured the next time they are written. Cookies failing their integrity check, and legacy
+// cookies past the cutoff, are counted and treated as empty.
+type SecureCodec struct {
+	mode          string
+	activeKeyID   string
+	keys          map[string]cookieKey
+	legacyCutoff  time.Time
+	metricsEngine metrics.MetricsEngine
+}
+
+// NewSecureCodec returns a Codec securing the uids cookie with the keys of the config and its keys file
+func NewSecureCodec(cfg config.UIDCookieSecurity, metricsEngine metrics.MetricsEngine) (*SecureCodec, error) {
+	keys := cfg.Keys
+	if cfg.KeysFile != "" {
+		fileKeys, err := readCookieKeysFile(cfg.KeysFile)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(append([]config.UIDCookieKey(nil), keys...), fileKeys...)
+	}
+
+	codec := &SecureCodec{
+		mode:          cfg.Mode,
+		activeKeyID:   cfg.ActiveKey,
+		keys:          make(map[string]cookieKey, len(keys)),
+		metricsEngine: metricsEngine,
+	}
+	if cfg.LegacyCutoff != "" {
+		legacyCutoff, err := time.Parse(time.RFC3339, cfg.LegacyCutoff)
+		if err != nil {
+			return nil, fmt.Errorf("invalid uids cookie legacy cutoff: %v", err)
+		}
+		codec.legacyCutoff = legacyCutoff
+	}
+	for _, key := range keys {
+		if key.ID == "" || strings.ContainsAny(key.ID, securedCookieSeparator+hostIDSeparator) || key.Secret == "" {
+			return nil, fmt.Errorf("invalid uids cookie key %q", key.ID)
+		}
+		if _, exists := codec.keys[key.ID]; exists {
+			return nil, fmt.Errorf("duplicate uids cookie key %q", key.ID)
+		}
+		derived, err := deriveCookieKey(key.Secret)
+		if err != nil {
+			return nil, err
+		}
+		codec.keys[key.ID] = derived
+	}
+	if _, exists := codec.keys[cfg.ActiveKey]; !exists {
+		return nil, fmt.Errorf("the active uids cookie key %q is not in the keyring", cfg.ActiveKey)
+	}
+	return codec, nil
+}
+
+func readCookieKeysFile(path string) ([]config.UIDCookieKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the uids cookie keys file: %v", err)
+	}
+	var keys []config.UIDCookieKey
+	if err := jsonutil.UnmarshalValid(data, &keys); err != nil {
+		return nil, fmt.Errorf("failed to parse the uids cookie keys file: %v", err)
+	}
+	return keys, nil
+}
+
+// deriveCookieKey derives distinct encryption and signing keys from the secret
+func deriveCookieKey(secret string) (cookieKey, error) {
+	block, err := aes.NewCipher(hmacSHA256([]byte(secret), []byte("uids-cookie-encryption")))
+	if err != nil {
+		return cookieKey{}, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return cookieKey{}, err
+	}
+	return cookieKey{
+		aead:       aead,
+		signingKey: hmacSHA256([]byte(secret), []byte("uids-cookie-signing")),
+	}, nil
+}
+
+// Encode writes the cookie as "e~<key id>~<nonce and ciphertext>" when encrypting, or as
+// "s~<key id>~<json>~<signature>" when signing.
+func (c *SecureCodec) Encode(cookie *Cookie) (string, error) {
+	j, err := jsonutil.Marshal(cookie)
+	if err != nil {
+		return "", err
+	}
+	key := c.keys[c.activeKeyID]
+
+	if c.mode == config.UIDCookieSecurityModeSign {
+		signed := signedCookieVersion + securedCookieSeparator + c.activeKeyID + securedCookieSeparator + base64.RawURLEncoding.EncodeToString(j)
+		return signed + securedCookieSeparator + base64.RawURLEncoding.EncodeToString(hmacSHA256(key.signingKey, []byte(signed))), nil
+	}
+
+	header := encryptedCookieVersion + securedCookieSeparator + c.activeKeyID
+	nonce := make([]byte, key.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	sealed := key.aead.Seal(nonce, nonce, j, []byte(header))
+	return header + securedCookieSeparator + base64.RawURLEncoding.EncodeToString(sealed), nil
+}
+
+// Decode reads encrypted, signed and legacy cookies regardless of the configured mode, so the mode can
+// be changed without losing the UIDs. Legacy cookies are rejected past the legacy cutoff.
+func (c *SecureCodec) Decode(encodedValue string) *Cookie {
+	if !strings.Contains(encodedValue, securedCookieSeparator) {
+		if encodedValue != "" && !c.legacyCutoff.IsZero() && time.Now().After(c.legacyCutoff) {
+			c.metricsEngine.RecordUIDCookieLegacyRejected()
+			return NewCookie()
+		}
+		return Base64Decoder{}.Decode(encodedValue)
+	}
+
+	j, err := c.open(encodedValue)
+	if err != nil {
+		c.metricsEngine.RecordUIDCookieTampered()
+		return NewCookie()
+	}
+
+	var cookie Cookie
+	if err := jsonutil.UnmarshalValid(j, &cookie); err != nil {
+		return NewCookie()
+	}
+	return &cookie
+}
+
+var errTamperedCookie = errors.New("the uids cookie failed its integrity check")
+
+// open verifies a secured cookie and returns its JSON
+func (c *SecureCodec) open(encodedValue string) ([]byte, error) {
+	parts := strings.Split(encodedValue, securedCookieSeparator)
+	if len(parts) < 3 {
+		return nil, errTamperedCookie
+	}
+	key, exists := c.keys[parts[1]]
+	if !exists {
+		return nil, errTamperedCookie
+	}
+
+	switch {
+	case parts[0] == encryptedCookieVersion && len(parts) == 3:
+		sealed, err := base64.RawURLEncoding.DecodeString(parts[2])
+		if err != nil || len(sealed) < key.aead.NonceSize() {
+			return nil, errTamperedCookie
+		}
+		nonce, ciphertext := sealed[:key.aead.NonceSize()], sealed[key.aead.NonceSize():]
+		j, err := key.aead.Open(nil, nonce, ciphertext, []byte(parts[0]+securedCookieSeparator+parts[1]))
+		if err != nil {
+			return nil, errTamperedCookie
+		}
+		return j, nil
+	case parts[0] == signedCookieVersion && len(parts) == 4:
+		signature, err := base64.RawURLEncoding.DecodeString(parts[3])
+		if err != nil {
+			return nil, errTamperedCookie
+		}
+		signed := strings.Join(parts[:3], securedCookieSeparator)
+		if !hmac.Equal(signature, hmacSHA256(key.signingKey, []byte(signed))) {
+			return nil, errTamperedCookie
+		}
+		j, err := base64.RawURLEncoding.DecodeString(parts[2])
+		if err != nil {
+			return nil, errTamperedCookie
+		}
+		return j, nil
+	default:
+		return nil, errTamperedCookie
+	}
+}
+
+func hmacSHA256(key, data []byte) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write(data)
+	return mac.Sum(nil)
+}
@@ -0,0 +1,309 @@
+package usersync
+
+import (
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prebid/prebid-server/v3/config"
+	"github.com/prebid/prebid-server/v3/metrics"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSecureCodecEncodeDecode(t *testing.T) {
+	testCases := []struct {
+		name           string
+		mode           string
+		expectedPrefix string
+		expectReadable bool
+	}{
+		{
+			name:           "encrypt",
+			mode:           config.UIDCookieSecurityModeEncrypt,
+			expectedPrefix: "e~k1~",
+		},
+		{
+			name:           "sign",
+			mode:           config.UIDCookieSecurityModeSign,
+			expectedPrefix: "s~k1~",
+			expectReadable: true,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.name, func(t *testing.T) {
+			codec := newTestSecureCodec(t, test.mode, "k1", &metrics.MetricsEngineMock{})
+
+			cookie := NewCookie()
+			require.NoError(t, cookie.Sync("adnxs", "secret-uid"))
+
+			encoded, err := codec.Encode(cookie)
+			require.NoError(t, err)
+			assert.True(t, strings.HasPrefix(encoded, test.expectedPrefix))
+			assert.NotContains(t, encoded, hostIDSeparator, "secured cookies must not be mistaken for host IDs")
+
+			payload, err := base64.RawURLEncoding.DecodeString(strings.Split(encoded, securedCookieSeparator)[2])
+			require.NoError(t, err)
+			assert.Equal(t, test.expectReadable, strings.Contains(string(payload), "secret-uid"), "only signed cookies hold readable UIDs")
+
+			assert.Equal(t, map[string]string{"adnxs": "secret-uid"}, codec.Decode(encoded).GetUIDs())
+		})
+	}
+}
+
+func TestSecureCodecDecode(t *testing.T) {
+	cookie := NewCookie()
+	require.NoError(t, cookie.Sync("adnxs", "123"))
+
+	legacyEncoded, err := Base64Encoder{}.Encode(cookie)
+	require.NoError(t, err)
+	encryptedWithOldKey, err := newTestSecureCodec(t, config.UIDCookieSecurityModeEncrypt, "k1", &metrics.MetricsEngineMock{}).Encode(cookie)
+	require.NoError(t, err)
+	signedWithOldKey, err := newTestSecureCodec(t, config.UIDCookieSecurityModeSign, "k1", &metrics.MetricsEngineMock{}).Encode(cookie)
+	require.NoError(t, err)
+	removedKeyCodec, err := NewSecureCodec(config.UIDCookieSecurity{
+		Mode:      config.UIDCookieSecurityModeEncrypt,
+		ActiveKey: "k0",
+		Keys:      []config.UIDCookieKey{{ID: "k0", Secret: "secret-0"}},
+	}, &metrics.MetricsEngineMock{})
+	require.NoError(t, err)
+	encryptedWithRemovedKey, err := removedKeyCodec.Encode(cookie)
+	require.NoError(t, err)
+
+	encryptedParts := strings.Split(encryptedWithOldKey, securedCookieSeparator)
+	sealed, err := base64.RawURLEncoding.DecodeString(encryptedParts[2])
+	require.NoError(t, err)
+	sealed[len(sealed)-1] ^= 1
+	encryptedParts[2] = base64.RawURLEncoding.EncodeToString(sealed)
+	changedCiphertext := strings.Join(encryptedParts, securedCookieSeparator)
+
+	signedParts := strings.Split(signedWithOldKey, securedCookieSeparator)
+	forgedCookie := NewCookie()
+	require.NoError(t, forgedCookie.Sync("adnxs", "forged"))
+	forgedJSON, err := Base64Encoder{}.Encode(forgedCookie)
+	require.NoError(t, err)
+	signedParts[2] = strings.TrimRight(forgedJSON, "=")
+	forgedSigned := strings.Join(signedParts, securedCookieSeparator)
+
+	testCases := []struct {
+		name           string
+		encoded        string
+		expectedUIDs   map[string]string
+		expectTampered bool
+	}{
+		{
+			name:         "legacy_cookie",
+			encoded:      legacyEncoded,
+			expectedUIDs: map[string]string{"adnxs": "123"},
+		},
+		{
+			name:         "malformed_legacy_cookie",
+			encoded:      "malformed",
+			expectedUIDs: map[string]string{},
+		},
+		{
+			name:         "encrypted_with_rotated_key",
+			encoded:      encryptedWithOldKey,
+			expectedUIDs: map[string]string{"adnxs": "123"},
+		},
+		{
+			name:         "signed_with_rotated_key",
+			encoded:      signedWithOldKey,
+			expectedUIDs: map[string]string{"adnxs": "123"},
+		},
+		{
+			name:           "encrypted_with_removed_key",
+			encoded:        encryptedWithRemovedKey,
+			expectedUIDs:   map[string]string{},
+			expectTampered: true,
+		},
+		{
+			name:           "key_id_changed",
+			encoded:        strings.Replace(encryptedWithOldKey, "~k1~", "~k2~", 1),
+			expectedUIDs:   map[string]string{},
+			expectTampered: true,
+		},
+		{
+			name:           "ciphertext_changed",
+			encoded:        changedCiphertext,
+			expectedUIDs:   map[string]string{},
+			expectTampered: true,
+		},
+		{
+			name:           "signed_payload_changed",
+			encoded:        forgedSigned,
+			expectedUIDs:   map[string]string{},
+			expectTampered: true,
+		},
+		{
+			name:           "unknown_version",
+			encoded:        "x" + encryptedWithOldKey[1:],
+			expectedUIDs:   map[string]string{},
+			expectTampered: true,
+		},
+		{
+			name:           "missing_parts",
+			encoded:        "e~k1",
+			expectedUIDs:   map[string]string{},
+			expectTampered: true,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.name, func(t *testing.T) {
+			metricsMock := &metrics.MetricsEngineMock{}
+			metricsMock.On("RecordUIDCookieTampered").Return()
+			codec := newTestSecureCodec(t, config.UIDCookieSecurityModeEncrypt, "k2", metricsMock)
+
+			decoded := codec.Decode(test.encoded)
+			assert.Equal(t, test.expectedUIDs, decoded.GetUIDs())
+			if test.expectTampered {
+				metricsMock.AssertNumberOfCalls(t, "RecordUIDCookieTampered", 1)
+			} else {
+				metricsMock.AssertNotCalled(t, "RecordUIDCookieTampered")
+			}
+		})
+	}
+}
+
+func TestSecureCodecDecodeLegacyCutoff(t *testing.T) {
+	cookie := NewCookie()
+	require.NoError(t, cookie.Sync("adnxs", "123"))
+	legacyEncoded, err := Base64Encoder{}.Encode(cookie)
+	require.NoError(t, err)
+
+	testCases := []struct {
+		name           string
+		legacyCutoff   string
+		expectedUIDs   map[string]string
+		expectRejected bool
+	}{
+		{
+			name:         "no_cutoff",
+			expectedUIDs: map[string]string{"adnxs": "123"},
+		},
+		{
+			name:         "before_cutoff",
+			legacyCutoff: time.Now().Add(time.Hour).Format(time.RFC3339),
+			expectedUIDs: map[string]string{"adnxs": "123"},
+		},
+		{
+			name:           "past_cutoff",
+			legacyCutoff:   time.Now().Add(-time.Hour).Format(time.RFC3339),
+			expectedUIDs:   map[string]string{},
+			expectRejected: true,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.name, func(t *testing.T) {
+			metricsMock := &metrics.MetricsEngineMock{}
+			metricsMock.On("RecordUIDCookieLegacyRejected").Return()
+			codec, err := NewSecureCodec(config.UIDCookieSecurity{
+				Mode:         config.UIDCookieSecurityModeSign,
+				ActiveKey:    "k1",
+				Keys:         []config.UIDCookieKey{{ID: "k1", Secret: "secret-1"}},
+				LegacyCutoff: test.legacyCutoff,
+			}, metricsMock)
+			require.NoError(t, err)
+
+			assert.Equal(t, test.expectedUIDs, codec.Decode(legacyEncoded).GetUIDs())
+			if test.expectRejected {
+				metricsMock.AssertNumberOfCalls(t, "RecordUIDCookieLegacyRejected", 1)
+			} else {
+				metricsMock.AssertNotCalled(t, "RecordUIDCookieLegacyRejected")
+			}
+
+			signed, err := codec.Encode(cookie)
+			require.NoError(t, err)
+			assert.Equal(t, map[string]string{"adnxs": "123"}, codec.Decode(signed).GetUIDs(), "the secured cookies must still be read")
+		})
+	}
+}
+
+func TestNewSecureCodecKeysFile(t *testing.T) {
+	dir := t.TempDir()
+	writeFile := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.WriteFile(path, []byte(content), 0600))
+		return path
+	}
+
+	testCases := []struct {
+		name          string
+		cfg           config.UIDCookieSecurity
+		expectedError string
+	}{
+		{
+			name: "file_keys",
+			cfg: config.UIDCookieSecurity{
+				Mode:      config.UIDCookieSecurityModeEncrypt,
+				ActiveKey: "k2",
+				Keys:      []config.UIDCookieKey{{ID: "k1", Secret: "secret-1"}},
+				KeysFile:  writeFile("keys.json", `[{"id":"k2","secret":"secret-2"}]`),
+			},
+		},
+		{
+			name: "missing_file",
+			cfg: config.UIDCookieSecurity{
+				Mode:      config.UIDCookieSecurityModeEncrypt,
+				ActiveKey: "k1",
+				KeysFile:  filepath.Join(dir, "missing.json"),
+			},
+			expectedError: "failed to read the uids cookie keys file",
+		},
+		{
+			name: "malformed_file",
+			cfg: config.UIDCookieSecurity{
+				Mode:      config.UIDCookieSecurityModeEncrypt,
+				ActiveKey: "k1",
+				KeysFile:  writeFile("malformed.json", `{"id":"k1"}`),
+			},
+			expectedError: "failed to parse the uids cookie keys file",
+		},
+		{
+			name: "invalid_file_key",
+			cfg: config.UIDCookieSecurity{
+				Mode:      config.UIDCookieSecurityModeEncrypt,
+				ActiveKey: "k1",
+				KeysFile:  writeFile("invalid.json", `[{"id":"k~1","secret":"secret"}]`),
+			},
+			expectedError: `invalid uids cookie key "k~1"`,
+		},
+		{
+			name: "duplicate_key",
+			cfg: config.UIDCookieSecurity{
+				Mode:      config.UIDCookieSecurityModeEncrypt,
+				ActiveKey: "k1",
+				Keys:      []config.UIDCookieKey{{ID: "k1", Secret: "secret-1"}},
+				KeysFile:  writeFile("duplicate.json", `[{"id":"k1","secret":"secret-2"}]`),
+			},
+			expectedError: `duplicate uids cookie key "k1"`,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.name, func(t *testing.T) {
+			codec, err := NewSecureCodec(test.cfg, &metrics.MetricsEngineMock{})
+			if test.expectedError != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), test.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			assert.Len(t, codec.keys, 2)
+		})
+	}
+}
+
+// newTestSecureCodec returns a codec writing with activeKey, whose keyring holds k1 and k2
+func newTestSecureCodec(t *testing.T, mode, activeKey string, metricsEngine metrics.MetricsEngine) *SecureCodec {
+	keys := []config.UIDCookieKey{{ID: "k1", Secret: "secret-1"}, {ID: "k2", Secret: "secret-2"}}
+	codec, err := NewSecureCodec(config.UIDCookieSecurity{Mode: mode, ActiveKey: activeKey, Keys: keys}, metricsEngine)
+	require.NoError(t, err)
+	return codec
+}
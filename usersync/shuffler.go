@@ -1,6 +1,10 @@
 package usersync
 
-import "math/rand"
+import (
+	"math"
+	"math/rand"
+	"sort"
+)
 
 // shuffler changes the order of elements in the slice.
 type shuffler interface {
@@ -13,3 +17,82 @@ type randomShuffler struct{}
 func (randomShuffler) shuffle(v []string) {
 	rand.Shuffle(len(v), func(i, j int) { v[i], v[j] = v[j], v[i] })
 }
+
+// randomSource returns pseudo-random numbers in [0.0,1.0).
+type randomSource interface {
+	Float64() float64
+}
+
+// globalRandomSource uses the goroutine safe source of the rand package.
+type globalRandomSource struct{}
+
+func (globalRandomSource) Float64() float64 {
+	return rand.Float64()
+}
+
+// weightedShuffler randomly changes the order of bidders in the slice, making the bidders with a
+// higher weight more likely to come first. Bidders without a known weight get the average weight of
+// the others, and bidders with no weight come last.
+type weightedShuffler struct {
+	weights BidderWeights
+	random  randomSource
+}
+
+func (s weightedShuffler) shuffle(v []string) {
+	weights := s.bidderWeights(v)
+
+	// weighted random sampling without replacement, see Efraimidis and Spirakis (2006). the bidders
+	// with no weight are shuffled after the others.
+	keys := make(map[string]weightedShuffleKey, len(v))
+	for _, bidder := range v {
+		if _, ok := keys[bidder]; ok {
+			continue
+		}
+		u := 1 - s.random.Float64()
+		if weights[bidder] > 0 {
+			keys[bidder] = weightedShuffleKey{weighted: true, value: math.Log(u) / weights[bidder]}
+		} else {
+			keys[bidder] = weightedShuffleKey{value: u}
+		}
+	}
+
+	sort.SliceStable(v, func(i, j int) bool {
+		a, b := keys[v[i]], keys[v[j]]
+		if a.weighted != b.weighted {
+			return a.weighted
+		}
+		return a.value > b.value
+	})
+}
+
+type weightedShuffleKey struct {
+	weighted bool
+	value    float64
+}
+
+func (s weightedShuffler) bidderWeights(v []string) map[string]float64 {
+	weights := make(map[string]float64, len(v))
+	unknown := make(map[string]struct{})
+	var total float64
+	for _, bidder := range v {
+		if _, seen := weights[bidder]; seen {
+			continue
+		}
+		weight, ok := s.weights.Weight(bidder)
+		if !ok {
+			unknown[bidder] = struct{}{}
+			continue
+		}
+		weights[bidder] = math.Max(weight, 0)
+		total += weights[bidder]
+	}
+
+	average := 1.0
+	if len(weights) > 0 {
+		average = total / float64(len(weights))
+	}
+	for bidder := range unknown {
+		weights[bidder] = average
+	}
+	return weights
+}
@@ -1,6 +1,7 @@
 package usersync
 
 import (
+	"math/rand"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -40,3 +41,104 @@ func TestShuffler(t *testing.T) {
 		assert.ElementsMatch(t, givenCopy, test.given, test.description)
 	}
 }
+
+func TestWeightedShuffler(t *testing.T) {
+	testCases := []struct {
+		description string
+		weights     StaticBidderWeights
+		given       []string
+		expected    []string
+	}{
+		{
+			description: "Nil",
+			weights:     StaticBidderWeights{},
+			given:       nil,
+			expected:    nil,
+		},
+		{
+			description: "Heaviest First",
+			weights:     StaticBidderWeights{"a": 1, "b": 1e6, "c": 1e3},
+			given:       []string{"a", "b", "c"},
+			expected:    []string{"b", "c", "a"},
+		},
+		{
+			description: "No Weight Last",
+			weights:     StaticBidderWeights{"a": 0, "b": 1, "c": -1},
+			given:       []string{"a", "b", "c"},
+			expected:    []string{"b", "a", "c"},
+		},
+		{
+			description: "Duplicates Kept Together",
+			weights:     StaticBidderWeights{"a": 1, "b": 1e6},
+			given:       []string{"a", "b", "a"},
+			expected:    []string{"b", "a", "a"},
+		},
+	}
+
+	for _, test := range testCases {
+		shuffler := weightedShuffler{weights: test.weights, random: rand.New(rand.NewSource(1))}
+		shuffler.shuffle(test.given)
+		assert.Equal(t, test.expected, test.given, test.description)
+	}
+}
+
+func TestWeightedShufflerBidderWeights(t *testing.T) {
+	testCases := []struct {
+		description string
+		weights     StaticBidderWeights
+		given       []string
+		expected    map[string]float64
+	}{
+		{
+			description: "Unknown Weight Is Average Of Known",
+			weights:     StaticBidderWeights{"a": 1, "b": 3},
+			given:       []string{"a", "b", "c", "b"},
+			expected:    map[string]float64{"a": 1, "b": 3, "c": 2},
+		},
+		{
+			description: "Unknown Weight Is One When None Known",
+			weights:     StaticBidderWeights{},
+			given:       []string{"a", "b"},
+			expected:    map[string]float64{"a": 1, "b": 1},
+		},
+		{
+			description: "Negative Weight Is Zero",
+			weights:     StaticBidderWeights{"a": -1, "b": 2},
+			given:       []string{"a", "b", "c"},
+			expected:    map[string]float64{"a": 0, "b": 2, "c": 1},
+		},
+	}
+
+	for _, test := range testCases {
+		shuffler := weightedShuffler{weights: test.weights}
+		assert.Equal(t, test.expected, shuffler.bidderWeights(test.given), test.description)
+	}
+}
+
+func TestWeightedShufflerIsDeterministicWithSeed(t *testing.T) {
+	weights := StaticBidderWeights{"a": 1, "b": 1, "c": 1, "d": 1}
+
+	shuffle := func() []string {
+		v := []string{"a", "b", "c", "d"}
+		weightedShuffler{weights: weights, random: rand.New(rand.NewSource(42))}.shuffle(v)
+		return v
+	}
+
+	assert.Equal(t, shuffle(), shuffle())
+}
+
+func TestWeightedShufflerFavorsHeavierBidders(t *testing.T) {
+	shuffler := weightedShuffler{weights: StaticBidderWeights{"light": 1, "heavy": 3}, random: rand.New(rand.NewSource(1))}
+
+	heavyFirst := 0
+	for i := 0; i < 1000; i++ {
+		v := []string{"light", "heavy"}
+		shuffler.shuffle(v)
+		if v[0] == "heavy" {
+			heavyFirst++
+		}
+	}
+
+	// the heavy bidder comes first with a probability of 3/4
+	assert.InDelta(t, 750, heavyFirst, 50)
+}
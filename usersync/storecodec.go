@@ -0,0 +1,118 @@
+package usersync
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/golang/glog"
+)
+
+// hostIDSeparator separates the host ID from its signature. It is not part of the base 64 URL alphabet,
+// so it tells the cookies holding a host ID from the cookies holding the UIDs.
+const hostIDSeparator = "."
+
+const hostIDBytes = 16
+
+var errHostUIDsUnread = errors.New("the UIDs of the host ID could not be read from the store")
+
+// StoreCodec writes uids cookies holding only a signed host ID and keeps the UIDs in a UIDStore.
+// It still reads the cookies holding the UIDs, which move to the store the next time they are written.
+type StoreCodec struct {
+	store       UIDStore
+	signingKey  []byte
+	timeout     time.Duration
+	cookieCodec Codec
+}
+
+// NewStoreCodec returns a Codec keeping the UIDs in the store, signing the host IDs with signingKey.
+// The cookies holding the UIDs are read and written with cookieCodec.
+func NewStoreCodec(store UIDStore, signingKey string, timeout time.Duration, cookieCodec Codec) *StoreCodec {
+	return &StoreCodec{
+		store:       store,
+		signingKey:  []byte(signingKey),
+		timeout:     timeout,
+		cookieCodec: cookieCodec,
+	}
+}
+
+// Encode saves the UIDs of the cookie in the store and returns the signed host ID. Opted out cookies
+// are encoded with the cookie codec instead, so the opt out never depends on the store. It fails for
+// the cookies whose UIDs couldn't be read from the store, which would otherwise overwrite them.
+func (c *StoreCodec) Encode(cookie *Cookie) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
+	defer cancel()
+
+	if cookie.optOut {
+		if cookie.hostID != "" {
+			if err := c.store.Delete(ctx, cookie.hostID); err != nil {
+				return "", err
+			}
+			cookie.hostID = ""
+		}
+		return c.cookieCodec.Encode(cookie)
+	}
+
+	if cookie.hostUIDsUnread {
+		return "", errHostUIDsUnread
+	}
+
+	if cookie.hostID == "" {
+		hostID, err := newHostID()
+		if err != nil {
+			return "", err
+		}
+		cookie.hostID = hostID
+	}
+
+	if err := c.store.Save(ctx, cookie.hostID, cookie.uids); err != nil {
+		return "", err
+	}
+	return cookie.hostID + hostIDSeparator + c.sign(cookie.hostID), nil
+}
+
+// Decode loads the UIDs of the host ID from the store, or decodes the UIDs held by the cookie
+func (c *StoreCodec) Decode(encodedValue string) *Cookie {
+	hostID, signature, isHostID := strings.Cut(encodedValue, hostIDSeparator)
+	if !isHostID {
+		return c.cookieCodec.Decode(encodedValue)
+	}
+	if !hmac.Equal([]byte(signature), []byte(c.sign(hostID))) {
+		return NewCookie()
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
+	defer cancel()
+
+	cookie := NewCookie()
+	cookie.hostID = hostID
+
+	uids, err := c.store.Get(ctx, hostID)
+	if err != nil {
+		glog.Errorf("Failed to read the UIDs of a host ID from the store: %v", err)
+		cookie.hostUIDsUnread = true
+		return cookie
+	}
+	for key, uid := range uids {
+		if !checkAudienceNetwork(key, uid.UID) {
+			cookie.uids[key] = uid
+		}
+	}
+	return cookie
+}
+
+func (c *StoreCodec) sign(hostID string) string {
+	return base64.RawURLEncoding.EncodeToString(hmacSHA256(c.signingKey, []byte(hostID)))
+}
+
+func newHostID() (string, error) {
+	id := make([]byte, hostIDBytes)
+	if _, err := rand.Read(id); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(id), nil
+}
@@ -0,0 +1,280 @@
+package usersync
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prebid/prebid-server/v3/config"
+	"github.com/prebid/prebid-server/v3/metrics"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStoreCodecEncodeDecode(t *testing.T) {
+	store := NewMemoryUIDStore(1024*1024, time.Hour)
+	codec := NewStoreCodec(store, "key", time.Second, Base64Codec{})
+
+	cookie := NewCookie()
+	require.NoError(t, cookie.Sync("adnxs", "123"))
+	require.NoError(t, cookie.Sync("rubicon", "456"))
+
+	encoded, err := codec.Encode(cookie)
+	require.NoError(t, err)
+	assert.NotContains(t, encoded, "123", "the cookie should not hold the UIDs")
+	assert.Contains(t, encoded, hostIDSeparator)
+
+	decoded := codec.Decode(encoded)
+	assert.Equal(t, map[string]string{"adnxs": "123", "rubicon": "456"}, decoded.GetUIDs())
+
+	require.NoError(t, decoded.Sync("openx", "789"))
+	reencoded, err := codec.Encode(decoded)
+	require.NoError(t, err)
+	assert.Equal(t, encoded, reencoded, "the host ID should be kept")
+	assert.Equal(t, map[string]string{"adnxs": "123", "rubicon": "456", "openx": "789"}, codec.Decode(reencoded).GetUIDs())
+}
+
+func TestStoreCodecDecode(t *testing.T) {
+	store := NewMemoryUIDStore(1024*1024, time.Hour)
+	codec := NewStoreCodec(store, "key", time.Second, Base64Codec{})
+
+	legacyCookie := NewCookie()
+	require.NoError(t, legacyCookie.Sync("adnxs", "123"))
+	legacyEncoded, err := Base64Encoder{}.Encode(legacyCookie)
+	require.NoError(t, err)
+
+	storedCookie := NewCookie()
+	require.NoError(t, storedCookie.Sync("rubicon", "456"))
+	storedEncoded, err := codec.Encode(storedCookie)
+	require.NoError(t, err)
+	hostID, _, _ := strings.Cut(storedEncoded, hostIDSeparator)
+
+	testCases := []struct {
+		name         string
+		encoded      string
+		expectedUIDs map[string]string
+		expectHostID bool
+	}{
+		{
+			name:         "legacy_cookie",
+			encoded:      legacyEncoded,
+			expectedUIDs: map[string]string{"adnxs": "123"},
+		},
+		{
+			name:         "signed_host_id",
+			encoded:      storedEncoded,
+			expectedUIDs: map[string]string{"rubicon": "456"},
+			expectHostID: true,
+		},
+		{
+			name:         "signed_with_another_key",
+			encoded:      hostID + hostIDSeparator + NewStoreCodec(store, "other", time.Second, Base64Codec{}).sign(hostID),
+			expectedUIDs: map[string]string{},
+		},
+		{
+			name:         "forged_host_id",
+			encoded:      "forged" + storedEncoded[len(hostID):],
+			expectedUIDs: map[string]string{},
+		},
+		{
+			name:         "malformed",
+			encoded:      "malformed",
+			expectedUIDs: map[string]string{},
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.name, func(t *testing.T) {
+			cookie := codec.Decode(test.encoded)
+			assert.Equal(t, test.expectedUIDs, cookie.GetUIDs())
+			assert.Equal(t, test.expectHostID, cookie.hostID != "")
+		})
+	}
+}
+
+func TestStoreCodecMigratesLegacyCookie(t *testing.T) {
+	store := NewMemoryUIDStore(1024*1024, time.Hour)
+	codec := NewStoreCodec(store, "key", time.Second, Base64Codec{})
+
+	legacyCookie := NewCookie()
+	require.NoError(t, legacyCookie.Sync("adnxs", "123"))
+	legacyEncoded, err := Base64Encoder{}.Encode(legacyCookie)
+	require.NoError(t, err)
+
+	cookie := codec.Decode(legacyEncoded)
+	require.NoError(t, cookie.Sync("rubicon", "456"))
+	encoded, err := codec.Encode(cookie)
+	require.NoError(t, err)
+
+	hostID, _, _ := strings.Cut(encoded, hostIDSeparator)
+	uids, err := store.Get(context.Background(), hostID)
+	require.NoError(t, err)
+	assert.Len(t, uids, 2)
+	assert.Equal(t, "123", uids["adnxs"].UID)
+	assert.Equal(t, "456", uids["rubicon"].UID)
+}
+
+func TestStoreCodecOptOut(t *testing.T) {
+	store := NewMemoryUIDStore(1024*1024, time.Hour)
+	codec := NewStoreCodec(store, "key", time.Second, Base64Codec{})
+
+	cookie := NewCookie()
+	require.NoError(t, cookie.Sync("adnxs", "123"))
+	encoded, err := codec.Encode(cookie)
+	require.NoError(t, err)
+	hostID, _, _ := strings.Cut(encoded, hostIDSeparator)
+
+	cookie = codec.Decode(encoded)
+	cookie.SetOptOut(true)
+	optOutEncoded, err := codec.Encode(cookie)
+	require.NoError(t, err)
+	assert.NotContains(t, optOutEncoded, hostIDSeparator, "opt outs should not depend on the store")
+	assert.False(t, codec.Decode(optOutEncoded).AllowSyncs())
+
+	uids, err := store.Get(context.Background(), hostID)
+	require.NoError(t, err)
+	assert.Empty(t, uids, "the UIDs should be removed from the store")
+}
+
+func TestStoreCodecStoreErrors(t *testing.T) {
+	codec := NewStoreCodec(failingUIDStore{}, "key", time.Second, Base64Codec{})
+
+	cookie := NewCookie()
+	require.NoError(t, cookie.Sync("adnxs", "123"))
+	_, err := codec.Encode(cookie)
+	assert.EqualError(t, err, "store unavailable")
+
+	hostID := "host"
+	decoded := codec.Decode(hostID + hostIDSeparator + codec.sign(hostID))
+	assert.Empty(t, decoded.GetUIDs())
+	assert.Equal(t, hostID, decoded.hostID)
+}
+
+func TestStoreCodecKeepsUnreadUIDs(t *testing.T) {
+	store := &unreadableUIDStore{UIDStore: NewMemoryUIDStore(1024*1024, time.Hour)}
+	codec := NewStoreCodec(store, "key", time.Second, Base64Codec{})
+
+	cookie := NewCookie()
+	require.NoError(t, cookie.Sync("adnxs", "123"))
+	encoded, err := codec.Encode(cookie)
+	require.NoError(t, err)
+	hostID, _, _ := strings.Cut(encoded, hostIDSeparator)
+
+	store.failGet = true
+	decoded := codec.Decode(encoded)
+	assert.Empty(t, decoded.GetUIDs())
+	require.NoError(t, decoded.Sync("rubicon", "456"))
+	_, err = codec.Encode(decoded)
+	assert.Equal(t, errHostUIDsUnread, err)
+
+	store.failGet = false
+	uids, err := store.Get(context.Background(), hostID)
+	require.NoError(t, err)
+	assert.Len(t, uids, 1, "the stored UIDs should not be overwritten")
+	assert.Equal(t, "123", uids["adnxs"].UID)
+
+	decoded = codec.Decode(encoded)
+	decoded.SetOptOut(true)
+	_, err = codec.Encode(decoded)
+	assert.NoError(t, err, "opt outs should not depend on reading the store")
+}
+
+func TestNewCodec(t *testing.T) {
+	uidStore := config.UIDStore{
+		Enabled:    true,
+		SigningKey: "key",
+		Type:       config.UIDStoreTypeMemory,
+		TimeoutMS:  50,
+		Memory:     config.UIDStoreMemory{SizeBytes: 1024 * 1024},
+	}
+	security := config.UIDCookieSecurity{
+		Mode:      config.UIDCookieSecurityModeEncrypt,
+		ActiveKey: "k1",
+		Keys:      []config.UIDCookieKey{{ID: "k1", Secret: "secret"}},
+	}
+
+	testCases := []struct {
+		name                string
+		hostCookie          config.HostCookie
+		expectedCodec       Codec
+		expectedCookieCodec Codec
+		expectedError       string
+	}{
+		{
+			name:          "default",
+			hostCookie:    config.HostCookie{},
+			expectedCodec: Base64Codec{},
+		},
+		{
+			name:          "security_none",
+			hostCookie:    config.HostCookie{Security: config.UIDCookieSecurity{Mode: config.UIDCookieSecurityModeNone}},
+			expectedCodec: Base64Codec{},
+		},
+		{
+			name:          "secure",
+			hostCookie:    config.HostCookie{Security: security},
+			expectedCodec: &SecureCodec{},
+		},
+		{
+			name:                "store",
+			hostCookie:          config.HostCookie{TTL: 90, UIDStore: uidStore},
+			expectedCodec:       &StoreCodec{},
+			expectedCookieCodec: Base64Codec{},
+		},
+		{
+			name:                "secure_store",
+			hostCookie:          config.HostCookie{TTL: 90, UIDStore: uidStore, Security: security},
+			expectedCodec:       &StoreCodec{},
+			expectedCookieCodec: &SecureCodec{},
+		},
+		{
+			name:          "invalid_keyring",
+			hostCookie:    config.HostCookie{Security: config.UIDCookieSecurity{Mode: config.UIDCookieSecurityModeSign, ActiveKey: "k2", Keys: security.Keys}},
+			expectedError: `the active uids cookie key "k2" is not in the keyring`,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.name, func(t *testing.T) {
+			codec, err := NewCodec(test.hostCookie, &metrics.MetricsEngineMock{})
+			if test.expectedError != "" {
+				assert.EqualError(t, err, test.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			assert.IsType(t, test.expectedCodec, codec)
+			if test.expectedCookieCodec != nil {
+				assert.IsType(t, test.expectedCookieCodec, codec.(*StoreCodec).cookieCodec)
+			}
+		})
+	}
+}
+
+type failingUIDStore struct{}
+
+func (failingUIDStore) Get(ctx context.Context, hostID string) (map[string]UIDEntry, error) {
+	return nil, errors.New("store unavailable")
+}
+
+func (failingUIDStore) Save(ctx context.Context, hostID string, uids map[string]UIDEntry) error {
+	return errors.New("store unavailable")
+}
+
+func (failingUIDStore) Delete(ctx context.Context, hostID string) error {
+	return errors.New("store unavailable")
+}
+
+// unreadableUIDStore is a UIDStore whose reads fail on demand
+type unreadableUIDStore struct {
+	UIDStore
+	failGet bool
+}
+
+func (s *unreadableUIDStore) Get(ctx context.Context, hostID string) (map[string]UIDEntry, error) {
+	if s.failGet {
+		return nil, errors.New("store unavailable")
+	}
+	return s.UIDStore.Get(ctx, hostID)
+}
@@ -0,0 +1,227 @@
+package usersync
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/coocood/freecache"
+	"github.com/golang/glog"
+	"github.com/prebid/openrtb/v20/openrtb2"
+	"github.com/prebid/prebid-server/v3/util/jsonutil"
+)
+
+const (
+	// UIDMappingKeyHostUID maps the partner UIDs to the UID of the host cookie
+	UIDMappingKeyHostUID = "hostuid"
+	// UIDMappingKeyEmailSHA256 maps the partner UIDs to the SHA-256 hash of the lower case email of the user
+	UIDMappingKeyEmailSHA256 = "email_sha256"
+)
+
+// UIDMapping maps the UID given to a user by a syncer to a key identifying the user.
+type UIDMapping struct {
+	KeyType   string
+	Key       string
+	SyncerKey string
+	UID       string
+	Expires   time.Time
+}
+
+// UIDMappingStore holds the partner UIDs ingested server to server. Implement it to share the
+// mappings between the Prebid Server instances of a host.
+type UIDMappingStore interface {
+	// Get returns the UIDs mapped to the key by syncer key, which are empty when the store holds none.
+	Get(ctx context.Context, keyType, key string) (map[string]UIDEntry, error)
+	// Save adds the mappings to the store, replacing the UIDs previously mapped for the same syncers.
+	Save(ctx context.Context, mappings []UIDMapping) error
+}
+
+type memoryUIDMappingStore struct {
+	cache *freecache.Cache
+	// mutex guards the read-modify-write of the mappings of a key
+	mutex sync.Mutex
+}
+
+// NewMemoryUIDMappingStore returns an in-memory UIDMappingStore of sizeBytes, which evicts the least
+// recently used keys once full.
+func NewMemoryUIDMappingStore(sizeBytes int) UIDMappingStore {
+	return &memoryUIDMappingStore{
+		cache: freecache.NewCache(sizeBytes),
+	}
+}
+
+func (s *memoryUIDMappingStore) Get(_ context.Context, keyType, key string) (map[string]UIDEntry, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return s.get(uidMappingCacheKey(keyType, key), time.Now())
+}
+
+func (s *memoryUIDMappingStore) Save(_ context.Context, mappings []UIDMapping) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	now := time.Now()
+	updated := make(map[string]map[string]UIDEntry)
+	for _, mapping := range mappings {
+		cacheKey := uidMappingCacheKey(mapping.KeyType, mapping.Key)
+		uids, ok := updated[cacheKey]
+		if !ok {
+			var err error
+			if uids, err = s.get(cacheKey, now); err != nil {
+				return err
+			}
+			updated[cacheKey] = uids
+		}
+		uids[mapping.SyncerKey] = UIDEntry{UID: mapping.UID, Expires: mapping.Expires}
+	}
+
+	for cacheKey, uids := range updated {
+		data, err := jsonutil.Marshal(uids)
+		if err != nil {
+			return err
+		}
+		if err := s.cache.Set([]byte(cacheKey), data, uidMappingTTLSeconds(uids, now)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// get returns the unexpired UIDs of the cache key
+func (s *memoryUIDMappingStore) get(cacheKey string, now time.Time) (map[string]UIDEntry, error) {
+	data, err := s.cache.Get([]byte(cacheKey))
+	if errors.Is(err, freecache.ErrNotFound) {
+		return make(map[string]UIDEntry), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	uids := make(map[string]UIDEntry)
+	if err := jsonutil.UnmarshalValid(data, &uids); err != nil {
+		return nil, err
+	}
+	for syncerKey, uid := range uids {
+		if !now.Before(uid.Expires) {
+			delete(uids, syncerKey)
+		}
+	}
+	return uids, nil
+}
+
+func uidMappingCacheKey(keyType, key string) string {
+	return keyType + ":" + key
+}
+
+// uidMappingTTLSeconds keeps the mappings of a key until the last of them expires
+func uidMappingTTLSeconds(uids map[string]UIDEntry, now time.Time) int {
+	var latest time.Time
+	for _, uid := range uids {
+		if uid.Expires.After(latest) {
+			latest = uid.Expires
+		}
+	}
+	return int(latest.Sub(now).Seconds()) + 1
+}
+
+// IDFetcher returns the UIDs of a user by syncer key.
+type IDFetcher interface {
+	GetUID(key string) (uid string, exists bool, notExpired bool)
+	HasAnyLiveSyncs() bool
+}
+
+// UIDMappings looks up the UIDs ingested server to server for the users whose uids cookie has none.
+type UIDMappings struct {
+	store          UIDMappingStore
+	hostFamily     string
+	emailEIDSource string
+	timeout        time.Duration
+}
+
+// NewUIDMappings returns the UID mappings of the store, keyed by the UID of the hostFamily syncer or by
+// the hashed email of the EID from emailEIDSource.
+func NewUIDMappings(store UIDMappingStore, hostFamily, emailEIDSource string, timeout time.Duration) *UIDMappings {
+	return &UIDMappings{
+		store:          store,
+		hostFamily:     hostFamily,
+		emailEIDSource: emailEIDSource,
+		timeout:        timeout,
+	}
+}
+
+// IDFetcher returns the UIDs of the cookie, falling back on the UIDs mapped to the user. It returns the
+// cookie itself when the mappings are nil or the user opted out. The mappings are read within ctx, the
+// context of the request.
+func (m *UIDMappings) IDFetcher(ctx context.Context, cookie *Cookie, user *openrtb2.User) IDFetcher {
+	if m == nil || !cookie.AllowSyncs() {
+		return cookie
+	}
+
+	var keys []UIDMapping
+	if hostUID, _, _ := cookie.GetUID(m.hostFamily); hostUID != "" && m.hostFamily != "" {
+		keys = append(keys, UIDMapping{KeyType: UIDMappingKeyHostUID, Key: hostUID})
+	}
+	if user != nil && m.emailEIDSource != "" {
+		for _, eid := range user.EIDs {
+			if eid.Source == m.emailEIDSource && len(eid.UIDs) > 0 && eid.UIDs[0].ID != "" {
+				keys = append(keys, UIDMapping{KeyType: UIDMappingKeyEmailSHA256, Key: strings.ToLower(eid.UIDs[0].ID)})
+				break
+			}
+		}
+	}
+	if len(keys) == 0 {
+		return cookie
+	}
+	return &mappedIDFetcher{ctx: ctx, cookie: cookie, mappings: m, keys: keys}
+}
+
+// mappedIDFetcher loads the mapped UIDs of the user the first time the cookie lacks a UID
+type mappedIDFetcher struct {
+	ctx      context.Context
+	cookie   *Cookie
+	mappings *UIDMappings
+	keys     []UIDMapping
+
+	once sync.Once
+	uids map[string]UIDEntry
+}
+
+func (f *mappedIDFetcher) GetUID(key string) (string, bool, bool) {
+	if uid, exists, notExpired := f.cookie.GetUID(key); exists && notExpired {
+		return uid, exists, notExpired
+	}
+
+	f.once.Do(f.load)
+	if uid, ok := f.uids[key]; ok {
+		return uid.UID, true, time.Now().Before(uid.Expires)
+	}
+	return f.cookie.GetUID(key)
+}
+
+// HasAnyLiveSyncs tells whether the cookie has live syncs, so the mapped UIDs don't change how the
+// request is labeled.
+func (f *mappedIDFetcher) HasAnyLiveSyncs() bool {
+	return f.cookie.HasAnyLiveSyncs()
+}
+
+// load merges the UIDs mapped to the keys of the user, the host UID mappings winning over the hashed
+// email ones.
+func (f *mappedIDFetcher) load() {
+	ctx, cancel := context.WithTimeout(f.ctx, f.mappings.timeout)
+	defer cancel()
+
+	f.uids = make(map[string]UIDEntry)
+	for i := len(f.keys) - 1; i >= 0; i-- {
+		uids, err := f.mappings.store.Get(ctx, f.keys[i].KeyType, f.keys[i].Key)
+		if err != nil {
+			glog.Errorf("Failed to read the UID mappings of the user: %v", err)
+			continue
+		}
+		for syncerKey, uid := range uids {
+			f.uids[syncerKey] = uid
+		}
+	}
+}
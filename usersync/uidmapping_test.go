@@ -0,0 +1,146 @@
+package usersync
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/prebid/openrtb/v20/openrtb2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryUIDMappingStore(t *testing.T) {
+	store := NewMemoryUIDMappingStore(1024 * 1024)
+	ctx := context.Background()
+	expires := time.Now().Add(time.Hour)
+
+	uids, err := store.Get(ctx, UIDMappingKeyHostUID, "host-1")
+	require.NoError(t, err)
+	assert.Empty(t, uids)
+
+	require.NoError(t, store.Save(ctx, []UIDMapping{
+		{KeyType: UIDMappingKeyHostUID, Key: "host-1", SyncerKey: "adnxs", UID: "an-1", Expires: expires},
+		{KeyType: UIDMappingKeyHostUID, Key: "host-1", SyncerKey: "rubicon", UID: "rp-1", Expires: time.Now().Add(-time.Second)},
+		{KeyType: UIDMappingKeyEmailSHA256, Key: "host-1", SyncerKey: "openx", UID: "ox-1", Expires: expires},
+	}))
+	require.NoError(t, store.Save(ctx, []UIDMapping{
+		{KeyType: UIDMappingKeyHostUID, Key: "host-1", SyncerKey: "openx", UID: "ox-2", Expires: expires},
+	}))
+
+	uids, err = store.Get(ctx, UIDMappingKeyHostUID, "host-1")
+	require.NoError(t, err)
+	assert.Len(t, uids, 2, "expired mappings should be dropped")
+	assert.Equal(t, "an-1", uids["adnxs"].UID)
+	assert.Equal(t, "ox-2", uids["openx"].UID, "the key types should not share mappings")
+}
+
+func TestUIDMappingsIDFetcher(t *testing.T) {
+	const emailSource = "email.example.com"
+	emailUser := &openrtb2.User{EIDs: []openrtb2.EID{
+		{Source: "other.com", UIDs: []openrtb2.UID{{ID: "other"}}},
+		{Source: emailSource, UIDs: []openrtb2.UID{{ID: "HASH"}}},
+	}}
+
+	store := NewMemoryUIDMappingStore(1024 * 1024)
+	expires := time.Now().Add(time.Hour)
+	require.NoError(t, store.Save(context.Background(), []UIDMapping{
+		{KeyType: UIDMappingKeyHostUID, Key: "host-1", SyncerKey: "adnxs", UID: "host-an", Expires: expires},
+		{KeyType: UIDMappingKeyEmailSHA256, Key: "hash", SyncerKey: "adnxs", UID: "email-an", Expires: expires},
+		{KeyType: UIDMappingKeyEmailSHA256, Key: "hash", SyncerKey: "rubicon", UID: "email-rp", Expires: expires},
+	}))
+	mappings := NewUIDMappings(store, "host", emailSource, time.Second)
+
+	testCases := []struct {
+		description  string
+		mappings     *UIDMappings
+		cookieUIDs   map[string]string
+		optOut       bool
+		user         *openrtb2.User
+		expectedUIDs map[string]string
+	}{
+		{
+			description:  "Mappings Disabled",
+			cookieUIDs:   map[string]string{"host": "host-1"},
+			user:         emailUser,
+			expectedUIDs: map[string]string{"adnxs": "", "rubicon": ""},
+		},
+		{
+			description:  "Cookie UID Wins",
+			mappings:     mappings,
+			cookieUIDs:   map[string]string{"host": "host-1", "adnxs": "cookie-an"},
+			user:         emailUser,
+			expectedUIDs: map[string]string{"adnxs": "cookie-an", "rubicon": "email-rp"},
+		},
+		{
+			description:  "Host UID Wins Over Hashed Email",
+			mappings:     mappings,
+			cookieUIDs:   map[string]string{"host": "host-1"},
+			user:         emailUser,
+			expectedUIDs: map[string]string{"adnxs": "host-an", "rubicon": "email-rp"},
+		},
+		{
+			description:  "Host UID Only",
+			mappings:     mappings,
+			cookieUIDs:   map[string]string{"host": "host-1"},
+			expectedUIDs: map[string]string{"adnxs": "host-an", "rubicon": ""},
+		},
+		{
+			description:  "Hashed Email Only",
+			mappings:     mappings,
+			user:         emailUser,
+			expectedUIDs: map[string]string{"adnxs": "email-an", "rubicon": "email-rp"},
+		},
+		{
+			description:  "Opted Out",
+			mappings:     mappings,
+			optOut:       true,
+			user:         emailUser,
+			expectedUIDs: map[string]string{"adnxs": "", "rubicon": ""},
+		},
+		{
+			description:  "Unknown User",
+			mappings:     mappings,
+			cookieUIDs:   map[string]string{"host": "host-2"},
+			expectedUIDs: map[string]string{"adnxs": "", "rubicon": ""},
+		},
+	}
+
+	for _, test := range testCases {
+		cookie := NewCookie()
+		for key, uid := range test.cookieUIDs {
+			require.NoError(t, cookie.Sync(key, uid))
+		}
+		cookie.SetOptOut(test.optOut)
+
+		fetcher := test.mappings.IDFetcher(context.Background(), cookie, test.user)
+		for key, expectedUID := range test.expectedUIDs {
+			uid, exists, notExpired := fetcher.GetUID(key)
+			assert.Equal(t, expectedUID, uid, test.description+":"+key)
+			assert.Equal(t, expectedUID != "", exists && notExpired, test.description+":"+key)
+		}
+		assert.Equal(t, cookie.HasAnyLiveSyncs(), fetcher.HasAnyLiveSyncs(), test.description)
+	}
+}
+
+func TestUIDMappingsIDFetcherStoreError(t *testing.T) {
+	cookie := NewCookie()
+	require.NoError(t, cookie.Sync("host", "host-1"))
+
+	fetcher := NewUIDMappings(failingUIDMappingStore{}, "host", "", time.Second).IDFetcher(context.Background(), cookie, nil)
+
+	uid, exists, _ := fetcher.GetUID("adnxs")
+	assert.Empty(t, uid)
+	assert.False(t, exists)
+}
+
+type failingUIDMappingStore struct{}
+
+func (failingUIDMappingStore) Get(ctx context.Context, keyType, key string) (map[string]UIDEntry, error) {
+	return nil, errors.New("store unavailable")
+}
+
+func (failingUIDMappingStore) Save(ctx context.Context, mappings []UIDMapping) error {
+	return errors.New("store unavailable")
+}
@@ -0,0 +1,64 @@
+package usersync
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/coocood/freecache"
+	"github.com/prebid/prebid-server/v3/util/jsonutil"
+)
+
+// UIDStore holds the bidder UIDs of the users identified by a host ID. Implement it to share the
+// UIDs between the Prebid Server instances of a host.
+type UIDStore interface {
+	// Get returns the UIDs of the user, which are empty when the store holds none.
+	Get(ctx context.Context, hostID string) (map[string]UIDEntry, error)
+	// Save replaces the UIDs of the user.
+	Save(ctx context.Context, hostID string, uids map[string]UIDEntry) error
+	// Delete removes the UIDs of the user.
+	Delete(ctx context.Context, hostID string) error
+}
+
+type memoryUIDStore struct {
+	cache      *freecache.Cache
+	ttlSeconds int
+}
+
+// NewMemoryUIDStore returns an in-memory UIDStore of sizeBytes, which evicts the least recently used
+// users once full and forgets the users not synced within ttl.
+func NewMemoryUIDStore(sizeBytes int, ttl time.Duration) UIDStore {
+	return &memoryUIDStore{
+		cache:      freecache.NewCache(sizeBytes),
+		ttlSeconds: int(ttl.Seconds()),
+	}
+}
+
+func (s *memoryUIDStore) Get(_ context.Context, hostID string) (map[string]UIDEntry, error) {
+	data, err := s.cache.Get([]byte(hostID))
+	if errors.Is(err, freecache.ErrNotFound) {
+		return make(map[string]UIDEntry), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	uids := make(map[string]UIDEntry)
+	if err := jsonutil.UnmarshalValid(data, &uids); err != nil {
+		return nil, err
+	}
+	return uids, nil
+}
+
+func (s *memoryUIDStore) Save(_ context.Context, hostID string, uids map[string]UIDEntry) error {
+	data, err := jsonutil.Marshal(uids)
+	if err != nil {
+		return err
+	}
+	return s.cache.Set([]byte(hostID), data, s.ttlSeconds)
+}
+
+func (s *memoryUIDStore) Delete(_ context.Context, hostID string) error {
+	s.cache.Del([]byte(hostID))
+	return nil
+}
@@ -0,0 +1,30 @@
+package usersync
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryUIDStore(t *testing.T) {
+	store := NewMemoryUIDStore(1024*1024, time.Hour)
+	ctx := context.Background()
+
+	uids, err := store.Get(ctx, "unknown")
+	require.NoError(t, err)
+	assert.Empty(t, uids)
+
+	saved := map[string]UIDEntry{"adnxs": {UID: "123", Expires: time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)}}
+	require.NoError(t, store.Save(ctx, "host", saved))
+	uids, err = store.Get(ctx, "host")
+	require.NoError(t, err)
+	assert.Equal(t, saved, uids)
+
+	require.NoError(t, store.Delete(ctx, "host"))
+	uids, err = store.Get(ctx, "host")
+	require.NoError(t, err)
+	assert.Empty(t, uids)
+}